@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/ui"
+	"github.com/spf13/cobra"
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Analyze Flow Logs that already exist",
+}
+
+var backfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "Month-by-month NAT traffic and cost trend from historic Flow Logs",
+	Long: `Analyzes Flow Logs you already collect, one day at a time, and prints a
+month-by-month breakdown of NAT traffic by destination service and its cost.
+No AWS resources are created.
+
+--source is either a CloudWatch Logs log group or an S3 URI pointing at the
+vpcflowlogs/<region>/ folder of a Flow Logs bucket.
+
+Examples:
+  terminat analyze backfill --source /vpc/flow-logs --from 2024-01-01 --to 2024-03-01
+  terminat analyze backfill --source s3://my-logs/AWSLogs/123456789012/vpcflowlogs/us-east-1/ --from 2024-01-01 --to 2024-03-01`,
+	RunE: runBackfill,
+}
+
+var (
+	backfillSource string
+	backfillFrom   string
+	backfillTo     string
+)
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+	analyzeCmd.AddCommand(backfillCmd)
+
+	analyzeCmd.PersistentFlags().StringVarP(&region, "region", "r", "", "AWS region (uses AWS_REGION env var if not specified)")
+	analyzeCmd.PersistentFlags().StringVarP(&profile, "profile", "p", "", "AWS profile (uses AWS_PROFILE env var if not specified)")
+	analyzeCmd.PersistentFlags().StringSliceVar(&assumeRoles, "assume-role", []string{}, "Role ARN(s) to assume in order after loading the profile (comma-separated for a chain)")
+	analyzeCmd.PersistentFlags().StringVar(&mfaSerial, "mfa-serial", "", "MFA device ARN for the first --assume-role hop")
+	analyzeCmd.PersistentFlags().BoolVar(&tagSession, "tag-session", false, "Tag assumed-role sessions with the operator and run ID (trust policies must allow sts:TagSession)")
+	analyzeCmd.PersistentFlags().StringVar(&mfaCode, "mfa-code", "", "MFA token code (prompted for if --mfa-serial is set and this is empty)")
+
+	backfillCmd.Flags().StringVar(&backfillSource, "source", "", "Flow Logs log group name or s3://bucket/prefix (required)")
+	backfillCmd.Flags().StringVar(&backfillFrom, "from", "", "First day to analyze, YYYY-MM-DD (required)")
+	backfillCmd.Flags().StringVar(&backfillTo, "to", "", "Day to stop at (exclusive), YYYY-MM-DD (required)")
+	backfillCmd.Flags().StringVar(&ipRangesFile, "ip-ranges-file", "", "Classify traffic with this ip-ranges.json snapshot instead of the cached or downloaded one, for reproducible analyses")
+	backfillCmd.MarkFlagRequired("source")
+	backfillCmd.MarkFlagRequired("from")
+	backfillCmd.MarkFlagRequired("to")
+}
+
+func runBackfill(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	var opts ui.BackfillOptions
+	if err := ui.ParseBackfillSource(backfillSource, &opts); err != nil {
+		return err
+	}
+	from, err := time.Parse("2006-01-02", backfillFrom)
+	if err != nil {
+		return fmt.Errorf("invalid --from %q: expected YYYY-MM-DD", backfillFrom)
+	}
+	to, err := time.Parse("2006-01-02", backfillTo)
+	if err != nil {
+		return fmt.Errorf("invalid --to %q: expected YYYY-MM-DD", backfillTo)
+	}
+	opts.From, opts.To = from, to
+
+	if err := loadIPRanges(); err != nil {
+		return err
+	}
+
+	selectedProfile := getProfile()
+	selectedRegion, err := getRegion(selectedProfile)
+	if err != nil {
+		return err
+	}
+	opts.Region = selectedRegion
+
+	scannerOpts, err := scannerOptions()
+	if err != nil {
+		return err
+	}
+	scannerOpts = append(scannerOpts, core.WithReadOnly())
+
+	scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile, scannerOpts...)
+	if err != nil {
+		printAuthHelp(err)
+		return fmt.Errorf("failed to create scanner")
+	}
+
+	return ui.RunBackfill(ctx, scanner, opts)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var anomaliesCmd = &cobra.Command{
+	Use:   "anomalies",
+	Short: "List NAT Gateway cost anomalies from AWS Cost Anomaly Detection",
+	Long: `Lists the recent anomalies of the account's Cost Anomaly Detection monitor for
+AWS services that NAT Gateway usage in the region contributed to (usage types
+such as NatGateway-Bytes and NatGateway-Hours among the root causes), so scan
+results can be read against real billing events.
+
+Monitors cannot be narrowed to usage types, so the account-wide services
+monitor is used and its anomalies filtered. With --create-monitor, one is
+created when the account has none; it finds anomalies from the next day on.
+scan deep --cost-anomalies adds the same list to the report.
+
+Examples:
+  terminat anomalies --region us-east-1
+  terminat anomalies --region us-east-1 --days 30 --create-monitor`,
+	Args: cobra.NoArgs,
+	RunE: runAnomalies,
+}
+
+var (
+	anomaliesDays          int
+	anomaliesCreateMonitor bool
+	anomaliesFormat        string
+)
+
+func init() {
+	rootCmd.AddCommand(anomaliesCmd)
+
+	anomaliesCmd.Flags().StringVarP(&region, "region", "r", "", "AWS region (uses AWS_REGION env var if not specified)")
+	anomaliesCmd.Flags().StringVarP(&profile, "profile", "p", "", "AWS profile (uses AWS_PROFILE env var if not specified)")
+	anomaliesCmd.Flags().StringSliceVar(&assumeRoles, "assume-role", []string{}, "Role ARN(s) to assume in order after loading the profile (comma-separated for a chain)")
+	anomaliesCmd.Flags().StringVar(&mfaSerial, "mfa-serial", "", "MFA device ARN for the first --assume-role hop")
+	anomaliesCmd.Flags().BoolVar(&tagSession, "tag-session", false, "Tag assumed-role sessions with the operator and run ID (trust policies must allow sts:TagSession)")
+	anomaliesCmd.Flags().StringVar(&mfaCode, "mfa-code", "", "MFA token code (prompted for if --mfa-serial is set and this is empty)")
+	anomaliesCmd.Flags().IntVar(&anomaliesDays, "days", 90, "List the anomalies of the last N days")
+	anomaliesCmd.Flags().BoolVar(&anomaliesCreateMonitor, "create-monitor", false, "Create a Cost Anomaly Detection monitor for AWS services when the account has none")
+	anomaliesCmd.Flags().StringVar(&anomaliesFormat, "format", "text", "Output format [text|json]")
+}
+
+func runAnomalies(cmd *cobra.Command, args []string) error {
+	if anomaliesFormat != "text" && anomaliesFormat != "json" {
+		return fmt.Errorf("invalid --format %q (valid: text, json)", anomaliesFormat)
+	}
+	if anomaliesDays <= 0 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	selectedProfile := getProfile()
+	selectedRegion, err := getRegion(selectedProfile)
+	if err != nil {
+		return err
+	}
+	scannerOpts, err := scannerOptions()
+	if err != nil {
+		return err
+	}
+	scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile, scannerOpts...)
+	if err != nil {
+		printAuthHelp(err)
+		return fmt.Errorf("failed to create scanner")
+	}
+
+	monitor, err := scanner.CostAnomalyMonitor(ctx)
+	if err != nil {
+		return err
+	}
+	if monitor == "" {
+		if !anomaliesCreateMonitor {
+			return fmt.Errorf("the account has no Cost Anomaly Detection monitor for AWS services; rerun with --create-monitor to create one")
+		}
+		if monitor, err = scanner.CreateCostAnomalyMonitor(ctx); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "✓ Created cost anomaly monitor %q (%s); anomalies show up from tomorrow on\n", core.CostAnomalyMonitorName, monitor)
+	}
+
+	anomalies, err := scanner.NATCostAnomalies(ctx, monitor, time.Now().AddDate(0, 0, -anomaliesDays))
+	if err != nil {
+		return err
+	}
+	if anomaliesFormat == "json" {
+		data, err := json.MarshalIndent(anomalies, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(anomalies) == 0 {
+		fmt.Printf("No NAT Gateway cost anomalies in %s/%s in the last %d days\n", scanner.GetAccountID(), selectedRegion, anomaliesDays)
+		return nil
+	}
+	fmt.Printf("NAT Gateway cost anomalies in %s/%s, last %d days\n\n", scanner.GetAccountID(), selectedRegion, anomaliesDays)
+	for _, a := range anomalies {
+		end := a.EndDate
+		if end == "" {
+			end = "ongoing"
+		}
+		fmt.Printf("  %s to %-10s  +$%-9.2f expected $%.2f, actual $%.2f  %s\n", a.StartDate, end, a.TotalImpact, a.ExpectedSpend, a.ActualSpend, strings.Join(a.UsageTypes, ", "))
+	}
+	return nil
+}
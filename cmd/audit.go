@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/doitintl/terminator/internal/audit"
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/naming"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditLogPath string
+	tagSession   bool
+	// commandPath is the running command, e.g. "terminat scan deep", for the
+	// audit log.
+	commandPath string
+	// currentRunID identifies the run in session tags, created resources and
+	// the audit log. scannerOptions starts a new run for every scanner.
+	currentRunID string
+)
+
+// rememberCommand runs before every command.
+func rememberCommand(cmd *cobra.Command, args []string) error {
+	commandPath = cmd.CommandPath()
+	return loadConfig(cmd, args)
+}
+
+func newRunID() string {
+	p := namingPolicy
+	if p == nil {
+		p = naming.Default()
+	}
+	return p.RunID(time.Now().Unix())
+}
+
+// auditOptions stamp a run with who ran it: the Operator tag on created
+// resources, the audit log of mutating calls and, with --tag-session,
+// Operator and RunId tags on every assumed-role session.
+func auditOptions(roles []string) ([]core.Option, error) {
+	currentRunID = newRunID()
+	operator := audit.Operator()
+
+	path := auditLogPath
+	if path == "" {
+		var err error
+		if path, err = audit.DefaultPath(time.Now().UTC()); err != nil {
+			return nil, err
+		}
+	}
+	opts := []core.Option{
+		core.WithOperator(operator),
+		core.WithAuditLog(audit.New(path, currentRunID, operator, commandPath)),
+	}
+
+	if tagSession {
+		if len(roles) == 0 {
+			return nil, fmt.Errorf("--tag-session requires --assume-role")
+		}
+		opts = append(opts, core.WithSessionTags(map[string]string{
+			"Operator": operator,
+			"RunId":    currentRunID,
+		}))
+	}
+	return opts, nil
+}
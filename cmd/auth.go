@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/doitintl/terminator/internal/cassette"
+	"github.com/doitintl/terminator/internal/core"
+)
+
+var (
+	assumeRoles []string
+	mfaSerial   string
+	mfaCode     string
+	allowIMDS   bool
+	stsRegion   string
+	stsEndpoint string
+)
+
+// scannerOptions turns the --assume-role/--mfa-*, --read-only, --allow-imds,
+// --sts-*, --naming-policy and --duration flags, and
+// $TERMINAT_RECORD/$TERMINAT_REPLAY, into scanner options. Without
+// --mfa-code, or once it has been used, the token is prompted for on stdin
+// when needed.
+func scannerOptions() ([]core.Option, error) {
+	var opts []core.Option
+	if readOnly {
+		opts = append(opts, core.WithReadOnly())
+	}
+	if allowIMDS {
+		opts = append(opts, core.WithIMDS())
+	}
+	if stsRegion != "" || stsEndpoint != "" {
+		opts = append(opts, core.WithSTS(stsRegion, stsEndpoint))
+	}
+	if namingPolicy != nil && len(namingPolicy.Tags) > 0 {
+		opts = append(opts, core.WithResourceTags(namingPolicy.Tags))
+	}
+	opts = append(opts, core.WithResourceExpiry(resourceTTL(duration)))
+	rec, err := cassette.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if rec != nil {
+		opts = append(opts, core.WithCassette(rec))
+	}
+
+	var roles []string
+	for _, r := range assumeRoles {
+		if r = strings.TrimSpace(r); r != "" {
+			roles = append(roles, r)
+		}
+	}
+
+	auditOpts, err := auditOptions(roles)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, auditOpts...)
+
+	if len(roles) == 0 {
+		if mfaSerial != "" || mfaCode != "" {
+			return nil, fmt.Errorf("--mfa-serial and --mfa-code require --assume-role")
+		}
+		return opts, nil
+	}
+	if mfaCode != "" && mfaSerial == "" {
+		return nil, fmt.Errorf("--mfa-code requires --mfa-serial")
+	}
+
+	return append(opts, core.WithAssumeRoleChain(roles, mfaSerial, mfaTokenProvider)), nil
+}
+
+// mfaCodeUsed is set once --mfa-code has been handed to STS.
+var mfaCodeUsed atomic.Bool
+
+// mfaTokenProvider returns --mfa-code the first time it is called in this
+// process and prompts on stdin after that. An MFA code is single-use, so a
+// refresh mid-scan, the next --profiles entry or the next serve run has to
+// ask for a fresh one rather than replay it to STS.
+func mfaTokenProvider() (string, error) {
+	if mfaCode == "" || mfaCodeUsed.Swap(true) {
+		return stscreds.StdinTokenProvider()
+	}
+	return mfaCode, nil
+}
+
+// resourceTTL is how long the Flow Logs and log group of a scan collecting
+// for minutes live before cleanup --expired may delete them.
+func resourceTTL(minutes int) time.Duration {
+	return time.Duration(minutes)*time.Minute + core.ExpiryGrace
+}
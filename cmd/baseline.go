@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/doitintl/terminator/internal/baseline"
+	"github.com/doitintl/terminator/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage the findings the team has accepted",
+	Long: `The findings baseline lists accepted findings, which deep scans hide.
+An entry matches a finding type, optionally narrowed to an account, region,
+VPC and service.
+
+With a shared backend (the [history] section of the config), pull the team's
+baseline before changing it and push it afterwards, so everyone running scans
+and CI hide the same findings. A push is refused when someone else pushed in
+between.
+
+Examples:
+  terminat baseline pull
+  terminat baseline accept --type missing-endpoint --vpc-id vpc-0abc --service DynamoDB \
+    --reason "traffic moves to the new VPC in Q3"
+  terminat baseline push`,
+}
+
+var baselinePullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Replace the local baseline with the team's",
+	Args:  cobra.NoArgs,
+	RunE:  runBaselinePull,
+}
+
+var baselinePushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Publish the local baseline to the team",
+	Args:  cobra.NoArgs,
+	RunE:  runBaselinePush,
+}
+
+var baselineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the accepted findings",
+	Args:  cobra.NoArgs,
+	RunE:  runBaselineList,
+}
+
+var baselineAcceptCmd = &cobra.Command{
+	Use:   "accept",
+	Short: "Accept a finding so scans stop reporting it",
+	Args:  cobra.NoArgs,
+	RunE:  runBaselineAccept,
+}
+
+var baselineRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Remove an accepted finding",
+	Args:  cobra.NoArgs,
+	RunE:  runBaselineRevoke,
+}
+
+var (
+	baselineFile      string
+	baselineForce     bool
+	baselineAccountID string
+	baselineRegion    string
+	baselineType      string
+	baselineVPCID     string
+	baselineService   string
+	baselineReason    string
+)
+
+func init() {
+	rootCmd.AddCommand(baselineCmd)
+	baselineCmd.AddCommand(baselinePullCmd, baselinePushCmd, baselineListCmd, baselineAcceptCmd, baselineRevokeCmd)
+
+	baselineCmd.PersistentFlags().StringVar(&baselineFile, "file", "", "Baseline file (default: baseline.json in the state directory)")
+	baselinePullCmd.Flags().BoolVar(&baselineForce, "force", false, "Discard local changes that were never pushed")
+	for _, c := range []*cobra.Command{baselineAcceptCmd, baselineRevokeCmd} {
+		c.Flags().StringVar(&baselineType, "type", "", "Finding type, e.g. missing-endpoint (required)")
+		c.Flags().StringVar(&baselineAccountID, "account-id", "", "Only in this account (default: any)")
+		c.Flags().StringVarP(&baselineRegion, "region", "r", "", "Only in this region (default: any)")
+		c.Flags().StringVar(&baselineVPCID, "vpc-id", "", "Only in this VPC (default: any)")
+		c.Flags().StringVar(&baselineService, "service", "", "Only for this service, e.g. S3 (default: any)")
+		c.MarkFlagRequired("type")
+	}
+	baselineAcceptCmd.Flags().StringVar(&baselineReason, "reason", "", "Why the finding is accepted (required)")
+	baselineAcceptCmd.MarkFlagRequired("reason")
+}
+
+func baselinePath() (string, error) {
+	if baselineFile != "" {
+		return baselineFile, nil
+	}
+	return baseline.DefaultPath()
+}
+
+func openSharedBaseline(ctx context.Context) (history.Shared, error) {
+	cfg, err := history.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history config: %w", err)
+	}
+	return history.OpenShared(ctx, cfg)
+}
+
+func runBaselinePull(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	path, err := baselinePath()
+	if err != nil {
+		return err
+	}
+	local, err := baseline.Load(path)
+	if err != nil {
+		return err
+	}
+	shared, err := openSharedBaseline(ctx)
+	if err != nil {
+		return err
+	}
+	team, err := baseline.Pull(ctx, shared)
+	if err != nil {
+		return err
+	}
+
+	if !baselineForce && local.Revision == team.Revision && !sameBaseline(local, team) {
+		return fmt.Errorf("%s has changes that were never pushed: push them, or pull with --force to discard them", path)
+	}
+	if err := team.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Pulled revision %d (%d accepted finding(s)) to %s\n", team.Revision, len(team.Accepted), path)
+	return nil
+}
+
+func runBaselinePush(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	path, err := baselinePath()
+	if err != nil {
+		return err
+	}
+	local, err := baseline.Load(path)
+	if err != nil {
+		return err
+	}
+	shared, err := openSharedBaseline(ctx)
+	if err != nil {
+		return err
+	}
+	if err := baseline.Push(ctx, shared, local); err != nil {
+		return err
+	}
+	if err := local.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Pushed revision %d (%d accepted finding(s))\n", local.Revision, len(local.Accepted))
+	return nil
+}
+
+func runBaselineList(cmd *cobra.Command, args []string) error {
+	path, err := baselinePath()
+	if err != nil {
+		return err
+	}
+	b, err := baseline.Load(path)
+	if err != nil {
+		return err
+	}
+	if len(b.Accepted) == 0 {
+		fmt.Printf("No accepted findings in %s\n", path)
+		return nil
+	}
+	fmt.Printf("%s (revision %d)\n\n", path, b.Revision)
+	for _, a := range b.Accepted {
+		fmt.Printf("  %-26s account=%s region=%s vpc=%s service=%s\n", a.Type, orAny(a.AccountID), orAny(a.Region), orAny(a.VPCID), orAny(a.Service))
+		fmt.Printf("    %s (%s, %s)\n", a.Reason, a.AcceptedBy, a.AcceptedAt.Format("2006-01-02"))
+	}
+	return nil
+}
+
+func runBaselineAccept(cmd *cobra.Command, args []string) error {
+	path, err := baselinePath()
+	if err != nil {
+		return err
+	}
+	b, err := baseline.Load(path)
+	if err != nil {
+		return err
+	}
+	a := baselineEntry()
+	a.Reason = baselineReason
+	a.AcceptedAt = time.Now().UTC()
+	if u, err := user.Current(); err == nil {
+		a.AcceptedBy = u.Username
+	}
+	b.Accept(a)
+	if err := b.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Accepted %s in %s (push to share it)\n", a.Type, path)
+	return nil
+}
+
+func runBaselineRevoke(cmd *cobra.Command, args []string) error {
+	path, err := baselinePath()
+	if err != nil {
+		return err
+	}
+	b, err := baseline.Load(path)
+	if err != nil {
+		return err
+	}
+	if b.Revoke(baselineEntry()) == 0 {
+		return fmt.Errorf("no accepted %s finding with that scope in %s", baselineType, path)
+	}
+	if err := b.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Revoked %s in %s (push to share it)\n", baselineType, path)
+	return nil
+}
+
+func baselineEntry() baseline.Accepted {
+	return baseline.Accepted{
+		AccountID: baselineAccountID,
+		Region:    baselineRegion,
+		Type:      baselineType,
+		VPCID:     baselineVPCID,
+		Service:   baselineService,
+	}
+}
+
+func sameBaseline(a, b *baseline.Baseline) bool {
+	x, errX := a.Encode()
+	y, errY := b.Encode()
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
+
+func orAny(s string) string {
+	if s == "" {
+		return "*"
+	}
+	return s
+}
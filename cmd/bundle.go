@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/doitintl/terminator/internal/bundle"
+	"github.com/spf13/cobra"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Package the last deep scan into a redacted diagnostic archive",
+	Long: `Zip the report and raw Logs Insights query results of the last stream deep scan,
+together with the termiNATor version and environment info, for support requests
+and GitHub issues.
+
+Account IDs, resource IDs, names and IP addresses are redacted in every file.`,
+	RunE: runBundle,
+}
+
+var (
+	bundleOutput string
+	bundleFrom   string
+)
+
+// bundleEnvVars are reported by name only; their values may identify the environment.
+var bundleEnvVars = []string{"AWS_PROFILE", "AWS_ACCESS_KEY_ID", "AWS_ROLE_ARN", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_CONFIG_FILE", "AWS_SHARED_CREDENTIALS_FILE", "DOIT_DATAHUB_API_KEY"}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "Archive path (default terminat-bundle-<timestamp>.zip)")
+	bundleCmd.Flags().StringVar(&bundleFrom, "from", "", "Directory with scan artifacts (default: where the last deep scan kept them)")
+}
+
+func runBundle(cmd *cobra.Command, args []string) error {
+	dir := bundleFrom
+	if dir == "" {
+		var err error
+		if dir, err = bundle.DefaultDir(); err != nil {
+			return err
+		}
+	}
+
+	output := bundleOutput
+	if output == "" {
+		output = fmt.Sprintf("terminat-bundle-%s.zip", time.Now().Format("20060102-150405"))
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := bundle.Write(f, dir, bundleInfo()); err != nil {
+		f.Close()
+		os.Remove(output)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	absPath, _ := filepath.Abs(output)
+	if absPath == "" {
+		absPath = output
+	}
+	fmt.Printf("✓ Diagnostic bundle saved: %s\n", absPath)
+	fmt.Println("  Account IDs, resource IDs, names and IP addresses are redacted; review it before attaching it to an issue.")
+	return nil
+}
+
+func bundleInfo() bundle.Info {
+	env := map[string]string{
+		"os":         runtime.GOOS,
+		"arch":       runtime.GOARCH,
+		"go_version": runtime.Version(),
+		"AWS_REGION": os.Getenv("AWS_REGION"),
+		"TERM":       os.Getenv("TERM"),
+	}
+	for _, name := range bundleEnvVars {
+		state := "unset"
+		if os.Getenv(name) != "" {
+			state = "set"
+		}
+		env[name] = state
+	}
+	return bundle.Info{Version: version, Environment: env}
+}
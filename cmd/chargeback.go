@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/doitintl/terminator/internal/chargeback"
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var chargebackCmd = &cobra.Command{
+	Use:   "chargeback <report.json>...",
+	Short: "Export NAT cost and savings per owning team as CSV",
+	Long: `Splits the projected NAT Gateway cost and gateway endpoint savings of deep
+scan JSON reports by VPC, keyed by a tag on the NAT Gateways, so platform
+teams can bill NAT waste back to the teams that own it. The tag is
+--tag-key, tag_key in the [chargeback] section of the config, or CostCenter.
+
+Gateways without the tag are billed to (untagged). Traffic that could only
+be measured across gateways of different owners is billed to (mixed).
+
+Reports can be stored scans, history:<id> (see terminat history).
+
+Examples:
+  terminat chargeback scan-prod.json scan-staging.json -o chargeback.csv
+  terminat chargeback history:123456789012-us-east-1-terminat-1717243200 --tag-key Team --group-by tag`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runChargeback,
+}
+
+var (
+	chargebackTagKey  string
+	chargebackGroupBy string
+	chargebackOutput  string
+)
+
+func init() {
+	rootCmd.AddCommand(chargebackCmd)
+	chargebackCmd.Flags().StringVar(&chargebackTagKey, "tag-key", "", "NAT Gateway tag naming the owner (default: [chargeback] tag_key, or CostCenter)")
+	chargebackCmd.Flags().StringVar(&chargebackGroupBy, "group-by", "vpc", "One line per owner and VPC, or per owner [vpc|tag]")
+	chargebackCmd.Flags().StringVarP(&chargebackOutput, "output", "o", "", "Write the CSV to this file (default: stdout)")
+}
+
+func runChargeback(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if chargebackGroupBy != "vpc" && chargebackGroupBy != "tag" {
+		return fmt.Errorf("invalid --group-by value %q (valid: vpc, tag)", chargebackGroupBy)
+	}
+	tagKey := chargebackTagKey
+	if tagKey == "" {
+		tagKey = chargeback.TagKey()
+	}
+
+	reports := make([]*report.Report, 0, len(args))
+	for _, arg := range args {
+		rep, err := loadReport(ctx, arg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+		reports = append(reports, rep)
+	}
+
+	lines := chargeback.Build(reports, tagKey)
+	if chargebackGroupBy == "tag" {
+		lines = chargeback.ByOwner(lines)
+	}
+
+	var w io.Writer = os.Stdout
+	if chargebackOutput != "" {
+		f, err := os.Create(chargebackOutput)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := chargeback.WriteCSV(w, tagKey, lines); err != nil {
+		return err
+	}
+	if chargebackOutput != "" {
+		fmt.Fprintf(os.Stderr, "✓ Wrote %d chargeback line(s) keyed by %s to %s\n", len(lines), tagKey, chargebackOutput)
+	}
+	return nil
+}
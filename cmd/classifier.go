@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/spf13/cobra"
+)
+
+var classifierCmd = &cobra.Command{
+	Use:   "classifier",
+	Short: "Check the traffic classifier",
+}
+
+var classifierTestCmd = &cobra.Command{
+	Use:   "test <corpus.jsonl>",
+	Short: "Report the classifier's accuracy on a labeled corpus of addresses",
+	Long: `Runs a labeled corpus of addresses through the classifier deep scans use
+and reports how many it classifies as labeled, per service, and the ones it
+gets wrong. Run it after AWS publishes new IP ranges or the classification
+changes, to see what moved.
+
+Each line of the corpus is a JSON object; blank lines and lines starting
+with # are skipped:
+
+  {"ip": "52.216.8.1", "expected": "s3", "note": "us-east-1 bucket"}
+  {"ip": "8.8.8.8", "expected": "other"}
+
+expected is s3, dynamodb, ecr or other.
+
+Examples:
+  terminat classifier test corpus.jsonl
+  terminat classifier test corpus.jsonl --ip-ranges-file ip-ranges-2024-06.json --min-accuracy 99`,
+	Args: cobra.ExactArgs(1),
+	RunE: runClassifierTest,
+}
+
+var (
+	classifierMinAccuracy float64
+	classifierJSON        bool
+)
+
+func init() {
+	rootCmd.AddCommand(classifierCmd)
+	classifierCmd.AddCommand(classifierTestCmd)
+	classifierTestCmd.Flags().StringVar(&ipRangesFile, "ip-ranges-file", "", "Classify with this ip-ranges.json snapshot instead of the cached or downloaded one")
+	classifierTestCmd.Flags().Float64Var(&classifierMinAccuracy, "min-accuracy", 0, "Exit non-zero when accuracy is below this percentage (for CI)")
+	classifierTestCmd.Flags().BoolVar(&classifierJSON, "json", false, "Print the result as JSON")
+}
+
+func runClassifierTest(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	entries, err := analysis.ReadCorpus(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("%s has no entries", args[0])
+	}
+	if err := loadIPRanges(); err != nil {
+		return err
+	}
+	classifier, err := analysis.NewTrafficClassifier()
+	if err != nil {
+		return err
+	}
+	result := classifier.TestCorpus(entries)
+
+	if classifierJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Accuracy: %.1f%% (%d of %d)\n\n", result.Accuracy(), result.Correct, result.Total)
+		fmt.Printf("  %-10s %8s %10s %9s %7s\n", "Service", "Expected", "Classified", "Precision", "Recall")
+		for _, s := range result.Services {
+			fmt.Printf("  %-10s %8d %10d %8.1f%% %6.1f%%\n", s.Service, s.Expected, s.Classified, s.Precision(), s.Recall())
+		}
+		if len(result.Misses) > 0 {
+			fmt.Println("\nMisclassified:")
+		}
+		for _, m := range result.Misses {
+			line := fmt.Sprintf("  %-39s expected %-8s got %s", m.IP, m.Expected, m.Got)
+			if m.Note != "" {
+				line += "  # " + m.Note
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if result.Accuracy() < classifierMinAccuracy {
+		return fmt.Errorf("accuracy %.1f%% is below --min-accuracy %.1f%%", result.Accuracy(), classifierMinAccuracy)
+	}
+	return nil
+}
@@ -3,8 +3,10 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/units"
 	"github.com/spf13/cobra"
 )
 
@@ -18,34 +20,62 @@ This command validates that:
 2. Data has been collected (not empty)
 3. User confirms deletion
 
-Log groups incur storage costs (~$0.50/GB/month), so cleanup after analysis is recommended.`,
+Log groups incur storage costs (~$0.50/GB/month), so cleanup after analysis is recommended.
+
+With --expired, it instead deletes every Flow Log and log group termiNATor
+created in the region whose ExpiresAt tag has passed, such as those of a
+scan that was killed before cleaning up. Scans tag their resources to
+expire 6 hours after the collection window; kept log groups are retagged
+to never expire, or to the --expire-kept-log-group date.
+
+Examples:
+  terminat cleanup --region us-east-1 --log-group /aws/vpc/flowlogs/terminat-1700000000
+  terminat cleanup --region us-east-1 --expired --force`,
 	RunE: runCleanup,
 }
 
 var (
-	logGroupName  string
-	force         bool
-	cleanupRegion string
+	logGroupName   string
+	force          bool
+	cleanupRegion  string
+	cleanupExpired bool
 )
 
 func init() {
 	rootCmd.AddCommand(cleanupCmd)
-	cleanupCmd.Flags().StringVar(&logGroupName, "log-group", "", "Log group name to delete (required)")
+	cleanupCmd.Flags().StringVar(&logGroupName, "log-group", "", "Log group name to delete (required unless --expired)")
+	cleanupCmd.Flags().BoolVar(&cleanupExpired, "expired", false, "Delete every termiNATor Flow Log and log group past its ExpiresAt tag")
 	cleanupCmd.Flags().BoolVar(&force, "force", false, "Skip confirmation prompt")
 	cleanupCmd.Flags().StringVarP(&cleanupRegion, "region", "r", "", "AWS region (required)")
-	cleanupCmd.MarkFlagRequired("log-group")
+	cleanupCmd.Flags().StringSliceVar(&assumeRoles, "assume-role", []string{}, "Role ARN(s) to assume in order (comma-separated for a chain)")
+	cleanupCmd.Flags().StringVar(&mfaSerial, "mfa-serial", "", "MFA device ARN for the first --assume-role hop")
+	cleanupCmd.Flags().BoolVar(&tagSession, "tag-session", false, "Tag assumed-role sessions with the operator and run ID (trust policies must allow sts:TagSession)")
+	cleanupCmd.Flags().StringVar(&mfaCode, "mfa-code", "", "MFA token code (prompted for if --mfa-serial is set and this is empty)")
 	cleanupCmd.MarkFlagRequired("region")
 }
 
 func runCleanup(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
+
+	if cleanupExpired == (logGroupName != "") {
+		return fmt.Errorf("set either --log-group or --expired")
+	}
+
+	opts, err := scannerOptions()
+	if err != nil {
+		return err
+	}
 
 	// Initialize scanner (no profile needed for cleanup)
-	scanner, err := core.NewScanner(ctx, cleanupRegion, "")
+	scanner, err := core.NewScanner(ctx, cleanupRegion, "", opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create scanner: %w", err)
 	}
 
+	if cleanupExpired {
+		return cleanupExpiredResources(ctx, scanner)
+	}
+
 	// Validate log group exists and get stats
 	stats, err := scanner.GetLogGroupStats(ctx, logGroupName)
 	if err != nil {
@@ -64,8 +94,8 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 
 	// Display stats
 	fmt.Printf("Log Group: %s\n", logGroupName)
-	fmt.Printf("Storage: %.2f MB\n", float64(stats.StoredBytes)/(1024*1024))
-	fmt.Printf("Estimated monthly cost: $%.4f\n", float64(stats.StoredBytes)/(1024*1024*1024)*0.50)
+	fmt.Printf("Storage: %s\n", units.Format(stats.StoredBytes))
+	fmt.Printf("Estimated monthly cost: $%.4f\n", units.BillingGB(stats.StoredBytes)*0.50)
 	fmt.Printf("Log streams: %d\n", stats.LogStreams)
 	fmt.Println()
 
@@ -88,3 +118,47 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 	fmt.Printf("✓ Log group deleted: %s\n", logGroupName)
 	return nil
 }
+
+// cleanupExpiredResources deletes the termiNATor resources past their
+// ExpiresAt tag, after confirmation unless --force.
+func cleanupExpiredResources(ctx context.Context, scanner *core.Scanner) error {
+	expired, err := scanner.FindExpiredResources(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to find expired resources: %w", err)
+	}
+	if expired.Empty() {
+		fmt.Println("No expired termiNATor resources")
+		return nil
+	}
+
+	for _, id := range expired.FlowLogIDs {
+		fmt.Printf("Flow Log:  %s\n", id)
+	}
+	for _, stack := range expired.Stacks {
+		fmt.Printf("Stack:     %s\n", stack)
+	}
+	for _, name := range expired.LogGroups {
+		fmt.Printf("Log Group: %s\n", name)
+	}
+	fmt.Println()
+
+	if !force {
+		fmt.Print("Delete these expired resources? (yes/no): ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "yes" {
+			fmt.Println("Cleanup cancelled")
+			return nil
+		}
+	}
+
+	skipped, err := scanner.DeleteExpiredResources(ctx, expired)
+	for _, name := range skipped {
+		fmt.Printf("⚠️  Kept log group %s: Flow Logs that haven't expired still write to it\n", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete expired resources: %w", err)
+	}
+	fmt.Printf("✓ Deleted %d Flow Log(s), %d stack(s) and %d log group(s)\n", len(expired.FlowLogIDs), len(expired.Stacks), len(expired.LogGroups)-len(skipped))
+	return nil
+}
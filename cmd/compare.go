@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <before.json> <after.json>",
+	Short: "List configuration drift between two deep scan reports",
+	Long: `Compares the NAT Gateways and VPC inventory of two JSON reports of the same
+account and region: VPC endpoints added or removed, endpoint route table
+associations, routes and subnet associations changed, NAT Gateways created.
+
+Changes that undo NAT cost remediation, such as a deleted gateway endpoint
+or a route table that lost its endpoint route, are marked as regressions.
+
+Both reports need inventory:
+  terminat scan deep --region us-east-1 --export json --include-inventory -o scan.json
+
+Either report can be a stored scan, history:<id> (see terminat history).
+
+Example:
+  terminat compare scan-2024-01.json scan-2024-06.json --fail-on-regression`,
+	Args: cobra.ExactArgs(2),
+	RunE: runCompare,
+}
+
+var (
+	compareFailOnRegression bool
+	compareJSON             bool
+)
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+	compareCmd.Flags().BoolVar(&compareFailOnRegression, "fail-on-regression", false, "Exit non-zero when a change undoes NAT cost remediation (for CI)")
+	compareCmd.Flags().BoolVar(&compareJSON, "json", false, "Print the changes as JSON")
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	before, err := loadReport(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	after, err := loadReport(ctx, args[1])
+	if err != nil {
+		return err
+	}
+	changes, err := report.Drift(before, after)
+	if err != nil {
+		return err
+	}
+
+	regressions := 0
+	for _, c := range changes {
+		if c.Regression {
+			regressions++
+		}
+	}
+
+	if compareJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(changes); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Drift in %s/%s between %s and %s\n\n", after.AccountID, after.Region,
+			before.GeneratedAt.Format("2006-01-02 15:04"), after.GeneratedAt.Format("2006-01-02 15:04"))
+		if len(changes) == 0 {
+			fmt.Println("No configuration drift.")
+		}
+		for _, c := range changes {
+			fmt.Println("  " + c.String())
+		}
+		if len(changes) > 0 {
+			fmt.Printf("\n%d change(s), %d regression(s)\n", len(changes), regressions)
+		}
+	}
+
+	if compareFailOnRegression && regressions > 0 {
+		return fmt.Errorf("%d regression(s) found", regressions)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/doitintl/terminator/internal/config"
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var configSource string
+
+// loadConfig reads --config, a file or ssm:///parameter-name, in place of
+// ~/.terminat/config.toml, and applies its [scan] defaults to the scan
+// commands.
+func loadConfig(cmd *cobra.Command, args []string) error {
+	if configSource != "" {
+		content, err := readConfigSource(cmd.Context(), configSource)
+		if err != nil {
+			return fmt.Errorf("failed to read --config %s: %w", configSource, err)
+		}
+		config.Use(content)
+	}
+	if !isScanCommand(cmd) {
+		return nil
+	}
+	content, err := config.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	return applyScanDefaults(cmd, config.Section(content, "scan"))
+}
+
+func readConfigSource(ctx context.Context, source string) (string, error) {
+	name, ok := config.SSMParameter(source)
+	if !ok {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	selectedProfile := getProfile()
+	selectedRegion, err := getRegion(selectedProfile)
+	if err != nil {
+		return "", err
+	}
+	opts, err := scannerOptions()
+	if err != nil {
+		return "", err
+	}
+	return core.GetParameter(ctx, selectedRegion, selectedProfile, name, opts...)
+}
+
+func scanFlagExists(name string) bool {
+	if scanCmd.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, c := range scanCmd.Commands() {
+		if c.Flags().Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func isScanCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == scanCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// applyScanDefaults sets each [scan] key as the default of the flag of the
+// same name, e.g. min-savings = 25 or exclude-vpc-ids = ["vpc-1"]. Flags
+// given on the command line win, and keys for flags another scan command
+// defines are skipped.
+func applyScanDefaults(cmd *cobra.Command, defaults map[string]string) error {
+	keys := make([]string, 0, len(defaults))
+	for k := range defaults {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if key == "config" {
+			return fmt.Errorf("config: [scan] cannot set config")
+		}
+		f := cmd.Flags().Lookup(key)
+		if f == nil {
+			if !scanFlagExists(key) {
+				fmt.Fprintf(os.Stderr, "⚠️  Ignoring unknown [scan] setting %q in config\n", key)
+			}
+			continue
+		}
+		if f.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(key, defaults[key]); err != nil {
+			return fmt.Errorf("config: [scan] %s: %w", key, err)
+		}
+	}
+	return nil
+}
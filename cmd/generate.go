@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/doitintl/terminator/internal/config"
+	"github.com/doitintl/terminator/internal/k8s"
+	"github.com/spf13/cobra"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate deployment files from the config",
+}
+
+var generateK8sCmd = &cobra.Command{
+	Use:   "k8s [-- extra terminat flags]",
+	Short: "Print Kubernetes manifests that run terminat serve or watch",
+	Long: `Prints the Kubernetes manifests that run terminat in a cluster, with
+~/.terminat/config.toml (or --config) mounted from a ConfigMap:
+
+  --mode serve   a Deployment running terminat serve, and a Service for its API
+  --mode watch   a CronJob running terminat watch --once on --schedule
+
+Both run as a ServiceAccount annotated with --role-arn for IAM Roles for
+Service Accounts (IRSA), and take environment variables such as
+DOIT_DATAHUB_API_KEY, JIRA_API_TOKEN and the token_env variables of
+[serve.token.<name>] sections from the optional --env-secret Secret.
+Arguments after -- are passed to terminat.
+
+Credentials are kept out of the ConfigMap: [notify.<name>] settings such
+as routing_key, secret or header.Authorization become <key>_env lines, and
+the DataHub and Jira keys are dropped, with their values in a
+<name>-credentials Secret the pods read as environment variables. The
+Secret is part of the output, so keep it out of version control. Credentials
+that cannot be moved are listed on stderr.
+
+Examples:
+  terminat generate k8s --mode serve --image registry.example.com/terminat:v1.0.0 \
+    --role-arn arn:aws:iam::123456789012:role/Terminator --region us-east-1 | kubectl apply -f -
+  terminat generate k8s --mode watch --image registry.example.com/terminat:v1.0.0 \
+    --schedule "*/15 * * * *" --state-pvc terminat-state -- --max-gb 50 --alert oncall`,
+	RunE: runGenerateK8s,
+}
+
+var generateK8s = k8s.Options{}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+	generateCmd.AddCommand(generateK8sCmd)
+
+	f := generateK8sCmd.Flags()
+	f.StringVar(&generateK8s.Mode, "mode", k8s.ModeServe, "What to run [serve|watch]")
+	f.StringVar(&generateK8s.Image, "image", "", "Container image with the terminat binary (required)")
+	f.StringVar(&generateK8s.Name, "name", "terminat", "Name of the generated resources")
+	f.StringVarP(&generateK8s.Namespace, "namespace", "n", "terminat", "Namespace of the generated resources")
+	f.StringVar(&generateK8s.RoleARN, "role-arn", "", "IAM role the ServiceAccount assumes through IRSA")
+	f.StringVarP(&generateK8s.Region, "region", "r", "", "AWS region to scan (the pod's AWS_REGION otherwise)")
+	f.StringVar(&generateK8s.Schedule, "schedule", "0 * * * *", "CronJob schedule of watch --once")
+	f.IntVar(&generateK8s.Port, "port", 8080, "Port terminat serve listens on")
+	f.StringVar(&generateK8s.EnvSecret, "env-secret", "", "Secret whose keys become environment variables")
+	f.StringVar(&generateK8s.StatePVC, "state-pvc", "", "PersistentVolumeClaim for the state directory (an emptyDir otherwise)")
+}
+
+func runGenerateK8s(cmd *cobra.Command, args []string) error {
+	content, err := config.Read()
+	if err != nil {
+		return err
+	}
+	opts := generateK8s
+	opts.Config, opts.Credentials = k8s.MoveCredentials(content)
+	opts.Args = args
+	if opts.Image == "" {
+		return fmt.Errorf("--image is required: terminat publishes no container image")
+	}
+	if err := k8s.Render(cmd.OutOrStdout(), opts); err != nil {
+		return err
+	}
+	if len(opts.Credentials) > 0 {
+		fmt.Fprintf(os.Stderr, "🔐 Moved %d credential(s) from the config into the %s-credentials Secret; the output holds their values, so keep it out of version control:\n", len(opts.Credentials), opts.Name)
+		for _, c := range opts.Credentials {
+			fmt.Fprintf(os.Stderr, "   [%s] %s -> %s\n", c.Section, c.Key, c.Env)
+		}
+	}
+	if found := k8s.Credentials(opts.Config); len(found) > 0 {
+		fmt.Fprintln(os.Stderr, "⚠️  The ConfigMap holds credentials; anyone who can read ConfigMaps in the namespace can read them:")
+		for _, key := range found {
+			fmt.Fprintf(os.Stderr, "   %s\n", key)
+		}
+	}
+	return nil
+}
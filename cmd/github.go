@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/doitintl/terminator/internal/github"
+	"github.com/doitintl/terminator/internal/redact"
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var githubCmd = &cobra.Command{
+	Use:   "github",
+	Short: "GitHub integration for CI runs",
+}
+
+var githubCommentCmd = &cobra.Command{
+	Use:   "comment",
+	Short: "Post or update a pull request comment summarizing a JSON report",
+	Long: `Summarizes a deep scan JSON report as a pull request comment: projected NAT
+cost, savings potential and findings. With --baseline (for example a report
+from the environment of the base branch) the comment shows the cost change
+and which findings the pull request adds or fixes.
+
+Run inside GitHub Actions on a pull_request event, with GITHUB_TOKEN in the
+step environment and pull-requests: write permission. Reruns edit the same
+comment.
+
+Example:
+  terminat scan deep --region us-east-1 --auto-approve --auto-cleanup --export json -o preview.json
+  terminat github comment --report preview.json --baseline main.json`,
+	RunE: runGitHubComment,
+}
+
+var (
+	githubReport   string
+	githubBaseline string
+	githubPR       int
+	githubRedact   bool
+)
+
+func init() {
+	rootCmd.AddCommand(githubCmd)
+	githubCmd.AddCommand(githubCommentCmd)
+
+	githubCommentCmd.Flags().StringVar(&githubReport, "report", "", "JSON report of this change's environment (required)")
+	githubCommentCmd.Flags().StringVar(&githubBaseline, "baseline", "", "JSON report to compare against, or history:<id> (optional)")
+	githubCommentCmd.Flags().IntVar(&githubPR, "pr", 0, "Pull request number (default: from the workflow event)")
+	githubCommentCmd.Flags().BoolVar(&githubRedact, "redact", false, "Obfuscate account IDs, resource IDs, names and IP addresses in the comment (for public repositories)")
+	githubCommentCmd.MarkFlagRequired("report")
+}
+
+func runGitHubComment(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	rep, err := report.Load(githubReport)
+	if err != nil {
+		return err
+	}
+	var baseline *report.Report
+	if githubBaseline != "" {
+		if baseline, err = loadReport(ctx, githubBaseline); err != nil {
+			return err
+		}
+	}
+
+	gh, err := github.FromEnv(githubPR)
+	if err != nil {
+		return err
+	}
+
+	body := rep.PRComment(baseline, gh.RunURL)
+	if githubRedact {
+		red, err := redact.New(rep.AccountID)
+		if err != nil {
+			return err
+		}
+		body = red.WithNames(rep.Names()...).String(body)
+	}
+
+	if _, err := github.UpsertComment(ctx, gh, report.CommentMarker, body); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Commented on %s#%d\n", gh.Repo, gh.PR)
+	return nil
+}
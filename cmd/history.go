@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/history"
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List and fetch the reports of earlier deep scans",
+	Long: `Every deep scan keeps its JSON report in the scan history (disable with
+--history=false). The history is local to this machine unless the [history]
+section of the config points at an S3 bucket for the reports and a DynamoDB
+table indexing them, which the whole team then shares:
+
+  [history]
+  bucket = "finops-terminat"
+  prefix = "history/"
+  table = "terminat-history"
+
+Stored reports can be compared directly:
+  terminat compare history:<id> history:<id>`,
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored scans, newest first",
+	RunE:  runHistoryList,
+}
+
+var historyGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Print or save the JSON report of a stored scan",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runHistoryGet,
+}
+
+var (
+	historyAccountID string
+	historyRegion    string
+	historyLimit     int
+	historyOutput    string
+)
+
+// historyRef prefixes a report argument that names a stored run.
+const historyRef = "history:"
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyGetCmd)
+	historyListCmd.Flags().StringVar(&historyAccountID, "account-id", "", "Only list scans of this account (required for the shared history)")
+	historyListCmd.Flags().StringVarP(&historyRegion, "region", "r", "", "Only list scans of this region (required for the shared history)")
+	historyListCmd.Flags().IntVar(&historyLimit, "limit", 20, "Maximum number of scans to list (0 = all)")
+	historyGetCmd.Flags().StringVarP(&historyOutput, "output", "o", "", "Save the report to this file instead of printing it")
+}
+
+func openHistory(ctx context.Context) (history.Store, error) {
+	cfg, err := history.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history config: %w", err)
+	}
+	return history.Open(ctx, cfg)
+}
+
+func runHistoryList(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	store, err := openHistory(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := store.List(ctx, historyAccountID, historyRegion, historyLimit)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No scans in %s\n", store.Location())
+		return nil
+	}
+	fmt.Printf("%-50s %-16s %12s %12s  %s\n", "ID", "GENERATED", "NAT/MONTH", "SAVINGS", "HOST")
+	for _, e := range entries {
+		fmt.Printf("%-50s %-16s %12s %12s  %s\n", e.ID(), e.GeneratedAt.Local().Format("2006-01-02 15:04"),
+			fmt.Sprintf("$%.2f", e.MonthlyNATCost), fmt.Sprintf("$%.2f", e.MonthlySavings), e.Host)
+	}
+	return nil
+}
+
+func runHistoryGet(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	store, err := openHistory(ctx)
+	if err != nil {
+		return err
+	}
+	rep, err := store.Get(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if historyOutput != "" {
+		if err := rep.SaveJSON(historyOutput); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "✓ Report saved to %s\n", historyOutput)
+		return nil
+	}
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// loadReport reads a report argument: a file, or history:<id> for a scan in
+// the history.
+func loadReport(ctx context.Context, arg string) (*report.Report, error) {
+	id, ok := strings.CutPrefix(arg, historyRef)
+	if !ok {
+		return report.Load(arg)
+	}
+	store, err := openHistory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Get(ctx, id)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/iampolicy"
+	"github.com/doitintl/terminator/internal/naming"
+	"github.com/spf13/cobra"
+)
+
+var (
+	iamPolicyMode       string
+	iamPolicyNamePrefix string
+)
+
+var iamPolicyCmd = &cobra.Command{
+	Use:   "iam-policy",
+	Short: "Print the least-privilege IAM policy for a scan mode",
+	Long: `Prints the IAM policy JSON with exactly the permissions terminat uses in the
+chosen mode:
+
+  quick      configuration-only scan (scan quick)
+  read-only  scan deep --read-only (metrics and existing log groups)
+  deep       scan deep and cleanup (creates Flow Logs and log groups)
+  deep-cloudformation
+             scan deep --provision-via cloudformation
+  apply      running the VPC endpoint remediation commands from the report
+  verify     verify (Reachability Analyzer before and after remediation)
+  backfill   analyze backfill (existing log groups or S3 Flow Logs)
+  savings    savings report (NAT Gateway costs from Cost Explorer)
+  anomalies  anomalies (NAT Gateway cost anomalies, --create-monitor)
+
+The deep modes scope log groups and stacks to the names scans give them:
+pass --name-prefix, or the --naming-policy file of the scans, when their
+prefix is not the default terminat-.
+
+Examples:
+  terminat iam-policy --mode quick > terminat-quick.json
+  terminat iam-policy --mode deep --naming-policy netops.toml
+  aws iam create-policy --policy-name terminat-deep --policy-document "$(terminat iam-policy --mode deep)"`,
+	RunE: runIAMPolicy,
+}
+
+func init() {
+	rootCmd.AddCommand(iamPolicyCmd)
+	iamPolicyCmd.Flags().StringVar(&iamPolicyMode, "mode", "deep", fmt.Sprintf("Scan mode [%s]", strings.Join(iampolicy.Modes, "|")))
+	iamPolicyCmd.Flags().StringVar(&iamPolicyNamePrefix, "name-prefix", "", "Prefix the scans name their log groups and stacks with (default "+naming.DefaultPrefix+")")
+	iamPolicyCmd.Flags().StringVar(&namingPolicyFile, "naming-policy", "", "Naming policy file of the scans, whose prefix scopes the log groups and stacks")
+	iamPolicyCmd.MarkFlagsMutuallyExclusive("name-prefix", "naming-policy")
+}
+
+func runIAMPolicy(cmd *cobra.Command, args []string) error {
+	prefix := iamPolicyNamePrefix
+	if namingPolicyFile != "" {
+		p, err := naming.LoadPolicy(namingPolicyFile)
+		if err != nil {
+			return err
+		}
+		prefix = p.Prefix
+	}
+	doc, err := iampolicy.ForMode(iamPolicyMode, prefix)
+	if err != nil {
+		return err
+	}
+	out, err := doc.JSON()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), out)
+	return nil
+}
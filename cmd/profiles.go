@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/ui"
+)
+
+// profileScanFunc runs one scan against an authenticated scanner and returns its summary row.
+type profileScanFunc func(ctx context.Context, scanner *core.Scanner, selectedRegion, selectedProfile string) (ui.AccountSummary, error)
+
+func runQuickScanProfiles(ctx context.Context) error {
+	if !isStreamUIMode(quickUIMode) {
+		return fmt.Errorf("--profiles requires --ui stream")
+	}
+	return runProfiles(ctx, quickDoctor, false, false, func(ctx context.Context, scanner *core.Scanner, _, _ string) (ui.AccountSummary, error) {
+		return ui.RunQuickScanStreamSummary(ctx, scanner)
+	})
+}
+
+func runDeepScanProfiles(ctx context.Context) error {
+	if !isStreamUIMode(deepUIMode) {
+		return fmt.Errorf("--profiles requires --ui stream")
+	}
+	return runProfiles(ctx, deepDoctor, !readOnly, true, func(ctx context.Context, scanner *core.Scanner, selectedRegion, selectedProfile string) (ui.AccountSummary, error) {
+		if err := checkCredentialLifetime(ctx, scanner); err != nil {
+			return ui.AccountSummary{Region: selectedRegion, AccountID: scanner.GetAccountID()}, err
+		}
+		return ui.RunDeepScanStreamSummary(ctx, scanner, deepScanOptions(selectedRegion, profileOutputFile(outputFile, selectedProfile)))
+	})
+}
+
+// runProfiles runs scan once per --profiles entry in order. A failing profile is
+// recorded in the summary table and does not stop the remaining profiles; an
+// interrupt does, and the profiles not yet scanned are reported as skipped.
+func runProfiles(ctx context.Context, doctor, requiresFlowLogsRole, includeSavings bool, scan profileScanFunc) error {
+	var runs []accountRun
+	for _, p := range profiles {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		runs = append(runs, accountRun{label: p, scan: func(ctx context.Context) (ui.AccountSummary, error) {
+			row, err := runProfile(ctx, p, doctor, requiresFlowLogsRole, scan)
+			row.Profile = p
+			return row, err
+		}})
+	}
+
+	rows, err := runAccounts(ctx, "Profile", "profile scan", runs)
+	ui.RenderAccountSummary(os.Stdout, rows, includeSavings)
+	recordRun(rows...)
+	return err
+}
+
+// accountRun is one scan of a multi-account run: a profile, or a tenant in
+// one region.
+type accountRun struct {
+	label string
+	scan  func(ctx context.Context) (ui.AccountSummary, error)
+}
+
+// runAccounts runs each of runs in order and returns their summary rows. A
+// failing run is recorded in its row and does not stop the others. SIGINT or
+// SIGTERM does: the scan in progress stops and cleans up on its own, and the
+// runs not started yet are returned in the error as skipped.
+func runAccounts(ctx context.Context, title, kind string, runs []accountRun) ([]ui.AccountSummary, error) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var rows []ui.AccountSummary
+	var skipped []string
+	failed := 0
+	for i, run := range runs {
+		if ctx.Err() != nil {
+			skipped = append(skipped, run.label)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "\n▶ %s %d/%d: %s\n", title, i+1, len(runs), run.label)
+
+		row, err := run.scan(ctx)
+		if err != nil {
+			row.Err = err
+			failed++
+			fmt.Fprintf(os.Stderr, "❌ %s %s failed: %v\n", title, run.label, err)
+		}
+		rows = append(rows, row)
+	}
+
+	if len(skipped) > 0 {
+		cause := context.Cause(ctx)
+		if errors.Is(cause, context.Canceled) {
+			cause = errInterrupted
+		}
+		return rows, fmt.Errorf("%w with %d %s(s) not started (%s)", cause, len(skipped), kind, strings.Join(skipped, ", "))
+	}
+	if failed > 0 {
+		return rows, fmt.Errorf("%d of %d %s(s) failed", failed, len(rows), kind)
+	}
+	return rows, nil
+}
+
+// errInterrupted is the cause of a multi-account run stopped by a signal.
+var errInterrupted = errors.New("interrupted")
+
+func runProfile(ctx context.Context, selectedProfile string, doctor, requiresFlowLogsRole bool, scan profileScanFunc) (ui.AccountSummary, error) {
+	selectedRegion, err := getRegion(selectedProfile)
+	if err != nil {
+		return ui.AccountSummary{}, err
+	}
+
+	opts, err := scannerOptions()
+	if err != nil {
+		return ui.AccountSummary{}, err
+	}
+
+	scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile, opts...)
+	if err != nil {
+		return ui.AccountSummary{Region: selectedRegion}, err
+	}
+
+	if doctor {
+		if err := runDoctorPreflight(ctx, scanner, selectedRegion, selectedProfile, requiresFlowLogsRole); err != nil {
+			return ui.AccountSummary{Region: selectedRegion, AccountID: scanner.GetAccountID()}, err
+		}
+	}
+
+	return scan(ctx, scanner, selectedRegion, selectedProfile)
+}
+
+// profileOutputFile keeps per-profile exports from overwriting each other by
+// inserting the profile name before the extension (report.md -> report-prod.md).
+func profileOutputFile(path, selectedProfile string) string {
+	if path == "" || len(profiles) < 2 {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + selectedProfile + ext
+}
+
+func isStreamUIMode(mode string) bool {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "stream":
+		return true
+	default:
+		return false
+	}
+}
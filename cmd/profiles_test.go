@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doitintl/terminator/ui"
+)
+
+// countingRuns are runs that record which ones were scanned; run at calls
+// during with its context before returning.
+func countingRuns(scanned *[]string, at int, during func(ctx context.Context)) []accountRun {
+	var runs []accountRun
+	for i, label := range []string{"dev", "staging", "prod", "dr"} {
+		runs = append(runs, accountRun{label: label, scan: func(ctx context.Context) (ui.AccountSummary, error) {
+			*scanned = append(*scanned, label)
+			if i == at {
+				during(ctx)
+				return ui.AccountSummary{}, ctx.Err()
+			}
+			return ui.AccountSummary{AccountID: label}, nil
+		}})
+	}
+	return runs
+}
+
+func TestRunAccountsStopsOnInterrupt(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scanned []string
+	rows, err := runAccounts(context.Background(), "Profile", "profile scan", countingRuns(&scanned, 1, func(ctx context.Context) {
+		if err := p.Signal(os.Interrupt); err != nil {
+			t.Skipf("cannot send an interrupt on this platform: %v", err)
+		}
+		// Delivery is asynchronous; wait for the run context to see it
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("the interrupt did not cancel the run context")
+		}
+	}))
+
+	if strings.Join(scanned, ",") != "dev,staging" {
+		t.Errorf("scanned %v, want dev and staging only", scanned)
+	}
+	if len(rows) != 2 || rows[1].Err == nil {
+		t.Errorf("rows = %+v, want dev and the interrupted staging", rows)
+	}
+	if err == nil || !errors.Is(err, errInterrupted) || !strings.Contains(err.Error(), "2 profile scan(s) not started (prod, dr)") {
+		t.Errorf("err = %v, want prod and dr reported as skipped", err)
+	}
+}
+
+func TestRunAccountsStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var scanned []string
+	rows, err := runAccounts(ctx, "Tenant", "tenant scan", countingRuns(&scanned, 2, func(context.Context) { cancel() }))
+
+	if strings.Join(scanned, ",") != "dev,staging,prod" || len(rows) != 3 {
+		t.Errorf("scanned %v (%d rows), want dev, staging and prod", scanned, len(rows))
+	}
+	if err == nil || !strings.Contains(err.Error(), "1 tenant scan(s) not started (dr)") {
+		t.Errorf("err = %v, want dr reported as skipped", err)
+	}
+}
+
+func TestRunAccountsRunsAll(t *testing.T) {
+	var scanned []string
+	_, err := runAccounts(context.Background(), "Profile", "profile scan", countingRuns(&scanned, -1, nil))
+	if err != nil || len(scanned) != 4 {
+		t.Errorf("scanned %v, err = %v; want all four without error", scanned, err)
+	}
+}
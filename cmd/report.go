@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/bundle"
+	"github.com/doitintl/terminator/internal/i18n"
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report [bundle]",
+	Short: "Render the report of a saved deep scan, without AWS access",
+	Long: `Exports the report and recommendations of a deep scan someone else ran, from
+the bundle they shared (terminat bundle), the directory the scan kept its
+artifacts in, or a JSON report. No AWS credentials are needed, so analysts
+can interpret a scan they could not run.
+
+Without an argument, the last deep scan on this machine is rendered.
+
+Bundles are redacted, so their reports are too.
+
+Examples:
+  terminat report terminat-bundle-20240601-120000.zip --report-lang es -o report.md
+  terminat report scan.json --min-savings 25 --export json -o filtered.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReport,
+}
+
+var (
+	reportExport     string
+	reportOutput     string
+	reportRenderLang string
+	reportRedact     bool
+	reportMinSavings float64
+	reportRowCap     int
+	reportTimeZone   string
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVar(&reportExport, "export", "markdown", "Export format [markdown|json]")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Output file (default terminat-report-<timestamp>.<ext>)")
+	reportCmd.Flags().StringVar(&reportRenderLang, "report-lang", "en", "Language of the markdown report [en|es|pt|ja]")
+	reportCmd.Flags().BoolVar(&reportRedact, "redact", false, "Obfuscate account IDs, resource IDs, names and IPs in the exported report")
+	reportCmd.Flags().Float64Var(&reportMinSavings, "min-savings", 0, "Hide recommendations and findings projected to save less than this many USD per month")
+	reportCmd.Flags().StringVar(&reportTimeZone, "timezone", "", "Show the collection window in this time zone instead of the scan operator's (IANA name, UTC or local)")
+	reportCmd.Flags().IntVar(&reportRowCap, "max-rows", 0, "Cap each markdown table at this many rows (0 = all; JSON keeps every row)")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	if reportExport != "markdown" && reportExport != "json" {
+		return fmt.Errorf("unsupported export format: %s", reportExport)
+	}
+	if _, err := i18n.New(reportRenderLang); err != nil {
+		return err
+	}
+	if reportMinSavings < 0 {
+		return fmt.Errorf("--min-savings must not be negative")
+	}
+	if reportRowCap < 0 {
+		return fmt.Errorf("--max-rows must not be negative")
+	}
+	var loc *time.Location
+	if reportTimeZone != "" {
+		var err error
+		if loc, err = report.LoadTimeZone(reportTimeZone); err != nil {
+			return fmt.Errorf("--timezone: %w", err)
+		}
+	}
+
+	rep, source, err := loadSavedReport(args)
+	if err != nil {
+		return err
+	}
+	rep.Lang = reportRenderLang
+	rep.Redact = reportRedact
+	rep.MaxRows = reportRowCap
+	if loc != nil && rep.CollectionWindow != nil {
+		rep.CollectionWindow = rep.CollectionWindow.In(loc)
+	}
+	if reportMinSavings > rep.MinSavings {
+		var droppedFindings, droppedRecs int
+		rep.Findings, droppedFindings = analysis.FilterFindings(rep.Findings, reportMinSavings)
+		rep.Recommendations, droppedRecs = analysis.FilterRecommendations(rep.Recommendations, reportMinSavings)
+		rep.MinSavings = reportMinSavings
+		rep.HiddenBelowMinSavings += droppedFindings + droppedRecs
+	}
+
+	output := reportOutput
+	if output == "" {
+		ext := ".md"
+		if reportExport == "json" {
+			ext = ".json"
+		}
+		output = fmt.Sprintf("terminat-report-%s%s", time.Now().Format("20060102-150405"), ext)
+	}
+	if reportExport == "json" {
+		err = rep.SaveJSON(output)
+	} else {
+		err = rep.SaveMarkdown(output)
+	}
+	if err != nil {
+		return err
+	}
+
+	absPath, _ := filepath.Abs(output)
+	if absPath == "" {
+		absPath = output
+	}
+	fmt.Printf("Scan of %s/%s from %s (%s)\n", rep.AccountID, rep.Region, rep.GeneratedAt.Format("2006-01-02 15:04"), source)
+	fmt.Printf("  %d recommendation(s), %d finding(s)", len(rep.Recommendations), len(rep.Findings))
+	if rep.CostEstimate != nil {
+		fmt.Printf(", $%.2f/month potential savings", rep.CostEstimate.TotalSavingsMonthly)
+	}
+	fmt.Println()
+	fmt.Printf("✓ Saved %s report: %s\n", reportExport, absPath)
+	return nil
+}
+
+// loadSavedReport reads the report of a bundle, artifact directory or JSON
+// file, or of the last deep scan, and says where it came from.
+func loadSavedReport(args []string) (*report.Report, string, error) {
+	source := ""
+	if len(args) == 1 {
+		source = args[0]
+	} else {
+		dir, err := bundle.DefaultDir()
+		if err != nil {
+			return nil, "", err
+		}
+		source = dir
+	}
+	if filepath.Ext(source) == ".json" {
+		rep, err := report.Load(source)
+		return rep, source, err
+	}
+	data, err := bundle.ReadReport(source)
+	if err != nil {
+		return nil, "", err
+	}
+	rep, err := report.Parse(data, source)
+	return rep, source, err
+}
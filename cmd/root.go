@@ -1,20 +1,84 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/doitintl/terminator/internal/secrets"
+	"github.com/doitintl/terminator/internal/units"
+	"github.com/doitintl/terminator/ui"
 	"github.com/spf13/cobra"
 )
 
 var version = "0.4.0"
 
+var (
+	displayUnits   string
+	insecureConfig bool
+	runTimeout     time.Duration
+	// cancelRun releases the run context once the command returns.
+	cancelRun context.CancelFunc = func() {}
+	// runRows are the accounts scanned by this run, for the run summary line.
+	runRows []ui.AccountSummary
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "terminat",
 	Short: "termiNATor - Terminate unnecessary NAT Gateway costs",
 	Long: `termiNATor helps AWS customers identify and quantify avoidable NAT Gateway 
 spend caused by workloads using NAT to reach AWS services when VPC endpoints 
 could be used instead.`,
+	PersistentPreRunE: setUpCommand,
+}
+
+func setUpCommand(cmd *cobra.Command, args []string) error {
+	system, err := units.ParseSystem(displayUnits)
+	if err != nil {
+		return err
+	}
+	units.SetSystem(system)
+	secrets.SetInsecureFallback(insecureConfig)
+	if runTimeout < 0 {
+		return fmt.Errorf("--timeout must be 0 (no limit) or a duration such as 90m")
+	}
+	ctx, cancel := runContext(cmd.Context(), runTimeout)
+	cmd.SetContext(ctx)
+	cancelRun = cancel
+	return rememberCommand(cmd, args)
+}
+
+// timeoutGrace is how long a run has, once --timeout cancelled it, to stop
+// its Flow Logs and exit before the process exits on its own.
+const timeoutGrace = 6 * time.Minute
+
+// errTimeout is the cause of a run context cancelled by --timeout.
+var errTimeout = errors.New("--timeout reached")
+
+// runContext returns the context every command runs with, cancelled after
+// timeout when it is set. Cleanup detaches from it and is bounded by its
+// own timeouts; a run still going timeoutGrace later exits with status 124,
+// so unattended runs always end.
+func runContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if parent == nil {
+		parent = context.Background()
+	}
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	ctx, cancel := context.WithTimeoutCause(parent, timeout, fmt.Errorf("%w after %s", errTimeout, timeout))
+	go func() {
+		<-ctx.Done()
+		if !errors.Is(context.Cause(ctx), errTimeout) {
+			return
+		}
+		time.Sleep(timeoutGrace)
+		fmt.Fprintf(os.Stderr, "❌ %v and the run did not finish cleaning up within %s; exiting\n", context.Cause(ctx), timeoutGrace)
+		os.Exit(124)
+	}()
+	return ctx, cancel
 }
 
 func SetVersion(v string) {
@@ -23,13 +87,48 @@ func SetVersion(v string) {
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	started := time.Now()
+	err := rootCmd.Execute()
+	cancelRun()
+	exitCode := 0
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		exitCode = 1
 	}
+	// --help and --version never reach a command, and have nothing to report
+	if commandPath != "" || err != nil {
+		command := commandPath
+		if command == "" {
+			command = rootCmd.Name()
+		}
+		ui.WriteRunSummary(os.Stderr, ui.RunSummary{
+			Command:  command,
+			RunID:    currentRunID,
+			Duration: time.Since(started),
+			Rows:     runRows,
+			ExitCode: exitCode,
+			Err:      err,
+		})
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// recordRun adds the accounts a scan covered to the run summary line.
+func recordRun(rows ...ui.AccountSummary) {
+	runRows = append(runRows, rows...)
 }
 
 func init() {
 	rootCmd.Version = version
+	rootCmd.PersistentFlags().StringVar(&configSource, "config", "", "Config file or SSM parameter (ssm:///terminat/prod) to use instead of ~/.terminat/config.toml")
+	rootCmd.PersistentFlags().StringVar(&displayUnits, "units", "binary", "How data sizes are shown: binary (KiB, MiB, GiB) or si (kB, MB, GB); costs always use AWS billing GB")
+	rootCmd.PersistentFlags().BoolVar(&insecureConfig, "insecure-config", false, "Allow API keys to be saved in plain text in ~/.terminat/config.toml when the OS keyring is unavailable")
+	rootCmd.PersistentFlags().BoolVar(&allowIMDS, "allow-imds", false, "Allow EC2 instance profile credentials from the Instance Metadata Service (detected automatically on Linux EC2 instances)")
+	rootCmd.PersistentFlags().StringVar(&stsRegion, "sts-region", "", "Region whose STS endpoint validates credentials and assumes roles (default: the scan region)")
+	rootCmd.PersistentFlags().StringVar(&stsEndpoint, "sts-endpoint", "", "STS endpoint URL to use instead, e.g. an STS interface VPC endpoint")
+	rootCmd.PersistentFlags().DurationVar(&runTimeout, "timeout", 0, "Cancel the run after this long (e.g. 90m), stopping created Flow Logs before exiting; 0 = no limit")
+	rootCmd.PersistentFlags().StringVar(&auditLogPath, "audit-log", "", "File to append mutating AWS calls to (default: audit/<date>.jsonl in the state directory)")
 	rootCmd.AddCommand(scanCmd)
 }
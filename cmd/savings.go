@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"os/user"
+	"time"
+
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/savings"
+	"github.com/spf13/cobra"
+)
+
+var savingsCmd = &cobra.Command{
+	Use:   "savings",
+	Short: "Track applied remediations and the savings they realized",
+	Long: `The savings ledger records each remediation applied after a scan: when, in
+which VPC, what was done and what it was projected to save. The report
+subcommand compares that with the NAT Gateway data processing cost in Cost
+Explorer since the first remediation, month by month.
+
+Examples:
+  terminat savings record --report ./terminat-report --recommendation 1 --date 2026-09-14
+  terminat savings record --region us-east-1 --vpc-id vpc-0abc --action "S3 gateway endpoint" --projected 412.50
+  terminat savings report --region us-east-1 --format markdown -o savings.md`,
+}
+
+var savingsRecordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record an applied remediation",
+	Args:  cobra.NoArgs,
+	RunE:  runSavingsRecord,
+}
+
+var savingsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the recorded remediations",
+	Args:  cobra.NoArgs,
+	RunE:  runSavingsList,
+}
+
+var savingsRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remove a recorded remediation",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSavingsRemove,
+}
+
+var savingsReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Compare realized with projected savings using Cost Explorer",
+	Long: `Reads the daily NAT Gateway data processing cost of the account and region
+from Cost Explorer, from --baseline-days before the first recorded
+remediation up to three days ago (Cost Explorer's last two days are still
+estimates), and reports per month what the cost would have
+been at the baseline rate, what was realized and what was projected.
+
+Cost Explorer bills $0.01 per request; a report usually needs one. Traffic
+growth or decline since the baseline counts toward the realized figure.`,
+	Args: cobra.NoArgs,
+	RunE: runSavingsReport,
+}
+
+var (
+	savingsFile           string
+	savingsAccountID      string
+	savingsVPCID          string
+	savingsAction         string
+	savingsProjected      float64
+	savingsDate           string
+	savingsNote           string
+	savingsReport         string
+	savingsRecommendation int
+	savingsBaselineDays   int
+	savingsFormat         string
+	savingsOutput         string
+)
+
+func init() {
+	rootCmd.AddCommand(savingsCmd)
+	savingsCmd.AddCommand(savingsRecordCmd, savingsListCmd, savingsRemoveCmd, savingsReportCmd)
+
+	savingsCmd.PersistentFlags().StringVar(&savingsFile, "file", "", "Savings ledger (default: savings.json in the state directory)")
+
+	savingsRecordCmd.Flags().StringVarP(&region, "region", "r", "", "Region of the remediation (default: the report's, or AWS_REGION)")
+	savingsRecordCmd.Flags().StringVarP(&profile, "profile", "p", "", "AWS profile to look up the account with (uses AWS_PROFILE env var if not specified)")
+	savingsRecordCmd.Flags().StringVar(&savingsAccountID, "account-id", "", "Account of the remediation (default: the report's, or the caller's)")
+	savingsRecordCmd.Flags().StringVar(&savingsVPCID, "vpc-id", "", "VPC of the remediation (default: the report's)")
+	savingsRecordCmd.Flags().StringVar(&savingsAction, "action", "", "What was done, e.g. \"S3 gateway endpoint\" (default: the recommendation's title)")
+	savingsRecordCmd.Flags().Float64Var(&savingsProjected, "projected", 0, "Projected monthly savings in USD (default: the recommendation's)")
+	savingsRecordCmd.Flags().StringVar(&savingsDate, "date", "", "Day the remediation was applied, YYYY-MM-DD (default: today)")
+	savingsRecordCmd.Flags().StringVar(&savingsNote, "note", "", "Free-form note, e.g. the change request")
+	savingsRecordCmd.Flags().StringVar(&savingsReport, "report", "", "Saved report (report.json, bundle directory or zip) to take the remediation from")
+	savingsRecordCmd.Flags().IntVar(&savingsRecommendation, "recommendation", 0, "Number of the report's recommendation that was applied")
+
+	savingsReportCmd.Flags().StringVarP(&region, "region", "r", "", "AWS region (uses AWS_REGION env var if not specified)")
+	savingsReportCmd.Flags().StringVarP(&profile, "profile", "p", "", "AWS profile (uses AWS_PROFILE env var if not specified)")
+	savingsReportCmd.Flags().StringSliceVar(&assumeRoles, "assume-role", []string{}, "Role ARN(s) to assume in order after loading the profile (comma-separated for a chain)")
+	savingsReportCmd.Flags().StringVar(&mfaSerial, "mfa-serial", "", "MFA device ARN for the first --assume-role hop")
+	savingsReportCmd.Flags().BoolVar(&tagSession, "tag-session", false, "Tag assumed-role sessions with the operator and run ID (trust policies must allow sts:TagSession)")
+	savingsReportCmd.Flags().StringVar(&mfaCode, "mfa-code", "", "MFA token code (prompted for if --mfa-serial is set and this is empty)")
+	savingsReportCmd.Flags().IntVar(&savingsBaselineDays, "baseline-days", 30, "Days before the first remediation to average as the baseline")
+	savingsReportCmd.Flags().StringVar(&savingsFormat, "format", "text", "Output format [text|markdown|json]")
+	savingsReportCmd.Flags().StringVarP(&savingsOutput, "output", "o", "", "Write the report to a file instead of stdout")
+}
+
+func savingsPath() (string, error) {
+	if savingsFile != "" {
+		return savingsFile, nil
+	}
+	return savings.DefaultPath()
+}
+
+func runSavingsRecord(cmd *cobra.Command, args []string) error {
+	e := savings.Entry{
+		AccountID:        savingsAccountID,
+		Region:           region,
+		VPCID:            savingsVPCID,
+		Action:           savingsAction,
+		ProjectedMonthly: savingsProjected,
+		Note:             savingsNote,
+	}
+	if savingsReport != "" {
+		if err := fillFromReport(&e); err != nil {
+			return err
+		}
+	} else if savingsRecommendation != 0 {
+		return fmt.Errorf("--recommendation needs --report")
+	}
+	if e.Action == "" {
+		return fmt.Errorf("--action is required without --report")
+	}
+
+	e.AppliedAt = time.Now().UTC()
+	if savingsDate != "" {
+		d, err := time.Parse("2006-01-02", savingsDate)
+		if err != nil {
+			return fmt.Errorf("invalid --date %q (want YYYY-MM-DD)", savingsDate)
+		}
+		e.AppliedAt = d
+	}
+	if u, err := user.Current(); err == nil {
+		e.RecordedBy = u.Username
+	}
+
+	if e.Region == "" || e.AccountID == "" {
+		ctx := cmd.Context()
+		selectedProfile := getProfile()
+		selectedRegion, err := getRegion(selectedProfile)
+		if err != nil {
+			return err
+		}
+		if e.Region == "" {
+			e.Region = selectedRegion
+		}
+		if e.AccountID == "" {
+			scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile)
+			if err != nil {
+				printAuthHelp(err)
+				return fmt.Errorf("failed to look up the account; pass --account-id")
+			}
+			e.AccountID = scanner.GetAccountID()
+		}
+	}
+
+	path, err := savingsPath()
+	if err != nil {
+		return err
+	}
+	l, err := savings.Load(path)
+	if err != nil {
+		return err
+	}
+	e = l.Record(e)
+	if err := l.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Recorded #%d %s in %s/%s (projected $%.2f/mo) in %s\n", e.ID, e.Action, e.AccountID, e.Region, e.ProjectedMonthly, path)
+	return nil
+}
+
+// fillFromReport fills what the flags left unset from the saved report and
+// its --recommendation.
+func fillFromReport(e *savings.Entry) error {
+	rep, source, err := loadSavedReport([]string{savingsReport})
+	if err != nil {
+		return err
+	}
+	if e.AccountID == "" {
+		e.AccountID = rep.AccountID
+	}
+	if e.Region == "" {
+		e.Region = rep.Region
+	}
+	if e.VPCID == "" && rep.EndpointAnalysis != nil {
+		e.VPCID = rep.EndpointAnalysis.VPCID
+	}
+	if savingsRecommendation == 0 {
+		return nil
+	}
+	if savingsRecommendation < 1 || savingsRecommendation > len(rep.Recommendations) {
+		return fmt.Errorf("%s has %d recommendation(s), not #%d", source, len(rep.Recommendations), savingsRecommendation)
+	}
+	rec := rep.Recommendations[savingsRecommendation-1]
+	if e.Action == "" {
+		e.Action = rec.Title
+	}
+	if e.ProjectedMonthly == 0 && rec.SavingsEstimated {
+		e.ProjectedMonthly = rec.MonthlySavings
+	}
+	return nil
+}
+
+func runSavingsList(cmd *cobra.Command, args []string) error {
+	path, err := savingsPath()
+	if err != nil {
+		return err
+	}
+	l, err := savings.Load(path)
+	if err != nil {
+		return err
+	}
+	if len(l.Entries) == 0 {
+		fmt.Printf("No remediations recorded in %s\n", path)
+		return nil
+	}
+	fmt.Printf("%s\n\n", path)
+	for _, e := range l.Entries {
+		fmt.Printf("  #%-3d %s  %s/%s  %-22s %-40s $%.2f/mo\n", e.ID, e.AppliedAt.Format("2006-01-02"), e.AccountID, e.Region, orAny(e.VPCID), e.Action, e.ProjectedMonthly)
+		if e.Note != "" {
+			fmt.Printf("       %s\n", e.Note)
+		}
+	}
+	return nil
+}
+
+func runSavingsRemove(cmd *cobra.Command, args []string) error {
+	var id int
+	if _, err := fmt.Sscan(args[0], &id); err != nil {
+		return fmt.Errorf("invalid id %q", args[0])
+	}
+	path, err := savingsPath()
+	if err != nil {
+		return err
+	}
+	l, err := savings.Load(path)
+	if err != nil {
+		return err
+	}
+	if !l.Remove(id) {
+		return fmt.Errorf("no remediation #%d in %s", id, path)
+	}
+	if err := l.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Removed #%d from %s\n", id, path)
+	return nil
+}
+
+func runSavingsReport(cmd *cobra.Command, args []string) error {
+	if savingsFormat != "text" && savingsFormat != "markdown" && savingsFormat != "json" {
+		return fmt.Errorf("invalid --format %q (valid: text, markdown, json)", savingsFormat)
+	}
+	path, err := savingsPath()
+	if err != nil {
+		return err
+	}
+	l, err := savings.Load(path)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	selectedProfile := getProfile()
+	selectedRegion, err := getRegion(selectedProfile)
+	if err != nil {
+		return err
+	}
+	scannerOpts, err := scannerOptions()
+	if err != nil {
+		return err
+	}
+	scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile, scannerOpts...)
+	if err != nil {
+		printAuthHelp(err)
+		return fmt.Errorf("failed to create scanner")
+	}
+
+	entries := l.For(scanner.GetAccountID(), selectedRegion)
+	if len(entries) == 0 {
+		return fmt.Errorf("no remediations recorded for %s/%s in %s", scanner.GetAccountID(), selectedRegion, path)
+	}
+	end := time.Now().UTC()
+	start, err := savings.Window(entries, savingsBaselineDays, end)
+	if err != nil {
+		return err
+	}
+	daily, err := scanner.NATProcessingCost(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	r, err := savings.Realize(entries, daily, savingsBaselineDays, end)
+	if err != nil {
+		return err
+	}
+
+	var out string
+	switch savingsFormat {
+	case "markdown":
+		out = r.Markdown()
+	case "json":
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = string(data) + "\n"
+	default:
+		out = r.Text()
+	}
+	if savingsOutput == "" {
+		fmt.Print(out)
+		return nil
+	}
+	if err := os.WriteFile(savingsOutput, []byte(out), 0o644); err != nil {
+		return fmt.Errorf("failed to save %s: %w", savingsOutput, err)
+	}
+	fmt.Fprintf(os.Stderr, "✓ Savings report saved to %s\n", savingsOutput)
+	return nil
+}
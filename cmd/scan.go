@@ -2,12 +2,24 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/baseline"
 	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/datahub"
+	"github.com/doitintl/terminator/internal/history"
+	"github.com/doitintl/terminator/internal/i18n"
+	"github.com/doitintl/terminator/internal/jira"
+	"github.com/doitintl/terminator/internal/naming"
+	"github.com/doitintl/terminator/internal/notify"
+	"github.com/doitintl/terminator/internal/report"
 	"github.com/doitintl/terminator/ui"
 	"github.com/spf13/cobra"
 )
@@ -15,13 +27,20 @@ import (
 var (
 	region                 string
 	profile                string
+	profiles               []string
 	duration               int
+	durationArg            string
+	durationPreset         *analysis.DurationPreset
+	timeZoneName           string
+	timeZone               *time.Location
 	natIDs                 []string
 	vpcID                  string
+	excludeVPCIDs          []string
 	quickDoctor            bool
 	deepDoctor             bool
 	deepUIMode             string
 	quickUIMode            string
+	quickFormat            string
 	demoUIMode             string
 	autoApprove            bool
 	autoCleanup            bool
@@ -29,6 +48,42 @@ var (
 	outputFile             string
 	datahubAPIKey          string
 	datahubCustomerContext string
+	datahubGranularity     string
+	readOnly               bool
+	existingLogGroup       string
+	ignoreCredentialExpiry bool
+	provisionVia           string
+	namingPolicyFile       string
+	namingPolicy           *naming.Policy
+	expireKeptDays         int
+	cloudTrailLogGroup     string
+	resolverLogGroup       string
+	minSavings             float64
+	reportTxt              bool
+	heartbeatFile          string
+	reportLang             string
+	redactReport           bool
+	reportMaxRows          int
+	notifyNames            []string
+	notifyTargets          []notify.Target
+	notifyWebhook          string
+	notifyWebhookHeaders   []string
+	notifyWebhookPayload   string
+	notifyWebhookSecret    string
+	jiraSync               bool
+	includeInventory       bool
+	keepHistory            bool
+	baselineFilePath       string
+	findingsBaseline       *baseline.Baseline
+	historyStore           history.Store
+	subnetID               string
+	eniID                  string
+	firehoseStream         string
+	firehoseS3             string
+	costAnomalyDays        int
+	includeRejected        bool
+	ipRangesFile           string
+	jiraConfig             *jira.Config
 )
 
 var scanCmd = &cobra.Command{
@@ -47,7 +102,15 @@ var quickCmd = &cobra.Command{
 	Use:   "quick",
 	Short: "Quick scan without Flow Logs (configuration-only)",
 	Long: `Performs a quick configuration scan to identify missing or misconfigured 
-VPC endpoints without enabling Flow Logs. Fast and cost-free.`,
+VPC endpoints without enabling Flow Logs. Fast and cost-free.
+
+With --format json, the NAT Gateways, findings and VPC inventory are printed
+to stdout as one JSON document and nothing else, for scripting; progress and
+errors go to stderr.
+
+Examples:
+  terminat scan quick --region us-east-1
+  terminat scan quick --region us-east-1 --format json | jq '.findings[].Title'`,
 	RunE: runQuickScan,
 }
 
@@ -64,15 +127,23 @@ Examples:
   # Export report to markdown
   terminat scan deep --region us-east-1 --export markdown
 
+  # Sample for two hours with the thorough preset
+  terminat scan deep --region us-east-1 --duration thorough
+
   # Export to custom file
   terminat scan deep --region us-east-1 --export json --output report.json
 
   # Fully automated scan with export
-  terminat scan deep --region us-east-1 --auto-approve --auto-cleanup --export markdown`,
+  terminat scan deep --region us-east-1 --auto-approve --auto-cleanup --export markdown
+
+  # Every customer org in the tenants file, one report per tenant
+  terminat scan deep --all-tenants --read-only --export markdown --output report.md`,
 	RunE: runDeepScan,
 }
 
 func init() {
+	deepCmd.Long += "\n\nDuration presets (--duration <preset>):\n" + analysis.DurationPresetGuide()
+
 	scanCmd.AddCommand(quickCmd)
 	scanCmd.AddCommand(deepCmd)
 	scanCmd.AddCommand(demoCmd)
@@ -80,22 +151,64 @@ func init() {
 	// Common flags
 	scanCmd.PersistentFlags().StringVarP(&region, "region", "r", "", "AWS region (uses AWS_REGION env var if not specified)")
 	scanCmd.PersistentFlags().StringVarP(&profile, "profile", "p", "", "AWS profile (uses AWS_PROFILE env var if not specified)")
+	scanCmd.PersistentFlags().StringSliceVar(&assumeRoles, "assume-role", []string{}, "Role ARN(s) to assume in order after loading the profile (comma-separated for a chain)")
+	scanCmd.PersistentFlags().StringVar(&mfaSerial, "mfa-serial", "", "MFA device ARN for the first --assume-role hop")
+	scanCmd.PersistentFlags().BoolVar(&tagSession, "tag-session", false, "Tag assumed-role sessions with the operator and run ID (trust policies must allow sts:TagSession)")
+	scanCmd.PersistentFlags().StringVar(&mfaCode, "mfa-code", "", "MFA token code (prompted for if --mfa-serial is set and this is empty)")
+	scanCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Never create, modify, or delete AWS resources (for read-only IAM policies)")
+	scanCmd.PersistentFlags().StringSliceVar(&profiles, "profiles", []string{}, "Comma-separated AWS profiles to scan sequentially (e.g. prod,staging,dev)")
+	scanCmd.PersistentFlags().StringSliceVar(&tenantNames, "tenant", []string{}, "Scan these tenants from the [tenant.<name>] sections of the config or --tenants-file (comma-separated)")
+	scanCmd.PersistentFlags().BoolVar(&allTenants, "all-tenants", false, "Scan every configured tenant")
+	scanCmd.PersistentFlags().StringVar(&tenantsFile, "tenants-file", "", "File with the [tenant.<name>] sections (default: the config file)")
 
 	// Deep scan specific flags
-	deepCmd.Flags().IntVarP(&duration, "duration", "d", 15, "Flow Log collection duration in minutes (max 60)")
+	deepCmd.Flags().StringVarP(&durationArg, "duration", "d", "15", "Flow Log collection duration in minutes (5-60) or a preset [quick|standard|thorough|daily-profile]")
 	deepCmd.Flags().StringSliceVar(&natIDs, "nat-gateway-ids", []string{}, "Specific NAT Gateway IDs to analyze (optional)")
 	deepCmd.Flags().StringVar(&vpcID, "vpc-id", "", "Filter NAT Gateways by VPC ID (optional)")
+	deepCmd.Flags().StringSliceVar(&excludeVPCIDs, "exclude-vpc-ids", []string{}, "Skip NAT Gateways in these VPCs (optional)")
 	deepCmd.Flags().BoolVar(&deepDoctor, "doctor", true, "Run doctor preflight checks before scan")
 	quickCmd.Flags().BoolVar(&quickDoctor, "doctor", true, "Run doctor preflight checks before scan")
 	deepCmd.Flags().StringVar(&deepUIMode, "ui", "stream", "UI mode [stream|tui]")
 	quickCmd.Flags().StringVar(&quickUIMode, "ui", "stream", "UI mode [stream|tui]")
+	quickCmd.Flags().StringVar(&quickFormat, "format", "text", "Output format [text|json]; json prints the findings and inventory alone on stdout")
 	demoCmd.Flags().StringVar(&demoUIMode, "ui", "stream", "UI mode [stream|tui]")
 	deepCmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Skip approval prompts (for automation)")
 	deepCmd.Flags().BoolVar(&autoCleanup, "auto-cleanup", false, "Automatically delete log groups after scan")
 	deepCmd.Flags().StringVarP(&exportFormat, "export", "e", "", "Export report format [json|markdown]")
 	deepCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path for export (requires --export)")
 	deepCmd.Flags().StringVar(&datahubAPIKey, "doit-datahub-api-key", "", "DoiT DataHub API key (or set DOIT_DATAHUB_API_KEY)")
+	deepCmd.Flags().StringVar(&provisionVia, "provision-via", ui.ProvisionDirect, "How temporary Flow Logs are created [direct|cloudformation]")
+	deepCmd.Flags().StringVar(&namingPolicyFile, "naming-policy", "", "Naming/tagging policy file applied to created resources (prefix, mandatory tags, forbidden characters)")
+	deepCmd.Flags().IntVar(&expireKeptDays, "expire-kept-log-group", 0, "Schedule deletion of a kept log group after this many days via EventBridge Scheduler (0 = off)")
+	deepCmd.Flags().StringVar(&cloudTrailLogGroup, "cloudtrail-log-group", "", "CloudTrail log group with S3 data events, used to attribute S3 traffic to buckets and principals (optional)")
+	deepCmd.Flags().StringVar(&resolverLogGroup, "resolver-log-group", "", "Route 53 Resolver query log group, used to name the DynamoDB endpoints clients resolve and flag cross-region access (optional)")
+	deepCmd.Flags().Float64Var(&minSavings, "min-savings", 0, "Hide recommendations and findings projected to save less than this many USD per month (0 = show all)")
+	deepCmd.Flags().BoolVar(&reportTxt, "report-txt", false, "Also save the stream report to a .txt file (named after --output when set)")
+	deepCmd.Flags().StringVar(&timeZoneName, "timezone", "local", "Time zone the collection window is also shown in besides UTC: an IANA name such as Europe/Berlin, UTC or local")
+	deepCmd.Flags().StringVar(&heartbeatFile, "heartbeat-file", "", "Keep the scan's status JSON (phase, percent, elapsed, estimated completion) in this file (default: status.json in the last run's directory)")
+	deepCmd.Flags().StringVar(&reportLang, "report-lang", "en", "Language of exported markdown reports [en|es|pt|ja]")
+	deepCmd.Flags().BoolVar(&redactReport, "redact", false, "Obfuscate account IDs, resource IDs, names and IP addresses in exported reports so they can be shared")
+	deepCmd.Flags().IntVar(&reportMaxRows, "max-rows", 0, "Cap each table of exported markdown reports at this many rows (0 = all; JSON keeps every row)")
+	deepCmd.Flags().StringSliceVar(&notifyNames, "notify", nil, "Send a scan summary to these targets from the [notify.<name>] sections of ~/.terminat/config.toml (e.g. slack,ops)")
+	deepCmd.Flags().StringVar(&notifyWebhook, "notify-webhook", "", "POST the scan summary or report as JSON to this URL when the scan completes")
+	deepCmd.Flags().StringArrayVar(&notifyWebhookHeaders, "notify-webhook-header", nil, "Extra header for --notify-webhook as 'Name: value' (repeatable)")
+	deepCmd.Flags().StringVar(&notifyWebhookPayload, "notify-webhook-payload", "summary", "What --notify-webhook sends [summary|report]")
+	deepCmd.Flags().StringVar(&notifyWebhookSecret, "notify-webhook-secret", "", "Sign --notify-webhook deliveries with HMAC-SHA256 (or set TERMINAT_WEBHOOK_SECRET)")
+	deepCmd.Flags().BoolVar(&jiraSync, "jira", false, "Create or update a Jira issue per high-severity finding, using the [jira] section of ~/.terminat/config.toml")
+	deepCmd.Flags().BoolVar(&keepHistory, "history", true, "Keep the JSON report in the scan history (the shared S3/DynamoDB backend when [history] is configured)")
+	deepCmd.Flags().StringVar(&baselineFilePath, "baseline-file", "", "Findings baseline whose accepted findings are hidden (default: the one terminat baseline manages)")
+	deepCmd.Flags().BoolVar(&includeInventory, "include-inventory", false, "Include the discovered subnets, route tables with routes and VPC endpoints of the scanned VPCs in the JSON report")
+	deepCmd.Flags().StringVar(&subnetID, "subnet-id", "", "Analyze what one workload subnet sends through its NAT Gateway (Flow Logs on the subnet instead of the NAT Gateway)")
+	deepCmd.Flags().StringVar(&eniID, "eni-id", "", "Analyze one network interface's traffic, e.g. a single instance or an interface endpoint (Flow Logs on the interface only)")
+	deepCmd.Flags().StringVar(&firehoseStream, "firehose-stream", "", "Deliver the temporary Flow Logs to this Kinesis Data Firehose stream ARN instead of creating a log group (requires --firehose-s3)")
+	deepCmd.Flags().StringVar(&firehoseS3, "firehose-s3", "", "s3://bucket/prefix where --firehose-stream writes (or backs up) its records, read back for the analysis")
+	deepCmd.Flags().IntVar(&costAnomalyDays, "cost-anomalies", 0, "Add the NAT Gateway cost anomalies of the last N days (AWS Cost Anomaly Detection) to the report's billing context (0 = off)")
+	deepCmd.Flags().BoolVar(&includeRejected, "include-rejected", false, "Also summarize the flows security groups and network ACLs rejected, with the top rejected destinations and sources")
+	deepCmd.Flags().StringVar(&ipRangesFile, "ip-ranges-file", "", "Classify traffic with this ip-ranges.json snapshot instead of the cached or downloaded one, for reproducible analyses")
+	deepCmd.Flags().BoolVar(&ignoreCredentialExpiry, "ignore-credential-expiry", false, "Start the scan even when the AWS session expires before it would finish, with a warning instead of an error")
+	deepCmd.Flags().StringVar(&existingLogGroup, "log-group", "", "Analyze an existing termiNATor Flow Logs log group instead of creating one (requires --read-only)")
 	deepCmd.Flags().StringVar(&datahubCustomerContext, "doit-customer-context", "", "DoiT customer context (optional, for multi-tenant API keys)")
+	deepCmd.Flags().StringVar(&datahubGranularity, "datahub-granularity", string(datahub.GranularityNAT), "What one set of DataHub events covers [nat|account]; nat uses each NAT Gateway's own traffic")
 }
 
 func getRegion(profile string) (string, error) {
@@ -158,10 +271,32 @@ func printAuthHelp(err error) {
 }
 
 func runQuickScan(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
 	if !isValidUIMode(quickUIMode) {
 		return fmt.Errorf("invalid --ui value %q (valid: stream, tui)", quickUIMode)
 	}
+	if quickFormat != "text" && quickFormat != "json" {
+		return fmt.Errorf("invalid --format %q (valid: text, json)", quickFormat)
+	}
+
+	if err := validateTenantFlags(); err != nil {
+		return err
+	}
+	if quickFormat == "json" {
+		switch {
+		case !isStreamUIMode(quickUIMode):
+			return fmt.Errorf("--format json cannot be used with --ui tui")
+		case tenantMode() || len(profiles) > 0:
+			return fmt.Errorf("--format json scans a single account; run it once per profile or tenant")
+		}
+	}
+	if tenantMode() {
+		return runQuickScanTenants(ctx)
+	}
+
+	if len(profiles) > 0 {
+		return runQuickScanProfiles(ctx)
+	}
 
 	// Get profile from flag or environment (optional)
 	selectedProfile := getProfile()
@@ -172,8 +307,13 @@ func runQuickScan(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	opts, err := scannerOptions()
+	if err != nil {
+		return err
+	}
+
 	// Create scanner - this validates credentials
-	scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile)
+	scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile, opts...)
 	if err != nil {
 		printAuthHelp(err)
 		return fmt.Errorf("failed to create scanner")
@@ -185,19 +325,36 @@ func runQuickScan(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if quickFormat == "json" {
+		row, err := ui.RunQuickScanJSON(ctx, scanner, os.Stdout)
+		recordRun(row)
+		return err
+	}
+
 	// Run quick scan with UI
+	if isStreamUIMode(quickUIMode) {
+		row, err := ui.RunQuickScanStreamSummary(ctx, scanner)
+		recordRun(row)
+		return err
+	}
 	return ui.RunQuickScan(ctx, scanner, quickUIMode)
 }
 
 func runDeepScan(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
 	if !isValidUIMode(deepUIMode) {
 		return fmt.Errorf("invalid --ui value %q (valid: stream, tui)", deepUIMode)
 	}
 
-	// Validate duration
-	if duration < 5 || duration > 60 {
-		return fmt.Errorf("duration must be between 5 and 60 minutes")
+	if err := resolveDuration(); err != nil {
+		return err
+	}
+	if needed := time.Duration(duration)*time.Minute + scanOverhead; runTimeout > 0 && existingLogGroup == "" && runTimeout < needed {
+		return fmt.Errorf("--timeout %s is shorter than the scan, about %s with --duration %d: raise it or shorten --duration", runTimeout, formatRemaining(needed), duration)
+	}
+	var err error
+	if timeZone, err = report.LoadTimeZone(timeZoneName); err != nil {
+		return fmt.Errorf("--timezone: %w", err)
 	}
 
 	// Validate --output requires --export
@@ -205,6 +362,94 @@ func runDeepScan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--output requires --export flag (e.g., --export markdown --output report.md)")
 	}
 
+	switch provisionVia {
+	case ui.ProvisionDirect, ui.ProvisionCloudFormation:
+	default:
+		return fmt.Errorf("invalid --provision-via value %q (valid: direct, cloudformation)", provisionVia)
+	}
+
+	if expireKeptDays < 0 {
+		return fmt.Errorf("--expire-kept-log-group must be 0 (off) or a number of days")
+	}
+
+	if minSavings < 0 {
+		return fmt.Errorf("--min-savings must be 0 (off) or a positive USD amount")
+	}
+
+	if includeInventory && strings.ToLower(strings.TrimSpace(exportFormat)) != "json" {
+		return fmt.Errorf("--include-inventory requires --export json")
+	}
+
+	if err := validateScopeFlags(); err != nil {
+		return err
+	}
+
+	if err := validateTenantFlags(); err != nil {
+		return err
+	}
+
+	if err := validateFirehoseFlags(); err != nil {
+		return err
+	}
+
+	if _, err := i18n.New(reportLang); err != nil {
+		return fmt.Errorf("--report-lang: %w", err)
+	}
+	if _, err := datahub.ParseGranularity(datahubGranularity); err != nil {
+		return fmt.Errorf("--datahub-granularity: %w", err)
+	}
+	if reportMaxRows < 0 {
+		return fmt.Errorf("--max-rows must be 0 (all) or a positive number of rows")
+	}
+
+	if err := validateReadOnlyFlags(); err != nil {
+		return err
+	}
+
+	if err := loadNamingPolicy(); err != nil {
+		return err
+	}
+
+	if err := loadNotifyTargets(); err != nil {
+		return err
+	}
+
+	if err := loadIPRanges(); err != nil {
+		return err
+	}
+
+	if jiraSync {
+		cfg := jira.LoadConfig()
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		jiraConfig = &cfg
+	}
+
+	accepted, err := loadFindingsBaseline()
+	if err != nil {
+		return err
+	}
+	findingsBaseline = accepted
+
+	if keepHistory {
+		cfg, err := history.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to read history config: %w", err)
+		}
+		if historyStore, err = history.Open(ctx, cfg); err != nil {
+			return err
+		}
+	}
+
+	if tenantMode() {
+		return runDeepScanTenants(ctx)
+	}
+
+	if len(profiles) > 0 {
+		return runDeepScanProfiles(ctx)
+	}
+
 	// Get profile from flag or environment (optional)
 	selectedProfile := getProfile()
 
@@ -214,21 +459,292 @@ func runDeepScan(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	opts, err := scannerOptions()
+	if err != nil {
+		return err
+	}
+
 	// Create scanner - this validates credentials
-	scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile)
+	scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile, opts...)
 	if err != nil {
 		printAuthHelp(err)
 		return fmt.Errorf("failed to create scanner")
 	}
 
 	if deepDoctor {
-		if err := runDoctorPreflight(ctx, scanner, selectedRegion, selectedProfile, true); err != nil {
+		if err := runDoctorPreflight(ctx, scanner, selectedRegion, selectedProfile, !readOnly); err != nil {
 			return err
 		}
 	}
 
+	if err := checkCredentialLifetime(ctx, scanner); err != nil {
+		return err
+	}
+
 	// Run deep scan with UI
-	return ui.RunDeepScan(ctx, scanner, selectedRegion, duration, natIDs, vpcID, deepUIMode, autoApprove, autoCleanup, exportFormat, outputFile, datahubAPIKey, datahubCustomerContext)
+	if isStreamUIMode(deepUIMode) {
+		row, err := ui.RunDeepScanStreamSummary(ctx, scanner, deepScanOptions(selectedRegion, outputFile))
+		recordRun(row)
+		return err
+	}
+	return ui.RunDeepScan(ctx, scanner, deepScanOptions(selectedRegion, outputFile))
+}
+
+func deepScanOptions(selectedRegion, output string) ui.DeepScanOptions {
+	granularity, _ := datahub.ParseGranularity(datahubGranularity) // validated in runDeepScan
+	return ui.DeepScanOptions{
+		Region:             selectedRegion,
+		Duration:           duration,
+		DurationPreset:     durationPreset,
+		NATIDs:             natIDs,
+		VPCID:              vpcID,
+		ExcludeVPCIDs:      excludeVPCIDs,
+		UIMode:             deepUIMode,
+		AutoApprove:        autoApprove,
+		AutoCleanup:        autoCleanup,
+		ExportFormat:       exportFormat,
+		OutputFile:         output,
+		ProvisionVia:       provisionVia,
+		DataHubAPIKey:      datahubAPIKey,
+		DataHubCustomerCtx: datahubCustomerContext,
+		DataHubGranularity: granularity,
+		ReadOnly:           readOnly,
+		LogGroup:           existingLogGroup,
+		RunID:              currentRunID,
+		ExpireKeptDays:     expireKeptDays,
+		CloudTrailLogGroup: cloudTrailLogGroup,
+		ResolverLogGroup:   resolverLogGroup,
+		MinSavings:         minSavings,
+		ReportTxt:          reportTxt,
+		ReportLang:         reportLang,
+		Redact:             redactReport,
+		MaxRows:            reportMaxRows,
+		NotifyTargets:      notifyTargets,
+		Jira:               jiraConfig,
+		IncludeInventory:   includeInventory,
+		History:            historyStore,
+		Baseline:           findingsBaseline,
+		SubnetID:           subnetID,
+		ENIID:              eniID,
+		FirehoseStream:     firehoseStream,
+		FirehoseS3:         firehoseS3,
+		CostAnomalyDays:    costAnomalyDays,
+		IncludeRejected:    includeRejected,
+		HeartbeatFile:      heartbeatFile,
+		TimeZone:           timeZone,
+	}
+}
+
+// resolveDuration sets duration from --duration: minutes, or the name of a
+// preset, which also sets durationPreset.
+func resolveDuration() error {
+	if p, ok := analysis.LookupDurationPreset(strings.TrimSpace(durationArg)); ok {
+		duration, durationPreset = p.Minutes, &p
+		return nil
+	}
+	minutes, err := strconv.Atoi(strings.TrimSpace(durationArg))
+	if err != nil || minutes < 5 || minutes > 60 {
+		return fmt.Errorf("--duration must be between 5 and 60 minutes or one of these presets:\n%s", strings.TrimSuffix(analysis.DurationPresetGuide(), "\n"))
+	}
+	duration, durationPreset = minutes, nil
+	return nil
+}
+
+// loadNamingPolicy reads --naming-policy and validates the names and tags a
+// run would use, so violations surface before any resource is created.
+func loadNamingPolicy() error {
+	if namingPolicyFile == "" {
+		return nil
+	}
+	p, err := naming.LoadPolicy(namingPolicyFile)
+	if err != nil {
+		return err
+	}
+	if err := p.Validate(p.RunID(time.Now().Unix())); err != nil {
+		return err
+	}
+	namingPolicy = p
+	return nil
+}
+
+// loadIPRanges pins classification to --ip-ranges-file, or validates the
+// cached AWS IP ranges (re-fetching them when corrupt), so a bad snapshot
+// surfaces before any resource is created.
+func loadIPRanges() error {
+	if ipRangesFile != "" {
+		if err := analysis.UseIPRangesFile(ipRangesFile); err != nil {
+			return fmt.Errorf("--ip-ranges-file: %w", err)
+		}
+		return nil
+	}
+	if err := analysis.PrimeIPRanges(); err != nil {
+		return fmt.Errorf("failed to load AWS IP ranges: %w", err)
+	}
+	return nil
+}
+
+// loadFindingsBaseline reads --baseline-file, or the default baseline when
+// one exists.
+func loadFindingsBaseline() (*baseline.Baseline, error) {
+	path := baselineFilePath
+	if path == "" {
+		var err error
+		if path, err = baseline.DefaultPath(); err != nil {
+			return nil, nil
+		}
+	} else if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("--baseline-file: %w", err)
+	}
+	b, err := baseline.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// loadNotifyTargets resolves --notify against config.toml and checks each
+// target's settings, so a typo doesn't surface only after a long scan.
+func loadNotifyTargets() error {
+	var targets []notify.Target
+	if len(notifyNames) > 0 {
+		configured, err := notify.LoadTargets()
+		if err != nil {
+			return fmt.Errorf("failed to read notify targets: %w", err)
+		}
+		if targets, err = notify.Select(configured, notifyNames); err != nil {
+			return err
+		}
+	}
+	webhook, err := notifyWebhookTarget()
+	if err != nil {
+		return err
+	}
+	if webhook != nil {
+		targets = append(targets, *webhook)
+	}
+	for _, t := range targets {
+		if _, err := notify.New(t); err != nil {
+			return err
+		}
+	}
+	notifyTargets = targets
+	return nil
+}
+
+// notifyWebhookTarget builds the ad-hoc target for --notify-webhook, or nil.
+func notifyWebhookTarget() (*notify.Target, error) {
+	if notifyWebhook == "" {
+		if len(notifyWebhookHeaders) > 0 || notifyWebhookSecret != "" {
+			return nil, fmt.Errorf("--notify-webhook-header and --notify-webhook-secret require --notify-webhook")
+		}
+		return nil, nil
+	}
+	settings := map[string]string{
+		notify.WebhookURL:     notifyWebhook,
+		notify.WebhookPayload: notifyWebhookPayload,
+	}
+	secret := notifyWebhookSecret
+	if secret == "" {
+		secret = os.Getenv("TERMINAT_WEBHOOK_SECRET")
+	}
+	if secret != "" {
+		settings[notify.WebhookSecret] = secret
+	}
+	for _, h := range notifyWebhookHeaders {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("--notify-webhook-header %q must be 'Name: value'", h)
+		}
+		settings[notify.WebhookHeaderPrefix+strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return &notify.Target{Name: "notify-webhook", Type: "webhook", Settings: settings}, nil
+}
+
+// validateScopeFlags checks --subnet-id and --eni-id, which pick their own NAT
+// Gateway and create one Flow Log directly.
+func validateScopeFlags() error {
+	flag := ""
+	switch {
+	case subnetID != "" && eniID != "":
+		return fmt.Errorf("--subnet-id and --eni-id cannot be used together")
+	case subnetID != "":
+		flag = "--subnet-id"
+	case eniID != "":
+		flag = "--eni-id"
+	default:
+		return nil
+	}
+	switch {
+	case len(natIDs) > 0 || vpcID != "":
+		return fmt.Errorf("%s picks its own NAT Gateway and cannot be used with --nat-gateway-ids or --vpc-id", flag)
+	case len(profiles) > 0:
+		return fmt.Errorf("%s cannot be used with --profiles", flag)
+	case tenantMode():
+		return fmt.Errorf("%s cannot be used with --tenant or --all-tenants", flag)
+	case readOnly:
+		return fmt.Errorf("%s creates Flow Logs and cannot be used with --read-only", flag)
+	case provisionVia == ui.ProvisionCloudFormation:
+		return fmt.Errorf("%s cannot be used with --provision-via cloudformation", flag)
+	}
+	return nil
+}
+
+// validateFirehoseFlags checks --firehose-stream and --firehose-s3, and
+// rejects the flags that only apply to a log group.
+func validateFirehoseFlags() error {
+	if firehoseStream == "" {
+		if firehoseS3 != "" {
+			return fmt.Errorf("--firehose-s3 requires --firehose-stream")
+		}
+		return nil
+	}
+	if !strings.HasPrefix(firehoseStream, "arn:") || !strings.Contains(firehoseStream, ":firehose:") {
+		return fmt.Errorf("--firehose-stream must be a delivery stream ARN (arn:aws:firehose:<region>:<account>:deliverystream/<name>)")
+	}
+	bucket, _, _ := strings.Cut(strings.TrimPrefix(firehoseS3, "s3://"), "/")
+	if !strings.HasPrefix(firehoseS3, "s3://") || bucket == "" {
+		return fmt.Errorf("--firehose-stream requires --firehose-s3 s3://bucket/prefix, where the stream writes its records")
+	}
+	switch {
+	case readOnly:
+		return fmt.Errorf("--firehose-stream creates Flow Logs and cannot be used with --read-only")
+	case provisionVia == ui.ProvisionCloudFormation:
+		return fmt.Errorf("--firehose-stream cannot be used with --provision-via cloudformation")
+	case autoCleanup:
+		return fmt.Errorf("--auto-cleanup cannot be used with --firehose-stream (no log group is created)")
+	case expireKeptDays > 0:
+		return fmt.Errorf("--expire-kept-log-group cannot be used with --firehose-stream (no log group is created)")
+	case includeRejected:
+		return fmt.Errorf("--include-rejected cannot be used with --firehose-stream (no log group to query)")
+	}
+	return nil
+}
+
+// validateReadOnlyFlags rejects deep scan flag combinations that would need
+// to create or delete resources, or data that only Flow Logs can provide.
+func validateReadOnlyFlags() error {
+	if existingLogGroup != "" && !readOnly {
+		return fmt.Errorf("--log-group requires --read-only")
+	}
+	if !readOnly {
+		return nil
+	}
+	if autoCleanup {
+		return fmt.Errorf("--auto-cleanup cannot be used with --read-only")
+	}
+	if expireKeptDays > 0 {
+		return fmt.Errorf("--expire-kept-log-group cannot be used with --read-only")
+	}
+	if provisionVia == ui.ProvisionCloudFormation {
+		return fmt.Errorf("--provision-via cloudformation cannot be used with --read-only")
+	}
+	if existingLogGroup == "" && exportFormat != "" {
+		return fmt.Errorf("--export with --read-only requires --log-group (metric-only estimates have no traffic breakdown to export)")
+	}
+	if existingLogGroup == "" && includeRejected {
+		return fmt.Errorf("--include-rejected with --read-only requires --log-group (metric-only estimates have no flows)")
+	}
+	return nil
 }
 
 func runDemoScan(cmd *cobra.Command, args []string) error {
@@ -247,6 +763,50 @@ func isValidUIMode(mode string) bool {
 	}
 }
 
+// scanOverhead is how long a deep scan runs besides collecting: up to 10
+// minutes waiting for the first Flow Logs records, then analysis and
+// cleanup.
+const scanOverhead = 15 * time.Minute
+
+// checkCredentialLifetime refuses a deep scan whose AWS session expires
+// before the scan would finish, which would otherwise fail with
+// AccessDenied after the collection. --ignore-credential-expiry turns the
+// error into a warning.
+func checkCredentialLifetime(ctx context.Context, scanner *core.Scanner) error {
+	if existingLogGroup != "" {
+		return nil
+	}
+	needed := time.Duration(duration)*time.Minute + scanOverhead
+	expires, err := scanner.CredentialExpiry(ctx)
+	if errors.Is(err, core.ErrCredentialExpiryUnknown) {
+		fmt.Fprintf(os.Stderr, "⚠️  %v; make sure they last the whole scan (about %s)\n", err, formatRemaining(needed))
+		return nil
+	}
+	if err != nil || expires.IsZero() {
+		return err
+	}
+	finish := time.Now().Add(needed)
+	if !expires.Before(finish) {
+		return nil
+	}
+	msg := fmt.Sprintf("AWS credentials expire at %s (in %s), before the scan would finish (about %s from now)",
+		expires.Local().Format("15:04 MST"), formatRemaining(time.Until(expires)), formatRemaining(needed))
+	if ignoreCredentialExpiry {
+		fmt.Fprintf(os.Stderr, "⚠️  %s; continuing because of --ignore-credential-expiry\n", msg)
+		return nil
+	}
+	return fmt.Errorf("%s: renew them (aws sso login for IAM Identity Center, a new MFA code, or a longer exported or credential_process session), shorten --duration, or pass --ignore-credential-expiry", msg)
+}
+
+// formatRemaining prints d in whole minutes: 45m, 1h5m.
+func formatRemaining(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d <= 0 {
+		return "0m"
+	}
+	return strings.TrimSuffix(d.String(), "0s")
+}
+
 func runDoctorPreflight(ctx context.Context, scanner *core.Scanner, selectedRegion, selectedProfile string, requiresFlowLogsRole bool) error {
 	fmt.Fprintln(os.Stderr, "🩺 Running doctor preflight checks...")
 	fmt.Fprintf(os.Stderr, "✓ Region: %s\n", selectedRegion)
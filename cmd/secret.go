@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/doitintl/terminator/internal/config"
+	"github.com/doitintl/terminator/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Keep credentials from config.toml in the OS keyring",
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <section> <setting>",
+	Short: "Store a credential setting in the OS keyring",
+	Long: `Reads the value of a credential setting from stdin and stores it in the OS
+keyring (macOS Keychain, Secret Service on Linux, Windows Credential Manager)
+instead of ~/.terminat/config.toml:
+
+  notify.<name> <setting>   routing_key, api_key, secret, webhook_url,
+                            header.<Name>, ... of a [notify.<name>] target
+  datahub api_key           the DataHub API key
+
+A value set in config.toml takes precedence over the keyring, so remove the
+line there. Without a keyring, the value is written to config.toml (readable
+only by you) when --insecure-config is given, and refused otherwise.
+
+Examples:
+  terminat secret set notify.oncall routing_key < routing-key.txt
+  printf '%s' "$HOOK_SECRET" | terminat secret set notify.ops secret
+  terminat secret set notify.ops header.Authorization`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSecretSet,
+}
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretSetCmd)
+}
+
+func runSecretSet(cmd *cobra.Command, args []string) error {
+	section, key := args[0], args[1]
+	name, err := secretName(section, key)
+	if err != nil {
+		return err
+	}
+	value, err := readSecretValue(cmd)
+	if err != nil {
+		return err
+	}
+
+	if section == "datahub" {
+		err = secrets.Set(name, value)
+	} else {
+		err = secrets.SetSetting(name, key, value)
+	}
+	if err == nil {
+		fmt.Fprintf(os.Stderr, "✓ [%s] %s stored in the OS keyring\n", section, key)
+		return nil
+	}
+
+	path, _ := config.DefaultPath()
+	if !secrets.InsecureFallback() {
+		return fmt.Errorf("could not store [%s] %s in the OS keyring: %w (rerun with --insecure-config to keep it in %s in plain text)", section, key, err, path)
+	}
+	content, err := config.Read()
+	if err != nil {
+		return err
+	}
+	if err := config.Write(config.SetValue(content, section, key, value)); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  No OS keyring; [%s] %s written to %s in plain text\n", section, key, path)
+	return nil
+}
+
+// secretName is the keyring name credentials of section are stored under.
+func secretName(section, key string) (string, error) {
+	if section == "datahub" {
+		if key != "api_key" {
+			return "", fmt.Errorf("the [datahub] credential is api_key, not %s", key)
+		}
+		return secrets.DataHubAPIKey, nil
+	}
+	target, ok := strings.CutPrefix(section, "notify.")
+	if !ok || target == "" {
+		return "", fmt.Errorf("section %q is neither notify.<name> nor datahub", section)
+	}
+	if key == "" || strings.HasSuffix(key, "_env") || key == "type" {
+		return "", fmt.Errorf("%q is not a credential setting", key)
+	}
+	return secrets.NotifyTarget(target), nil
+}
+
+// readSecretValue reads the value from the first line of stdin, prompting
+// without echo when stdin is a terminal.
+func readSecretValue(cmd *cobra.Command) (string, error) {
+	if term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Fprint(os.Stderr, "Value: ")
+		value, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		if len(value) == 0 {
+			return "", fmt.Errorf("no value entered")
+		}
+		return string(value), nil
+	}
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	value := strings.TrimRight(line, "\r\n")
+	if value == "" {
+		if err != nil {
+			return "", fmt.Errorf("no value on stdin: %w", err)
+		}
+		return "", fmt.Errorf("no value on stdin")
+	}
+	return value, nil
+}
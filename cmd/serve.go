@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/doitintl/terminator/internal/schedule"
+	"github.com/doitintl/terminator/internal/server"
+	"github.com/doitintl/terminator/ui"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve an HTTP API that runs quick scans and returns their reports",
+	Long: `Runs an HTTP API so internal portals can trigger quick scans and embed
+their results without shelling out:
+
+  GET  /runs                   runs, newest first
+  POST /runs                   queue a quick scan; {"region": "eu-west-1"} scans
+                               another region than --region
+  GET  /runs/{id}              one run, to poll until it has succeeded or failed
+  GET  /runs/{id}/report.json  the JSON report of a finished run
+
+Triggered scans run one at a time with the credentials terminat serve was
+started with, and are read-only. Runs are kept in memory, the last
+--max-runs of them, and are lost on restart.
+
+[serve.token.<name>] sections of ~/.terminat/config.toml protect the API
+with bearer tokens: viewer tokens list runs and read reports, operator
+tokens can also trigger scans. Without tokens, serve only listens on a
+loopback address unless --no-auth is given.
+
+[schedule.<name>] sections of ~/.terminat/config.toml run quick or deep
+scans on a cron expression, per region and, with profile or role_arn,
+per account. Scheduled deep scans run beside the quick scans, create and
+remove their Flow Logs without asking, and delete their log group.
+
+Examples:
+  terminat serve --region us-east-1
+  curl -X POST localhost:8080/runs -d '{"region": "eu-west-1"}'
+  curl localhost:8080/runs/terminat-1717243200/report.json`,
+	RunE: runServe,
+}
+
+var (
+	serveListen  string
+	serveMaxRuns int
+	serveNoAuth  bool
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8080", "Address to serve the API on")
+	serveCmd.Flags().BoolVar(&serveNoAuth, "no-auth", false, "Serve without tokens on a non-loopback --listen address")
+	serveCmd.Flags().IntVar(&serveMaxRuns, "max-runs", server.DefaultMaxRuns, "Runs to keep in memory; the oldest finished ones are dropped first")
+	serveCmd.Flags().StringVarP(&region, "region", "r", "", "Region scanned when a request names none (uses AWS_REGION env var if not specified)")
+	serveCmd.Flags().StringVarP(&profile, "profile", "p", "", "AWS profile (uses AWS_PROFILE env var if not specified)")
+	serveCmd.Flags().StringSliceVar(&assumeRoles, "assume-role", []string{}, "Role ARN(s) to assume in order after loading the profile (comma-separated for a chain)")
+	serveCmd.Flags().BoolVar(&tagSession, "tag-session", false, "Tag assumed-role sessions with the operator and run ID (trust policies must allow sts:TagSession)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if serveMaxRuns < 1 {
+		return fmt.Errorf("--max-runs must be at least 1")
+	}
+	selectedProfile := getProfile()
+	selectedRegion, err := getRegion(selectedProfile)
+	if err != nil {
+		return err
+	}
+
+	schedules, err := schedule.Load()
+	if err != nil {
+		return fmt.Errorf("failed to read schedules: %w", err)
+	}
+	tokens, err := server.LoadTokens()
+	if err != nil {
+		return fmt.Errorf("failed to read serve tokens: %w", err)
+	}
+	if len(tokens) == 0 && !serveNoAuth && !isLoopback(serveListen) {
+		return fmt.Errorf("--listen %s is reachable from other hosts: add [serve.token.<name>] sections to the config, or pass --no-auth", serveListen)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := server.New(server.Options{
+		Region:    selectedRegion,
+		Scan:      serveScan(selectedProfile),
+		Schedules: schedules,
+		Tokens:    tokens,
+		NewRunID:  newRunID,
+		MaxRuns:   serveMaxRuns,
+		Log: func(format string, args ...any) {
+			fmt.Printf("[%s] %-8s %s\n", time.Now().Format("15:04:05"), "serve", fmt.Sprintf(format, args...))
+		},
+	})
+	served := make(chan struct{})
+	go func() {
+		srv.Serve(ctx)
+		close(served)
+	}()
+
+	httpServer := &http.Server{
+		Addr:              serveListen,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- httpServer.ListenAndServe() }()
+	fmt.Printf("Serving the termiNATor API on http://%s (default region %s)\n", serveListen, selectedRegion)
+	if len(tokens) == 0 {
+		fmt.Println("⚠️  No [serve.token.<name>] sections: every client can list runs and trigger scans")
+	}
+	for _, sc := range schedules {
+		fmt.Printf("Schedule %s: %s scan, next at %s\n", sc.Name, sc.Type, sc.Cron.Next(time.Now()).Format(time.RFC3339))
+	}
+
+	select {
+	case err := <-errc:
+		stop()
+		<-served
+		return err
+	case <-ctx.Done():
+	}
+	// A deep scan still running stops its Flow Logs before Serve returns.
+	<-served
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	for _, r := range srv.Runs() {
+		row := ui.AccountSummary{AccountID: r.AccountID, Region: r.Region, NATGateways: r.NATGateways, Findings: r.Findings}
+		if r.Status == server.StatusFailed {
+			row.Err = errors.New(r.Error)
+		}
+		recordRun(row)
+	}
+	return nil
+}
+
+// isLoopback reports whether addr (host:port) only accepts local
+// connections; an empty host listens on every interface.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serveScan runs a quick or deep scan of a request. The scanner options
+// come from the command-line globals, which a schedule's profile and roles
+// replace for its runs, so scanners are created one at a time.
+func serveScan(defaultProfile string) server.ScanFunc {
+	var mu sync.Mutex
+	return func(ctx context.Context, req server.Request) (*report.Report, error) {
+		mu.Lock()
+		selectedProfile := defaultProfile
+		if req.Profile != "" {
+			selectedProfile = req.Profile
+		}
+		defaultRoles := assumeRoles
+		if len(req.RoleARNs) > 0 {
+			assumeRoles = req.RoleARNs
+		}
+		opts, err := scannerOptions()
+		runID := currentRunID
+		assumeRoles = defaultRoles
+		if err != nil {
+			mu.Unlock()
+			return nil, err
+		}
+		if req.Type != schedule.TypeDeep {
+			opts = append(opts, core.WithReadOnly())
+		} else {
+			opts = append(opts, core.WithResourceExpiry(resourceTTL(req.Duration)))
+		}
+		scanner, err := core.NewScanner(ctx, req.Region, selectedProfile, opts...)
+		mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+
+		if req.Type != schedule.TypeDeep {
+			return ui.QuickScanReport(ctx, scanner)
+		}
+		deepOpts := deepScanOptions(req.Region, "")
+		deepOpts.Duration, deepOpts.UIMode, deepOpts.RunID = req.Duration, "stream", runID
+		return ui.RunDeepScanStreamReport(ctx, scanner, deepOpts)
+	}
+}
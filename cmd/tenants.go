@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/tenants"
+	"github.com/doitintl/terminator/ui"
+)
+
+var (
+	tenantNames []string
+	allTenants  bool
+	tenantsFile string
+)
+
+// tenantScanFunc returns the scan to run for one tenant in one region.
+type tenantScanFunc func(t tenants.Tenant, selectedRegion string) profileScanFunc
+
+func tenantMode() bool {
+	return allTenants || len(tenantNames) > 0
+}
+
+// validateTenantFlags rejects the flags --tenant and --all-tenants replace:
+// each tenant brings its own role and profile.
+func validateTenantFlags() error {
+	if !tenantMode() {
+		if tenantsFile != "" {
+			return fmt.Errorf("--tenants-file requires --tenant or --all-tenants")
+		}
+		return nil
+	}
+	switch {
+	case allTenants && len(tenantNames) > 0:
+		return fmt.Errorf("--tenant and --all-tenants cannot be used together")
+	case len(profiles) > 0:
+		return fmt.Errorf("--tenant and --all-tenants cannot be used with --profiles")
+	case len(assumeRoles) > 0:
+		return fmt.Errorf("--tenant and --all-tenants assume each tenant's role_arn and cannot be used with --assume-role")
+	case profile != "":
+		return fmt.Errorf("--tenant and --all-tenants use each tenant's profile and cannot be used with --profile")
+	}
+	return nil
+}
+
+func runQuickScanTenants(ctx context.Context) error {
+	if !isStreamUIMode(quickUIMode) {
+		return fmt.Errorf("--tenant and --all-tenants require --ui stream")
+	}
+	return runTenants(ctx, quickDoctor, false, false, func(tenants.Tenant, string) profileScanFunc {
+		return func(ctx context.Context, scanner *core.Scanner, _, _ string) (ui.AccountSummary, error) {
+			return ui.RunQuickScanStreamSummary(ctx, scanner)
+		}
+	})
+}
+
+func runDeepScanTenants(ctx context.Context) error {
+	if !isStreamUIMode(deepUIMode) {
+		return fmt.Errorf("--tenant and --all-tenants require --ui stream")
+	}
+	return runTenants(ctx, deepDoctor, !readOnly, true, func(t tenants.Tenant, selectedRegion string) profileScanFunc {
+		return func(ctx context.Context, scanner *core.Scanner, _, _ string) (ui.AccountSummary, error) {
+			if err := checkCredentialLifetime(ctx, scanner); err != nil {
+				return ui.AccountSummary{Region: selectedRegion, AccountID: scanner.GetAccountID()}, err
+			}
+			opts := deepScanOptions(selectedRegion, tenantOutputFile(outputFile, t, selectedRegion))
+			if t.CustomerContext != "" {
+				opts.DataHubCustomerCtx = t.CustomerContext
+			}
+			return ui.RunDeepScanStreamSummary(ctx, scanner, opts)
+		}
+	})
+}
+
+// runTenants scans each selected tenant in each of its regions, assuming the
+// tenant's role from its profile, then prints the per-account table and the
+// per-tenant roll-up. A failing tenant or region is recorded and does not
+// stop the rest; an interrupt does, and the tenants and regions not yet
+// scanned are reported as skipped.
+func runTenants(ctx context.Context, doctor, requiresFlowLogsRole, includeSavings bool, scan tenantScanFunc) error {
+	configured, err := tenants.Load(tenantsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tenants: %w", err)
+	}
+	selected, err := tenants.Select(configured, tenantNames, allTenants)
+	if err != nil {
+		return err
+	}
+
+	defaultRegion := region
+	defer func() {
+		region, assumeRoles = defaultRegion, nil
+	}()
+
+	var runs []accountRun
+	for _, t := range selected {
+		regions := t.Regions
+		if len(regions) == 0 {
+			regions = []string{defaultRegion}
+		}
+		for _, r := range regions {
+			label := t.Name
+			if r != "" {
+				label += " (" + r + ")"
+			}
+			runs = append(runs, accountRun{label: label, scan: func(ctx context.Context) (ui.AccountSummary, error) {
+				region, assumeRoles = r, t.RoleARNs
+				row, err := runProfile(ctx, t.Profile, doctor, requiresFlowLogsRole, scan(t, r))
+				row.Profile, row.Tenant = t.Name, t.Name
+				if row.Region == "" {
+					row.Region = r
+				}
+				return row, err
+			}})
+		}
+	}
+
+	rows, err := runAccounts(ctx, "Tenant", "tenant scan", runs)
+	ui.RenderAccountSummary(os.Stdout, rows, includeSavings)
+	ui.RenderTenantSummary(os.Stdout, rows, includeSavings)
+	recordRun(rows...)
+	return err
+}
+
+// tenantOutputFile gives each tenant its own export by inserting the tenant
+// and, when it has several, the region before the extension
+// (report.md -> report-acme-us-east-1.md).
+func tenantOutputFile(path string, t tenants.Tenant, selectedRegion string) string {
+	if path == "" {
+		return path
+	}
+	suffix := "-" + t.Name
+	if len(t.Regions) > 1 {
+		suffix += "-" + selectedRegion
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + suffix + ext
+}
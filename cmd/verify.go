@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/verify"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Prove with VPC Reachability Analyzer which way subnets reach S3 and DynamoDB",
+	Long: `Runs VPC Reachability Analyzer from a workload network interface in every
+route table that sends traffic to a NAT Gateway, to an address of the S3 and
+DynamoDB prefix lists on TCP 443, and reports whether each path goes through
+a gateway endpoint or the NAT Gateway.
+
+Run it before remediation and again afterwards with --baseline, and attach
+the Markdown output to the change request as evidence that the new path
+works. Reachability Analyzer bills $0.10 per analysis; the analyses and
+their paths are deleted afterwards unless --keep-analyses is set.
+
+Examples:
+  terminat verify --region us-east-1 -o before.json
+  # ... create the gateway endpoints and routes ...
+  terminat verify --region us-east-1 --baseline before.json --format markdown -o after.json`,
+	RunE: runVerify,
+}
+
+var (
+	verifyVPCIDs       []string
+	verifyServices     []string
+	verifyBaseline     string
+	verifyOutput       string
+	verifyFormat       string
+	verifyYes          bool
+	verifyKeepAnalyses bool
+	verifyFailOnNAT    bool
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVarP(&region, "region", "r", "", "AWS region (uses AWS_REGION env var if not specified)")
+	verifyCmd.Flags().StringVarP(&profile, "profile", "p", "", "AWS profile (uses AWS_PROFILE env var if not specified)")
+	verifyCmd.Flags().StringSliceVar(&assumeRoles, "assume-role", []string{}, "Role ARN(s) to assume in order after loading the profile (comma-separated for a chain)")
+	verifyCmd.Flags().StringVar(&mfaSerial, "mfa-serial", "", "MFA device ARN for the first --assume-role hop")
+	verifyCmd.Flags().BoolVar(&tagSession, "tag-session", false, "Tag assumed-role sessions with the operator and run ID (trust policies must allow sts:TagSession)")
+	verifyCmd.Flags().StringVar(&mfaCode, "mfa-code", "", "MFA token code (prompted for if --mfa-serial is set and this is empty)")
+	verifyCmd.Flags().StringSliceVar(&verifyVPCIDs, "vpc-id", nil, "Only verify these VPCs (default: every VPC with a NAT Gateway)")
+	verifyCmd.Flags().StringSliceVar(&verifyServices, "services", []string{"s3", "dynamodb"}, "Services to verify [s3|dynamodb]")
+	verifyCmd.Flags().StringVar(&verifyBaseline, "baseline", "", "Verification JSON from before the change, to show each path's before and after")
+	verifyCmd.Flags().StringVarP(&verifyOutput, "output", "o", "", "Save the verdicts as JSON (the baseline for the next run)")
+	verifyCmd.Flags().StringVar(&verifyFormat, "format", "text", "Output format [text|markdown]")
+	verifyCmd.Flags().BoolVar(&verifyYes, "yes", false, "Run the analyses without asking to confirm the cost")
+	verifyCmd.Flags().BoolVar(&verifyKeepAnalyses, "keep-analyses", false, "Keep the Reachability Analyzer paths and analyses in the account for review in the console")
+	verifyCmd.Flags().BoolVar(&verifyFailOnNAT, "fail-on-nat", false, "Exit non-zero when a path still goes through a NAT Gateway (for CI)")
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	if verifyFormat != "text" && verifyFormat != "markdown" {
+		return fmt.Errorf("invalid --format %q (valid: text, markdown)", verifyFormat)
+	}
+	services := make([]string, 0, len(verifyServices))
+	for _, s := range verifyServices {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if _, ok := verify.Services[s]; !ok {
+			return fmt.Errorf("invalid service %q (valid: s3, dynamodb)", s)
+		}
+		services = append(services, s)
+	}
+	sort.Strings(services)
+
+	var baseline *verify.Report
+	if verifyBaseline != "" {
+		var err error
+		if baseline, err = verify.Load(verifyBaseline); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	selectedProfile := getProfile()
+	selectedRegion, err := getRegion(selectedProfile)
+	if err != nil {
+		return err
+	}
+	scannerOpts, err := scannerOptions()
+	if err != nil {
+		return err
+	}
+	scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile, scannerOpts...)
+	if err != nil {
+		printAuthHelp(err)
+		return fmt.Errorf("failed to create scanner")
+	}
+	if baseline != nil && (baseline.AccountID != scanner.GetAccountID() || baseline.Region != selectedRegion) {
+		return fmt.Errorf("baseline covers %s/%s, not %s/%s", baseline.AccountID, baseline.Region, scanner.GetAccountID(), selectedRegion)
+	}
+
+	rep, err := verify.Plan(ctx, scanner, scanner.GetAccountID(), selectedRegion, verifyVPCIDs, services)
+	if err != nil {
+		return err
+	}
+	pending := rep.Pending()
+	if pending == 0 {
+		return fmt.Errorf("no route table through a NAT Gateway has a workload network interface to analyze from")
+	}
+
+	fmt.Fprintf(os.Stderr, "%d Reachability Analyzer analyses (~$%.2f)\n", pending, float64(pending)*verify.PricePerAnalysis)
+	if !verifyYes {
+		fmt.Fprint(os.Stderr, "Run them? (yes/no): ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "yes" {
+			fmt.Fprintln(os.Stderr, "Verification cancelled")
+			return nil
+		}
+	}
+
+	rep.Analyze(ctx, scanner, verifyKeepAnalyses, func(p verify.Path) {
+		fmt.Fprintf(os.Stderr, "  %s %s: %s\n", p.RouteTableID, p.Service, p.Verdict)
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if verifyFormat == "markdown" {
+		fmt.Print(rep.Markdown(baseline))
+	} else {
+		fmt.Print(rep.Text(baseline))
+	}
+
+	if verifyOutput != "" {
+		if err := rep.SaveJSON(verifyOutput); err != nil {
+			return fmt.Errorf("failed to save %s: %w", verifyOutput, err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Verdicts saved to %s\n", verifyOutput)
+	}
+
+	if n := rep.Count(verify.VerdictNAT); verifyFailOnNAT && n > 0 {
+		return fmt.Errorf("%d path(s) still go through a NAT Gateway", n)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/notify"
+	"github.com/doitintl/terminator/ui"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Alert on NAT Gateway traffic and cost spikes and on finding changes",
+	Long: `Polls NAT Gateway CloudWatch metrics and sends a PagerDuty or Opsgenie
+event when a NAT Gateway processes more data, or is on track to cost more,
+than the thresholds allow. The incident is resolved once traffic falls back.
+No AWS resources are created.
+
+With --quick-scan-every, it also runs a quick scan that often and notifies
+the --notify targets only when the findings change from the previous scan:
+a new missing endpoint, an association removed from a route table, or a
+finding that was fixed. The last scan's findings are kept in the state
+directory, so restarts and --once runs from cron compare against it.
+
+With --sample-minutes, it enables Flow Logs on every NAT Gateway for that
+many minutes once a day and removes them again, starting 7 hours later each
+day so every hour of the day is covered within 24 days. The samples of the
+last five weeks are projected to a month with the same weekday/weekend
+model as backfill, for a service breakdown at a fraction of the ingestion
+cost of continuous Flow Logs. It needs the termiNATor-FlowLogsRole and
+write access, unlike the rest of watch.
+
+With --delete-expired, and always with --sample-minutes, every check also
+deletes the Flow Logs and log groups termiNATor created whose ExpiresAt tag
+has passed, like terminat cleanup --expired, in case a scan died before
+cleaning up.
+
+Alert targets are [notify.<name>] sections of ~/.terminat/config.toml with
+type pagerduty (routing_key) or opsgenie (api_key); --notify takes any type.
+
+Examples:
+  terminat watch --region us-east-1 --max-monthly-cost 500 --alert pagerduty
+  terminat watch --region us-east-1 --max-gb 50 --window 1h --alert opsgenie --once
+  terminat watch --region us-east-1 --quick-scan-every 24h --notify slack
+  terminat watch --region us-east-1 --sample-minutes 15
+  terminat watch --region us-east-1 --delete-expired --interval 1h`,
+	RunE: runWatch,
+}
+
+var (
+	watchInterval       time.Duration
+	watchWindow         time.Duration
+	watchMaxGB          float64
+	watchMaxMonthlyCost float64
+	watchAlertNames     []string
+	watchOnce           bool
+	watchQuickScanEvery time.Duration
+	watchNotifyNames    []string
+	watchSampleMinutes  int
+	watchDeleteExpired  bool
+)
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().StringVarP(&region, "region", "r", "", "AWS region (uses AWS_REGION env var if not specified)")
+	watchCmd.Flags().StringVarP(&profile, "profile", "p", "", "AWS profile (uses AWS_PROFILE env var if not specified)")
+	watchCmd.Flags().StringSliceVar(&assumeRoles, "assume-role", []string{}, "Role ARN(s) to assume in order after loading the profile (comma-separated for a chain)")
+	watchCmd.Flags().StringVar(&mfaSerial, "mfa-serial", "", "MFA device ARN for the first --assume-role hop")
+	watchCmd.Flags().BoolVar(&tagSession, "tag-session", false, "Tag assumed-role sessions with the operator and run ID (trust policies must allow sts:TagSession)")
+	watchCmd.Flags().StringVar(&mfaCode, "mfa-code", "", "MFA token code (prompted for if --mfa-serial is set and this is empty)")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 15*time.Minute, "How often to check")
+	watchCmd.Flags().DurationVar(&watchWindow, "window", time.Hour, "Trailing metrics window each check looks at")
+	watchCmd.Flags().Float64Var(&watchMaxGB, "max-gb", 0, "Alert when a NAT Gateway processes more than this many GB within --window (0 = off)")
+	watchCmd.Flags().Float64Var(&watchMaxMonthlyCost, "max-monthly-cost", 0, "Alert when a NAT Gateway's projected monthly data processing cost exceeds this many USD (0 = off)")
+	watchCmd.Flags().StringSliceVar(&watchAlertNames, "alert", nil, "PagerDuty/Opsgenie targets from the [notify.<name>] sections of ~/.terminat/config.toml (required with thresholds)")
+	watchCmd.Flags().DurationVar(&watchQuickScanEvery, "quick-scan-every", 0, "Run a quick scan this often, e.g. 24h, and notify when its findings change (0 = off)")
+	watchCmd.Flags().StringSliceVar(&watchNotifyNames, "notify", nil, "Targets from the [notify.<name>] sections of ~/.terminat/config.toml for finding changes (required with --quick-scan-every)")
+	watchCmd.Flags().IntVar(&watchSampleMinutes, "sample-minutes", 0, "Enable Flow Logs on every NAT Gateway for this many minutes a day, at a rotating hour, and project the month from the samples (0 = off)")
+	watchCmd.Flags().BoolVar(&watchDeleteExpired, "delete-expired", false, "Delete termiNATor Flow Logs and log groups past their ExpiresAt tag every check (always on with --sample-minutes)")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Run a single check and exit (for cron)")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if watchMaxGB < 0 || watchMaxMonthlyCost < 0 {
+		return fmt.Errorf("--max-gb and --max-monthly-cost must be 0 (off) or positive")
+	}
+	thresholds := watchMaxGB > 0 || watchMaxMonthlyCost > 0
+	if !thresholds && watchQuickScanEvery == 0 && watchSampleMinutes == 0 && !watchDeleteExpired {
+		return fmt.Errorf("set --max-gb, --max-monthly-cost, --quick-scan-every, --sample-minutes and/or --delete-expired")
+	}
+	// Flow Logs aggregate over up to 10 minutes; shorter samples are mostly empty.
+	if watchSampleMinutes != 0 && (watchSampleMinutes < 10 || watchSampleMinutes > 120) {
+		return fmt.Errorf("--sample-minutes must be between 10 and 120")
+	}
+	if thresholds != (len(watchAlertNames) > 0) {
+		return fmt.Errorf("--alert is required with --max-gb or --max-monthly-cost, and only used with them")
+	}
+	if watchQuickScanEvery != 0 && watchQuickScanEvery < time.Hour {
+		return fmt.Errorf("--quick-scan-every must be at least 1h")
+	}
+	if (watchQuickScanEvery > 0) != (len(watchNotifyNames) > 0) {
+		return fmt.Errorf("--notify is required with --quick-scan-every, and only used with it")
+	}
+	if watchInterval < time.Minute {
+		return fmt.Errorf("--interval must be at least 1m")
+	}
+	// NAT Gateway metrics are published per minute; shorter windows are mostly empty.
+	if watchWindow < 5*time.Minute {
+		return fmt.Errorf("--window must be at least 5m")
+	}
+
+	configured, err := notify.LoadTargets()
+	if err != nil {
+		return fmt.Errorf("failed to read notify targets: %w", err)
+	}
+	var targets, notifyTargets []notify.Target
+	if thresholds {
+		if targets, err = notify.Select(configured, watchAlertNames); err != nil {
+			return err
+		}
+		if err := notify.CheckAlerters(targets); err != nil {
+			return err
+		}
+	}
+	if watchQuickScanEvery > 0 {
+		if notifyTargets, err = notify.Select(configured, watchNotifyNames); err != nil {
+			return err
+		}
+	}
+
+	selectedProfile := getProfile()
+	selectedRegion, err := getRegion(selectedProfile)
+	if err != nil {
+		return err
+	}
+
+	scannerOpts, err := scannerOptions()
+	if err != nil {
+		return err
+	}
+	if watchSampleMinutes > 0 {
+		scannerOpts = append(scannerOpts, core.WithResourceExpiry(resourceTTL(watchSampleMinutes)))
+	} else if !watchDeleteExpired {
+		scannerOpts = append(scannerOpts, core.WithReadOnly())
+	}
+
+	scanner, err := core.NewScanner(ctx, selectedRegion, selectedProfile, scannerOpts...)
+	if err != nil {
+		printAuthHelp(err)
+		return fmt.Errorf("failed to create scanner")
+	}
+
+	return ui.RunWatch(ctx, scanner, ui.WatchOptions{
+		Region:         selectedRegion,
+		Interval:       watchInterval,
+		Window:         watchWindow,
+		MaxGB:          watchMaxGB,
+		MaxMonthlyCost: watchMaxMonthlyCost,
+		AlertTargets:   targets,
+		QuickScanEvery: watchQuickScanEvery,
+		NotifyTargets:  notifyTargets,
+		SampleMinutes:  watchSampleMinutes,
+		DeleteExpired:  watchDeleteExpired || watchSampleMinutes > 0,
+		NewRunID:       newRunID,
+		Once:           watchOnce,
+	})
+}
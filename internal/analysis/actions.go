@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// Placeholders of the remediation action parameters a scan cannot fill in.
+const (
+	placeholderRegion          = "<region>"
+	placeholderSubnetID        = "<private-subnet-id>"
+	placeholderSecurityGroupID = "<security-group-id>"
+)
+
+// regionReporter is implemented by scanners that know their region, which
+// names the endpoint services of remediation actions.
+type regionReporter interface {
+	GetRegion() string
+}
+
+func ec2Action(action string, params map[string]any) types.RemediationAction {
+	return types.RemediationAction{Service: "ec2", Action: action, Parameters: params}
+}
+
+// createGatewayEndpointAction creates the gateway endpoint of service (s3
+// or dynamodb) in vpcID, routed from routeTableIDs.
+func createGatewayEndpointAction(region, vpcID, service string, routeTableIDs []string) types.RemediationAction {
+	return ec2Action("CreateVpcEndpoint", map[string]any{
+		"VpcId":           vpcID,
+		"ServiceName":     fmt.Sprintf("com.amazonaws.%s.%s", region, service),
+		"VpcEndpointType": "Gateway",
+		"RouteTableIds":   routeTableIDs,
+	})
+}
+
+// addEndpointRoutesAction associates gateway endpoint endpointID with
+// routeTableIDs.
+func addEndpointRoutesAction(endpointID string, routeTableIDs []string) types.RemediationAction {
+	return ec2Action("ModifyVpcEndpoint", map[string]any{
+		"VpcEndpointId":    endpointID,
+		"AddRouteTableIds": routeTableIDs,
+	})
+}
+
+// createInterfaceEndpointActions create interface endpoints for
+// serviceNames in vpcID, in the subnets and security group of the
+// endpoints' placement, or placeholders without one.
+func createInterfaceEndpointActions(endpoints *EndpointAnalysis, vpcID string, serviceNames []string) []types.RemediationAction {
+	subnets, group := []string{placeholderSubnetID}, placeholderSecurityGroupID
+	if endpoints != nil && endpoints.Placement != nil {
+		p := endpoints.Placement
+		if len(p.SubnetIDs) > 0 {
+			subnets = p.SubnetIDs
+		}
+		if p.SecurityGroupID != "" {
+			group = p.SecurityGroupID
+		}
+	}
+	var actions []types.RemediationAction
+	for _, name := range serviceNames {
+		actions = append(actions, ec2Action("CreateVpcEndpoint", map[string]any{
+			"VpcId":             vpcID,
+			"ServiceName":       name,
+			"VpcEndpointType":   "Interface",
+			"SubnetIds":         subnets,
+			"SecurityGroupIds":  []string{group},
+			"PrivateDnsEnabled": true,
+		}))
+	}
+	return actions
+}
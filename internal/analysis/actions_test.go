@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+type regionalScanner struct {
+	dnsScanner
+	routeTables []types.RouteTable
+}
+
+func (s regionalScanner) DiscoverRouteTables(ctx context.Context, vpcID string) ([]types.RouteTable, error) {
+	return s.routeTables, nil
+}
+
+func (s regionalScanner) GetRegion() string { return "eu-west-1" }
+
+func TestAnalyzeAllVPCEndpointsRemediationActions(t *testing.T) {
+	natRT := func(id string) types.RouteTable {
+		return types.RouteTable{ID: id, Routes: []types.Route{{DestinationCIDR: "0.0.0.0/0", Target: "nat-1", TargetType: "nat-gateway"}}}
+	}
+	scanner := regionalScanner{
+		dnsScanner: dnsScanner{endpoints: []types.VPCEndpoint{
+			{ID: "vpce-ddb", ServiceName: "com.amazonaws.eu-west-1.dynamodb", Type: "Gateway", State: "available", RouteTables: []string{"rtb-a"}},
+		}},
+		routeTables: []types.RouteTable{natRT("rtb-a"), natRT("rtb-b")},
+	}
+	findings := AnalyzeAllVPCEndpoints(context.Background(), scanner, []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1"}})
+
+	byService := map[string]types.Finding{}
+	for _, f := range findings {
+		byService[f.Service] = f
+	}
+	s3 := byService["S3"].Remediation
+	if len(s3) != 1 || s3[0].Service != "ec2" || s3[0].Action != "CreateVpcEndpoint" ||
+		s3[0].Parameters["ServiceName"] != "com.amazonaws.eu-west-1.s3" || s3[0].Parameters["VpcEndpointType"] != "Gateway" {
+		t.Errorf("unexpected S3 remediation: %+v", s3)
+	}
+	ddb := byService["DynamoDB"].Remediation
+	if len(ddb) != 1 || ddb[0].Action != "ModifyVpcEndpoint" || ddb[0].Parameters["VpcEndpointId"] != "vpce-ddb" {
+		t.Fatalf("unexpected DynamoDB remediation: %+v", ddb)
+	}
+	if rts, _ := ddb[0].Parameters["AddRouteTableIds"].([]string); len(rts) != 1 || rts[0] != "rtb-b" {
+		t.Errorf("expected only rtb-b to be added, got %v", ddb[0].Parameters["AddRouteTableIds"])
+	}
+
+	data, err := json.Marshal(ddb[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"service":"ec2","action":"ModifyVpcEndpoint","parameters":{"AddRouteTableIds":["rtb-b"],"VpcEndpointId":"vpce-ddb"}}`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
+
+func TestCreateInterfaceEndpointActionsUsePlacement(t *testing.T) {
+	names := []string{"com.amazonaws.us-east-1.sts"}
+	got := createInterfaceEndpointActions(nil, "vpc-1", names)
+	if len(got) != 1 || got[0].Parameters["SecurityGroupIds"].([]string)[0] != placeholderSecurityGroupID {
+		t.Errorf("expected placeholders without a placement, got %+v", got)
+	}
+
+	endpoints := &EndpointAnalysis{Placement: &EndpointPlacement{SubnetIDs: []string{"subnet-1", "subnet-2"}, SecurityGroupID: "sg-1"}}
+	got = createInterfaceEndpointActions(endpoints, "vpc-1", names)
+	if subnets := got[0].Parameters["SubnetIds"].([]string); len(subnets) != 2 || got[0].Parameters["SecurityGroupIds"].([]string)[0] != "sg-1" {
+		t.Errorf("expected the placement's subnets and group, got %+v", got[0])
+	}
+}
@@ -1,40 +1,82 @@
 package analysis
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"net/netip"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/doitintl/terminator/internal/units"
 )
 
 type SourceIPStats struct {
 	Bytes   int64
 	Records int
-	S3      int64
-	Dynamo  int64
-	ECR     int64
-	Other   int64
+	// Services splits Bytes by the service of the destinations.
+	Services map[Service]int64
 }
 
 type TrafficStats struct {
-	S3Bytes       int64
-	DynamoBytes   int64
-	ECRBytes      int64
-	OtherBytes    int64
-	TotalBytes    int64
-	S3Records     int
-	DynamoRecords int
-	ECRRecords    int
-	OtherRecords  int
-	TotalRecords  int
-	SourceIPs     map[string]*SourceIPStats
+	// Services splits the traffic by classified service; see Bytes,
+	// Records and ServiceOrder.
+	Services     map[Service]ServiceStats
+	TotalBytes   int64
+	TotalRecords int
+	SourceIPs    map[string]*SourceIPStats
+	Accuracy     ClassificationAccuracy
+	// S3BytesByRegion and DynamoBytesByRegion split the S3 and DynamoDB
+	// traffic by the region of the range each destination fell in ("" when
+	// unknown).
+	S3BytesByRegion     map[string]int64
+	DynamoBytesByRegion map[string]int64
+	// RegistryBytes is traffic to public container registries (Docker Hub,
+	// Quay, GHCR), counted within the ECR or Other totals.
+	RegistryBytes map[string]int64
+	// ServiceBytes is traffic to regional AWS APIs (ecr.api, ecr.dkr, sts,
+	// logs, monitoring, eks), counted within the ECR or Other totals.
+	ServiceBytes map[string]int64
+	// Planes splits each service's bytes into API calls and data transfer,
+	// keyed by regional service (sts, kms, ...) when recognized, else s3,
+	// dynamodb, ecr or other.
+	Planes map[string]*PlaneSplit
+	// PortBytes is traffic by protocol and service port ("tcp/443", see
+	// PortKey), telling HTTPS apart from plaintext, database and other flows.
+	PortBytes map[string]int64
+}
+
+// ClassificationAccuracy splits the analyzed bytes by how specific their
+// classification was, so users can judge how far to trust the service split.
+type ClassificationAccuracy struct {
+	ExactBytes     int64 // matched a service-specific range (S3, DynamoDB)
+	BroadEC2Bytes  int64 // matched only the broad EC2 range; the ECR share is an upper bound
+	UnmatchedBytes int64 // matched no tracked AWS range
+	// ResolvedBytes went to an address a public registry or regional AWS API
+	// hostname resolved to. It overlaps the three shares above.
+	ResolvedBytes int64
+}
+
+func (a *ClassificationAccuracy) add(kind MatchKind, bytes int64) {
+	switch kind {
+	case MatchExact:
+		a.ExactBytes += bytes
+	case MatchBroad:
+		a.BroadEC2Bytes += bytes
+	default:
+		a.UnmatchedBytes += bytes
+	}
 }
 
 type TrafficAnalyzer struct {
 	classifier *TrafficClassifier
 	stats      TrafficStats
+	// subnetScope is set for Flow Logs of a workload subnet rather than a
+	// NAT Gateway; see ScopeToSubnet.
+	subnetScope bool
+	bypassed    map[string]bool
 }
 
 func NewTrafficAnalyzer() (*TrafficAnalyzer, error) {
@@ -45,13 +87,59 @@ func NewTrafficAnalyzer() (*TrafficAnalyzer, error) {
 	return &TrafficAnalyzer{classifier: classifier}, nil
 }
 
+// ScopeToSubnet prepares the analyzer for Flow Logs of a workload subnet.
+// Those record every flow of the subnet's interfaces, so flows between
+// internal addresses (which never reach the NAT Gateway) are dropped, each
+// remaining flow is attributed to its public peer (the destination of egress,
+// the source of responses), and traffic to bypassed services ("s3",
+// "dynamodb"), which the subnet reaches through gateway endpoints, is left out.
+func (ta *TrafficAnalyzer) ScopeToSubnet(bypassed ...string) {
+	ta.subnetScope = true
+	ta.bypassed = map[string]bool{}
+	for _, svc := range bypassed {
+		ta.bypassed[svc] = true
+	}
+}
+
+// natPeer returns the address a flow is classified by, and false when the
+// flow doesn't go through the NAT Gateway.
+func (ta *TrafficAnalyzer) natPeer(src, dst string) (string, bool) {
+	if !ta.subnetScope {
+		return dst, true
+	}
+	peer := dst
+	if isInternalAddr(dst) {
+		if src == "" || isInternalAddr(src) {
+			return "", false
+		}
+		peer = src
+	}
+	if ta.bypassed[ta.classifier.ClassifyIP(peer)] {
+		return "", false
+	}
+	return peer, true
+}
+
+// cgnatPrefix is the shared address space VPCs may use as secondary CIDRs.
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// isInternalAddr reports whether an address is private to the VPC or its
+// peered networks. Unparseable addresses are not.
+func isInternalAddr(s string) bool {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return false
+	}
+	return addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || cgnatPrefix.Contains(addr)
+}
+
 // AnalyzeAggregatedResults processes aggregated CloudWatch query results
 func (ta *TrafficAnalyzer) AnalyzeAggregatedResults(results [][]types.ResultField) (*TrafficStats, error) {
 	ta.stats = TrafficStats{SourceIPs: make(map[string]*SourceIPStats)}
 
 	for _, result := range results {
-		var dstAddr string
-		var totalBytes int64
+		var srcAddr, dstAddr, port, protocol string
+		var totalBytes, flowCount int64
 
 		// Extract fields from aggregated result
 		for _, field := range result {
@@ -62,10 +150,20 @@ func (ta *TrafficAnalyzer) AnalyzeAggregatedResults(results [][]types.ResultFiel
 			switch *field.Field {
 			case "pkt_dstaddr", "dstaddr", "resolved_dst":
 				dstAddr = *field.Value
+			case "resolved_src":
+				srcAddr = *field.Value
+			case "service_port":
+				port = *field.Value
+			case "protocol":
+				protocol = *field.Value
 			case "total_bytes":
 				if bytes, err := parseAggregatedBytes(*field.Value); err == nil {
 					totalBytes = bytes
 				}
+			case "flow_count":
+				if n, err := parseAggregatedBytes(*field.Value); err == nil {
+					flowCount = n
+				}
 			}
 		}
 
@@ -73,28 +171,38 @@ func (ta *TrafficAnalyzer) AnalyzeAggregatedResults(results [][]types.ResultFiel
 			continue
 		}
 
+		peer, ok := ta.natPeer(srcAddr, dstAddr)
+		if !ok {
+			continue
+		}
+		dstAddr = peer
 		if dstAddr == "" || dstAddr == "-" {
 			dstAddr = "unknown"
 		}
 
-		service := ta.classifier.ClassifyIP(dstAddr)
+		service, match := ta.classifier.ClassifyIPMatch(dstAddr)
+		registry, regional := ta.classifier.PublicRegistry(dstAddr), ta.classifier.RegionalService(dstAddr)
+		ta.stats.addRegistry(registry, totalBytes)
+		ta.stats.addService(regional, totalBytes)
+		ta.stats.addPlane(planeKey(service, regional), totalBytes, flowCount, port)
+		if protocol != "" {
+			ta.stats.addPort(PortKey(protocol, port), totalBytes)
+		}
 
 		ta.stats.TotalBytes += totalBytes
 		ta.stats.TotalRecords++
+		ta.stats.Accuracy.add(match, totalBytes)
+		if registry != "" || regional != "" {
+			ta.stats.Accuracy.ResolvedBytes += totalBytes
+		}
 
-		switch service {
-		case "s3":
-			ta.stats.S3Bytes += totalBytes
-			ta.stats.S3Records++
-		case "dynamodb":
-			ta.stats.DynamoBytes += totalBytes
-			ta.stats.DynamoRecords++
-		case "ecr":
-			ta.stats.ECRBytes += totalBytes
-			ta.stats.ECRRecords++
-		default:
-			ta.stats.OtherBytes += totalBytes
-			ta.stats.OtherRecords++
+		svc := classified(service)
+		ta.stats.AddService(svc, totalBytes, 1)
+		switch svc {
+		case ServiceS3:
+			ta.stats.addS3Region(ta.classifier.S3Region(dstAddr), totalBytes)
+		case ServiceDynamoDB:
+			ta.stats.addDynamoRegion(ta.classifier.DynamoDBRegion(dstAddr), totalBytes)
 		}
 	}
 
@@ -133,80 +241,308 @@ func (ta *TrafficAnalyzer) AnalyzeFlowLogs(logLines []string) (*TrafficStats, er
 			continue
 		}
 
-		service := ta.classifier.ClassifyIP(record.DstAddr)
+		ta.addRecord(record)
+	}
 
-		ta.stats.TotalBytes += record.Bytes
-		ta.stats.TotalRecords++
+	return &ta.stats, nil
+}
 
-		// Track source IP
-		if _, ok := ta.stats.SourceIPs[record.SrcAddr]; !ok {
-			ta.stats.SourceIPs[record.SrcAddr] = &SourceIPStats{}
-		}
-		ta.stats.SourceIPs[record.SrcAddr].Bytes += record.Bytes
-		ta.stats.SourceIPs[record.SrcAddr].Records++
-
-		switch service {
-		case "s3":
-			ta.stats.S3Bytes += record.Bytes
-			ta.stats.S3Records++
-			ta.stats.SourceIPs[record.SrcAddr].S3 += record.Bytes
-		case "dynamodb":
-			ta.stats.DynamoBytes += record.Bytes
-			ta.stats.DynamoRecords++
-			ta.stats.SourceIPs[record.SrcAddr].Dynamo += record.Bytes
-		case "ecr":
-			ta.stats.ECRBytes += record.Bytes
-			ta.stats.ECRRecords++
-			ta.stats.SourceIPs[record.SrcAddr].ECR += record.Bytes
-		default:
-			ta.stats.OtherBytes += record.Bytes
-			ta.stats.OtherRecords++
-			ta.stats.SourceIPs[record.SrcAddr].Other += record.Bytes
+// AnalyzeFlowLogReader analyzes Flow Log records as delivered to S3: the first
+// line is a header naming the fields, so any log format with dstaddr, bytes,
+// and action columns works.
+func (ta *TrafficAnalyzer) AnalyzeFlowLogReader(r io.Reader) (*TrafficStats, error) {
+	return ta.analyzeFlowLogReader(r, nil)
+}
+
+// AnalyzeNATFlowLogReader is AnalyzeFlowLogReader for Flow Logs that may
+// cover a whole VPC, such as those in a shared S3 bucket: only records of
+// the NAT Gateways' interfaces (any when natENIs is empty, or when the
+// format has no interface-id) to addresses outside the VPC count, so
+// traffic between workloads isn't priced as NAT traffic.
+func (ta *TrafficAnalyzer) AnalyzeNATFlowLogReader(r io.Reader, natENIs map[string]bool) (*TrafficStats, error) {
+	return ta.analyzeFlowLogReader(r, func(record *FlowLogRecord) bool {
+		if len(natENIs) > 0 && record.InterfaceID != "" && !natENIs[record.InterfaceID] {
+			return false
 		}
+		return !isInternalAddr(record.DstAddr)
+	})
+}
+
+// analyzeFlowLogReader is AnalyzeFlowLogReader keeping only the records keep
+// accepts (all when nil).
+func (ta *TrafficAnalyzer) analyzeFlowLogReader(r io.Reader, keep func(*FlowLogRecord) bool) (*TrafficStats, error) {
+	ta.stats = TrafficStats{SourceIPs: make(map[string]*SourceIPStats)}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	if !scanner.Scan() {
+		return &ta.stats, scanner.Err()
+	}
+	layout, err := ParseFlowLogHeader(scanner.Text())
+	if err != nil {
+		return nil, err
 	}
 
+	for scanner.Scan() {
+		if record, ok := layout.Parse(scanner.Text()); ok && (keep == nil || keep(record)) {
+			ta.addRecord(record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read flow logs: %w", err)
+	}
 	return &ta.stats, nil
 }
 
-func (ts *TrafficStats) String() string {
-	return fmt.Sprintf(
-		"Total: %d records, %.2f MB\n"+
-			"  S3: %d records, %.2f MB (%.1f%%)\n"+
-			"  DynamoDB: %d records, %.2f MB (%.1f%%)\n"+
-			"  Other: %d records, %.2f MB (%.1f%%)",
-		ts.TotalRecords, float64(ts.TotalBytes)/(1024*1024),
-		ts.S3Records, float64(ts.S3Bytes)/(1024*1024), ts.S3Percentage(),
-		ts.DynamoRecords, float64(ts.DynamoBytes)/(1024*1024), ts.DynamoPercentage(),
-		ts.OtherRecords, float64(ts.OtherBytes)/(1024*1024), ts.OtherPercentage(),
-	)
-}
-
-func (ts *TrafficStats) S3Percentage() float64 {
-	if ts.TotalBytes == 0 {
-		return 0
+// firehoseLayouts are the layouts of the Flow Logs termiNATor creates (see
+// aws.FlowLogFormat), current and before pkt-dst-aws-service was added.
+// Records delivered through Kinesis Data Firehose carry no header line naming
+// their columns.
+var firehoseLayouts = func() []*FlowLogLayout {
+	const legacy = "interface-id srcaddr dstaddr pkt-srcaddr pkt-dstaddr srcport dstport protocol packets bytes start end action log-status"
+	current, _ := ParseFlowLogHeader(legacy + " pkt-dst-aws-service")
+	previous, _ := ParseFlowLogHeader(legacy)
+	return []*FlowLogLayout{current, previous}
+}()
+
+// parseFirehoseRecord parses line in any of termiNATor's layouts.
+func parseFirehoseRecord(line string) (*FlowLogRecord, bool) {
+	for _, l := range firehoseLayouts {
+		if record, ok := l.Parse(line); ok {
+			return record, true
+		}
 	}
-	return float64(ts.S3Bytes) / float64(ts.TotalBytes) * 100
+	return nil, false
 }
 
-func (ts *TrafficStats) DynamoPercentage() float64 {
-	if ts.TotalBytes == 0 {
-		return 0
+// AnalyzeFirehoseReader classifies Flow Logs records delivered through a
+// Kinesis Data Firehose stream. The stream may carry other Flow Logs too, so
+// only records in termiNATor's own format are read, and of those only the
+// ones of interfaces (any when empty) that started between start and end.
+func (ta *TrafficAnalyzer) AnalyzeFirehoseReader(r io.Reader, interfaces map[string]bool, start, end int64) (*TrafficStats, error) {
+	ta.stats = TrafficStats{SourceIPs: make(map[string]*SourceIPStats)}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		record, ok := parseFirehoseRecord(scanner.Text())
+		if !ok || !strings.HasPrefix(record.InterfaceID, "eni-") || record.Start < start || record.Start > end {
+			continue
+		}
+		if len(interfaces) > 0 && !interfaces[record.InterfaceID] {
+			continue
+		}
+		ta.addRecord(record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read flow logs: %w", err)
 	}
-	return float64(ts.DynamoBytes) / float64(ts.TotalBytes) * 100
+	return &ta.stats, nil
 }
 
-func (ts *TrafficStats) ECRPercentage() float64 {
+func (ta *TrafficAnalyzer) addRecord(record *FlowLogRecord) {
+	peer, ok := ta.natPeer(record.SrcAddr, record.DstAddr)
+	if !ok {
+		return
+	}
+	if peer != record.DstAddr {
+		// A response: the workload is the destination.
+		record.SrcAddr, record.DstAddr = record.DstAddr, peer
+	}
+	service, match := ta.classifier.ClassifyIPMatch(record.DstAddr)
+	registry, regional := ta.classifier.PublicRegistry(record.DstAddr), ta.classifier.RegionalService(record.DstAddr)
+	ta.stats.addRegistry(registry, record.Bytes)
+	ta.stats.addService(regional, record.Bytes)
+	ta.stats.addPlane(planeKey(service, regional), record.Bytes, 1, record.DstPort)
+	if record.Protocol != "" {
+		ta.stats.addPort(PortKey(record.Protocol, ServicePort(record.SrcPort, record.DstPort)), record.Bytes)
+	}
+
+	ta.stats.TotalBytes += record.Bytes
+	ta.stats.TotalRecords++
+	ta.stats.Accuracy.add(match, record.Bytes)
+	if registry != "" || regional != "" {
+		ta.stats.Accuracy.ResolvedBytes += record.Bytes
+	}
+
+	// Track source IP
+	src, ok := ta.stats.SourceIPs[record.SrcAddr]
+	if !ok {
+		src = &SourceIPStats{}
+		ta.stats.SourceIPs[record.SrcAddr] = src
+	}
+	src.Bytes += record.Bytes
+	src.Records++
+
+	svc := classified(service)
+	ta.stats.AddService(svc, record.Bytes, 1)
+	src.add(svc, record.Bytes)
+	switch svc {
+	case ServiceS3:
+		ta.stats.addS3Region(ta.classifier.S3Region(record.DstAddr), record.Bytes)
+	case ServiceDynamoDB:
+		ta.stats.addDynamoRegion(ta.classifier.DynamoDBRegion(record.DstAddr), record.Bytes)
+	}
+}
+
+func (ts *TrafficStats) addS3Region(region string, bytes int64) {
+	if ts.S3BytesByRegion == nil {
+		ts.S3BytesByRegion = make(map[string]int64)
+	}
+	ts.S3BytesByRegion[region] += bytes
+}
+
+func (ts *TrafficStats) addDynamoRegion(region string, bytes int64) {
+	if ts.DynamoBytesByRegion == nil {
+		ts.DynamoBytesByRegion = make(map[string]int64)
+	}
+	ts.DynamoBytesByRegion[region] += bytes
+}
+
+func (ts *TrafficStats) addRegistry(registry string, bytes int64) {
+	if registry == "" {
+		return
+	}
+	if ts.RegistryBytes == nil {
+		ts.RegistryBytes = make(map[string]int64)
+	}
+	ts.RegistryBytes[registry] += bytes
+}
+
+// planeKey is the Planes key for a destination: its regional service when
+// recognized, otherwise its classification bucket.
+func planeKey(service, regional string) string {
+	if regional != "" {
+		return regional
+	}
+	if service == "unknown" {
+		return "other"
+	}
+	return service
+}
+
+func (ts *TrafficStats) addService(service string, bytes int64) {
+	if service == "" {
+		return
+	}
+	if ts.ServiceBytes == nil {
+		ts.ServiceBytes = make(map[string]int64)
+	}
+	ts.ServiceBytes[service] += bytes
+}
+
+// CrossRegionS3Bytes is the S3 traffic to buckets in regions other than
+// region, which a gateway endpoint in region does not carry.
+func (ts *TrafficStats) CrossRegionS3Bytes(region string) int64 {
+	return crossRegionBytes(ts.S3BytesByRegion, region)
+}
+
+// CrossRegionDynamoBytes is the DynamoDB traffic to regions other than
+// region, which a gateway endpoint in region does not carry.
+func (ts *TrafficStats) CrossRegionDynamoBytes(region string) int64 {
+	return crossRegionBytes(ts.DynamoBytesByRegion, region)
+}
+
+// crossRegionBytes sums byRegion over the regions other than region.
+func crossRegionBytes(byRegion map[string]int64, region string) int64 {
+	var bytes int64
+	for r, b := range byRegion {
+		if r != "" && r != region && r != "GLOBAL" {
+			bytes += b
+		}
+	}
+	return bytes
+}
+
+// Merge adds other's counters into ts.
+func (ts *TrafficStats) Merge(other *TrafficStats) {
+	if other == nil {
+		return
+	}
+	for service, s := range other.Services {
+		ts.AddService(service, s.Bytes, s.Records)
+	}
+	ts.TotalBytes += other.TotalBytes
+	ts.TotalRecords += other.TotalRecords
+	ts.Accuracy.ExactBytes += other.Accuracy.ExactBytes
+	ts.Accuracy.BroadEC2Bytes += other.Accuracy.BroadEC2Bytes
+	ts.Accuracy.UnmatchedBytes += other.Accuracy.UnmatchedBytes
+	ts.Accuracy.ResolvedBytes += other.Accuracy.ResolvedBytes
+
+	for region, bytes := range other.S3BytesByRegion {
+		ts.addS3Region(region, bytes)
+	}
+	for region, bytes := range other.DynamoBytesByRegion {
+		ts.addDynamoRegion(region, bytes)
+	}
+	for registry, bytes := range other.RegistryBytes {
+		ts.addRegistry(registry, bytes)
+	}
+	for service, bytes := range other.ServiceBytes {
+		ts.addService(service, bytes)
+	}
+	for key, bytes := range other.PortBytes {
+		ts.addPort(key, bytes)
+	}
+	for service, p := range other.Planes {
+		if ts.Planes == nil {
+			ts.Planes = make(map[string]*PlaneSplit)
+		}
+		cur, ok := ts.Planes[service]
+		if !ok {
+			cur = &PlaneSplit{}
+			ts.Planes[service] = cur
+		}
+		cur.ControlBytes += p.ControlBytes
+		cur.DataBytes += p.DataBytes
+	}
+
+	if len(other.SourceIPs) > 0 && ts.SourceIPs == nil {
+		ts.SourceIPs = make(map[string]*SourceIPStats, len(other.SourceIPs))
+	}
+	for ip, o := range other.SourceIPs {
+		cur, ok := ts.SourceIPs[ip]
+		if !ok {
+			cur = &SourceIPStats{}
+			ts.SourceIPs[ip] = cur
+		}
+		cur.Bytes += o.Bytes
+		cur.Records += o.Records
+		for service, bytes := range o.Services {
+			cur.add(service, bytes)
+		}
+	}
+}
+
+func (ts *TrafficStats) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Total: %s records, %s", units.Count(ts.TotalRecords), units.Format(ts.TotalBytes))
+	for _, service := range ts.ServiceOrder() {
+		fmt.Fprintf(&b, "\n  %s: %s records, %s (%.1f%%)", service.Name(),
+			units.Count(ts.Records(service)), units.Format(ts.Bytes(service)), ts.Percentage(service))
+	}
+	return b.String()
+}
+
+// AccuracyPercentages returns the exact, broad-EC2, and unmatched shares of
+// TotalBytes.
+func (ts *TrafficStats) AccuracyPercentages() (exact, broad, unmatched float64) {
 	if ts.TotalBytes == 0 {
-		return 0
+		return 0, 0, 0
 	}
-	return float64(ts.ECRBytes) / float64(ts.TotalBytes) * 100
+	total := float64(ts.TotalBytes)
+	return float64(ts.Accuracy.ExactBytes) / total * 100,
+		float64(ts.Accuracy.BroadEC2Bytes) / total * 100,
+		float64(ts.Accuracy.UnmatchedBytes) / total * 100
 }
 
-func (ts *TrafficStats) OtherPercentage() float64 {
+// ResolvedPercentage returns the share of TotalBytes sent to addresses
+// recognized from resolved hostnames rather than the published IP ranges.
+func (ts *TrafficStats) ResolvedPercentage() float64 {
 	if ts.TotalBytes == 0 {
 		return 0
 	}
-	return float64(ts.OtherBytes) / float64(ts.TotalBytes) * 100
+	return float64(ts.Accuracy.ResolvedBytes) / float64(ts.TotalBytes) * 100
 }
 
 // TopSourceIPs returns source IPs sorted by bytes descending
@@ -1,6 +1,8 @@
 package analysis
 
 import (
+	"net"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
@@ -27,8 +29,8 @@ func TestAnalyzeAggregatedResultsUsesResolvedDestination(t *testing.T) {
 	if stats.TotalRecords != 1 {
 		t.Fatalf("expected TotalRecords 1, got %d", stats.TotalRecords)
 	}
-	if stats.OtherBytes != 1024 || stats.OtherRecords != 1 {
-		t.Fatalf("expected row to be counted as other traffic, got bytes=%d records=%d", stats.OtherBytes, stats.OtherRecords)
+	if stats.Bytes(ServiceOther) != 1024 || stats.Records(ServiceOther) != 1 {
+		t.Fatalf("expected row to be counted as other traffic, got bytes=%d records=%d", stats.Bytes(ServiceOther), stats.Records(ServiceOther))
 	}
 }
 
@@ -69,8 +71,8 @@ func TestAnalyzeAggregatedResultsDoesNotDropBytesWhenDestinationMissing(t *testi
 	if stats.TotalBytes != 512 || stats.TotalRecords != 1 {
 		t.Fatalf("expected bytes row to be preserved, got bytes=%d records=%d", stats.TotalBytes, stats.TotalRecords)
 	}
-	if stats.OtherBytes != 512 || stats.OtherRecords != 1 {
-		t.Fatalf("expected unknown destination to count as other, got bytes=%d records=%d", stats.OtherBytes, stats.OtherRecords)
+	if stats.Bytes(ServiceOther) != 512 || stats.Records(ServiceOther) != 1 {
+		t.Fatalf("expected unknown destination to count as other, got bytes=%d records=%d", stats.Bytes(ServiceOther), stats.Records(ServiceOther))
 	}
 }
 
@@ -109,3 +111,152 @@ func TestParseAggregatedBytes(t *testing.T) {
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestClassificationAccuracy(t *testing.T) {
+	_, s3Net, _ := net.ParseCIDR("52.216.0.0/15")
+	_, ec2Net, _ := net.ParseCIDR("3.80.0.0/12")
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{
+		s3Ranges:  []*net.IPNet{s3Net},
+		ecrRanges: []*net.IPNet{ec2Net},
+	}}
+
+	results := [][]types.ResultField{
+		{{Field: strPtr("resolved_dst"), Value: strPtr("52.216.0.1")}, {Field: strPtr("total_bytes"), Value: strPtr("600")}},
+		{{Field: strPtr("resolved_dst"), Value: strPtr("3.80.0.1")}, {Field: strPtr("total_bytes"), Value: strPtr("300")}},
+		{{Field: strPtr("resolved_dst"), Value: strPtr("8.8.8.8")}, {Field: strPtr("total_bytes"), Value: strPtr("100")}},
+	}
+
+	stats, err := ta.AnalyzeAggregatedResults(results)
+	if err != nil {
+		t.Fatalf("AnalyzeAggregatedResults returned error: %v", err)
+	}
+	if stats.Accuracy.ExactBytes != 600 || stats.Accuracy.BroadEC2Bytes != 300 || stats.Accuracy.UnmatchedBytes != 100 {
+		t.Fatalf("unexpected accuracy split: %+v", stats.Accuracy)
+	}
+	exact, broad, unmatched := stats.AccuracyPercentages()
+	if exact != 60 || broad != 30 || unmatched != 10 {
+		t.Fatalf("unexpected percentages: %.1f/%.1f/%.1f", exact, broad, unmatched)
+	}
+}
+
+func TestDynamoBytesByRegion(t *testing.T) {
+	_, useNet, _ := net.ParseCIDR("3.218.180.0/22")
+	_, euNet, _ := net.ParseCIDR("52.119.240.0/21")
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{
+		dynamoRanges:  []*net.IPNet{useNet, euNet},
+		dynamoRegions: []string{"us-east-1", "eu-west-1"},
+	}}
+
+	results := [][]types.ResultField{
+		{{Field: strPtr("resolved_dst"), Value: strPtr("3.218.180.10")}, {Field: strPtr("total_bytes"), Value: strPtr("400")}},
+		{{Field: strPtr("resolved_dst"), Value: strPtr("52.119.240.5")}, {Field: strPtr("total_bytes"), Value: strPtr("250")}},
+	}
+
+	stats, err := ta.AnalyzeAggregatedResults(results)
+	if err != nil {
+		t.Fatalf("AnalyzeAggregatedResults returned error: %v", err)
+	}
+	if stats.DynamoBytesByRegion["us-east-1"] != 400 || stats.DynamoBytesByRegion["eu-west-1"] != 250 {
+		t.Fatalf("unexpected per-region split: %v", stats.DynamoBytesByRegion)
+	}
+	if got := stats.CrossRegionDynamoBytes("us-east-1"); got != 250 {
+		t.Fatalf("CrossRegionDynamoBytes = %d, want 250", got)
+	}
+}
+
+func TestS3BytesByRegion(t *testing.T) {
+	_, useNet, _ := net.ParseCIDR("52.216.0.0/15")
+	_, euNet, _ := net.ParseCIDR("52.218.0.0/17")
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{
+		s3Ranges:  []*net.IPNet{useNet, euNet},
+		s3Regions: []string{"us-east-1", "eu-west-1"},
+	}}
+
+	results := [][]types.ResultField{
+		{{Field: strPtr("resolved_dst"), Value: strPtr("52.216.1.10")}, {Field: strPtr("total_bytes"), Value: strPtr("700")}},
+		{{Field: strPtr("resolved_dst"), Value: strPtr("52.218.0.5")}, {Field: strPtr("total_bytes"), Value: strPtr("300")}},
+	}
+
+	stats, err := ta.AnalyzeAggregatedResults(results)
+	if err != nil {
+		t.Fatalf("AnalyzeAggregatedResults returned error: %v", err)
+	}
+	if stats.S3BytesByRegion["us-east-1"] != 700 || stats.S3BytesByRegion["eu-west-1"] != 300 {
+		t.Fatalf("unexpected per-region split: %v", stats.S3BytesByRegion)
+	}
+	if got := stats.CrossRegionS3Bytes("us-east-1"); got != 300 {
+		t.Fatalf("CrossRegionS3Bytes = %d, want 300", got)
+	}
+}
+
+func TestScopeToSubnet(t *testing.T) {
+	_, s3Net, _ := net.ParseCIDR("52.216.0.0/15")
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{s3Ranges: []*net.IPNet{s3Net}}}
+	ta.ScopeToSubnet("s3")
+
+	row := func(src, dst, bytes string) []types.ResultField {
+		return []types.ResultField{
+			{Field: strPtr("resolved_src"), Value: strPtr(src)},
+			{Field: strPtr("resolved_dst"), Value: strPtr(dst)},
+			{Field: strPtr("total_bytes"), Value: strPtr(bytes)},
+		}
+	}
+	stats, err := ta.AnalyzeAggregatedResults([][]types.ResultField{
+		row("10.0.1.5", "10.0.2.9", "5000"),   // intra-VPC, never reaches the NAT Gateway
+		row("10.0.1.5", "52.216.0.1", "4000"), // S3 through the gateway endpoint
+		row("10.0.1.5", "203.0.113.7", "300"), // egress
+		row("203.0.113.7", "10.0.1.5", "700"), // the response
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeAggregatedResults returned error: %v", err)
+	}
+	if stats.TotalBytes != 1000 || stats.Bytes(ServiceS3) != 0 {
+		t.Fatalf("expected only the internet flow and its response, got total=%d s3=%d", stats.TotalBytes, stats.Bytes(ServiceS3))
+	}
+	if stats.TotalRecords != 2 || stats.Bytes(ServiceOther) != 1000 {
+		t.Errorf("expected the response to count with its egress flow, got records=%d other=%d", stats.TotalRecords, stats.Bytes(ServiceOther))
+	}
+}
+
+func TestAnalyzeFirehoseReaderKeepsOwnRecords(t *testing.T) {
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{}}
+	input := strings.Join([]string{
+		"eni-nat 10.0.1.5 52.216.0.1 10.0.1.5 52.216.0.1 443 51000 6 10 1000 1700000100 1700000160 ACCEPT OK",
+		"eni-nat 10.0.1.5 52.216.0.1 - - 443 51000 6 10 200 1700000100 1700000160 REJECT OK",   // rejected
+		"eni-other 10.0.1.5 52.216.0.1 - - 443 51000 6 10 400 1700000100 1700000160 ACCEPT OK", // another interface
+		"eni-nat 10.0.1.5 52.216.0.1 - - 443 51000 6 10 800 1600000000 1600000060 ACCEPT OK",   // before the scan
+		"2 123456789012 eni-nat 10.0.1.5 52.216.0.1 443 51000 6 10 600 1700000100 1700000160 ACCEPT OK",
+		"",
+	}, "\n")
+
+	stats, err := ta.AnalyzeFirehoseReader(strings.NewReader(input), map[string]bool{"eni-nat": true}, 1700000000, 1700000300)
+	if err != nil {
+		t.Fatalf("AnalyzeFirehoseReader returned error: %v", err)
+	}
+	if stats.TotalRecords != 1 || stats.TotalBytes != 1000 {
+		t.Fatalf("expected only the scan's own record, got records=%d bytes=%d", stats.TotalRecords, stats.TotalBytes)
+	}
+
+	stats, err = ta.AnalyzeFirehoseReader(strings.NewReader(input), nil, 1700000000, 1700000300)
+	if err != nil {
+		t.Fatalf("AnalyzeFirehoseReader returned error: %v", err)
+	}
+	if stats.TotalBytes != 1400 {
+		t.Errorf("expected records of every interface without a filter, got bytes=%d", stats.TotalBytes)
+	}
+}
+
+func TestAnalyzeFirehoseReaderReadsBothLayouts(t *testing.T) {
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{}}
+	input := strings.Join([]string{
+		"eni-nat 10.0.1.5 52.216.0.1 10.0.1.5 52.216.0.1 51000 443 6 10 1000 1700000100 1700000160 ACCEPT OK S3",
+		"eni-nat 10.0.1.5 52.216.0.1 10.0.1.5 52.216.0.1 51000 443 6 10 500 1700000100 1700000160 ACCEPT OK",
+	}, "\n")
+	stats, err := ta.AnalyzeFirehoseReader(strings.NewReader(input), nil, 1700000000, 1700000300)
+	if err != nil {
+		t.Fatalf("AnalyzeFirehoseReader returned error: %v", err)
+	}
+	if stats.TotalRecords != 2 || stats.TotalBytes != 1500 {
+		t.Errorf("expected records with and without pkt-dst-aws-service, got records=%d bytes=%d", stats.TotalRecords, stats.TotalBytes)
+	}
+}
@@ -0,0 +1,237 @@
+package analysis
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/doitintl/terminator/internal/units"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// azImbalanceShare is the share of traffic one AZ must carry before the
+// breakdown flags it as a hot spot.
+const azImbalanceShare = 60.0
+
+// ZoneSample is traffic of one availability zone during the sample: what a
+// single NAT Gateway processed, or what the subnets of a zone sent through
+// it (see ZoneSamplesBySource).
+type ZoneSample struct {
+	NATID            string
+	AvailabilityZone string // empty for regional NAT Gateways
+	Stats            *TrafficStats
+}
+
+// AZTraffic is the sampled traffic and projected monthly cost of one availability zone.
+type AZTraffic struct {
+	AvailabilityZone string   `json:"availability_zone"`
+	NATGateways      []string `json:"nat_gateways"`
+	TotalBytes       int64    `json:"total_bytes"`
+	S3Bytes          int64    `json:"s3_bytes"`
+	DynamoBytes      int64    `json:"dynamodb_bytes"`
+	SharePct         float64  `json:"share_pct"`
+	MonthlyCost      float64  `json:"projected_monthly_cost"`
+	MonthlySavings   float64  `json:"projected_monthly_savings"`
+}
+
+// BreakdownByAZ groups per-NAT traffic by availability zone and projects each
+// zone's monthly cost the same way CalculateCosts does. Zones are returned
+// busiest first.
+func BreakdownByAZ(region string, samples []ZoneSample, collectionMinutes int) []AZTraffic {
+	if collectionMinutes <= 0 {
+		return nil
+	}
+
+	byAZ := make(map[string]*AZTraffic)
+	var total int64
+	for _, s := range samples {
+		if s.Stats == nil {
+			continue
+		}
+		az := s.AvailabilityZone
+		if az == "" {
+			az = "regional"
+		}
+		t, ok := byAZ[az]
+		if !ok {
+			t = &AZTraffic{AvailabilityZone: az}
+			byAZ[az] = t
+		}
+		if s.NATID != "" && !slices.Contains(t.NATGateways, s.NATID) {
+			t.NATGateways = append(t.NATGateways, s.NATID)
+		}
+		t.TotalBytes += s.Stats.TotalBytes
+		t.S3Bytes += s.Stats.Bytes(ServiceS3)
+		t.DynamoBytes += s.Stats.Bytes(ServiceDynamoDB)
+		total += s.Stats.TotalBytes
+	}
+
+	monthlyMultiplier := 43200.0 / float64(collectionMinutes)
+	pricePerGB := NATGatewayPricePerGB(region)
+	zones := make([]AZTraffic, 0, len(byAZ))
+	for _, t := range byAZ {
+		if total > 0 {
+			t.SharePct = float64(t.TotalBytes) / float64(total) * 100
+		}
+		t.MonthlyCost = units.BillingGB(t.TotalBytes) * monthlyMultiplier * pricePerGB
+		t.MonthlySavings = units.BillingGB(t.S3Bytes+t.DynamoBytes) * monthlyMultiplier * pricePerGB
+		zones = append(zones, *t)
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		if zones[i].TotalBytes != zones[j].TotalBytes {
+			return zones[i].TotalBytes > zones[j].TotalBytes
+		}
+		return zones[i].AvailabilityZone < zones[j].AvailabilityZone
+	})
+	return zones
+}
+
+// AZImbalance describes a zone carrying most of the traffic, or returns ""
+// when traffic is spread evenly or only one zone was sampled.
+func AZImbalance(zones []AZTraffic) string {
+	if len(zones) < 2 || zones[0].SharePct < azImbalanceShare {
+		return ""
+	}
+	return fmt.Sprintf("%s carries %.0f%% of NAT traffic across %d zones. Check that private subnets in every AZ route to a NAT Gateway in the same AZ, and that endpoint DNS resolves to zone-local addresses; cross-AZ hops add $0.01/GB each way on top of NAT processing.",
+		zones[0].AvailabilityZone, zones[0].SharePct, len(zones))
+}
+
+// SourceTraffic is what one source address sent through one NAT Gateway
+// interface during the sample.
+type SourceTraffic struct {
+	InterfaceID string
+	Addr        string
+	Bytes       int64
+	S3Bytes     int64
+	DynamoBytes int64
+}
+
+// ParseSourceTraffic reads the rows of a query summing NAT traffic by
+// interface_id, resolved_src and aws_service (pkt-dst-aws-service).
+func ParseSourceTraffic(results [][]cwltypes.ResultField) []SourceTraffic {
+	type key struct{ iface, addr string }
+	bySource := map[key]*SourceTraffic{}
+	var order []key
+	for _, row := range results {
+		var iface, addr, service string
+		var bytes int64
+		for _, field := range row {
+			if field.Field == nil || field.Value == nil {
+				continue
+			}
+			switch *field.Field {
+			case "interface_id":
+				iface = *field.Value
+			case "resolved_src":
+				addr = *field.Value
+			case "aws_service":
+				service = *field.Value
+			case "total_bytes":
+				bytes, _ = parseAggregatedBytes(*field.Value)
+			}
+		}
+		if bytes == 0 || addr == "" || addr == "-" {
+			continue
+		}
+		k := key{iface, addr}
+		t, ok := bySource[k]
+		if !ok {
+			t = &SourceTraffic{InterfaceID: iface, Addr: addr}
+			bySource[k] = t
+			order = append(order, k)
+		}
+		t.Bytes += bytes
+		switch service {
+		case "S3":
+			t.S3Bytes += bytes
+		case "DYNAMODB":
+			t.DynamoBytes += bytes
+		}
+	}
+	sources := make([]SourceTraffic, 0, len(order))
+	for _, k := range order {
+		sources = append(sources, *bySource[k])
+	}
+	return sources
+}
+
+// ZoneSamplesBySource attributes NAT traffic to the availability zone of
+// the subnet each source address is in, so a NAT Gateway serving several
+// zones shows what each of them sends. What the NAT Gateways send from
+// their own addresses, and what comes from outside the subnets (responses),
+// is left out; the rest is scaled to total, the traffic the scan measured.
+// It returns nil when no source is in one of subnets.
+func ZoneSamplesBySource(sources []SourceTraffic, subnets []types.Subnet, nats []types.NATGateway, total *TrafficStats) []ZoneSample {
+	type zoneSubnet struct {
+		prefix netip.Prefix
+		az     string
+	}
+	var zones []zoneSubnet
+	for _, s := range subnets {
+		if prefix, err := netip.ParsePrefix(s.CIDR); err == nil && s.AvailabilityZone != "" {
+			zones = append(zones, zoneSubnet{prefix, s.AvailabilityZone})
+		}
+	}
+	natByENI := map[string]string{}
+	natAddrs := map[string]bool{}
+	for _, nat := range nats {
+		if nat.NetworkInterfaceID != "" {
+			natByENI[nat.NetworkInterfaceID] = nat.ID
+		}
+		for _, ip := range nat.PrivateIPs {
+			natAddrs[ip] = true
+		}
+	}
+
+	type key struct{ nat, az string }
+	byZone := map[key]*TrafficStats{}
+	var keys []key
+	var sum int64
+	for _, src := range sources {
+		addr, err := netip.ParseAddr(src.Addr)
+		if err != nil || natAddrs[src.Addr] {
+			continue
+		}
+		az := ""
+		for _, z := range zones {
+			if z.prefix.Contains(addr) {
+				az = z.az
+				break
+			}
+		}
+		if az == "" {
+			continue
+		}
+		k := key{natByENI[src.InterfaceID], az}
+		stats, ok := byZone[k]
+		if !ok {
+			stats = &TrafficStats{Services: map[Service]ServiceStats{}}
+			byZone[k] = stats
+			keys = append(keys, k)
+		}
+		stats.TotalBytes += src.Bytes
+		stats.Services[ServiceS3] = ServiceStats{Bytes: stats.Bytes(ServiceS3) + src.S3Bytes}
+		stats.Services[ServiceDynamoDB] = ServiceStats{Bytes: stats.Bytes(ServiceDynamoDB) + src.DynamoBytes}
+		sum += src.Bytes
+	}
+	if sum == 0 {
+		return nil
+	}
+
+	scale := 1.0
+	if total != nil && total.TotalBytes > 0 {
+		scale = float64(total.TotalBytes) / float64(sum)
+	}
+	samples := make([]ZoneSample, 0, len(keys))
+	for _, k := range keys {
+		stats := byZone[k]
+		stats.TotalBytes = int64(float64(stats.TotalBytes) * scale)
+		for svc, st := range stats.Services {
+			stats.Services[svc] = ServiceStats{Bytes: int64(float64(st.Bytes) * scale)}
+		}
+		samples = append(samples, ZoneSample{NATID: k.nat, AvailabilityZone: k.az, Stats: stats})
+	}
+	return samples
+}
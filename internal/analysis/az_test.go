@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestBreakdownByAZ(t *testing.T) {
+	gb := int64(1024 * 1024 * 1024)
+	samples := []ZoneSample{
+		{NATID: "nat-a", AvailabilityZone: "us-east-1a", Stats: &TrafficStats{TotalBytes: 6 * gb, Services: map[Service]ServiceStats{ServiceS3: {Bytes: 2 * gb}}}},
+		{NATID: "nat-b", AvailabilityZone: "us-east-1b", Stats: &TrafficStats{TotalBytes: 1 * gb}},
+		{NATID: "nat-c", AvailabilityZone: "us-east-1a", Stats: &TrafficStats{TotalBytes: 1 * gb, Services: map[Service]ServiceStats{ServiceDynamoDB: {Bytes: gb}}}},
+		{NATID: "nat-r", Stats: &TrafficStats{TotalBytes: 2 * gb}},
+		{NATID: "nat-failed", AvailabilityZone: "us-east-1c"},
+	}
+
+	zones := BreakdownByAZ("us-east-1", samples, 60)
+	if len(zones) != 3 {
+		t.Fatalf("expected 3 zones, got %d: %+v", len(zones), zones)
+	}
+	a := zones[0]
+	if a.AvailabilityZone != "us-east-1a" || len(a.NATGateways) != 2 {
+		t.Errorf("busiest zone = %+v, want us-east-1a with two NAT Gateways", a)
+	}
+	assertApprox(t, a.SharePct, 70, 0.001, "us-east-1a share")
+	// 7 GB/hour * 720 hours * $0.045
+	assertApprox(t, a.MonthlyCost, 7*720*0.045, 0.001, "us-east-1a monthly cost")
+	assertApprox(t, a.MonthlySavings, 3*720*0.045, 0.001, "us-east-1a monthly savings")
+	if zones[1].AvailabilityZone != "regional" {
+		t.Errorf("regional NAT traffic should be grouped as \"regional\", got %q", zones[1].AvailabilityZone)
+	}
+
+	if msg := AZImbalance(zones); !strings.Contains(msg, "us-east-1a carries 70%") {
+		t.Errorf("expected imbalance warning for us-east-1a, got %q", msg)
+	}
+}
+
+func TestAZImbalanceBalanced(t *testing.T) {
+	zones := []AZTraffic{
+		{AvailabilityZone: "us-east-1a", SharePct: 55},
+		{AvailabilityZone: "us-east-1b", SharePct: 45},
+	}
+	if msg := AZImbalance(zones); msg != "" {
+		t.Errorf("balanced zones should not be flagged, got %q", msg)
+	}
+	if msg := AZImbalance(zones[:1]); msg != "" {
+		t.Errorf("a single zone should not be flagged, got %q", msg)
+	}
+	if zones := BreakdownByAZ("us-east-1", nil, 0); zones != nil {
+		t.Errorf("zero-minute sample should yield no breakdown, got %+v", zones)
+	}
+}
+
+func TestParseSourceTraffic(t *testing.T) {
+	results := [][]cwltypes.ResultField{
+		row("interface_id", "eni-nat", "resolved_src", "10.0.1.5", "aws_service", "S3", "total_bytes", "300"),
+		row("interface_id", "eni-nat", "resolved_src", "10.0.1.5", "aws_service", "-", "total_bytes", "700"),
+		row("interface_id", "eni-nat", "resolved_src", "10.0.2.9", "aws_service", "DYNAMODB", "total_bytes", "1.5e3"),
+		row("interface_id", "eni-nat", "resolved_src", "-", "total_bytes", "50"),
+	}
+
+	sources := ParseSourceTraffic(results)
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 sources, got %d: %+v", len(sources), sources)
+	}
+	if s := sources[0]; s.Addr != "10.0.1.5" || s.Bytes != 1000 || s.S3Bytes != 300 {
+		t.Errorf("first source = %+v, want 10.0.1.5 with 1000 bytes, 300 to S3", s)
+	}
+	if s := sources[1]; s.Addr != "10.0.2.9" || s.Bytes != 1500 || s.DynamoBytes != 1500 {
+		t.Errorf("second source = %+v, want 10.0.2.9 with 1500 bytes to DynamoDB", s)
+	}
+}
+
+func TestZoneSamplesBySource(t *testing.T) {
+	gb := int64(1024 * 1024 * 1024)
+	subnets := []types.Subnet{
+		{ID: "subnet-a", CIDR: "10.0.1.0/24", AvailabilityZone: "us-east-1a"},
+		{ID: "subnet-b", CIDR: "10.0.2.0/24", AvailabilityZone: "us-east-1b"},
+		{ID: "subnet-c", CIDR: "10.0.3.0/24", AvailabilityZone: "us-east-1c"},
+	}
+	// One NAT Gateway in us-east-1a serves all three zones
+	nats := []types.NATGateway{{ID: "nat-a", AvailabilityZone: "us-east-1a", NetworkInterfaceID: "eni-nat", PrivateIPs: []string{"10.0.1.10"}}}
+	sources := []SourceTraffic{
+		{InterfaceID: "eni-nat", Addr: "10.0.1.5", Bytes: gb},
+		{InterfaceID: "eni-nat", Addr: "10.0.2.9", Bytes: 6 * gb, S3Bytes: 2 * gb},
+		{InterfaceID: "eni-nat", Addr: "10.0.3.7", Bytes: gb},
+		{InterfaceID: "eni-nat", Addr: "10.0.1.10", Bytes: 5 * gb},  // the NAT Gateway itself
+		{InterfaceID: "eni-nat", Addr: "52.216.0.1", Bytes: 5 * gb}, // responses
+	}
+	total := &TrafficStats{TotalBytes: 16 * gb}
+
+	samples := ZoneSamplesBySource(sources, subnets, nats, total)
+	if len(samples) != 3 {
+		t.Fatalf("expected 3 zone samples, got %d: %+v", len(samples), samples)
+	}
+
+	zones := BreakdownByAZ("us-east-1", samples, 60)
+	b := zones[0]
+	if b.AvailabilityZone != "us-east-1b" || len(b.NATGateways) != 1 || b.NATGateways[0] != "nat-a" {
+		t.Errorf("busiest zone = %+v, want us-east-1b through nat-a", b)
+	}
+	// 6 of the 8 GB that matched a subnet, scaled to the 16 GB measured
+	if b.TotalBytes != 12*gb || b.S3Bytes != 4*gb {
+		t.Errorf("us-east-1b bytes = %d (S3 %d), want %d (S3 %d)", b.TotalBytes, b.S3Bytes, 12*gb, 4*gb)
+	}
+	assertApprox(t, b.SharePct, 75, 0.001, "us-east-1b share")
+	if msg := AZImbalance(zones); !strings.Contains(msg, "us-east-1b carries 75%") {
+		t.Errorf("expected imbalance warning for us-east-1b, got %q", msg)
+	}
+}
+
+func TestZoneSamplesBySourceNoMatch(t *testing.T) {
+	subnets := []types.Subnet{{ID: "subnet-a", CIDR: "10.0.1.0/24", AvailabilityZone: "us-east-1a"}}
+	sources := []SourceTraffic{{InterfaceID: "eni-nat", Addr: "192.168.0.1", Bytes: 100}}
+	if samples := ZoneSamplesBySource(sources, subnets, nil, &TrafficStats{TotalBytes: 100}); samples != nil {
+		t.Errorf("expected nil without a matching subnet, got %+v", samples)
+	}
+}
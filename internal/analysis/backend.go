@@ -0,0 +1,138 @@
+package analysis
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// ErrNoRecords is returned by backends when the window holds no Flow Logs
+// records to classify.
+var ErrNoRecords = errors.New("query returned no results")
+
+// AnalysisBackend classifies the Flow Logs records of one source over a time
+// window: a CloudWatch Logs log group queried with Logs Insights, Flow Logs
+// objects parsed locally, an Athena table or a file. Backends only fetch
+// records; classification is the same for all of them.
+type AnalysisBackend interface {
+	// Name identifies the backend in messages, e.g. "logs-insights".
+	Name() string
+	// Traffic classifies the ACCEPT records of the query's window. It
+	// returns ErrNoRecords when there are none.
+	Traffic(ctx context.Context, q TrafficQuery) (*TrafficStats, error)
+}
+
+// TrafficQuery is what a backend classifies.
+type TrafficQuery struct {
+	// Start and End bound the window, in Unix seconds.
+	Start, End int64
+	// ENI limits the records to one network interface when set.
+	ENI string
+	// ENIs limits the records to any of several network interfaces when
+	// set, e.g. the NAT Gateways' in a VPC-wide Flow Logs group.
+	ENIs []string
+	// Subnet marks the records as Flow Logs of a workload subnet or network
+	// interface instead of NAT Gateways (see TrafficAnalyzer.ScopeToSubnet).
+	Subnet *types.SubnetEgress
+	// Region and AccountID, when set, let regional AWS API endpoints be
+	// recognized (see TrafficAnalyzer.RecognizeRegionalServices).
+	Region, AccountID string
+}
+
+// interfaces lists the network interfaces the records are limited to, none
+// for all.
+func (q TrafficQuery) interfaces() []string {
+	if q.ENI == "" {
+		return q.ENIs
+	}
+	return append([]string{q.ENI}, q.ENIs...)
+}
+
+// keepsInterface reports whether records of eni are in the query. Records
+// without an interface-id column are always kept.
+func (q TrafficQuery) keepsInterface(eni string) bool {
+	enis := q.interfaces()
+	return len(enis) == 0 || eni == "" || slices.Contains(enis, eni)
+}
+
+// newAnalyzer returns an analyzer set up for the query, from newTA (nil for
+// NewTrafficAnalyzer).
+func (q TrafficQuery) newAnalyzer(newTA func() (*TrafficAnalyzer, error)) (*TrafficAnalyzer, error) {
+	if newTA == nil {
+		newTA = NewTrafficAnalyzer
+	}
+	analyzer, err := newTA()
+	if err != nil {
+		return nil, err
+	}
+	if q.Region != "" {
+		analyzer.RecognizeRegionalServices(q.Region, q.AccountID)
+	}
+	if q.Subnet != nil && q.Subnet.EndpointID == "" {
+		analyzer.ScopeToSubnet(q.Subnet.BypassedServices...)
+	}
+	return analyzer, nil
+}
+
+// FileBackend classifies the Flow Logs records in a local file, in the format
+// they are delivered to S3: a header line naming the fields, then one record
+// per line, gzip-compressed when the name ends in .gz. Records are only
+// filtered by window and interface when the file has start and interface-id
+// columns.
+type FileBackend struct {
+	path string
+	// newAnalyzer replaces NewTrafficAnalyzer in tests.
+	newAnalyzer func() (*TrafficAnalyzer, error)
+}
+
+// NewFileBackend returns a backend reading path.
+func NewFileBackend(path string) *FileBackend {
+	return &FileBackend{path: path}
+}
+
+// Name implements AnalysisBackend.
+func (b *FileBackend) Name() string { return "file" }
+
+// Traffic implements AnalysisBackend.
+func (b *FileBackend) Traffic(ctx context.Context, q TrafficQuery) (*TrafficStats, error) {
+	f, err := os.Open(b.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open flow logs file: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(b.path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress %s: %w", b.path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	analyzer, err := q.newAnalyzer(b.newAnalyzer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analyzer: %w", err)
+	}
+	stats, err := analyzer.analyzeFlowLogReader(r, func(record *FlowLogRecord) bool {
+		if record.Start != 0 && (record.Start < q.Start || record.Start > q.End) {
+			return false
+		}
+		return q.keepsInterface(record.InterfaceID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stats.TotalRecords == 0 {
+		return nil, ErrNoRecords
+	}
+	return stats, nil
+}
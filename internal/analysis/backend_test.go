@@ -0,0 +1,153 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// fakeRunner answers Logs Insights queries with canned rows: aggregated
+// rows for the stats query, raw rows for the @message fallback.
+type fakeRunner struct {
+	aggregated, raw [][]cwltypes.ResultField
+	queries         []string
+}
+
+func (f *fakeRunner) RunQuery(ctx context.Context, logGroupName string, startTime, endTime int64, query string) ([][]cwltypes.ResultField, error) {
+	f.queries = append(f.queries, query)
+	if strings.Contains(query, "stats ") {
+		return f.aggregated, nil
+	}
+	return f.raw, nil
+}
+
+func testAnalyzer() (*TrafficAnalyzer, error) {
+	_, s3Net, _ := net.ParseCIDR("52.216.0.0/15")
+	return &TrafficAnalyzer{classifier: &TrafficClassifier{s3Ranges: []*net.IPNet{s3Net}}}, nil
+}
+
+func TestLogsInsightsBackend(t *testing.T) {
+	runner := &fakeRunner{aggregated: [][]cwltypes.ResultField{
+		{{Field: strPtr("resolved_dst"), Value: strPtr("52.216.0.1")}, {Field: strPtr("total_bytes"), Value: strPtr("600")}, {Field: strPtr("flow_count"), Value: strPtr("2")}},
+		{{Field: strPtr("resolved_dst"), Value: strPtr("8.8.8.8")}, {Field: strPtr("total_bytes"), Value: strPtr("400")}, {Field: strPtr("flow_count"), Value: strPtr("1")}},
+	}}
+	var backend AnalysisBackend = &LogsInsightsBackend{runner: runner, logGroup: "/terminator/test", newAnalyzer: testAnalyzer}
+
+	stats, err := backend.Traffic(context.Background(), TrafficQuery{Start: 1, End: 2, ENI: "eni-nat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Bytes(ServiceS3) != 600 || stats.TotalBytes != 1000 {
+		t.Errorf("S3=%d total=%d", stats.Bytes(ServiceS3), stats.TotalBytes)
+	}
+	if len(runner.queries) != 1 || !strings.Contains(runner.queries[0], `f1 = "eni-nat"`) {
+		t.Errorf("queries = %q", runner.queries)
+	}
+
+	if _, err := backend.Traffic(context.Background(), TrafficQuery{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(runner.queries[1], "f1 =") {
+		t.Errorf("query without an ENI should not filter on one: %s", runner.queries[1])
+	}
+	empty := &LogsInsightsBackend{runner: &fakeRunner{}, newAnalyzer: testAnalyzer}
+	if _, err := empty.Traffic(context.Background(), TrafficQuery{}); !errors.Is(err, ErrNoRecords) {
+		t.Errorf("err = %v, want ErrNoRecords", err)
+	}
+}
+
+func TestLogsInsightsBackendFallsBackToRawMessages(t *testing.T) {
+	runner := &fakeRunner{
+		// Rows whose byte counts don't parse yield no records
+		aggregated: [][]cwltypes.ResultField{{{Field: strPtr("resolved_dst"), Value: strPtr("52.216.0.1")}, {Field: strPtr("total_bytes"), Value: strPtr("n/a")}}},
+		raw: [][]cwltypes.ResultField{{{Field: strPtr("@message"), Value: strPtr(
+			"eni-nat 10.0.1.5 52.216.0.1 10.0.1.5 52.216.0.1 51000 443 6 10 700 1700000100 1700000160 ACCEPT OK")}}},
+	}
+	backend := &LogsInsightsBackend{runner: runner, newAnalyzer: testAnalyzer}
+	stats, err := backend.Traffic(context.Background(), TrafficQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Bytes(ServiceS3) != 700 || len(runner.queries) != 2 {
+		t.Errorf("S3=%d after %d queries", stats.Bytes(ServiceS3), len(runner.queries))
+	}
+}
+
+func TestFileBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flows.log")
+	lines := strings.Join([]string{
+		"version account-id interface-id srcaddr dstaddr srcport dstport protocol packets bytes start end action log-status",
+		"2 123456789012 eni-nat 10.0.1.5 52.216.0.1 51000 443 6 10 500 1700000100 1700000160 ACCEPT OK",
+		"2 123456789012 eni-nat 10.0.1.5 8.8.8.8 51000 443 6 10 300 1700000100 1700000160 ACCEPT OK",
+		"2 123456789012 eni-other 10.0.1.5 52.216.0.1 51000 443 6 10 900 1700000100 1700000160 ACCEPT OK",
+		"2 123456789012 eni-nat 10.0.1.5 52.216.0.1 51000 443 6 10 800 1600000000 1600000060 ACCEPT OK",
+	}, "\n")
+	if err := os.WriteFile(path, []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	backend := &FileBackend{path: path, newAnalyzer: testAnalyzer}
+
+	stats, err := backend.Traffic(context.Background(), TrafficQuery{Start: 1700000000, End: 1700000300, ENI: "eni-nat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalBytes != 800 || stats.Bytes(ServiceS3) != 500 {
+		t.Errorf("total=%d S3=%d, want only eni-nat records in the window", stats.TotalBytes, stats.Bytes(ServiceS3))
+	}
+	if _, err := backend.Traffic(context.Background(), TrafficQuery{Start: 1800000000, End: 1800000300}); !errors.Is(err, ErrNoRecords) {
+		t.Errorf("err = %v, want ErrNoRecords outside the window", err)
+	}
+}
+
+// mixedENIFlows are a VPC-wide Flow Logs group's records of one S3 upload,
+// seen on the instance and on the NAT Gateway it goes through, plus traffic
+// of an instance in a public subnet going out through the internet gateway.
+var mixedENIFlows = []string{
+	"version account-id interface-id srcaddr dstaddr srcport dstport protocol packets bytes start end action log-status",
+	"2 123456789012 eni-web 10.0.1.5 52.216.0.1 51000 443 6 10 500 1700000100 1700000160 ACCEPT OK",
+	"2 123456789012 eni-nat-a 10.0.1.5 52.216.0.1 51000 443 6 10 500 1700000100 1700000160 ACCEPT OK",
+	"2 123456789012 eni-nat-b 10.0.2.7 8.8.8.8 51000 443 6 10 200 1700000100 1700000160 ACCEPT OK",
+	"2 123456789012 eni-public 10.0.0.9 52.216.0.1 51000 443 6 10 4000 1700000100 1700000160 ACCEPT OK",
+}
+
+func TestBackendsKeepOnlyNATInterfaces(t *testing.T) {
+	q := TrafficQuery{Start: 1700000000, End: 1700000300, ENIs: []string{"eni-nat-a", "eni-nat-b"}}
+
+	path := filepath.Join(t.TempDir(), "flows.log")
+	if err := os.WriteFile(path, []byte(strings.Join(mixedENIFlows, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := (&FileBackend{path: path, newAnalyzer: testAnalyzer}).Traffic(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalBytes != 700 || stats.Bytes(ServiceS3) != 500 {
+		t.Errorf("total=%d S3=%d, want only the NAT Gateways' 700 bytes, S3 counted once", stats.TotalBytes, stats.Bytes(ServiceS3))
+	}
+
+	runner := &fakeRunner{
+		aggregated: [][]cwltypes.ResultField{{{Field: strPtr("resolved_dst"), Value: strPtr("52.216.0.1")}, {Field: strPtr("total_bytes"), Value: strPtr("500")}, {Field: strPtr("flow_count"), Value: strPtr("1")}}},
+		raw:        [][]cwltypes.ResultField{{{Field: strPtr("@message"), Value: strPtr("eni-nat-a 10.0.1.5 52.216.0.1 10.0.1.5 52.216.0.1 51000 443 6 10 500 1700000100 1700000160 ACCEPT OK")}}},
+	}
+	if _, err := (&LogsInsightsBackend{runner: runner, newAnalyzer: testAnalyzer}).Traffic(context.Background(), q); err != nil {
+		t.Fatal(err)
+	}
+	if len(runner.queries) != 1 || !strings.Contains(runner.queries[0], `f1 in ["eni-nat-a", "eni-nat-b"]`) {
+		t.Errorf("stats query does not filter on the NAT interfaces: %q", runner.queries)
+	}
+
+	// Rows whose byte counts don't parse fall back to the raw messages
+	runner.aggregated = [][]cwltypes.ResultField{{{Field: strPtr("resolved_dst"), Value: strPtr("52.216.0.1")}, {Field: strPtr("total_bytes"), Value: strPtr("n/a")}}}
+	if _, err := (&LogsInsightsBackend{runner: runner, newAnalyzer: testAnalyzer}).Traffic(context.Background(), q); err != nil {
+		t.Fatal(err)
+	}
+	if last := runner.queries[len(runner.queries)-1]; !strings.Contains(last, "like /eni-nat-a|eni-nat-b/") {
+		t.Errorf("raw query does not filter on the NAT interfaces: %s", last)
+	}
+}
@@ -0,0 +1,61 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"github.com/doitintl/terminator/internal/units"
+)
+
+// DailyTraffic is the traffic analyzed for one UTC day of existing Flow Logs.
+type DailyTraffic struct {
+	Day   time.Time
+	Stats *TrafficStats
+}
+
+// MonthlyTraffic aggregates the backfilled days of one calendar month.
+type MonthlyTraffic struct {
+	Month string // YYYY-MM
+	Days  int    // days that had Flow Logs data
+	Stats *TrafficStats
+}
+
+// NATCost is the NAT Gateway data processing charge for the month's traffic.
+func (m MonthlyTraffic) NATCost(region string) float64 {
+	return units.BillingGB(m.Stats.TotalBytes) * NATGatewayPricePerGB(region)
+}
+
+// GatewaySavings is the part of NATCost that free S3/DynamoDB gateway endpoints
+// in region avoid. S3 and DynamoDB traffic to other regions stays on the NAT
+// Gateway.
+func (m MonthlyTraffic) GatewaySavings(region string) float64 {
+	s3 := m.Stats.Bytes(ServiceS3) - m.Stats.CrossRegionS3Bytes(region)
+	dynamo := m.Stats.Bytes(ServiceDynamoDB) - m.Stats.CrossRegionDynamoBytes(region)
+	return units.BillingGB(s3+dynamo) * NATGatewayPricePerGB(region)
+}
+
+// GroupByMonth merges daily traffic into calendar months, oldest first. Days
+// without any records do not count towards Days.
+func GroupByMonth(days []DailyTraffic) []MonthlyTraffic {
+	byMonth := map[string]*MonthlyTraffic{}
+	for _, d := range days {
+		key := d.Day.UTC().Format("2006-01")
+		m, ok := byMonth[key]
+		if !ok {
+			m = &MonthlyTraffic{Month: key, Stats: &TrafficStats{SourceIPs: map[string]*SourceIPStats{}}}
+			byMonth[key] = m
+		}
+		if d.Stats == nil || d.Stats.TotalRecords == 0 {
+			continue
+		}
+		m.Days++
+		m.Stats.Merge(d.Stats)
+	}
+
+	months := make([]MonthlyTraffic, 0, len(byMonth))
+	for _, m := range byMonth {
+		months = append(months, *m)
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Month < months[j].Month })
+	return months
+}
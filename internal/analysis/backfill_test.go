@@ -0,0 +1,101 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeFlowLogReaderDefaultFormat(t *testing.T) {
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{}}
+	data := strings.Join([]string{
+		"version account-id interface-id srcaddr dstaddr srcport dstport protocol packets bytes start end action log-status",
+		"2 123456789012 eni-1 10.0.1.5 52.216.0.1 443 51000 6 10 1000 1 2 ACCEPT OK",
+		"2 123456789012 eni-1 10.0.1.5 52.216.0.1 443 51000 6 10 500 1 2 REJECT OK",
+		"2 123456789012 eni-1 - - - - - - - 1 2 - NODATA",
+	}, "\n")
+
+	stats, err := ta.AnalyzeFlowLogReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("AnalyzeFlowLogReader returned error: %v", err)
+	}
+	if stats.TotalRecords != 1 || stats.TotalBytes != 1000 {
+		t.Fatalf("expected only the ACCEPT record, got records=%d bytes=%d", stats.TotalRecords, stats.TotalBytes)
+	}
+	if stats.SourceIPs["10.0.1.5"] == nil {
+		t.Fatal("expected source IP 10.0.1.5 to be tracked")
+	}
+}
+
+func TestAnalyzeNATFlowLogReaderKeepsNATEgress(t *testing.T) {
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{}}
+	data := strings.Join([]string{
+		"version account-id interface-id srcaddr dstaddr srcport dstport protocol packets bytes start end action log-status",
+		"2 123456789012 eni-nat 10.0.1.5 52.216.0.1 51000 443 6 10 1000 1 2 ACCEPT OK",
+		"2 123456789012 eni-nat 10.0.1.5 10.0.2.9 51000 5432 6 10 200 1 2 ACCEPT OK",
+		"2 123456789012 eni-app 10.0.1.5 52.216.0.1 51000 443 6 10 4000 1 2 ACCEPT OK",
+		"2 123456789012 eni-app 10.0.1.5 10.0.2.9 51000 5432 6 10 8000 1 2 ACCEPT OK",
+		"2 123456789012 eni-nat 10.0.1.6 100.64.3.2 51000 443 6 10 300 1 2 ACCEPT OK",
+	}, "\n")
+
+	stats, err := ta.AnalyzeNATFlowLogReader(strings.NewReader(data), map[string]bool{"eni-nat": true})
+	if err != nil {
+		t.Fatalf("AnalyzeNATFlowLogReader returned error: %v", err)
+	}
+	if stats.TotalRecords != 1 || stats.TotalBytes != 1000 {
+		t.Fatalf("expected only the NAT egress record, got records=%d bytes=%d", stats.TotalRecords, stats.TotalBytes)
+	}
+
+	stats, err = ta.AnalyzeNATFlowLogReader(strings.NewReader(data), nil)
+	if err != nil {
+		t.Fatalf("AnalyzeNATFlowLogReader returned error: %v", err)
+	}
+	if stats.TotalBytes != 5000 {
+		t.Fatalf("without NAT interfaces expected public destinations only (5000 bytes), got %d", stats.TotalBytes)
+	}
+}
+
+func TestParseFlowLogHeaderPrefersPacketAddresses(t *testing.T) {
+	layout, err := ParseFlowLogHeader("interface-id srcaddr dstaddr pkt-srcaddr pkt-dstaddr srcport dstport protocol packets bytes start end action log-status")
+	if err != nil {
+		t.Fatalf("ParseFlowLogHeader returned error: %v", err)
+	}
+	rec, ok := layout.Parse("eni-1 10.0.0.9 10.0.0.10 10.0.1.5 52.216.0.1 443 51000 6 10 2048 1 2 ACCEPT OK")
+	if !ok {
+		t.Fatal("expected record to parse")
+	}
+	if rec.SrcAddr != "10.0.1.5" || rec.DstAddr != "52.216.0.1" || rec.Bytes != 2048 {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+
+	if _, err := ParseFlowLogHeader("version account-id interface-id"); err == nil {
+		t.Fatal("expected error for header without dstaddr/bytes/action")
+	}
+}
+
+func TestGroupByMonth(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	stats := func(total, s3 int64) *TrafficStats {
+		return &TrafficStats{TotalBytes: total, TotalRecords: 1, Services: map[Service]ServiceStats{ServiceS3: {Bytes: s3}}}
+	}
+
+	months := GroupByMonth([]DailyTraffic{
+		{Day: day("2024-02-01"), Stats: stats(100, 10)},
+		{Day: day("2024-01-30"), Stats: stats(200, 50)},
+		{Day: day("2024-01-31"), Stats: stats(300, 0)},
+		{Day: day("2024-02-02"), Stats: &TrafficStats{}},
+	})
+
+	if len(months) != 2 {
+		t.Fatalf("expected 2 months, got %d", len(months))
+	}
+	if months[0].Month != "2024-01" || months[0].Days != 2 || months[0].Stats.TotalBytes != 500 || months[0].Stats.Bytes(ServiceS3) != 50 {
+		t.Fatalf("unexpected January: %+v %+v", months[0], months[0].Stats)
+	}
+	if months[1].Month != "2024-02" || months[1].Days != 1 || months[1].Stats.TotalBytes != 100 {
+		t.Fatalf("unexpected February: %+v %+v", months[1], months[1].Stats)
+	}
+}
@@ -0,0 +1,129 @@
+package analysis
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// benchRecords is the sample size the benchmarks analyze: a busy NAT
+// Gateway's hour, well past what Logs Insights returns per query.
+const benchRecords = 1 << 20
+
+// syntheticClassifier has about as many ranges as the published
+// ip-ranges.json: thousands of EC2 prefixes and hundreds of S3 and DynamoDB
+// ones, in random order.
+func syntheticClassifier() *TrafficClassifier {
+	rng := rand.New(rand.NewSource(1))
+	prefix := func() *net.IPNet {
+		ones := 12 + rng.Intn(13)
+		ip := net.IPv4(byte(3+rng.Intn(60)), byte(rng.Intn(256)), byte(rng.Intn(256)), 0)
+		return &net.IPNet{IP: ip.Mask(net.CIDRMask(ones, 32)).To4(), Mask: net.CIDRMask(ones, 32)}
+	}
+	tc := &TrafficClassifier{}
+	for i := 0; i < 400; i++ {
+		tc.s3Ranges = append(tc.s3Ranges, prefix())
+	}
+	for i := 0; i < 150; i++ {
+		tc.dynamoRanges = append(tc.dynamoRanges, prefix())
+		tc.dynamoRegions = append(tc.dynamoRegions, fmt.Sprintf("region-%d", i%20))
+	}
+	for i := 0; i < 4000; i++ {
+		tc.ecrRanges = append(tc.ecrRanges, prefix())
+	}
+	return tc
+}
+
+// syntheticAddrs are destinations spread over the ranges and outside them.
+func syntheticAddrs(n int) []string {
+	rng := rand.New(rand.NewSource(2))
+	addrs := make([]string, n)
+	for i := range addrs {
+		addrs[i] = net.IPv4(byte(1+rng.Intn(80)), byte(rng.Intn(256)), byte(rng.Intn(256)), byte(rng.Intn(256))).String()
+	}
+	return addrs
+}
+
+var (
+	benchLinesOnce sync.Once
+	benchLines     []string
+	benchResults   [][]types.ResultField
+)
+
+func benchData() ([]string, [][]types.ResultField) {
+	benchLinesOnce.Do(func() {
+		dsts := syntheticAddrs(4096)
+		benchLines = make([]string, benchRecords)
+		for i := range benchLines {
+			src := fmt.Sprintf("10.0.%d.%d", i/256%256, i%256)
+			benchLines[i] = fmt.Sprintf("eni-0a1b2c3d 10.0.0.5 %s %s %s 443 %d 6 10 %d 1700000000 1700000060 ACCEPT OK",
+				dsts[i%len(dsts)], src, dsts[i%len(dsts)], 32768+i%20000, 100+i%9000)
+		}
+		benchResults = make([][]types.ResultField, benchRecords)
+		for i := range benchResults {
+			benchResults[i] = []types.ResultField{
+				{Field: strPtr("resolved_dst"), Value: strPtr(dsts[i%len(dsts)])},
+				{Field: strPtr("service_port"), Value: strPtr("443")},
+				{Field: strPtr("protocol"), Value: strPtr("6")},
+				{Field: strPtr("total_bytes"), Value: strPtr(strconv.Itoa(100 + i%9000))},
+				{Field: strPtr("flow_count"), Value: strPtr("1")},
+			}
+		}
+	})
+	return benchLines, benchResults
+}
+
+func BenchmarkClassifyIP(b *testing.B) {
+	tc := syntheticClassifier()
+	addrs := syntheticAddrs(4096)
+	tc.ClassifyIPMatch(addrs[0])
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tc.ClassifyIPMatch(addrs[i%len(addrs)])
+	}
+}
+
+func BenchmarkAnalyzeFlowLogs(b *testing.B) {
+	lines, _ := benchData()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ta := &TrafficAnalyzer{classifier: syntheticClassifier()}
+		if _, err := ta.AnalyzeFlowLogs(lines); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(lines)), "ns/record")
+}
+
+func BenchmarkAnalyzeAggregatedResults(b *testing.B) {
+	_, results := benchData()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ta := &TrafficAnalyzer{classifier: syntheticClassifier()}
+		if _, err := ta.AnalyzeAggregatedResults(results); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(results)), "ns/record")
+}
+
+// TestClassifyIPBudget holds classification to no allocations per address:
+// it runs once or more per record, over samples of millions of records.
+func TestClassifyIPBudget(t *testing.T) {
+	tc := syntheticClassifier()
+	addrs := syntheticAddrs(64)
+	i := 0
+	allocs := testing.AllocsPerRun(1000, func() {
+		tc.ClassifyIPMatch(addrs[i%len(addrs)])
+		tc.DynamoDBRegion(addrs[i%len(addrs)])
+		i++
+	})
+	if allocs != 0 {
+		t.Fatalf("classification allocates %.1f times per address, want 0", allocs)
+	}
+}
@@ -0,0 +1,51 @@
+package analysis
+
+// ChargeComponent is one charge on the NAT path of the traffic a gateway
+// endpoint could carry, and whether the endpoint removes it.
+type ChargeComponent struct {
+	Service    string  `json:"service"`
+	Component  string  `json:"component"`
+	GB         float64 `json:"gb"`
+	PricePerGB float64 `json:"price_per_gb"`
+	// Removed is set when the endpoint removes the charge; only removed
+	// charges count toward MonthlySavings.
+	Removed        bool    `json:"removed"`
+	MonthlySavings float64 `json:"monthly_savings"`
+	Note           string  `json:"note"`
+}
+
+// ChargeComponents itemizes the charges of the projected S3 and DynamoDB
+// traffic and which of them a gateway endpoint removes. Only NAT data
+// processing is removed: same-region transfer to S3 and DynamoDB is free
+// on either path, and cross-region traffic stays on the NAT path, so the
+// savings never count data transfer.
+func (c *CostEstimate) ChargeComponents() []ChargeComponent {
+	var out []ChargeComponent
+	for _, svc := range []struct {
+		name                 string
+		gb, crossGB, savings float64
+	}{
+		{"S3", c.S3DataGB, c.CrossRegionS3GB, c.S3SavingsMonthly},
+		{"DynamoDB", c.DynamoDataGB, c.CrossRegionDynamoGB, c.DynamoSavingsMonthly},
+	} {
+		if local := svc.gb - svc.crossGB; local > 0 {
+			out = append(out,
+				ChargeComponent{
+					Service: svc.name, Component: "NAT data processing", GB: local, PricePerGB: c.NATGatewayPricePerGB,
+					Removed: true, MonthlySavings: svc.savings,
+					Note: "charged per GB on the NAT path; gateway endpoints have no data charge",
+				},
+				ChargeComponent{
+					Service: svc.name, Component: "Same-region data transfer", GB: local,
+					Note: "free with or without the endpoint; not counted",
+				})
+		}
+		if svc.crossGB > 0 {
+			out = append(out, ChargeComponent{
+				Service: svc.name, Component: "Cross-region traffic", GB: svc.crossGB, PricePerGB: c.NATGatewayPricePerGB,
+				Note: "a gateway endpoint only reaches its own region; NAT processing and inter-region transfer stay",
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+package analysis
+
+import "testing"
+
+func TestChargeComponentsNetOutCrossRegionS3(t *testing.T) {
+	const gb = 1 << 30
+	stats := &TrafficStats{TotalBytes: 10 * gb}
+	stats.AddService(ServiceS3, 10*gb, 1)
+	stats.addS3Region("us-east-1", 8*gb)
+	stats.addS3Region("eu-west-1", 2*gb)
+
+	// A 43,200-minute sample is already a month.
+	c := CalculateCosts("us-east-1", stats, 43200)
+	assertApprox(t, c.CrossRegionS3GB, 2, 0.0001, "cross-region S3 GB")
+	assertApprox(t, c.S3SavingsMonthly, 8*0.045, 0.0001, "S3 savings")
+	assertApprox(t, c.TotalSavingsMonthly, 8*0.045, 0.0001, "total savings")
+
+	got := c.ChargeComponents()
+	if len(got) != 3 {
+		t.Fatalf("got %d components, want 3: %+v", len(got), got)
+	}
+	var removed float64
+	for _, cc := range got {
+		if cc.Removed {
+			removed += cc.MonthlySavings
+		} else if cc.MonthlySavings != 0 {
+			t.Errorf("%s %s is kept but saves $%.2f", cc.Service, cc.Component, cc.MonthlySavings)
+		}
+	}
+	assertApprox(t, removed, c.TotalSavingsMonthly, 0.0001, "removed charges")
+	if got[1].Component != "Same-region data transfer" || got[1].PricePerGB != 0 || got[1].GB != 8 {
+		t.Errorf("unexpected same-region transfer: %+v", got[1])
+	}
+	if got[2].Component != "Cross-region traffic" || got[2].GB != 2 {
+		t.Errorf("unexpected cross-region traffic: %+v", got[2])
+	}
+}
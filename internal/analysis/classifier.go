@@ -1,19 +1,27 @@
 package analysis
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/netip"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 type IPRanges struct {
-	Prefixes []IPPrefix `json:"prefixes"`
+	SyncToken  string     `json:"syncToken"`
+	CreateDate string     `json:"createDate"`
+	Prefixes   []IPPrefix `json:"prefixes"`
 }
 
 type IPPrefix struct {
@@ -23,9 +31,21 @@ type IPPrefix struct {
 }
 
 type TrafficClassifier struct {
-	s3Ranges     []*net.IPNet
+	s3Ranges []*net.IPNet
+	// s3Regions[i] is the region of s3Ranges[i]
+	s3Regions    []string
 	dynamoRanges []*net.IPNet
-	ecrRanges    []*net.IPNet
+	// dynamoRegions[i] is the region of dynamoRanges[i]
+	dynamoRegions []string
+	ecrRanges     []*net.IPNet
+	// registries maps resolved public registry addresses to the registry name
+	registries map[string]string
+	// services maps resolved regional AWS API addresses to the endpoint name
+	services map[string]string
+
+	// The ranges are indexed in one trie on first lookup.
+	indexOnce sync.Once
+	trie      *rangeTrie
 }
 
 const (
@@ -33,8 +53,48 @@ const (
 	cacheTTL      = 24 * time.Hour
 	cacheFileName = "aws-ip-ranges.json"
 	cacheTimeFile = "aws-ip-ranges.timestamp"
+	cacheSumFile  = "aws-ip-ranges.sha256"
+)
+
+// pinnedIPRanges is the snapshot loaded with UseIPRangesFile, when set,
+// and pinnedIPRangesPath the file it came from.
+var (
+	pinnedIPRanges     []byte
+	pinnedIPRangesPath string
 )
 
+// UseIPRangesFile pins classification to an ip-ranges.json snapshot instead
+// of the cached or downloaded one, so analyses are reproducible across runs.
+func UseIPRangesFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read IP ranges file: %w", err)
+	}
+	if _, err := parseIPRanges(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	pinnedIPRanges, pinnedIPRangesPath = data, path
+	return nil
+}
+
+// parseIPRanges decodes ip-ranges.json, rejecting documents that parse but
+// are not a ranges snapshot (an error page, a truncated download).
+func parseIPRanges(data []byte) (*IPRanges, error) {
+	var ranges IPRanges
+	if err := json.Unmarshal(data, &ranges); err != nil {
+		return nil, fmt.Errorf("failed to parse IP ranges: %w", err)
+	}
+	if ranges.SyncToken == "" || len(ranges.Prefixes) == 0 {
+		return nil, errors.New("not an AWS ip-ranges.json snapshot (no syncToken or prefixes)")
+	}
+	return &ranges, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 func getCacheDir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -62,14 +122,40 @@ func isCacheValid(cacheDir string) bool {
 	return time.Since(cacheTime) < cacheTTL
 }
 
+// loadFromCache reads the cached ranges, checking them against the checksum
+// saved with them and that they still parse.
 func loadFromCache(cacheDir string) ([]byte, error) {
 	cachePath := filepath.Join(cacheDir, cacheFileName)
-	return os.ReadFile(cachePath)
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := os.ReadFile(filepath.Join(cacheDir, cacheSumFile))
+	if err != nil {
+		return nil, fmt.Errorf("cached IP ranges have no checksum: %w", err)
+	}
+	if strings.TrimSpace(string(sum)) != checksum(data) {
+		return nil, fmt.Errorf("cached IP ranges %s are corrupt: checksum mismatch", cachePath)
+	}
+	if _, err := parseIPRanges(data); err != nil {
+		return nil, fmt.Errorf("cached IP ranges %s are corrupt: %w", cachePath, err)
+	}
+	return data, nil
 }
 
+// saveToCache writes the ranges through a temporary file, so an interrupted
+// write never leaves a truncated cache behind, then their checksum and the
+// time they were fetched.
 func saveToCache(cacheDir string, data []byte) error {
 	cachePath := filepath.Join(cacheDir, cacheFileName)
-	if err := os.WriteFile(cachePath, data, 0644); err != nil {
+	tmp := cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, cachePath); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, cacheSumFile), []byte(checksum(data)+"\n"), 0644); err != nil {
 		return err
 	}
 
@@ -78,41 +164,78 @@ func saveToCache(cacheDir string, data []byte) error {
 	return os.WriteFile(timestampPath, timestamp, 0644)
 }
 
-func fetchIPRanges() ([]byte, error) {
+// fetchIPRanges returns the pinned snapshot, the cached ranges while they
+// are fresh and intact, or a new download, and where they came from. A
+// corrupt cache is re-fetched; when the download fails, an intact stale
+// cache is used instead.
+func fetchIPRanges() (data []byte, source string, err error) {
+	if pinnedIPRanges != nil {
+		return pinnedIPRanges, "pinned snapshot " + pinnedIPRangesPath, nil
+	}
 	cacheDir, err := getCacheDir()
-	if err == nil && isCacheValid(cacheDir) {
+	if err != nil {
+		cacheDir = ""
+	}
+	if cacheDir != "" && isCacheValid(cacheDir) {
 		if data, err := loadFromCache(cacheDir); err == nil {
-			return data, nil
+			return data, "cached copy of " + ipRangesURL, nil
+		}
+	}
+
+	data, err = downloadIPRanges()
+	if err != nil {
+		if cacheDir != "" {
+			if stale, cacheErr := loadFromCache(cacheDir); cacheErr == nil {
+				return stale, "stale cached copy of " + ipRangesURL + " (download failed)", nil
+			}
 		}
+		return nil, "", err
 	}
 
+	if cacheDir != "" {
+		_ = saveToCache(cacheDir, data)
+	}
+
+	return data, ipRangesURL, nil
+}
+
+// PrimeIPRanges loads the IP ranges classification will use, validating the
+// cache and re-fetching it when corrupt, so a scan fails before it creates
+// anything rather than when it classifies.
+func PrimeIPRanges() error {
+	_, _, err := fetchIPRanges()
+	return err
+}
+
+func downloadIPRanges() ([]byte, error) {
 	resp, err := http.Get(ipRangesURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch AWS IP ranges: %w", err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch AWS IP ranges: %s", resp.Status)
+	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read IP ranges: %w", err)
 	}
-
-	if cacheDir != "" {
-		_ = saveToCache(cacheDir, data)
+	if _, err := parseIPRanges(data); err != nil {
+		return nil, err
 	}
-
 	return data, nil
 }
 
 func NewTrafficClassifier() (*TrafficClassifier, error) {
-	body, err := fetchIPRanges()
+	body, _, err := fetchIPRanges()
 	if err != nil {
 		return nil, err
 	}
 
-	var ranges IPRanges
-	if err := json.Unmarshal(body, &ranges); err != nil {
-		return nil, fmt.Errorf("failed to parse IP ranges: %w", err)
+	ranges, err := parseIPRanges(body)
+	if err != nil {
+		return nil, err
 	}
 
 	tc := &TrafficClassifier{}
@@ -125,51 +248,112 @@ func NewTrafficClassifier() (*TrafficClassifier, error) {
 		switch prefix.Service {
 		case "S3":
 			tc.s3Ranges = append(tc.s3Ranges, ipNet)
+			tc.s3Regions = append(tc.s3Regions, prefix.Region)
 		case "DYNAMODB":
 			tc.dynamoRanges = append(tc.dynamoRanges, ipNet)
+			tc.dynamoRegions = append(tc.dynamoRegions, prefix.Region)
 		case "EC2":
 			// ECR uses EC2 service IPs
 			tc.ecrRanges = append(tc.ecrRanges, ipNet)
 		}
 	}
 
+	tc.registries = resolvedRegistryIPs()
 	return tc, nil
 }
 
+// MatchKind says how specifically an IP was attributed to a service.
+type MatchKind int
+
+const (
+	MatchNone  MatchKind = iota // in no tracked AWS range, or not an IP
+	MatchBroad                  // only in the broad EC2 range (reported as ECR)
+	MatchExact                  // in a service-specific range (S3, DynamoDB)
+)
+
 func (tc *TrafficClassifier) ClassifyIP(ip string) string {
-	parsedIP := net.ParseIP(ip)
-	if parsedIP == nil {
-		return "unknown"
+	service, _ := tc.ClassifyIPMatch(ip)
+	return service
+}
+
+// ClassifyIPMatch classifies ip and reports how specific the matching range was.
+func (tc *TrafficClassifier) ClassifyIPMatch(ip string) (string, MatchKind) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "unknown", MatchNone
+	}
+	m := tc.index().lookup(addr)
+	switch {
+	case m.has(rangeS3):
+		return "s3", MatchExact
+	case m.has(rangeDynamoDB):
+		return "dynamodb", MatchExact
+	case m.has(rangeEC2):
+		return "ecr", MatchBroad
 	}
+	return "other", MatchNone
+}
 
-	for _, ipNet := range tc.s3Ranges {
-		if ipNet.Contains(parsedIP) {
-			return "s3"
-		}
+// S3Region returns the region of the S3 range containing ip, or "" when ip
+// is not an S3 address or its region is unknown.
+func (tc *TrafficClassifier) S3Region(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	if i := tc.index().lookup(addr).s3; i >= 0 && i < len(tc.s3Regions) {
+		return tc.s3Regions[i]
 	}
+	return ""
+}
 
-	for _, ipNet := range tc.dynamoRanges {
-		if ipNet.Contains(parsedIP) {
-			return "dynamodb"
-		}
+// DynamoDBRegion returns the region of the DynamoDB range containing ip, or ""
+// when ip is not a DynamoDB address or its region is unknown.
+func (tc *TrafficClassifier) DynamoDBRegion(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
 	}
+	if i := tc.index().lookup(addr).dynamo; i >= 0 && i < len(tc.dynamoRegions) {
+		return tc.dynamoRegions[i]
+	}
+	return ""
+}
 
-	for _, ipNet := range tc.ecrRanges {
-		if ipNet.Contains(parsedIP) {
-			return "ecr"
+// index returns the trie of every service's ranges, building it first.
+func (tc *TrafficClassifier) index() *rangeTrie {
+	tc.indexOnce.Do(func() {
+		tc.trie = &rangeTrie{}
+		for service, ranges := range map[uint8][]*net.IPNet{rangeS3: tc.s3Ranges, rangeDynamoDB: tc.dynamoRanges, rangeEC2: tc.ecrRanges} {
+			for i, r := range ranges {
+				tc.trie.insert(r, service, i)
+			}
 		}
-	}
+	})
+	return tc.trie
+}
+
+// PublicRegistry returns the public container registry ip belongs to, or "".
+func (tc *TrafficClassifier) PublicRegistry(ip string) string {
+	return tc.registries[ip]
+}
 
-	return "other"
+// RegionalService returns the AWS API endpoint name (ecr.api, sts, ...) ip
+// was resolved from, or "".
+func (tc *TrafficClassifier) RegionalService(ip string) string {
+	return tc.services[ip]
 }
 
 type FlowLogRecord struct {
-	SrcAddr  string
-	DstAddr  string
-	SrcPort  string
-	DstPort  string
-	Protocol string
-	Bytes    int64
+	// InterfaceID and Start are only set when the layout has those columns.
+	InterfaceID string
+	Start       int64
+	SrcAddr     string
+	DstAddr     string
+	SrcPort     string
+	DstPort     string
+	Protocol    string
+	Bytes       int64
 }
 
 func ParseFlowLogLine(line string) (*FlowLogRecord, error) {
@@ -178,10 +362,9 @@ func ParseFlowLogLine(line string) (*FlowLogRecord, error) {
 		return nil, fmt.Errorf("invalid flow log format")
 	}
 
-	// Custom format: interface-id srcaddr dstaddr pkt-srcaddr pkt-dstaddr srcport dstport protocol packets bytes start end action log-status
-	// Indices:       0            1       2       3           4           5       6       7        8       9     10    11  12     13
-	var bytes int64
-	fmt.Sscanf(fields[9], "%d", &bytes)
+	// Custom format: interface-id srcaddr dstaddr pkt-srcaddr pkt-dstaddr srcport dstport protocol packets bytes start end action log-status [pkt-dst-aws-service]
+	// Indices:       0            1       2       3           4           5       6       7        8       9     10    11  12     13          14
+	bytes, _ := strconv.ParseInt(fields[9], 10, 64)
 
 	return &FlowLogRecord{
 		SrcAddr:  fields[3], // pkt-srcaddr
@@ -192,3 +375,78 @@ func ParseFlowLogLine(line string) (*FlowLogRecord, error) {
 		Bytes:    bytes,
 	}, nil
 }
+
+// FlowLogLayout maps the columns of a Flow Logs header line, as written at the
+// top of every Flow Logs object delivered to S3.
+type FlowLogLayout struct {
+	src, pktSrc, dst, pktDst, bytes, action int
+	iface, start                            int
+	fields                                  int
+}
+
+// ParseFlowLogHeader reads a header such as
+// "version account-id interface-id srcaddr dstaddr ... bytes start end action log-status".
+func ParseFlowLogHeader(header string) (*FlowLogLayout, error) {
+	l := &FlowLogLayout{src: -1, pktSrc: -1, dst: -1, pktDst: -1, bytes: -1, action: -1, iface: -1, start: -1}
+	names := strings.Fields(header)
+	for i, name := range names {
+		switch strings.ReplaceAll(name, "_", "-") {
+		case "srcaddr":
+			l.src = i
+		case "pkt-srcaddr":
+			l.pktSrc = i
+		case "dstaddr":
+			l.dst = i
+		case "pkt-dstaddr":
+			l.pktDst = i
+		case "bytes":
+			l.bytes = i
+		case "action":
+			l.action = i
+		case "interface-id":
+			l.iface = i
+		case "start":
+			l.start = i
+		}
+	}
+	if l.dst < 0 || l.bytes < 0 || l.action < 0 {
+		return nil, fmt.Errorf("flow log header %q lacks dstaddr, bytes, or action", header)
+	}
+	l.fields = len(names)
+	return l, nil
+}
+
+// Parse returns the record on line if it is a well-formed ACCEPT record.
+// pkt-srcaddr/pkt-dstaddr are preferred over srcaddr/dstaddr when present.
+func (l *FlowLogLayout) Parse(line string) (*FlowLogRecord, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != l.fields || fields[l.action] != "ACCEPT" {
+		return nil, false
+	}
+	bytes, err := strconv.ParseInt(fields[l.bytes], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	pick := func(preferred, fallback int) string {
+		if preferred >= 0 && fields[preferred] != "-" {
+			return fields[preferred]
+		}
+		if fallback >= 0 {
+			return fields[fallback]
+		}
+		return "-"
+	}
+	record := &FlowLogRecord{
+		SrcAddr: pick(l.pktSrc, l.src),
+		DstAddr: pick(l.pktDst, l.dst),
+		Bytes:   bytes,
+	}
+	if l.iface >= 0 {
+		record.InterfaceID = fields[l.iface]
+	}
+	if l.start >= 0 {
+		record.Start, _ = strconv.ParseInt(fields[l.start], 10, 64)
+	}
+	return record, true
+}
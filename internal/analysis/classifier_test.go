@@ -0,0 +1,167 @@
+package analysis
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testIPRanges = `{"syncToken":"1700000000","createDate":"2024-01-01-00-00-00","prefixes":[{"ip_prefix":"52.216.0.0/15","region":"us-east-1","service":"S3"}]}`
+
+func TestIPRangesCacheIntegrity(t *testing.T) {
+	dir := t.TempDir()
+	if err := saveToCache(dir, []byte(testIPRanges)); err != nil {
+		t.Fatal(err)
+	}
+	if !isCacheValid(dir) {
+		t.Error("a freshly saved cache should be valid")
+	}
+	data, err := loadFromCache(dir)
+	if err != nil || string(data) != testIPRanges {
+		t.Fatalf("loadFromCache = %q, %v", data, err)
+	}
+
+	// A cache changed after it was saved no longer matches its checksum
+	path := filepath.Join(dir, cacheFileName)
+	if err := os.WriteFile(path, []byte(testIPRanges[:40]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFromCache(dir); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("truncated cache: err = %v", err)
+	}
+
+	// Intact but not a ranges snapshot, e.g. a cached error page
+	if err := saveToCache(dir, []byte(`{"message":"Access Denied"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFromCache(dir); err == nil || !strings.Contains(err.Error(), "not an AWS ip-ranges.json snapshot") {
+		t.Errorf("error page cache: err = %v", err)
+	}
+}
+
+func TestUseIPRangesFile(t *testing.T) {
+	defer func() { pinnedIPRanges = nil }()
+	dir := t.TempDir()
+	good := filepath.Join(dir, "ip-ranges.json")
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(good, []byte(testIPRanges), 0644)
+	os.WriteFile(bad, []byte("<html>"), 0644)
+
+	if err := UseIPRangesFile(bad); err == nil {
+		t.Error("expected an unparseable snapshot to be rejected")
+	}
+	if err := UseIPRangesFile(good); err != nil {
+		t.Fatal(err)
+	}
+	data, _, err := fetchIPRanges()
+	if err != nil || string(data) != testIPRanges {
+		t.Errorf("fetchIPRanges = %q, %v; want the pinned snapshot", data, err)
+	}
+}
+
+// classification is everything the classifier reports about an address.
+type classification struct {
+	service      string
+	kind         MatchKind
+	s3Region     string
+	dynamoRegion string
+}
+
+func classify(tc *TrafficClassifier, ip string) classification {
+	service, kind := tc.ClassifyIPMatch(ip)
+	return classification{service, kind, tc.S3Region(ip), tc.DynamoDBRegion(ip)}
+}
+
+// linearClassify is the reference classification: a Contains per range,
+// S3 before DynamoDB before EC2, each in slice order.
+func linearClassify(tc *TrafficClassifier, ip string) classification {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return classification{service: "unknown"}
+	}
+	first := func(ranges []*net.IPNet) int {
+		for i, n := range ranges {
+			if n.Contains(parsed) {
+				return i
+			}
+		}
+		return -1
+	}
+	region := func(regions []string, i int) string {
+		if i < 0 || i >= len(regions) {
+			return ""
+		}
+		return regions[i]
+	}
+	s3, dynamo := first(tc.s3Ranges), first(tc.dynamoRanges)
+	c := classification{service: "other", s3Region: region(tc.s3Regions, s3), dynamoRegion: region(tc.dynamoRegions, dynamo)}
+	switch {
+	case s3 >= 0:
+		c.service, c.kind = "s3", MatchExact
+	case dynamo >= 0:
+		c.service, c.kind = "dynamodb", MatchExact
+	case first(tc.ecrRanges) >= 0:
+		c.service, c.kind = "ecr", MatchBroad
+	}
+	return c
+}
+
+// classifierAddrs are the edge addresses of tc's ranges.
+func classifierAddrs(tc *TrafficClassifier) []string {
+	var addrs []string
+	for _, addr := range edgeAddrs(trieRanges{rangeS3: tc.s3Ranges, rangeDynamoDB: tc.dynamoRanges, rangeEC2: tc.ecrRanges}) {
+		addrs = append(addrs, addr.String())
+	}
+	return addrs
+}
+
+func TestClassifierMatchesLinearScan(t *testing.T) {
+	tc := syntheticClassifier()
+	seen := map[string]int{}
+	for _, ip := range append(syntheticAddrs(5000), classifierAddrs(tc)...) {
+		want := linearClassify(tc, ip)
+		if got := classify(tc, ip); got != want {
+			t.Fatalf("%s: got %+v, want %+v", ip, got, want)
+		}
+		seen[want.service]++
+	}
+	for _, service := range []string{"s3", "dynamodb", "ecr", "other"} {
+		if seen[service] == 0 {
+			t.Errorf("no synthetic address classified as %s: %v", service, seen)
+		}
+	}
+}
+
+func TestClassifierMatchesLinearScanEdges(t *testing.T) {
+	tc := &TrafficClassifier{
+		// Nested S3 ranges of different regions: the first listed wins
+		s3Ranges:  cidrs(t, "52.216.0.0/15", "52.216.8.0/24", "52.92.0.0/17", "2600:1fa0::/28", "2600:1fa0:4000::/40"),
+		s3Regions: []string{"us-east-1", "us-east-2", "eu-west-1", "us-west-2", "eu-central-1"},
+		// DynamoDB overlapping S3, and an IPv6 range inside EC2's
+		dynamoRanges:  cidrs(t, "52.216.8.128/25", "3.218.180.0/22", "3.218.180.0/24", "2600:1f18::/33"),
+		dynamoRegions: []string{"us-east-2", "us-east-1", "ap-south-1", "us-east-1"},
+		ecrRanges:     cidrs(t, "52.0.0.0/8", "3.208.0.0/12", "3.218.183.255/32", "2600:1f00::/24", "2a05:d000::/25"),
+	}
+	addrs := append(classifierAddrs(tc), "not-an-ip", "", "52.216.8.200", "::ffff:52.216.8.200", "2600:1f18:0:1::1")
+	for _, ip := range addrs {
+		if got, want := classify(tc, ip), linearClassify(tc, ip); got != want {
+			t.Errorf("%s: got %+v, want %+v", ip, got, want)
+		}
+	}
+
+	// Spot checks that the reference itself reads the ranges as intended
+	for ip, want := range map[string]classification{
+		"52.216.8.200":     {"s3", MatchExact, "us-east-1", "us-east-2"},
+		"3.218.183.255":    {"dynamodb", MatchExact, "", "us-east-1"},
+		"3.218.184.0":      {"ecr", MatchBroad, "", ""},
+		"2600:1f18::1":     {"dynamodb", MatchExact, "", "us-east-1"},
+		"2600:1fa0:4000::": {"s3", MatchExact, "us-west-2", ""},
+		"2a05:d080::":      {"other", MatchNone, "", ""},
+	} {
+		if got := classify(tc, ip); got != want {
+			t.Errorf("%s: got %+v, want %+v", ip, got, want)
+		}
+	}
+}
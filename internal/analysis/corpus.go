@@ -0,0 +1,132 @@
+package analysis
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CorpusEntry is one labeled line of a classifier corpus:
+//
+//	{"ip": "52.216.8.1", "expected": "s3", "note": "us-east-1 bucket"}
+//
+// expected is one of the classifier's services: s3, dynamodb, ecr or other.
+type CorpusEntry struct {
+	IP       string `json:"ip"`
+	Expected string `json:"expected"`
+	Note     string `json:"note,omitempty"`
+}
+
+var corpusServices = map[string]bool{"s3": true, "dynamodb": true, "ecr": true, "other": true}
+
+// ReadCorpus reads a JSON Lines corpus, skipping blank lines and lines
+// starting with #. An entry without an IP or with an unknown service is an
+// error, so a typo cannot pass as a misclassification.
+func ReadCorpus(r io.Reader) ([]CorpusEntry, error) {
+	var entries []CorpusEntry
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var e CorpusEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		e.Expected = strings.ToLower(e.Expected)
+		if e.IP == "" {
+			return nil, fmt.Errorf("line %d: no ip", n)
+		}
+		if !corpusServices[e.Expected] {
+			return nil, fmt.Errorf("line %d: expected %q is not s3, dynamodb, ecr or other", n, e.Expected)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CorpusResult is how a classifier fared on a corpus.
+type CorpusResult struct {
+	Total   int `json:"total"`
+	Correct int `json:"correct"`
+	// Services holds the counts per service, expected or classified.
+	Services []CorpusServiceResult `json:"services"`
+	// Misses are the entries classified as another service, in corpus order.
+	Misses []CorpusMiss `json:"misses,omitempty"`
+}
+
+// CorpusServiceResult counts one service's entries.
+type CorpusServiceResult struct {
+	Service string `json:"service"`
+	// Expected entries are labeled with the service, Classified ones were
+	// classified as it, and Correct ones both.
+	Expected   int `json:"expected"`
+	Classified int `json:"classified"`
+	Correct    int `json:"correct"`
+}
+
+// Precision is the share of addresses classified as the service that are
+// labeled with it.
+func (s CorpusServiceResult) Precision() float64 {
+	return pct(s.Correct, s.Classified)
+}
+
+// Recall is the share of addresses labeled with the service the classifier
+// found.
+func (s CorpusServiceResult) Recall() float64 {
+	return pct(s.Correct, s.Expected)
+}
+
+// CorpusMiss is an entry the classifier got wrong.
+type CorpusMiss struct {
+	CorpusEntry
+	Got string `json:"got"`
+}
+
+// Accuracy is the share of entries classified as labeled.
+func (r CorpusResult) Accuracy() float64 {
+	return pct(r.Correct, r.Total)
+}
+
+func pct(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of) * 100
+}
+
+// TestCorpus classifies every entry's address and compares it with its
+// label.
+func (tc *TrafficClassifier) TestCorpus(entries []CorpusEntry) CorpusResult {
+	result := CorpusResult{Total: len(entries)}
+	services := map[string]*CorpusServiceResult{}
+	service := func(name string) *CorpusServiceResult {
+		if services[name] == nil {
+			services[name] = &CorpusServiceResult{Service: name}
+		}
+		return services[name]
+	}
+	for _, e := range entries {
+		got := tc.ClassifyIP(e.IP)
+		service(e.Expected).Expected++
+		service(got).Classified++
+		if got == e.Expected {
+			result.Correct++
+			service(got).Correct++
+			continue
+		}
+		result.Misses = append(result.Misses, CorpusMiss{CorpusEntry: e, Got: got})
+	}
+	for _, s := range services {
+		result.Services = append(result.Services, *s)
+	}
+	sort.Slice(result.Services, func(i, j int) bool { return result.Services[i].Service < result.Services[j].Service })
+	return result
+}
@@ -0,0 +1,59 @@
+package analysis
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestTestCorpus(t *testing.T) {
+	_, s3Net, _ := net.ParseCIDR("52.216.0.0/15")
+	_, ec2Net, _ := net.ParseCIDR("3.80.0.0/12")
+	tc := &TrafficClassifier{s3Ranges: []*net.IPNet{s3Net}, ecrRanges: []*net.IPNet{ec2Net}}
+
+	entries, err := ReadCorpus(strings.NewReader(`# regression corpus
+{"ip": "52.216.0.1", "expected": "S3"}
+{"ip": "3.80.0.1", "expected": "ecr"}
+
+{"ip": "3.80.0.2", "expected": "dynamodb", "note": "DynamoDB range missing"}
+{"ip": "8.8.8.8", "expected": "other"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := tc.TestCorpus(entries)
+	if r.Total != 4 || r.Correct != 3 || r.Accuracy() != 75 {
+		t.Errorf("total=%d correct=%d accuracy=%.1f", r.Total, r.Correct, r.Accuracy())
+	}
+	if len(r.Misses) != 1 || r.Misses[0].IP != "3.80.0.2" || r.Misses[0].Got != "ecr" {
+		t.Fatalf("misses = %+v", r.Misses)
+	}
+
+	var ecr, dynamo CorpusServiceResult
+	for _, s := range r.Services {
+		switch s.Service {
+		case "ecr":
+			ecr = s
+		case "dynamodb":
+			dynamo = s
+		}
+	}
+	if ecr.Precision() != 50 || ecr.Recall() != 100 {
+		t.Errorf("ecr = %+v", ecr)
+	}
+	if dynamo.Expected != 1 || dynamo.Recall() != 0 {
+		t.Errorf("dynamodb = %+v", dynamo)
+	}
+}
+
+func TestReadCorpusRejectsBadEntries(t *testing.T) {
+	for _, line := range []string{
+		`{"ip": "52.216.0.1", "expected": "s4"}`,
+		`{"expected": "s3"}`,
+		`{"ip": `,
+	} {
+		if _, err := ReadCorpus(strings.NewReader("\n" + line)); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("ReadCorpus(%s) err = %v", line, err)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package analysis
 
 import (
 	"fmt"
+
+	"github.com/doitintl/terminator/internal/units"
 )
 
 // NAT Gateway data processing costs per GB by region (as of 2024)
@@ -39,27 +41,100 @@ type CostEstimate struct {
 	DynamoSavingsMonthly float64
 	TotalSavingsMonthly  float64
 	NATGatewayPricePerGB float64
+	ProjectionMethod     string // how the sample was scaled to a month
+	// CrossRegionS3GB and CrossRegionDynamoGB are projected S3 and DynamoDB
+	// traffic to other regions, excluded from S3SavingsMonthly and
+	// DynamoSavingsMonthly since a gateway endpoint won't carry it.
+	CrossRegionS3GB     float64
+	CrossRegionDynamoGB float64
 }
 
-func CalculateCosts(region string, stats *TrafficStats, collectionMinutes int) *CostEstimate {
-	// Get regional pricing
-	pricePerGB, ok := natGatewayPricing[region]
-	if !ok {
-		pricePerGB = natGatewayPricing["default"]
+// Prices of the CloudWatch Logs usage a deep scan itself incurs.
+const (
+	FlowLogsIngestionPricePerGB = 0.50  // vended logs delivered to CloudWatch Logs
+	LogsInsightsPricePerGB      = 0.005 // Logs Insights data scanned
+)
+
+// ScanCost is what running the analysis cost, separate from the NAT spend it measures.
+type ScanCost struct {
+	IngestedGB   float64 `json:"ingested_gb"`
+	IngestionUSD float64 `json:"ingestion_usd"`
+	ScannedGB    float64 `json:"query_scanned_gb"`
+	QueryUSD     float64 `json:"query_usd"`
+}
+
+// CalculateScanCost prices the Flow Logs data ingested and the bytes scanned by Logs Insights queries.
+func CalculateScanCost(ingestedGB, queryBytesScanned float64) *ScanCost {
+	scannedGB := units.BillingGB(queryBytesScanned)
+	return &ScanCost{
+		IngestedGB:   ingestedGB,
+		IngestionUSD: ingestedGB * FlowLogsIngestionPricePerGB,
+		ScannedGB:    scannedGB,
+		QueryUSD:     scannedGB * LogsInsightsPricePerGB,
+	}
+}
+
+// Total is the combined ingestion and query cost.
+func (c *ScanCost) Total() float64 {
+	return c.IngestionUSD + c.QueryUSD
+}
+
+// String is the one-line "cost of this scan" summary.
+func (c *ScanCost) String() string {
+	return fmt.Sprintf("~$%.2f (Flow Logs ingestion ~$%.2f for %.2f GB, Logs Insights ~$%.4f for %.2f GB scanned)",
+		c.Total(), c.IngestionUSD, c.IngestedGB, c.QueryUSD, c.ScannedGB)
+}
+
+// NATGatewayPricePerGB returns the NAT Gateway data processing price for region.
+func NATGatewayPricePerGB(region string) float64 {
+	if price, ok := natGatewayPricing[region]; ok {
+		return price
 	}
+	return natGatewayPricing["default"]
+}
 
+func CalculateCosts(region string, stats *TrafficStats, collectionMinutes int) *CostEstimate {
 	// Convert bytes to GB
-	totalGB := float64(stats.TotalBytes) / (1024 * 1024 * 1024)
-	s3GB := float64(stats.S3Bytes) / (1024 * 1024 * 1024)
-	dynamoGB := float64(stats.DynamoBytes) / (1024 * 1024 * 1024)
+	totalGB := units.BillingGB(stats.TotalBytes)
+	s3GB := units.BillingGB(stats.Bytes(ServiceS3))
+	dynamoGB := units.BillingGB(stats.Bytes(ServiceDynamoDB))
 
 	// Extrapolate to monthly costs (assuming collection period is representative)
 	// 1 month = ~43,200 minutes
 	monthlyMultiplier := 43200.0 / float64(collectionMinutes)
 
-	monthlyTotalGB := totalGB * monthlyMultiplier
-	monthlyS3GB := s3GB * monthlyMultiplier
-	monthlyDynamoGB := dynamoGB * monthlyMultiplier
+	estimate := monthlyCostEstimate(region, totalGB*monthlyMultiplier, s3GB*monthlyMultiplier, dynamoGB*monthlyMultiplier)
+	estimate.ProjectionMethod = fmt.Sprintf("linear extrapolation of a %d-minute sample", collectionMinutes)
+	estimate.excludeCrossRegionS3(units.BillingGB(stats.CrossRegionS3Bytes(region)) * monthlyMultiplier)
+	estimate.excludeCrossRegionDynamo(units.BillingGB(stats.CrossRegionDynamoBytes(region)) * monthlyMultiplier)
+	return estimate
+}
+
+// excludeCrossRegionS3 takes gb of projected S3 traffic to buckets in other
+// regions out of the gateway endpoint savings.
+func (e *CostEstimate) excludeCrossRegionS3(gb float64) {
+	if gb <= 0 {
+		return
+	}
+	e.CrossRegionS3GB = gb
+	e.S3SavingsMonthly -= gb * e.NATGatewayPricePerGB
+	e.TotalSavingsMonthly = e.S3SavingsMonthly + e.DynamoSavingsMonthly
+}
+
+// excludeCrossRegionDynamo takes gb of projected DynamoDB traffic to other
+// regions out of the gateway endpoint savings.
+func (e *CostEstimate) excludeCrossRegionDynamo(gb float64) {
+	if gb <= 0 {
+		return
+	}
+	e.CrossRegionDynamoGB = gb
+	e.DynamoSavingsMonthly -= gb * e.NATGatewayPricePerGB
+	e.TotalSavingsMonthly = e.S3SavingsMonthly + e.DynamoSavingsMonthly
+}
+
+// monthlyCostEstimate prices already-projected monthly traffic volumes.
+func monthlyCostEstimate(region string, monthlyTotalGB, monthlyS3GB, monthlyDynamoGB float64) *CostEstimate {
+	pricePerGB := NATGatewayPricePerGB(region)
 
 	// Calculate costs
 	currentMonthlyCost := monthlyTotalGB * pricePerGB
@@ -86,12 +161,13 @@ func (c *CostEstimate) String() string {
 		"COST ESTIMATE (based on collected traffic sample)\n"+
 			"━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"+
 			"Region: %s\n"+
-			"NAT Gateway Data Processing: $%.4f per GB\n\n"+
+			"NAT Gateway Data Processing: $%.4f per GB\n"+
+			"Projection: %s\n\n"+
 			"Projected Monthly Traffic:\n"+
-			"  Total:    %.2f GB\n"+
-			"  S3:       %.2f GB (%.1f%%)\n"+
-			"  DynamoDB: %.2f GB (%.1f%%)\n"+
-			"  Other:    %.2f GB (%.1f%%)\n\n"+
+			"  Total:    %s GB\n"+
+			"  S3:       %s GB (%.1f%%)\n"+
+			"  DynamoDB: %s GB (%.1f%%)\n"+
+			"  Other:    %s GB (%.1f%%)\n\n"+
 			"Current Monthly NAT Gateway Cost: $%.2f\n\n"+
 			"Potential Monthly Savings with VPC Endpoints:\n"+
 			"  S3 Gateway Endpoint:       $%.2f\n"+
@@ -103,10 +179,11 @@ func (c *CostEstimate) String() string {
 			"   Gateway VPC Endpoints for S3 and DynamoDB are FREE (no hourly or data charges).",
 		c.Region,
 		c.NATGatewayPricePerGB,
-		c.TotalDataGB,
-		c.S3DataGB, c.S3Percentage(),
-		c.DynamoDataGB, c.DynamoPercentage(),
-		c.OtherDataGB, c.OtherPercentage(),
+		c.ProjectionMethod,
+		units.Number(c.TotalDataGB),
+		units.Number(c.S3DataGB), c.S3Percentage(),
+		units.Number(c.DynamoDataGB), c.DynamoPercentage(),
+		units.Number(c.OtherDataGB), c.OtherPercentage(),
 		c.CurrentMonthlyCost,
 		c.S3SavingsMonthly,
 		c.DynamoSavingsMonthly,
@@ -0,0 +1,15 @@
+package analysis
+
+import "testing"
+
+func TestNATGatewayPricePerGBFallback(t *testing.T) {
+	assertApprox(t, NATGatewayPricePerGB("us-east-1"), 0.045, 0.0001, "us-east-1 price")
+	assertApprox(t, NATGatewayPricePerGB("unknown-region-1"), natGatewayPricing["default"], 0.0001, "fallback price")
+}
+
+func TestCalculateScanCost(t *testing.T) {
+	c := CalculateScanCost(2, 10*1024*1024*1024)
+	assertApprox(t, c.IngestionUSD, 1.0, 0.0001, "ingestion cost")
+	assertApprox(t, c.QueryUSD, 0.05, 0.0001, "query cost")
+	assertApprox(t, c.Total(), 1.05, 0.0001, "total scan cost")
+}
@@ -0,0 +1,163 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// insightsTimeLayout is how Logs Insights prints bin() timestamps.
+const insightsTimeLayout = "2006-01-02 15:04:05.000"
+
+// SampleCoverage is how complete a Flow Logs sample is: the aggregation
+// interval records were captured at, how many records AWS could not capture
+// (SKIPDATA) or had nothing to report (NODATA), and the minutes of the sample
+// window with no records at all. Projections scale whatever was captured, so
+// a sample with gaps or SKIPDATA records undercounts traffic.
+type SampleCoverage struct {
+	// AggregationIntervalSeconds is the Flow Logs' maximum aggregation
+	// interval; 0 when unknown (an existing log group).
+	AggregationIntervalSeconds int       `json:"aggregation_interval_seconds,omitempty"`
+	OKRecords                  int64     `json:"ok_records"`
+	NoDataRecords              int64     `json:"nodata_records"`
+	SkipDataRecords            int64     `json:"skipdata_records"`
+	WindowStart                time.Time `json:"window_start"`
+	WindowEnd                  time.Time `json:"window_end"`
+	// FirstRecord and LastRecord are the minutes of the first and last
+	// records; zero when there were none.
+	FirstRecord time.Time `json:"first_record,omitzero"`
+	LastRecord  time.Time `json:"last_record,omitzero"`
+	// Gaps are runs of minutes between the first and last record without
+	// any record, not even NODATA: Flow Logs delivered nothing for them.
+	Gaps []CoverageGap `json:"gaps,omitempty"`
+	// FailedNATGateways are the NAT Gateways whose traffic query failed;
+	// their traffic is missing from the totals.
+	FailedNATGateways []string `json:"failed_nat_gateways,omitempty"`
+}
+
+// CoverageGap is a run of minutes without records, End exclusive.
+type CoverageGap struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Minutes is the gap's length.
+func (g CoverageGap) Minutes() int {
+	return int(g.End.Sub(g.Start) / time.Minute)
+}
+
+// ParseSampleCoverage reads Logs Insights rows grouped by log_status and
+// bin(1m) with a records column, for the sample window from start to end.
+func ParseSampleCoverage(results [][]types.ResultField, start, end time.Time, intervalSeconds int) *SampleCoverage {
+	c := &SampleCoverage{AggregationIntervalSeconds: intervalSeconds, WindowStart: start.UTC(), WindowEnd: end.UTC()}
+	seen := make(map[time.Time]bool)
+	for _, row := range results {
+		var status string
+		var minute time.Time
+		var records int64
+		for _, field := range row {
+			if field.Field == nil || field.Value == nil {
+				continue
+			}
+			switch name := *field.Field; {
+			case name == "log_status":
+				// Records with fields after log-status (pkt-dst-aws-service)
+				// end up in the same column
+				if f := strings.Fields(*field.Value); len(f) > 0 {
+					status = strings.ToUpper(f[0])
+				}
+			case name == "records":
+				records, _ = strconv.ParseInt(*field.Value, 10, 64)
+			case strings.HasPrefix(name, "bin("):
+				if t, err := time.ParseInLocation(insightsTimeLayout, *field.Value, time.UTC); err == nil {
+					minute = t
+				}
+			}
+		}
+		switch status {
+		case "NODATA":
+			c.NoDataRecords += records
+		case "SKIPDATA":
+			c.SkipDataRecords += records
+		default:
+			c.OKRecords += records
+		}
+		if !minute.IsZero() && records > 0 {
+			seen[minute] = true
+		}
+	}
+
+	minutes := make([]time.Time, 0, len(seen))
+	for m := range seen {
+		minutes = append(minutes, m)
+	}
+	if len(minutes) == 0 {
+		return c
+	}
+	sort.Slice(minutes, func(i, j int) bool { return minutes[i].Before(minutes[j]) })
+	c.FirstRecord, c.LastRecord = minutes[0], minutes[len(minutes)-1]
+	for i := 1; i < len(minutes); i++ {
+		if next := minutes[i-1].Add(time.Minute); minutes[i].After(next) {
+			c.Gaps = append(c.Gaps, CoverageGap{Start: next, End: minutes[i]})
+		}
+	}
+	return c
+}
+
+// Complete reports whether nothing is known to be missing from the sample.
+func (c *SampleCoverage) Complete() bool {
+	return c.SkipDataRecords == 0 && len(c.Gaps) == 0 && len(c.FailedNATGateways) == 0 &&
+		!c.FirstRecord.IsZero() && c.startDelay() == 0 && c.endDelay() == 0
+}
+
+// startDelay is how many minutes passed before the first record arrived.
+func (c *SampleCoverage) startDelay() int {
+	if c.FirstRecord.IsZero() || !c.FirstRecord.After(c.WindowStart.Add(time.Minute)) {
+		return 0
+	}
+	return int(c.FirstRecord.Sub(c.WindowStart) / time.Minute)
+}
+
+// endDelay is how many minutes at the end of the window had no records yet
+// when the query ran.
+func (c *SampleCoverage) endDelay() int {
+	last := c.LastRecord.Add(time.Minute)
+	if c.LastRecord.IsZero() || !c.WindowEnd.After(last.Add(time.Minute)) {
+		return 0
+	}
+	return int(c.WindowEnd.Sub(last) / time.Minute)
+}
+
+// Notes describe what limits the sample, in plain sentences.
+func (c *SampleCoverage) Notes() []string {
+	var notes []string
+	if c.AggregationIntervalSeconds > 0 {
+		notes = append(notes, fmt.Sprintf("Flow Logs aggregated records over at most %d seconds", c.AggregationIntervalSeconds))
+	} else {
+		notes = append(notes, "The Flow Logs' aggregation interval is unknown (existing log group)")
+	}
+	notes = append(notes, fmt.Sprintf("%d OK, %d NODATA (no traffic in an interval) and %d SKIPDATA record(s)", c.OKRecords, c.NoDataRecords, c.SkipDataRecords))
+	if c.FirstRecord.IsZero() {
+		return append(notes, "No records were delivered during the sample window")
+	}
+	if c.SkipDataRecords > 0 {
+		notes = append(notes, "SKIPDATA records mean AWS could not capture some flows (capacity or internal errors): traffic is undercounted")
+	}
+	if d := c.startDelay(); d > 0 {
+		notes = append(notes, fmt.Sprintf("No records for the first %d minute(s) of the window while Flow Logs started delivering", d))
+	}
+	for _, g := range c.Gaps {
+		notes = append(notes, fmt.Sprintf("No records from %s to %s UTC (%d minute(s)): traffic in that gap is missing", g.Start.Format("15:04"), g.End.Format("15:04"), g.Minutes()))
+	}
+	if d := c.endDelay(); d > 0 {
+		notes = append(notes, fmt.Sprintf("The last %d minute(s) of the window were not delivered yet when the sample was queried", d))
+	}
+	if len(c.FailedNATGateways) > 0 {
+		notes = append(notes, fmt.Sprintf("Traffic queries failed for %s: their traffic is missing", strings.Join(c.FailedNATGateways, ", ")))
+	}
+	return notes
+}
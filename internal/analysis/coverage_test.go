@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+func TestParseSampleCoverage(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	row := func(status string, minute, records int) []types.ResultField {
+		return []types.ResultField{
+			{Field: strPtr("log_status"), Value: strPtr(status)},
+			{Field: strPtr("bin(1m)"), Value: strPtr(start.Add(time.Duration(minute) * time.Minute).Format("2006-01-02 15:04:05.000"))},
+			{Field: strPtr("records"), Value: strPtr(fmt.Sprint(records))},
+		}
+	}
+	var results [][]types.ResultField
+	for _, m := range []int{0, 1, 2, 6, 7, 8, 9} {
+		results = append(results, row("OK", m, 100))
+	}
+	results = append(results, row("NODATA", 9, 4), row("SKIPDATA", 7, 2))
+
+	c := ParseSampleCoverage(results, start, start.Add(10*time.Minute), 60)
+	if c.OKRecords != 700 || c.NoDataRecords != 4 || c.SkipDataRecords != 2 {
+		t.Errorf("records = %d OK, %d NODATA, %d SKIPDATA", c.OKRecords, c.NoDataRecords, c.SkipDataRecords)
+	}
+	if len(c.Gaps) != 1 || c.Gaps[0].Start != start.Add(3*time.Minute) || c.Gaps[0].Minutes() != 3 {
+		t.Fatalf("gaps = %+v", c.Gaps)
+	}
+	if c.Complete() {
+		t.Error("a sample with gaps and SKIPDATA records is not complete")
+	}
+	notes := strings.Join(c.Notes(), "\n")
+	for _, want := range []string{"at most 60 seconds", "SKIPDATA records mean", "No records from 10:03 to 10:06 UTC (3 minute(s))"} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("notes missing %q:\n%s", want, notes)
+		}
+	}
+}
+
+func TestSampleCoverageComplete(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	var results [][]types.ResultField
+	for m := 0; m < 5; m++ {
+		results = append(results, []types.ResultField{
+			{Field: strPtr("log_status"), Value: strPtr("OK")},
+			{Field: strPtr("bin(1m)"), Value: strPtr(start.Add(time.Duration(m) * time.Minute).Format("2006-01-02 15:04:05.000"))},
+			{Field: strPtr("records"), Value: strPtr("10")},
+		})
+	}
+	c := ParseSampleCoverage(results, start, start.Add(5*time.Minute), 0)
+	if !c.Complete() {
+		t.Errorf("expected a complete sample, notes: %v", c.Notes())
+	}
+	if !strings.Contains(strings.Join(c.Notes(), "\n"), "aggregation interval is unknown") {
+		t.Error("an unknown aggregation interval should be disclosed")
+	}
+
+	c.FailedNATGateways = []string{"nat-0abc"}
+	if c.Complete() {
+		t.Error("a failed NAT Gateway query leaves the sample incomplete")
+	}
+
+	empty := ParseSampleCoverage(nil, start, start.Add(5*time.Minute), 60)
+	if empty.Complete() || !strings.Contains(strings.Join(empty.Notes(), "\n"), "No records were delivered") {
+		t.Errorf("empty sample notes = %v", empty.Notes())
+	}
+}
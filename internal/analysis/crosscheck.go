@@ -0,0 +1,118 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// maxDisagreements caps the disagreements a ClassificationCheck keeps.
+const maxDisagreements = 10
+
+// ClassificationCheck compares the IP-range classification of each
+// destination with the AWS service Flow Logs recorded for it
+// (pkt-dst-aws-service). Bytes to "AMAZON", the umbrella range overlapping
+// every service, are not compared.
+type ClassificationCheck struct {
+	ComparedBytes int64 `json:"compared_bytes"`
+	AgreedBytes   int64 `json:"agreed_bytes"`
+	// Disagreements are the largest destinations the two disagree on.
+	Disagreements []ClassificationDisagreement `json:"disagreements,omitempty"`
+}
+
+// ClassificationDisagreement is a destination classified one way by the IP
+// ranges and another by Flow Logs.
+type ClassificationDisagreement struct {
+	Address string `json:"address"`
+	// IPRanges is the classifier's service: s3, dynamodb, ecr or other.
+	IPRanges string `json:"ip_ranges"`
+	// FlowLogs is pkt-dst-aws-service as recorded, "-" for none.
+	FlowLogs string `json:"flow_logs"`
+	Bytes    int64  `json:"bytes"`
+	Flows    int64  `json:"flows"`
+}
+
+// AgreementPct is the share of compared bytes both classified alike.
+func (c *ClassificationCheck) AgreementPct() float64 {
+	if c == nil || c.ComparedBytes == 0 {
+		return 0
+	}
+	return float64(c.AgreedBytes) / float64(c.ComparedBytes) * 100
+}
+
+// flowLogService maps a pkt-dst-aws-service value to the classifier's
+// services; "" for AMAZON, which overlaps them all.
+func flowLogService(awsService string) string {
+	switch strings.ToUpper(awsService) {
+	case "AMAZON":
+		return ""
+	case "S3":
+		return "s3"
+	case "DYNAMODB":
+		return "dynamodb"
+	case "EC2":
+		// The classifier counts the broad EC2 range as ECR
+		return "ecr"
+	}
+	return "other"
+}
+
+// CheckClassification reads Logs Insights rows of resolved_dst, aws_service,
+// total_bytes and flow_count, and classifies each destination both ways. It
+// returns nil when no row carries the Flow Logs service, e.g. for log groups
+// in the format older termiNATor versions created.
+func (ta *TrafficAnalyzer) CheckClassification(results [][]types.ResultField) *ClassificationCheck {
+	c := &ClassificationCheck{}
+	rows := 0
+	var disagreements []ClassificationDisagreement
+	for _, row := range results {
+		var dst, awsService string
+		var bytes, flows int64
+		for _, field := range row {
+			if field.Field == nil || field.Value == nil {
+				continue
+			}
+			switch *field.Field {
+			case "resolved_dst":
+				dst = *field.Value
+			case "aws_service":
+				awsService = strings.TrimSpace(*field.Value)
+			case "total_bytes":
+				bytes, _ = parseAggregatedBytes(*field.Value)
+			case "flow_count":
+				flows, _ = strconv.ParseInt(*field.Value, 10, 64)
+			}
+		}
+		if dst == "" || awsService == "" {
+			continue
+		}
+		rows++
+		want := flowLogService(awsService)
+		if want == "" {
+			continue
+		}
+		got, _ := ta.classifier.ClassifyIPMatch(dst)
+		c.ComparedBytes += bytes
+		if got == want {
+			c.AgreedBytes += bytes
+			continue
+		}
+		disagreements = append(disagreements, ClassificationDisagreement{Address: dst, IPRanges: got, FlowLogs: awsService, Bytes: bytes, Flows: flows})
+	}
+	if rows == 0 {
+		return nil
+	}
+	sort.Slice(disagreements, func(i, j int) bool {
+		if disagreements[i].Bytes != disagreements[j].Bytes {
+			return disagreements[i].Bytes > disagreements[j].Bytes
+		}
+		return disagreements[i].Address < disagreements[j].Address
+	})
+	if len(disagreements) > maxDisagreements {
+		disagreements = disagreements[:maxDisagreements]
+	}
+	c.Disagreements = disagreements
+	return c
+}
@@ -0,0 +1,50 @@
+package analysis
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+func TestCheckClassification(t *testing.T) {
+	_, s3Net, _ := net.ParseCIDR("52.216.0.0/15")
+	_, ec2Net, _ := net.ParseCIDR("3.80.0.0/12")
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{
+		s3Ranges:  []*net.IPNet{s3Net},
+		ecrRanges: []*net.IPNet{ec2Net},
+	}}
+	row := func(dst, service string, bytes int) []types.ResultField {
+		return []types.ResultField{
+			{Field: strPtr("resolved_dst"), Value: strPtr(dst)},
+			{Field: strPtr("aws_service"), Value: strPtr(service)},
+			{Field: strPtr("total_bytes"), Value: strPtr(fmt.Sprint(bytes))},
+			{Field: strPtr("flow_count"), Value: strPtr("3")},
+		}
+	}
+
+	check := ta.CheckClassification([][]types.ResultField{
+		row("52.216.0.1", "S3", 600),
+		row("3.80.0.1", "EC2", 200),
+		row("3.80.0.2", "AMAZON", 5000), // overlaps every service: not compared
+		row("8.8.8.8", "-", 100),
+		row("3.80.0.3", "DYNAMODB", 100),
+	})
+	if check == nil {
+		t.Fatal("expected a cross-check")
+	}
+	if check.ComparedBytes != 1000 || check.AgreedBytes != 900 || check.AgreementPct() != 90 {
+		t.Errorf("compared=%d agreed=%d pct=%.1f", check.ComparedBytes, check.AgreedBytes, check.AgreementPct())
+	}
+	if len(check.Disagreements) != 1 {
+		t.Fatalf("disagreements = %+v", check.Disagreements)
+	}
+	if d := check.Disagreements[0]; d.Address != "3.80.0.3" || d.IPRanges != "ecr" || d.FlowLogs != "DYNAMODB" || d.Flows != 3 {
+		t.Errorf("disagreement = %+v", d)
+	}
+
+	if ta.CheckClassification(nil) != nil {
+		t.Error("expected nil without pkt-dst-aws-service rows")
+	}
+}
@@ -0,0 +1,235 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// EndpointHostname returns the default regional hostname of an AWS service
+// endpoint, "" for services that are not AWS services (PrivateLink partners,
+// endpoint services in other accounts):
+//
+//	com.amazonaws.us-east-1.s3      -> s3.us-east-1.amazonaws.com
+//	com.amazonaws.us-east-1.ecr.dkr -> dkr.ecr.us-east-1.amazonaws.com
+func EndpointHostname(serviceName string) string {
+	parts := strings.Split(serviceName, ".")
+	if len(parts) < 4 || parts[0] != "com" || parts[1] != "amazonaws" || parts[2] == "vpce" {
+		return ""
+	}
+	region, svc := parts[2], parts[3:]
+	labels := make([]string, 0, len(svc)+3)
+	for i := len(svc) - 1; i >= 0; i-- {
+		labels = append(labels, svc[i])
+	}
+	labels = append(labels, region, "amazonaws", "com")
+	return strings.Join(labels, ".")
+}
+
+// endpointService is the service part of an endpoint's service name, using
+// the labels other findings use for S3 and DynamoDB.
+func endpointService(serviceName string) string {
+	parts := strings.SplitN(serviceName, ".", 4)
+	if len(parts) < 4 {
+		return serviceName
+	}
+	switch parts[3] {
+	case "s3":
+		return "S3"
+	case "dynamodb":
+		return "DynamoDB"
+	}
+	return parts[3]
+}
+
+// domainCovers reports whether name is domain or a subdomain of it.
+func domainCovers(domain, name string) bool {
+	return domain == "." || name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// governingRule returns the Resolver rule that answers for name: the one
+// with the most specific matching domain, as Route 53 Resolver picks it.
+func governingRule(rules []types.ResolverRule, name string) *types.ResolverRule {
+	var best *types.ResolverRule
+	for i := range rules {
+		r := &rules[i]
+		if !domainCovers(r.DomainName, name) {
+			continue
+		}
+		if best == nil || len(r.DomainName) > len(best.DomainName) {
+			best = r
+		}
+	}
+	return best
+}
+
+// AnalyzeEndpointDNS checks that the hostnames of a VPC's endpoints resolve
+// to the endpoints. A VPC endpoint only keeps traffic off the NAT Gateway
+// when clients resolve the service's default hostname to it; when they don't,
+// traffic silently keeps flowing through NAT although the endpoint exists:
+//
+//   - interface endpoints with private DNS disabled
+//   - VPCs with DNS resolution or DNS hostnames disabled, which private DNS needs
+//   - Resolver forwarding rules that send an endpoint's hostname to other resolvers
+//   - private hosted zones that override an endpoint's hostname
+func AnalyzeEndpointDNS(endpoints []types.VPCEndpoint, dns *types.VPCDNS) []types.Finding {
+	if dns == nil {
+		return nil
+	}
+	vpcID := dns.VPCID
+
+	gateways := map[string]bool{}
+	for _, ep := range endpoints {
+		if ep.Type == "Gateway" {
+			gateways[ep.ServiceName] = true
+		}
+	}
+
+	var findings []types.Finding
+	var privateDNS []types.VPCEndpoint
+	for _, ep := range endpoints {
+		if ep.Type != "Interface" || !strings.EqualFold(ep.State, "available") {
+			continue
+		}
+		host := EndpointHostname(ep.ServiceName)
+		if host == "" {
+			continue
+		}
+		if ep.PrivateDNS {
+			privateDNS = append(privateDNS, ep)
+			continue
+		}
+		// An S3 interface endpoint next to an S3 gateway endpoint usually has
+		// private DNS off on purpose: the default hostname reaches S3 through
+		// the gateway, and the interface endpoint serves on-premises clients.
+		if gateways[ep.ServiceName] {
+			continue
+		}
+		findings = append(findings, types.Finding{
+			Type:        "endpoint-dns",
+			Severity:    "high",
+			Title:       fmt.Sprintf("Private DNS disabled on %s interface endpoint", endpointService(ep.ServiceName)),
+			Description: fmt.Sprintf("VPC %s: %s resolves to public addresses, so clients that use it bypass endpoint %s and go through NAT Gateway", vpcID, host, ep.ID),
+			VPCID:       vpcID,
+			Service:     endpointService(ep.ServiceName),
+			Action:      fmt.Sprintf("aws ec2 modify-vpc-endpoint --vpc-endpoint-id %s --private-dns-enabled", shellQuote(ep.ID)),
+			Impact:      "The endpoint is paid for but traffic to the service still incurs NAT Gateway data processing charges",
+			Confidence:  0.8,
+			Effort:      EffortLow,
+			Remediation: []types.RemediationAction{ec2Action("ModifyVpcEndpoint", map[string]any{"VpcEndpointId": ep.ID, "PrivateDnsEnabled": true})},
+		})
+	}
+
+	if len(privateDNS) > 0 && (!dns.DNSSupport || !dns.DNSHostnames) {
+		var disabled, commands []string
+		var actions []types.RemediationAction
+		// ModifyVpcAttribute changes one attribute per call
+		if !dns.DNSSupport {
+			disabled = append(disabled, "DNS resolution")
+			commands = append(commands, fmt.Sprintf("aws ec2 modify-vpc-attribute --vpc-id %s --enable-dns-support", shellQuote(vpcID)))
+			actions = append(actions, ec2Action("ModifyVpcAttribute", map[string]any{"VpcId": vpcID, "EnableDnsSupport": map[string]any{"Value": true}}))
+		}
+		if !dns.DNSHostnames {
+			disabled = append(disabled, "DNS hostnames")
+			commands = append(commands, fmt.Sprintf("aws ec2 modify-vpc-attribute --vpc-id %s --enable-dns-hostnames", shellQuote(vpcID)))
+			actions = append(actions, ec2Action("ModifyVpcAttribute", map[string]any{"VpcId": vpcID, "EnableDnsHostnames": map[string]any{"Value": true}}))
+		}
+		findings = append(findings, types.Finding{
+			Type:        "endpoint-dns",
+			Severity:    "high",
+			Title:       "VPC DNS settings break interface endpoint private DNS",
+			Description: fmt.Sprintf("VPC %s has %s disabled; private DNS of its %d interface endpoint(s) needs both enabled", vpcID, strings.Join(disabled, " and "), len(privateDNS)),
+			VPCID:       vpcID,
+			Service:     "DNS",
+			Action:      strings.Join(commands, "\n"),
+			Impact:      "Service hostnames resolve to public addresses and traffic goes through NAT Gateway",
+			Confidence:  0.8,
+			Effort:      EffortLow,
+			Remediation: actions,
+		})
+	}
+
+	// Resolver rules only matter for names the VPC resolver would otherwise
+	// answer with endpoint addresses.
+	forwarded := map[string][]string{}
+	for _, ep := range privateDNS {
+		host := EndpointHostname(ep.ServiceName)
+		if rule := governingRule(dns.ResolverRules, host); rule != nil && rule.RuleType == "FORWARD" {
+			label := rule.ID
+			if rule.Name != "" {
+				label = rule.Name + " (" + rule.ID + ")"
+			}
+			forwarded[label] = append(forwarded[label], host)
+		}
+	}
+	for _, label := range sortedKeys(forwarded) {
+		hosts := forwarded[label]
+		findings = append(findings, types.Finding{
+			Type:        "endpoint-dns",
+			Severity:    "medium",
+			Title:       "Resolver rule forwards endpoint hostnames",
+			Description: fmt.Sprintf("VPC %s: Resolver rule %s forwards %s to other resolvers, which answer with public addresses unless they forward back to the VPC resolver", vpcID, label, strings.Join(hosts, ", ")),
+			VPCID:       vpcID,
+			Service:     "DNS",
+			Action:      fmt.Sprintf("Add a SYSTEM Resolver rule for each hostname (for example aws route53resolver create-resolver-rule --rule-type SYSTEM --domain-name %s) and associate it with %s", shellQuote(hosts[0]), vpcID),
+			Impact:      "Clients resolving through the rule bypass the interface endpoints and go through NAT Gateway",
+			Confidence:  0.6,
+			Effort:      EffortLow,
+		})
+	}
+
+	// Private hosted zones win over endpoint private DNS and gateway endpoint
+	// routing alike; a zone for a service hostname usually points it at a
+	// proxy or a shared endpoint in another VPC.
+	shadowed := map[string][]string{}
+	for _, ep := range endpoints {
+		if !strings.EqualFold(ep.State, "available") || (ep.Type == "Interface" && !ep.PrivateDNS) {
+			continue
+		}
+		host := EndpointHostname(ep.ServiceName)
+		if host == "" {
+			continue
+		}
+		for _, zone := range dns.PrivateZones {
+			if zone != "." && domainCovers(zone, host) && !containsString(shadowed[zone], host) {
+				shadowed[zone] = append(shadowed[zone], host)
+			}
+		}
+	}
+	for _, zone := range sortedKeys(shadowed) {
+		findings = append(findings, types.Finding{
+			Type:        "endpoint-dns",
+			Severity:    "medium",
+			Title:       fmt.Sprintf("Private hosted zone %s overrides endpoint hostnames", zone),
+			Description: fmt.Sprintf("VPC %s: private hosted zone %s answers for %s instead of the VPC endpoints", vpcID, zone, strings.Join(shadowed[zone], ", ")),
+			VPCID:       vpcID,
+			Service:     "DNS",
+			Action:      fmt.Sprintf("Check that the records in %s point at a VPC endpoint, or disassociate the zone from %s", zone, vpcID),
+			Impact:      "Traffic to the overridden hostnames may go through NAT Gateway",
+			Confidence:  0.5,
+			Effort:      EffortLow,
+		})
+	}
+
+	return findings
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestEndpointHostname(t *testing.T) {
+	cases := map[string]string{
+		"com.amazonaws.us-east-1.s3":                "s3.us-east-1.amazonaws.com",
+		"com.amazonaws.eu-west-1.ecr.dkr":           "dkr.ecr.eu-west-1.amazonaws.com",
+		"com.amazonaws.eu-west-1.ecr.api":           "api.ecr.eu-west-1.amazonaws.com",
+		"com.amazonaws.vpce.us-east-1.vpce-svc-123": "",
+		"aws.sagemaker.us-east-1.notebook":          "",
+	}
+	for in, want := range cases {
+		if got := EndpointHostname(in); got != want {
+			t.Errorf("EndpointHostname(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func dnsFindingsByTitle(findings []types.Finding) map[string]types.Finding {
+	m := map[string]types.Finding{}
+	for _, f := range findings {
+		m[f.Title] = f
+	}
+	return m
+}
+
+func TestAnalyzeEndpointDNSPrivateDNSDisabled(t *testing.T) {
+	endpoints := []types.VPCEndpoint{
+		{ID: "vpce-sts", ServiceName: "com.amazonaws.us-east-1.sts", Type: "Interface", State: "available"},
+		{ID: "vpce-ecr", ServiceName: "com.amazonaws.us-east-1.ecr.dkr", Type: "Interface", State: "available", PrivateDNS: true},
+		// Private DNS off on purpose next to the gateway endpoint.
+		{ID: "vpce-s3gw", ServiceName: "com.amazonaws.us-east-1.s3", Type: "Gateway", State: "available"},
+		{ID: "vpce-s3if", ServiceName: "com.amazonaws.us-east-1.s3", Type: "Interface", State: "available"},
+		{ID: "vpce-pending", ServiceName: "com.amazonaws.us-east-1.ssm", Type: "Interface", State: "pending"},
+	}
+	dns := &types.VPCDNS{VPCID: "vpc-1", DNSSupport: true, DNSHostnames: true}
+
+	findings := AnalyzeEndpointDNS(endpoints, dns)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Service != "sts" || f.Severity != "high" || !strings.Contains(f.Action, "--vpc-endpoint-id 'vpce-sts' --private-dns-enabled") {
+		t.Errorf("unexpected finding: %+v", f)
+	}
+	if len(f.Remediation) != 1 || f.Remediation[0].Action != "ModifyVpcEndpoint" || f.Remediation[0].Parameters["VpcEndpointId"] != "vpce-sts" {
+		t.Errorf("unexpected remediation: %+v", f.Remediation)
+	}
+}
+
+func TestAnalyzeEndpointDNSVPCAttributes(t *testing.T) {
+	endpoints := []types.VPCEndpoint{
+		{ID: "vpce-ecr", ServiceName: "com.amazonaws.us-east-1.ecr.dkr", Type: "Interface", State: "Available", PrivateDNS: true},
+	}
+	findings := AnalyzeEndpointDNS(endpoints, &types.VPCDNS{VPCID: "vpc-1", DNSSupport: true})
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+	}
+	if !strings.Contains(findings[0].Action, "--enable-dns-hostnames") || strings.Contains(findings[0].Action, "--enable-dns-support") {
+		t.Errorf("unexpected action: %q", findings[0].Action)
+	}
+	if r := findings[0].Remediation; len(r) != 1 || r[0].Action != "ModifyVpcAttribute" || r[0].Parameters["EnableDnsHostnames"] == nil {
+		t.Errorf("unexpected remediation: %+v", r)
+	}
+
+	// Without interface endpoints the attributes don't matter to NAT traffic.
+	gateway := []types.VPCEndpoint{{ID: "vpce-s3", ServiceName: "com.amazonaws.us-east-1.s3", Type: "Gateway", State: "available"}}
+	if findings := AnalyzeEndpointDNS(gateway, &types.VPCDNS{VPCID: "vpc-1"}); len(findings) != 0 {
+		t.Errorf("expected no findings for gateway endpoints only, got %+v", findings)
+	}
+}
+
+func TestAnalyzeEndpointDNSResolverRules(t *testing.T) {
+	endpoints := []types.VPCEndpoint{
+		{ID: "vpce-ecr", ServiceName: "com.amazonaws.us-east-1.ecr.dkr", Type: "Interface", State: "available", PrivateDNS: true},
+		{ID: "vpce-sts", ServiceName: "com.amazonaws.us-east-1.sts", Type: "Interface", State: "available", PrivateDNS: true},
+	}
+	dns := &types.VPCDNS{
+		VPCID: "vpc-1", DNSSupport: true, DNSHostnames: true,
+		ResolverRules: []types.ResolverRule{
+			{ID: "rslvr-rr-all", Name: "onprem", DomainName: ".", RuleType: "FORWARD"},
+			// The more specific SYSTEM rule keeps STS on the VPC resolver.
+			{ID: "rslvr-rr-sts", DomainName: "sts.us-east-1.amazonaws.com", RuleType: "SYSTEM"},
+		},
+	}
+
+	findings := AnalyzeEndpointDNS(endpoints, dns)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+	}
+	d := findings[0].Description
+	if !strings.Contains(d, "onprem (rslvr-rr-all)") || !strings.Contains(d, "dkr.ecr.us-east-1.amazonaws.com") || strings.Contains(d, "sts.") {
+		t.Errorf("unexpected description: %q", d)
+	}
+}
+
+func TestAnalyzeEndpointDNSPrivateZones(t *testing.T) {
+	endpoints := []types.VPCEndpoint{
+		{ID: "vpce-s3gw", ServiceName: "com.amazonaws.us-east-1.s3", Type: "Gateway", State: "available"},
+		{ID: "vpce-s3if", ServiceName: "com.amazonaws.us-east-1.s3", Type: "Interface", State: "available", PrivateDNS: true},
+		{ID: "vpce-ddb", ServiceName: "com.amazonaws.us-east-1.dynamodb", Type: "Gateway", State: "available"},
+	}
+	dns := &types.VPCDNS{
+		VPCID: "vpc-1", DNSSupport: true, DNSHostnames: true,
+		PrivateZones: []string{"s3.us-east-1.amazonaws.com", "corp.example.com"},
+	}
+
+	byTitle := dnsFindingsByTitle(AnalyzeEndpointDNS(endpoints, dns))
+	if len(byTitle) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", byTitle)
+	}
+	f, ok := byTitle["Private hosted zone s3.us-east-1.amazonaws.com overrides endpoint hostnames"]
+	if !ok {
+		t.Fatalf("missing private zone finding: %+v", byTitle)
+	}
+	if strings.Count(f.Description, "s3.us-east-1.amazonaws.com") != 2 {
+		t.Errorf("expected the zone and its hostname once each: %q", f.Description)
+	}
+}
+
+type dnsScanner struct {
+	endpoints []types.VPCEndpoint
+	dns       *types.VPCDNS
+}
+
+func (s dnsScanner) DiscoverVPCEndpoints(ctx context.Context, vpcID string) ([]types.VPCEndpoint, error) {
+	return s.endpoints, nil
+}
+
+func (s dnsScanner) DiscoverRouteTables(ctx context.Context, vpcID string) ([]types.RouteTable, error) {
+	return nil, nil
+}
+
+func (s dnsScanner) DiscoverVPCDNS(ctx context.Context, vpcID string) (*types.VPCDNS, error) {
+	return s.dns, nil
+}
+
+func TestAnalyzeAllVPCEndpointsChecksDNS(t *testing.T) {
+	scanner := dnsScanner{
+		endpoints: []types.VPCEndpoint{
+			{ID: "vpce-s3", ServiceName: "com.amazonaws.us-east-1.s3", Type: "Gateway", State: "available"},
+			{ID: "vpce-ddb", ServiceName: "com.amazonaws.us-east-1.dynamodb", Type: "Gateway", State: "available"},
+			{ID: "vpce-sts", ServiceName: "com.amazonaws.us-east-1.sts", Type: "Interface", State: "available"},
+		},
+		dns: &types.VPCDNS{VPCID: "vpc-1", DNSSupport: true, DNSHostnames: true},
+	}
+	findings := AnalyzeAllVPCEndpoints(context.Background(), scanner, []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1"}})
+	if len(findings) != 1 || findings[0].Type != "endpoint-dns" {
+		t.Fatalf("expected the private DNS finding, got %+v", findings)
+	}
+}
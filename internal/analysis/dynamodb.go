@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// DynamoDBEndpoint is a DynamoDB hostname seen in Route 53 Resolver query logs.
+type DynamoDBEndpoint struct {
+	Hostname    string `json:"hostname"`
+	Region      string `json:"region"`
+	Queries     int    `json:"queries"`
+	Clients     int    `json:"clients"`
+	CrossRegion bool   `json:"cross_region"`
+}
+
+// ParseDynamoDBEndpoints reads Logs Insights rows grouped by query_name with
+// queries and clients columns, marking hostnames outside homeRegion. Rows are
+// returned busiest first.
+func ParseDynamoDBEndpoints(results [][]types.ResultField, homeRegion string) []DynamoDBEndpoint {
+	byHost := make(map[string]*DynamoDBEndpoint)
+	for _, row := range results {
+		var host string
+		var queries, clients int
+		for _, field := range row {
+			if field.Field == nil || field.Value == nil {
+				continue
+			}
+			switch *field.Field {
+			case "query_name":
+				host = strings.ToLower(strings.TrimSuffix(*field.Value, "."))
+			case "queries":
+				queries, _ = strconv.Atoi(*field.Value)
+			case "clients":
+				clients, _ = strconv.Atoi(*field.Value)
+			}
+		}
+		region := DynamoDBEndpointRegion(host)
+		if region == "" {
+			continue
+		}
+		ep, ok := byHost[host]
+		if !ok {
+			ep = &DynamoDBEndpoint{Hostname: host, Region: region, CrossRegion: region != homeRegion}
+			byHost[host] = ep
+		}
+		ep.Queries += queries
+		if clients > ep.Clients {
+			ep.Clients = clients
+		}
+	}
+
+	out := make([]DynamoDBEndpoint, 0, len(byHost))
+	for _, ep := range byHost {
+		out = append(out, *ep)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Queries != out[j].Queries {
+			return out[i].Queries > out[j].Queries
+		}
+		return out[i].Hostname < out[j].Hostname
+	})
+	return out
+}
+
+// DynamoDBEndpointRegion extracts the region from a DynamoDB hostname such as
+// dynamodb.eu-west-1.amazonaws.com, streams.dynamodb.us-east-1.amazonaws.com or
+// the account-based 123456789012.ddb.us-west-2.amazonaws.com. It returns ""
+// for hostnames that are not DynamoDB endpoints.
+func DynamoDBEndpointRegion(host string) string {
+	labels := strings.Split(host, ".")
+	for i := 0; i+1 < len(labels); i++ {
+		switch labels[i] {
+		case "dynamodb", "dynamodb-fips", "ddb":
+			if region := labels[i+1]; looksLikeRegion(region) {
+				return region
+			}
+		}
+	}
+	return ""
+}
+
+// looksLikeRegion matches names like us-east-1 or ap-southeast-2.
+func looksLikeRegion(s string) bool {
+	parts := strings.Split(s, "-")
+	if len(parts) < 3 {
+		return false
+	}
+	_, err := strconv.Atoi(parts[len(parts)-1])
+	return err == nil
+}
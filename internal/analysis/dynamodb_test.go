@@ -0,0 +1,80 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+func TestDynamoDBEndpointRegion(t *testing.T) {
+	cases := map[string]string{
+		"dynamodb.eu-west-1.amazonaws.com":          "eu-west-1",
+		"streams.dynamodb.us-east-1.amazonaws.com":  "us-east-1",
+		"123456789012.ddb.us-west-2.amazonaws.com":  "us-west-2",
+		"dynamodb-fips.us-gov-west-1.amazonaws.com": "us-gov-west-1",
+		"s3.us-east-1.amazonaws.com":                "",
+		"dynamodb.amazonaws.com":                    "",
+	}
+	for host, want := range cases {
+		if got := DynamoDBEndpointRegion(host); got != want {
+			t.Errorf("DynamoDBEndpointRegion(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestParseDynamoDBEndpoints(t *testing.T) {
+	results := [][]types.ResultField{
+		row("query_name", "dynamodb.us-east-1.amazonaws.com.", "queries", "120", "clients", "8"),
+		row("query_name", "dynamodb.eu-west-1.amazonaws.com.", "queries", "300", "clients", "2"),
+		row("query_name", "sts.us-east-1.amazonaws.com.", "queries", "900", "clients", "9"),
+	}
+
+	got := ParseDynamoDBEndpoints(results, "us-east-1")
+	if len(got) != 2 {
+		t.Fatalf("expected 2 DynamoDB endpoints, got %d: %+v", len(got), got)
+	}
+	if got[0].Hostname != "dynamodb.eu-west-1.amazonaws.com" || !got[0].CrossRegion || got[0].Queries != 300 {
+		t.Errorf("busiest endpoint = %+v, want cross-region eu-west-1 with 300 queries", got[0])
+	}
+	if got[1].CrossRegion {
+		t.Errorf("same-region endpoint flagged as cross-region: %+v", got[1])
+	}
+}
+
+func TestCalculateCostsExcludesCrossRegionDynamoDB(t *testing.T) {
+	gb := int64(1024 * 1024 * 1024)
+	stats := &TrafficStats{
+		TotalBytes: 4 * gb,
+		Services: map[Service]ServiceStats{
+			ServiceDynamoDB: {Bytes: 4 * gb},
+		},
+		DynamoBytesByRegion: map[string]int64{"us-east-1": gb, "eu-west-1": 3 * gb},
+	}
+	est := CalculateCosts("us-east-1", stats, 43200)
+	assertApprox(t, est.CrossRegionDynamoGB, 3, 0.0001, "cross-region DynamoDB GB")
+	assertApprox(t, est.DynamoSavingsMonthly, 1*0.045, 0.0001, "DynamoDB savings")
+	assertApprox(t, est.TotalSavingsMonthly, 1*0.045, 0.0001, "total savings")
+}
+
+func TestSeasonalAndBackfillSavingsExcludeCrossRegionDynamoDB(t *testing.T) {
+	gb := int64(1024 * 1024 * 1024)
+	stats := &TrafficStats{
+		TotalBytes:   4 * gb,
+		TotalRecords: 1,
+		Services: map[Service]ServiceStats{
+			ServiceDynamoDB: {Bytes: 4 * gb},
+		},
+		DynamoBytesByRegion: map[string]int64{"us-east-1": gb, "eu-west-1": 3 * gb},
+	}
+	mon := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// One 720-hour sample projects one-to-one onto a month
+	est := CalculateSeasonalCosts("us-east-1", []TrafficSample{{Start: mon, Duration: 720 * time.Hour, Stats: stats}})
+	assertApprox(t, est.CrossRegionDynamoGB, 3, 0.0001, "seasonal cross-region DynamoDB GB")
+	assertApprox(t, est.DynamoSavingsMonthly, 1*0.045, 0.0001, "seasonal DynamoDB savings")
+	assertApprox(t, est.TotalSavingsMonthly, 1*0.045, 0.0001, "seasonal total savings")
+
+	month := MonthlyTraffic{Month: "2024-01", Days: 31, Stats: stats}
+	assertApprox(t, month.GatewaySavings("us-east-1"), 1*0.045, 0.0001, "backfill gateway savings")
+}
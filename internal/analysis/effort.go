@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// effortSizes are the T-shirt sizes of EffortLow..EffortHigh and the rough
+// engineering hours each takes, including review and rollout.
+var effortSizes = map[int]struct {
+	size  string
+	hours float64
+}{
+	EffortLow:    {"S", 1},
+	EffortMedium: {"M", 4},
+	EffortHigh:   {"L", 24},
+}
+
+// EffortSize is effort as S, M or L. Unset effort is the default, M.
+func EffortSize(effort int) string {
+	if s, ok := effortSizes[effort]; ok {
+		return s.size
+	}
+	return effortSizes[defaultEffort].size
+}
+
+// EffortHours is the rough number of engineering hours effort takes.
+func EffortHours(effort int) float64 {
+	if s, ok := effortSizes[effort]; ok {
+		return s.hours
+	}
+	return effortSizes[defaultEffort].hours
+}
+
+// EffortLabel is effort as reports print it, e.g. "S (~1h)" or "L (~3d)",
+// counting 8-hour days.
+func EffortLabel(effort int) string {
+	hours := EffortHours(effort)
+	if hours >= 8 {
+		return fmt.Sprintf("%s (~%gd)", EffortSize(effort), hours/8)
+	}
+	return fmt.Sprintf("%s (~%gh)", EffortSize(effort), hours)
+}
+
+// PlanItem is one priced finding or recommendation in a RemediationPlan.
+type PlanItem struct {
+	Title          string  `json:"title"`
+	Effort         string  `json:"effort"`
+	Hours          float64 `json:"hours"`
+	MonthlySavings float64 `json:"monthly_savings"`
+	// SavingsPerHour is MonthlySavings per hour of effort.
+	SavingsPerHour float64 `json:"savings_per_hour"`
+}
+
+// RemediationPlan is every priced finding and recommendation ordered by
+// savings per hour of effort, with the totals a remediation sprint is
+// planned from.
+type RemediationPlan struct {
+	Items          []PlanItem `json:"items"`
+	Hours          float64    `json:"hours"`
+	MonthlySavings float64    `json:"monthly_savings"`
+}
+
+// PlanRemediation builds the RemediationPlan of findings and recs. Items
+// without a savings estimate are left out; equal savings per hour keep
+// findings before recommendations.
+func PlanRemediation(findings []types.Finding, recs []Recommendation) RemediationPlan {
+	var p RemediationPlan
+	add := func(title string, savings float64, effort int) {
+		hours := EffortHours(effort)
+		p.Items = append(p.Items, PlanItem{
+			Title:          title,
+			Effort:         EffortSize(effort),
+			Hours:          hours,
+			MonthlySavings: savings,
+			SavingsPerHour: savings / hours,
+		})
+		p.Hours += hours
+		p.MonthlySavings += savings
+	}
+	for _, f := range findings {
+		if f.SavingsEstimated {
+			add(f.Title, f.MonthlySavings, f.Effort)
+		}
+	}
+	for _, rec := range recs {
+		if rec.SavingsEstimated {
+			add(rec.Title, rec.MonthlySavings, rec.Effort)
+		}
+	}
+	sort.SliceStable(p.Items, func(i, j int) bool { return p.Items[i].SavingsPerHour > p.Items[j].SavingsPerHour })
+	return p
+}
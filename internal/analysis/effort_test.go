@@ -0,0 +1,37 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestEffortLabel(t *testing.T) {
+	for effort, want := range map[int]string{EffortLow: "S (~1h)", EffortMedium: "M (~4h)", EffortHigh: "L (~3d)", 0: "M (~4h)"} {
+		if got := EffortLabel(effort); got != want {
+			t.Errorf("EffortLabel(%d) = %q, want %q", effort, got, want)
+		}
+	}
+}
+
+func TestPlanRemediationOrdersBySavingsPerHour(t *testing.T) {
+	findings := []types.Finding{
+		{Title: "interface endpoints", MonthlySavings: 100, SavingsEstimated: true, Effort: EffortMedium},
+		{Title: "S3 gateway", MonthlySavings: 40, SavingsEstimated: true, Effort: EffortLow},
+		{Title: "unpriced", Effort: EffortLow},
+	}
+	recs := []Recommendation{
+		{Title: "pull-through cache", MonthlySavings: 240, SavingsEstimated: true, Effort: EffortHigh},
+	}
+
+	p := PlanRemediation(findings, recs)
+	if len(p.Items) != 3 || p.Items[0].Title != "S3 gateway" || p.Items[1].Title != "interface endpoints" || p.Items[2].Title != "pull-through cache" {
+		t.Fatalf("unexpected plan: %+v", p.Items)
+	}
+	assertApprox(t, p.Items[1].SavingsPerHour, 25, 0.001, "interface endpoints per hour")
+	assertApprox(t, p.Hours, 29, 0.001, "total hours")
+	assertApprox(t, p.MonthlySavings, 380, 0.001, "total savings")
+	if p.Items[2].Effort != "L" {
+		t.Errorf("effort = %q, want L", p.Items[2].Effort)
+	}
+}
@@ -0,0 +1,221 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doitintl/terminator/internal/units"
+)
+
+// eksBundleServices are the interface endpoints recommended for EKS node
+// subnets, alongside the S3 gateway endpoint that carries ECR image layers.
+var eksBundleServices = []string{"ecr.api", "ecr.dkr", "sts", "logs"}
+
+// regionalServiceHosts are the hostnames resolved to recognize traffic from
+// instances and EKS nodes to AWS APIs that ip-ranges.json does not list separately.
+func regionalServiceHosts(region, accountID string) map[string][]string {
+	hosts := map[string][]string{
+		"ecr.api":        {"api.ecr." + region + ".amazonaws.com"},
+		"sts":            {"sts." + region + ".amazonaws.com", "sts.amazonaws.com"},
+		"logs":           {"logs." + region + ".amazonaws.com"},
+		"monitoring":     {"monitoring." + region + ".amazonaws.com"},
+		"eks":            {"eks." + region + ".amazonaws.com"},
+		"ssm":            {"ssm." + region + ".amazonaws.com"},
+		"ec2messages":    {"ec2messages." + region + ".amazonaws.com"},
+		"ssmmessages":    {"ssmmessages." + region + ".amazonaws.com"},
+		"secretsmanager": {"secretsmanager." + region + ".amazonaws.com"},
+		"kms":            {"kms." + region + ".amazonaws.com"},
+	}
+	if accountID != "" {
+		hosts["ecr.dkr"] = []string{accountID + ".dkr.ecr." + region + ".amazonaws.com"}
+	}
+	return hosts
+}
+
+var (
+	serviceIPsMu    sync.Mutex
+	serviceIPsCache = map[string]map[string]string{}
+)
+
+// ResolveServiceIPs maps each address the regional service hostnames resolve
+// to onto the service's endpoint name (ecr.api, sts, ...). Lookup failures are skipped.
+func ResolveServiceIPs(ctx context.Context, lookup func(ctx context.Context, host string) ([]string, error), region, accountID string) map[string]string {
+	ips := make(map[string]string)
+	for service, hosts := range regionalServiceHosts(region, accountID) {
+		for _, host := range hosts {
+			addrs, err := lookup(ctx, host)
+			if err != nil {
+				continue
+			}
+			for _, addr := range addrs {
+				ips[addr] = service
+			}
+		}
+	}
+	return ips
+}
+
+// RecognizeRegionalServices resolves the regional AWS API hostnames EKS nodes
+// talk to, so their traffic is counted in TrafficStats.ServiceBytes. AWS APIs
+// answer from large address pools, so the counts are a lower bound.
+func (ta *TrafficAnalyzer) RecognizeRegionalServices(region, accountID string) {
+	key := region + "/" + accountID
+	serviceIPsMu.Lock()
+	defer serviceIPsMu.Unlock()
+	ips, ok := serviceIPsCache[key]
+	if !ok {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		ips = ResolveServiceIPs(ctx, net.DefaultResolver.LookupHost, region, accountID)
+		serviceIPsCache[key] = ips
+	}
+	ta.classifier.services = ips
+}
+
+// EKSBundleEstimate is the aggregate cost and benefit of the EKS endpoint
+// bundle: ecr.api, ecr.dkr, sts and logs interface endpoints plus the S3
+// gateway endpoint.
+type EKSBundleEstimate struct {
+	Clusters []string `json:"clusters"`
+	// MonthlyGBByService is projected traffic per bundle service, including "s3".
+	MonthlyGBByService   map[string]float64 `json:"monthly_gb_by_service"`
+	MissingEndpoints     []string           `json:"missing_endpoints"`
+	NATCostMonthly       float64            `json:"nat_cost_monthly"`
+	EndpointFixedMonthly float64            `json:"endpoint_fixed_monthly"`
+	EndpointDataMonthly  float64            `json:"endpoint_data_monthly"`
+	NetSavingsMonthly    float64            `json:"net_savings_monthly"`
+
+	endpoints *EndpointAnalysis
+}
+
+// EstimateEKSBundle prices moving EKS node traffic to ECR, STS, CloudWatch
+// Logs and S3 off the NAT Gateway. Only endpoints missing from endpoints add
+// hourly cost. S3 traffic is listed but not priced: the S3 gateway endpoint
+// removes it and its savings are counted with that endpoint, so the bundle
+// only claims what the interface endpoints take off the NAT Gateway. It
+// returns nil when no EKS clusters use the VPC.
+func EstimateEKSBundle(region string, clusters []string, stats *TrafficStats, collectionMinutes int, endpoints *EndpointAnalysis) *EKSBundleEstimate {
+	if len(clusters) == 0 || stats == nil || collectionMinutes <= 0 {
+		return nil
+	}
+	if endpoints == nil {
+		endpoints = &EndpointAnalysis{Region: region}
+	}
+
+	monthlyMultiplier := 43200.0 / float64(collectionMinutes)
+	toMonthlyGB := func(bytes int64) float64 { return units.BillingGB(bytes) * monthlyMultiplier }
+	est := &EKSBundleEstimate{
+		Clusters:           clusters,
+		MonthlyGBByService: map[string]float64{"s3": toMonthlyGB(stats.Bytes(ServiceS3))},
+		endpoints:          endpoints,
+	}
+	var interfaceGB float64
+	for _, svc := range eksBundleServices {
+		gb := toMonthlyGB(stats.ServiceBytes[svc])
+		est.MonthlyGBByService[svc] = gb
+		interfaceGB += gb
+	}
+
+	pricePerGB := NATGatewayPricePerGB(region)
+	hourlyPerAZ, dataPerGB := endpoints.GetECRInterfaceEndpointPricing()
+	azCount := endpoints.endpointAZCount()
+
+	est.NATCostMonthly = interfaceGB * pricePerGB
+	if endpoints.S3Endpoint == nil {
+		est.MissingEndpoints = append(est.MissingEndpoints, "s3")
+	}
+	for _, svc := range eksBundleServices {
+		if !endpoints.hasInterfaceEndpoint(svc) {
+			est.MissingEndpoints = append(est.MissingEndpoints, svc)
+			est.EndpointFixedMonthly += hourlyPerAZ * float64(azCount) * 24 * 30
+		}
+	}
+	est.EndpointDataMonthly = interfaceGB * dataPerGB
+	est.NetSavingsMonthly = est.NATCostMonthly - est.EndpointFixedMonthly - est.EndpointDataMonthly
+	return est
+}
+
+// hasInterfaceEndpoint reports whether the VPC has an endpoint for
+// com.amazonaws.<region>.<svc>.
+func (a *EndpointAnalysis) hasInterfaceEndpoint(svc string) bool {
+	name := fmt.Sprintf("com.amazonaws.%s.%s", a.Region, svc)
+	switch svc {
+	case "ecr.api":
+		if a.ECRAPIEndpoint != nil {
+			return true
+		}
+	case "ecr.dkr":
+		if a.ECRDKREndpoint != nil {
+			return true
+		}
+	}
+	for _, ep := range a.InterfaceEndpoints {
+		if ep.ServiceName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Recommendation turns the estimate into the EKS endpoint bundle recommendation.
+func (e *EKSBundleEstimate) Recommendation(region, vpcID string) Recommendation {
+	priority := "low"
+	if e.NetSavingsMonthly > 0 {
+		priority = "high"
+	}
+
+	var commands, interfaceNames []string
+	for _, svc := range e.MissingEndpoints {
+		serviceName := fmt.Sprintf("com.amazonaws.%s.%s", region, svc)
+		if svc == "s3" {
+			prechecks, create := e.endpoints.precheckCommands("Gateway", []string{serviceName})
+			commands = append(commands, prechecks...)
+			if len(create) == 0 {
+				continue
+			}
+			commands = append(commands, fmt.Sprintf("aws ec2 create-vpc-endpoint \\\n  --vpc-id %s \\\n  --service-name %s \\\n  --route-table-ids %s",
+				shellQuote(vpcID), shellQuote(serviceName), shellQuote("<private-route-table-ids>")))
+			continue
+		}
+		interfaceNames = append(interfaceNames, serviceName)
+	}
+	commands = append(commands, interfaceEndpointCommands(e.endpoints, vpcID, interfaceNames, shellQuote("<node-subnet-ids>"))...)
+
+	services := make([]string, 0, len(e.MonthlyGBByService))
+	for svc := range e.MonthlyGBByService {
+		services = append(services, svc)
+	}
+	sort.Strings(services)
+	var traffic []string
+	for _, svc := range services {
+		traffic = append(traffic, fmt.Sprintf("%s %.1f GB", svc, e.MonthlyGBByService[svc]))
+	}
+
+	missing := "none"
+	if len(e.MissingEndpoints) > 0 {
+		missing = strings.Join(e.MissingEndpoints, ", ")
+	}
+	return Recommendation{
+		Type:     "eks-endpoint-bundle",
+		Priority: priority,
+		Title:    fmt.Sprintf("Add the EKS endpoint bundle for %s", strings.Join(e.Clusters, ", ")),
+		Description: fmt.Sprintf("EKS nodes pull images and call STS and CloudWatch Logs through the NAT Gateway (projected monthly: %s). Missing endpoints: %s. S3 savings are counted with the S3 gateway endpoint, not here.",
+			strings.Join(traffic, ", "), missing),
+		Benefits: []string{
+			"Image layers from ECR are served from S3 over the free gateway endpoint",
+			"IRSA/Pod Identity token exchange (STS) and container log shipping stay inside the VPC",
+			"Nodes keep pulling images and shipping logs if the NAT Gateway is unavailable",
+		},
+		Commands:         commands,
+		Savings:          fmt.Sprintf("~$%.2f/month net of $%.2f/month endpoint hours and $%.2f/month endpoint data", e.NetSavingsMonthly, e.EndpointFixedMonthly, e.EndpointDataMonthly),
+		MonthlySavings:   e.NetSavingsMonthly,
+		SavingsEstimated: true,
+		Confidence:       0.6,
+		Effort:           EffortMedium,
+	}
+}
@@ -0,0 +1,67 @@
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestResolveServiceIPs(t *testing.T) {
+	lookup := func(_ context.Context, host string) ([]string, error) {
+		switch host {
+		case "sts.us-east-1.amazonaws.com":
+			return []string{"209.54.177.164"}, nil
+		case "123456789012.dkr.ecr.us-east-1.amazonaws.com":
+			return []string{"52.4.1.1", "52.4.1.2"}, nil
+		}
+		return nil, nil
+	}
+
+	ips := ResolveServiceIPs(context.Background(), lookup, "us-east-1", "123456789012")
+	if ips["209.54.177.164"] != "sts" || ips["52.4.1.2"] != "ecr.dkr" || len(ips) != 3 {
+		t.Fatalf("unexpected service IPs: %v", ips)
+	}
+}
+
+func TestEstimateEKSBundle(t *testing.T) {
+	gb := int64(1024 * 1024 * 1024)
+	stats := &TrafficStats{
+		Services: map[Service]ServiceStats{
+			ServiceS3: {Bytes: 10 * gb},
+		},
+		ServiceBytes: map[string]int64{"ecr.dkr": gb, "sts": gb, "monitoring": gb},
+	}
+	endpoints := &EndpointAnalysis{
+		Region:             "us-east-1",
+		S3Endpoint:         &types.VPCEndpoint{ID: "vpce-s3"},
+		InterfaceEndpoints: []types.VPCEndpoint{{ID: "vpce-logs", ServiceName: "com.amazonaws.us-east-1.logs"}},
+	}
+
+	if EstimateEKSBundle("us-east-1", nil, stats, 60, endpoints) != nil {
+		t.Fatal("expected no bundle without EKS clusters")
+	}
+
+	// 60-minute sample, so 720x to a month
+	est := EstimateEKSBundle("us-east-1", []string{"prod"}, stats, 60, endpoints)
+	if got := strings.Join(est.MissingEndpoints, ","); got != "ecr.api,ecr.dkr,sts" {
+		t.Errorf("MissingEndpoints = %s, want ecr.api,ecr.dkr,sts", got)
+	}
+	assertApprox(t, est.MonthlyGBByService["s3"], 7200, 0.001, "s3 monthly GB")
+	// Only the interface endpoint traffic: the S3 gateway endpoint's savings
+	// are not counted a second time
+	assertApprox(t, est.NATCostMonthly, 2*720*0.045, 0.001, "NAT cost avoided")
+	// Three missing interface endpoints in one AZ
+	assertApprox(t, est.EndpointFixedMonthly, 3*0.01*720, 0.001, "endpoint hours")
+	assertApprox(t, est.EndpointDataMonthly, 2*720*0.01, 0.001, "endpoint data")
+	assertApprox(t, est.NetSavingsMonthly, est.NATCostMonthly-est.EndpointFixedMonthly-est.EndpointDataMonthly, 0.001, "net savings")
+
+	rec := est.Recommendation("us-east-1", "vpc-123")
+	if rec.Type != "eks-endpoint-bundle" || rec.Priority != "high" || len(rec.Commands) != 3 {
+		t.Fatalf("unexpected recommendation: %+v", rec)
+	}
+	if !strings.Contains(rec.Commands[2], "com.amazonaws.us-east-1.sts") {
+		t.Errorf("expected an STS endpoint command, got %s", rec.Commands[2])
+	}
+}
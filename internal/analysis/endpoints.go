@@ -38,6 +38,14 @@ type EndpointAnalysis struct {
 	RouteTables        []types.RouteTable
 	MissingEndpoints   []string
 	MissingRoutes      []MissingRoute
+	// Placement is where new interface endpoints go; commands use
+	// placeholders without it.
+	Placement *EndpointPlacement
+	// Conflicts are endpoints that are pending or broken, which new ones
+	// would clash with; they don't count as existing.
+	Conflicts []EndpointConflict
+	// Quotas are the endpoint quotas, when they were checked.
+	Quotas []EndpointQuota
 }
 
 // InterfaceEndpointCost represents the cost of an interface endpoint
@@ -93,6 +101,12 @@ func AnalyzeEndpoints(region string, vpcID string, endpoints []types.VPCEndpoint
 	// Find existing endpoints
 	for i := range endpoints {
 		ep := &endpoints[i]
+		if usable, conflict := endpointUsable(*ep); !usable {
+			if conflict {
+				analysis.Conflicts = append(analysis.Conflicts, EndpointConflict{ServiceName: ep.ServiceName, EndpointID: ep.ID, Type: ep.Type, State: ep.State})
+			}
+			continue
+		}
 		if strings.Contains(ep.ServiceName, ".s3") && ep.Type == "Gateway" {
 			analysis.S3Endpoint = ep
 		}
@@ -233,24 +247,10 @@ func (a *EndpointAnalysis) String() string {
 
 // GetCreateEndpointCommands returns AWS CLI commands to create missing endpoints
 func (a *EndpointAnalysis) GetCreateEndpointCommands() []string {
-	var commands []string
-
-	// Get all route table IDs that route to NAT
-	rtIDs := a.getNATRouteTableIDs()
-	var quotedRTIDs []string
-	for _, id := range rtIDs {
-		quotedRTIDs = append(quotedRTIDs, shellQuote(id))
-	}
-	rtIDsStr := strings.Join(quotedRTIDs, " ")
-
-	for _, svc := range a.MissingEndpoints {
-		cmd := fmt.Sprintf("aws ec2 create-vpc-endpoint \\\n  --vpc-id %s \\\n  --service-name %s \\\n  --route-table-ids %s",
-			shellQuote(a.VPCID), shellQuote(svc), rtIDsStr)
-		commands = append(commands, cmd)
-	}
+	commands := a.GetCreateGatewayEndpointCommands()
 
 	// Add ECR Interface endpoint commands (paid endpoints) if missing.
-	subnets := a.getNATSubnetIDs()
+	subnets := a.NATSubnetIDs()
 	if len(subnets) == 0 {
 		subnets = []string{"<private-subnet-id>"}
 	}
@@ -260,17 +260,27 @@ func (a *EndpointAnalysis) GetCreateEndpointCommands() []string {
 	}
 	subnetIDsStr := strings.Join(quotedSubnets, " ")
 
-	for _, svc := range a.MissingECRInterfaceServiceNames() {
-		cmd := fmt.Sprintf(
-			"aws ec2 create-vpc-endpoint \\\n  --vpc-id %s \\\n  --service-name %s \\\n  --vpc-endpoint-type Interface \\\n  --subnet-ids %s \\\n  --security-group-ids %s \\\n  --private-dns-enabled",
-			shellQuote(a.VPCID),
-			shellQuote(svc),
-			subnetIDsStr,
-			shellQuote("<security-group-id>"),
-		)
-		commands = append(commands, cmd)
+	return append(commands, interfaceEndpointCommands(a, a.VPCID, a.MissingECRInterfaceServiceNames(), subnetIDsStr)...)
+}
+
+// GetCreateGatewayEndpointCommands returns AWS CLI commands to create the
+// missing S3 and DynamoDB gateway endpoints, associated with every route
+// table that routes to a NAT Gateway.
+func (a *EndpointAnalysis) GetCreateGatewayEndpointCommands() []string {
+	// Get all route table IDs that route to NAT
+	rtIDs := a.getNATRouteTableIDs()
+	var quotedRTIDs []string
+	for _, id := range rtIDs {
+		quotedRTIDs = append(quotedRTIDs, shellQuote(id))
 	}
+	rtIDsStr := strings.Join(quotedRTIDs, " ")
 
+	commands, create := a.precheckCommands("Gateway", a.MissingEndpoints)
+	for _, svc := range create {
+		cmd := fmt.Sprintf("aws ec2 create-vpc-endpoint \\\n  --vpc-id %s \\\n  --service-name %s \\\n  --route-table-ids %s",
+			shellQuote(a.VPCID), shellQuote(svc), rtIDsStr)
+		commands = append(commands, cmd)
+	}
 	return commands
 }
 
@@ -376,12 +386,7 @@ func (a *EndpointAnalysis) EstimateECRInterfaceEndpointMonthlyCost(monthlyECRDat
 		return 0, 0, 0, 0, 0
 	}
 
-	subnets := a.getNATSubnetIDs()
-	azCount = len(subnets)
-	if azCount == 0 {
-		azCount = 1
-	}
-
+	azCount = a.endpointAZCount()
 	hourlyPerAZ, dataPerGB := a.GetECRInterfaceEndpointPricing()
 	fixedMonthly = hourlyPerAZ * float64(azCount) * float64(endpointCount) * 24 * 30
 	dataMonthly = monthlyECRDataGB * dataPerGB
@@ -402,7 +407,8 @@ func (a *EndpointAnalysis) getNATRouteTableIDs() []string {
 	return rtIDs
 }
 
-func (a *EndpointAnalysis) getNATSubnetIDs() []string {
+// NATSubnetIDs returns the subnets whose default route is a NAT Gateway.
+func (a *EndpointAnalysis) NATSubnetIDs() []string {
 	seen := make(map[string]bool)
 	var subnets []string
 	for _, rt := range a.RouteTables {
@@ -427,6 +433,12 @@ func (a *EndpointAnalysis) getNATSubnetIDs() []string {
 	return subnets
 }
 
+// vpcDNSDiscoverer is implemented by scanners that can read VPC DNS
+// configuration, enabling the endpoint DNS checks.
+type vpcDNSDiscoverer interface {
+	DiscoverVPCDNS(ctx context.Context, vpcID string) (*types.VPCDNS, error)
+}
+
 // AnalyzeAllVPCEndpoints runs quick scan analysis on all VPCs with NAT Gateways
 // Returns findings for all VPCs
 func AnalyzeAllVPCEndpoints(ctx context.Context, scanner interface {
@@ -441,6 +453,11 @@ func AnalyzeAllVPCEndpoints(ctx context.Context, scanner interface {
 		vpcNATs[nat.VPCID] = append(vpcNATs[nat.VPCID], nat)
 	}
 
+	region := placeholderRegion
+	if r, ok := scanner.(regionReporter); ok {
+		region = r.GetRegion()
+	}
+
 	// Check each VPC for missing endpoints
 	for vpcID := range vpcNATs {
 		endpoints, err := scanner.DiscoverVPCEndpoints(ctx, vpcID)
@@ -453,13 +470,19 @@ func AnalyzeAllVPCEndpoints(ctx context.Context, scanner interface {
 			continue
 		}
 
+		if d, ok := scanner.(vpcDNSDiscoverer); ok {
+			if dns, err := d.DiscoverVPCDNS(ctx, vpcID); err == nil {
+				findings = append(findings, AnalyzeEndpointDNS(endpoints, dns)...)
+			}
+		}
+
 		// Check for S3 gateway endpoint
 		hasS3Gateway := false
-		s3EndpointRTs := []string{}
+		s3EndpointID, s3EndpointRTs := "", []string{}
 		for _, ep := range endpoints {
 			if strings.Contains(ep.ServiceName, ".s3") && ep.Type == "Gateway" {
 				hasS3Gateway = true
-				s3EndpointRTs = ep.RouteTables
+				s3EndpointID, s3EndpointRTs = ep.ID, ep.RouteTables
 				break
 			}
 		}
@@ -474,6 +497,9 @@ func AnalyzeAllVPCEndpoints(ctx context.Context, scanner interface {
 				Service:     "S3",
 				Action:      "Create S3 Gateway VPC endpoint and associate with private route tables",
 				Impact:      "All S3 traffic is going through NAT Gateway, incurring $0.045/GB data processing charges",
+				Confidence:  0.9,
+				Effort:      EffortLow,
+				Remediation: []types.RemediationAction{createGatewayEndpointAction(region, vpcID, "s3", getRouteTablesWithNAT(routeTables))},
 			})
 		} else {
 			// Check route table associations
@@ -489,17 +515,20 @@ func AnalyzeAllVPCEndpoints(ctx context.Context, scanner interface {
 					Service:     "S3",
 					Action:      fmt.Sprintf("Associate S3 endpoint with: %s", strings.Join(missingAssociations, ", ")),
 					Impact:      "S3 traffic from some subnets still goes through NAT Gateway",
+					Confidence:  0.9,
+					Effort:      EffortLow,
+					Remediation: []types.RemediationAction{addEndpointRoutesAction(s3EndpointID, missingAssociations)},
 				})
 			}
 		}
 
 		// Check for DynamoDB gateway endpoint
 		hasDDBGateway := false
-		ddbEndpointRTs := []string{}
+		ddbEndpointID, ddbEndpointRTs := "", []string{}
 		for _, ep := range endpoints {
 			if strings.Contains(ep.ServiceName, ".dynamodb") && ep.Type == "Gateway" {
 				hasDDBGateway = true
-				ddbEndpointRTs = ep.RouteTables
+				ddbEndpointID, ddbEndpointRTs = ep.ID, ep.RouteTables
 				break
 			}
 		}
@@ -514,6 +543,9 @@ func AnalyzeAllVPCEndpoints(ctx context.Context, scanner interface {
 				Service:     "DynamoDB",
 				Action:      "Create DynamoDB Gateway VPC endpoint and associate with private route tables",
 				Impact:      "All DynamoDB traffic is going through NAT Gateway, incurring $0.045/GB data processing charges",
+				Confidence:  0.9,
+				Effort:      EffortLow,
+				Remediation: []types.RemediationAction{createGatewayEndpointAction(region, vpcID, "dynamodb", getRouteTablesWithNAT(routeTables))},
 			})
 		} else {
 			natRouteTables := getRouteTablesWithNAT(routeTables)
@@ -528,6 +560,9 @@ func AnalyzeAllVPCEndpoints(ctx context.Context, scanner interface {
 					Service:     "DynamoDB",
 					Action:      fmt.Sprintf("Associate DynamoDB endpoint with: %s", strings.Join(missingAssociations, ", ")),
 					Impact:      "DynamoDB traffic from some subnets still goes through NAT Gateway",
+					Confidence:  0.9,
+					Effort:      EffortLow,
+					Remediation: []types.RemediationAction{addEndpointRoutesAction(ddbEndpointID, missingAssociations)},
 				})
 			}
 		}
@@ -0,0 +1,41 @@
+package analysis
+
+import "github.com/doitintl/terminator/internal/units"
+
+// EndpointENITraffic is what one interface endpoint network interface carried
+// during an --eni-id scan, projected to a month: the endpoint's data
+// processing charge, and what the same traffic would cost through a NAT
+// Gateway.
+type EndpointENITraffic struct {
+	ENIID               string  `json:"eni_id"`
+	EndpointID          string  `json:"endpoint_id"`
+	MonthlyGB           float64 `json:"monthly_gb"`
+	EndpointCostMonthly float64 `json:"endpoint_cost_monthly"`
+	NATCostMonthly      float64 `json:"nat_cost_monthly"`
+}
+
+// ProjectEndpointENI projects the traffic sampled on an interface endpoint's
+// network interface to a month.
+func ProjectEndpointENI(region, eniID, endpointID string, stats *TrafficStats, collectionMinutes int) *EndpointENITraffic {
+	if stats == nil || collectionMinutes <= 0 {
+		return nil
+	}
+	price, ok := interfaceEndpointPricing[region]
+	if !ok {
+		price = interfaceEndpointPricing["default"]
+	}
+	monthlyGB := units.BillingGB(stats.TotalBytes) * 43200.0 / float64(collectionMinutes)
+	return &EndpointENITraffic{
+		ENIID:               eniID,
+		EndpointID:          endpointID,
+		MonthlyGB:           monthlyGB,
+		EndpointCostMonthly: monthlyGB * price.dataPerGB,
+		NATCostMonthly:      monthlyGB * NATGatewayPricePerGB(region),
+	}
+}
+
+// AvoidedMonthly is the NAT Gateway processing the endpoint saves, net of its
+// own data processing charge.
+func (t *EndpointENITraffic) AvoidedMonthly() float64 {
+	return t.NATCostMonthly - t.EndpointCostMonthly
+}
@@ -0,0 +1,31 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProjectEndpointENI(t *testing.T) {
+	// 1 GB in 10 minutes is 4,320 GB a month
+	stats := &TrafficStats{TotalBytes: 1024 * 1024 * 1024}
+	got := ProjectEndpointENI("us-east-1", "eni-1", "vpce-1", stats, 10)
+	if got == nil {
+		t.Fatal("expected a projection")
+	}
+	if math.Abs(got.MonthlyGB-4320) > 0.01 {
+		t.Errorf("MonthlyGB = %.2f, want 4320", got.MonthlyGB)
+	}
+	if math.Abs(got.EndpointCostMonthly-43.2) > 0.01 {
+		t.Errorf("EndpointCostMonthly = %.2f, want 43.20", got.EndpointCostMonthly)
+	}
+	if want := 4320 * NATGatewayPricePerGB("us-east-1"); math.Abs(got.NATCostMonthly-want) > 0.01 {
+		t.Errorf("NATCostMonthly = %.2f, want %.2f", got.NATCostMonthly, want)
+	}
+	if got.AvoidedMonthly() <= 0 {
+		t.Errorf("expected the endpoint to avoid NAT cost, got %.2f", got.AvoidedMonthly())
+	}
+
+	if ProjectEndpointENI("us-east-1", "eni-1", "vpce-1", nil, 10) != nil {
+		t.Error("expected no projection without stats")
+	}
+}
@@ -0,0 +1,89 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// AttachFindingSavings sets the projected savings of S3 and DynamoDB gateway
+// endpoint findings from est, so they can be filtered by --min-savings. est
+// covers the traffic of every scanned VPC, so each VPC's finding gets the
+// share of it that VPC's NAT Gateways carried, from byVPC. Without traffic
+// by VPC, the whole projection goes to the first finding, labelled
+// account-wide, so it is never counted twice.
+func AttachFindingSavings(findings []types.Finding, est *CostEstimate, byVPC map[string]*TrafficStats) {
+	if est == nil {
+		return
+	}
+	attachServiceSavings(findings, "S3", ServiceS3, est.S3SavingsMonthly, byVPC)
+	attachServiceSavings(findings, "DynamoDB", ServiceDynamoDB, est.DynamoSavingsMonthly, byVPC)
+}
+
+func attachServiceSavings(findings []types.Finding, name string, svc Service, monthly float64, byVPC map[string]*TrafficStats) {
+	var idx []int
+	for i := range findings {
+		if findings[i].Service == name && !findings[i].SavingsEstimated {
+			idx = append(idx, i)
+		}
+	}
+	if len(idx) == 0 {
+		return
+	}
+
+	var total int64
+	for _, stats := range byVPC {
+		if stats != nil {
+			total += stats.Bytes(svc)
+		}
+	}
+	if total == 0 {
+		f := &findings[idx[0]]
+		f.MonthlySavings, f.SavingsEstimated = monthly, true
+		if len(idx) > 1 {
+			f.Impact = strings.TrimSpace(f.Impact + fmt.Sprintf(" Savings are account-wide: the %s traffic of every scanned VPC.", name))
+		}
+		return
+	}
+	for _, i := range idx {
+		var bytes int64
+		if stats := byVPC[findings[i].VPCID]; stats != nil {
+			bytes = stats.Bytes(svc)
+		}
+		findings[i].MonthlySavings = monthly * float64(bytes) / float64(total)
+		findings[i].SavingsEstimated = true
+	}
+}
+
+// FilterRecommendations drops recommendations projected to save less than
+// minMonthly. Recommendations without a projection are kept.
+func FilterRecommendations(recs []Recommendation, minMonthly float64) (kept []Recommendation, dropped int) {
+	if minMonthly <= 0 {
+		return recs, 0
+	}
+	for _, rec := range recs {
+		if rec.SavingsEstimated && rec.MonthlySavings < minMonthly {
+			dropped++
+			continue
+		}
+		kept = append(kept, rec)
+	}
+	return kept, dropped
+}
+
+// FilterFindings drops findings projected to save less than minMonthly.
+// Findings without a projection are kept.
+func FilterFindings(findings []types.Finding, minMonthly float64) (kept []types.Finding, dropped int) {
+	if minMonthly <= 0 {
+		return findings, 0
+	}
+	for _, f := range findings {
+		if f.SavingsEstimated && f.MonthlySavings < minMonthly {
+			dropped++
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept, dropped
+}
@@ -0,0 +1,77 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestFilterRecommendations(t *testing.T) {
+	recs := []Recommendation{
+		{Title: "big", MonthlySavings: 40, SavingsEstimated: true},
+		{Title: "small", MonthlySavings: 2, SavingsEstimated: true},
+		{Title: "unpriced"},
+	}
+
+	kept, dropped := FilterRecommendations(recs, 5)
+	if dropped != 1 || len(kept) != 2 || kept[0].Title != "big" || kept[1].Title != "unpriced" {
+		t.Fatalf("unexpected filter result: kept=%+v dropped=%d", kept, dropped)
+	}
+	if kept, dropped := FilterRecommendations(recs, 0); dropped != 0 || len(kept) != 3 {
+		t.Fatalf("a zero threshold should keep everything, kept=%d dropped=%d", len(kept), dropped)
+	}
+}
+
+func TestFilterFindingsUsesCostEstimate(t *testing.T) {
+	findings := []types.Finding{
+		{Service: "S3", Title: "missing S3 endpoint"},
+		{Service: "DynamoDB", Title: "missing DynamoDB endpoint"},
+		{Service: "ECR", Title: "unpriced"},
+	}
+	AttachFindingSavings(findings, &CostEstimate{S3SavingsMonthly: 120, DynamoSavingsMonthly: 1.5}, nil)
+
+	kept, dropped := FilterFindings(findings, 5)
+	if dropped != 1 || len(kept) != 2 || kept[0].Service != "S3" || kept[1].Service != "ECR" {
+		t.Fatalf("unexpected filter result: kept=%+v dropped=%d", kept, dropped)
+	}
+}
+
+func TestAttachFindingSavingsSplitsByVPC(t *testing.T) {
+	findings := []types.Finding{
+		{Service: "S3", VPCID: "vpc-a"},
+		{Service: "S3", VPCID: "vpc-b"},
+		{Service: "S3", VPCID: "vpc-c"},
+	}
+	byVPC := map[string]*TrafficStats{
+		"vpc-a": {Services: map[Service]ServiceStats{ServiceS3: {Bytes: 300}}},
+		"vpc-b": {Services: map[Service]ServiceStats{ServiceS3: {Bytes: 100}}},
+	}
+	AttachFindingSavings(findings, &CostEstimate{S3SavingsMonthly: 80}, byVPC)
+
+	var sum float64
+	for i, want := range []float64{60, 20, 0} {
+		if !findings[i].SavingsEstimated || findings[i].MonthlySavings != want {
+			t.Errorf("%s: savings = %v (estimated %v), want %v", findings[i].VPCID, findings[i].MonthlySavings, findings[i].SavingsEstimated, want)
+		}
+		sum += findings[i].MonthlySavings
+	}
+	if sum != 80 {
+		t.Errorf("findings add up to $%.2f, want the estimate's $80", sum)
+	}
+}
+
+func TestAttachFindingSavingsAccountWideOnce(t *testing.T) {
+	findings := []types.Finding{
+		{Service: "S3", VPCID: "vpc-a"},
+		{Service: "S3", VPCID: "vpc-b"},
+	}
+	AttachFindingSavings(findings, &CostEstimate{S3SavingsMonthly: 80}, nil)
+
+	if findings[0].MonthlySavings != 80 || !strings.Contains(findings[0].Impact, "account-wide") {
+		t.Errorf("first finding = %+v, want the whole $80 labelled account-wide", findings[0])
+	}
+	if findings[1].MonthlySavings != 0 {
+		t.Errorf("second finding counts the savings again: %+v", findings[1])
+	}
+}
@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// headlineActions is how many actions the headline lists.
+const headlineActions = 3
+
+// Headline is the one-screen summary printed above the full report.
+type Headline struct {
+	MonthlyNATCost   float64
+	MonthlySavings   float64
+	Actions          []HeadlineAction
+	Confidence       string // "high", "medium" or "low"
+	ConfidenceReason string
+}
+
+// HeadlineAction is one of the top-scored findings or recommendations.
+type HeadlineAction struct {
+	Title            string  `json:"title"`
+	MonthlySavings   float64 `json:"monthly_savings"`
+	SavingsEstimated bool    `json:"savings_estimated"`
+	Score            float64 `json:"score"`
+}
+
+// BuildHeadline picks the top-scored actions across findings and
+// recommendations (both already ranked) and grades how far the projection can
+// be trusted from the sample length and how much traffic matched only broad
+// EC2 ranges.
+func BuildHeadline(est *CostEstimate, stats *TrafficStats, findings []types.Finding, recs []Recommendation, minutes int) Headline {
+	var h Headline
+	if est != nil {
+		h.MonthlyNATCost = est.CurrentMonthlyCost
+		h.MonthlySavings = est.TotalSavingsMonthly
+	}
+
+	var actions []HeadlineAction
+	for _, f := range findings {
+		actions = append(actions, HeadlineAction{Title: f.Title, MonthlySavings: f.MonthlySavings, SavingsEstimated: f.SavingsEstimated, Score: f.Score})
+	}
+	for _, rec := range recs {
+		actions = append(actions, HeadlineAction{Title: rec.Title, MonthlySavings: rec.MonthlySavings, SavingsEstimated: rec.SavingsEstimated, Score: rec.Score})
+	}
+	sort.SliceStable(actions, func(i, j int) bool { return actions[i].Score > actions[j].Score })
+	if len(actions) > headlineActions {
+		actions = actions[:headlineActions]
+	}
+	h.Actions = actions
+
+	h.Confidence, h.ConfidenceReason = headlineConfidence(stats, minutes)
+	return h
+}
+
+func headlineConfidence(stats *TrafficStats, minutes int) (string, string) {
+	if stats == nil || stats.TotalRecords == 0 {
+		return "low", "no traffic was sampled"
+	}
+	_, broad, _ := stats.AccuracyPercentages()
+	reason := fmt.Sprintf("%d-minute sample, %.0f%% of bytes matched only broad EC2 ranges", minutes, broad)
+	switch {
+	case minutes < 15 || broad >= 50:
+		return "low", reason
+	case minutes >= 60 && broad < 25:
+		return "high", reason
+	default:
+		return "medium", reason
+	}
+}
@@ -0,0 +1,42 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestBuildHeadlinePicksTopThreeAcrossSources(t *testing.T) {
+	findings := []types.Finding{
+		{Title: "S3 gateway", MonthlySavings: 90, SavingsEstimated: true, Score: 81},
+		{Title: "DynamoDB gateway", MonthlySavings: 4, SavingsEstimated: true, Score: 3.6},
+	}
+	recs := []Recommendation{
+		{Title: "EKS bundle", MonthlySavings: 50, SavingsEstimated: true, Score: 15},
+		{Title: "regional NAT"},
+	}
+	stats := &TrafficStats{TotalRecords: 10, TotalBytes: 100, Services: map[Service]ServiceStats{ServiceS3: {Bytes: 100}}}
+
+	h := BuildHeadline(&CostEstimate{CurrentMonthlyCost: 300, TotalSavingsMonthly: 94}, stats, findings, recs, 60)
+	if len(h.Actions) != 3 || h.Actions[0].Title != "S3 gateway" || h.Actions[1].Title != "EKS bundle" || h.Actions[2].Title != "DynamoDB gateway" {
+		t.Fatalf("unexpected actions: %+v", h.Actions)
+	}
+	assertApprox(t, h.MonthlyNATCost, 300, 0.001, "NAT spend")
+	assertApprox(t, h.MonthlySavings, 94, 0.001, "savings")
+	if h.Confidence != "high" {
+		t.Errorf("an hour of exactly classified traffic should be high confidence, got %s (%s)", h.Confidence, h.ConfidenceReason)
+	}
+}
+
+func TestHeadlineConfidence(t *testing.T) {
+	if c, _ := headlineConfidence(nil, 60); c != "low" {
+		t.Errorf("no sample should be low confidence, got %s", c)
+	}
+	stats := &TrafficStats{TotalRecords: 10, TotalBytes: 100, Services: map[Service]ServiceStats{ServiceS3: {Bytes: 100}}}
+	if c, _ := headlineConfidence(stats, 5); c != "low" {
+		t.Errorf("a 5-minute sample should be low confidence, got %s", c)
+	}
+	if c, _ := headlineConfidence(stats, 30); c != "medium" {
+		t.Errorf("a 30-minute sample should be medium confidence, got %s", c)
+	}
+}
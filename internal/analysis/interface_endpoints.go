@@ -0,0 +1,185 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/units"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// InterfaceEndpointCase weighs a group of interface endpoints against the NAT
+// data processing they would avoid, so paid endpoints are only recommended
+// where the sampled traffic pays for them.
+type InterfaceEndpointCase struct {
+	Name                 string   `json:"name"`
+	Services             []string `json:"services"`
+	MissingEndpoints     []string `json:"missing_endpoints"`
+	MonthlyGB            float64  `json:"monthly_gb"`
+	NATCostMonthly       float64  `json:"nat_cost_monthly"`
+	EndpointFixedMonthly float64  `json:"endpoint_fixed_monthly"`
+	EndpointDataMonthly  float64  `json:"endpoint_data_monthly"`
+	NetSavingsMonthly    float64  `json:"net_savings_monthly"`
+	// BreakEvenGB is the monthly traffic above which the missing endpoints pay for themselves.
+	BreakEvenGB float64 `json:"break_even_gb"`
+
+	endpoints *EndpointAnalysis
+}
+
+// EvaluateInterfaceEndpoints projects the sampled traffic to services (keys of
+// TrafficStats.ServiceBytes) to a month and prices the endpoints endpoints
+// reports missing, one per NAT-routed AZ.
+func EvaluateInterfaceEndpoints(name, region string, services []string, stats *TrafficStats, collectionMinutes int, endpoints *EndpointAnalysis) *InterfaceEndpointCase {
+	if stats == nil || collectionMinutes <= 0 {
+		return nil
+	}
+	if endpoints == nil {
+		endpoints = &EndpointAnalysis{Region: region}
+	}
+
+	c := &InterfaceEndpointCase{Name: name, Services: services, endpoints: endpoints}
+	var bytes int64
+	for _, svc := range services {
+		bytes += stats.ServiceBytes[svc]
+		if !endpoints.hasInterfaceEndpoint(svc) {
+			c.MissingEndpoints = append(c.MissingEndpoints, svc)
+		}
+	}
+	c.MonthlyGB = units.BillingGB(bytes) * 43200.0 / float64(collectionMinutes)
+
+	pricePerGB := NATGatewayPricePerGB(region)
+	hourlyPerAZ, dataPerGB := endpoints.GetECRInterfaceEndpointPricing()
+	azCount := endpoints.endpointAZCount()
+
+	c.NATCostMonthly = c.MonthlyGB * pricePerGB
+	c.EndpointFixedMonthly = hourlyPerAZ * float64(azCount) * float64(len(c.MissingEndpoints)) * 24 * 30
+	c.EndpointDataMonthly = c.MonthlyGB * dataPerGB
+	c.NetSavingsMonthly = c.NATCostMonthly - c.EndpointFixedMonthly - c.EndpointDataMonthly
+	if pricePerGB > dataPerGB {
+		c.BreakEvenGB = c.EndpointFixedMonthly / (pricePerGB - dataPerGB)
+	}
+	return c
+}
+
+// Worthwhile reports whether adding the missing endpoints saves money.
+func (c *InterfaceEndpointCase) Worthwhile() bool {
+	return len(c.MissingEndpoints) > 0 && c.NetSavingsMonthly > 0
+}
+
+// Verdict is a one-line, number-backed statement of whether the endpoints pay off.
+func (c *InterfaceEndpointCase) Verdict() string {
+	if len(c.MissingEndpoints) == 0 {
+		return fmt.Sprintf("%s endpoints already exist", c.Name)
+	}
+	if c.Worthwhile() {
+		return fmt.Sprintf("%s endpoints pay off: %.1f GB/month is above the %.1f GB/month break-even (~$%.2f/month net)",
+			c.Name, c.MonthlyGB, c.BreakEvenGB, c.NetSavingsMonthly)
+	}
+	return fmt.Sprintf("%s endpoints are not worth it for cost: %.1f GB/month is below the %.1f GB/month break-even (they would add ~$%.2f/month)",
+		c.Name, c.MonthlyGB, c.BreakEvenGB, -c.NetSavingsMonthly)
+}
+
+// createCommands returns create-vpc-endpoint commands for the missing
+// endpoints, after any commands creating their security group.
+func (c *InterfaceEndpointCase) createCommands(region, vpcID string) []string {
+	var names []string
+	for _, svc := range c.MissingEndpoints {
+		names = append(names, fmt.Sprintf("com.amazonaws.%s.%s", region, svc))
+	}
+	return interfaceEndpointCommands(c.endpoints, vpcID, names, shellQuote("<private-subnet-ids>"))
+}
+
+// ssmServices are the endpoints SSM Agent and Session Manager need.
+var ssmServices = []string{"ssm", "ec2messages", "ssmmessages"}
+
+// EvaluateSSMEndpoints weighs the ssm, ec2messages and ssmmessages endpoints
+// for a VPC with managedInstances online SSM-managed instances. It returns nil
+// when the VPC has no managed instances and no SSM traffic was recognized.
+func EvaluateSSMEndpoints(region string, managedInstances int, stats *TrafficStats, collectionMinutes int, endpoints *EndpointAnalysis) *InterfaceEndpointCase {
+	c := EvaluateInterfaceEndpoints("SSM / Session Manager", region, ssmServices, stats, collectionMinutes, endpoints)
+	if c == nil || (managedInstances == 0 && c.MonthlyGB == 0) {
+		return nil
+	}
+	return c
+}
+
+// SSMRecommendation recommends the SSM endpoint trio with its break-even math.
+func (c *InterfaceEndpointCase) SSMRecommendation(region, vpcID string, managedInstances int) Recommendation {
+	priority := "low"
+	if c.Worthwhile() {
+		priority = "medium"
+	}
+	return Recommendation{
+		Type:     "ssm-endpoints",
+		Priority: priority,
+		Title:    fmt.Sprintf("SSM endpoints for %d managed instance(s) in %s", managedInstances, vpcID),
+		Description: fmt.Sprintf("SSM Agent and Session Manager traffic (%s) goes through the NAT Gateway. %s.",
+			strings.Join(c.Services, ", "), c.Verdict()),
+		Benefits: []string{
+			"Session Manager and Run Command keep working without NAT or internet access",
+			"Agent polling (ec2messages) and session streams (ssmmessages) stay inside the VPC",
+		},
+		Commands:         c.createCommands(region, vpcID),
+		Savings:          fmt.Sprintf("~$%.2f/month net (break-even at %.1f GB/month)", c.NetSavingsMonthly, c.BreakEvenGB),
+		MonthlySavings:   c.NetSavingsMonthly,
+		SavingsEstimated: true,
+		Confidence:       0.6,
+		Effort:           EffortMedium,
+	}
+}
+
+// trafficDrivenEndpoints are single interface endpoints recommended only when
+// the sampled traffic to them clears the break-even, never as blanket advice.
+var trafficDrivenEndpoints = []struct {
+	name    string
+	service string
+}{
+	{"Secrets Manager", "secretsmanager"},
+	{"KMS", "kms"},
+}
+
+// EvaluateTrafficDrivenEndpoints weighs the Secrets Manager and KMS endpoints
+// for every one of them the sample saw traffic to.
+func EvaluateTrafficDrivenEndpoints(region string, stats *TrafficStats, collectionMinutes int, endpoints *EndpointAnalysis) []*InterfaceEndpointCase {
+	if stats == nil {
+		return nil
+	}
+	var cases []*InterfaceEndpointCase
+	for _, ep := range trafficDrivenEndpoints {
+		if stats.ServiceBytes[ep.service] == 0 {
+			continue
+		}
+		if c := EvaluateInterfaceEndpoints(ep.name, region, []string{ep.service}, stats, collectionMinutes, endpoints); c != nil {
+			cases = append(cases, c)
+		}
+	}
+	return cases
+}
+
+// Finding reports a missing endpoint that pays for itself. ok is false when
+// the endpoint exists or would cost more than it saves.
+func (c *InterfaceEndpointCase) Finding(region, vpcID string) (finding types.Finding, ok bool) {
+	if !c.Worthwhile() {
+		return types.Finding{}, false
+	}
+	action := strings.Join(c.createCommands(region, vpcID), "\n")
+	var names []string
+	for _, svc := range c.MissingEndpoints {
+		names = append(names, fmt.Sprintf("com.amazonaws.%s.%s", region, svc))
+	}
+	return types.Finding{
+		Type:             "missing-endpoint",
+		Severity:         "medium",
+		Title:            fmt.Sprintf("%s interface endpoint would save ~$%.2f/month", c.Name, c.NetSavingsMonthly),
+		Description:      c.Verdict(),
+		VPCID:            vpcID,
+		Service:          c.Name,
+		Action:           action,
+		Impact:           fmt.Sprintf("~$%.2f/month", c.NetSavingsMonthly),
+		MonthlySavings:   c.NetSavingsMonthly,
+		SavingsEstimated: true,
+		Confidence:       0.6,
+		Effort:           EffortMedium,
+		Remediation:      createInterfaceEndpointActions(c.endpoints, vpcID, names),
+	}, true
+}
@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestEvaluateSSMEndpoints(t *testing.T) {
+	gb := int64(1024 * 1024 * 1024)
+	endpoints := &EndpointAnalysis{Region: "us-east-1"}
+
+	if EvaluateSSMEndpoints("us-east-1", 0, &TrafficStats{}, 60, endpoints) != nil {
+		t.Fatal("expected nil without managed instances or SSM traffic")
+	}
+
+	// 1 GB in an hour is 720 GB/month, above the 3 * $7.20 / $0.035 ≈ 617 GB break-even
+	busy := EvaluateSSMEndpoints("us-east-1", 4, &TrafficStats{ServiceBytes: map[string]int64{"ssmmessages": gb}}, 60, endpoints)
+	assertApprox(t, busy.EndpointFixedMonthly, 21.6, 0.001, "fixed cost of three endpoints")
+	assertApprox(t, busy.BreakEvenGB, 21.6/0.035, 0.001, "break-even GB")
+	if !busy.Worthwhile() || !strings.Contains(busy.Verdict(), "pay off") {
+		t.Errorf("expected worthwhile verdict, got %q", busy.Verdict())
+	}
+
+	quiet := EvaluateSSMEndpoints("us-east-1", 4, &TrafficStats{ServiceBytes: map[string]int64{"ssm": gb / 100}}, 60, endpoints)
+	if quiet.Worthwhile() || !strings.Contains(quiet.Verdict(), "not worth it") {
+		t.Errorf("expected not-worth-it verdict, got %q", quiet.Verdict())
+	}
+	rec := quiet.SSMRecommendation("us-east-1", "vpc-1", 4)
+	if rec.Priority != "low" || len(rec.Commands) != 3 || !strings.Contains(rec.Commands[2], "com.amazonaws.us-east-1.ssmmessages") {
+		t.Errorf("unexpected recommendation: %+v", rec)
+	}
+}
+
+func TestEvaluateInterfaceEndpointsExisting(t *testing.T) {
+	endpoints := &EndpointAnalysis{
+		Region:             "us-east-1",
+		InterfaceEndpoints: []types.VPCEndpoint{{ServiceName: "com.amazonaws.us-east-1.kms"}},
+	}
+	c := EvaluateInterfaceEndpoints("KMS", "us-east-1", []string{"kms"}, &TrafficStats{}, 60, endpoints)
+	if len(c.MissingEndpoints) != 0 || c.EndpointFixedMonthly != 0 || c.Worthwhile() {
+		t.Errorf("existing endpoint should add no cost: %+v", c)
+	}
+	if !strings.Contains(c.Verdict(), "already exist") {
+		t.Errorf("unexpected verdict %q", c.Verdict())
+	}
+}
+
+func TestEvaluateTrafficDrivenEndpoints(t *testing.T) {
+	gb := int64(1024 * 1024 * 1024)
+	stats := &TrafficStats{ServiceBytes: map[string]int64{
+		"secretsmanager": gb,      // 720 GB/month, well above the ~206 GB break-even
+		"kms":            gb / 50, // ~14 GB/month
+	}}
+	cases := EvaluateTrafficDrivenEndpoints("us-east-1", stats, 60, &EndpointAnalysis{Region: "us-east-1"})
+	if len(cases) != 2 || cases[0].Name != "Secrets Manager" || cases[1].Name != "KMS" {
+		t.Fatalf("unexpected cases: %+v", cases)
+	}
+
+	finding, ok := cases[0].Finding("us-east-1", "vpc-1")
+	if !ok || finding.Service != "Secrets Manager" || !strings.Contains(finding.Action, "com.amazonaws.us-east-1.secretsmanager") {
+		t.Errorf("expected a Secrets Manager finding, got %+v (ok=%v)", finding, ok)
+	}
+	if _, ok := cases[1].Finding("us-east-1", "vpc-1"); ok {
+		t.Error("KMS below break-even should not produce a finding")
+	}
+	if !strings.Contains(cases[1].Verdict(), "not worth it") {
+		t.Errorf("KMS verdict should say the endpoint is not worth it, got %q", cases[1].Verdict())
+	}
+
+	if got := EvaluateTrafficDrivenEndpoints("us-east-1", &TrafficStats{}, 60, nil); len(got) != 0 {
+		t.Errorf("no traffic should yield no cases, got %+v", got)
+	}
+}
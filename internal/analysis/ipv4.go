@@ -0,0 +1,128 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/units"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// PublicIPv4PricePerHour is the charge for each public IPv4 address, in use
+// or idle, including the Elastic IPs of public NAT Gateways.
+const PublicIPv4PricePerHour = 0.005
+
+// dualStackServices are the regional AWS APIs of ServiceBytes whose public
+// endpoints also answer over IPv6, so clients with IPv6 addresses can reach
+// them through an egress-only internet gateway instead of a NAT Gateway.
+var dualStackServices = []string{"ecr.api", "ecr.dkr", "sts", "logs", "monitoring", "eks"}
+
+// PublicIPv4Address is one public IPv4 address found on a scanned resource.
+type PublicIPv4Address struct {
+	Resource    string  `json:"resource"`
+	Address     string  `json:"address"`
+	MonthlyCost float64 `json:"monthly_cost"`
+}
+
+// PublicIPv4Estimate is the public IPv4 charge of the scanned NAT Gateways,
+// and the NAT traffic that could move to IPv6.
+type PublicIPv4Estimate struct {
+	Addresses   []PublicIPv4Address `json:"addresses,omitempty"`
+	MonthlyCost float64             `json:"monthly_cost"`
+	// IPv6CapableGBByService is projected monthly NAT traffic to dual-stack
+	// AWS APIs.
+	IPv6CapableGBByService map[string]float64 `json:"ipv6_capable_gb_by_service,omitempty"`
+	IPv6CapableGB          float64            `json:"ipv6_capable_gb"`
+	// IPv6CapableNATCostMonthly is the NAT data processing of that traffic.
+	IPv6CapableNATCostMonthly float64 `json:"ipv6_capable_nat_cost_monthly"`
+}
+
+// EstimatePublicIPv4 prices the public IPv4 addresses of nats for a month
+// and projects the sampled traffic to dual-stack AWS APIs. It returns nil
+// when the NAT Gateways have no public addresses and none of the traffic
+// could use IPv6.
+func EstimatePublicIPv4(region string, nats []types.NATGateway, stats *TrafficStats, collectionMinutes int) *PublicIPv4Estimate {
+	est := &PublicIPv4Estimate{}
+	for _, nat := range nats {
+		for _, ip := range nat.PublicIPs {
+			est.Addresses = append(est.Addresses, PublicIPv4Address{Resource: nat.ID, Address: ip, MonthlyCost: PublicIPv4PricePerHour * hoursPerMonth})
+			est.MonthlyCost += PublicIPv4PricePerHour * hoursPerMonth
+		}
+	}
+
+	if stats != nil && collectionMinutes > 0 {
+		monthlyMultiplier := 43200.0 / float64(collectionMinutes)
+		for _, svc := range dualStackServices {
+			if bytes := stats.ServiceBytes[svc]; bytes > 0 {
+				if est.IPv6CapableGBByService == nil {
+					est.IPv6CapableGBByService = make(map[string]float64)
+				}
+				gb := units.BillingGB(bytes) * monthlyMultiplier
+				est.IPv6CapableGBByService[svc] = gb
+				est.IPv6CapableGB += gb
+			}
+		}
+		est.IPv6CapableNATCostMonthly = est.IPv6CapableGB * NATGatewayPricePerGB(region)
+	}
+
+	if len(est.Addresses) == 0 && est.IPv6CapableGB == 0 {
+		return nil
+	}
+	return est
+}
+
+// IPv6CapableServices returns the dual-stack services seen in the sample,
+// busiest first.
+func (e *PublicIPv4Estimate) IPv6CapableServices() []string {
+	names := make([]string, 0, len(e.IPv6CapableGBByService))
+	for name := range e.IPv6CapableGBByService {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if e.IPv6CapableGBByService[names[i]] != e.IPv6CapableGBByService[names[j]] {
+			return e.IPv6CapableGBByService[names[i]] > e.IPv6CapableGBByService[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Recommendation suggests moving the traffic to dual-stack APIs onto IPv6
+// through an egress-only internet gateway, with the commands for vpcID. It
+// returns false when the sample saw no such traffic.
+func (e *PublicIPv4Estimate) Recommendation(region, vpcID string) (Recommendation, bool) {
+	if e.IPv6CapableGB == 0 {
+		return Recommendation{}, false
+	}
+	priority := "low"
+	if e.IPv6CapableNATCostMonthly >= 100 {
+		priority = "medium"
+	}
+	return Recommendation{
+		Type:     "ipv6-egress",
+		Priority: priority,
+		Title:    "Switch IPv6-capable traffic to an egress-only internet gateway",
+		Description: fmt.Sprintf("About %s GB/month goes through the NAT Gateway to AWS APIs that also answer over IPv6 (%s). "+
+			"Workloads with IPv6 addresses can reach them through an egress-only internet gateway, which has no hourly, data processing or public IPv4 charge.",
+			units.Number(e.IPv6CapableGB), strings.Join(e.IPv6CapableServices(), ", ")),
+		Benefits: []string{
+			fmt.Sprintf("Removes $%.2f/month of NAT data processing", e.IPv6CapableNATCostMonthly),
+			"IPv6 traffic needs no public IPv4 address ($0.005/hour each)",
+			"Overlaps with interface endpoint recommendations for the same services; count only one of them",
+		},
+		Commands: []string{
+			fmt.Sprintf("aws ec2 associate-vpc-cidr-block --region %s --vpc-id %s --amazon-provided-ipv6-cidr-block", shellQuote(region), shellQuote(vpcID)),
+			fmt.Sprintf("aws ec2 create-egress-only-internet-gateway --region %s --vpc-id %s", shellQuote(region), shellQuote(vpcID)),
+			fmt.Sprintf("aws ec2 create-route --region %s --route-table-id <private-route-table-id> --destination-ipv6-cidr-block ::/0 --egress-only-internet-gateway-id <eigw-id>", shellQuote(region)),
+			"# Assign IPv6 CIDRs to the private subnets, then point SDKs at dual-stack endpoints:",
+			"export AWS_USE_DUALSTACK_ENDPOINT=true",
+		},
+		Savings:          fmt.Sprintf("~$%.2f/month of NAT data processing", e.IPv6CapableNATCostMonthly),
+		MonthlySavings:   e.IPv6CapableNATCostMonthly,
+		SavingsEstimated: true,
+		// Workloads must get IPv6 addresses and dual-stack clients first
+		Confidence: 0.4,
+		Effort:     EffortHigh,
+	}, true
+}
@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestEstimatePublicIPv4(t *testing.T) {
+	const gb = 1 << 30
+	nats := []types.NATGateway{
+		{ID: "nat-1", PublicIPs: []string{"203.0.113.10", "203.0.113.11"}},
+		{ID: "nat-2", ConnectivityType: "private"},
+	}
+	stats := &TrafficStats{ServiceBytes: map[string]int64{"ecr.dkr": 3 * gb, "sts": gb, "ssm": 5 * gb}}
+
+	// A 43,200-minute sample is already a month.
+	est := EstimatePublicIPv4("us-east-1", nats, stats, 43200)
+	if est == nil || len(est.Addresses) != 2 || est.Addresses[1].Resource != "nat-1" {
+		t.Fatalf("unexpected estimate: %+v", est)
+	}
+	assertApprox(t, est.MonthlyCost, 2*0.005*720, 0.0001, "public IPv4 cost")
+	assertApprox(t, est.IPv6CapableGB, 4, 0.0001, "IPv6-capable GB")
+	assertApprox(t, est.IPv6CapableNATCostMonthly, 4*0.045, 0.0001, "IPv6-capable NAT cost")
+	if got := est.IPv6CapableServices(); len(got) != 2 || got[0] != "ecr.dkr" || got[1] != "sts" {
+		t.Errorf("services = %v, want [ecr.dkr sts]", got)
+	}
+
+	rec, ok := est.Recommendation("us-east-1", "vpc-1")
+	if !ok || rec.Type != "ipv6-egress" || rec.MonthlySavings != est.IPv6CapableNATCostMonthly {
+		t.Fatalf("unexpected recommendation: %+v", rec)
+	}
+	if !strings.Contains(strings.Join(rec.Commands, "\n"), "create-egress-only-internet-gateway --region 'us-east-1' --vpc-id 'vpc-1'") {
+		t.Errorf("missing egress-only gateway command: %v", rec.Commands)
+	}
+
+	if est := EstimatePublicIPv4("us-east-1", nats[1:], &TrafficStats{}, 60); est != nil {
+		t.Errorf("expected no estimate without addresses or IPv6-capable traffic, got %+v", est)
+	}
+	if _, ok := EstimatePublicIPv4("us-east-1", nats, nil, 60).Recommendation("us-east-1", "vpc-1"); ok {
+		t.Error("expected no recommendation without IPv6-capable traffic")
+	}
+}
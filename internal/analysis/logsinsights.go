@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// QueryRunner runs a Logs Insights query over a log group and returns its rows.
+type QueryRunner interface {
+	RunQuery(ctx context.Context, logGroupName string, startTime, endTime int64, query string) ([][]cwltypes.ResultField, error)
+}
+
+// LogsInsightsBackend classifies the Flow Logs in a CloudWatch Logs log group
+// with an aggregated Logs Insights query, falling back to raw messages when
+// the aggregated rows cannot be parsed.
+type LogsInsightsBackend struct {
+	runner   QueryRunner
+	logGroup string
+	// newAnalyzer replaces NewTrafficAnalyzer in tests.
+	newAnalyzer func() (*TrafficAnalyzer, error)
+}
+
+// NewLogsInsightsBackend returns a backend querying logGroup through runner.
+func NewLogsInsightsBackend(runner QueryRunner, logGroup string) *LogsInsightsBackend {
+	return &LogsInsightsBackend{runner: runner, logGroup: logGroup}
+}
+
+// Name implements AnalysisBackend.
+func (b *LogsInsightsBackend) Name() string { return "logs-insights" }
+
+// Traffic implements AnalysisBackend.
+func (b *LogsInsightsBackend) Traffic(ctx context.Context, q TrafficQuery) (*TrafficStats, error) {
+	eniFilter := ""
+	switch enis := q.interfaces(); len(enis) {
+	case 0:
+	case 1:
+		eniFilter = fmt.Sprintf(` and f1 = "%s"`, enis[0])
+	default:
+		eniFilter = ` and f1 in ["` + strings.Join(enis, `", "`) + `"]`
+	}
+
+	// Use aggregated query to avoid OOM on large datasets
+	query := `fields @message
+| parse @message "* * * * * * * * * * * * * *" as f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12, f13, f14
+| filter f13 = "ACCEPT"` + eniFilter + `
+| fields coalesce(f5, f3) as resolved_dst, least(f6, f7) as service_port, f8 as protocol, f10 as flow_bytes
+| stats sum(flow_bytes) as total_bytes, count(*) as flow_count by resolved_dst, service_port, protocol
+| sort total_bytes desc`
+	if q.Subnet != nil {
+		// Responses are attributed to their source, so group by both ends.
+		query = `fields @message
+| parse @message "* * * * * * * * * * * * * *" as f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12, f13, f14
+| filter f13 = "ACCEPT"
+| fields coalesce(f4, f2) as resolved_src, coalesce(f5, f3) as resolved_dst, least(f6, f7) as service_port, f8 as protocol, f10 as flow_bytes
+| stats sum(flow_bytes) as total_bytes, count(*) as flow_count by resolved_src, resolved_dst, service_port, protocol
+| sort total_bytes desc`
+	}
+
+	// Throttled calls are retried with backoff and a failed query is re-issued once
+	results, err := b.runner.RunQuery(ctx, b.logGroup, q.Start, q.End, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Diagnostic: check if query returned any results
+	if len(results) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	// Process aggregated results
+	analyzer, err := q.newAnalyzer(b.newAnalyzer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analyzer: %w", err)
+	}
+
+	stats, err := analyzer.AnalyzeAggregatedResults(results)
+	if err != nil {
+		return nil, err
+	}
+	if stats.TotalRecords > 0 {
+		return stats, nil
+	}
+
+	// Fallback path: when aggregated parsing yields zero, parse raw log lines directly.
+	rawStats, err := b.rawMessages(ctx, q, analyzer)
+	if err != nil {
+		return nil, fmt.Errorf("aggregated analysis returned zero records and fallback raw analysis failed: %w", err)
+	}
+	if rawStats.TotalRecords > 0 {
+		return rawStats, nil
+	}
+
+	return stats, nil
+}
+
+func (b *LogsInsightsBackend) rawMessages(ctx context.Context, q TrafficQuery, analyzer *TrafficAnalyzer) (*TrafficStats, error) {
+	rawQuery := `fields @message
+| filter @message not like /NODATA|SKIPDATA/`
+	switch enis := q.interfaces(); len(enis) {
+	case 0:
+	case 1:
+		rawQuery += fmt.Sprintf(`
+| filter @message like "%s"`, enis[0])
+	default:
+		rawQuery += `
+| filter @message like /` + strings.Join(enis, "|") + `/`
+	}
+	rawQuery += `
+| limit 20000`
+
+	results, err := b.runner.RunQuery(ctx, b.logGroup, q.Start, q.End, rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("raw flow logs query: %w", err)
+	}
+
+	logLines := make([]string, 0, len(results))
+	for _, row := range results {
+		for _, field := range row {
+			if field.Field == nil || field.Value == nil {
+				continue
+			}
+			if *field.Field == "@message" && *field.Value != "" {
+				logLines = append(logLines, *field.Value)
+				break
+			}
+		}
+	}
+
+	return analyzer.AnalyzeFlowLogs(logLines)
+}
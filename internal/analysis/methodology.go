@@ -0,0 +1,86 @@
+package analysis
+
+import "fmt"
+
+// Methodology records how a run's numbers were derived: the queries behind
+// the traffic sample, how it was scaled to a month, the prices applied and
+// the IP ranges snapshot traffic was classified with. Exported reports print
+// it as an appendix, so the figures can be audited.
+type Methodology struct {
+	// Queries are the Logs Insights queries run, without repeats.
+	Queries []string `json:"queries,omitempty"`
+	// Projection is how the sample was scaled to a month, and Formula the
+	// arithmetic behind the monthly figures.
+	Projection string  `json:"projection"`
+	Formula    string  `json:"formula"`
+	Prices     []Price `json:"prices"`
+	// IPRanges is unset when the snapshot could not be read.
+	IPRanges *IPRangesSnapshot `json:"ip_ranges,omitempty"`
+}
+
+// Price is one rate the estimates use.
+type Price struct {
+	Item string  `json:"item"`
+	USD  float64 `json:"usd"`
+	Unit string  `json:"unit"`
+}
+
+// IPRangesSnapshot identifies the AWS ip-ranges.json document traffic was
+// classified with.
+type IPRangesSnapshot struct {
+	Source     string `json:"source"`
+	SyncToken  string `json:"sync_token"`
+	CreateDate string `json:"create_date"`
+}
+
+// CurrentIPRanges identifies the ranges classification uses: the pinned
+// snapshot, the cache or a new download.
+func CurrentIPRanges() (*IPRangesSnapshot, error) {
+	data, source, err := fetchIPRanges()
+	if err != nil {
+		return nil, err
+	}
+	ranges, err := parseIPRanges(data)
+	if err != nil {
+		return nil, err
+	}
+	return &IPRangesSnapshot{Source: source, SyncToken: ranges.SyncToken, CreateDate: ranges.CreateDate}, nil
+}
+
+// NewMethodology describes a scan of region that sampled collectionMinutes
+// of traffic and priced it as cost, with the queries it ran.
+func NewMethodology(region string, collectionMinutes int, cost *CostEstimate, queries []string) *Methodology {
+	natPrice := NATGatewayPricePerGB(region)
+	endpointPrice, ok := interfaceEndpointPricing[region]
+	if !ok {
+		endpointPrice = interfaceEndpointPricing["default"]
+	}
+	m := &Methodology{
+		Projection: fmt.Sprintf("linear extrapolation of a %d-minute sample", collectionMinutes),
+		Formula: fmt.Sprintf("monthly GB = sample bytes / 2^30 × 43,200 / %d (minutes in a 30-day month over sample minutes); "+
+			"monthly cost = monthly GB × $%.4f; gateway endpoint savings = monthly S3 and in-region DynamoDB GB × $%.4f",
+			collectionMinutes, natPrice, natPrice),
+		Prices: []Price{
+			{Item: "NAT Gateway data processing (" + region + ")", USD: natPrice, Unit: "per GB"},
+			{Item: "S3 and DynamoDB gateway endpoints", USD: 0, Unit: "per GB"},
+			{Item: "Interface endpoint hours (" + region + ")", USD: endpointPrice.hourlyPerAZ, Unit: "per AZ-hour"},
+			{Item: "Interface endpoint data processing (" + region + ")", USD: endpointPrice.dataPerGB, Unit: "per GB"},
+			{Item: "Flow Logs ingestion into CloudWatch Logs", USD: FlowLogsIngestionPricePerGB, Unit: "per GB"},
+			{Item: "Logs Insights data scanned", USD: LogsInsightsPricePerGB, Unit: "per GB"},
+		},
+	}
+	if cost != nil && cost.ProjectionMethod != "" {
+		m.Projection = cost.ProjectionMethod
+	}
+	seen := make(map[string]bool)
+	for _, q := range queries {
+		if !seen[q] {
+			seen[q] = true
+			m.Queries = append(m.Queries, q)
+		}
+	}
+	if snapshot, err := CurrentIPRanges(); err == nil {
+		m.IPRanges = snapshot
+	}
+	return m
+}
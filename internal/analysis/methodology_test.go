@@ -0,0 +1,37 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewMethodology(t *testing.T) {
+	defer func() { pinnedIPRanges, pinnedIPRangesPath = nil, "" }()
+	path := filepath.Join(t.TempDir(), "ip-ranges.json")
+	os.WriteFile(path, []byte(testIPRanges), 0644)
+	if err := UseIPRangesFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	cost := &CostEstimate{ProjectionMethod: "projected using 4 samples across 2 weekdays + 1 weekend day"}
+	m := NewMethodology("us-east-1", 15, cost, []string{"fields @message", "stats count(*)", "fields @message"})
+
+	if m.Projection != cost.ProjectionMethod {
+		t.Errorf("Projection = %q, want the estimate's method", m.Projection)
+	}
+	if !strings.Contains(m.Formula, "43,200 / 15") || !strings.Contains(m.Formula, "$0.0450") {
+		t.Errorf("Formula = %q", m.Formula)
+	}
+	if len(m.Queries) != 2 {
+		t.Errorf("Queries = %q, want repeats dropped", m.Queries)
+	}
+	if m.IPRanges == nil || m.IPRanges.SyncToken != "1700000000" || m.IPRanges.CreateDate != "2024-01-01-00-00-00" ||
+		m.IPRanges.Source != "pinned snapshot "+path {
+		t.Errorf("IPRanges = %+v", m.IPRanges)
+	}
+	if m.Prices[0].USD != NATGatewayPricePerGB("us-east-1") {
+		t.Errorf("Prices = %+v", m.Prices)
+	}
+}
@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	pkgtypes "github.com/doitintl/terminator/pkg/types"
+)
+
+// OldNATAge is the age past which a zonal NAT Gateway in a VPC whose
+// subnets have since spread to other Availability Zones is flagged: it was
+// most likely sized for the VPC as it was then.
+const OldNATAge = 2 * 365 * 24 * time.Hour
+
+// FormatAge formats an age as "3y 2mo", "5mo" or "12d".
+func FormatAge(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	years, months := days/365, days%365/30
+	switch {
+	case years > 0 && months > 0:
+		return fmt.Sprintf("%dy %dmo", years, months)
+	case years > 0:
+		return fmt.Sprintf("%dy", years)
+	case months > 0:
+		return fmt.Sprintf("%dmo", months)
+	}
+	return fmt.Sprintf("%dd", days)
+}
+
+// NATCreated describes when nat was created, e.g. "2021-03-04 (3y 2mo
+// ago)", or "" when its creation time is unknown.
+func NATCreated(nat pkgtypes.NATGateway, now time.Time) string {
+	if nat.CreateTime.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s ago)", nat.CreateTime.UTC().Format(time.DateOnly), FormatAge(nat.Age(now)))
+}
+
+// VPCAvailabilityZones returns the Availability Zones of subnets by VPC,
+// sorted, for AnalyzeNATGatewaySetup.
+func VPCAvailabilityZones(subnets []pkgtypes.Subnet) map[string][]string {
+	seen := map[string]map[string]bool{}
+	for _, s := range subnets {
+		if s.AvailabilityZone == "" {
+			continue
+		}
+		if seen[s.VPCID] == nil {
+			seen[s.VPCID] = map[string]bool{}
+		}
+		seen[s.VPCID][s.AvailabilityZone] = true
+	}
+	azs := make(map[string][]string, len(seen))
+	for vpcID, set := range seen {
+		for az := range set {
+			azs[vpcID] = append(azs[vpcID], az)
+		}
+		sort.Strings(azs[vpcID])
+	}
+	return azs
+}
+
+// agingZonalNAT is the oldest zonal NAT Gateway of a VPC and the VPC's
+// Availability Zones without a zonal NAT Gateway of their own.
+type agingZonalNAT struct {
+	NAT         pkgtypes.NATGateway
+	UncoveredAZ []string
+}
+
+// findAgingZonalNAT returns the VPC's oldest zonal NAT Gateway when it is
+// older than OldNATAge and the VPC now has subnets in Availability Zones
+// none of its zonal NAT Gateways are in.
+func findAgingZonalNAT(zonal []pkgtypes.NATGateway, vpcAZs []string, now time.Time) (agingZonalNAT, bool) {
+	var oldest pkgtypes.NATGateway
+	covered := map[string]bool{}
+	for _, nat := range zonal {
+		covered[nat.AvailabilityZone] = true
+		if !nat.CreateTime.IsZero() && (oldest.CreateTime.IsZero() || nat.CreateTime.Before(oldest.CreateTime)) {
+			oldest = nat
+		}
+	}
+	if oldest.Age(now) < OldNATAge {
+		return agingZonalNAT{}, false
+	}
+	var uncovered []string
+	for _, az := range vpcAZs {
+		if !covered[az] {
+			uncovered = append(uncovered, az)
+		}
+	}
+	if len(uncovered) == 0 {
+		return agingZonalNAT{}, false
+	}
+	return agingZonalNAT{NAT: oldest, UncoveredAZ: uncovered}, true
+}
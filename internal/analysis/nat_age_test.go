@@ -0,0 +1,64 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	pkgtypes "github.com/doitintl/terminator/pkg/types"
+)
+
+func TestFormatAge(t *testing.T) {
+	day := 24 * time.Hour
+	tests := map[time.Duration]string{
+		12 * day:           "12d",
+		75 * day:           "2mo",
+		3 * 365 * day:      "3y",
+		(3*365 + 62) * day: "3y 2mo",
+	}
+	for d, want := range tests {
+		if got := FormatAge(d); got != want {
+			t.Errorf("FormatAge(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestNATCreated(t *testing.T) {
+	now := time.Date(2024, 3, 14, 15, 0, 0, 0, time.UTC)
+	nat := pkgtypes.NATGateway{ID: "nat-1", CreateTime: time.Date(2021, 1, 12, 9, 30, 0, 0, time.UTC)}
+	if got, want := NATCreated(nat, now), "2021-01-12 (3y 2mo ago)"; got != want {
+		t.Errorf("NATCreated = %q, want %q", got, want)
+	}
+	if got := NATCreated(pkgtypes.NATGateway{ID: "nat-2"}, now); got != "" {
+		t.Errorf("NATCreated without a creation time = %q, want empty", got)
+	}
+}
+
+func TestAnalyzeNATGatewaySetupFlagsOutgrownZonalNAT(t *testing.T) {
+	now := time.Date(2024, 3, 14, 15, 0, 0, 0, time.UTC)
+	old := pkgtypes.NATGateway{ID: "nat-old", VPCID: "vpc-1", AvailabilityZone: "us-east-1a", AvailabilityMode: "zonal", CreateTime: now.AddDate(-4, 0, 0)}
+	subnets := []pkgtypes.Subnet{
+		{ID: "subnet-a", VPCID: "vpc-1", AvailabilityZone: "us-east-1a"},
+		{ID: "subnet-b", VPCID: "vpc-1", AvailabilityZone: "us-east-1b"},
+		{ID: "subnet-c", VPCID: "vpc-1", AvailabilityZone: "us-east-1c"},
+	}
+
+	recs := AnalyzeNATGatewaySetup([]pkgtypes.NATGateway{old}, VPCAvailabilityZones(subnets), now)
+	if len(recs) != 1 || recs[0].Type != "regional-nat-gateway" || recs[0].Priority != "medium" {
+		t.Fatalf("want one medium regional NAT recommendation, got %+v", recs)
+	}
+	for _, want := range []string{"nat-old was created on 2020-03-14 (4y ago)", "us-east-1b, us-east-1c"} {
+		if !strings.Contains(recs[0].Description, want) {
+			t.Errorf("description missing %q: %s", want, recs[0].Description)
+		}
+	}
+
+	young := old
+	young.CreateTime = now.AddDate(0, -6, 0)
+	if recs := AnalyzeNATGatewaySetup([]pkgtypes.NATGateway{young}, VPCAvailabilityZones(subnets), now); len(recs) != 0 {
+		t.Errorf("a young single NAT Gateway was flagged: %+v", recs)
+	}
+	if recs := AnalyzeNATGatewaySetup([]pkgtypes.NATGateway{old}, nil, now); len(recs) != 0 {
+		t.Errorf("flagged without subnet Availability Zones: %+v", recs)
+	}
+}
@@ -0,0 +1,63 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/units"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// NATCost is the projected monthly cost of the traffic one sample covered:
+// a single NAT Gateway, or several when their traffic could not be split.
+// It is what chargeback bills to the gateways' owners.
+type NATCost struct {
+	NATGateways    []string `json:"nat_gateways"`
+	VPCID          string   `json:"vpc_id,omitempty"`
+	TotalBytes     int64    `json:"total_bytes"`
+	MonthlyCost    float64  `json:"projected_monthly_cost"`
+	MonthlySavings float64  `json:"projected_monthly_savings"`
+}
+
+// BreakdownByNAT projects each sample's monthly cost and gateway endpoint
+// savings the way BreakdownByAZ does, and ties it to the gateways' VPC (empty
+// when a combined sample spans several). Costs are returned highest first.
+func BreakdownByNAT(region string, nats []types.NATGateway, samples []ZoneSample, collectionMinutes int) []NATCost {
+	if collectionMinutes <= 0 {
+		return nil
+	}
+	vpcByNAT := make(map[string]string, len(nats))
+	for _, nat := range nats {
+		vpcByNAT[nat.ID] = nat.VPCID
+	}
+
+	monthlyMultiplier := 43200.0 / float64(collectionMinutes)
+	pricePerGB := NATGatewayPricePerGB(region)
+	var costs []NATCost
+	for _, s := range samples {
+		if s.Stats == nil {
+			continue
+		}
+		ids := strings.Split(s.NATID, ", ")
+		vpc := vpcByNAT[ids[0]]
+		for _, id := range ids[1:] {
+			if vpcByNAT[id] != vpc {
+				vpc = ""
+			}
+		}
+		costs = append(costs, NATCost{
+			NATGateways:    ids,
+			VPCID:          vpc,
+			TotalBytes:     s.Stats.TotalBytes,
+			MonthlyCost:    units.BillingGB(s.Stats.TotalBytes) * monthlyMultiplier * pricePerGB,
+			MonthlySavings: units.BillingGB(s.Stats.Bytes(ServiceS3)+s.Stats.Bytes(ServiceDynamoDB)) * monthlyMultiplier * pricePerGB,
+		})
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].MonthlyCost != costs[j].MonthlyCost {
+			return costs[i].MonthlyCost > costs[j].MonthlyCost
+		}
+		return costs[i].NATGateways[0] < costs[j].NATGateways[0]
+	})
+	return costs
+}
@@ -0,0 +1,43 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestBreakdownByNAT(t *testing.T) {
+	gb := int64(1024 * 1024 * 1024)
+	nats := []types.NATGateway{
+		{ID: "nat-a", VPCID: "vpc-1"},
+		{ID: "nat-b", VPCID: "vpc-2"},
+		{ID: "nat-c", VPCID: "vpc-2"},
+	}
+	samples := []ZoneSample{
+		{NATID: "nat-a", Stats: &TrafficStats{TotalBytes: gb, Services: map[Service]ServiceStats{ServiceS3: {Bytes: gb}}}},
+		{NATID: "nat-b, nat-c", Stats: &TrafficStats{TotalBytes: 3 * gb}},
+		{NATID: "nat-a, nat-b", Stats: &TrafficStats{TotalBytes: 2 * gb}},
+		{NATID: "nat-failed"},
+	}
+
+	costs := BreakdownByNAT("us-east-1", nats, samples, 60)
+	if len(costs) != 3 {
+		t.Fatalf("expected 3 costs, got %d: %+v", len(costs), costs)
+	}
+	if c := costs[0]; len(c.NATGateways) != 2 || c.VPCID != "vpc-2" {
+		t.Errorf("highest cost = %+v, want nat-b and nat-c in vpc-2", c)
+	}
+	// 3 GB/hour * 720 hours * $0.045
+	assertApprox(t, costs[0].MonthlyCost, 3*720*0.045, 0.001, "vpc-2 monthly cost")
+	if c := costs[1]; c.VPCID != "" {
+		t.Errorf("a sample spanning VPCs should have no VPC, got %+v", c)
+	}
+	if c := costs[2]; c.NATGateways[0] != "nat-a" || c.VPCID != "vpc-1" {
+		t.Errorf("lowest cost = %+v, want nat-a in vpc-1", c)
+	}
+	assertApprox(t, costs[2].MonthlySavings, 720*0.045, 0.001, "nat-a monthly savings")
+
+	if costs := BreakdownByNAT("us-east-1", nats, samples, 0); costs != nil {
+		t.Errorf("zero-minute sample should yield no breakdown, got %+v", costs)
+	}
+}
@@ -0,0 +1,266 @@
+package analysis
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// endpointSecurityGroupVar holds the ID of a security group created by
+// EndpointPlacement.SecurityGroupCommands for the commands that follow.
+const endpointSecurityGroupVar = "SG_ID"
+
+// EndpointPlacement is where new interface endpoints go: one subnet in each
+// availability zone NAT traffic comes from, and a security group admitting
+// HTTPS from the workloads that send it.
+type EndpointPlacement struct {
+	VPCID             string   `json:"vpc_id"`
+	SubnetIDs         []string `json:"subnet_ids"`
+	AvailabilityZones []string `json:"availability_zones"`
+	// SecurityGroupID is an existing group that already admits HTTPS from
+	// the workloads; empty when a new one has to be created.
+	SecurityGroupID string `json:"security_group_id,omitempty"`
+	// SecurityGroupReason says why the group was picked.
+	SecurityGroupReason string `json:"security_group_reason,omitempty"`
+	// SourceCIDRs are the NAT-routed subnets.
+	SourceCIDRs []string `json:"source_cidrs"`
+	// VPCCIDRs are the VPC's ranges, which a new group admits HTTPS from.
+	VPCCIDRs []string `json:"vpc_cidrs,omitempty"`
+	// WorkloadSecurityGroups are the groups of the network interfaces in the
+	// NAT-routed subnets, the ones generating the traffic.
+	WorkloadSecurityGroups []string `json:"workload_security_groups,omitempty"`
+}
+
+// PlacementInventory is what placement is decided from.
+type PlacementInventory struct {
+	Subnets        []types.Subnet
+	SecurityGroups []types.SecurityGroup
+	VPCCIDRs       []string
+	// WorkloadSecurityGroups are the security groups of the workload network
+	// interfaces, by subnet ID.
+	WorkloadSecurityGroups map[string][]string
+}
+
+// PlanEndpointPlacement picks the subnets and security group for the
+// interface endpoints of the VPC endpoints analyzed. activeAZs are the zones
+// the sample saw NAT traffic in; when none of them has a NAT-routed subnet,
+// every zone that has one is used. It returns nil when no subnet routes to a
+// NAT Gateway.
+func PlanEndpointPlacement(endpoints *EndpointAnalysis, inv PlacementInventory, activeAZs []string) *EndpointPlacement {
+	if endpoints == nil {
+		return nil
+	}
+	natRouted := make(map[string]bool)
+	for _, id := range endpoints.NATSubnetIDs() {
+		natRouted[id] = true
+	}
+
+	byAZ := make(map[string][]types.Subnet)
+	var sources, workloadGroups []string
+	for _, sn := range inv.Subnets {
+		if !natRouted[sn.ID] || sn.AvailabilityZone == "" {
+			continue
+		}
+		byAZ[sn.AvailabilityZone] = append(byAZ[sn.AvailabilityZone], sn)
+		if sn.CIDR != "" {
+			sources = append(sources, sn.CIDR)
+		}
+		workloadGroups = append(workloadGroups, inv.WorkloadSecurityGroups[sn.ID]...)
+	}
+	if len(byAZ) == 0 {
+		return nil
+	}
+
+	var zones []string
+	for _, az := range activeAZs {
+		if len(byAZ[az]) > 0 {
+			zones = append(zones, az)
+		}
+	}
+	if len(zones) == 0 {
+		for az := range byAZ {
+			zones = append(zones, az)
+		}
+	}
+	sort.Strings(zones)
+	zones = dedupeSorted(zones)
+	sort.Strings(sources)
+	sort.Strings(workloadGroups)
+
+	p := &EndpointPlacement{
+		VPCID:                  endpoints.VPCID,
+		AvailabilityZones:      zones,
+		SourceCIDRs:            dedupeSorted(sources),
+		VPCCIDRs:               inv.VPCCIDRs,
+		WorkloadSecurityGroups: dedupeSorted(workloadGroups),
+	}
+	for _, az := range zones {
+		candidates := byAZ[az]
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+		p.SubnetIDs = append(p.SubnetIDs, candidates[0].ID)
+	}
+	p.SecurityGroupID, p.SecurityGroupReason = p.reusableSecurityGroup(endpoints, inv.SecurityGroups)
+	return p
+}
+
+// reusableSecurityGroup returns a group that lets the workloads reach the
+// endpoints on HTTPS, by CIDR or by referencing their security groups,
+// preferring one the VPC's interface endpoints already use.
+func (p *EndpointPlacement) reusableSecurityGroup(endpoints *EndpointAnalysis, groups []types.SecurityGroup) (id, reason string) {
+	used := make(map[string]bool)
+	for _, ep := range endpoints.InterfaceEndpoints {
+		for _, id := range ep.SecurityGroups {
+			used[id] = true
+		}
+	}
+	sorted := append([]types.SecurityGroup(nil), groups...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if used[sorted[i].ID] != used[sorted[j].ID] {
+			return used[sorted[i].ID]
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	for _, g := range sorted {
+		var admits string
+		switch {
+		case len(p.SourceCIDRs) > 0 && admitsAll(g.HTTPSCIDRs, p.SourceCIDRs):
+			admits = "admits HTTPS from every NAT-routed subnet"
+		case len(p.WorkloadSecurityGroups) > 0 && containsAll(g.HTTPSGroups, p.WorkloadSecurityGroups):
+			admits = "admits HTTPS from the workloads' security groups"
+		default:
+			continue
+		}
+		if used[g.ID] {
+			return g.ID, "used by existing interface endpoints; " + admits
+		}
+		return g.ID, admits
+	}
+	return "", ""
+}
+
+func containsAll(values, want []string) bool {
+	have := make(map[string]bool, len(values))
+	for _, v := range values {
+		have[v] = true
+	}
+	for _, w := range want {
+		if !have[w] {
+			return false
+		}
+	}
+	return true
+}
+
+// admitsAll reports whether every source range lies inside one of allowed.
+func admitsAll(allowed, sources []string) bool {
+	for _, src := range sources {
+		_, srcNet, err := net.ParseCIDR(src)
+		if err != nil {
+			return false
+		}
+		srcBits, _ := srcNet.Mask.Size()
+		ok := false
+		for _, a := range allowed {
+			_, aNet, err := net.ParseCIDR(a)
+			if err != nil {
+				continue
+			}
+			if bits, _ := aNet.Mask.Size(); bits <= srcBits && aNet.Contains(srcNet.IP) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func dedupeSorted(values []string) []string {
+	out := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// SecurityGroupCommands create a minimal endpoint security group, admitting
+// only HTTPS from the VPC's ranges, when no existing one can be reused. The
+// new group's ID is kept in $SG_ID for the create-vpc-endpoint commands that
+// follow, so run them in the same shell.
+func (p *EndpointPlacement) SecurityGroupCommands() []string {
+	if p == nil || p.SecurityGroupID != "" {
+		return nil
+	}
+	cidrs := p.VPCCIDRs
+	if len(cidrs) == 0 {
+		cidrs = p.SourceCIDRs
+	}
+	var ranges []string
+	for _, cidr := range cidrs {
+		ranges = append(ranges, "{CidrIp="+cidr+"}")
+	}
+	return []string{
+		fmt.Sprintf("%s=$(aws ec2 create-security-group \\\n  --vpc-id %s \\\n  --group-name %s \\\n  --description %s \\\n  --query GroupId --output text)",
+			endpointSecurityGroupVar, shellQuote(p.VPCID), shellQuote("terminat-endpoints-"+p.VPCID), shellQuote("HTTPS from the VPC to interface endpoints")),
+		fmt.Sprintf("aws ec2 authorize-security-group-ingress \\\n  --group-id \"$%s\" \\\n  --ip-permissions %s",
+			endpointSecurityGroupVar, shellQuote("IpProtocol=tcp,FromPort=443,ToPort=443,IpRanges=["+strings.Join(ranges, ",")+"]")),
+	}
+}
+
+// interfaceEndpointCommands returns create-vpc-endpoint commands for the
+// services, in the subnets and security group of the endpoints' placement,
+// after the quota and conflict prechecks and the commands creating the group
+// if needed. Without a placement, subnetsArg and a placeholder security
+// group are used.
+func interfaceEndpointCommands(endpoints *EndpointAnalysis, vpcID string, serviceNames []string, subnetsArg string) []string {
+	if len(serviceNames) == 0 {
+		return nil
+	}
+	commands, serviceNames := endpoints.precheckCommands("Interface", serviceNames)
+	if len(serviceNames) == 0 {
+		return commands
+	}
+	securityGroup := shellQuote("<security-group-id>")
+	var p *EndpointPlacement
+	if endpoints != nil {
+		p = endpoints.Placement
+	}
+	if p != nil {
+		var quoted []string
+		for _, id := range p.SubnetIDs {
+			quoted = append(quoted, shellQuote(id))
+		}
+		subnetsArg = strings.Join(quoted, " ")
+		if p.SecurityGroupID != "" {
+			securityGroup = shellQuote(p.SecurityGroupID)
+		} else {
+			securityGroup = "\"$" + endpointSecurityGroupVar + "\""
+			commands = append(commands, p.SecurityGroupCommands()...)
+		}
+	}
+	for _, name := range serviceNames {
+		commands = append(commands, fmt.Sprintf(
+			"aws ec2 create-vpc-endpoint \\\n  --vpc-id %s \\\n  --service-name %s \\\n  --vpc-endpoint-type Interface \\\n  --subnet-ids %s \\\n  --security-group-ids %s \\\n  --private-dns-enabled",
+			shellQuote(vpcID), shellQuote(name), subnetsArg, securityGroup))
+	}
+	return commands
+}
+
+// endpointAZCount is how many zones each new interface endpoint is priced
+// for: the placement's, or one per NAT-routed subnet without one.
+func (a *EndpointAnalysis) endpointAZCount() int {
+	if a.Placement != nil && len(a.Placement.SubnetIDs) > 0 {
+		return len(a.Placement.SubnetIDs)
+	}
+	if n := len(a.NATSubnetIDs()); n > 0 {
+		return n
+	}
+	return 1
+}
@@ -0,0 +1,117 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func placementFixture() (*EndpointAnalysis, []types.Subnet) {
+	endpoints := &EndpointAnalysis{
+		VPCID:  "vpc-1",
+		Region: "us-east-1",
+		RouteTables: []types.RouteTable{
+			{ID: "rtb-private", Routes: []types.Route{{DestinationCIDR: "0.0.0.0/0", TargetType: "nat-gateway"}}, Subnets: []string{"subnet-b1", "subnet-a2", "subnet-a1", "subnet-c1"}},
+			{ID: "rtb-public", Routes: []types.Route{{DestinationCIDR: "0.0.0.0/0", TargetType: "igw"}}, Subnets: []string{"subnet-pub"}},
+		},
+	}
+	subnets := []types.Subnet{
+		{ID: "subnet-a1", CIDR: "10.0.1.0/24", AvailabilityZone: "us-east-1a"},
+		{ID: "subnet-a2", CIDR: "10.0.2.0/24", AvailabilityZone: "us-east-1a"},
+		{ID: "subnet-b1", CIDR: "10.0.3.0/24", AvailabilityZone: "us-east-1b"},
+		{ID: "subnet-c1", CIDR: "10.0.4.0/24", AvailabilityZone: "us-east-1c"},
+		{ID: "subnet-pub", CIDR: "10.0.0.0/24", AvailabilityZone: "us-east-1a"},
+	}
+	return endpoints, subnets
+}
+
+func TestPlanEndpointPlacementOneSubnetPerActiveAZ(t *testing.T) {
+	endpoints, subnets := placementFixture()
+
+	p := PlanEndpointPlacement(endpoints, PlacementInventory{Subnets: subnets}, []string{"us-east-1b", "us-east-1a", "us-west-2z"})
+	if !reflect.DeepEqual(p.SubnetIDs, []string{"subnet-a1", "subnet-b1"}) {
+		t.Errorf("subnets = %v, want one per active AZ", p.SubnetIDs)
+	}
+	if !reflect.DeepEqual(p.SourceCIDRs, []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24", "10.0.4.0/24"}) {
+		t.Errorf("source CIDRs = %v", p.SourceCIDRs)
+	}
+
+	all := PlanEndpointPlacement(endpoints, PlacementInventory{Subnets: subnets}, nil)
+	if !reflect.DeepEqual(all.AvailabilityZones, []string{"us-east-1a", "us-east-1b", "us-east-1c"}) {
+		t.Errorf("without traffic AZs, zones = %v, want every NAT-routed zone", all.AvailabilityZones)
+	}
+
+	if PlanEndpointPlacement(&EndpointAnalysis{VPCID: "vpc-2"}, PlacementInventory{Subnets: subnets}, nil) != nil {
+		t.Error("expected no placement without NAT-routed subnets")
+	}
+}
+
+func TestPlanEndpointPlacementReusesSecurityGroup(t *testing.T) {
+	endpoints, subnets := placementFixture()
+	endpoints.InterfaceEndpoints = []types.VPCEndpoint{{ServiceName: "com.amazonaws.us-east-1.sts", SecurityGroups: []string{"sg-endpoints"}}}
+	groups := []types.SecurityGroup{
+		{ID: "sg-narrow", HTTPSCIDRs: []string{"10.0.1.0/24"}},
+		{ID: "sg-app", HTTPSCIDRs: []string{"10.0.0.0/16"}},
+		{ID: "sg-endpoints", HTTPSCIDRs: []string{"10.0.0.0/20"}},
+	}
+
+	p := PlanEndpointPlacement(endpoints, PlacementInventory{Subnets: subnets, SecurityGroups: groups}, nil)
+	if p.SecurityGroupID != "sg-endpoints" || !strings.HasPrefix(p.SecurityGroupReason, "used by existing interface endpoints") {
+		t.Errorf("security group = %q (%s), want the one existing endpoints use", p.SecurityGroupID, p.SecurityGroupReason)
+	}
+	if cmds := p.SecurityGroupCommands(); cmds != nil {
+		t.Errorf("expected no group to create, got %v", cmds)
+	}
+
+	none := PlanEndpointPlacement(endpoints, PlacementInventory{Subnets: subnets, SecurityGroups: groups[:1]}, nil)
+	if none.SecurityGroupID != "" {
+		t.Errorf("a group admitting one subnet was reused: %q", none.SecurityGroupID)
+	}
+}
+
+func TestPlanEndpointPlacementWorkloadSecurityGroups(t *testing.T) {
+	endpoints, subnets := placementFixture()
+	inv := PlacementInventory{
+		Subnets: subnets,
+		SecurityGroups: []types.SecurityGroup{
+			{ID: "sg-partial", HTTPSGroups: []string{"sg-web"}},
+			{ID: "sg-https", HTTPSGroups: []string{"sg-batch", "sg-web", "sg-other"}},
+		},
+		WorkloadSecurityGroups: map[string][]string{
+			"subnet-a1":  {"sg-web"},
+			"subnet-c1":  {"sg-batch", "sg-web"},
+			"subnet-pub": {"sg-bastion"},
+		},
+	}
+	p := PlanEndpointPlacement(endpoints, inv, nil)
+	if !reflect.DeepEqual(p.WorkloadSecurityGroups, []string{"sg-batch", "sg-web"}) {
+		t.Errorf("workload groups = %v, want those of NAT-routed subnets", p.WorkloadSecurityGroups)
+	}
+	if p.SecurityGroupID != "sg-https" {
+		t.Errorf("security group = %q, want the one referencing every workload group", p.SecurityGroupID)
+	}
+}
+
+func TestInterfaceEndpointCommandsArePlaced(t *testing.T) {
+	endpoints, subnets := placementFixture()
+	endpoints.Placement = PlanEndpointPlacement(endpoints, PlacementInventory{Subnets: subnets, VPCCIDRs: []string{"10.0.0.0/16"}}, []string{"us-east-1a", "us-east-1c"})
+
+	c := EvaluateInterfaceEndpoints("KMS", "us-east-1", []string{"kms"}, &TrafficStats{ServiceBytes: map[string]int64{"kms": 1}}, 60, endpoints)
+	cmds := c.createCommands("us-east-1", "vpc-1")
+	if len(cmds) != 3 {
+		t.Fatalf("expected group creation, ingress and endpoint commands, got %d: %v", len(cmds), cmds)
+	}
+	if !strings.HasPrefix(cmds[0], "SG_ID=$(aws ec2 create-security-group") || !strings.Contains(cmds[1], "IpRanges=[{CidrIp=10.0.0.0/16}]") {
+		t.Errorf("unexpected security group commands: %v", cmds[:2])
+	}
+	create := cmds[2]
+	if !strings.Contains(create, "--subnet-ids 'subnet-a1' 'subnet-c1'") || !strings.Contains(create, `--security-group-ids "$SG_ID"`) {
+		t.Errorf("endpoint command not placed: %s", create)
+	}
+	if strings.Contains(strings.Join(cmds, "\n"), "<") {
+		t.Errorf("placeholders left in commands: %v", cmds)
+	}
+	assertApprox(t, c.EndpointFixedMonthly, 0.01*2*24*30, 0.001, "fixed cost priced for the two placed AZs")
+}
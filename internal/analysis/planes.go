@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// controlPlaneMaxBytesPerFlow is the average Flow Log record size below which
+// traffic to a destination is treated as API calls rather than payload
+// transfer. Records cover a 60-second aggregation window, so a steady stream
+// of small API requests stays well under it while S3 GET/PUT payloads do not.
+const controlPlaneMaxBytesPerFlow = 64 * 1024
+
+// PlaneSplit divides a service's traffic into control-plane API calls and
+// data-plane transfer.
+type PlaneSplit struct {
+	ControlBytes int64 `json:"control_bytes"`
+	DataBytes    int64 `json:"data_bytes"`
+}
+
+// DataShare is the percentage of the service's bytes that are data-plane transfer.
+func (p *PlaneSplit) DataShare() float64 {
+	total := p.ControlBytes + p.DataBytes
+	if total == 0 {
+		return 0
+	}
+	return float64(p.DataBytes) / float64(total) * 100
+}
+
+// isControlPlane applies the port and bytes-per-flow heuristics. HTTPS is the
+// only port AWS APIs listen on, so anything else is data plane; an empty port
+// (aggregated results) is treated as HTTPS.
+func isControlPlane(bytes, flows int64, dstPort string) bool {
+	if dstPort != "" && dstPort != "443" {
+		return false
+	}
+	if flows <= 0 {
+		flows = 1
+	}
+	return bytes/flows < controlPlaneMaxBytesPerFlow
+}
+
+func (ts *TrafficStats) addPlane(service string, bytes, flows int64, dstPort string) {
+	if ts.Planes == nil {
+		ts.Planes = make(map[string]*PlaneSplit)
+	}
+	p, ok := ts.Planes[service]
+	if !ok {
+		p = &PlaneSplit{}
+		ts.Planes[service] = p
+	}
+	if isControlPlane(bytes, flows, dstPort) {
+		p.ControlBytes += bytes
+	} else {
+		p.DataBytes += bytes
+	}
+}
+
+// PlaneServices returns the services in Planes, most data-plane bytes first.
+func (ts *TrafficStats) PlaneServices() []string {
+	services := make([]string, 0, len(ts.Planes))
+	for svc := range ts.Planes {
+		services = append(services, svc)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		a, b := ts.Planes[services[i]], ts.Planes[services[j]]
+		if a.DataBytes != b.DataBytes {
+			return a.DataBytes > b.DataBytes
+		}
+		return services[i] < services[j]
+	})
+	return services
+}
+
+// findingPlaneService maps a Finding.Service to its key in TrafficStats.Planes.
+func findingPlaneService(service string) string {
+	switch service {
+	case "Secrets Manager":
+		return "secretsmanager"
+	default:
+		return strings.ToLower(service)
+	}
+}
+
+// apiOnlyConfidence is the score confidence given to findings whose service
+// only saw API calls in the sample.
+const apiOnlyConfidence = 0.3
+
+// PrioritizeFindings orders findings so services with the most data-plane
+// traffic come first, since that is where per-GB savings are real. Findings
+// for services the sample saw only API calls to are lowered to "low" severity.
+// Findings for services without sampled traffic keep their order at the end.
+func PrioritizeFindings(findings []types.Finding, stats *TrafficStats) []types.Finding {
+	if stats == nil || len(stats.Planes) == 0 {
+		return findings
+	}
+	out := append([]types.Finding(nil), findings...)
+	dataBytes := func(f types.Finding) int64 {
+		if p, ok := stats.Planes[findingPlaneService(f.Service)]; ok {
+			return p.DataBytes
+		}
+		return -1
+	}
+	for i, f := range out {
+		p, ok := stats.Planes[findingPlaneService(f.Service)]
+		if ok && p.DataBytes == 0 && p.ControlBytes > 0 {
+			out[i].Severity = "low"
+			out[i].Confidence = apiOnlyConfidence
+			out[i].Impact = strings.TrimSpace(out[i].Impact + " Sampled traffic is API calls only; per-GB savings are small.")
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return dataBytes(out[i]) > dataBytes(out[j]) })
+	return out
+}
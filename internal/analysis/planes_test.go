@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	pkgtypes "github.com/doitintl/terminator/pkg/types"
+)
+
+func TestAggregatedResultsSplitPlanes(t *testing.T) {
+	_, s3Net, _ := net.ParseCIDR("52.216.0.0/15")
+	_, ddbNet, _ := net.ParseCIDR("3.218.180.0/22")
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{
+		s3Ranges:     []*net.IPNet{s3Net},
+		dynamoRanges: []*net.IPNet{ddbNet},
+		services:     map[string]string{"1.2.3.4": "sts"},
+	}}
+
+	results := [][]types.ResultField{
+		// 10 MB over 20 records: S3 payloads
+		{{Field: strPtr("resolved_dst"), Value: strPtr("52.216.0.1")}, {Field: strPtr("total_bytes"), Value: strPtr("10485760")}, {Field: strPtr("flow_count"), Value: strPtr("20")}},
+		// 200 KB over 100 records: DynamoDB API calls
+		{{Field: strPtr("resolved_dst"), Value: strPtr("3.218.180.1")}, {Field: strPtr("total_bytes"), Value: strPtr("204800")}, {Field: strPtr("flow_count"), Value: strPtr("100")}},
+		{{Field: strPtr("resolved_dst"), Value: strPtr("1.2.3.4")}, {Field: strPtr("total_bytes"), Value: strPtr("5000")}, {Field: strPtr("flow_count"), Value: strPtr("5")}},
+	}
+	stats, err := ta.AnalyzeAggregatedResults(results)
+	if err != nil {
+		t.Fatalf("AnalyzeAggregatedResults returned error: %v", err)
+	}
+	if p := stats.Planes["s3"]; p == nil || p.DataBytes != 10485760 || p.ControlBytes != 0 {
+		t.Errorf("S3 should be data plane: %+v", p)
+	}
+	if p := stats.Planes["dynamodb"]; p == nil || p.ControlBytes != 204800 || p.DataBytes != 0 {
+		t.Errorf("DynamoDB should be control plane: %+v", p)
+	}
+	if p := stats.Planes["sts"]; p == nil || p.ControlBytes != 5000 {
+		t.Errorf("STS should be keyed by its regional service: %+v", p)
+	}
+	if got := stats.PlaneServices(); got[0] != "s3" {
+		t.Errorf("PlaneServices() = %v, want s3 first", got)
+	}
+}
+
+func TestIsControlPlanePort(t *testing.T) {
+	if isControlPlane(100, 1, "5432") {
+		t.Error("non-HTTPS traffic should never be control plane")
+	}
+	if !isControlPlane(100, 1, "443") || !isControlPlane(100, 0, "") {
+		t.Error("small HTTPS flows should be control plane")
+	}
+}
+
+func TestPrioritizeFindings(t *testing.T) {
+	stats := &TrafficStats{Planes: map[string]*PlaneSplit{
+		"s3":       {DataBytes: 1 << 30},
+		"dynamodb": {ControlBytes: 1 << 20},
+	}}
+	findings := []pkgtypes.Finding{
+		{Service: "ECR", Severity: "high"},
+		{Service: "DynamoDB", Severity: "high"},
+		{Service: "S3", Severity: "high"},
+	}
+
+	got := PrioritizeFindings(findings, stats)
+	if got[0].Service != "S3" || got[1].Service != "DynamoDB" || got[2].Service != "ECR" {
+		t.Fatalf("unexpected order: %+v", got)
+	}
+	if got[1].Severity != "low" || got[0].Severity != "high" {
+		t.Errorf("API-only DynamoDB should be lowered, S3 kept: %+v", got)
+	}
+	if findings[1].Severity != "high" {
+		t.Error("PrioritizeFindings must not modify its input")
+	}
+}
@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Port categories, which decide the remediation: VPC endpoints only carry
+// HTTPS to AWS services, so the rest needs something else.
+const (
+	PortHTTPS     = "https"
+	PortPlaintext = "plaintext"
+	PortDatabase  = "database"
+	PortOther     = "other"
+)
+
+// wellKnownPorts names the service ports worth telling apart.
+var wellKnownPorts = map[string]struct{ name, category string }{
+	"tcp/443":   {"HTTPS", PortHTTPS},
+	"tcp/80":    {"HTTP", PortPlaintext},
+	"tcp/21":    {"FTP", PortPlaintext},
+	"tcp/23":    {"Telnet", PortPlaintext},
+	"tcp/22":    {"SSH", PortOther},
+	"tcp/25":    {"SMTP", PortOther},
+	"tcp/587":   {"SMTP submission", PortOther},
+	"udp/53":    {"DNS", PortOther},
+	"tcp/53":    {"DNS", PortOther},
+	"udp/123":   {"NTP", PortOther},
+	"tcp/1433":  {"SQL Server", PortDatabase},
+	"tcp/1521":  {"Oracle", PortDatabase},
+	"tcp/3306":  {"MySQL", PortDatabase},
+	"tcp/5432":  {"PostgreSQL", PortDatabase},
+	"tcp/6379":  {"Redis", PortDatabase},
+	"tcp/9042":  {"Cassandra", PortDatabase},
+	"tcp/9092":  {"Kafka", PortDatabase},
+	"tcp/9200":  {"Elasticsearch", PortDatabase},
+	"tcp/11211": {"Memcached", PortDatabase},
+	"tcp/27017": {"MongoDB", PortDatabase},
+}
+
+// protocolNames are the IANA protocol numbers Flow Logs record.
+var protocolNames = map[string]string{"1": "icmp", "6": "tcp", "17": "udp", "58": "icmpv6"}
+
+// PortKey identifies traffic by protocol and service port, as "tcp/443";
+// ICMP has no ports and is keyed by protocol alone.
+func PortKey(protocol, port string) string {
+	name, ok := protocolNames[protocol]
+	if !ok {
+		if protocol == "" || protocol == "-" {
+			return "unknown"
+		}
+		name = "proto-" + protocol
+	}
+	if name == "icmp" || name == "icmpv6" || port == "" || port == "-" {
+		return name
+	}
+	return name + "/" + port
+}
+
+// ServicePort picks the service end of a flow: the lower of its two ports,
+// since clients connect from ephemeral ports. Requests and their responses
+// land on the same port this way.
+func ServicePort(srcPort, dstPort string) string {
+	src, errSrc := strconv.Atoi(srcPort)
+	dst, errDst := strconv.Atoi(dstPort)
+	switch {
+	case errDst != nil:
+		return srcPort
+	case errSrc != nil || dst <= src:
+		return dstPort
+	default:
+		return srcPort
+	}
+}
+
+func (ts *TrafficStats) addPort(key string, bytes int64) {
+	if ts.PortBytes == nil {
+		ts.PortBytes = make(map[string]int64)
+	}
+	ts.PortBytes[key] += bytes
+}
+
+// PortTraffic is the sampled traffic of one protocol and service port.
+type PortTraffic struct {
+	Key      string  `json:"key"`
+	Name     string  `json:"name,omitempty"`
+	Category string  `json:"category"`
+	Bytes    int64   `json:"bytes"`
+	SharePct float64 `json:"share_pct"`
+}
+
+// Remediation says what takes this traffic off the NAT Gateway.
+func (p PortTraffic) Remediation() string {
+	switch p.Category {
+	case PortHTTPS:
+		return "VPC endpoints where the destination is an AWS service"
+	case PortPlaintext:
+		return "Unencrypted: move to HTTPS, which is all VPC endpoints serve"
+	case PortDatabase:
+		return "Database or replication traffic: VPC peering, Transit Gateway or PrivateLink, not VPC endpoints"
+	default:
+		return "Not HTTPS: VPC endpoints don't carry it; peering or PrivateLink if the peer is yours"
+	}
+}
+
+// PortBreakdown lists the traffic by protocol and service port, most bytes
+// first.
+func (ts *TrafficStats) PortBreakdown() []PortTraffic {
+	var total int64
+	for _, b := range ts.PortBytes {
+		total += b
+	}
+	ports := make([]PortTraffic, 0, len(ts.PortBytes))
+	for key, b := range ts.PortBytes {
+		p := PortTraffic{Key: key, Category: PortOther, Bytes: b}
+		if known, ok := wellKnownPorts[key]; ok {
+			p.Name, p.Category = known.name, known.category
+		}
+		if total > 0 {
+			p.SharePct = float64(b) / float64(total) * 100
+		}
+		ports = append(ports, p)
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Bytes != ports[j].Bytes {
+			return ports[i].Bytes > ports[j].Bytes
+		}
+		return ports[i].Key < ports[j].Key
+	})
+	return ports
+}
@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+func TestPortKeyAndServicePort(t *testing.T) {
+	cases := []struct{ protocol, src, dst, want string }{
+		{"6", "51234", "443", "tcp/443"},
+		{"6", "5432", "40000", "tcp/5432"}, // a response from PostgreSQL
+		{"17", "53", "53", "udp/53"},
+		{"1", "0", "0", "icmp"},
+		{"47", "-", "-", "proto-47"},
+		{"", "1", "2", "unknown"},
+	}
+	for _, c := range cases {
+		if got := PortKey(c.protocol, ServicePort(c.src, c.dst)); got != c.want {
+			t.Errorf("PortKey(%s, ServicePort(%s, %s)) = %q, want %q", c.protocol, c.src, c.dst, got, c.want)
+		}
+	}
+}
+
+func TestAggregatedResultsByPort(t *testing.T) {
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{}}
+	row := func(dst, port, protocol, bytes string) []types.ResultField {
+		return []types.ResultField{
+			{Field: strPtr("resolved_dst"), Value: strPtr(dst)},
+			{Field: strPtr("service_port"), Value: strPtr(port)},
+			{Field: strPtr("protocol"), Value: strPtr(protocol)},
+			{Field: strPtr("total_bytes"), Value: strPtr(bytes)},
+			{Field: strPtr("flow_count"), Value: strPtr("1")},
+		}
+	}
+	stats, err := ta.AnalyzeAggregatedResults([][]types.ResultField{
+		row("203.0.113.10", "443", "6", "6000"),
+		row("203.0.113.11", "443", "6", "1000"),
+		row("198.51.100.5", "5432", "6", "2000"),
+		row("198.51.100.6", "80", "6", "1000"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ports := stats.PortBreakdown()
+	if len(ports) != 3 {
+		t.Fatalf("expected 3 ports, got %+v", ports)
+	}
+	if p := ports[0]; p.Key != "tcp/443" || p.Bytes != 7000 || p.Category != PortHTTPS {
+		t.Errorf("top port = %+v, want HTTPS with 7000 bytes", p)
+	}
+	assertApprox(t, ports[0].SharePct, 70, 0.001, "HTTPS share")
+	if p := ports[1]; p.Name != "PostgreSQL" || p.Category != PortDatabase {
+		t.Errorf("second port = %+v, want PostgreSQL", p)
+	}
+	if p := ports[2]; p.Category != PortPlaintext {
+		t.Errorf("port 80 = %+v, want plaintext", p)
+	}
+	if p := stats.Planes["other"]; p == nil || p.ControlBytes != 7000 || p.DataBytes != 3000 {
+		t.Errorf("non-HTTPS flows should be data plane: %+v", p)
+	}
+
+	merged := &TrafficStats{}
+	merged.Merge(stats)
+	merged.Merge(stats)
+	if merged.PortBytes["tcp/5432"] != 4000 {
+		t.Errorf("merged PortBytes = %v", merged.PortBytes)
+	}
+}
@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// Quota codes and defaults of the VPC endpoint quotas, for the vpc service
+// code in Service Quotas.
+const (
+	GatewayEndpointQuotaCode      = "L-1B52E74A" // Gateway VPC endpoints per Region
+	InterfaceEndpointQuotaCode    = "L-29B6F2EB" // Interface VPC endpoints per VPC
+	DefaultGatewayEndpointQuota   = 20
+	DefaultInterfaceEndpointQuota = 50
+)
+
+// EndpointQuota is how many endpoints of a type count against their quota:
+// gateway endpoints per region, interface endpoints per VPC.
+type EndpointQuota struct {
+	Type      string `json:"type"`
+	QuotaCode string `json:"quota_code"`
+	Used      int    `json:"used"`
+	Limit     int    `json:"limit"`
+	// Default is set when the quota could not be read and Limit is the AWS
+	// default.
+	Default bool `json:"default,omitempty"`
+}
+
+// Remaining is how many more endpoints the quota allows.
+func (q EndpointQuota) Remaining() int {
+	if q.Used >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Used
+}
+
+// EndpointConflict is an endpoint for a service that neither works nor is
+// gone: a new endpoint for the service would clash with it.
+type EndpointConflict struct {
+	ServiceName string `json:"service_name"`
+	EndpointID  string `json:"endpoint_id"`
+	Type        string `json:"type"`
+	State       string `json:"state"`
+}
+
+// Pending reports whether the endpoint is still coming up, so it should be
+// waited for (or accepted) rather than replaced.
+func (c EndpointConflict) Pending() bool {
+	s := strings.ToLower(c.State)
+	return s == "pending" || s == "pendingacceptance"
+}
+
+// endpointUsable sorts an endpoint by state: usable endpoints count as
+// existing, conflicting ones are recorded, and deleted ones are ignored.
+func endpointUsable(ep types.VPCEndpoint) (usable, conflict bool) {
+	switch strings.ToLower(ep.State) {
+	case "", "available":
+		return true, false
+	case "deleting", "deleted":
+		return false, false
+	default:
+		return false, true
+	}
+}
+
+// precheckCommands are what has to happen before creating endpoints of
+// endpointType for serviceNames: a note when the quota would be exceeded,
+// and for each conflicting endpoint, a command deleting a failed one or a
+// note to wait for a pending one. The second result drops the services
+// whose pending endpoint should be waited for.
+func (a *EndpointAnalysis) precheckCommands(endpointType string, serviceNames []string) (commands, create []string) {
+	if a == nil {
+		return nil, serviceNames
+	}
+	var remove []string
+	for _, name := range serviceNames {
+		waiting := false
+		for _, c := range a.Conflicts {
+			if c.ServiceName != name || c.Type != endpointType {
+				continue
+			}
+			if c.Pending() {
+				waiting = true
+				commands = append(commands, fmt.Sprintf("# %s for %s is %s: wait for it (or accept it) instead of creating another", c.EndpointID, name, c.State))
+				continue
+			}
+			commands = append(commands, fmt.Sprintf("# %s for %s is %s; delete it before creating a new one", c.EndpointID, name, c.State))
+			remove = append(remove, shellQuote(c.EndpointID))
+		}
+		if !waiting {
+			create = append(create, name)
+		}
+	}
+	if len(remove) > 0 {
+		commands = append(commands, "aws ec2 delete-vpc-endpoints \\\n  --vpc-endpoint-ids "+strings.Join(remove, " "))
+	}
+
+	for _, q := range a.Quotas {
+		if q.Type != endpointType || len(create) <= q.Remaining() {
+			continue
+		}
+		scope := "the VPC"
+		if endpointType == "Gateway" {
+			scope = "the region"
+		}
+		limit := fmt.Sprintf("%d", q.Limit)
+		if q.Default {
+			limit += " (AWS default; the applied quota could not be read)"
+		}
+		commands = append([]string{fmt.Sprintf("# ⚠️ %s has %d of %s %s endpoints; request a quota increase (%s) before creating %d more",
+			scope, q.Used, limit, strings.ToLower(endpointType), q.QuotaCode, len(create))}, commands...)
+	}
+	return commands, create
+}
+
+// Prechecks lists the quota and conflict problems in plain sentences, for
+// summaries.
+func (a *EndpointAnalysis) Prechecks() []string {
+	if a == nil {
+		return nil
+	}
+	var notes []string
+	for _, q := range a.Quotas {
+		if q.Remaining() == 0 {
+			notes = append(notes, fmt.Sprintf("%s endpoint quota %s is used up (%d of %d)", q.Type, q.QuotaCode, q.Used, q.Limit))
+		}
+	}
+	for _, c := range a.Conflicts {
+		notes = append(notes, fmt.Sprintf("%s endpoint %s for %s is %s", c.Type, c.EndpointID, c.ServiceName, c.State))
+	}
+	return notes
+}
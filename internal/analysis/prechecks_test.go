@@ -0,0 +1,64 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestAnalyzeEndpointsRecordsConflicts(t *testing.T) {
+	endpoints := []types.VPCEndpoint{
+		{ID: "vpce-s3", ServiceName: "com.amazonaws.us-east-1.s3", Type: "Gateway", State: "Failed"},
+		{ID: "vpce-ddb", ServiceName: "com.amazonaws.us-east-1.dynamodb", Type: "Gateway", State: "Available"},
+		{ID: "vpce-old", ServiceName: "com.amazonaws.us-east-1.ecr.api", Type: "Interface", State: "Deleted"},
+		{ID: "vpce-dkr", ServiceName: "com.amazonaws.us-east-1.ecr.dkr", Type: "Interface", State: "Pending"},
+	}
+	a := AnalyzeEndpoints("us-east-1", "vpc-1", endpoints, nil)
+
+	if a.S3Endpoint != nil || a.DynamoEndpoint == nil {
+		t.Errorf("a failed endpoint counted as existing, or an available one did not")
+	}
+	if len(a.Conflicts) != 2 {
+		t.Fatalf("conflicts = %+v, want the failed and the pending endpoint", a.Conflicts)
+	}
+	if a.ECRAPIEndpoint != nil || a.ECRDKREndpoint != nil {
+		t.Error("deleted or pending ECR endpoints counted as existing")
+	}
+
+	commands := strings.Join(a.GetCreateEndpointCommands(), "\n")
+	if !strings.Contains(commands, "--vpc-endpoint-ids 'vpce-s3'") {
+		t.Errorf("expected the failed S3 endpoint to be deleted first:\n%s", commands)
+	}
+	if !strings.Contains(commands, "vpce-dkr for com.amazonaws.us-east-1.ecr.dkr is Pending: wait for it") {
+		t.Errorf("expected a note to wait for the pending endpoint:\n%s", commands)
+	}
+	if strings.Contains(commands, "--service-name 'com.amazonaws.us-east-1.ecr.dkr'") {
+		t.Errorf("a second endpoint is created next to the pending one:\n%s", commands)
+	}
+}
+
+func TestPrecheckCommandsQuota(t *testing.T) {
+	a := &EndpointAnalysis{
+		VPCID:  "vpc-1",
+		Region: "us-east-1",
+		Quotas: []EndpointQuota{
+			{Type: "Interface", QuotaCode: InterfaceEndpointQuotaCode, Used: 49, Limit: 50},
+			{Type: "Gateway", QuotaCode: GatewayEndpointQuotaCode, Used: 3, Limit: 20},
+		},
+	}
+	commands := interfaceEndpointCommands(a, "vpc-1", []string{"com.amazonaws.us-east-1.ssm", "com.amazonaws.us-east-1.kms"}, "'subnet-1'")
+	if len(commands) != 3 || !strings.HasPrefix(commands[0], "# ⚠️ the VPC has 49 of 50 interface endpoints") || !strings.Contains(commands[0], InterfaceEndpointQuotaCode) {
+		t.Errorf("expected a quota warning before the commands, got %v", commands)
+	}
+
+	notes, create := a.precheckCommands("Gateway", []string{"com.amazonaws.us-east-1.s3"})
+	if len(notes) != 0 || len(create) != 1 {
+		t.Errorf("gateway quota has room, got notes %v", notes)
+	}
+
+	a.Quotas[0].Used = 50
+	if got := a.Prechecks(); len(got) != 1 || !strings.Contains(got[0], "used up (50 of 50)") {
+		t.Errorf("Prechecks() = %v", got)
+	}
+}
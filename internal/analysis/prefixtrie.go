@@ -0,0 +1,103 @@
+package analysis
+
+import (
+	"net"
+	"net/netip"
+)
+
+// Services a range in a rangeTrie belongs to.
+const (
+	rangeS3 uint8 = 1 << iota
+	rangeDynamoDB
+	rangeEC2
+)
+
+// rangeTrie is a binary trie of the classifier's CIDR ranges for every
+// service, so classifying an address is one walk of at most 32 (IPv4) or 128
+// (IPv6) steps instead of a Contains per range; the EC2 ranges alone number
+// in the thousands.
+type rangeTrie struct {
+	v4, v6 trieNode
+}
+
+type trieNode struct {
+	child [2]*trieNode
+	// services are those of the ranges ending here; s3 and dynamo are 1 +
+	// the lowest index of the S3 and DynamoDB ones, 0 for none.
+	services   uint8
+	s3, dynamo int
+}
+
+// rangeMatch is every range containing an address.
+type rangeMatch struct {
+	services uint8
+	// s3 and dynamo are the lowest index of the S3 and DynamoDB ranges,
+	// the one a scan in slice order finds first, or -1.
+	s3, dynamo int
+}
+
+func (m rangeMatch) has(service uint8) bool {
+	return m.services&service != 0
+}
+
+// insert adds a range of service; index is its position among the
+// service's ranges.
+func (t *rangeTrie) insert(r *net.IPNet, service uint8, index int) {
+	ones, bits := r.Mask.Size()
+	ip, node := r.IP.To4(), &t.v4
+	if bits == 128 || ip == nil {
+		ip, node = r.IP.To16(), &t.v6
+	}
+	if ip == nil || ones > len(ip)*8 {
+		return
+	}
+	for i := 0; i < ones; i++ {
+		b := ip[i/8] >> (7 - i%8) & 1
+		if node.child[b] == nil {
+			node.child[b] = &trieNode{}
+		}
+		node = node.child[b]
+	}
+	node.services |= service
+	switch service {
+	case rangeS3:
+		node.s3 = lowestIndex(node.s3, index+1)
+	case rangeDynamoDB:
+		node.dynamo = lowestIndex(node.dynamo, index+1)
+	}
+}
+
+// lowestIndex is the lower of two 1-based indexes, where 0 is none.
+func lowestIndex(a, b int) int {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// lookup returns the ranges containing addr.
+func (t *rangeTrie) lookup(addr netip.Addr) rangeMatch {
+	addr = addr.Unmap()
+	var ip []byte
+	node := &t.v4
+	if addr.Is4() {
+		a := addr.As4()
+		ip = a[:]
+	} else {
+		a := addr.As16()
+		ip, node = a[:], &t.v6
+	}
+	var m rangeMatch
+	s3, dynamo := 0, 0
+	for i := 0; node != nil; i++ {
+		m.services |= node.services
+		s3 = lowestIndex(s3, node.s3)
+		dynamo = lowestIndex(dynamo, node.dynamo)
+		if i == len(ip)*8 {
+			break
+		}
+		node = node.child[ip[i/8]>>(7-i%8)&1]
+	}
+	m.s3, m.dynamo = s3-1, dynamo-1
+	return m
+}
@@ -0,0 +1,190 @@
+package analysis
+
+import (
+	"math/rand"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+// trieRanges are ranges per service as the classifier inserts them.
+type trieRanges map[uint8][]*net.IPNet
+
+func newTrie(ranges trieRanges) *rangeTrie {
+	t := &rangeTrie{}
+	for service, rs := range ranges {
+		for i, r := range rs {
+			t.insert(r, service, i)
+		}
+	}
+	return t
+}
+
+// linearLookup is the reference lookup: a Contains per range, in slice order.
+func linearLookup(ranges trieRanges, addr netip.Addr) rangeMatch {
+	ip := net.ParseIP(addr.String())
+	m := rangeMatch{s3: -1, dynamo: -1}
+	for service, rs := range ranges {
+		for i, r := range rs {
+			if !r.Contains(ip) {
+				continue
+			}
+			if service == rangeS3 && m.s3 < 0 {
+				m.s3 = i
+			}
+			if service == rangeDynamoDB && m.dynamo < 0 {
+				m.dynamo = i
+			}
+			m.services |= service
+		}
+	}
+	return m
+}
+
+func cidrs(t *testing.T, prefixes ...string) []*net.IPNet {
+	t.Helper()
+	nets := make([]*net.IPNet, len(prefixes))
+	for i, p := range prefixes {
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nets[i] = n
+	}
+	return nets
+}
+
+// edgeAddrs are the first and last address of every range and the ones
+// just outside it, plus the ends of both address families.
+func edgeAddrs(ranges trieRanges) []netip.Addr {
+	addrs := []netip.Addr{
+		netip.MustParseAddr("0.0.0.0"),
+		netip.MustParseAddr("255.255.255.255"),
+		netip.MustParseAddr("::"),
+		netip.MustParseAddr("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"),
+	}
+	for _, rs := range ranges {
+		for _, r := range rs {
+			ones, _ := r.Mask.Size()
+			first, _ := netip.AddrFromSlice(r.IP)
+			first = first.Unmap()
+			last := lastAddr(netip.PrefixFrom(first, ones))
+			addrs = append(addrs, first, last)
+			if prev := first.Prev(); prev.IsValid() {
+				addrs = append(addrs, prev)
+			}
+			if next := last.Next(); next.IsValid() {
+				addrs = append(addrs, next)
+			}
+			if first.Is4() {
+				// The same address as an IPv4-mapped IPv6 one
+				addrs = append(addrs, netip.AddrFrom16(first.As16()))
+			}
+		}
+	}
+	return addrs
+}
+
+// lastAddr is the highest address of p.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+func TestRangeTrieMatchesLinearScan(t *testing.T) {
+	ranges := trieRanges{
+		// Nested S3 ranges, listed out of order, and a duplicate: the lowest
+		// index containing an address wins
+		rangeS3: cidrs(t,
+			"52.216.0.0/15",
+			"52.216.4.0/24",
+			"52.216.0.0/16",
+			"52.216.4.0/24",
+			"52.216.4.7/32",
+			"2600:1fa0::/28",
+			"2600:1fa0:4000::/40",
+		),
+		// DynamoDB overlapping S3 and itself
+		rangeDynamoDB: cidrs(t,
+			"52.217.0.0/16",
+			"52.216.4.128/25",
+			"3.218.180.0/22",
+			"2600:1f18::/33",
+			"2600:1f18::/64",
+		),
+		// EC2 covering most of the others, including a whole family
+		rangeEC2: cidrs(t,
+			"52.0.0.0/8",
+			"3.208.0.0/12",
+			"3.218.180.0/22",
+			"0.0.0.0/0",
+			"2600:1f00::/24",
+			"2600:1f18:4000::/36",
+			"2a05:d000::/25",
+			"2a05:d07f:ffff:ffff:ffff:ffff:ffff:ffff/128",
+		),
+	}
+	trie := newTrie(ranges)
+	for _, addr := range edgeAddrs(ranges) {
+		if got, want := trie.lookup(addr), linearLookup(ranges, addr); got != want {
+			t.Errorf("lookup(%s) = %+v, want %+v", addr, got, want)
+		}
+	}
+}
+
+func TestRangeTrieMatchesLinearScanRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	// randomPrefix draws from a small space so ranges nest and overlap
+	randomPrefix := func(v6 bool) *net.IPNet {
+		var b []byte
+		if v6 {
+			b = make([]byte, 16)
+			b[0], b[1], b[2] = 0x26, 0x00, byte(rng.Intn(4))
+			rng.Read(b[3:])
+		} else {
+			b = []byte{byte(52 + rng.Intn(2)), byte(rng.Intn(4)), 0, 0}
+			rng.Read(b[2:])
+		}
+		ones := rng.Intn(len(b)*8 + 1)
+		if ones < 8 {
+			ones += 8
+		}
+		mask := net.CIDRMask(ones, len(b)*8)
+		return &net.IPNet{IP: net.IP(b).Mask(mask), Mask: mask}
+	}
+	randomAddr := func(v6 bool) netip.Addr {
+		n := randomPrefix(v6)
+		b := append([]byte(nil), n.IP...)
+		ones, _ := n.Mask.Size()
+		for i := ones; i < len(b)*8; i++ {
+			if rng.Intn(2) == 1 {
+				b[i/8] |= 1 << (7 - i%8)
+			}
+		}
+		addr, _ := netip.AddrFromSlice(b)
+		return addr
+	}
+
+	for round := 0; round < 20; round++ {
+		ranges := trieRanges{}
+		for _, service := range []uint8{rangeS3, rangeDynamoDB, rangeEC2} {
+			for i := 0; i < 1+rng.Intn(60); i++ {
+				ranges[service] = append(ranges[service], randomPrefix(rng.Intn(3) == 0))
+			}
+		}
+		trie := newTrie(ranges)
+		addrs := edgeAddrs(ranges)
+		for i := 0; i < 500; i++ {
+			addrs = append(addrs, randomAddr(rng.Intn(3) == 0))
+		}
+		for _, addr := range addrs {
+			if got, want := trie.lookup(addr), linearLookup(ranges, addr); got != want {
+				t.Fatalf("round %d: lookup(%s) = %+v, want %+v", round, addr, got, want)
+			}
+		}
+	}
+}
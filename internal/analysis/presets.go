@@ -0,0 +1,67 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DurationPreset is a named deep scan duration (--duration quick), with
+// the trade-off of picking it and the accuracy to expect.
+type DurationPreset struct {
+	Name    string `json:"name"`
+	Minutes int    `json:"minutes"`
+	// Length is the collection window as users write it, e.g. "30m".
+	Length   string `json:"length"`
+	Tradeoff string `json:"tradeoff"`
+	Accuracy string `json:"accuracy"`
+}
+
+// DurationPresets are the presets --duration accepts besides minutes, from
+// shortest to longest.
+var DurationPresets = []DurationPreset{
+	{
+		Name: "quick", Minutes: 10, Length: "10m",
+		Tradeoff: "cheapest and fastest; sees steady traffic but can miss batch jobs and peaks",
+		Accuracy: "rough, the monthly projection can be off by half when traffic is bursty",
+	},
+	{
+		Name: "standard", Minutes: 30, Length: "30m",
+		Tradeoff: "balanced cost and coverage for most accounts; catches recurring traffic within the half hour",
+		Accuracy: "good for steady workloads, but daily peaks outside the window are missed",
+	},
+	{
+		Name: "thorough", Minutes: 120, Length: "2h",
+		Tradeoff: "four times the Flow Logs ingestion of standard; covers several job cycles",
+		Accuracy: "reliable for workloads with hourly cycles, still from one time of day",
+	},
+	{
+		Name: "daily-profile", Minutes: 24 * 60, Length: "24h-sampled",
+		Tradeoff: "the highest Flow Logs ingestion cost; run unattended with --auto-approve --auto-cleanup",
+		Accuracy: "best, a whole day with its nightly batches and peaks projected as a daily profile",
+	},
+}
+
+// LookupDurationPreset returns the preset called name.
+func LookupDurationPreset(name string) (DurationPreset, bool) {
+	for _, p := range DurationPresets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return DurationPreset{}, false
+}
+
+// String is the preset as "standard (30m)".
+func (p DurationPreset) String() string {
+	return fmt.Sprintf("%s (%s)", p.Name, p.Length)
+}
+
+// DurationPresetGuide describes every preset, one per line, for help and
+// error messages.
+func DurationPresetGuide() string {
+	var b strings.Builder
+	for _, p := range DurationPresets {
+		fmt.Fprintf(&b, "  %-14s %-12s %s; accuracy: %s\n", p.Name, p.Length, p.Tradeoff, p.Accuracy)
+	}
+	return b.String()
+}
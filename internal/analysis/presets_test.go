@@ -0,0 +1,24 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupDurationPreset(t *testing.T) {
+	for name, minutes := range map[string]int{"quick": 10, "standard": 30, "thorough": 120, "daily-profile": 1440} {
+		p, ok := LookupDurationPreset(name)
+		if !ok || p.Minutes != minutes {
+			t.Errorf("LookupDurationPreset(%q) = %+v, %v; want %d minutes", name, p, ok, minutes)
+		}
+	}
+	if _, ok := LookupDurationPreset("30"); ok {
+		t.Error("minutes must not resolve to a preset")
+	}
+	if got := DurationPresets[3].String(); got != "daily-profile (24h-sampled)" {
+		t.Errorf("String() = %q", got)
+	}
+	if guide := DurationPresetGuide(); strings.Count(guide, "\n") != len(DurationPresets) || !strings.Contains(guide, "thorough") {
+		t.Errorf("unexpected guide:\n%s", guide)
+	}
+}
@@ -3,6 +3,7 @@ package analysis
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	pkgtypes "github.com/doitintl/terminator/pkg/types"
 )
@@ -15,65 +16,100 @@ type Recommendation struct {
 	Benefits    []string
 	Commands    []string
 	Savings     string
+	// MonthlySavings is the projected net saving; only meaningful when SavingsEstimated.
+	MonthlySavings   float64
+	SavingsEstimated bool
+	// Confidence (0-1) and Effort (EffortLow..EffortHigh) feed Score, which
+	// RankRecommendations sets.
+	Confidence float64
+	Effort     int
+	Score      float64
 }
 
-// AnalyzeNATGatewaySetup analyzes NAT Gateway configuration and provides recommendations
-func AnalyzeNATGatewaySetup(nats []pkgtypes.NATGateway) []Recommendation {
+// AnalyzeNATGatewaySetup analyzes NAT Gateway configuration and provides
+// recommendations. vpcAZs, the Availability Zones of each VPC's subnets
+// (see VPCAvailabilityZones), may be nil; with it, VPCs whose zonal NAT
+// Gateways are older than OldNATAge and miss some of those zones are
+// flagged too.
+func AnalyzeNATGatewaySetup(nats []pkgtypes.NATGateway, vpcAZs map[string][]string, now time.Time) []Recommendation {
 	var recommendations []Recommendation
 
 	// Group NAT Gateways by VPC
 	vpcNATs := make(map[string][]pkgtypes.NATGateway)
+	var vpcIDs []string
 	for _, nat := range nats {
+		if _, ok := vpcNATs[nat.VPCID]; !ok {
+			vpcIDs = append(vpcIDs, nat.VPCID)
+		}
 		vpcNATs[nat.VPCID] = append(vpcNATs[nat.VPCID], nat)
 	}
 
 	// Check each VPC for multi-zonal NAT setup
-	for vpcID, vpcNATList := range vpcNATs {
-		zonalCount := 0
+	for _, vpcID := range vpcIDs {
+		var zonal []pkgtypes.NATGateway
 		regionalCount := 0
 
-		for _, nat := range vpcNATList {
+		for _, nat := range vpcNATs[vpcID] {
 			if nat.AvailabilityMode == "regional" {
 				regionalCount++
 			} else {
-				zonalCount++
+				zonal = append(zonal, nat)
 			}
 		}
+		if regionalCount > 0 {
+			continue
+		}
+		aging, isAging := findAgingZonalNAT(zonal, vpcAZs[vpcID], now)
 
-		// Recommend Regional NAT if customer has multiple zonal NATs
-		if zonalCount >= 2 && regionalCount == 0 {
-			recommendations = append(recommendations, Recommendation{
-				Type:     "regional-nat-gateway",
-				Priority: "high",
-				Title:    fmt.Sprintf("Consider Regional NAT Gateway for VPC %s", vpcID),
-				Description: fmt.Sprintf(
-					"You have %d zonal NAT Gateways in this VPC. AWS Regional NAT Gateway can simplify your architecture "+
-						"by replacing multiple zonal NAT Gateways with a single regional resource that automatically spans all Availability Zones.",
-					zonalCount,
-				),
-				Benefits: []string{
-					"Simplified management - single NAT Gateway resource instead of multiple",
-					"No public subnets required - improved security posture",
-					"Automatic multi-AZ expansion - scales to new AZs automatically",
-					"Eliminates cross-AZ data transfer costs ($0.01/GB) when properly configured",
-					"Built-in high availability across all AZs",
-					"Automatic IP scaling - up to 32 IPs per AZ for port exhaustion protection",
-				},
-				Commands: []string{
-					fmt.Sprintf("# Create Regional NAT Gateway for VPC %s", vpcID),
-					fmt.Sprintf("aws ec2 create-nat-gateway \\"),
-					fmt.Sprintf("  --vpc-id %s \\", shellQuote(vpcID)),
-					"  --availability-mode regional \\",
-					"  --connectivity-type public",
-					"",
-					"# After creating Regional NAT Gateway:",
-					"# 1. Update route tables to point to new Regional NAT Gateway",
-					"# 2. Test connectivity from all AZs",
-					"# 3. Delete old zonal NAT Gateways",
-				},
-				Savings: "Eliminates cross-AZ data transfer costs ($0.01/GB) and simplifies operations",
-			})
+		// Recommend Regional NAT if customer has multiple zonal NATs, or an
+		// old one the VPC has outgrown
+		if len(zonal) < 2 && !isAging {
+			continue
+		}
+		rec := Recommendation{
+			Type:     "regional-nat-gateway",
+			Priority: "high",
+			Title:    fmt.Sprintf("Consider Regional NAT Gateway for VPC %s", vpcID),
+			Description: fmt.Sprintf(
+				"You have %d zonal NAT Gateways in this VPC. AWS Regional NAT Gateway can simplify your architecture "+
+					"by replacing multiple zonal NAT Gateways with a single regional resource that automatically spans all Availability Zones.",
+				len(zonal),
+			),
+			Benefits: []string{
+				"Simplified management - single NAT Gateway resource instead of multiple",
+				"No public subnets required - improved security posture",
+				"Automatic multi-AZ expansion - scales to new AZs automatically",
+				"Eliminates cross-AZ data transfer costs ($0.01/GB) when properly configured",
+				"Built-in high availability across all AZs",
+				"Automatic IP scaling - up to 32 IPs per AZ for port exhaustion protection",
+			},
+			Commands: []string{
+				fmt.Sprintf("# Create Regional NAT Gateway for VPC %s", vpcID),
+				fmt.Sprintf("aws ec2 create-nat-gateway \\"),
+				fmt.Sprintf("  --vpc-id %s \\", shellQuote(vpcID)),
+				"  --availability-mode regional \\",
+				"  --connectivity-type public",
+				"",
+				"# After creating Regional NAT Gateway:",
+				"# 1. Update route tables to point to new Regional NAT Gateway",
+				"# 2. Test connectivity from all AZs",
+				"# 3. Delete old zonal NAT Gateways",
+			},
+			Savings:    "Eliminates cross-AZ data transfer costs ($0.01/GB) and simplifies operations",
+			Confidence: defaultConfidence,
+			Effort:     EffortHigh,
+		}
+		if isAging {
+			if len(zonal) < 2 {
+				rec.Priority = "medium"
+				rec.Description = "This VPC has a single zonal NAT Gateway. AWS Regional NAT Gateway can replace it " +
+					"with a single regional resource that automatically spans all Availability Zones."
+			}
+			rec.Description += fmt.Sprintf(" %s was created on %s, and the VPC has since grown subnets in %s without a NAT Gateway of their own: "+
+				"their traffic crosses Availability Zones to reach it and loses egress if its zone fails.",
+				aging.NAT.Label(), NATCreated(aging.NAT, now), strings.Join(aging.UncoveredAZ, ", "))
 		}
+		recommendations = append(recommendations, rec)
 	}
 
 	return recommendations
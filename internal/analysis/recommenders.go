@@ -0,0 +1,83 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	pkgtypes "github.com/doitintl/terminator/pkg/types"
+)
+
+// ScanContext is what a Recommender sees of a finished deep scan.
+type ScanContext struct {
+	Region    string
+	AccountID string
+	// Duration is the Flow Logs sample, in minutes.
+	Duration int
+	NATs     []pkgtypes.NATGateway
+	// VPCID is the deep scanned VPC, whose endpoints Endpoints describes.
+	VPCID     string
+	Endpoints *EndpointAnalysis
+	Traffic   *TrafficStats
+	Cost      *CostEstimate
+	// Findings are the endpoint findings of every VPC with a NAT Gateway.
+	Findings []pkgtypes.Finding
+}
+
+// Recommender generates recommendations beyond the built-in ones, such as
+// organization-specific advice. It must not modify the scan context.
+type Recommender func(ScanContext) ([]Recommendation, error)
+
+type namedRecommender struct {
+	name string
+	fn   Recommender
+}
+
+var (
+	recommendersMu sync.Mutex
+	recommenders   []namedRecommender
+)
+
+// RegisterRecommender adds a Recommender every deep scan runs once its
+// traffic is analyzed. Register from an init function or main, before
+// running the scan.
+func RegisterRecommender(name string, fn Recommender) {
+	recommendersMu.Lock()
+	defer recommendersMu.Unlock()
+	recommenders = append(recommenders, namedRecommender{name, fn})
+}
+
+// RunRecommenders returns the recommendations of every registered
+// Recommender, in registration order. A Recommender that fails or panics
+// is reported in the error and left out; the others still run.
+func RunRecommenders(ctx ScanContext) ([]Recommendation, error) {
+	recommendersMu.Lock()
+	registered := append([]namedRecommender(nil), recommenders...)
+	recommendersMu.Unlock()
+
+	var recs []Recommendation
+	var errs []error
+	for _, r := range registered {
+		got, err := r.run(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recommender %s: %w", r.name, err))
+			continue
+		}
+		for i := range got {
+			if got[i].Type == "" {
+				got[i].Type = r.name
+			}
+		}
+		recs = append(recs, got...)
+	}
+	return recs, errors.Join(errs...)
+}
+
+func (r namedRecommender) run(ctx ScanContext) (recs []Recommendation, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			recs, err = nil, fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return r.fn(ctx)
+}
@@ -0,0 +1,41 @@
+package analysis
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	pkgtypes "github.com/doitintl/terminator/pkg/types"
+)
+
+func TestRunRecommenders(t *testing.T) {
+	defer func(saved []namedRecommender) { recommenders = saved }(recommenders)
+	recommenders = nil
+
+	RegisterRecommender("shared-egress", func(scan ScanContext) ([]Recommendation, error) {
+		if scan.Traffic.Bytes(ServiceOther) == 0 {
+			return nil, nil
+		}
+		return []Recommendation{
+			{Title: "Route egress through the shared egress VPC " + scan.VPCID},
+			{Type: "egress-policy", Title: "Tag NAT Gateways with an owner"},
+		}, nil
+	})
+	RegisterRecommender("broken", func(ScanContext) ([]Recommendation, error) {
+		return nil, errors.New("no inventory API")
+	})
+	RegisterRecommender("panicky", func(scan ScanContext) ([]Recommendation, error) {
+		_ = scan.Endpoints.S3Endpoint // nil EndpointAnalysis
+		return nil, nil
+	})
+
+	stats := &TrafficStats{TotalBytes: 10}
+	stats.AddService(ServiceOther, 10, 1)
+	recs, err := RunRecommenders(ScanContext{VPCID: "vpc-1", NATs: []pkgtypes.NATGateway{{ID: "nat-1"}}, Traffic: stats})
+	if len(recs) != 2 || recs[0].Type != "shared-egress" || recs[0].Title != "Route egress through the shared egress VPC vpc-1" || recs[1].Type != "egress-policy" {
+		t.Errorf("recommendations = %+v", recs)
+	}
+	if err == nil || !strings.Contains(err.Error(), "recommender broken: no inventory API") || !strings.Contains(err.Error(), "recommender panicky: panic") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doitintl/terminator/internal/units"
+)
+
+// publicRegistryHosts are the registry and blob CDN hostnames resolved to
+// recognize image pulls from public registries in Flow Logs destinations.
+var publicRegistryHosts = map[string][]string{
+	"Docker Hub": {"registry-1.docker.io", "auth.docker.io", "production.cloudflare.docker.com"},
+	"Quay":       {"quay.io", "cdn.quay.io", "cdn01.quay.io", "cdn02.quay.io", "cdn03.quay.io"},
+	"GHCR":       {"ghcr.io", "pkg-containers.githubusercontent.com"},
+}
+
+// pullThroughUpstreams are the upstream URLs and credential needs of ECR
+// pull-through cache rules for each recognized registry.
+var pullThroughUpstreams = map[string]struct {
+	prefix      string
+	upstreamURL string
+	needsSecret bool
+}{
+	"Docker Hub": {"docker-hub", "registry-1.docker.io", true},
+	"Quay":       {"quay", "quay.io", false},
+	"GHCR":       {"github", "ghcr.io", true},
+}
+
+// sharedCDNPrefixes are the published ranges of CDNs that front registry
+// blob hosts along with countless other sites (Cloudflare for Docker Hub,
+// Fastly for GHCR). Traffic to their addresses is not evidence of an image
+// pull, so registry hostnames resolving into them are not recognized.
+var sharedCDNPrefixes = mustParsePrefixes(
+	// Cloudflare, https://www.cloudflare.com/ips-v4
+	"173.245.48.0/20", "103.21.244.0/22", "103.22.200.0/22", "103.31.4.0/22",
+	"141.101.64.0/18", "108.162.192.0/18", "190.93.240.0/20", "188.114.96.0/20",
+	"197.234.240.0/22", "198.41.128.0/17", "162.158.0.0/15", "104.16.0.0/13",
+	"104.24.0.0/14", "172.64.0.0/13", "131.0.72.0/22",
+	// Cloudflare, https://www.cloudflare.com/ips-v6
+	"2400:cb00::/32", "2606:4700::/32", "2803:f800::/32", "2405:b500::/32",
+	"2405:8100::/32", "2a06:98c0::/29", "2c0f:f248::/32",
+	// Fastly, https://api.fastly.com/public-ip-list
+	"23.235.32.0/20", "43.249.72.0/22", "103.244.50.0/24", "103.245.222.0/23",
+	"103.245.224.0/24", "104.156.80.0/20", "140.248.64.0/18", "140.248.128.0/17",
+	"146.75.0.0/17", "151.101.0.0/16", "157.52.64.0/18", "167.82.0.0/17",
+	"167.82.128.0/20", "167.82.160.0/20", "167.82.224.0/20", "172.111.64.0/18",
+	"185.31.16.0/22", "199.27.72.0/21", "199.232.0.0/16",
+	"2a04:4e40::/32", "2a04:4e42::/32",
+)
+
+func mustParsePrefixes(cidrs ...string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefixes = append(prefixes, netip.MustParsePrefix(cidr))
+	}
+	return prefixes
+}
+
+// inSharedCDN reports whether addr is in one of sharedCDNPrefixes.
+func inSharedCDN(addr string) bool {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range sharedCDNPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	registryIPsOnce sync.Once
+	registryIPs     map[string]string
+	// registryLookup resolves registry hostnames; tests replace it so they
+	// never reach the network.
+	registryLookup = net.DefaultResolver.LookupHost
+)
+
+// resolvedRegistryIPs resolves publicRegistryHosts once per process and
+// caches the answers for every classifier built after. CDN-hosted
+// registries answer with different addresses over time, so matches are a
+// lower bound.
+func resolvedRegistryIPs() map[string]string {
+	registryIPsOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		registryIPs = ResolveRegistryIPs(ctx, registryLookup)
+	})
+	return registryIPs
+}
+
+// ResolveRegistryIPs maps each address the public registry hostnames resolve
+// to onto the registry's name. Lookup failures are skipped, and so are
+// addresses of shared CDNs, which serve far more than the registry.
+func ResolveRegistryIPs(ctx context.Context, lookup func(ctx context.Context, host string) ([]string, error)) map[string]string {
+	ips := make(map[string]string)
+	for registry, hosts := range publicRegistryHosts {
+		for _, host := range hosts {
+			addrs, err := lookup(ctx, host)
+			if err != nil {
+				continue
+			}
+			for _, addr := range addrs {
+				if inSharedCDN(addr) {
+					continue
+				}
+				ips[addr] = registry
+			}
+		}
+	}
+	return ips
+}
+
+// RegistryPullEstimate compares pulling public images through the NAT Gateway
+// with an ECR pull-through cache reached over ECR interface endpoints.
+type RegistryPullEstimate struct {
+	// MonthlyGBByRegistry is projected pull traffic per public registry.
+	MonthlyGBByRegistry  map[string]float64 `json:"monthly_gb_by_registry"`
+	MonthlyGB            float64            `json:"monthly_gb"`
+	NATCostMonthly       float64            `json:"nat_cost_monthly"`
+	EndpointFixedMonthly float64            `json:"endpoint_fixed_monthly"`
+	EndpointDataMonthly  float64            `json:"endpoint_data_monthly"`
+	NetSavingsMonthly    float64            `json:"net_savings_monthly"`
+}
+
+// EstimateRegistryPullThrough projects public registry pulls to a month and
+// prices moving them behind an ECR pull-through cache. The fixed cost only
+// covers ECR interface endpoints that endpoints reports missing, and all pulled
+// data is charged at the interface endpoint rate, so the savings are a floor.
+// It returns nil when no registry traffic was recognized.
+func EstimateRegistryPullThrough(region string, stats *TrafficStats, collectionMinutes int, endpoints *EndpointAnalysis) *RegistryPullEstimate {
+	if stats == nil || len(stats.RegistryBytes) == 0 || collectionMinutes <= 0 {
+		return nil
+	}
+
+	monthlyMultiplier := 43200.0 / float64(collectionMinutes)
+	est := &RegistryPullEstimate{MonthlyGBByRegistry: make(map[string]float64, len(stats.RegistryBytes))}
+	for registry, bytes := range stats.RegistryBytes {
+		gb := units.BillingGB(bytes) * monthlyMultiplier
+		est.MonthlyGBByRegistry[registry] = gb
+		est.MonthlyGB += gb
+	}
+	est.NATCostMonthly = est.MonthlyGB * NATGatewayPricePerGB(region)
+
+	if endpoints == nil {
+		endpoints = &EndpointAnalysis{Region: region}
+	}
+	_, dataPerGB := endpoints.GetECRInterfaceEndpointPricing()
+	est.EndpointFixedMonthly, _, _, _, _ = endpoints.EstimateECRInterfaceEndpointMonthlyCost(0)
+	est.EndpointDataMonthly = est.MonthlyGB * dataPerGB
+	est.NetSavingsMonthly = est.NATCostMonthly - est.EndpointFixedMonthly - est.EndpointDataMonthly
+	return est
+}
+
+// Registries returns the recognized registries, busiest first.
+func (e *RegistryPullEstimate) Registries() []string {
+	names := make([]string, 0, len(e.MonthlyGBByRegistry))
+	for name := range e.MonthlyGBByRegistry {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if e.MonthlyGBByRegistry[names[i]] != e.MonthlyGBByRegistry[names[j]] {
+			return e.MonthlyGBByRegistry[names[i]] > e.MonthlyGBByRegistry[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Recommendation turns the estimate into a pull-through cache recommendation
+// with the ECR commands for each recognized registry.
+func (e *RegistryPullEstimate) Recommendation(region string) Recommendation {
+	priority := "low"
+	if e.NetSavingsMonthly > 0 {
+		priority = "high"
+	}
+
+	var commands []string
+	for _, registry := range e.Registries() {
+		up, ok := pullThroughUpstreams[registry]
+		if !ok {
+			continue
+		}
+		cmd := fmt.Sprintf("aws ecr create-pull-through-cache-rule --region %s --ecr-repository-prefix %s --upstream-registry-url %s",
+			shellQuote(region), up.prefix, up.upstreamURL)
+		if up.needsSecret {
+			cmd += fmt.Sprintf(" --credential-arn <arn:aws:secretsmanager:...:secret:ecr-pullthroughcache/%s>", up.prefix)
+		}
+		commands = append(commands, cmd)
+	}
+
+	return Recommendation{
+		Type:     "ecr-pull-through-cache",
+		Priority: priority,
+		Title:    "Cache public container images with ECR pull-through cache",
+		Description: fmt.Sprintf("About %.1f GB/month of image pulls from %s go through the NAT Gateway ($%.2f/month). "+
+			"A pull-through cache serves them from ECR, reached over the ecr.api/ecr.dkr interface endpoints and the S3 gateway endpoint.",
+			e.MonthlyGB, strings.Join(e.Registries(), ", "), e.NATCostMonthly),
+		Benefits: []string{
+			"Image layers are served from S3 over the free gateway endpoint instead of the NAT Gateway",
+			"Avoids public registry rate limits (Docker Hub) during scale-out",
+			"ECR storage for cached images ($0.10/GB-month) is not included in the estimate",
+		},
+		Commands:         commands,
+		Savings:          fmt.Sprintf("~$%.2f/month net of $%.2f/month endpoint hours and $%.2f/month endpoint data", e.NetSavingsMonthly, e.EndpointFixedMonthly, e.EndpointDataMonthly),
+		MonthlySavings:   e.NetSavingsMonthly,
+		SavingsEstimated: true,
+		// Registry addresses are resolved at scan time, so matches are a lower bound
+		Confidence: 0.5,
+		Effort:     EffortHigh,
+	}
+}
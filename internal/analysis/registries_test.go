@@ -0,0 +1,138 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+func TestMain(m *testing.M) {
+	// Classifiers built by tests must not resolve registries over the network
+	registryLookup = func(context.Context, string) ([]string, error) {
+		return nil, errors.New("no network in tests")
+	}
+	os.Exit(m.Run())
+}
+
+func TestResolveRegistryIPs(t *testing.T) {
+	lookup := func(_ context.Context, host string) ([]string, error) {
+		switch host {
+		case "registry-1.docker.io":
+			return []string{"54.236.113.205"}, nil
+		case "ghcr.io":
+			return []string{"140.82.112.33"}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	ips := ResolveRegistryIPs(context.Background(), lookup)
+	if ips["54.236.113.205"] != "Docker Hub" || ips["140.82.112.33"] != "GHCR" || len(ips) != 2 {
+		t.Fatalf("unexpected registry IPs: %v", ips)
+	}
+}
+
+func TestResolveRegistryIPsSkipsSharedCDNs(t *testing.T) {
+	lookup := func(_ context.Context, host string) ([]string, error) {
+		switch host {
+		case "registry-1.docker.io":
+			return []string{"54.236.113.205"}, nil
+		case "production.cloudflare.docker.com":
+			return []string{"104.16.100.207", "2606:4700::6810:64cf"}, nil
+		case "pkg-containers.githubusercontent.com":
+			return []string{"185.199.108.154", "151.101.1.194"}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	ips := ResolveRegistryIPs(context.Background(), lookup)
+	if len(ips) != 2 || ips["54.236.113.205"] != "Docker Hub" || ips["185.199.108.154"] != "GHCR" {
+		t.Fatalf("shared CDN addresses should be skipped, got %v", ips)
+	}
+}
+
+func TestRegistryBytesFromAggregatedResults(t *testing.T) {
+	_, ec2Net, _ := net.ParseCIDR("54.236.0.0/15")
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{
+		ecrRanges:  []*net.IPNet{ec2Net},
+		registries: map[string]string{"54.236.113.205": "Docker Hub", "140.82.112.33": "GHCR"},
+	}}
+
+	results := [][]types.ResultField{
+		{{Field: strPtr("resolved_dst"), Value: strPtr("54.236.113.205")}, {Field: strPtr("total_bytes"), Value: strPtr("700")}},
+		{{Field: strPtr("resolved_dst"), Value: strPtr("140.82.112.33")}, {Field: strPtr("total_bytes"), Value: strPtr("300")}},
+		{{Field: strPtr("resolved_dst"), Value: strPtr("8.8.8.8")}, {Field: strPtr("total_bytes"), Value: strPtr("100")}},
+	}
+	stats, err := ta.AnalyzeAggregatedResults(results)
+	if err != nil {
+		t.Fatalf("AnalyzeAggregatedResults returned error: %v", err)
+	}
+	if stats.RegistryBytes["Docker Hub"] != 700 || stats.RegistryBytes["GHCR"] != 300 {
+		t.Fatalf("unexpected registry split: %v", stats.RegistryBytes)
+	}
+	if stats.Bytes(ServiceECR) != 700 || stats.Bytes(ServiceOther) != 400 {
+		t.Fatalf("registry traffic should stay in its service bucket, got ECR=%d Other=%d", stats.Bytes(ServiceECR), stats.Bytes(ServiceOther))
+	}
+}
+
+func TestResolvedShareCountsEachFlowOnce(t *testing.T) {
+	ta := &TrafficAnalyzer{classifier: &TrafficClassifier{
+		registries: map[string]string{"54.236.113.205": "Docker Hub", "3.5.1.1": "Quay"},
+		services:   map[string]string{"52.94.0.10": "sts", "3.5.1.1": "ecr.dkr"},
+	}}
+
+	results := [][]types.ResultField{
+		{{Field: strPtr("resolved_dst"), Value: strPtr("54.236.113.205")}, {Field: strPtr("total_bytes"), Value: strPtr("200")}},
+		{{Field: strPtr("resolved_dst"), Value: strPtr("52.94.0.10")}, {Field: strPtr("total_bytes"), Value: strPtr("100")}},
+		// Both a registry and an AWS API address: counted once
+		{{Field: strPtr("resolved_dst"), Value: strPtr("3.5.1.1")}, {Field: strPtr("total_bytes"), Value: strPtr("100")}},
+		{{Field: strPtr("resolved_dst"), Value: strPtr("8.8.8.8")}, {Field: strPtr("total_bytes"), Value: strPtr("600")}},
+	}
+	stats, err := ta.AnalyzeAggregatedResults(results)
+	if err != nil {
+		t.Fatalf("AnalyzeAggregatedResults returned error: %v", err)
+	}
+	if stats.Accuracy.ResolvedBytes != 400 {
+		t.Fatalf("ResolvedBytes = %d, want 400", stats.Accuracy.ResolvedBytes)
+	}
+	if got := stats.ResolvedPercentage(); got != 40 {
+		t.Fatalf("ResolvedPercentage() = %.1f, want 40", got)
+	}
+}
+
+func TestEstimateRegistryPullThrough(t *testing.T) {
+	gb := int64(1024 * 1024 * 1024)
+	stats := &TrafficStats{RegistryBytes: map[string]int64{"Docker Hub": 2 * gb, "Quay": gb}}
+	endpoints := &EndpointAnalysis{Region: "us-east-1"}
+
+	// 60-minute sample, so 720x to a month
+	est := EstimateRegistryPullThrough("us-east-1", stats, 60, endpoints)
+	assertApprox(t, est.MonthlyGB, 3*720, 0.001, "monthly registry GB")
+	assertApprox(t, est.NATCostMonthly, 3*720*0.045, 0.001, "NAT cost")
+	// Two missing ECR endpoints in one AZ: 2 * $0.01 * 720 hours
+	assertApprox(t, est.EndpointFixedMonthly, 14.4, 0.001, "endpoint fixed cost")
+	assertApprox(t, est.EndpointDataMonthly, 3*720*0.01, 0.001, "endpoint data cost")
+	assertApprox(t, est.NetSavingsMonthly, 3*720*0.035-14.4, 0.001, "net savings")
+
+	if got := est.Registries(); len(got) != 2 || got[0] != "Docker Hub" {
+		t.Errorf("Registries() = %v, want Docker Hub first", got)
+	}
+	rec := est.Recommendation("us-east-1")
+	if rec.Priority != "high" || len(rec.Commands) != 2 {
+		t.Fatalf("unexpected recommendation: %+v", rec)
+	}
+	if !strings.Contains(rec.Commands[0], "--upstream-registry-url registry-1.docker.io --credential-arn") {
+		t.Errorf("Docker Hub rule should need a credential: %s", rec.Commands[0])
+	}
+	if strings.Contains(rec.Commands[1], "--credential-arn") {
+		t.Errorf("Quay rule should not need a credential: %s", rec.Commands[1])
+	}
+
+	if EstimateRegistryPullThrough("us-east-1", &TrafficStats{}, 60, endpoints) != nil {
+		t.Error("expected no estimate without registry traffic")
+	}
+}
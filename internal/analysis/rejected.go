@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// maxRejectedPeers is how many destinations and sources RejectedTraffic keeps.
+const maxRejectedPeers = 10
+
+// RejectedPeer is an address rejected flows went to or came from, with the
+// ports they tried ("tcp/22", see PortKey).
+type RejectedPeer struct {
+	Address string   `json:"address"`
+	Ports   []string `json:"ports"`
+	Flows   int64    `json:"flows"`
+	Bytes   int64    `json:"bytes"`
+}
+
+// RejectedTraffic summarizes the REJECT records of the sample: flows blocked
+// by security groups or network ACLs, which point at misconfigured rules or
+// blocked callbacks rather than NAT cost.
+type RejectedTraffic struct {
+	Flows        int64          `json:"flows"`
+	Bytes        int64          `json:"bytes"`
+	Destinations []RejectedPeer `json:"top_destinations"`
+	Sources      []RejectedPeer `json:"top_sources"`
+}
+
+// ParseRejectedTraffic reads Logs Insights rows grouped by resolved_src,
+// resolved_dst, dst_port and protocol with flow_count and total_bytes
+// columns. It returns nil when nothing was rejected.
+func ParseRejectedTraffic(results [][]types.ResultField) *RejectedTraffic {
+	rejected := &RejectedTraffic{}
+	destinations := make(map[string]*RejectedPeer)
+	sources := make(map[string]*RejectedPeer)
+	for _, row := range results {
+		var src, dst, port, protocol string
+		var flows, bytes int64
+		for _, field := range row {
+			if field.Field == nil || field.Value == nil {
+				continue
+			}
+			switch *field.Field {
+			case "resolved_src":
+				src = *field.Value
+			case "resolved_dst":
+				dst = *field.Value
+			case "dst_port":
+				port = *field.Value
+			case "protocol":
+				protocol = *field.Value
+			case "flow_count":
+				flows, _ = strconv.ParseInt(*field.Value, 10, 64)
+			case "total_bytes":
+				bytes, _ = parseAggregatedBytes(*field.Value)
+			}
+		}
+		if flows == 0 {
+			continue
+		}
+		key := PortKey(protocol, port)
+		rejected.Flows += flows
+		rejected.Bytes += bytes
+		addRejectedPeer(destinations, dst, key, flows, bytes)
+		addRejectedPeer(sources, src, key, flows, bytes)
+	}
+	if rejected.Flows == 0 {
+		return nil
+	}
+	rejected.Destinations = topRejectedPeers(destinations)
+	rejected.Sources = topRejectedPeers(sources)
+	return rejected
+}
+
+func addRejectedPeer(peers map[string]*RejectedPeer, address, port string, flows, bytes int64) {
+	if address == "" || address == "-" {
+		address = "unknown"
+	}
+	p, ok := peers[address]
+	if !ok {
+		p = &RejectedPeer{Address: address}
+		peers[address] = p
+	}
+	p.Flows += flows
+	p.Bytes += bytes
+	for _, existing := range p.Ports {
+		if existing == port {
+			return
+		}
+	}
+	p.Ports = append(p.Ports, port)
+}
+
+// topRejectedPeers returns the peers with the most rejected flows.
+func topRejectedPeers(peers map[string]*RejectedPeer) []RejectedPeer {
+	out := make([]RejectedPeer, 0, len(peers))
+	for _, p := range peers {
+		sort.Strings(p.Ports)
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Flows != out[j].Flows {
+			return out[i].Flows > out[j].Flows
+		}
+		return out[i].Address < out[j].Address
+	})
+	if len(out) > maxRejectedPeers {
+		out = out[:maxRejectedPeers]
+	}
+	return out
+}
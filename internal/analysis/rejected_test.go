@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+func TestParseRejectedTraffic(t *testing.T) {
+	row := func(src, dst, port, protocol string, flows int) []types.ResultField {
+		return []types.ResultField{
+			{Field: strPtr("resolved_src"), Value: strPtr(src)},
+			{Field: strPtr("resolved_dst"), Value: strPtr(dst)},
+			{Field: strPtr("dst_port"), Value: strPtr(port)},
+			{Field: strPtr("protocol"), Value: strPtr(protocol)},
+			{Field: strPtr("flow_count"), Value: strPtr(fmt.Sprint(flows))},
+			{Field: strPtr("total_bytes"), Value: strPtr(fmt.Sprint(flows * 60))},
+		}
+	}
+	results := [][]types.ResultField{
+		row("10.0.1.5", "198.51.100.7", "4444", "6", 40),
+		row("10.0.1.6", "198.51.100.7", "8443", "6", 10),
+		row("203.0.113.9", "10.0.1.5", "22", "6", 30),
+	}
+	for i := 0; i < 12; i++ {
+		results = append(results, row(fmt.Sprintf("192.0.2.%d", i), "10.0.2.1", "3389", "6", 1))
+	}
+
+	rejected := ParseRejectedTraffic(results)
+	if rejected == nil {
+		t.Fatal("expected rejected traffic")
+	}
+	if rejected.Flows != 92 || rejected.Bytes != 92*60 {
+		t.Errorf("totals = %d flows, %d bytes", rejected.Flows, rejected.Bytes)
+	}
+	top := rejected.Destinations[0]
+	if top.Address != "198.51.100.7" || top.Flows != 50 || strings.Join(top.Ports, ",") != "tcp/4444,tcp/8443" {
+		t.Errorf("top destination = %+v", top)
+	}
+	if len(rejected.Sources) != maxRejectedPeers || rejected.Sources[0].Address != "10.0.1.5" {
+		t.Errorf("sources = %+v, want %d led by 10.0.1.5", rejected.Sources, maxRejectedPeers)
+	}
+
+	if ParseRejectedTraffic(nil) != nil {
+		t.Error("no rows should mean no rejected traffic")
+	}
+}
@@ -0,0 +1,78 @@
+package analysis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// VPCRemediation is the commands that fix the gateway endpoint findings of
+// one VPC.
+type VPCRemediation struct {
+	VPCID                  string   `json:"vpc_id"`
+	CreateEndpointCommands []string `json:"create_endpoint_commands,omitempty"`
+	AddRouteCommands       []string `json:"add_route_commands,omitempty"`
+}
+
+// gatewayServiceSuffixes are the gateway endpoint service names of each
+// finding Service.
+var gatewayServiceSuffixes = map[string]string{
+	"S3":       ".s3",
+	"DynamoDB": ".dynamodb",
+}
+
+// RemediateVPCs returns the commands that fix the missing-endpoint and
+// misconfigured-endpoint findings of every VPC but skipVPC, whose detailed
+// EndpointAnalysis already has its own. analyze returns the endpoint
+// analysis of a VPC; VPCs it fails for are left out. Only services a
+// finding names get commands, so accepted findings stay unfixed.
+func RemediateVPCs(ctx context.Context, analyze func(ctx context.Context, vpcID string) (*EndpointAnalysis, error), findings []types.Finding, skipVPC string) []VPCRemediation {
+	var vpcIDs []string
+	services := map[string]map[string]bool{}
+	for _, f := range findings {
+		if f.Type != "missing-endpoint" && f.Type != "misconfigured-endpoint" {
+			continue
+		}
+		if _, ok := gatewayServiceSuffixes[f.Service]; !ok || f.VPCID == "" || f.VPCID == skipVPC {
+			continue
+		}
+		if services[f.VPCID] == nil {
+			services[f.VPCID] = map[string]bool{}
+			vpcIDs = append(vpcIDs, f.VPCID)
+		}
+		services[f.VPCID][f.Service] = true
+	}
+
+	var out []VPCRemediation
+	for _, vpcID := range vpcIDs {
+		a, err := analyze(ctx, vpcID)
+		if err != nil || a == nil {
+			continue
+		}
+		// Narrow a copy to the services with findings.
+		fix := *a
+		fix.MissingEndpoints, fix.MissingRoutes = nil, nil
+		for _, name := range a.MissingEndpoints {
+			for svc := range services[vpcID] {
+				if strings.HasSuffix(name, gatewayServiceSuffixes[svc]) {
+					fix.MissingEndpoints = append(fix.MissingEndpoints, name)
+				}
+			}
+		}
+		for _, mr := range a.MissingRoutes {
+			if services[vpcID][mr.Service] {
+				fix.MissingRoutes = append(fix.MissingRoutes, mr)
+			}
+		}
+		r := VPCRemediation{
+			VPCID:                  vpcID,
+			CreateEndpointCommands: fix.GetCreateGatewayEndpointCommands(),
+			AddRouteCommands:       fix.GetAddRouteCommands(),
+		}
+		if len(r.CreateEndpointCommands) > 0 || len(r.AddRouteCommands) > 0 {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestRemediateVPCsCoversEveryVPCWithFindings(t *testing.T) {
+	natRT := func(id string) types.RouteTable {
+		return types.RouteTable{ID: id, Routes: []types.Route{{DestinationCIDR: "0.0.0.0/0", Target: "nat-1", TargetType: "nat-gateway"}}}
+	}
+	analyses := map[string]*EndpointAnalysis{
+		"vpc-2": AnalyzeEndpoints("us-east-1", "vpc-2", nil, []types.RouteTable{natRT("rtb-2")}),
+		"vpc-3": AnalyzeEndpoints("us-east-1", "vpc-3", []types.VPCEndpoint{
+			{ID: "vpce-s3", ServiceName: "com.amazonaws.us-east-1.s3", Type: "Gateway", State: "available", RouteTables: []string{"rtb-3a"}},
+		}, []types.RouteTable{natRT("rtb-3a"), natRT("rtb-3b")}),
+	}
+	analyze := func(_ context.Context, vpcID string) (*EndpointAnalysis, error) {
+		if a, ok := analyses[vpcID]; ok {
+			return a, nil
+		}
+		return nil, errors.New("not found")
+	}
+	findings := []types.Finding{
+		{Type: "missing-endpoint", VPCID: "vpc-1", Service: "S3"},
+		{Type: "missing-endpoint", VPCID: "vpc-2", Service: "S3"},
+		{Type: "misconfigured-endpoint", VPCID: "vpc-3", Service: "S3"},
+		{Type: "missing-endpoint", VPCID: "vpc-4", Service: "DynamoDB"},
+		{Type: "dns-misconfigured", VPCID: "vpc-2", Service: "DynamoDB"},
+	}
+
+	got := RemediateVPCs(context.Background(), analyze, findings, "vpc-1")
+	if len(got) != 2 || got[0].VPCID != "vpc-2" || got[1].VPCID != "vpc-3" {
+		t.Fatalf("unexpected remediation: %+v", got)
+	}
+	if len(got[0].CreateEndpointCommands) != 1 || !strings.Contains(got[0].CreateEndpointCommands[0], "com.amazonaws.us-east-1.s3") || len(got[0].AddRouteCommands) != 0 {
+		t.Errorf("vpc-2 should only create the S3 endpoint: %+v", got[0])
+	}
+	if len(got[1].CreateEndpointCommands) != 0 || len(got[1].AddRouteCommands) != 1 || !strings.Contains(got[1].AddRouteCommands[0], "'rtb-3b'") {
+		t.Errorf("vpc-3 should only associate rtb-3b: %+v", got[1])
+	}
+}
@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// S3Attribution is the S3 traffic CloudTrail data events recorded for one
+// bucket and principal through the NAT Gateways' public IPs.
+type S3Attribution struct {
+	Bucket    string `json:"bucket"`
+	Principal string `json:"principal"`
+	Bytes     int64  `json:"bytes"`
+	Requests  int    `json:"requests"`
+}
+
+// ParseS3Attribution reads Logs Insights rows grouped by bucket and principal
+// with bytes_in, bytes_out and requests columns. Rows are returned largest first.
+func ParseS3Attribution(results [][]types.ResultField) []S3Attribution {
+	var out []S3Attribution
+	for _, row := range results {
+		var a S3Attribution
+		for _, field := range row {
+			if field.Field == nil || field.Value == nil {
+				continue
+			}
+			switch *field.Field {
+			case "bucket":
+				a.Bucket = *field.Value
+			case "principal":
+				a.Principal = *field.Value
+			case "bytes_in", "bytes_out":
+				if n, err := parseAggregatedBytes(*field.Value); err == nil {
+					a.Bytes += n
+				}
+			case "requests":
+				if n, err := strconv.Atoi(*field.Value); err == nil {
+					a.Requests = n
+				}
+			}
+		}
+		if a.Bucket == "" {
+			continue
+		}
+		out = append(out, a)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Bytes > out[j].Bytes })
+	return out
+}
+
+// PrincipalName shortens a CloudTrail userIdentity ARN to "role <name>" or
+// "user <name>"; other values are returned unchanged.
+func PrincipalName(arn string) string {
+	_, resource, ok := strings.Cut(arn, ":assumed-role/")
+	if ok {
+		name, _, _ := strings.Cut(resource, "/")
+		return "role " + name
+	}
+	if _, resource, ok := strings.Cut(arn, ":user/"); ok {
+		return "user " + resource[strings.LastIndex(resource, "/")+1:]
+	}
+	if _, resource, ok := strings.Cut(arn, ":role/"); ok {
+		return "role " + resource[strings.LastIndex(resource, "/")+1:]
+	}
+	if arn == "" {
+		return "unknown"
+	}
+	return arn
+}
@@ -0,0 +1,50 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+func row(kv ...string) []types.ResultField {
+	var out []types.ResultField
+	for i := 0; i+1 < len(kv); i += 2 {
+		k, v := kv[i], kv[i+1]
+		out = append(out, types.ResultField{Field: &k, Value: &v})
+	}
+	return out
+}
+
+func TestParseS3Attribution(t *testing.T) {
+	results := [][]types.ResultField{
+		row("bucket", "logs-archive", "principal", "arn:aws:iam::123456789012:user/ops/backup", "bytes_in", "1024", "requests", "3"),
+		row("bucket", "analytics-raw", "principal", "arn:aws:sts::123456789012:assumed-role/etl-runner/i-0abc", "bytes_in", "512", "bytes_out", "4096", "requests", "42"),
+		row("principal", "arn:aws:sts::123456789012:assumed-role/etl-runner/i-0abc", "bytes_out", "99"),
+	}
+
+	got := ParseS3Attribution(results)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 attributions (row without a bucket dropped), got %d: %+v", len(got), got)
+	}
+	if got[0].Bucket != "analytics-raw" || got[0].Bytes != 4608 || got[0].Requests != 42 {
+		t.Errorf("largest attribution = %+v, want analytics-raw with 4608 bytes in 42 requests", got[0])
+	}
+	if got[1].Bucket != "logs-archive" || got[1].Bytes != 1024 {
+		t.Errorf("second attribution = %+v, want logs-archive with 1024 bytes", got[1])
+	}
+}
+
+func TestPrincipalName(t *testing.T) {
+	cases := map[string]string{
+		"arn:aws:sts::123456789012:assumed-role/etl-runner/i-0abc": "role etl-runner",
+		"arn:aws:iam::123456789012:user/ops/backup":                "user backup",
+		"arn:aws:iam::123456789012:role/service-role/loader":       "role loader",
+		"":           "unknown",
+		"AWSService": "AWSService",
+	}
+	for arn, want := range cases {
+		if got := PrincipalName(arn); got != want {
+			t.Errorf("PrincipalName(%q) = %q, want %q", arn, got, want)
+		}
+	}
+}
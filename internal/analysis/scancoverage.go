@@ -0,0 +1,102 @@
+package analysis
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// Coverage statuses of NAT Gateways and VPCs in a ScanCoverage.
+const (
+	// CoverageMeasured: Flow Logs were sampled and their traffic is in the
+	// report.
+	CoverageMeasured = "measured"
+	// CoverageConfigChecked: only the configuration was checked; no traffic
+	// was measured.
+	CoverageConfigChecked = "config-checked"
+	// CoverageSkipped: discovered, but left out of the scan.
+	CoverageSkipped = "skipped"
+)
+
+// ScanCoverage states exactly what a deep scan measured: every discovered
+// NAT Gateway and VPC, and why any was not measured.
+type ScanCoverage struct {
+	// Source is where the sampled Flow Logs came from.
+	Source      string          `json:"source"`
+	NATGateways []CoverageEntry `json:"nat_gateways"`
+	VPCs        []CoverageEntry `json:"vpcs"`
+}
+
+// CoverageEntry is how a scan covered one NAT Gateway or VPC.
+type CoverageEntry struct {
+	ID string `json:"id"`
+	// VPCID is the VPC of a NAT Gateway.
+	VPCID  string `json:"vpc_id,omitempty"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SkippedNAT is a discovered NAT Gateway a scan left out, and why.
+type SkippedNAT struct {
+	NAT    types.NATGateway
+	Reason string
+}
+
+// BuildScanCoverage states the coverage of a scan of nats, whose Flow
+// Logs came from source (e.g. "Flow Logs created for this scan").
+// unmeasured are the reasons, by NAT Gateway ID, that some of nats had no
+// traffic measured, such as a failed query; skipped are the discovered NAT
+// Gateways left out. detailedVPC is the VPC whose endpoints were analyzed
+// in detail; the others only get endpoint findings.
+func BuildScanCoverage(nats []types.NATGateway, source string, unmeasured map[string]string, skipped []SkippedNAT, detailedVPC string) *ScanCoverage {
+	c := &ScanCoverage{Source: source}
+	var vpcIDs []string
+	known := map[string]bool{}
+	measured := map[string]bool{}
+	scanned := map[string]bool{}
+	skipReasons := map[string][]string{}
+	seen := func(vpcID string) {
+		if !known[vpcID] {
+			known[vpcID] = true
+			vpcIDs = append(vpcIDs, vpcID)
+		}
+	}
+
+	for _, nat := range nats {
+		seen(nat.VPCID)
+		scanned[nat.VPCID] = true
+		entry := CoverageEntry{ID: nat.ID, VPCID: nat.VPCID, Status: CoverageMeasured}
+		if reason, ok := unmeasured[nat.ID]; ok {
+			entry.Status, entry.Reason = CoverageConfigChecked, reason
+		} else {
+			measured[nat.VPCID] = true
+		}
+		c.NATGateways = append(c.NATGateways, entry)
+	}
+	for _, s := range skipped {
+		seen(s.NAT.VPCID)
+		if !slices.Contains(skipReasons[s.NAT.VPCID], s.Reason) {
+			skipReasons[s.NAT.VPCID] = append(skipReasons[s.NAT.VPCID], s.Reason)
+		}
+		c.NATGateways = append(c.NATGateways, CoverageEntry{ID: s.NAT.ID, VPCID: s.NAT.VPCID, Status: CoverageSkipped, Reason: s.Reason})
+	}
+
+	for _, vpcID := range vpcIDs {
+		entry := CoverageEntry{ID: vpcID}
+		detail := "endpoint findings only; rescan with --vpc-id " + vpcID + " for the detailed endpoint analysis"
+		if vpcID == detailedVPC {
+			detail = "endpoints analyzed in detail"
+		}
+		switch {
+		case measured[vpcID]:
+			entry.Status, entry.Reason = CoverageMeasured, "traffic sampled; "+detail
+		case scanned[vpcID]:
+			entry.Status, entry.Reason = CoverageConfigChecked, "no Flow Logs traffic measured; "+detail
+		default:
+			entry.Status, entry.Reason = CoverageSkipped, strings.Join(skipReasons[vpcID], "; ")
+		}
+		c.VPCs = append(c.VPCs, entry)
+	}
+	return c
+}
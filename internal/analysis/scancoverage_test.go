@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestBuildScanCoverage(t *testing.T) {
+	nats := []types.NATGateway{{ID: "nat-a", VPCID: "vpc-1"}, {ID: "nat-b", VPCID: "vpc-2"}, {ID: "nat-c", VPCID: "vpc-3"}}
+	unmeasured := map[string]string{"nat-c": "Flow Logs query failed, traffic excluded: query timed out"}
+	skipped := []SkippedNAT{
+		{NAT: types.NATGateway{ID: "nat-d", VPCID: "vpc-1"}, Reason: "not selected for this scan"},
+		{NAT: types.NATGateway{ID: "nat-e", VPCID: "vpc-4"}, Reason: "VPC excluded by --exclude-vpc-ids"},
+		{NAT: types.NATGateway{ID: "nat-f", VPCID: "vpc-4"}, Reason: "VPC excluded by --exclude-vpc-ids"},
+	}
+
+	c := BuildScanCoverage(nats, "Flow Logs created for this scan", unmeasured, skipped, "vpc-1")
+	wantNATs := map[string]string{"nat-a": CoverageMeasured, "nat-b": CoverageMeasured, "nat-c": CoverageConfigChecked, "nat-d": CoverageSkipped, "nat-e": CoverageSkipped, "nat-f": CoverageSkipped}
+	if len(c.NATGateways) != len(wantNATs) {
+		t.Fatalf("NAT coverage = %+v", c.NATGateways)
+	}
+	for _, e := range c.NATGateways {
+		if e.Status != wantNATs[e.ID] {
+			t.Errorf("%s status = %s, want %s", e.ID, e.Status, wantNATs[e.ID])
+		}
+	}
+	if c.NATGateways[0].Reason != "" || c.NATGateways[2].Reason != "Flow Logs query failed, traffic excluded: query timed out" {
+		t.Errorf("NAT reasons = %q, %q", c.NATGateways[0].Reason, c.NATGateways[2].Reason)
+	}
+
+	want := []CoverageEntry{
+		{ID: "vpc-1", Status: CoverageMeasured, Reason: "traffic sampled; endpoints analyzed in detail"},
+		{ID: "vpc-2", Status: CoverageMeasured, Reason: "traffic sampled; endpoint findings only; rescan with --vpc-id vpc-2 for the detailed endpoint analysis"},
+		{ID: "vpc-3", Status: CoverageConfigChecked, Reason: "no Flow Logs traffic measured; endpoint findings only; rescan with --vpc-id vpc-3 for the detailed endpoint analysis"},
+		{ID: "vpc-4", Status: CoverageSkipped, Reason: "VPC excluded by --exclude-vpc-ids"},
+	}
+	if len(c.VPCs) != len(want) {
+		t.Fatalf("VPC coverage = %+v", c.VPCs)
+	}
+	for i := range want {
+		if c.VPCs[i] != want[i] {
+			t.Errorf("VPC %d = %+v, want %+v", i, c.VPCs[i], want[i])
+		}
+	}
+}
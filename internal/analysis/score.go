@@ -0,0 +1,61 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// Effort levels used by the prioritization score; EffortSize and
+// EffortHours size them for planning.
+const (
+	EffortLow    = 1 // route table or configuration change only
+	EffortMedium = 2 // a new paid resource, no workload changes
+	EffortHigh   = 3 // workloads or architecture must change
+)
+
+// Defaults for items created without an explicit confidence or effort.
+const (
+	defaultConfidence = 0.5
+	defaultEffort     = EffortMedium
+)
+
+// Score is the prioritization score: projected monthly savings weighted by
+// confidence (0-1) and divided by effort. Unpriced items score 0.
+func Score(monthlySavings, confidence float64, effort int) float64 {
+	if confidence <= 0 {
+		confidence = defaultConfidence
+	}
+	if effort <= 0 {
+		effort = defaultEffort
+	}
+	return monthlySavings * confidence / float64(effort)
+}
+
+// RankRecommendations scores each recommendation and orders them highest
+// score first; equal scores keep their original order.
+func RankRecommendations(recs []Recommendation) []Recommendation {
+	out := append([]Recommendation(nil), recs...)
+	for i := range out {
+		out[i].Score = 0
+		if out[i].SavingsEstimated {
+			out[i].Score = Score(out[i].MonthlySavings, out[i].Confidence, out[i].Effort)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// RankFindings scores each finding and orders them highest score first;
+// equal scores keep their original order.
+func RankFindings(findings []types.Finding) []types.Finding {
+	out := append([]types.Finding(nil), findings...)
+	for i := range out {
+		out[i].Score = 0
+		if out[i].SavingsEstimated {
+			out[i].Score = Score(out[i].MonthlySavings, out[i].Confidence, out[i].Effort)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
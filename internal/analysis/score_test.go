@@ -0,0 +1,43 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestScore(t *testing.T) {
+	assertApprox(t, Score(100, 0.9, EffortLow), 90, 0.001, "gateway endpoint score")
+	assertApprox(t, Score(100, 0.5, EffortHigh), 100*0.5/3, 0.001, "architecture change score")
+	assertApprox(t, Score(100, 0, 0), 100*defaultConfidence/defaultEffort, 0.001, "unset confidence and effort use defaults")
+}
+
+func TestRankRecommendationsPutsBiggestWinFirst(t *testing.T) {
+	recs := []Recommendation{
+		{Title: "generic advice"},
+		{Title: "regional NAT", MonthlySavings: 30, SavingsEstimated: true, Confidence: 0.5, Effort: EffortHigh},
+		{Title: "endpoint bundle", MonthlySavings: 80, SavingsEstimated: true, Confidence: 0.6, Effort: EffortMedium},
+	}
+
+	ranked := RankRecommendations(recs)
+	if ranked[0].Title != "endpoint bundle" || ranked[1].Title != "regional NAT" || ranked[2].Title != "generic advice" {
+		t.Fatalf("unexpected order: %+v", ranked)
+	}
+	assertApprox(t, ranked[0].Score, 24, 0.001, "bundle score")
+	if recs[0].Title != "generic advice" {
+		t.Error("ranking should not reorder the input slice")
+	}
+}
+
+func TestRankFindingsWeighsEffort(t *testing.T) {
+	findings := []types.Finding{
+		{Title: "interface endpoints", MonthlySavings: 50, SavingsEstimated: true, Confidence: 0.6, Effort: EffortMedium},
+		{Title: "S3 gateway", MonthlySavings: 40, SavingsEstimated: true, Confidence: 0.9, Effort: EffortLow},
+		{Title: "unpriced", Confidence: 0.9, Effort: EffortLow},
+	}
+
+	ranked := RankFindings(findings)
+	if ranked[0].Title != "S3 gateway" || ranked[1].Title != "interface endpoints" || ranked[2].Score != 0 {
+		t.Fatalf("unexpected order: %+v", ranked)
+	}
+}
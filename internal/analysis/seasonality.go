@@ -0,0 +1,119 @@
+package analysis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/doitintl/terminator/internal/units"
+)
+
+// hoursPerMonth matches the 43,200-minute month used by CalculateCosts.
+const hoursPerMonth = 720.0
+
+// TrafficSample is the traffic observed over one window of known length, such
+// as a backfilled day or one deep scan collection period.
+type TrafficSample struct {
+	Start    time.Time
+	Duration time.Duration
+	Stats    *TrafficStats
+}
+
+// seasonVolume is traffic in GB, either observed or projected to a month.
+type seasonVolume struct {
+	totalGB, s3GB, dynamoGB float64
+	// crossS3GB and crossDynamoGB are the S3 and DynamoDB traffic to other
+	// regions than the estimate's, which a gateway endpoint does not carry.
+	crossS3GB, crossDynamoGB float64
+}
+
+func (v seasonVolume) scale(f float64) seasonVolume {
+	return seasonVolume{v.totalGB * f, v.s3GB * f, v.dynamoGB * f, v.crossS3GB * f, v.crossDynamoGB * f}
+}
+
+func (v seasonVolume) plus(o seasonVolume) seasonVolume {
+	return seasonVolume{v.totalGB + o.totalGB, v.s3GB + o.s3GB, v.dynamoGB + o.dynamoGB,
+		v.crossS3GB + o.crossS3GB, v.crossDynamoGB + o.crossDynamoGB}
+}
+
+// estimate prices a monthly volume like CalculateCosts does.
+func (v seasonVolume) estimate(region string) *CostEstimate {
+	estimate := monthlyCostEstimate(region, v.totalGB, v.s3GB, v.dynamoGB)
+	estimate.excludeCrossRegionS3(v.crossS3GB)
+	estimate.excludeCrossRegionDynamo(v.crossDynamoGB)
+	return estimate
+}
+
+type seasonBucket struct {
+	hours float64
+	seasonVolume
+	days map[string]bool
+}
+
+func (b *seasonBucket) add(region string, s TrafficSample) {
+	b.hours += s.Duration.Hours()
+	b.totalGB += units.BillingGB(s.Stats.TotalBytes)
+	b.s3GB += units.BillingGB(s.Stats.Bytes(ServiceS3))
+	b.dynamoGB += units.BillingGB(s.Stats.Bytes(ServiceDynamoDB))
+	b.crossS3GB += units.BillingGB(s.Stats.CrossRegionS3Bytes(region))
+	b.crossDynamoGB += units.BillingGB(s.Stats.CrossRegionDynamoBytes(region))
+	b.days[s.Start.UTC().Format("2006-01-02")] = true
+}
+
+// monthly scales the bucket's hourly rates to the given share of a month.
+func (b *seasonBucket) monthly(share float64) seasonVolume {
+	if b.hours == 0 {
+		return seasonVolume{}
+	}
+	return b.seasonVolume.scale(hoursPerMonth * share / b.hours)
+}
+
+// CalculateSeasonalCosts projects monthly cost from several samples using a
+// weekly seasonality model: weekday and weekend traffic rates are measured
+// separately and weighted 5/7 and 2/7. When only weekdays or only weekends
+// were sampled it falls back to linear extrapolation of everything observed.
+// Samples without any records are ignored.
+func CalculateSeasonalCosts(region string, samples []TrafficSample) *CostEstimate {
+	weekday := &seasonBucket{days: map[string]bool{}}
+	weekend := &seasonBucket{days: map[string]bool{}}
+	used := 0
+	for _, s := range samples {
+		if s.Stats == nil || s.Stats.TotalRecords == 0 || s.Duration <= 0 {
+			continue
+		}
+		used++
+		switch s.Start.UTC().Weekday() {
+		case time.Saturday, time.Sunday:
+			weekend.add(region, s)
+		default:
+			weekday.add(region, s)
+		}
+	}
+
+	if used == 0 {
+		estimate := monthlyCostEstimate(region, 0, 0, 0)
+		estimate.ProjectionMethod = "no samples with traffic"
+		return estimate
+	}
+
+	if len(weekday.days) > 0 && len(weekend.days) > 0 {
+		estimate := weekday.monthly(5.0 / 7.0).plus(weekend.monthly(2.0 / 7.0)).estimate(region)
+		estimate.ProjectionMethod = fmt.Sprintf("projected using %s across %s + %s",
+			plural(used, "sample"), plural(len(weekday.days), "weekday"), plural(len(weekend.days), "weekend day"))
+		return estimate
+	}
+
+	only, label := weekday, "weekdays only"
+	if len(weekend.days) > 0 {
+		only, label = weekend, "weekend days only"
+	}
+	estimate := only.monthly(1).estimate(region)
+	estimate.ProjectionMethod = fmt.Sprintf("linear extrapolation of %s (%s, no weekly seasonality)", plural(used, "sample"), label)
+	return estimate
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
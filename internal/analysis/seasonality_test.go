@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+)
+
+func gbSample(day time.Time, hours int, totalGB int64) TrafficSample {
+	const gb = 1024 * 1024 * 1024
+	var records int
+	if totalGB > 0 {
+		records = 1
+	}
+	return TrafficSample{
+		Start:    day,
+		Duration: time.Duration(hours) * time.Hour,
+		Stats:    &TrafficStats{TotalRecords: records, TotalBytes: totalGB * gb, Services: map[Service]ServiceStats{ServiceS3: {Bytes: totalGB * gb / 2}}},
+	}
+}
+
+func TestCalculateSeasonalCostsWeighsWeekdaysAndWeekends(t *testing.T) {
+	mon := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // Monday
+	tue := mon.AddDate(0, 0, 1)
+	sat := mon.AddDate(0, 0, 5)
+
+	est := CalculateSeasonalCosts("us-east-1", []TrafficSample{
+		gbSample(mon, 12, 12),
+		gbSample(mon.Add(12*time.Hour), 12, 12),
+		gbSample(tue, 24, 24),
+		gbSample(sat, 24, 0), // no records: ignored
+		gbSample(sat, 24, 2),
+	})
+
+	// Weekday 1 GB/h, weekend 1/12 GB/h.
+	want := 720*5.0/7.0*1 + 720*2.0/7.0/12
+	assertApprox(t, est.TotalDataGB, want, 0.001, "seasonal monthly GB")
+	assertApprox(t, est.S3DataGB, want/2, 0.001, "seasonal monthly S3 GB")
+	if got := "projected using 4 samples across 2 weekdays + 1 weekend day"; est.ProjectionMethod != got {
+		t.Fatalf("ProjectionMethod = %q, want %q", est.ProjectionMethod, got)
+	}
+}
+
+func TestCalculateSeasonalCostsFallsBackToLinear(t *testing.T) {
+	mon := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	est := CalculateSeasonalCosts("us-east-1", []TrafficSample{gbSample(mon, 24, 24)})
+
+	assertApprox(t, est.TotalDataGB, 720, 0.001, "linear monthly GB")
+	if est.ProjectionMethod != "linear extrapolation of 1 sample (weekdays only, no weekly seasonality)" {
+		t.Fatalf("unexpected ProjectionMethod %q", est.ProjectionMethod)
+	}
+}
+
+func TestSeasonalAndBackfillSavingsExcludeCrossRegionS3(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	mon := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // Monday
+	weekday := gbSample(mon, 24, 24)
+	weekday.Stats.S3BytesByRegion = map[string]int64{"us-east-1": 6 * gb, "eu-west-1": 6 * gb}
+	weekend := gbSample(mon.AddDate(0, 0, 5), 24, 24)
+	weekend.Stats.S3BytesByRegion = map[string]int64{"us-east-1": 12 * gb}
+
+	est := CalculateSeasonalCosts("us-east-1", []TrafficSample{weekday, weekend})
+
+	// Weekdays send 0.25 GB/h to eu-west-1 buckets, weekends none.
+	cross := 720 * 5.0 / 7.0 * 0.25
+	assertApprox(t, est.S3DataGB, 360, 0.001, "seasonal monthly S3 GB")
+	assertApprox(t, est.CrossRegionS3GB, cross, 0.001, "seasonal cross-region S3 GB")
+	assertApprox(t, est.S3SavingsMonthly, (360-cross)*0.045, 0.001, "seasonal S3 savings")
+	assertApprox(t, est.TotalSavingsMonthly, (360-cross)*0.045, 0.001, "seasonal total savings")
+
+	month := MonthlyTraffic{Month: "2024-01", Days: 1, Stats: weekday.Stats}
+	assertApprox(t, month.GatewaySavings("us-east-1"), 6*0.045, 0.0001, "backfill gateway savings")
+}
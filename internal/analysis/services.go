@@ -0,0 +1,199 @@
+package analysis
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Service is a class of destinations the classifier attributes traffic to.
+type Service string
+
+const (
+	ServiceS3       Service = "s3"
+	ServiceDynamoDB Service = "dynamodb"
+	ServiceECR      Service = "ecr"
+	ServiceOther    Service = "other"
+)
+
+// Services are the classified services in the order reports list them.
+var Services = []Service{ServiceS3, ServiceDynamoDB, ServiceECR, ServiceOther}
+
+var serviceNames = map[Service]string{
+	ServiceS3:       "S3",
+	ServiceDynamoDB: "DynamoDB",
+	ServiceECR:      "ECR",
+	ServiceOther:    "Other",
+}
+
+// Name is the service as reports print it.
+func (s Service) Name() string {
+	if name, ok := serviceNames[s]; ok {
+		return name
+	}
+	return string(s)
+}
+
+// classified returns the Service of a classifier result; addresses that
+// are not IPs count as other.
+func classified(service string) Service {
+	if _, ok := serviceNames[Service(service)]; ok {
+		return Service(service)
+	}
+	return ServiceOther
+}
+
+// ServiceStats is the traffic classified as one service.
+type ServiceStats struct {
+	Bytes   int64
+	Records int
+}
+
+// Bytes is the traffic classified as service.
+func (ts *TrafficStats) Bytes(service Service) int64 {
+	return ts.Services[service].Bytes
+}
+
+// Records is the number of flows classified as service.
+func (ts *TrafficStats) Records(service Service) int {
+	return ts.Services[service].Records
+}
+
+// Percentage is service's share of TotalBytes.
+func (ts *TrafficStats) Percentage(service Service) float64 {
+	if ts.TotalBytes == 0 {
+		return 0
+	}
+	return float64(ts.Bytes(service)) / float64(ts.TotalBytes) * 100
+}
+
+// AddService counts bytes over records flows towards service, without
+// changing the totals.
+func (ts *TrafficStats) AddService(service Service, bytes int64, records int) {
+	if ts.Services == nil {
+		ts.Services = make(map[Service]ServiceStats)
+	}
+	s := ts.Services[service]
+	s.Bytes += bytes
+	s.Records += records
+	ts.Services[service] = s
+}
+
+// ServiceOrder returns the services to report: every one of Services, in
+// that order, then any other service with traffic by name.
+func (ts *TrafficStats) ServiceOrder() []Service {
+	order := append([]Service(nil), Services...)
+	var extra []Service
+	for s := range ts.Services {
+		if _, ok := serviceNames[s]; !ok {
+			extra = append(extra, s)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+	return append(order, extra...)
+}
+
+// legacyTrafficStats are the per-service fields of reports written before
+// TrafficStats.Services. They are still written for the built-in services,
+// for consumers of the JSON report, and read from older reports.
+type legacyTrafficStats struct {
+	S3Bytes       int64
+	DynamoBytes   int64
+	ECRBytes      int64
+	OtherBytes    int64
+	S3Records     int
+	DynamoRecords int
+	ECRRecords    int
+	OtherRecords  int
+}
+
+func (ts TrafficStats) MarshalJSON() ([]byte, error) {
+	type plain TrafficStats
+	return json.Marshal(struct {
+		legacyTrafficStats
+		plain
+	}{
+		legacyTrafficStats{
+			S3Bytes: ts.Bytes(ServiceS3), DynamoBytes: ts.Bytes(ServiceDynamoDB), ECRBytes: ts.Bytes(ServiceECR), OtherBytes: ts.Bytes(ServiceOther),
+			S3Records: ts.Records(ServiceS3), DynamoRecords: ts.Records(ServiceDynamoDB), ECRRecords: ts.Records(ServiceECR), OtherRecords: ts.Records(ServiceOther),
+		},
+		plain(ts),
+	})
+}
+
+func (ts *TrafficStats) UnmarshalJSON(data []byte) error {
+	type plain TrafficStats
+	var v struct {
+		legacyTrafficStats
+		plain
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*ts = TrafficStats(v.plain)
+	if ts.Services == nil {
+		l := v.legacyTrafficStats
+		for _, s := range []struct {
+			service Service
+			bytes   int64
+			records int
+		}{
+			{ServiceS3, l.S3Bytes, l.S3Records},
+			{ServiceDynamoDB, l.DynamoBytes, l.DynamoRecords},
+			{ServiceECR, l.ECRBytes, l.ECRRecords},
+			{ServiceOther, l.OtherBytes, l.OtherRecords},
+		} {
+			if s.bytes != 0 || s.records != 0 {
+				ts.AddService(s.service, s.bytes, s.records)
+			}
+		}
+	}
+	return nil
+}
+
+// legacySourceIPStats are the per-service fields of SourceIPStats before
+// SourceIPStats.Services, kept the same way as legacyTrafficStats.
+type legacySourceIPStats struct {
+	S3     int64
+	Dynamo int64
+	ECR    int64
+	Other  int64
+}
+
+func (s SourceIPStats) MarshalJSON() ([]byte, error) {
+	type plain SourceIPStats
+	return json.Marshal(struct {
+		plain
+		legacySourceIPStats
+	}{
+		plain(s),
+		legacySourceIPStats{s.Services[ServiceS3], s.Services[ServiceDynamoDB], s.Services[ServiceECR], s.Services[ServiceOther]},
+	})
+}
+
+func (s *SourceIPStats) UnmarshalJSON(data []byte) error {
+	type plain SourceIPStats
+	var v struct {
+		plain
+		legacySourceIPStats
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = SourceIPStats(v.plain)
+	if s.Services == nil {
+		l := v.legacySourceIPStats
+		for service, bytes := range map[Service]int64{ServiceS3: l.S3, ServiceDynamoDB: l.Dynamo, ServiceECR: l.ECR, ServiceOther: l.Other} {
+			if bytes != 0 {
+				s.add(service, bytes)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SourceIPStats) add(service Service, bytes int64) {
+	if s.Services == nil {
+		s.Services = make(map[Service]int64)
+	}
+	s.Services[service] += bytes
+}
@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestServiceOrder(t *testing.T) {
+	var stats TrafficStats
+	stats.AddService("rds", 10, 1)
+	stats.AddService(ServiceS3, 30, 2)
+	stats.AddService("kafka", 5, 1)
+	stats.TotalBytes = 45
+
+	want := []Service{ServiceS3, ServiceDynamoDB, ServiceECR, ServiceOther, "kafka", "rds"}
+	if got := stats.ServiceOrder(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ServiceOrder = %v, want %v", got, want)
+	}
+	if stats.Bytes(ServiceS3) != 30 || stats.Records(ServiceS3) != 2 || stats.Bytes(ServiceDynamoDB) != 0 {
+		t.Errorf("services = %+v", stats.Services)
+	}
+	if pct := stats.Percentage("rds"); pct < 22.2 || pct > 22.3 {
+		t.Errorf("rds share = %.2f", pct)
+	}
+	if Service("rds").Name() != "rds" || ServiceDynamoDB.Name() != "DynamoDB" {
+		t.Error("unexpected service names")
+	}
+}
+
+func TestTrafficStatsJSON(t *testing.T) {
+	// A report written before TrafficStats.Services.
+	legacy := `{"S3Bytes": 600, "ECRBytes": 100, "OtherBytes": 300, "TotalBytes": 1000, "S3Records": 6, "OtherRecords": 3, "TotalRecords": 9,
+		"SourceIPs": {"10.0.1.10": {"Bytes": 1000, "Records": 9, "S3": 600, "Dynamo": 0, "ECR": 100, "Other": 300}}}`
+	var stats TrafficStats
+	if err := json.Unmarshal([]byte(legacy), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Bytes(ServiceS3) != 600 || stats.Records(ServiceS3) != 6 || stats.Bytes(ServiceECR) != 100 || stats.TotalBytes != 1000 {
+		t.Fatalf("legacy stats = %+v", stats)
+	}
+	if _, ok := stats.Services[ServiceDynamoDB]; ok {
+		t.Error("a service without traffic should not be added")
+	}
+	if src := stats.SourceIPs["10.0.1.10"]; src == nil || src.Services[ServiceOther] != 300 || src.Records != 9 {
+		t.Fatalf("legacy source = %+v", src)
+	}
+
+	// Written and read back, the legacy fields stay and Services wins.
+	stats.AddService("rds", 50, 1)
+	data, err := json.Marshal(&stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["S3Bytes"] != 600.0 || fields["DynamoBytes"] != 0.0 {
+		t.Errorf("legacy fields = %v", fields)
+	}
+	var back TrafficStats
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Services, stats.Services) || !reflect.DeepEqual(back.SourceIPs, stats.SourceIPs) {
+		t.Errorf("round trip = %+v, want %+v", back, stats)
+	}
+}
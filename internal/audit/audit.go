@@ -0,0 +1,148 @@
+// Package audit records every mutating AWS API call termiNATor makes, one
+// JSON line per call, as change-management evidence of what a run did, who
+// ran it and whether each call succeeded.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doitintl/terminator/internal/manifest"
+)
+
+// OperatorEnv overrides the operator identity, e.g. with the CI actor.
+const OperatorEnv = "TERMINAT_OPERATOR"
+
+// Event is one API call.
+type Event struct {
+	Time      time.Time       `json:"time"`
+	RunID     string          `json:"run_id,omitempty"`
+	Operator  string          `json:"operator"`
+	Command   string          `json:"command,omitempty"`
+	Service   string          `json:"service"`
+	Operation string          `json:"operation"`
+	Region    string          `json:"region,omitempty"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// Log appends events to a JSON Lines file. It is safe for concurrent use;
+// a nil *Log records nothing.
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	runID    string
+	operator string
+	command  string
+}
+
+// New returns a log writing to path, stamping events with the run and who
+// ran it.
+func New(path, runID, operator, command string) *Log {
+	return &Log{path: path, runID: runID, operator: operator, command: command}
+}
+
+// Path returns the file events are appended to.
+func (l *Log) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// SetRunID stamps later events with runID.
+func (l *Log) SetRunID(runID string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.runID = runID
+}
+
+// Record appends e, filling in the run, operator and command.
+func (l *Log) Record(e Event) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.RunID, e.Operator, e.Command = l.runID, l.operator, l.command
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// DefaultPath is one file per day, audit/<date>.jsonl under
+// $TERMINAT_STATE_DIR or <user config dir>/terminat.
+func DefaultPath(now time.Time) (string, error) {
+	dir := os.Getenv(manifest.StateDirEnv)
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("no directory for the audit log: %w", err)
+		}
+		dir = filepath.Join(base, "terminat")
+	}
+	return filepath.Join(dir, "audit", now.Format("2006-01-02")+".jsonl"), nil
+}
+
+// Operator identifies who runs termiNATor: $TERMINAT_OPERATOR, or the local
+// user and host.
+func Operator() string {
+	if v := strings.TrimSpace(os.Getenv(OperatorEnv)); v != "" {
+		return v
+	}
+	name := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		name += "@" + host
+	}
+	return name
+}
+
+// readOnlyPrefixes start the names of API operations that change nothing.
+var readOnlyPrefixes = []string{"Describe", "Get", "List", "Filter", "Lookup", "Search", "Head", "Assume", "Validate"}
+
+// readOnlyOperations change nothing despite their names: they run Logs
+// Insights queries.
+var readOnlyOperations = map[string]bool{
+	"StartQuery": true,
+	"StopQuery":  true,
+}
+
+// Mutating reports whether an API operation can change AWS resources.
+func Mutating(operation string) bool {
+	if readOnlyOperations[operation] {
+		return false
+	}
+	for _, p := range readOnlyPrefixes {
+		if strings.HasPrefix(operation, p) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMutating(t *testing.T) {
+	for op, want := range map[string]bool{
+		"CreateFlowLogs":               true,
+		"DeleteLogGroup":               true,
+		"PutObject":                    true,
+		"ExecuteChangeSet":             true,
+		"DescribeNatGateways":          false,
+		"GetQueryResults":              false,
+		"ListTagsForResource":          false,
+		"FilterLogEvents":              false,
+		"AssumeRole":                   false,
+		"StartQuery":                   false,
+		"StopQuery":                    false,
+		"StartNetworkInsightsAnalysis": true,
+	} {
+		if got := Mutating(op); got != want {
+			t.Errorf("Mutating(%q) = %v, want %v", op, got, want)
+		}
+	}
+}
+
+func TestRecordAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "2024-06-01.jsonl")
+	log := New(path, "terminat-1", "jdoe@laptop", "terminat scan deep")
+	if err := log.Record(Event{Service: "EC2", Operation: "CreateFlowLogs", Params: json.RawMessage(`{"ResourceIds":["nat-1"]}`)}); err != nil {
+		t.Fatal(err)
+	}
+	log.SetRunID("terminat-2")
+	if err := log.Record(Event{Service: "CloudWatch Logs", Operation: "DeleteLogGroup", Error: "access denied"}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var events []Event
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Event
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		events = append(events, e)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if e := events[0]; e.RunID != "terminat-1" || e.Operator != "jdoe@laptop" || e.Command != "terminat scan deep" || e.Time.IsZero() {
+		t.Errorf("first event not stamped: %+v", e)
+	}
+	if !strings.Contains(string(events[0].Params), "nat-1") {
+		t.Errorf("params = %s", events[0].Params)
+	}
+	if e := events[1]; e.RunID != "terminat-2" || e.Error != "access denied" {
+		t.Errorf("second event = %+v", e)
+	}
+}
+
+func TestNilLogRecordsNothing(t *testing.T) {
+	var log *Log
+	if err := log.Record(Event{Operation: "CreateFlowLogs"}); err != nil {
+		t.Fatal(err)
+	}
+	if log.Path() != "" {
+		t.Error("nil log has a path")
+	}
+}
+
+func TestOperatorEnv(t *testing.T) {
+	t.Setenv(OperatorEnv, " ci-bot ")
+	if got := Operator(); got != "ci-bot" {
+		t.Errorf("Operator() = %q, want ci-bot", got)
+	}
+	t.Setenv(OperatorEnv, "")
+	if got := Operator(); got == "" {
+		t.Error("Operator() is empty without the env var")
+	}
+}
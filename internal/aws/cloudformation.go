@@ -0,0 +1,167 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	pkgtypes "github.com/doitintl/terminator/pkg/types"
+)
+
+// CloudFormationClient provisions deep scan Flow Logs through a short-lived stack
+type CloudFormationClient struct {
+	client *cloudformation.Client
+}
+
+// NewCloudFormationClient creates a new CloudFormation client wrapper
+func NewCloudFormationClient(client *cloudformation.Client) *CloudFormationClient {
+	return &CloudFormationClient{client: client}
+}
+
+// FlowLogsStack describes the resources a deep scan provisions through CloudFormation
+type FlowLogsStack struct {
+	StackName       string
+	LogGroupName    string
+	DeliveryRoleArn string
+	RunID           string
+	NATs            []pkgtypes.NATGateway
+	Tags            map[string]string // propagated by CloudFormation to every resource
+}
+
+// CreateFlowLogsStack creates the stack through a change set and returns the
+// IDs of the Flow Logs it created. A stack that fails to create is deleted;
+// its log group is removed by the rollback, but not when the stack is
+// deleted before the rollback ran, so callers delete it too.
+func (c *CloudFormationClient) CreateFlowLogsStack(ctx context.Context, stack FlowLogsStack) ([]string, error) {
+	body, err := flowLogsTemplate(stack)
+	if err != nil {
+		return nil, err
+	}
+
+	changeSetName := stack.StackName + "-create"
+	_, err = c.client.CreateChangeSet(ctx, &cloudformation.CreateChangeSetInput{
+		StackName:     &stack.StackName,
+		ChangeSetName: &changeSetName,
+		ChangeSetType: types.ChangeSetTypeCreate,
+		TemplateBody:  &body,
+		Description:   stringPtr("termiNATor temporary Flow Logs for NAT Gateway analysis"),
+		Tags:          stackTags(stack),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create change set: %w", err)
+	}
+
+	changeSetWaiter := cloudformation.NewChangeSetCreateCompleteWaiter(c.client)
+	if err := changeSetWaiter.Wait(ctx, &cloudformation.DescribeChangeSetInput{
+		StackName:     &stack.StackName,
+		ChangeSetName: &changeSetName,
+	}, 5*time.Minute); err != nil {
+		_ = c.DeleteFlowLogsStack(ctx, stack.StackName)
+		return nil, fmt.Errorf("change set did not become ready: %w", err)
+	}
+
+	if _, err := c.client.ExecuteChangeSet(ctx, &cloudformation.ExecuteChangeSetInput{
+		StackName:     &stack.StackName,
+		ChangeSetName: &changeSetName,
+	}); err != nil {
+		_ = c.DeleteFlowLogsStack(ctx, stack.StackName)
+		return nil, fmt.Errorf("failed to execute change set: %w", err)
+	}
+
+	stackWaiter := cloudformation.NewStackCreateCompleteWaiter(c.client)
+	if err := stackWaiter.Wait(ctx, &cloudformation.DescribeStacksInput{StackName: &stack.StackName}, 10*time.Minute); err != nil {
+		_ = c.DeleteFlowLogsStack(ctx, stack.StackName)
+		return nil, fmt.Errorf("stack %s did not reach CREATE_COMPLETE: %w", stack.StackName, err)
+	}
+
+	resources, err := c.client.DescribeStackResources(ctx, &cloudformation.DescribeStackResourcesInput{StackName: &stack.StackName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe stack resources: %w", err)
+	}
+
+	var flowLogIDs []string
+	for _, r := range resources.StackResources {
+		if stringValue(r.ResourceType) == "AWS::EC2::FlowLog" && r.PhysicalResourceId != nil {
+			flowLogIDs = append(flowLogIDs, *r.PhysicalResourceId)
+		}
+	}
+	return flowLogIDs, nil
+}
+
+// DeleteFlowLogsStack deletes the stack and waits for it to be gone. The log
+// group is retained so the caller can decide whether to keep the data.
+func (c *CloudFormationClient) DeleteFlowLogsStack(ctx context.Context, stackName string) error {
+	if _, err := c.client.DeleteStack(ctx, &cloudformation.DeleteStackInput{StackName: &stackName}); err != nil {
+		return fmt.Errorf("failed to delete stack: %w", err)
+	}
+
+	waiter := cloudformation.NewStackDeleteCompleteWaiter(c.client)
+	if err := waiter.Wait(ctx, &cloudformation.DescribeStacksInput{StackName: &stackName}, 10*time.Minute); err != nil {
+		return fmt.Errorf("stack %s did not reach DELETE_COMPLETE: %w", stackName, err)
+	}
+	return nil
+}
+
+func stackTags(stack FlowLogsStack) []types.Tag {
+	var tags []types.Tag
+	for _, k := range sortedKeys(stack.Tags) {
+		if k == "CreatedBy" || k == "RunId" {
+			continue
+		}
+		tags = append(tags, types.Tag{Key: stringPtr(k), Value: stringPtr(stack.Tags[k])})
+	}
+	return append(tags,
+		types.Tag{Key: stringPtr("CreatedBy"), Value: stringPtr("termiNATor")},
+		types.Tag{Key: stringPtr("RunId"), Value: stringPtr(stack.RunID)},
+	)
+}
+
+// flowLogsTemplate renders the stack template: one log group (retained on
+// stack deletion, but not when the stack's creation rolls back) and one Flow
+// Log per NAT Gateway, matching CreateFlowLogs.
+func flowLogsTemplate(stack FlowLogsStack) (string, error) {
+	resources := map[string]any{
+		"LogGroup": map[string]any{
+			"Type":           "AWS::Logs::LogGroup",
+			"DeletionPolicy": "RetainExceptOnCreate",
+			"Properties": map[string]any{
+				"LogGroupName":    stack.LogGroupName,
+				"RetentionInDays": 1,
+			},
+		},
+	}
+
+	for i, nat := range stack.NATs {
+		resourceType, resourceID := flowLogTarget(nat)
+		resources[fmt.Sprintf("FlowLog%d", i)] = map[string]any{
+			"Type":      "AWS::EC2::FlowLog",
+			"DependsOn": "LogGroup",
+			"Properties": map[string]any{
+				"ResourceId":               resourceID,
+				"ResourceType":             string(resourceType),
+				"TrafficType":              "ALL",
+				"LogDestinationType":       "cloud-watch-logs",
+				"LogGroupName":             stack.LogGroupName,
+				"DeliverLogsPermissionArn": stack.DeliveryRoleArn,
+				"LogFormat":                FlowLogFormat,
+				"MaxAggregationInterval":   FlowLogAggregationInterval,
+				"Tags": []map[string]string{
+					{"Key": "NatGatewayId", "Value": nat.ID},
+				},
+			},
+		}
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"AWSTemplateFormatVersion": "2010-09-09",
+		"Description":              "termiNATor temporary Flow Logs for NAT Gateway analysis",
+		"Resources":                resources,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render stack template: %w", err)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,35 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	pkgtypes "github.com/doitintl/terminator/pkg/types"
+)
+
+func TestFlowLogsTemplateLogGroupPolicy(t *testing.T) {
+	body, err := flowLogsTemplate(FlowLogsStack{
+		LogGroupName: "/aws/vpc/flowlogs/terminat-1700000000",
+		NATs:         []pkgtypes.NATGateway{{ID: "nat-a", NetworkInterfaceID: "eni-a"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tmpl struct {
+		Resources map[string]struct {
+			Type           string
+			DeletionPolicy string
+		}
+	}
+	if err := json.Unmarshal([]byte(body), &tmpl); err != nil {
+		t.Fatal(err)
+	}
+	// Kept when the stack is deleted after the scan, but not left behind
+	// when creating the stack rolls back
+	if got := tmpl.Resources["LogGroup"].DeletionPolicy; got != "RetainExceptOnCreate" {
+		t.Errorf("log group DeletionPolicy = %q, want RetainExceptOnCreate", got)
+	}
+	if got := tmpl.Resources["FlowLog0"].Type; got != "AWS::EC2::FlowLog" {
+		t.Errorf("FlowLog0 type = %q", got)
+	}
+}
@@ -2,28 +2,53 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
 )
 
+// CloudWatchLogsAPI is the part of the CloudWatch Logs API the client
+// calls, so tests can stand in for it.
+type CloudWatchLogsAPI interface {
+	cloudwatchlogs.DescribeLogGroupsAPIClient
+	CreateLogGroup(ctx context.Context, params *cloudwatchlogs.CreateLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	DeleteLogGroup(ctx context.Context, params *cloudwatchlogs.DeleteLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogGroupOutput, error)
+	PutRetentionPolicy(ctx context.Context, params *cloudwatchlogs.PutRetentionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutRetentionPolicyOutput, error)
+	DescribeLogStreams(ctx context.Context, params *cloudwatchlogs.DescribeLogStreamsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogStreamsOutput, error)
+	FilterLogEvents(ctx context.Context, params *cloudwatchlogs.FilterLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.FilterLogEventsOutput, error)
+	StartQuery(ctx context.Context, params *cloudwatchlogs.StartQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StartQueryOutput, error)
+	GetQueryResults(ctx context.Context, params *cloudwatchlogs.GetQueryResultsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error)
+	StopQuery(ctx context.Context, params *cloudwatchlogs.StopQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StopQueryOutput, error)
+	ListTagsForResource(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error)
+	TagResource(ctx context.Context, params *cloudwatchlogs.TagResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.TagResourceOutput, error)
+}
+
 // CloudWatchLogsClient wraps AWS CloudWatch Logs API calls
 type CloudWatchLogsClient struct {
-	client *cloudwatchlogs.Client
+	client CloudWatchLogsAPI
+
+	mu           sync.Mutex
+	bytesScanned float64 // Logs Insights bytes scanned by completed queries
 }
 
 // NewCloudWatchLogsClient creates a new CloudWatch Logs client wrapper
-func NewCloudWatchLogsClient(client *cloudwatchlogs.Client) *CloudWatchLogsClient {
+func NewCloudWatchLogsClient(client CloudWatchLogsAPI) *CloudWatchLogsClient {
 	return &CloudWatchLogsClient{client: client}
 }
 
-// CreateLogGroup creates a CloudWatch Logs log group
-func (c *CloudWatchLogsClient) CreateLogGroup(ctx context.Context, logGroupName string) error {
+// CreateLogGroup creates a CloudWatch Logs log group with the given tags
+func (c *CloudWatchLogsClient) CreateLogGroup(ctx context.Context, logGroupName string, tags map[string]string) error {
 	input := &cloudwatchlogs.CreateLogGroupInput{
 		LogGroupName: &logGroupName,
 	}
+	if len(tags) > 0 {
+		input.Tags = tags
+	}
 
 	_, err := c.client.CreateLogGroup(ctx, input)
 	if err != nil {
@@ -66,6 +91,53 @@ func (c *CloudWatchLogsClient) DeleteLogGroup(ctx context.Context, logGroupName
 	return nil
 }
 
+// LogGroupTags returns the tags of every log group whose name starts with
+// prefix, by log group name.
+func (c *CloudWatchLogsClient) LogGroupTags(ctx context.Context, prefix string) (map[string]map[string]string, error) {
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(c.client, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: &prefix,
+	})
+	tags := map[string]map[string]string{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list log groups: %w", err)
+		}
+		for _, lg := range page.LogGroups {
+			if lg.LogGroupName == nil || lg.LogGroupArn == nil {
+				continue
+			}
+			resp, err := c.client.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{ResourceArn: lg.LogGroupArn})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tags of %s: %w", *lg.LogGroupName, err)
+			}
+			tags[*lg.LogGroupName] = resp.Tags
+		}
+	}
+	return tags, nil
+}
+
+// TagLogGroup adds tags to a log group, replacing the values of existing
+// keys.
+func (c *CloudWatchLogsClient) TagLogGroup(ctx context.Context, logGroupName string, tags map[string]string) error {
+	resp, err := c.client.DescribeLogGroups(ctx, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: &logGroupName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe log group: %w", err)
+	}
+	for _, lg := range resp.LogGroups {
+		if lg.LogGroupName == nil || *lg.LogGroupName != logGroupName || lg.LogGroupArn == nil {
+			continue
+		}
+		if _, err := c.client.TagResource(ctx, &cloudwatchlogs.TagResourceInput{ResourceArn: lg.LogGroupArn, Tags: tags}); err != nil {
+			return fmt.Errorf("failed to tag log group: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("log group not found: %s", logGroupName)
+}
+
 // LogGroupStats contains statistics about a log group
 type LogGroupStats struct {
 	StoredBytes int64
@@ -109,7 +181,12 @@ func (c *CloudWatchLogsClient) StartQuery(ctx context.Context, logGroupName stri
 		QueryString:  &queryString,
 	}
 
-	result, err := c.client.StartQuery(ctx, input)
+	var result *cloudwatchlogs.StartQueryOutput
+	err := retryThrottled(ctx, func() error {
+		var err error
+		result, err = c.client.StartQuery(ctx, input)
+		return err
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to start query: %w", err)
 	}
@@ -117,11 +194,24 @@ func (c *CloudWatchLogsClient) StartQuery(ctx context.Context, logGroupName stri
 	return *result.QueryId, nil
 }
 
-// WaitForQueryResults waits for query to complete and returns results
+// queryTimeout bounds how long WaitForQueryResults waits for one query: a
+// little past the 60 minutes after which Logs Insights times it out itself,
+// in case its status never leaves Scheduled or Running.
+const queryTimeout = 65 * time.Minute
+
+// WaitForQueryResults waits for query to complete and returns results. A
+// query given up on, because ctx was cancelled or it ran past queryTimeout,
+// is stopped so it doesn't keep scanning, and billing, on its own.
 func (c *CloudWatchLogsClient) WaitForQueryResults(ctx context.Context, queryID string) ([][]types.ResultField, error) {
+	deadline := time.Now().Add(queryTimeout)
 	for {
-		result, err := c.client.GetQueryResults(ctx, &cloudwatchlogs.GetQueryResultsInput{
-			QueryId: &queryID,
+		var result *cloudwatchlogs.GetQueryResultsOutput
+		err := retryThrottled(ctx, func() error {
+			var err error
+			result, err = c.client.GetQueryResults(ctx, &cloudwatchlogs.GetQueryResultsInput{
+				QueryId: &queryID,
+			})
+			return err
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query results: %w", err)
@@ -129,15 +219,105 @@ func (c *CloudWatchLogsClient) WaitForQueryResults(ctx context.Context, queryID
 
 		switch result.Status {
 		case types.QueryStatusComplete:
+			c.addBytesScanned(result.Statistics)
 			return result.Results, nil
-		case types.QueryStatusFailed, types.QueryStatusCancelled:
+		case types.QueryStatusFailed, types.QueryStatusCancelled, types.QueryStatusTimeout:
+			// Failed queries are still billed for what they scanned
+			c.addBytesScanned(result.Statistics)
 			return nil, fmt.Errorf("query failed with status: %s", result.Status)
-		case types.QueryStatusRunning, types.QueryStatusScheduled:
-			time.Sleep(2 * time.Second)
+		default:
+			if time.Now().After(deadline) {
+				c.stopQuery(queryID)
+				return nil, fmt.Errorf("query still %s after %s", result.Status, queryTimeout)
+			}
+			select {
+			case <-ctx.Done():
+				c.stopQuery(queryID)
+				return nil, ctx.Err()
+			case <-time.After(2 * time.Second):
+			}
 		}
 	}
 }
 
+// stopQuery stops a running query on a fresh context, since the scan's may
+// already be cancelled. Errors are ignored: the query ends on its own.
+func (c *CloudWatchLogsClient) stopQuery(queryID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	c.client.StopQuery(ctx, &cloudwatchlogs.StopQueryInput{QueryId: &queryID})
+}
+
+// BytesScanned returns the Logs Insights bytes scanned by this client's
+// queries so far, which is what Logs Insights bills for.
+func (c *CloudWatchLogsClient) BytesScanned() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytesScanned
+}
+
+func (c *CloudWatchLogsClient) addBytesScanned(stats *types.QueryStatistics) {
+	if stats == nil {
+		return
+	}
+	c.mu.Lock()
+	c.bytesScanned += stats.BytesScanned
+	c.mu.Unlock()
+}
+
+// RunQuery starts a Logs Insights query and waits for its results. A query
+// that fails after it was started is re-issued once, so a transient failure
+// at the end of a long scan does not lose the whole analysis.
+func (c *CloudWatchLogsClient) RunQuery(ctx context.Context, logGroupName string, startTime, endTime int64, queryString string) ([][]types.ResultField, error) {
+	var results [][]types.ResultField
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var queryID string
+		queryID, err = c.StartQuery(ctx, logGroupName, startTime, endTime, queryString)
+		if err != nil {
+			return nil, err
+		}
+		results, err = c.WaitForQueryResults(ctx, queryID)
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+	return results, err
+}
+
+// throttleBackoff is the wait before each retry of a throttled call.
+var throttleBackoff = []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second}
+
+// retryThrottled calls op, backing off and retrying while AWS reports
+// throttling or a concurrent-query limit.
+func retryThrottled(ctx context.Context, op func() error) error {
+	err := op()
+	for _, wait := range throttleBackoff {
+		if !isThrottled(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		err = op()
+	}
+	return err
+}
+
+func isThrottled(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "LimitExceededException", "ThrottlingException", "TooManyRequestsException", "RequestLimitExceeded":
+		return true
+	}
+	return false
+}
+
 // HasLogEvents checks whether the log group has at least one event in the given time range.
 // startTime and endTime are unix seconds.
 func (c *CloudWatchLogsClient) HasLogEvents(ctx context.Context, logGroupName string, startTime, endTime int64) (bool, error) {
@@ -185,7 +365,7 @@ func (c *CloudWatchLogsClient) QueryFlowLogs(ctx context.Context, logGroupName s
 | parse @message "* * * * * * * * * * * * * *" as f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12, f13, f14
 | filter f13 = "ACCEPT"
 | fields coalesce(f5, f3) as resolved_dst, f10 as flow_bytes
-| stats sum(flow_bytes) as total_bytes by resolved_dst
+| stats sum(flow_bytes) as total_bytes, count(*) as flow_count by resolved_dst
 | sort total_bytes desc`
 
 	input := &cloudwatchlogs.StartQueryInput{
@@ -0,0 +1,170 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
+)
+
+// fastBackoff shortens throttleBackoff for the duration of a test.
+func fastBackoff(t *testing.T) {
+	t.Helper()
+	saved := throttleBackoff
+	throttleBackoff = []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+	t.Cleanup(func() { throttleBackoff = saved })
+}
+
+func TestIsThrottled(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&smithy.GenericAPIError{Code: "ThrottlingException"}, true},
+		{&types.LimitExceededException{Message: awssdk.String("too many concurrent queries")}, true},
+		{fmt.Errorf("failed to start query: %w", &smithy.GenericAPIError{Code: "TooManyRequestsException"}), true},
+		{&types.ResourceNotFoundException{}, false},
+		{errors.New("ThrottlingException"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := isThrottled(tt.err); got != tt.want {
+			t.Errorf("isThrottled(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryThrottled(t *testing.T) {
+	fastBackoff(t)
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"succeeds at once", []error{nil}, 1, false},
+		{"throttling then success", []error{&smithy.GenericAPIError{Code: "ThrottlingException"}, nil}, 2, false},
+		{"concurrent query limit then success", []error{&types.LimitExceededException{}, &types.LimitExceededException{}, nil}, 3, false},
+		{"other errors are not retried", []error{&types.InvalidParameterException{}}, 1, true},
+		{"gives up after the backoff", []error{&types.LimitExceededException{}}, 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryThrottled(context.Background(), func() error {
+				err := tt.errs[min(calls, len(tt.errs)-1)]
+				calls++
+				return err
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("op called %d times, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryThrottledCancelled(t *testing.T) {
+	saved := throttleBackoff
+	throttleBackoff = []time.Duration{time.Hour}
+	t.Cleanup(func() { throttleBackoff = saved })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- retryThrottled(ctx, func() error {
+			calls++
+			return &smithy.GenericAPIError{Code: "ThrottlingException"}
+		})
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+		if calls != 1 {
+			t.Errorf("op called %d times after cancellation, want 1", calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retryThrottled kept waiting after the context was cancelled")
+	}
+}
+
+// fakeQueries is a Logs Insights stand-in: StartQuery returns each of
+// startErrs in turn before succeeding, and GetQueryResults reports each
+// query's status from statuses, by query.
+type fakeQueries struct {
+	CloudWatchLogsAPI
+	startErrs []error
+	statuses  []types.QueryStatus
+	started   int
+}
+
+func (f *fakeQueries) StartQuery(ctx context.Context, params *cloudwatchlogs.StartQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StartQueryOutput, error) {
+	if len(f.startErrs) > 0 {
+		err := f.startErrs[0]
+		f.startErrs = f.startErrs[1:]
+		return nil, err
+	}
+	f.started++
+	return &cloudwatchlogs.StartQueryOutput{QueryId: awssdk.String(fmt.Sprintf("q-%d", f.started))}, nil
+}
+
+func (f *fakeQueries) GetQueryResults(ctx context.Context, params *cloudwatchlogs.GetQueryResultsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	var n int
+	fmt.Sscanf(*params.QueryId, "q-%d", &n)
+	out := &cloudwatchlogs.GetQueryResultsOutput{Status: f.statuses[n-1], Statistics: &types.QueryStatistics{BytesScanned: 10}}
+	if out.Status == types.QueryStatusComplete {
+		out.Results = [][]types.ResultField{{{Field: awssdk.String("total_bytes"), Value: awssdk.String("42")}}}
+	}
+	return out, nil
+}
+
+func TestRunQueryReissuesAfterThrottledStart(t *testing.T) {
+	fastBackoff(t)
+	api := &fakeQueries{
+		// The first query is throttled twice before it starts, then fails
+		startErrs: []error{&types.LimitExceededException{}, &smithy.GenericAPIError{Code: "ThrottlingException"}},
+		statuses:  []types.QueryStatus{types.QueryStatusFailed, types.QueryStatusComplete},
+	}
+	c := NewCloudWatchLogsClient(api)
+
+	results, err := c.RunQuery(context.Background(), "/terminator/test", 0, 60, "fields @message")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.started != 2 {
+		t.Errorf("started %d queries, want the failed one re-issued once", api.started)
+	}
+	if len(results) != 1 || *results[0][0].Value != "42" {
+		t.Errorf("results = %v", results)
+	}
+	// Both queries are billed, the failed one included
+	if got := c.BytesScanned(); got != 20 {
+		t.Errorf("BytesScanned = %v, want 20", got)
+	}
+}
+
+func TestRunQueryGivesUpAfterOneReissue(t *testing.T) {
+	fastBackoff(t)
+	api := &fakeQueries{statuses: []types.QueryStatus{types.QueryStatusFailed, types.QueryStatusTimeout}}
+
+	_, err := NewCloudWatchLogsClient(api).RunQuery(context.Background(), "/terminator/test", 0, 60, "fields @message")
+	if err == nil {
+		t.Fatal("expected an error after the re-issued query failed too")
+	}
+	if api.started != 2 {
+		t.Errorf("started %d queries, want 2", api.started)
+	}
+}
@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	pkgtypes "github.com/doitintl/terminator/pkg/types"
+)
+
+// natBytesUsageType ends the usage type of NAT Gateway data processing,
+// e.g. USE2-NatGateway-Bytes (plain NatGateway-Bytes in us-east-1).
+const natBytesUsageType = "NatGateway-Bytes"
+
+// CostExplorerClient wraps Cost Explorer API calls
+type CostExplorerClient struct {
+	client *costexplorer.Client
+}
+
+// NewCostExplorerClient creates a new Cost Explorer client wrapper
+func NewCostExplorerClient(client *costexplorer.Client) *CostExplorerClient {
+	return &CostExplorerClient{client: client}
+}
+
+// NATProcessingCost returns the unblended NAT Gateway data processing cost of
+// the account in the region for each day from start up to (not including)
+// end, keyed by date (2006-01-02). Days without usage are zero.
+func (c *CostExplorerClient) NATProcessingCost(ctx context.Context, accountID, region string, start, end time.Time) (map[string]float64, error) {
+	filters := []types.Expression{
+		{Dimensions: &types.DimensionValues{Key: types.DimensionRegion, Values: []string{region}}},
+		{Dimensions: &types.DimensionValues{Key: types.DimensionService, Values: []string{"EC2 - Other"}}},
+	}
+	if accountID != "" {
+		filters = append(filters, types.Expression{Dimensions: &types.DimensionValues{Key: types.DimensionLinkedAccount, Values: []string{accountID}}})
+	}
+	input := &costexplorer.GetCostAndUsageInput{
+		TimePeriod: &types.DateInterval{
+			Start: stringPtr(start.Format("2006-01-02")),
+			End:   stringPtr(end.Format("2006-01-02")),
+		},
+		Granularity: types.GranularityDaily,
+		Metrics:     []string{"UnblendedCost"},
+		Filter:      &types.Expression{And: filters},
+		GroupBy:     []types.GroupDefinition{{Type: types.GroupDefinitionTypeDimension, Key: stringPtr("USAGE_TYPE")}},
+	}
+
+	daily := make(map[string]float64)
+	for {
+		out, err := c.client.GetCostAndUsage(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get NAT Gateway costs: %w", err)
+		}
+		for _, r := range out.ResultsByTime {
+			if r.TimePeriod == nil || r.TimePeriod.Start == nil {
+				continue
+			}
+			var total float64
+			for _, g := range r.Groups {
+				if len(g.Keys) == 0 || !strings.HasSuffix(g.Keys[0], natBytesUsageType) {
+					continue
+				}
+				m, ok := g.Metrics["UnblendedCost"]
+				if !ok || m.Amount == nil {
+					continue
+				}
+				amount, err := strconv.ParseFloat(*m.Amount, 64)
+				if err != nil {
+					return nil, fmt.Errorf("unexpected cost amount %q: %w", *m.Amount, err)
+				}
+				total += amount
+			}
+			daily[*r.TimePeriod.Start] = total
+		}
+		if out.NextPageToken == nil {
+			return daily, nil
+		}
+		input.NextPageToken = out.NextPageToken
+	}
+}
+
+// natUsageType is in the usage types of every NAT Gateway charge, e.g.
+// USE2-NatGateway-Hours.
+const natUsageType = "NatGateway"
+
+// ServiceAnomalyMonitor returns the ARN of the account's anomaly monitor
+// watching each AWS service, the kind NAT Gateway charges (EC2 - Other) show
+// up in, or "" when there is none.
+func (c *CostExplorerClient) ServiceAnomalyMonitor(ctx context.Context) (string, error) {
+	input := &costexplorer.GetAnomalyMonitorsInput{}
+	for {
+		out, err := c.client.GetAnomalyMonitors(ctx, input)
+		if err != nil {
+			return "", fmt.Errorf("failed to list cost anomaly monitors: %w", err)
+		}
+		for _, m := range out.AnomalyMonitors {
+			if m.MonitorType == types.MonitorTypeDimensional && m.MonitorDimension == types.MonitorDimensionService && m.MonitorArn != nil {
+				return *m.MonitorArn, nil
+			}
+		}
+		if out.NextPageToken == nil {
+			return "", nil
+		}
+		input.NextPageToken = out.NextPageToken
+	}
+}
+
+// CreateServiceAnomalyMonitor creates an anomaly monitor watching each AWS
+// service and returns its ARN. Custom monitors cannot be narrowed to usage
+// types, so anomalies are narrowed to NAT Gateway by their root causes.
+func (c *CostExplorerClient) CreateServiceAnomalyMonitor(ctx context.Context, name string, tags map[string]string) (string, error) {
+	input := &costexplorer.CreateAnomalyMonitorInput{
+		AnomalyMonitor: &types.AnomalyMonitor{
+			MonitorName:      stringPtr(name),
+			MonitorType:      types.MonitorTypeDimensional,
+			MonitorDimension: types.MonitorDimensionService,
+		},
+	}
+	for k, v := range tags {
+		input.ResourceTags = append(input.ResourceTags, types.ResourceTag{Key: stringPtr(k), Value: stringPtr(v)})
+	}
+	out, err := c.client.CreateAnomalyMonitor(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cost anomaly monitor: %w", err)
+	}
+	return *out.MonitorArn, nil
+}
+
+// NATAnomalies returns the monitor's anomalies since start with a NAT
+// Gateway usage type in region among their root causes, newest first.
+func (c *CostExplorerClient) NATAnomalies(ctx context.Context, monitorARN, region string, start time.Time) ([]pkgtypes.CostAnomaly, error) {
+	input := &costexplorer.GetAnomaliesInput{
+		MonitorArn:   stringPtr(monitorARN),
+		DateInterval: &types.AnomalyDateInterval{StartDate: stringPtr(start.Format("2006-01-02"))},
+	}
+	var anomalies []pkgtypes.CostAnomaly
+	for {
+		out, err := c.client.GetAnomalies(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cost anomalies: %w", err)
+		}
+		for _, a := range out.Anomalies {
+			var usageTypes []string
+			for _, rc := range a.RootCauses {
+				if rc.UsageType == nil || !strings.Contains(*rc.UsageType, natUsageType) {
+					continue
+				}
+				if rc.Region != nil && *rc.Region != region {
+					continue
+				}
+				usageTypes = append(usageTypes, *rc.UsageType)
+			}
+			if len(usageTypes) == 0 {
+				continue
+			}
+			anomaly := pkgtypes.CostAnomaly{
+				ID:         awssdk.ToString(a.AnomalyId),
+				StartDate:  awssdk.ToString(a.AnomalyStartDate),
+				EndDate:    awssdk.ToString(a.AnomalyEndDate),
+				UsageTypes: usageTypes,
+				Feedback:   string(a.Feedback),
+			}
+			if a.Impact != nil {
+				anomaly.TotalImpact = a.Impact.TotalImpact
+				anomaly.MaxImpact = a.Impact.MaxImpact
+				anomaly.ExpectedSpend = awssdk.ToFloat64(a.Impact.TotalExpectedSpend)
+				anomaly.ActualSpend = awssdk.ToFloat64(a.Impact.TotalActualSpend)
+			}
+			anomalies = append(anomalies, anomaly)
+		}
+		if out.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = out.NextPageToken
+	}
+	sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].StartDate > anomalies[j].StartDate })
+	return anomalies, nil
+}
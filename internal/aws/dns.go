@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/aws/aws-sdk-go-v2/service/route53resolver"
+	resolvertypes "github.com/aws/aws-sdk-go-v2/service/route53resolver/types"
+	pkgtypes "github.com/doitintl/terminator/pkg/types"
+)
+
+// DNSClient wraps the Route 53 and Route 53 Resolver API calls that show how
+// a VPC resolves names
+type DNSClient struct {
+	route53  *route53.Client
+	resolver *route53resolver.Client
+}
+
+// NewDNSClient creates a new DNS client wrapper
+func NewDNSClient(r53 *route53.Client, resolver *route53resolver.Client) *DNSClient {
+	return &DNSClient{route53: r53, resolver: resolver}
+}
+
+// ResolverRules returns the Resolver rules associated with a VPC
+func (c *DNSClient) ResolverRules(ctx context.Context, vpcID string) ([]pkgtypes.ResolverRule, error) {
+	paginator := route53resolver.NewListResolverRuleAssociationsPaginator(c.resolver, &route53resolver.ListResolverRuleAssociationsInput{
+		Filters: []resolvertypes.Filter{
+			{Name: stringPtr("VPCId"), Values: []string{vpcID}},
+		},
+	})
+
+	var rules []pkgtypes.ResolverRule
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resolver rule associations: %w", err)
+		}
+		for _, assoc := range page.ResolverRuleAssociations {
+			if assoc.ResolverRuleId == nil || assoc.Status == resolvertypes.ResolverRuleAssociationStatusDeleting {
+				continue
+			}
+			out, err := c.resolver.GetResolverRule(ctx, &route53resolver.GetResolverRuleInput{ResolverRuleId: assoc.ResolverRuleId})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get resolver rule %s: %w", *assoc.ResolverRuleId, err)
+			}
+			rule := out.ResolverRule
+			rules = append(rules, pkgtypes.ResolverRule{
+				ID:         stringValue(rule.Id),
+				Name:       stringValue(rule.Name),
+				DomainName: trimDot(stringValue(rule.DomainName)),
+				RuleType:   string(rule.RuleType),
+			})
+		}
+	}
+	return rules, nil
+}
+
+// PrivateZones returns the names of the private hosted zones associated with
+// a VPC
+func (c *DNSClient) PrivateZones(ctx context.Context, vpcID, region string) ([]string, error) {
+	var names []string
+	input := &route53.ListHostedZonesByVPCInput{
+		VPCId:     stringPtr(vpcID),
+		VPCRegion: route53types.VPCRegion(region),
+	}
+	for {
+		out, err := c.route53.ListHostedZonesByVPC(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list private hosted zones: %w", err)
+		}
+		for _, zone := range out.HostedZoneSummaries {
+			names = append(names, trimDot(stringValue(zone.Name)))
+		}
+		if out.NextToken == nil {
+			return names, nil
+		}
+		input.NextToken = out.NextToken
+	}
+}
+
+// trimDot strips the trailing dot of a fully qualified name, keeping the
+// root "." as is.
+func trimDot(name string) string {
+	if name == "." {
+		return name
+	}
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
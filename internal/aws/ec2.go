@@ -3,6 +3,8 @@ package aws
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -61,6 +63,17 @@ func (c *EC2Client) DiscoverNATGateways(ctx context.Context) ([]pkgtypes.NATGate
 			AvailabilityMode: availabilityMode,
 			Tags:             tags,
 		}
+		if nat.CreateTime != nil {
+			natGW.CreateTime = *nat.CreateTime
+		}
+		for _, addr := range nat.NatGatewayAddresses {
+			if addr.PublicIp != nil {
+				natGW.PublicIPs = append(natGW.PublicIPs, *addr.PublicIp)
+			}
+			if addr.PrivateIp != nil {
+				natGW.PrivateIPs = append(natGW.PrivateIPs, *addr.PrivateIp)
+			}
+		}
 
 		// For zonal NAT gateways, SubnetID is required
 		// For regional NAT gateways, SubnetID may be nil
@@ -86,9 +99,41 @@ func (c *EC2Client) DiscoverNATGateways(ctx context.Context) ([]pkgtypes.NATGate
 		nats = append(nats, natGW)
 	}
 
+	// Availability zones are best effort: discovery still succeeds without them
+	if azs, err := c.subnetAvailabilityZones(ctx, nats); err == nil {
+		for i := range nats {
+			nats[i].AvailabilityZone = azs[nats[i].SubnetID]
+		}
+	}
+
 	return nats, nil
 }
 
+// subnetAvailabilityZones maps the subnets of zonal NAT Gateways to their AZ
+func (c *EC2Client) subnetAvailabilityZones(ctx context.Context, nats []pkgtypes.NATGateway) (map[string]string, error) {
+	var subnetIDs []string
+	for _, nat := range nats {
+		if nat.AvailabilityMode == "zonal" && nat.SubnetID != "" {
+			subnetIDs = append(subnetIDs, nat.SubnetID)
+		}
+	}
+	azs := make(map[string]string)
+	if len(subnetIDs) == 0 {
+		return azs, nil
+	}
+
+	result, err := c.client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{SubnetIds: subnetIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe subnets: %w", err)
+	}
+	for _, subnet := range result.Subnets {
+		if subnet.SubnetId != nil && subnet.AvailabilityZone != nil {
+			azs[*subnet.SubnetId] = *subnet.AvailabilityZone
+		}
+	}
+	return azs, nil
+}
+
 // DiscoverVPCEndpoints finds all VPC endpoints for a given VPC
 func (c *EC2Client) DiscoverVPCEndpoints(ctx context.Context, vpcID string) ([]pkgtypes.VPCEndpoint, error) {
 	input := &ec2.DescribeVpcEndpointsInput{
@@ -125,6 +170,9 @@ func (c *EC2Client) DiscoverVPCEndpoints(ctx context.Context, vpcID string) ([]p
 			PrivateDNS:  ep.PrivateDnsEnabled != nil && *ep.PrivateDnsEnabled,
 			Tags:        tags,
 		}
+		for _, g := range ep.Groups {
+			endpoint.SecurityGroups = append(endpoint.SecurityGroups, stringValue(g.GroupId))
+		}
 
 		endpoints = append(endpoints, endpoint)
 	}
@@ -132,6 +180,32 @@ func (c *EC2Client) DiscoverVPCEndpoints(ctx context.Context, vpcID string) ([]p
 	return endpoints, nil
 }
 
+// CountVPCEndpoints counts the endpoints of a type ("Gateway" or
+// "Interface") that count against quotas, in one VPC or, with an empty
+// vpcID, the whole region
+func (c *EC2Client) CountVPCEndpoints(ctx context.Context, vpcID, endpointType string) (int, error) {
+	filters := []types.Filter{
+		{Name: stringPtr("vpc-endpoint-type"), Values: []string{endpointType}},
+	}
+	if vpcID != "" {
+		filters = append(filters, types.Filter{Name: stringPtr("vpc-id"), Values: []string{vpcID}})
+	}
+	paginator := ec2.NewDescribeVpcEndpointsPaginator(c.client, &ec2.DescribeVpcEndpointsInput{Filters: filters})
+	count := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to describe VPC endpoints: %w", err)
+		}
+		for _, ep := range page.VpcEndpoints {
+			if ep.State != types.StateDeleted && ep.State != types.StateDeleting {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
 // DiscoverRouteTables finds all route tables for a VPC
 func (c *EC2Client) DiscoverRouteTables(ctx context.Context, vpcID string) ([]pkgtypes.RouteTable, error) {
 	input := &ec2.DescribeRouteTablesInput{
@@ -162,6 +236,9 @@ func (c *EC2Client) DiscoverRouteTables(ctx context.Context, vpcID string) ([]pk
 			r := pkgtypes.Route{}
 			if route.DestinationCidrBlock != nil {
 				r.DestinationCIDR = *route.DestinationCidrBlock
+			} else if route.DestinationPrefixListId != nil {
+				// Gateway endpoint routes target the service's prefix list
+				r.DestinationCIDR = *route.DestinationPrefixListId
 			}
 
 			// Determine target type
@@ -172,6 +249,8 @@ func (c *EC2Client) DiscoverRouteTables(ctx context.Context, vpcID string) ([]pk
 				r.Target = *route.GatewayId
 				if *route.GatewayId == "local" {
 					r.TargetType = "local"
+				} else if strings.HasPrefix(*route.GatewayId, "vpce-") {
+					r.TargetType = "vpc-endpoint"
 				} else {
 					r.TargetType = "igw"
 				}
@@ -213,49 +292,393 @@ func (c *EC2Client) DiscoverRouteTables(ctx context.Context, vpcID string) ([]pk
 	return routeTables, nil
 }
 
+// DescribeSubnet looks up one subnet by ID
+func (c *EC2Client) DescribeSubnet(ctx context.Context, subnetID string) (pkgtypes.Subnet, error) {
+	result, err := c.client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{SubnetIds: []string{subnetID}})
+	if err != nil {
+		return pkgtypes.Subnet{}, fmt.Errorf("failed to describe subnet %s: %w", subnetID, err)
+	}
+	if len(result.Subnets) == 0 {
+		return pkgtypes.Subnet{}, fmt.Errorf("subnet %s not found", subnetID)
+	}
+	sn := result.Subnets[0]
+	return pkgtypes.Subnet{
+		ID:               stringValue(sn.SubnetId),
+		VPCID:            stringValue(sn.VpcId),
+		CIDR:             stringValue(sn.CidrBlock),
+		AvailabilityZone: stringValue(sn.AvailabilityZone),
+		MapPublicIP:      sn.MapPublicIpOnLaunch != nil && *sn.MapPublicIpOnLaunch,
+	}, nil
+}
+
+// DescribeNetworkInterface returns the subnet of a network interface and,
+// for an interface endpoint's network interface, the endpoint ID.
+func (c *EC2Client) DescribeNetworkInterface(ctx context.Context, eniID string) (subnetID, endpointID string, err error) {
+	result, err := c.client.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{NetworkInterfaceIds: []string{eniID}})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to describe network interface %s: %w", eniID, err)
+	}
+	if len(result.NetworkInterfaces) == 0 {
+		return "", "", fmt.Errorf("network interface %s not found", eniID)
+	}
+	eni := result.NetworkInterfaces[0]
+	if eni.InterfaceType == types.NetworkInterfaceTypeVpcEndpoint {
+		// The description is "VPC Endpoint Interface vpce-..."
+		for _, field := range strings.Fields(stringValue(eni.Description)) {
+			if strings.HasPrefix(field, "vpce-") {
+				endpointID = field
+			}
+		}
+	}
+	return stringValue(eni.SubnetId), endpointID, nil
+}
+
+// DiscoverSubnets finds all subnets in a VPC
+func (c *EC2Client) DiscoverSubnets(ctx context.Context, vpcID string) ([]pkgtypes.Subnet, error) {
+	paginator := ec2.NewDescribeSubnetsPaginator(c.client, &ec2.DescribeSubnetsInput{
+		Filters: []types.Filter{
+			{Name: stringPtr("vpc-id"), Values: []string{vpcID}},
+		},
+	})
+
+	var subnets []pkgtypes.Subnet
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe subnets: %w", err)
+		}
+		for _, sn := range page.Subnets {
+			tags := make(map[string]string)
+			for _, tag := range sn.Tags {
+				if tag.Key != nil && tag.Value != nil {
+					tags[*tag.Key] = *tag.Value
+				}
+			}
+			subnets = append(subnets, pkgtypes.Subnet{
+				ID:               stringValue(sn.SubnetId),
+				VPCID:            stringValue(sn.VpcId),
+				CIDR:             stringValue(sn.CidrBlock),
+				AvailabilityZone: stringValue(sn.AvailabilityZone),
+				MapPublicIP:      sn.MapPublicIpOnLaunch != nil && *sn.MapPublicIpOnLaunch,
+				Tags:             tags,
+			})
+		}
+	}
+	return subnets, nil
+}
+
+// DiscoverSecurityGroups finds the security groups of a VPC and the IPv4
+// ranges each admits to HTTPS
+func (c *EC2Client) DiscoverSecurityGroups(ctx context.Context, vpcID string) ([]pkgtypes.SecurityGroup, error) {
+	paginator := ec2.NewDescribeSecurityGroupsPaginator(c.client, &ec2.DescribeSecurityGroupsInput{
+		Filters: []types.Filter{
+			{Name: stringPtr("vpc-id"), Values: []string{vpcID}},
+		},
+	})
+
+	var groups []pkgtypes.SecurityGroup
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe security groups: %w", err)
+		}
+		for _, sg := range page.SecurityGroups {
+			group := pkgtypes.SecurityGroup{
+				ID:    stringValue(sg.GroupId),
+				VPCID: stringValue(sg.VpcId),
+				Name:  stringValue(sg.GroupName),
+			}
+			for _, perm := range sg.IpPermissions {
+				if !admitsHTTPS(perm) {
+					continue
+				}
+				for _, r := range perm.IpRanges {
+					if r.CidrIp != nil {
+						group.HTTPSCIDRs = append(group.HTTPSCIDRs, *r.CidrIp)
+					}
+				}
+				for _, pair := range perm.UserIdGroupPairs {
+					if pair.GroupId != nil {
+						group.HTTPSGroups = append(group.HTTPSGroups, *pair.GroupId)
+					}
+				}
+			}
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// VPCCIDRs returns the IPv4 ranges associated with a VPC
+func (c *EC2Client) VPCCIDRs(ctx context.Context, vpcID string) ([]string, error) {
+	result, err := c.client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{VpcIds: []string{vpcID}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe VPC %s: %w", vpcID, err)
+	}
+	var cidrs []string
+	for _, vpc := range result.Vpcs {
+		for _, assoc := range vpc.CidrBlockAssociationSet {
+			if assoc.CidrBlock != nil && assoc.CidrBlockState != nil && assoc.CidrBlockState.State == types.VpcCidrBlockStateCodeAssociated {
+				cidrs = append(cidrs, *assoc.CidrBlock)
+			}
+		}
+	}
+	return cidrs, nil
+}
+
+// VPCNames returns the Name tags of the VPCs in vpcIDs that have one
+func (c *EC2Client) VPCNames(ctx context.Context, vpcIDs []string) (map[string]string, error) {
+	if len(vpcIDs) == 0 {
+		return nil, nil
+	}
+	result, err := c.client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{VpcIds: vpcIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe VPCs: %w", err)
+	}
+	names := make(map[string]string)
+	for _, vpc := range result.Vpcs {
+		for _, tag := range vpc.Tags {
+			if tag.Key != nil && *tag.Key == "Name" && tag.Value != nil && *tag.Value != "" {
+				names[*vpc.VpcId] = *tag.Value
+			}
+		}
+	}
+	return names, nil
+}
+
+// WorkloadSecurityGroups returns the security groups of the in-use workload
+// network interfaces in subnetIDs, by subnet. Like WorkloadENI, it skips the
+// interfaces of load balancers, NAT Gateways and endpoints.
+func (c *EC2Client) WorkloadSecurityGroups(ctx context.Context, subnetIDs []string) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	if len(subnetIDs) == 0 {
+		return groups, nil
+	}
+	paginator := ec2.NewDescribeNetworkInterfacesPaginator(c.client, &ec2.DescribeNetworkInterfacesInput{
+		Filters: []types.Filter{
+			{Name: stringPtr("subnet-id"), Values: subnetIDs},
+			{Name: stringPtr("status"), Values: []string{"in-use"}},
+		},
+	})
+	seen := make(map[string]bool)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe network interfaces: %w", err)
+		}
+		for _, eni := range page.NetworkInterfaces {
+			if eni.RequesterManaged != nil && *eni.RequesterManaged && eni.InterfaceType != types.NetworkInterfaceTypeLambda {
+				continue
+			}
+			subnetID := stringValue(eni.SubnetId)
+			for _, g := range eni.Groups {
+				id := stringValue(g.GroupId)
+				if id == "" || seen[subnetID+"/"+id] {
+					continue
+				}
+				seen[subnetID+"/"+id] = true
+				groups[subnetID] = append(groups[subnetID], id)
+			}
+		}
+	}
+	return groups, nil
+}
+
+// admitsHTTPS reports whether an inbound rule covers TCP 443.
+func admitsHTTPS(perm types.IpPermission) bool {
+	protocol := stringValue(perm.IpProtocol)
+	if protocol == "-1" {
+		return true
+	}
+	if protocol != "tcp" && protocol != "6" {
+		return false
+	}
+	return perm.FromPort != nil && perm.ToPort != nil && *perm.FromPort <= 443 && *perm.ToPort >= 443
+}
+
+// VPCDNSAttributes returns whether DNS resolution and DNS hostnames are
+// enabled for a VPC
+func (c *EC2Client) VPCDNSAttributes(ctx context.Context, vpcID string) (support, hostnames bool, err error) {
+	out, err := c.client.DescribeVpcAttribute(ctx, &ec2.DescribeVpcAttributeInput{
+		VpcId:     stringPtr(vpcID),
+		Attribute: types.VpcAttributeNameEnableDnsSupport,
+	})
+	if err != nil {
+		return false, false, fmt.Errorf("failed to describe DNS support of %s: %w", vpcID, err)
+	}
+	support = out.EnableDnsSupport != nil && out.EnableDnsSupport.Value != nil && *out.EnableDnsSupport.Value
+
+	out, err = c.client.DescribeVpcAttribute(ctx, &ec2.DescribeVpcAttributeInput{
+		VpcId:     stringPtr(vpcID),
+		Attribute: types.VpcAttributeNameEnableDnsHostnames,
+	})
+	if err != nil {
+		return false, false, fmt.Errorf("failed to describe DNS hostnames of %s: %w", vpcID, err)
+	}
+	hostnames = out.EnableDnsHostnames != nil && out.EnableDnsHostnames.Value != nil && *out.EnableDnsHostnames.Value
+	return support, hostnames, nil
+}
+
+// InstanceIDsInVPC returns the IDs of running instances in a VPC
+func (c *EC2Client) InstanceIDsInVPC(ctx context.Context, vpcID string) ([]string, error) {
+	paginator := ec2.NewDescribeInstancesPaginator(c.client, &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			{Name: stringPtr("vpc-id"), Values: []string{vpcID}},
+			{Name: stringPtr("instance-state-name"), Values: []string{"running"}},
+		},
+	})
+
+	var ids []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe instances: %w", err)
+		}
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				if instance.InstanceId != nil {
+					ids = append(ids, *instance.InstanceId)
+				}
+			}
+		}
+	}
+	return ids, nil
+}
+
+// eksClusterTagPrefix marks subnets that an EKS cluster's nodes or load balancers use
+const eksClusterTagPrefix = "kubernetes.io/cluster/"
+
+// DiscoverEKSClusters returns the EKS cluster names tagged on a VPC's subnets
+func (c *EC2Client) DiscoverEKSClusters(ctx context.Context, vpcID string) ([]string, error) {
+	result, err := c.client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+		Filters: []types.Filter{
+			{
+				Name:   stringPtr("vpc-id"),
+				Values: []string{vpcID},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe subnets: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var clusters []string
+	for _, subnet := range result.Subnets {
+		for _, tag := range subnet.Tags {
+			if tag.Key == nil || !strings.HasPrefix(*tag.Key, eksClusterTagPrefix) {
+				continue
+			}
+			name := strings.TrimPrefix(*tag.Key, eksClusterTagPrefix)
+			if name != "" && !seen[name] {
+				seen[name] = true
+				clusters = append(clusters, name)
+			}
+		}
+	}
+	sort.Strings(clusters)
+	return clusters, nil
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
 
-// CreateFlowLogs creates VPC Flow Logs for NAT Gateway analysis
-func (c *EC2Client) CreateFlowLogs(ctx context.Context, nat pkgtypes.NATGateway, logGroupName string, deliveryRoleArn string, runID string) (string, error) {
-	// Determine resource type and ID based on NAT mode
-	var resourceType types.FlowLogsResourceType
-	var resourceID string
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
 
+// FlowLogFormat is the custom record format of every Flow Log termiNATor creates,
+// with pkt-dstaddr for accurate destination tracking and pkt-dst-aws-service
+// to cross-check the IP-range classification. Queries parse the first 14
+// fields, so log groups from before the last field was added still work.
+const FlowLogFormat = "${interface-id} ${srcaddr} ${dstaddr} ${pkt-srcaddr} ${pkt-dstaddr} ${srcport} ${dstport} ${protocol} ${packets} ${bytes} ${start} ${end} ${action} ${log-status} ${pkt-dst-aws-service}"
+
+// FlowLogAggregationInterval is the maximum aggregation interval, in seconds,
+// of every Flow Log termiNATor creates: the shortest AWS allows, for faster data.
+const FlowLogAggregationInterval = 60
+
+// flowLogTarget returns the Flow Logs resource type and ID for a NAT Gateway.
+func flowLogTarget(nat pkgtypes.NATGateway) (types.FlowLogsResourceType, string) {
 	if nat.AvailabilityMode == "regional" {
 		// Regional NAT: target the NAT Gateway itself
-		resourceType = "RegionalNatGateway"
-		resourceID = nat.ID
-	} else {
-		// Zonal NAT: target the ENI
-		resourceType = types.FlowLogsResourceTypeNetworkInterface
-		resourceID = nat.NetworkInterfaceID
+		return "RegionalNatGateway", nat.ID
 	}
+	// Zonal NAT: target the ENI
+	return types.FlowLogsResourceTypeNetworkInterface, nat.NetworkInterfaceID
+}
+
+// CreateFlowLogs creates VPC Flow Logs for NAT Gateway analysis
+// Extra tags are added alongside the built-in CreatedBy, RunId, and Timestamp tags.
+// The destination is a CloudWatch Logs log group name, or the ARN of a Kinesis
+// Data Firehose delivery stream, which needs no delivery role.
+func (c *EC2Client) CreateFlowLogs(ctx context.Context, nat pkgtypes.NATGateway, destination string, deliveryRoleArn string, runID string, extraTags map[string]string) (string, error) {
+	// Determine resource type and ID based on NAT mode
+	resourceType, resourceID := flowLogTarget(nat)
+	return c.createFlowLogs(ctx, resourceType, resourceID, destination, deliveryRoleArn, runID, extraTags)
+}
 
-	// Custom log format with pkt-dstaddr for accurate destination tracking
-	logFormat := "${interface-id} ${srcaddr} ${dstaddr} ${pkt-srcaddr} ${pkt-dstaddr} ${srcport} ${dstport} ${protocol} ${packets} ${bytes} ${start} ${end} ${action} ${log-status}"
+// IsFirehoseStream reports whether a Flow Logs destination is a Kinesis Data
+// Firehose delivery stream ARN rather than a log group name.
+func IsFirehoseStream(destination string) bool {
+	return strings.HasPrefix(destination, "arn:") && strings.Contains(destination, ":firehose:")
+}
+
+// CreateSubnetFlowLogs creates VPC Flow Logs for every network interface in a
+// subnet, in the same format as CreateFlowLogs.
+func (c *EC2Client) CreateSubnetFlowLogs(ctx context.Context, subnetID string, destination string, deliveryRoleArn string, runID string, extraTags map[string]string) (string, error) {
+	return c.createFlowLogs(ctx, types.FlowLogsResourceTypeSubnet, subnetID, destination, deliveryRoleArn, runID, extraTags)
+}
+
+// CreateENIFlowLogs creates VPC Flow Logs for one network interface, in the
+// same format as CreateFlowLogs.
+func (c *EC2Client) CreateENIFlowLogs(ctx context.Context, eniID string, destination string, deliveryRoleArn string, runID string, extraTags map[string]string) (string, error) {
+	return c.createFlowLogs(ctx, types.FlowLogsResourceTypeNetworkInterface, eniID, destination, deliveryRoleArn, runID, extraTags)
+}
+
+func (c *EC2Client) createFlowLogs(ctx context.Context, resourceType types.FlowLogsResourceType, resourceID string, destination string, deliveryRoleArn string, runID string, extraTags map[string]string) (string, error) {
+	logFormat := FlowLogFormat
+
+	tags := []types.Tag{
+		{Key: stringPtr("CreatedBy"), Value: stringPtr("termiNATor")},
+		{Key: stringPtr("RunId"), Value: stringPtr(runID)},
+		{Key: stringPtr("Timestamp"), Value: stringPtr(time.Now().Format(time.RFC3339))},
+	}
+	for _, k := range sortedKeys(extraTags) {
+		if k == "CreatedBy" || k == "RunId" || k == "Timestamp" {
+			continue
+		}
+		tags = append(tags, types.Tag{Key: stringPtr(k), Value: stringPtr(extraTags[k])})
+	}
 
 	input := &ec2.CreateFlowLogsInput{
 		ResourceType:             resourceType,
 		ResourceIds:              []string{resourceID},
 		TrafficType:              types.TrafficTypeAll,
 		LogDestinationType:       types.LogDestinationTypeCloudWatchLogs,
-		LogGroupName:             &logGroupName,
+		LogGroupName:             &destination,
 		DeliverLogsPermissionArn: &deliveryRoleArn,
 		LogFormat:                &logFormat,
-		MaxAggregationInterval:   intPtr(60), // 60 seconds for faster data
+		MaxAggregationInterval:   intPtr(FlowLogAggregationInterval),
 		TagSpecifications: []types.TagSpecification{
 			{
 				ResourceType: types.ResourceTypeVpcFlowLog,
-				Tags: []types.Tag{
-					{Key: stringPtr("CreatedBy"), Value: stringPtr("termiNATor")},
-					{Key: stringPtr("RunId"), Value: stringPtr(runID)},
-					{Key: stringPtr("Timestamp"), Value: stringPtr(time.Now().Format(time.RFC3339))},
-				},
+				Tags:         tags,
 			},
 		},
 	}
+	if IsFirehoseStream(destination) {
+		// Delivered through the log delivery service-linked role
+		input.LogDestinationType = types.LogDestinationTypeKinesisDataFirehose
+		input.LogDestination = &destination
+		input.LogGroupName = nil
+		input.DeliverLogsPermissionArn = nil
+	}
 
 	result, err := c.client.CreateFlowLogs(ctx, input)
 	if err != nil {
@@ -346,6 +769,39 @@ func (c *EC2Client) DescribeFlowLogs(ctx context.Context, flowLogIDs []string) (
 	return flowLogs, nil
 }
 
+// ListTerminatorFlowLogs returns the Flow Logs tagged CreatedBy=termiNATor,
+// with their tags.
+func (c *EC2Client) ListTerminatorFlowLogs(ctx context.Context) ([]pkgtypes.FlowLog, error) {
+	paginator := ec2.NewDescribeFlowLogsPaginator(c.client, &ec2.DescribeFlowLogsInput{
+		Filter: []types.Filter{{Name: stringPtr("tag:CreatedBy"), Values: []string{"termiNATor"}}},
+	})
+	var flowLogs []pkgtypes.FlowLog
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe flow logs: %w", err)
+		}
+		for _, fl := range page.FlowLogs {
+			tags := make(map[string]string, len(fl.Tags))
+			for _, t := range fl.Tags {
+				tags[stringValue(t.Key)] = stringValue(t.Value)
+			}
+			flowLog := pkgtypes.FlowLog{
+				ID:           stringValue(fl.FlowLogId),
+				ResourceID:   stringValue(fl.ResourceId),
+				Status:       stringValue(fl.FlowLogStatus),
+				LogGroupName: stringValue(fl.LogGroupName),
+				Tags:         tags,
+			}
+			if fl.CreationTime != nil {
+				flowLog.CreationTime = *fl.CreationTime
+			}
+			flowLogs = append(flowLogs, flowLog)
+		}
+	}
+	return flowLogs, nil
+}
+
 func intPtr(i int32) *int32 {
 	return &i
 }
@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+)
+
+// QuotasClient wraps Service Quotas API calls
+type QuotasClient struct {
+	client *servicequotas.Client
+}
+
+// NewQuotasClient creates a new Service Quotas client wrapper
+func NewQuotasClient(client *servicequotas.Client) *QuotasClient {
+	return &QuotasClient{client: client}
+}
+
+// Quota returns the applied value of a quota, or its AWS default when the
+// account never changed it
+func (c *QuotasClient) Quota(ctx context.Context, serviceCode, quotaCode string) (float64, error) {
+	out, err := c.client.GetServiceQuota(ctx, &servicequotas.GetServiceQuotaInput{
+		ServiceCode: stringPtr(serviceCode),
+		QuotaCode:   stringPtr(quotaCode),
+	})
+	var noSuch *types.NoSuchResourceException
+	if errors.As(err, &noSuch) {
+		def, derr := c.client.GetAWSDefaultServiceQuota(ctx, &servicequotas.GetAWSDefaultServiceQuotaInput{
+			ServiceCode: stringPtr(serviceCode),
+			QuotaCode:   stringPtr(quotaCode),
+		})
+		if derr != nil {
+			return 0, fmt.Errorf("failed to read quota %s: %w", quotaCode, derr)
+		}
+		if def.Quota == nil || def.Quota.Value == nil {
+			return 0, fmt.Errorf("quota %s has no value", quotaCode)
+		}
+		return *def.Quota.Value, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read quota %s: %w", quotaCode, err)
+	}
+	if out.Quota == nil || out.Quota.Value == nil {
+		return 0, fmt.Errorf("quota %s has no value", quotaCode)
+	}
+	return *out.Quota.Value, nil
+}
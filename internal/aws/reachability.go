@@ -0,0 +1,278 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	pkgtypes "github.com/doitintl/terminator/pkg/types"
+)
+
+// reachabilityPollInterval is how often a running analysis is checked.
+var reachabilityPollInterval = 5 * time.Second
+
+// reachabilityTimeout bounds how long AnalyzeReachability waits for an
+// analysis that never leaves the running state.
+const reachabilityTimeout = 10 * time.Minute
+
+// WorkloadENI returns an in-use network interface in a subnet to start a
+// Reachability Analyzer path from, preferring instance interfaces. It returns
+// "" when the subnet has none.
+func (c *EC2Client) WorkloadENI(ctx context.Context, subnetID string) (string, error) {
+	result, err := c.client.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{
+		Filters: []types.Filter{
+			{Name: stringPtr("subnet-id"), Values: []string{subnetID}},
+			{Name: stringPtr("status"), Values: []string{"in-use"}},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe network interfaces: %w", err)
+	}
+
+	fallback := ""
+	for _, eni := range result.NetworkInterfaces {
+		if eni.NetworkInterfaceId == nil {
+			continue
+		}
+		// Load balancer, NAT Gateway and endpoint interfaces don't originate
+		// workload traffic.
+		if eni.RequesterManaged != nil && *eni.RequesterManaged && eni.InterfaceType != types.NetworkInterfaceTypeLambda {
+			continue
+		}
+		if eni.Attachment != nil && eni.Attachment.InstanceId != nil {
+			return *eni.NetworkInterfaceId, nil
+		}
+		if fallback == "" {
+			fallback = *eni.NetworkInterfaceId
+		}
+	}
+	return fallback, nil
+}
+
+// ServicePrefixListAddress returns the ID of an AWS-managed prefix list, such
+// as com.amazonaws.us-east-1.s3, and an address inside it.
+func (c *EC2Client) ServicePrefixListAddress(ctx context.Context, prefixListName string) (id, address string, err error) {
+	lists, err := c.client.DescribeManagedPrefixLists(ctx, &ec2.DescribeManagedPrefixListsInput{
+		Filters: []types.Filter{
+			{Name: stringPtr("prefix-list-name"), Values: []string{prefixListName}},
+		},
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to describe prefix list %s: %w", prefixListName, err)
+	}
+	if len(lists.PrefixLists) == 0 || lists.PrefixLists[0].PrefixListId == nil {
+		return "", "", fmt.Errorf("prefix list %s not found", prefixListName)
+	}
+	id = *lists.PrefixLists[0].PrefixListId
+
+	entries, err := c.client.GetManagedPrefixListEntries(ctx, &ec2.GetManagedPrefixListEntriesInput{PrefixListId: &id})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get entries of %s: %w", id, err)
+	}
+	for _, e := range entries.Entries {
+		prefix, err := netip.ParsePrefix(stringValue(e.Cidr))
+		if err != nil || !prefix.Addr().Is4() {
+			continue
+		}
+		// The first host address; the network address itself is not a
+		// realistic destination.
+		return id, prefix.Masked().Addr().Next().String(), nil
+	}
+	return "", "", fmt.Errorf("prefix list %s has no IPv4 entries", id)
+}
+
+// AnalyzeReachability runs a Reachability Analyzer analysis of TCP traffic
+// from a network interface to destIP:port and waits for the result. The path
+// and analysis are deleted afterwards unless keep is set.
+func (c *EC2Client) AnalyzeReachability(ctx context.Context, sourceENI, destIP string, port int32, keep bool, extraTags map[string]string) (*pkgtypes.ReachabilityResult, error) {
+	tags := []types.Tag{
+		{Key: stringPtr("CreatedBy"), Value: stringPtr("termiNATor")},
+		{Key: stringPtr("Timestamp"), Value: stringPtr(time.Now().Format(time.RFC3339))},
+	}
+	for _, k := range sortedKeys(extraTags) {
+		if k == "CreatedBy" || k == "Timestamp" {
+			continue
+		}
+		tags = append(tags, types.Tag{Key: stringPtr(k), Value: stringPtr(extraTags[k])})
+	}
+
+	path, err := c.client.CreateNetworkInsightsPath(ctx, &ec2.CreateNetworkInsightsPathInput{
+		Source:   &sourceENI,
+		Protocol: types.ProtocolTcp,
+		FilterAtDestination: &types.PathRequestFilter{
+			DestinationAddress:   &destIP,
+			DestinationPortRange: &types.RequestFilterPortRange{FromPort: &port, ToPort: &port},
+		},
+		TagSpecifications: []types.TagSpecification{
+			{ResourceType: types.ResourceTypeNetworkInsightsPath, Tags: tags},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create network insights path: %w", err)
+	}
+	pathID := stringValue(path.NetworkInsightsPath.NetworkInsightsPathId)
+
+	started, err := c.client.StartNetworkInsightsAnalysis(ctx, &ec2.StartNetworkInsightsAnalysisInput{
+		NetworkInsightsPathId: &pathID,
+		TagSpecifications: []types.TagSpecification{
+			{ResourceType: types.ResourceTypeNetworkInsightsAnalysis, Tags: tags},
+		},
+	})
+	if err != nil {
+		c.deleteInsightsPath(pathID, "")
+		return nil, fmt.Errorf("failed to start network insights analysis: %w", err)
+	}
+	analysisID := stringValue(started.NetworkInsightsAnalysis.NetworkInsightsAnalysisId)
+	if !keep {
+		defer c.deleteInsightsPath(pathID, analysisID)
+	}
+
+	deadline := time.Now().Add(reachabilityTimeout)
+	for {
+		out, err := c.client.DescribeNetworkInsightsAnalyses(ctx, &ec2.DescribeNetworkInsightsAnalysesInput{
+			NetworkInsightsAnalysisIds: []string{analysisID},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe network insights analysis %s: %w", analysisID, err)
+		}
+		if len(out.NetworkInsightsAnalyses) == 0 {
+			return nil, fmt.Errorf("network insights analysis %s not found", analysisID)
+		}
+		a := out.NetworkInsightsAnalyses[0]
+		switch a.Status {
+		case types.AnalysisStatusSucceeded:
+			result := reachabilityResult(a)
+			result.PathID = pathID
+			return result, nil
+		case types.AnalysisStatusFailed:
+			return nil, fmt.Errorf("network insights analysis %s failed: %s", analysisID, stringValue(a.StatusMessage))
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("network insights analysis %s still running after %s", analysisID, reachabilityTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(reachabilityPollInterval):
+		}
+	}
+}
+
+// insightsCleanupTimeout bounds how long deleteInsightsPath waits for a
+// running analysis to finish and retries the deletes.
+const insightsCleanupTimeout = 3 * time.Minute
+
+// insightsDeleteBackoff is the wait before each retry of a failed delete.
+var insightsDeleteBackoff = []time.Duration{2 * time.Second, 5 * time.Second, 10 * time.Second, 20 * time.Second}
+
+// deleteInsightsPath removes an analysis and its path. A running analysis can
+// be neither stopped nor deleted, and keeps its path from being deleted, so
+// it first waits for the analysis to finish. It runs on a fresh context so an
+// interrupted scan still cleans up, and ignores errors: the leftovers are
+// tagged CreatedBy=termiNATor and cost nothing.
+func (c *EC2Client) deleteInsightsPath(pathID, analysisID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), insightsCleanupTimeout)
+	defer cancel()
+	if analysisID != "" {
+		c.waitForAnalysis(ctx, analysisID)
+		retryDelete(ctx, func() error {
+			_, err := c.client.DeleteNetworkInsightsAnalysis(ctx, &ec2.DeleteNetworkInsightsAnalysisInput{NetworkInsightsAnalysisId: &analysisID})
+			return err
+		})
+	}
+	retryDelete(ctx, func() error {
+		_, err := c.client.DeleteNetworkInsightsPath(ctx, &ec2.DeleteNetworkInsightsPathInput{NetworkInsightsPathId: &pathID})
+		return err
+	})
+}
+
+// waitForAnalysis returns once an analysis is no longer running, cannot be
+// described, or ctx is done.
+func (c *EC2Client) waitForAnalysis(ctx context.Context, analysisID string) {
+	for {
+		out, err := c.client.DescribeNetworkInsightsAnalyses(ctx, &ec2.DescribeNetworkInsightsAnalysesInput{
+			NetworkInsightsAnalysisIds: []string{analysisID},
+		})
+		if err != nil || len(out.NetworkInsightsAnalyses) == 0 || out.NetworkInsightsAnalyses[0].Status != types.AnalysisStatusRunning {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reachabilityPollInterval):
+		}
+	}
+}
+
+// retryDelete calls del, backing off and retrying until it succeeds or finds
+// the resource already gone.
+func retryDelete(ctx context.Context, del func() error) error {
+	err := del()
+	for _, wait := range insightsDeleteBackoff {
+		if err == nil || isNotFound(err) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		err = del()
+	}
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// isNotFound reports an EC2 *.NotFound error code.
+func isNotFound(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && strings.HasSuffix(apiErr.ErrorCode(), ".NotFound")
+}
+
+// reachabilityResult extracts the verdict of a finished analysis: the first
+// route target on the forward path, or why no path was found.
+func reachabilityResult(a types.NetworkInsightsAnalysis) *pkgtypes.ReachabilityResult {
+	result := &pkgtypes.ReachabilityResult{
+		AnalysisID: stringValue(a.NetworkInsightsAnalysisId),
+		PathFound:  a.NetworkPathFound != nil && *a.NetworkPathFound,
+	}
+	for _, pc := range a.ForwardPathComponents {
+		if r := pc.RouteTableRoute; r != nil {
+			switch {
+			case r.NatGatewayId != nil:
+				result.Via = *r.NatGatewayId
+			case r.GatewayId != nil:
+				result.Via = *r.GatewayId
+			case r.TransitGatewayId != nil:
+				result.Via = *r.TransitGatewayId
+			case r.VpcPeeringConnectionId != nil:
+				result.Via = *r.VpcPeeringConnectionId
+			case r.NetworkInterfaceId != nil:
+				result.Via = *r.NetworkInterfaceId
+			}
+			if result.Via != "" {
+				break
+			}
+		}
+	}
+	if !result.PathFound {
+		var reasons []string
+		for _, e := range a.Explanations {
+			reason := stringValue(e.ExplanationCode)
+			if e.Component != nil && e.Component.Id != nil {
+				reason += " at " + *e.Component.Id
+			}
+			reasons = append(reasons, reason)
+		}
+		result.Explanation = strings.Join(reasons, "; ")
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestRetryDelete(t *testing.T) {
+	saved := insightsDeleteBackoff
+	insightsDeleteBackoff = []time.Duration{time.Millisecond, time.Millisecond}
+	t.Cleanup(func() { insightsDeleteBackoff = saved })
+
+	inUse := &smithy.GenericAPIError{Code: "InvalidParameterValue", Message: "path has analyses in progress"}
+	gone := &smithy.GenericAPIError{Code: "InvalidNetworkInsightsPathId.NotFound"}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"deleted at once", []error{nil}, 1, false},
+		{"in use, then deleted", []error{inUse, inUse, nil}, 3, false},
+		{"already gone", []error{gone}, 1, false},
+		{"gone after a retry", []error{inUse, gone}, 2, false},
+		{"gives up after the backoff", []error{inUse}, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryDelete(context.Background(), func() error {
+				err := tt.errs[min(calls, len(tt.errs)-1)]
+				calls++
+				return err
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("delete called %d times, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryDeleteCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	err := retryDelete(ctx, func() error {
+		calls++
+		return errors.New("DependencyViolation")
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("err = %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+}
@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Client wraps the S3 calls used to read Flow Logs delivered to a bucket
+type S3Client struct {
+	client *s3.Client
+}
+
+// NewS3Client creates a new S3 client wrapper
+func NewS3Client(client *s3.Client) *S3Client {
+	return &S3Client{client: client}
+}
+
+// ListObjectKeys returns every object key under prefix
+func (c *S3Client) ListObjectKeys(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket: &bucket,
+		Prefix: &prefix,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %w", bucket, prefix, err)
+		}
+		for _, obj := range page.Contents {
+			if obj.Key != nil {
+				keys = append(keys, *obj.Key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// GetObject opens an object for reading; the caller closes it
+func (c *S3Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	out, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read s3://%s/%s: %w", bucket, key, err)
+	}
+	return out.Body, nil
+}
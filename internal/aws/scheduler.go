@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler/types"
+)
+
+// deleteLogGroupTarget is the EventBridge Scheduler universal target that
+// calls CloudWatch Logs DeleteLogGroup, so no Lambda function is needed.
+const deleteLogGroupTarget = "arn:aws:scheduler:::aws-sdk:cloudwatchlogs:deleteLogGroup"
+
+// SchedulerClient wraps AWS EventBridge Scheduler API calls
+type SchedulerClient struct {
+	client *scheduler.Client
+}
+
+// NewSchedulerClient creates a new EventBridge Scheduler client wrapper
+func NewSchedulerClient(client *scheduler.Client) *SchedulerClient {
+	return &SchedulerClient{client: client}
+}
+
+// ScheduleLogGroupDeletion creates a one-time schedule that deletes
+// logGroupName at the given time and then deletes itself. roleARN is the
+// role the scheduler assumes to call DeleteLogGroup.
+func (c *SchedulerClient) ScheduleLogGroupDeletion(ctx context.Context, scheduleName, logGroupName string, at time.Time, roleARN string) error {
+	input, err := json.Marshal(map[string]string{"LogGroupName": logGroupName})
+	if err != nil {
+		return err
+	}
+
+	expression := fmt.Sprintf("at(%s)", at.UTC().Format("2006-01-02T15:04:05"))
+	target := deleteLogGroupTarget
+	payload := string(input)
+	description := fmt.Sprintf("termiNATor: delete kept log group %s", logGroupName)
+	timezone := "UTC"
+
+	_, err = c.client.CreateSchedule(ctx, &scheduler.CreateScheduleInput{
+		Name:                       &scheduleName,
+		Description:                &description,
+		ScheduleExpression:         &expression,
+		ScheduleExpressionTimezone: &timezone,
+		FlexibleTimeWindow:         &types.FlexibleTimeWindow{Mode: types.FlexibleTimeWindowModeOff},
+		ActionAfterCompletion:      types.ActionAfterCompletionDelete,
+		Target: &types.Target{
+			Arn:     &target,
+			RoleArn: &roleARN,
+			Input:   &payload,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create deletion schedule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// SSMClient wraps AWS Systems Manager API calls
+type SSMClient struct {
+	client *ssm.Client
+}
+
+// NewSSMClient creates a new Systems Manager client wrapper
+func NewSSMClient(client *ssm.Client) *SSMClient {
+	return &SSMClient{client: client}
+}
+
+// ManagedInstanceIDs returns the EC2 instances whose SSM Agent is online
+func (c *SSMClient) ManagedInstanceIDs(ctx context.Context) ([]string, error) {
+	paginator := ssm.NewDescribeInstanceInformationPaginator(c.client, &ssm.DescribeInstanceInformationInput{
+		Filters: []types.InstanceInformationStringFilter{
+			{Key: stringPtr("ResourceType"), Values: []string{"EC2Instance"}},
+			{Key: stringPtr("PingStatus"), Values: []string{"Online"}},
+		},
+	})
+
+	var ids []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe SSM managed instances: %w", err)
+		}
+		for _, info := range page.InstanceInformationList {
+			if info.InstanceId != nil {
+				ids = append(ids, *info.InstanceId)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// GetParameter returns a parameter's value, decrypting a SecureString
+func (c *SSMClient) GetParameter(ctx context.Context, name string) (string, error) {
+	decrypt := true
+	out, err := c.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           &name,
+		WithDecryption: &decrypt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get SSM parameter %s: %w", name, err)
+	}
+	if out.Parameter == nil {
+		return "", fmt.Errorf("SSM parameter %s not found", name)
+	}
+	return stringValue(out.Parameter.Value), nil
+}
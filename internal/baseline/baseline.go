@@ -0,0 +1,153 @@
+// Package baseline keeps the findings a team has accepted (by design, or
+// fixed another way), so deep scans stop reporting them. The file can be
+// pulled from and pushed to the shared history backend, so everyone running
+// scans, and CI, hides the same findings.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/doitintl/terminator/internal/manifest"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// SharedName is the baseline's object name in the shared backend.
+const SharedName = "baseline.json"
+
+// Accepted is one accepted finding. Empty AccountID, Region or VPCID match
+// any, so one entry can accept a finding everywhere.
+type Accepted struct {
+	AccountID  string    `json:"account_id,omitempty"`
+	Region     string    `json:"region,omitempty"`
+	Type       string    `json:"type"`
+	VPCID      string    `json:"vpc_id,omitempty"`
+	Service    string    `json:"service,omitempty"`
+	Reason     string    `json:"reason"`
+	AcceptedBy string    `json:"accepted_by,omitempty"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+func (a Accepted) key() string {
+	return a.AccountID + "|" + a.Region + "|" + a.Type + "|" + a.VPCID + "|" + a.Service
+}
+
+// Matches reports whether the entry accepts f in accountID/region.
+func (a Accepted) Matches(accountID, region string, f types.Finding) bool {
+	return a.Type == f.Type &&
+		(a.AccountID == "" || a.AccountID == accountID) &&
+		(a.Region == "" || a.Region == region) &&
+		(a.VPCID == "" || a.VPCID == f.VPCID) &&
+		(a.Service == "" || a.Service == f.Service)
+}
+
+// Baseline is the list of accepted findings. Revision counts the pushes to
+// the shared backend: a push is refused unless it builds on the latest one.
+type Baseline struct {
+	Revision int        `json:"revision"`
+	Accepted []Accepted `json:"accepted"`
+}
+
+// DefaultPath is the baseline file: baseline.json under
+// $TERMINAT_STATE_DIR, or <user config dir>/terminat/baseline.json.
+func DefaultPath() (string, error) {
+	if dir := os.Getenv(manifest.StateDirEnv); dir != "" {
+		return filepath.Join(dir, "baseline.json"), nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory for the findings baseline: %w", err)
+	}
+	return filepath.Join(base, "terminat", "baseline.json"), nil
+}
+
+// Load reads a baseline file; a missing file is an empty baseline.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Baseline{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, path)
+}
+
+// Parse decodes a baseline; name identifies it in errors.
+func Parse(data []byte, name string) (*Baseline, error) {
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s is not a termiNATor findings baseline: %w", name, err)
+	}
+	return &b, nil
+}
+
+// Encode returns the baseline as JSON.
+func (b *Baseline) Encode() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// Save writes the baseline file.
+func (b *Baseline) Save(path string) error {
+	data, err := b.Encode()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Accept adds an entry, replacing one for the same finding.
+func (b *Baseline) Accept(a Accepted) {
+	for i := range b.Accepted {
+		if b.Accepted[i].key() == a.key() {
+			b.Accepted[i] = a
+			return
+		}
+	}
+	b.Accepted = append(b.Accepted, a)
+	sort.SliceStable(b.Accepted, func(i, j int) bool { return b.Accepted[i].key() < b.Accepted[j].key() })
+}
+
+// Revoke removes the entries for a finding and returns how many it removed.
+func (b *Baseline) Revoke(a Accepted) int {
+	kept := b.Accepted[:0]
+	for _, e := range b.Accepted {
+		if e.key() != a.key() {
+			kept = append(kept, e)
+		}
+	}
+	removed := len(b.Accepted) - len(kept)
+	b.Accepted = kept
+	return removed
+}
+
+// Filter drops the findings the baseline accepts in accountID/region.
+func (b *Baseline) Filter(accountID, region string, findings []types.Finding) (kept []types.Finding, hidden int) {
+	if b == nil || len(b.Accepted) == 0 {
+		return findings, 0
+	}
+	for _, f := range findings {
+		if b.accepts(accountID, region, f) {
+			hidden++
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept, hidden
+}
+
+func (b *Baseline) accepts(accountID, region string, f types.Finding) bool {
+	for _, a := range b.Accepted {
+		if a.Matches(accountID, region, f) {
+			return true
+		}
+	}
+	return false
+}
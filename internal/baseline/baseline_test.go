@@ -0,0 +1,129 @@
+package baseline
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/internal/history"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestFilterMatchesScope(t *testing.T) {
+	b := &Baseline{}
+	b.Accept(Accepted{Type: "missing-endpoint", VPCID: "vpc-1", Service: "DynamoDB", Reason: "moving VPC"})
+	b.Accept(Accepted{AccountID: "111111111111", Type: "no-route", Reason: "by design"})
+
+	findings := []types.Finding{
+		{Type: "missing-endpoint", VPCID: "vpc-1", Service: "DynamoDB"},
+		{Type: "missing-endpoint", VPCID: "vpc-1", Service: "S3"},
+		{Type: "no-route", VPCID: "vpc-2", Service: "S3"},
+	}
+	kept, hidden := b.Filter("111111111111", "us-east-1", findings)
+	if hidden != 2 || len(kept) != 1 || kept[0].Service != "S3" || kept[0].Type != "missing-endpoint" {
+		t.Fatalf("unexpected filter result: hidden=%d kept=%+v", hidden, kept)
+	}
+	if _, hidden := b.Filter("222222222222", "us-east-1", findings); hidden != 1 {
+		t.Errorf("expected the account-scoped entry not to apply elsewhere, hidden=%d", hidden)
+	}
+
+	var none *Baseline
+	if kept, hidden := none.Filter("111111111111", "us-east-1", findings); hidden != 0 || len(kept) != 3 {
+		t.Error("expected a nil baseline to hide nothing")
+	}
+}
+
+func TestAcceptReplacesAndRevokeRemoves(t *testing.T) {
+	b := &Baseline{}
+	b.Accept(Accepted{Type: "missing-endpoint", VPCID: "vpc-1", Reason: "first"})
+	b.Accept(Accepted{Type: "missing-endpoint", VPCID: "vpc-1", Reason: "second"})
+	if len(b.Accepted) != 1 || b.Accepted[0].Reason != "second" {
+		t.Fatalf("expected the entry to be replaced, got %+v", b.Accepted)
+	}
+	if n := b.Revoke(Accepted{Type: "missing-endpoint"}); n != 0 {
+		t.Errorf("expected a differently scoped revoke to remove nothing, removed %d", n)
+	}
+	if n := b.Revoke(Accepted{Type: "missing-endpoint", VPCID: "vpc-1"}); n != 1 || len(b.Accepted) != 0 {
+		t.Errorf("expected the entry to be removed, removed %d, left %+v", n, b.Accepted)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "baseline.json")
+	if b, err := Load(path); err != nil || len(b.Accepted) != 0 {
+		t.Fatalf("expected a missing file to load as empty, got %+v, %v", b, err)
+	}
+	b := &Baseline{Revision: 3}
+	b.Accept(Accepted{Type: "no-route", Reason: "by design"})
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Revision != 3 || len(loaded.Accepted) != 1 {
+		t.Errorf("unexpected round trip: %+v", loaded)
+	}
+}
+
+// fakeShared is an in-memory history.Shared with versions.
+type fakeShared struct {
+	data    []byte
+	version int
+}
+
+func (f *fakeShared) Read(ctx context.Context, name string) ([]byte, string, error) {
+	if f.data == nil {
+		return nil, "", history.ErrNotFound
+	}
+	return f.data, strconv.Itoa(f.version), nil
+}
+
+func (f *fakeShared) Write(ctx context.Context, name string, data []byte, version string) error {
+	current := ""
+	if f.data != nil {
+		current = strconv.Itoa(f.version)
+	}
+	if version != current {
+		return history.ErrConflict
+	}
+	f.data = data
+	f.version++
+	return nil
+}
+
+func TestPushRefusesStaleBaseline(t *testing.T) {
+	ctx := context.Background()
+	shared := &fakeShared{}
+
+	alice, err := Pull(ctx, shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, _ := Pull(ctx, shared)
+
+	alice.Accept(Accepted{Type: "no-route", Reason: "by design"})
+	if err := Push(ctx, shared, alice); err != nil {
+		t.Fatalf("first push: %v", err)
+	}
+	if alice.Revision != 1 {
+		t.Errorf("expected revision 1 after the push, got %d", alice.Revision)
+	}
+
+	bob.Accept(Accepted{Type: "missing-endpoint", Reason: "later"})
+	err = Push(ctx, shared, bob)
+	if err == nil || !strings.Contains(err.Error(), "terminat baseline pull") {
+		t.Fatalf("expected a stale push to be refused, got %v", err)
+	}
+
+	team, err := Pull(ctx, shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team.Revision != 1 || len(team.Accepted) != 1 || team.Accepted[0].Type != "no-route" {
+		t.Errorf("expected the first push to be kept, got %+v", team)
+	}
+}
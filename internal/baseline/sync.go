@@ -0,0 +1,61 @@
+package baseline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/doitintl/terminator/internal/history"
+)
+
+// Pull returns the team's baseline from the shared backend, or an empty
+// baseline if none was pushed yet.
+func Pull(ctx context.Context, shared history.Shared) (*Baseline, error) {
+	b, _, err := pull(ctx, shared)
+	return b, err
+}
+
+func pull(ctx context.Context, shared history.Shared) (*Baseline, string, error) {
+	data, version, err := shared.Read(ctx, SharedName)
+	if errors.Is(err, history.ErrNotFound) {
+		return &Baseline{}, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	b, err := Parse(data, SharedName)
+	return b, version, err
+}
+
+// Push uploads b as the next revision of the team's baseline and bumps
+// b.Revision. It refuses when someone pushed since b was pulled, so their
+// changes are not lost: pull, re-apply, and push again.
+func Push(ctx context.Context, shared history.Shared, b *Baseline) error {
+	current, version, err := pull(ctx, shared)
+	if err != nil {
+		return err
+	}
+	if current.Revision != b.Revision {
+		return staleError(current.Revision, b.Revision)
+	}
+	next := *b
+	next.Revision++
+	data, err := next.Encode()
+	if err != nil {
+		return err
+	}
+	if err := shared.Write(ctx, SharedName, data, version); err != nil {
+		if errors.Is(err, history.ErrConflict) {
+			return fmt.Errorf("the shared baseline changed while pushing: %s", retryHint)
+		}
+		return err
+	}
+	b.Revision = next.Revision
+	return nil
+}
+
+const retryHint = "run terminat baseline pull, re-apply your changes and push again"
+
+func staleError(shared, local int) error {
+	return fmt.Errorf("the shared baseline is at revision %d but yours builds on revision %d: %s", shared, local, retryHint)
+}
@@ -0,0 +1,167 @@
+// Package bundle keeps the artifacts of the last deep scan and packs them,
+// redacted, into a diagnostic archive for support requests and bug reports.
+// The report in a bundle or artifact directory can be read back, so it can
+// be rendered without AWS access.
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doitintl/terminator/internal/manifest"
+	"github.com/doitintl/terminator/internal/redact"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// Files kept for the last run.
+const (
+	ReportFile  = "report.json"
+	QueriesFile = "queries.json"
+)
+
+// DefaultDir is where the last run's artifacts are kept: last-run under
+// $TERMINAT_STATE_DIR, or <user config dir>/terminat/last-run.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(manifest.StateDirEnv); dir != "" {
+		return filepath.Join(dir, "last-run"), nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory for scan artifacts: %w", err)
+	}
+	return filepath.Join(base, "terminat", "last-run"), nil
+}
+
+// SaveLastRun replaces the artifacts in dir with this run's report and raw
+// query results. Both are marshalled to JSON as given.
+func SaveLastRun(dir string, report, queries any) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	for name, v := range map[string]any{ReportFile: report, QueriesFile: queries} {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Info describes the tool and machine that produced a bundle.
+type Info struct {
+	Version string
+	// Environment holds name/value pairs such as OS, Go version and which
+	// AWS settings are present.
+	Environment map[string]string
+}
+
+// Write zips the artifacts in dir, redacted, together with version and
+// environment info.
+func Write(w io.Writer, dir string, info Info) error {
+	account, names := reportIdentity(dir)
+	red, err := redact.New(account)
+	if err != nil {
+		return err
+	}
+	red = red.WithNames(names...)
+
+	zw := zip.NewWriter(w)
+	found := 0
+	for _, name := range []string{ReportFile, QueriesFile} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		found++
+		if err := writeFile(zw, name, red.String(string(data))); err != nil {
+			return err
+		}
+	}
+	if found == 0 {
+		return fmt.Errorf("no scan artifacts in %s; run a deep scan first", dir)
+	}
+
+	if err := writeFile(zw, "version.txt", info.Version+"\n"); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(info.Environment))
+	for k := range info.Environment {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var env strings.Builder
+	for _, k := range keys {
+		env.WriteString(fmt.Sprintf("%s: %s\n", k, info.Environment[k]))
+	}
+	if err := writeFile(zw, "environment.txt", red.String(env.String())); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeFile(zw *zip.Writer, name, content string) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+// ReadReport returns the JSON report in a bundle archive or an artifact
+// directory such as the last run's.
+func ReadReport(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		data, err := os.ReadFile(filepath.Join(path, ReportFile))
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no %s in %s; run a deep scan first", ReportFile, path)
+		}
+		return data, err
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a directory nor a termiNATor bundle: %w", path, err)
+	}
+	defer zr.Close()
+	f, err := zr.Open(ReportFile)
+	if err != nil {
+		return nil, fmt.Errorf("no %s in bundle %s", ReportFile, path)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// reportIdentity reads the account ID and the account, VPC and NAT Gateway
+// names from the saved report, so the ID is redacted outside ARNs too and
+// the names at all. It returns "" when there is no report.
+func reportIdentity(dir string) (string, []string) {
+	data, err := os.ReadFile(filepath.Join(dir, ReportFile))
+	if err != nil {
+		return "", nil
+	}
+	var r struct {
+		AccountID string             `json:"account_id"`
+		NATs      []types.NATGateway `json:"nat_gateways"`
+		types.FriendlyNames
+	}
+	_ = json.Unmarshal(data, &r)
+	return r.AccountID, append(r.FriendlyNames.All(), types.NATNames(r.NATs)...)
+}
@@ -0,0 +1,99 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteRedactsArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	rep := map[string]any{"account_id": "123456789012", "account_alias": "payments-prod", "nat_gateways": []map[string]string{{"ID": "nat-0123456789abcdef0"}}}
+	queries := []map[string]any{{"log_group": "/terminator/run", "rows": []map[string]string{{"resolved_dst": "52.216.10.4", "total_bytes": "1048576"}}}}
+	if err := SaveLastRun(dir, rep, queries); err != nil {
+		t.Fatalf("SaveLastRun: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, dir, Info{Version: "1.2.3", Environment: map[string]string{"os": "linux"}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	for _, name := range []string{ReportFile, QueriesFile, "version.txt", "environment.txt"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("bundle is missing %s", name)
+		}
+	}
+	all := strings.Join([]string{files[ReportFile], files[QueriesFile]}, "\n")
+	for _, leaked := range []string{"123456789012", "payments-prod", "nat-0123456789abcdef0", "52.216.10.4"} {
+		if strings.Contains(all, leaked) {
+			t.Errorf("%q leaked into the bundle", leaked)
+		}
+	}
+	if !strings.Contains(files[QueriesFile], `"1048576"`) {
+		t.Error("numbers in query results should be kept")
+	}
+	if files["version.txt"] != "1.2.3\n" {
+		t.Errorf("unexpected version.txt: %q", files["version.txt"])
+	}
+}
+
+func TestWriteWithoutArtifacts(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, t.TempDir(), Info{}); err == nil || !strings.Contains(err.Error(), "run a deep scan first") {
+		t.Fatalf("expected a missing artifacts error, got %v", err)
+	}
+}
+
+func TestReadReportFromDirAndArchive(t *testing.T) {
+	dir := t.TempDir()
+	if err := SaveLastRun(dir, map[string]any{"region": "eu-west-1"}, []any{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadReport(dir)
+	if err != nil {
+		t.Fatalf("ReadReport(dir): %v", err)
+	}
+	if !strings.Contains(string(data), "eu-west-1") {
+		t.Errorf("report from dir = %s", data)
+	}
+
+	archive := filepath.Join(t.TempDir(), "bundle.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(f, dir, Info{Version: "1.2.3"}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	data, err = ReadReport(archive)
+	if err != nil {
+		t.Fatalf("ReadReport(zip): %v", err)
+	}
+	if !strings.Contains(string(data), "eu-west-1") {
+		t.Errorf("report from archive = %s", data)
+	}
+
+	if _, err := ReadReport(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without a report")
+	}
+}
@@ -0,0 +1,280 @@
+// Package cassette records the AWS API traffic of a run to a file and
+// replays it later without AWS, so quick and deep scans can run in CI
+// against recorded responses.
+//
+// A Recorder is an http.RoundTripper for the SDK clients' HTTP client. A
+// replayed request gets the first unused recorded response for the same
+// request, or failing that, for the same operation: bodies carrying the time
+// (Logs Insights windows, run IDs) differ between runs, so same-operation
+// calls are answered in the order they were recorded.
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// RecordEnv and ReplayEnv name a cassette file to record to, or replay from.
+const (
+	RecordEnv = "TERMINAT_RECORD"
+	ReplayEnv = "TERMINAT_REPLAY"
+)
+
+// Mode is whether a Recorder records or replays.
+type Mode int
+
+const (
+	ModeRecord Mode = iota
+	ModeReplay
+)
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is what a request is matched on.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Operation is the X-Amz-Target header of JSON protocols, or the Action
+	// of query protocols; empty for REST services such as S3.
+	Operation string `json:"operation,omitempty"`
+	Body      string `json:"body,omitempty"`
+}
+
+// Response is a recorded response. Body is base64 when BodyBase64 is set,
+// for bodies that are not text.
+type Response struct {
+	Status     int                 `json:"status"`
+	Header     map[string][]string `json:"header,omitempty"`
+	Body       string              `json:"body,omitempty"`
+	BodyBase64 bool                `json:"body_base64,omitempty"`
+}
+
+// Cassette is the file format: the interactions in the order they happened.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder records to or replays from a cassette file. It is safe for
+// concurrent use.
+type Recorder struct {
+	mode Mode
+	path string
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New returns a recorder for the cassette at path. Recording sends requests
+// through next (http.DefaultTransport when nil) and rewrites the file after
+// every response, so a run that dies still leaves what it recorded.
+// Replaying reads the file now.
+func New(path string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{mode: mode, path: path, next: next}
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("%s is not a cassette: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// FromEnv returns the recorder $TERMINAT_RECORD or $TERMINAT_REPLAY asks
+// for, or nil when neither is set.
+func FromEnv() (*Recorder, error) {
+	record, replay := os.Getenv(RecordEnv), os.Getenv(ReplayEnv)
+	switch {
+	case record != "" && replay != "":
+		return nil, fmt.Errorf("set %s or %s, not both", RecordEnv, ReplayEnv)
+	case record != "":
+		return New(record, ModeRecord, nil)
+	case replay != "":
+		return New(replay, ModeReplay, nil)
+	}
+	return nil, nil
+}
+
+// Mode reports whether r records or replays.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Path returns the cassette file.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Client returns an HTTP client sending through r.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip records or replays one request.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := requestKey(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, key)
+	}
+	return r.record(req, key)
+}
+
+func (r *Recorder) record(req *http.Request, key Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	rec := Response{Status: resp.StatusCode, Header: resp.Header.Clone()}
+	if utf8.Valid(body) {
+		rec.Body = string(body)
+	} else {
+		rec.Body, rec.BodyBase64 = base64.StdEncoding.EncodeToString(body), true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{Request: key, Response: rec})
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0o600)
+}
+
+func (r *Recorder) replay(req *http.Request, key Request) (*http.Response, error) {
+	r.mu.Lock()
+	i := r.match(key, true)
+	if i < 0 {
+		i = r.match(key, false)
+	}
+	if i >= 0 {
+		r.used[i] = true
+	}
+	r.mu.Unlock()
+	if i < 0 {
+		return nil, fmt.Errorf("cassette %s has no response left for %s %s %s", r.path, key.Method, key.URL, key.Operation)
+	}
+
+	rec := r.cassette.Interactions[i].Response
+	body := []byte(rec.Body)
+	if rec.BodyBase64 {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(rec.Body); err != nil {
+			return nil, fmt.Errorf("cassette %s: response %d: %w", r.path, i, err)
+		}
+	}
+	header := http.Header(rec.Header).Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// match returns the first unused interaction for key, comparing bodies when
+// exact is set, or -1.
+func (r *Recorder) match(key Request, exact bool) int {
+	for i, in := range r.cassette.Interactions {
+		got := in.Request
+		if r.used[i] || got.Method != key.Method || got.URL != key.URL || got.Operation != key.Operation {
+			continue
+		}
+		if exact && got.Body != key.Body {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// Remaining returns how many recorded responses were never replayed, so a
+// test can tell its flow made every call it recorded.
+func (r *Recorder) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, u := range r.used {
+		if !u {
+			n++
+		}
+	}
+	return n
+}
+
+// requestKey reads what req is matched on, leaving its body readable.
+func requestKey(req *http.Request) (Request, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return Request{}, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	u := *req.URL
+	u.User = nil
+	key := Request{
+		Method:    req.Method,
+		URL:       u.String(),
+		Operation: req.Header.Get("X-Amz-Target"),
+		Body:      string(body),
+	}
+	if key.Operation == "" && strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			key.Operation = form.Get("Action")
+		}
+	}
+	return key, nil
+}
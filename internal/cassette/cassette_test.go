@@ -0,0 +1,106 @@
+package cassette
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/doitintl/terminator/internal/aws"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		fmt.Fprintf(w, `{"call":%d,"echo":%q}`, calls, body)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "run.json")
+	rec, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, body := range []string{`{"startTime":1}`, `{"startTime":2}`} {
+		if got := post(t, rec.Client(), srv.URL, "Logs_20140328.StartQuery", body); !strings.Contains(got, fmt.Sprintf(`"call":%d`, i+1)) {
+			t.Fatalf("recorded response = %s", got)
+		}
+	}
+
+	replay, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The second recorded body is matched exactly, the first answers a body
+	// never seen, and nothing is left for a third call.
+	if got := post(t, replay.Client(), srv.URL, "Logs_20140328.StartQuery", `{"startTime":2}`); !strings.Contains(got, `"call":2`) {
+		t.Fatalf("exact match = %s", got)
+	}
+	if got := post(t, replay.Client(), srv.URL, "Logs_20140328.StartQuery", `{"startTime":3}`); !strings.Contains(got, `"call":1`) {
+		t.Fatalf("same-operation match = %s", got)
+	}
+	if replay.Remaining() != 0 {
+		t.Fatalf("remaining = %d", replay.Remaining())
+	}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("{}"))
+	req.Header.Set("X-Amz-Target", "Logs_20140328.StartQuery")
+	if _, err := replay.Client().Do(req); err == nil || !strings.Contains(err.Error(), "no response left") {
+		t.Fatalf("exhausted cassette: err = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("replay reached the server: %d calls", calls)
+	}
+}
+
+func TestReplayEC2Discovery(t *testing.T) {
+	rec, err := New(filepath.Join("testdata", "discover-nat-gateways.json"), ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := aws.NewEC2Client(ec2.New(ec2.Options{
+		Region:           "us-east-1",
+		HTTPClient:       rec.Client(),
+		Credentials:      credentials.NewStaticCredentialsProvider("REPLAY", "REPLAY", ""),
+		RetryMaxAttempts: 1,
+	}))
+
+	nats, err := client.DiscoverNATGateways(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nats) != 1 || nats[0].ID != "nat-0a1b2c3d" || nats[0].AvailabilityZone != "us-east-1a" ||
+		nats[0].NetworkInterfaceID != "eni-0a1b2c3d" || nats[0].Tags["Name"] != "egress-a" {
+		t.Fatalf("nats = %+v", nats)
+	}
+	if rec.Remaining() != 0 {
+		t.Fatalf("%d recorded responses never replayed", rec.Remaining())
+	}
+}
+
+func post(t *testing.T, client *http.Client, url, target, body string) string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Amz-Target", target)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,189 @@
+// Package chargeback bills NAT Gateway cost back to the teams that own it.
+// The projected cost and gateway endpoint savings of deep scan reports are
+// split by VPC and keyed by a tag on the NAT Gateways, such as CostCenter, so
+// central platform teams can charge NAT waste to its owners.
+package chargeback
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/config"
+	"github.com/doitintl/terminator/internal/report"
+)
+
+// DefaultTagKey is the tag owners are read from unless the [chargeback]
+// section of the config sets tag_key.
+const DefaultTagKey = "CostCenter"
+
+// Owner values for NAT Gateways without the tag, and for costs that could
+// only be measured across gateways of different owners.
+const (
+	Untagged = "(untagged)"
+	Mixed    = "(mixed)"
+)
+
+// Line is the cost one owner is billed in one VPC, or across all of its
+// VPCs once grouped with ByOwner.
+type Line struct {
+	Owner          string
+	AccountID      string
+	Region         string
+	VPCID          string
+	NATGateways    []string
+	MonthlyCost    float64
+	MonthlySavings float64
+}
+
+// TagKey returns the configured chargeback tag, or DefaultTagKey.
+func TagKey() string {
+	content, err := config.Read()
+	if err != nil {
+		return DefaultTagKey
+	}
+	if key := config.Section(content, "chargeback")["tag_key"]; key != "" {
+		return key
+	}
+	return DefaultTagKey
+}
+
+// Build splits the reports' NAT cost by owner and VPC, the owner being the
+// NAT Gateways' tagKey tag. Reports without a per-gateway split (older
+// reports, or scans of one sample) bill their whole estimate to the VPC and
+// owner their gateways share. Lines are sorted by owner, costliest first.
+func Build(reports []*report.Report, tagKey string) []Line {
+	byKey := map[string]*Line{}
+	var keys []string
+	for _, rep := range reports {
+		owners := make(map[string]string, len(rep.NATGateways))
+		for _, nat := range rep.NATGateways {
+			owners[nat.ID] = Untagged
+			if v := strings.TrimSpace(nat.Tags[tagKey]); v != "" {
+				owners[nat.ID] = v
+			}
+		}
+		for _, c := range costs(rep) {
+			owner := Mixed
+			for i, id := range c.NATGateways {
+				o, ok := owners[id]
+				if !ok {
+					o = Untagged
+				}
+				if i == 0 {
+					owner = o
+				} else if o != owner {
+					owner = Mixed
+				}
+			}
+			key := strings.Join([]string{owner, rep.AccountID, rep.Region, c.VPCID}, "|")
+			l := byKey[key]
+			if l == nil {
+				l = &Line{Owner: owner, AccountID: rep.AccountID, Region: rep.Region, VPCID: c.VPCID}
+				byKey[key] = l
+				keys = append(keys, key)
+			}
+			l.NATGateways = append(l.NATGateways, c.NATGateways...)
+			l.MonthlyCost += c.MonthlyCost
+			l.MonthlySavings += c.MonthlySavings
+		}
+	}
+
+	lines := make([]Line, 0, len(keys))
+	for _, key := range keys {
+		l := byKey[key]
+		l.NATGateways = uniqueSorted(l.NATGateways)
+		lines = append(lines, *l)
+	}
+	sortLines(lines)
+	return lines
+}
+
+// costs are a report's per-gateway costs, or one cost for all its gateways
+// from the overall estimate.
+func costs(rep *report.Report) []analysis.NATCost {
+	if len(rep.NATCosts) > 0 {
+		return rep.NATCosts
+	}
+	if rep.CostEstimate == nil || len(rep.NATGateways) == 0 {
+		return nil
+	}
+	c := analysis.NATCost{
+		VPCID:          rep.NATGateways[0].VPCID,
+		MonthlyCost:    rep.CostEstimate.CurrentMonthlyCost,
+		MonthlySavings: rep.CostEstimate.TotalSavingsMonthly,
+	}
+	for _, nat := range rep.NATGateways {
+		c.NATGateways = append(c.NATGateways, nat.ID)
+		if nat.VPCID != c.VPCID {
+			c.VPCID = ""
+		}
+	}
+	return []analysis.NATCost{c}
+}
+
+// ByOwner adds up each owner's lines across accounts, regions and VPCs.
+func ByOwner(lines []Line) []Line {
+	byOwner := map[string]*Line{}
+	var owners []string
+	for _, l := range lines {
+		o := byOwner[l.Owner]
+		if o == nil {
+			o = &Line{Owner: l.Owner}
+			byOwner[l.Owner] = o
+			owners = append(owners, l.Owner)
+		}
+		o.NATGateways = append(o.NATGateways, l.NATGateways...)
+		o.MonthlyCost += l.MonthlyCost
+		o.MonthlySavings += l.MonthlySavings
+	}
+	grouped := make([]Line, 0, len(owners))
+	for _, owner := range owners {
+		o := byOwner[owner]
+		o.NATGateways = uniqueSorted(o.NATGateways)
+		grouped = append(grouped, *o)
+	}
+	sortLines(grouped)
+	return grouped
+}
+
+// WriteCSV writes the lines with a header naming the tag they are keyed by.
+// Amounts are USD per month.
+func WriteCSV(w io.Writer, tagKey string, lines []Line) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{tagKey, "account_id", "region", "vpc_id", "nat_gateways", "monthly_nat_cost_usd", "projected_monthly_savings_usd"}); err != nil {
+		return err
+	}
+	for _, l := range lines {
+		record := []string{l.Owner, l.AccountID, l.Region, l.VPCID, strings.Join(l.NATGateways, " "),
+			fmt.Sprintf("%.2f", l.MonthlyCost), fmt.Sprintf("%.2f", l.MonthlySavings)}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func sortLines(lines []Line) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].Owner != lines[j].Owner {
+			return lines[i].Owner < lines[j].Owner
+		}
+		return lines[i].MonthlyCost > lines[j].MonthlyCost
+	})
+}
+
+func uniqueSorted(values []string) []string {
+	sort.Strings(values)
+	out := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package chargeback
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestBuild(t *testing.T) {
+	scanned := &report.Report{
+		AccountID: "111111111111",
+		Region:    "us-east-1",
+		NATGateways: []types.NATGateway{
+			{ID: "nat-a", VPCID: "vpc-1", Tags: map[string]string{"CostCenter": "payments"}},
+			{ID: "nat-b", VPCID: "vpc-2", Tags: map[string]string{"CostCenter": "search"}},
+			{ID: "nat-c", VPCID: "vpc-2"},
+		},
+		NATCosts: []analysis.NATCost{
+			{NATGateways: []string{"nat-a"}, VPCID: "vpc-1", MonthlyCost: 100, MonthlySavings: 40},
+			{NATGateways: []string{"nat-b"}, VPCID: "vpc-2", MonthlyCost: 50, MonthlySavings: 10},
+			{NATGateways: []string{"nat-c"}, VPCID: "vpc-2", MonthlyCost: 5},
+		},
+	}
+	older := &report.Report{
+		AccountID:    "222222222222",
+		Region:       "eu-west-1",
+		NATGateways:  []types.NATGateway{{ID: "nat-d", VPCID: "vpc-3", Tags: map[string]string{"CostCenter": "payments"}}},
+		CostEstimate: &analysis.CostEstimate{CurrentMonthlyCost: 300, TotalSavingsMonthly: 120},
+	}
+
+	lines := Build([]*report.Report{scanned, older}, "CostCenter")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d: %+v", len(lines), lines)
+	}
+	want := []struct {
+		owner, vpc string
+		cost       float64
+	}{
+		{Untagged, "vpc-2", 5},
+		{"payments", "vpc-3", 300},
+		{"payments", "vpc-1", 100},
+		{"search", "vpc-2", 50},
+	}
+	for i, w := range want {
+		if lines[i].Owner != w.owner || lines[i].VPCID != w.vpc || lines[i].MonthlyCost != w.cost {
+			t.Errorf("line %d = %+v, want %s in %s at %.0f", i, lines[i], w.owner, w.vpc, w.cost)
+		}
+	}
+
+	grouped := ByOwner(lines)
+	if len(grouped) != 3 || grouped[1].Owner != "payments" || grouped[1].MonthlyCost != 400 || grouped[1].MonthlySavings != 160 {
+		t.Fatalf("grouped = %+v", grouped)
+	}
+	if strings.Join(grouped[1].NATGateways, ",") != "nat-a,nat-d" {
+		t.Errorf("payments gateways = %v", grouped[1].NATGateways)
+	}
+}
+
+func TestBuildMixedOwners(t *testing.T) {
+	rep := &report.Report{
+		NATGateways: []types.NATGateway{
+			{ID: "nat-a", VPCID: "vpc-1", Tags: map[string]string{"Team": "a"}},
+			{ID: "nat-b", VPCID: "vpc-1", Tags: map[string]string{"Team": "b"}},
+		},
+		NATCosts: []analysis.NATCost{{NATGateways: []string{"nat-a", "nat-b"}, VPCID: "vpc-1", MonthlyCost: 10}},
+	}
+	lines := Build([]*report.Report{rep}, "Team")
+	if len(lines) != 1 || lines[0].Owner != Mixed {
+		t.Fatalf("lines = %+v, want one %s line", lines, Mixed)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	lines := []Line{{Owner: "payments", AccountID: "111111111111", Region: "us-east-1", VPCID: "vpc-1", NATGateways: []string{"nat-a", "nat-b"}, MonthlyCost: 100.456, MonthlySavings: 40}}
+	if err := WriteCSV(&buf, "CostCenter", lines); err != nil {
+		t.Fatal(err)
+	}
+	want := "CostCenter,account_id,region,vpc_id,nat_gateways,monthly_nat_cost_usd,projected_monthly_savings_usd\n" +
+		"payments,111111111111,us-east-1,vpc-1,nat-a nat-b,100.46,40.00\n"
+	if buf.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
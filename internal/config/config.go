@@ -0,0 +1,156 @@
+// Package config locates the termiNATor configuration document. It is
+// ~/.terminat/config.toml unless --config points at another file or at an
+// SSM Parameter Store parameter (ssm:///terminat/prod), so scan defaults and
+// notification targets can be managed centrally.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SSMScheme prefixes a --config source read from SSM Parameter Store.
+const SSMScheme = "ssm://"
+
+// override is the document loaded from --config, when set.
+var override *string
+
+// DefaultPath returns ~/.terminat/config.toml.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".terminat", "config.toml"), nil
+}
+
+// SSMParameter returns the parameter name of an ssm:///name source, and false
+// for a file path.
+func SSMParameter(source string) (string, bool) {
+	name, ok := strings.CutPrefix(source, SSMScheme)
+	if !ok {
+		return "", false
+	}
+	return name, true
+}
+
+// Use replaces ~/.terminat/config.toml with content for the rest of the run.
+func Use(content string) {
+	override = &content
+}
+
+// Read returns the configuration document: the one set with Use, or
+// ~/.terminat/config.toml. A missing file reads as empty.
+func Read() (string, error) {
+	if override != nil {
+		return *override, nil
+	}
+	path, err := DefaultPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Write replaces ~/.terminat/config.toml with content. The file can hold
+// credentials (--insecure-config), so only its owner may read it, even when
+// it was created with looser permissions.
+func Write(content string) error {
+	path, err := DefaultPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0o600)
+}
+
+// SetValue returns content with key = "value" in [section], replacing an
+// earlier value of key there and adding the section when it is missing.
+func SetValue(content, section, key, value string) string {
+	line := fmt.Sprintf("%s = %q", key, value)
+	lines := strings.Split(content, "\n")
+	inSection, insertAt := false, -1
+	for i, l := range lines {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "[") {
+			if inSection {
+				break
+			}
+			inSection = trimmed == "["+section+"]"
+			if inSection {
+				insertAt = i + 1
+			}
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		if k, _, ok := strings.Cut(trimmed, "="); ok && strings.TrimSpace(k) == key && !strings.HasPrefix(trimmed, "#") {
+			lines[i] = line
+			return strings.Join(lines, "\n")
+		}
+		if trimmed != "" {
+			insertAt = i + 1
+		}
+	}
+	if insertAt < 0 {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + "[" + section + "]\n" + line + "\n"
+	}
+	lines = append(lines[:insertAt], append([]string{line}, lines[insertAt:]...)...)
+	return strings.Join(lines, "\n")
+}
+
+// Section returns the key = value lines of one [section]. Quotes are
+// stripped and a list such as ["vpc-1", "vpc-2"] becomes "vpc-1,vpc-2", the
+// form slice flags accept.
+func Section(content, name string) map[string]string {
+	values := map[string]string{}
+	inSection := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inSection = line == "["+name+"]"
+			continue
+		}
+		if !inSection || line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		val := strings.TrimSpace(parts[1])
+		if list, ok := strings.CutPrefix(val, "["); ok {
+			items := strings.Split(strings.TrimSuffix(list, "]"), ",")
+			kept := items[:0]
+			for _, item := range items {
+				if item = strings.Trim(strings.TrimSpace(item), "\""); item != "" {
+					kept = append(kept, item)
+				}
+			}
+			val = strings.Join(kept, ",")
+		} else {
+			val = strings.Trim(val, "\"")
+		}
+		values[key] = val
+	}
+	return values
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSection(t *testing.T) {
+	content := `
+[notify.slack]
+webhook_url = "https://hooks.slack.com/services/x"
+
+[scan]
+# central defaults
+min-savings = 25
+exclude-vpc-ids = ["vpc-1", "vpc-2"]
+notify = []
+report-lang = "ja"
+
+[jira]
+project = "NET"
+`
+	got := Section(content, "scan")
+	want := map[string]string{
+		"min-savings":     "25",
+		"exclude-vpc-ids": "vpc-1,vpc-2",
+		"notify":          "",
+		"report-lang":     "ja",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d keys, got %v", len(want), got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestSSMParameter(t *testing.T) {
+	if name, ok := SSMParameter("ssm:///terminat/prod"); !ok || name != "/terminat/prod" {
+		t.Errorf("unexpected parameter %q, %v", name, ok)
+	}
+	if _, ok := SSMParameter("/etc/terminat.toml"); ok {
+		t.Error("expected a file path not to be an SSM parameter")
+	}
+}
+
+func TestUseReplacesDefaultFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer func() { override = nil }()
+
+	if content, err := Read(); err != nil || content != "" {
+		t.Fatalf("expected a missing default file to read as empty, got %q, %v", content, err)
+	}
+	Use("[scan]\nduration = 30\n")
+	content, err := Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Section(content, "scan")["duration"] != "30" {
+		t.Errorf("expected the --config document, got %q", content)
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	content := `[notify.ops]
+type = "webhook"
+secret = "old"
+
+[scan]
+min-savings = 25
+`
+	got := SetValue(content, "notify.ops", "secret", "new")
+	if Section(got, "notify.ops")["secret"] != "new" || Section(got, "scan")["min-savings"] != "25" {
+		t.Errorf("replacing a value:\n%s", got)
+	}
+
+	got = SetValue(content, "notify.ops", "header.Authorization", "Bearer abc")
+	if s := Section(got, "notify.ops"); s["header.Authorization"] != "Bearer abc" || s["secret"] != "old" {
+		t.Errorf("adding a value:\n%s", got)
+	}
+	if len(Section(got, "scan")) != 1 {
+		t.Errorf("added value leaked into [scan]:\n%s", got)
+	}
+
+	got = SetValue(content, "datahub", "api_key", "k")
+	if Section(got, "datahub")["api_key"] != "k" || Section(got, "notify.ops")["secret"] != "old" {
+		t.Errorf("adding a section:\n%s", got)
+	}
+}
+
+func TestWriteIsPrivate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("[scan]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Write("[datahub]\napi_key = \"k\"\n"); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Errorf("config.toml mode = %o, want 600", perm)
+	}
+}
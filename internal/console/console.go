@@ -0,0 +1,70 @@
+// Package console builds AWS Management Console links to the resources
+// reports mention, so reviewers can open them in one click. Links point to
+// the console of the region's partition (aws, aws-cn or aws-us-gov).
+package console
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// vpcPages are the VPC console pages of each resource type, by ID prefix.
+var vpcPages = map[string]string{
+	"nat":    "NatGatewayDetails:natGatewayId=",
+	"vpce":   "EndpointDetails:vpcEndpointId=",
+	"rtb":    "RouteTableDetails:RouteTableId=",
+	"vpc":    "VpcDetails:VpcId=",
+	"subnet": "SubnetDetails:subnetId=",
+}
+
+// idPattern matches the resource IDs URL links, as AWS formats them.
+var idPattern = regexp.MustCompile(`\b(?:nat|vpce|rtb|vpc|subnet)-[0-9a-f]{8,17}\b`)
+
+// host is the console of region's partition.
+func host(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "https://" + region + ".console.amazonaws.cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "https://console.amazonaws-us-gov.com"
+	default:
+		return "https://" + region + ".console.aws.amazon.com"
+	}
+}
+
+// URL returns the console page of the NAT Gateway, VPC endpoint, route
+// table, VPC or subnet id in region, or "" for any other ID.
+func URL(region, id string) string {
+	prefix, _, ok := strings.Cut(id, "-")
+	page, known := vpcPages[prefix]
+	if !ok || !known || region == "" {
+		return ""
+	}
+	return host(region) + "/vpcconsole/home?region=" + region + "#" + page + id
+}
+
+// LogGroupURL returns the console page of the CloudWatch Logs log group
+// name in region.
+func LogGroupURL(region, name string) string {
+	if region == "" || name == "" {
+		return ""
+	}
+	// The console reads the log group from its URL fragment, escaped twice
+	// with '$' in place of '%'.
+	escaped := strings.ReplaceAll(url.QueryEscape(url.QueryEscape(name)), "%", "$")
+	return host(region) + "/cloudwatch/home?region=" + region + "#logsV2:log-groups/log-group/" + escaped
+}
+
+// IDs returns the resource IDs in text that URL links, in order, once each.
+func IDs(text string) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, id := range idPattern.FindAllString(text, -1) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
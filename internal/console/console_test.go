@@ -0,0 +1,39 @@
+package console
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		region, id, want string
+	}{
+		{"us-east-1", "nat-0123456789abcdef0", "https://us-east-1.console.aws.amazon.com/vpcconsole/home?region=us-east-1#NatGatewayDetails:natGatewayId=nat-0123456789abcdef0"},
+		{"eu-west-1", "rtb-0abc1234", "https://eu-west-1.console.aws.amazon.com/vpcconsole/home?region=eu-west-1#RouteTableDetails:RouteTableId=rtb-0abc1234"},
+		{"cn-north-1", "vpce-0abc1234", "https://cn-north-1.console.amazonaws.cn/vpcconsole/home?region=cn-north-1#EndpointDetails:vpcEndpointId=vpce-0abc1234"},
+		{"us-gov-west-1", "vpc-0abc1234", "https://console.amazonaws-us-gov.com/vpcconsole/home?region=us-gov-west-1#VpcDetails:VpcId=vpc-0abc1234"},
+		{"us-east-1", "sg-0abc1234", ""},
+		{"", "nat-0abc1234", ""},
+	}
+	for _, tt := range tests {
+		if got := URL(tt.region, tt.id); got != tt.want {
+			t.Errorf("URL(%q, %q) = %q, want %q", tt.region, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestLogGroupURL(t *testing.T) {
+	want := "https://us-east-1.console.aws.amazon.com/cloudwatch/home?region=us-east-1#logsV2:log-groups/log-group/$252Faws$252Fvpc$252Fterminat-20260101"
+	if got := LogGroupURL("us-east-1", "/aws/vpc/terminat-20260101"); got != want {
+		t.Errorf("LogGroupURL = %q, want %q", got, want)
+	}
+}
+
+func TestIDs(t *testing.T) {
+	text := "aws ec2 modify-vpc-endpoint --vpc-endpoint-id vpce-0abc1234 --add-route-table-ids rtb-0aaa1111 rtb-0bbb2222 rtb-0aaa1111 # in vpc-endpoint vpc-0ccc3333"
+	want := []string{"vpce-0abc1234", "rtb-0aaa1111", "rtb-0bbb2222", "vpc-0ccc3333"}
+	if got := IDs(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("IDs = %v, want %v", got, want)
+	}
+}
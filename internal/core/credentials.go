@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+)
+
+// ErrCredentialExpiryUnknown is returned by CredentialExpiry for session
+// credentials that carry no expiry: a session token in ~/.aws/credentials,
+// or exported without AWS_CREDENTIAL_EXPIRATION.
+var ErrCredentialExpiryUnknown = errors.New("AWS session credentials have no known expiry")
+
+// sessionCredentials are the credentials whose expiry bounds a scan: the
+// profile's own, or the MFA-gated first role of the chain, which can't be
+// renewed without a new code. Roles assumed without MFA renew themselves
+// from the credentials before them.
+type sessionCredentials struct {
+	provider awssdk.CredentialsProvider
+	mfa      bool
+	// ssoCacheKey names the cached IAM Identity Center token SSO
+	// credentials are renewed with: the sso_session name, or the start URL
+	// of profiles without one.
+	ssoCacheKey string
+}
+
+// renewingSources are the credential sources the SDK renews on its own
+// before they expire. SSO credentials renew only while the cached IAM
+// Identity Center token lasts, which CredentialExpiry checks separately.
+// credential_process credentials are not listed: the SDK runs the process
+// again, but nothing says it can hand out a new session (it may need an
+// MFA code or reuse its own cache).
+var renewingSources = []string{
+	stscreds.ProviderName,
+	stscreds.WebIdentityProviderName,
+	ec2rolecreds.ProviderName,
+	endpointcreds.ProviderName,
+	ssocreds.ProviderName,
+}
+
+// CredentialExpiry returns when the scanner's credentials stop working: the
+// expiry of session credentials nothing renews, such as an exported
+// AWS_SESSION_TOKEN, credential_process output or an MFA-gated role
+// session, or of the IAM Identity Center token SSO credentials are renewed
+// with. It returns the zero time when the credentials renew themselves or
+// never expire, and ErrCredentialExpiryUnknown for session credentials
+// without an expiry.
+func (s *Scanner) CredentialExpiry(ctx context.Context) (time.Time, error) {
+	if s.session.provider == nil {
+		return time.Time{}, nil
+	}
+	creds, err := s.session.provider.Retrieve(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if strings.HasPrefix(creds.Source, ssocreds.ProviderName) && s.session.ssoCacheKey != "" {
+		path, err := ssocreds.StandardCachedTokenFilepath(s.session.ssoCacheKey)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return ssoTokenExpiry(path)
+	}
+	expires := sessionExpiry(creds, s.session.mfa, os.Getenv)
+	if expires.IsZero() && creds.SessionToken != "" && !creds.CanExpire {
+		return time.Time{}, ErrCredentialExpiryUnknown
+	}
+	return expires, nil
+}
+
+// ssoTokenExpiry reads when the cached IAM Identity Center token at path
+// expires. Tokens of sso_session profiles that carry a refresh token are
+// renewed by the SDK, so they count as not expiring; a missing cache file
+// is left for the SDK to report.
+func ssoTokenExpiry(path string) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read cached SSO token: %w", err)
+	}
+	var token struct {
+		ExpiresAt    string `json:"expiresAt"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse cached SSO token %s: %w", path, err)
+	}
+	if token.RefreshToken != "" {
+		return time.Time{}, nil
+	}
+	at, err := time.Parse(time.RFC3339, token.ExpiresAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse cached SSO token expiry %q: %w", token.ExpiresAt, err)
+	}
+	return at, nil
+}
+
+// ssoCacheKey is the cache key of the IAM Identity Center token behind the
+// profile cfg was loaded from, or "" when it does not use SSO.
+func ssoCacheKey(cfg awssdk.Config) string {
+	for _, src := range cfg.ConfigSources {
+		shared, ok := src.(config.SharedConfig)
+		if !ok || shared.SSOAccountID == "" {
+			continue
+		}
+		if shared.SSOSessionName != "" {
+			return shared.SSOSessionName
+		}
+		return shared.SSOStartURL
+	}
+	return ""
+}
+
+// sessionExpiry is the expiry of creds unless their source renews them.
+// Environment credentials carry no expiry of their own; tools exporting
+// them (aws-vault, aws configure export-credentials) set
+// AWS_CREDENTIAL_EXPIRATION.
+func sessionExpiry(creds awssdk.Credentials, mfa bool, getenv func(string) string) time.Time {
+	switch {
+	case creds.Source == config.CredentialsSourceName:
+		at, err := time.Parse(time.RFC3339, strings.TrimSpace(getenv("AWS_CREDENTIAL_EXPIRATION")))
+		if err != nil {
+			return time.Time{}
+		}
+		return at
+	case !creds.CanExpire:
+		return time.Time{}
+	case mfa:
+		return creds.Expires
+	}
+	for _, source := range renewingSources {
+		if strings.HasPrefix(creds.Source, source) {
+			return time.Time{}
+		}
+	}
+	return creds.Expires
+}
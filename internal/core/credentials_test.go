@@ -0,0 +1,65 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestSessionExpiry(t *testing.T) {
+	expires := time.Date(2024, 3, 14, 13, 0, 0, 0, time.UTC)
+	env := map[string]string{"AWS_CREDENTIAL_EXPIRATION": "2024-03-14T12:30:00Z"}
+	tests := []struct {
+		name  string
+		creds awssdk.Credentials
+		mfa   bool
+		env   map[string]string
+		want  time.Time
+	}{
+		{"static keys", awssdk.Credentials{Source: "SharedConfigCredentials: ~/.aws/credentials"}, false, nil, time.Time{}},
+		{"env with expiration", awssdk.Credentials{Source: "EnvConfigCredentials"}, false, env, time.Date(2024, 3, 14, 12, 30, 0, 0, time.UTC)},
+		{"env without expiration", awssdk.Credentials{Source: "EnvConfigCredentials"}, false, nil, time.Time{}},
+		{"sso renews", awssdk.Credentials{Source: "SSOProvider", CanExpire: true, Expires: expires}, false, nil, time.Time{}},
+		{"instance role renews", awssdk.Credentials{Source: "EC2RoleProvider", CanExpire: true, Expires: expires}, false, nil, time.Time{}},
+		{"assumed role renews", awssdk.Credentials{Source: "AssumeRoleProvider", CanExpire: true, Expires: expires}, false, nil, time.Time{}},
+		{"mfa role", awssdk.Credentials{Source: "AssumeRoleProvider", CanExpire: true, Expires: expires}, true, nil, expires},
+		{"unknown expiring source", awssdk.Credentials{Source: "custom", CanExpire: true, Expires: expires}, false, nil, expires},
+		{"credential_process", awssdk.Credentials{Source: "ProcessProvider", CanExpire: true, Expires: expires}, false, nil, expires},
+	}
+	for _, tt := range tests {
+		got := sessionExpiry(tt.creds, tt.mfa, func(k string) string { return tt.env[k] })
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: sessionExpiry = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSSOTokenExpiry(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	got, err := ssoTokenExpiry(write("legacy.json", `{"accessToken":"t","expiresAt":"2024-03-14T12:30:00Z"}`))
+	if err != nil || !got.Equal(time.Date(2024, 3, 14, 12, 30, 0, 0, time.UTC)) {
+		t.Errorf("legacy token expiry = %v, %v; want 2024-03-14T12:30:00Z", got, err)
+	}
+	got, err = ssoTokenExpiry(write("session.json", `{"accessToken":"t","expiresAt":"2024-03-14T12:30:00Z","refreshToken":"r"}`))
+	if err != nil || !got.IsZero() {
+		t.Errorf("refreshable token expiry = %v, %v; want zero", got, err)
+	}
+	got, err = ssoTokenExpiry(filepath.Join(dir, "missing.json"))
+	if err != nil || !got.IsZero() {
+		t.Errorf("missing token expiry = %v, %v; want zero", got, err)
+	}
+	if _, err := ssoTokenExpiry(write("bad.json", `{"expiresAt":"soon"}`)); err == nil {
+		t.Error("expected an error for an unparseable expiry")
+	}
+}
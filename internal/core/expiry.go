@@ -0,0 +1,174 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/doitintl/terminator/internal/naming"
+)
+
+// ExpiresAtTag records, in RFC 3339 UTC, when a created Flow Log or log
+// group is no longer needed. `terminat cleanup --expired` and `terminat
+// watch` delete what is past it, in case a scan died without cleaning up.
+const ExpiresAtTag = "ExpiresAt"
+
+// NeverExpires is the ExpiresAt value of log groups kept on purpose.
+const NeverExpires = "never"
+
+// ExpiryGrace is added to the collection time of a scan's resources before
+// they expire, covering Flow Logs startup, analysis and the cleanup prompt.
+const ExpiryGrace = 6 * time.Hour
+
+// stackNameTag is set by CloudFormation on the resources of a stack.
+const stackNameTag = "aws:cloudformation:stack-name"
+
+// WithResourceExpiry tags every Flow Log and log group the scanner creates
+// with an ExpiresAt of their creation time plus ttl.
+func WithResourceExpiry(ttl time.Duration) Option {
+	return func(o *scannerOptions) {
+		o.resourceTTL = ttl
+	}
+}
+
+// Expired reports whether tags, of a resource termiNATor created, carry an
+// ExpiresAt at or before now. Missing, unparsable and NeverExpires values
+// never expire.
+func Expired(tags map[string]string, now time.Time) bool {
+	if tags["CreatedBy"] != "termiNATor" {
+		return false
+	}
+	at, err := time.Parse(time.RFC3339, tags[ExpiresAtTag])
+	if err != nil {
+		return false
+	}
+	return !at.After(now)
+}
+
+// ExpiredResources are the termiNATor resources past their ExpiresAt tag.
+type ExpiredResources struct {
+	FlowLogIDs []string
+	// Stacks manage expired Flow Logs or log groups. Their Flow Logs are
+	// deleted with the stack; their log groups are retained by it and also
+	// listed in LogGroups.
+	Stacks    []string
+	LogGroups []string
+}
+
+// Empty reports whether nothing has expired.
+func (e *ExpiredResources) Empty() bool {
+	return len(e.FlowLogIDs) == 0 && len(e.Stacks) == 0 && len(e.LogGroups) == 0
+}
+
+// FindExpiredResources lists the Flow Logs and log groups termiNATor created
+// whose ExpiresAt is at or before now.
+func (s *Scanner) FindExpiredResources(ctx context.Context, now time.Time) (*ExpiredResources, error) {
+	found := &ExpiredResources{}
+	stacks := map[string]bool{}
+
+	flowLogs, err := s.ec2Client.ListTerminatorFlowLogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, fl := range flowLogs {
+		if !Expired(fl.Tags, now) {
+			continue
+		}
+		if stack := fl.Tags[stackNameTag]; stack != "" {
+			stacks[stack] = true
+			continue
+		}
+		found.FlowLogIDs = append(found.FlowLogIDs, fl.ID)
+	}
+
+	logGroups, err := s.cwlClient.LogGroupTags(ctx, naming.LogGroupName(""))
+	if err != nil {
+		return nil, err
+	}
+	for name, tags := range logGroups {
+		if !Expired(tags, now) {
+			continue
+		}
+		if stack := tags[stackNameTag]; stack != "" {
+			// Deleting the stack keeps the log group, even once the stack
+			// is gone
+			stacks[stack] = true
+		}
+		found.LogGroups = append(found.LogGroups, name)
+	}
+
+	for stack := range stacks {
+		found.Stacks = append(found.Stacks, stack)
+	}
+	sort.Strings(found.FlowLogIDs)
+	sort.Strings(found.Stacks)
+	sort.Strings(found.LogGroups)
+	return found, nil
+}
+
+// DeleteExpiredResources deletes what FindExpiredResources found: the Flow
+// Logs first, then the stacks and log groups. A log group still written to
+// by Flow Logs that haven't expired is left alone. It returns the log
+// groups it skipped.
+func (s *Scanner) DeleteExpiredResources(ctx context.Context, expired *ExpiredResources) (skipped []string, err error) {
+	if s.readOnly {
+		return nil, ErrReadOnly
+	}
+	if err := s.ec2Client.DeleteFlowLogs(ctx, expired.FlowLogIDs); err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, stack := range expired.Stacks {
+		if err := s.cfnClient.DeleteFlowLogsStack(ctx, stack); err != nil {
+			errs = append(errs, fmt.Errorf("stack %s: %w", stack, err))
+		}
+	}
+	deleted := make(map[string]bool, len(expired.FlowLogIDs))
+	for _, id := range expired.FlowLogIDs {
+		deleted[id] = true
+	}
+	for _, name := range expired.LogGroups {
+		active, err := s.ec2Client.CheckActiveFlowLogs(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("log group %s: %w", name, err))
+			continue
+		}
+		if inUse(active, deleted) {
+			skipped = append(skipped, name)
+			continue
+		}
+		if err := s.cwlClient.DeleteLogGroup(ctx, name); err != nil {
+			errs = append(errs, fmt.Errorf("log group %s: %w", name, err))
+		}
+	}
+	return skipped, errors.Join(errs...)
+}
+
+// inUse reports whether any of the active Flow Logs is not being deleted.
+func inUse(active []string, deleted map[string]bool) bool {
+	for _, id := range active {
+		if !deleted[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// SetLogGroupExpiry retags a kept log group to expire at at, or never when
+// at is zero, so expiry sweeps leave it alone until then.
+func (s *Scanner) SetLogGroupExpiry(ctx context.Context, logGroupName string, at time.Time) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	return s.cwlClient.TagLogGroup(ctx, logGroupName, map[string]string{ExpiresAtTag: expiryValue(at)})
+}
+
+// expiryValue is the ExpiresAt tag value for at.
+func expiryValue(at time.Time) string {
+	if at.IsZero() {
+		return NeverExpires
+	}
+	return at.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		tags map[string]string
+		want bool
+	}{
+		{"past", map[string]string{"CreatedBy": "termiNATor", ExpiresAtTag: "2024-03-14T11:59:00Z"}, true},
+		{"now", map[string]string{"CreatedBy": "termiNATor", ExpiresAtTag: "2024-03-14T12:00:00Z"}, true},
+		{"future", map[string]string{"CreatedBy": "termiNATor", ExpiresAtTag: "2024-03-14T12:01:00Z"}, false},
+		{"never", map[string]string{"CreatedBy": "termiNATor", ExpiresAtTag: NeverExpires}, false},
+		{"untagged", map[string]string{"CreatedBy": "termiNATor"}, false},
+		{"not ours", map[string]string{ExpiresAtTag: "2024-03-14T11:59:00Z"}, false},
+	}
+	for _, tt := range tests {
+		if got := Expired(tt.tags, now); got != tt.want {
+			t.Errorf("%s: Expired = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCreateTagsExpiresAt(t *testing.T) {
+	s := &Scanner{resourceTTL: 2 * time.Hour}
+	at, err := time.Parse(time.RFC3339, s.createTags()[ExpiresAtTag])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(at); d < 119*time.Minute || d > 2*time.Hour {
+		t.Errorf("ExpiresAt %s is not 2h from now", at)
+	}
+	if tags := (&Scanner{}).createTags(); tags != nil {
+		t.Errorf("createTags without expiry = %v, want nil", tags)
+	}
+}
+
+func TestDeletionScheduleName(t *testing.T) {
+	long := "/terminator/" + strings.Repeat("flowlogs-", 10)
+	tests := []struct {
+		name         string
+		logGroupName string
+		want         string
+	}{
+		{"last path element", "/terminator/flowlogs-1700000000", "flowlogs-1700000000-expiry"},
+		{"no path", "flowlogs", "flowlogs-expiry"},
+		{"allowed punctuation kept", "/a/run_1.v2-x", "run_1.v2-x-expiry"},
+		{"other characters replaced", "/terminator/team a#1:é", "team-a-1---expiry"},
+		{"exactly at the limit", "/x/" + strings.Repeat("a", 57), strings.Repeat("a", 57) + "-expiry"},
+	}
+	for _, tt := range tests {
+		if got := deletionScheduleName(tt.logGroupName); got != tt.want {
+			t.Errorf("%s: deletionScheduleName(%q) = %q, want %q", tt.name, tt.logGroupName, got, tt.want)
+		}
+	}
+
+	got := deletionScheduleName(long + "a")
+	if len(got) != 64 || !strings.HasPrefix(got, "flowlogs-flowlogs-") || !strings.HasSuffix(got, "-expiry") {
+		t.Errorf("long name = %q (%d characters), want 64 ending in -expiry", got, len(got))
+	}
+	if got != deletionScheduleName(long+"a") {
+		t.Error("the name of a long log group is not stable")
+	}
+	// Names differing only past the cut still get distinct schedules
+	if other := deletionScheduleName(long + "b"); other == got {
+		t.Errorf("%q and %q both map to %q", long+"a", long+"b", got)
+	}
+	if other := deletionScheduleName("/other" + long + "a"); other == got {
+		t.Errorf("log groups in different paths both map to %q", got)
+	}
+	if !regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,64}$`).MatchString(got) {
+		t.Errorf("%q is not a valid schedule name", got)
+	}
+}
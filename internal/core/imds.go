@@ -0,0 +1,27 @@
+package core
+
+import (
+	"os"
+	"strings"
+)
+
+// ec2Markers are files whose content starts with the prefix only on EC2:
+// the DMI vendor of Nitro instances and the hypervisor UUID of Xen ones.
+var ec2Markers = []struct{ path, prefix string }{
+	{"/sys/devices/virtual/dmi/id/sys_vendor", "Amazon EC2"},
+	{"/sys/devices/virtual/dmi/id/board_vendor", "Amazon EC2"},
+	{"/sys/hypervisor/uuid", "ec2"},
+}
+
+// onEC2 reports whether this looks like an EC2 instance, where instance
+// profile credentials come from the Instance Metadata Service. It only reads
+// local files, so it costs nothing off EC2.
+func onEC2() bool {
+	for _, m := range ec2Markers {
+		data, err := os.ReadFile(m.path)
+		if err == nil && strings.HasPrefix(strings.TrimSpace(string(data)), m.prefix) {
+			return true
+		}
+	}
+	return false
+}
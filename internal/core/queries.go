@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// maxRecordedRows caps the rows kept per query, so the raw-message fallback
+// doesn't hold tens of thousands of Flow Log lines in a diagnostic bundle.
+const maxRecordedRows = 1000
+
+// QueryRecord is a Logs Insights query the scanner ran and its raw rows,
+// kept for diagnostic bundles.
+type QueryRecord struct {
+	LogGroup  string              `json:"log_group"`
+	Query     string              `json:"query"`
+	StartTime int64               `json:"start_time"`
+	EndTime   int64               `json:"end_time"`
+	Rows      []map[string]string `json:"rows"`
+	// TruncatedRows counts rows dropped beyond maxRecordedRows.
+	TruncatedRows int    `json:"truncated_rows,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// runQuery runs a Logs Insights query and records it with its results.
+func (s *Scanner) runQuery(ctx context.Context, logGroupName string, startTime, endTime int64, query string) ([][]cwltypes.ResultField, error) {
+	results, err := s.cwlClient.RunQuery(ctx, logGroupName, startTime, endTime, query)
+
+	rec := QueryRecord{LogGroup: logGroupName, Query: query, StartTime: startTime, EndTime: endTime}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	for i, row := range results {
+		if i == maxRecordedRows {
+			rec.TruncatedRows = len(results) - i
+			break
+		}
+		fields := make(map[string]string, len(row))
+		for _, f := range row {
+			if f.Field != nil && f.Value != nil {
+				fields[*f.Field] = *f.Value
+			}
+		}
+		rec.Rows = append(rec.Rows, fields)
+	}
+
+	s.queryMu.Lock()
+	s.queries = append(s.queries, rec)
+	s.queryMu.Unlock()
+	return results, err
+}
+
+// queryRecorder runs queries for analysis backends through runQuery, so
+// they are recorded too.
+type queryRecorder struct{ s *Scanner }
+
+func (r queryRecorder) RunQuery(ctx context.Context, logGroupName string, startTime, endTime int64, query string) ([][]cwltypes.ResultField, error) {
+	return r.s.runQuery(ctx, logGroupName, startTime, endTime, query)
+}
+
+// QueryRecords returns the queries run so far, oldest first.
+func (s *Scanner) QueryRecords() []QueryRecord {
+	s.queryMu.Lock()
+	defer s.queryMu.Unlock()
+	return append([]QueryRecord(nil), s.queries...)
+}
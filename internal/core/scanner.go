@@ -1,54 +1,220 @@
 package core
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	cloudwatchtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53resolver"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/audit"
 	"github.com/doitintl/terminator/internal/aws"
+	"github.com/doitintl/terminator/internal/cassette"
+	"github.com/doitintl/terminator/internal/units"
 	"github.com/doitintl/terminator/pkg/types"
 )
 
+// ErrReadOnly is returned by every mutating Scanner method when the scanner
+// was created WithReadOnly.
+var ErrReadOnly = errors.New("refusing to modify AWS resources in read-only mode")
+
 // Scanner orchestrates the NAT Gateway analysis
 type Scanner struct {
-	region    string
-	accountID string
-	ec2Client *aws.EC2Client
-	cwlClient *aws.CloudWatchLogsClient
-	iamClient *iam.Client
-	cwClient  *cloudwatch.Client
+	region       string
+	accountID    string
+	readOnly     bool
+	resourceTags map[string]string
+	resourceTTL  time.Duration
+	operator     string
+	ec2Client    *aws.EC2Client
+	cwlClient    *aws.CloudWatchLogsClient
+	iamClient    *iam.Client
+	cwClient     *cloudwatch.Client
+	cfnClient    *aws.CloudFormationClient
+	s3Client     *aws.S3Client
+	schedClient  *aws.SchedulerClient
+	ssmClient    *aws.SSMClient
+	dnsClient    *aws.DNSClient
+	quotaClient  *aws.QuotasClient
+	costClient   *aws.CostExplorerClient
+	backend      func(logGroupName string) analysis.AnalysisBackend
+	session      sessionCredentials
+
+	queryMu sync.Mutex
+	queries []QueryRecord
 }
 
-// NewScanner creates a new scanner instance
-func NewScanner(ctx context.Context, region, profile string) (*Scanner, error) {
-	// Build config options with fast IMDS timeout
-	configOpts := []func(*config.LoadOptions) error{
-		config.WithRegion(region),
-		config.WithEC2IMDSClientEnableState(imds.ClientDisabled), // Disable IMDS for fast failure on non-EC2
+// Option customizes how NewScanner obtains credentials.
+type Option func(*scannerOptions)
+
+type scannerOptions struct {
+	assumeRoles      []string
+	mfaSerial        string
+	mfaTokenProvider func() (string, error)
+	readOnly         bool
+	resourceTags     map[string]string
+	resourceTTL      time.Duration
+	sessionTags      map[string]string
+	operator         string
+	auditLog         *audit.Log
+	trafficBackend   func(logGroupName string) analysis.AnalysisBackend
+	cassette         *cassette.Recorder
+	allowIMDS        bool
+	stsRegion        string
+	stsEndpoint      string
+}
+
+// WithAssumeRoleChain makes the scanner assume each role in order, every hop
+// using the credentials of the previous one. When mfaSerial is set, the first
+// hop is MFA-gated and tokenProvider is asked for the code.
+func WithAssumeRoleChain(roleARNs []string, mfaSerial string, tokenProvider func() (string, error)) Option {
+	return func(o *scannerOptions) {
+		o.assumeRoles = roleARNs
+		o.mfaSerial = mfaSerial
+		o.mfaTokenProvider = tokenProvider
 	}
+}
 
-	// Add profile if specified
-	if profile != "" {
-		configOpts = append(configOpts, config.WithSharedConfigProfile(profile))
+// WithReadOnly makes every mutating scanner method fail with ErrReadOnly, so
+// a scan can never create, modify, or delete AWS resources.
+func WithReadOnly() Option {
+	return func(o *scannerOptions) {
+		o.readOnly = true
+	}
+}
+
+// WithResourceTags adds tags to every resource the scanner creates, for
+// accounts whose SCPs require mandatory tags.
+func WithResourceTags(tags map[string]string) Option {
+	return func(o *scannerOptions) {
+		o.resourceTags = tags
 	}
+}
 
-	cfg, err := config.LoadDefaultConfig(ctx, configOpts...)
+// WithSessionTags tags every assumed-role session, transitively through the
+// chain, so CloudTrail attributes the calls. The roles' trust policies must
+// allow sts:TagSession.
+func WithSessionTags(tags map[string]string) Option {
+	return func(o *scannerOptions) {
+		o.sessionTags = tags
+	}
+}
+
+// WithOperator adds an Operator tag to the Flow Logs, stacks and analyses
+// the scanner creates.
+func WithOperator(operator string) Option {
+	return func(o *scannerOptions) {
+		o.operator = operator
+	}
+}
+
+// WithAuditLog records every mutating API call in log.
+func WithAuditLog(log *audit.Log) Option {
+	return func(o *scannerOptions) {
+		o.auditLog = log
+	}
+}
+
+// WithTrafficBackend classifies the traffic of a log group with the backend
+// newBackend returns for it, instead of querying it with Logs Insights.
+func WithTrafficBackend(newBackend func(logGroupName string) analysis.AnalysisBackend) Option {
+	return func(o *scannerOptions) {
+		o.trafficBackend = newBackend
+	}
+}
+
+// WithCassette sends every AWS API call through rec, recording the responses
+// or replaying recorded ones. Replays sign with dummy credentials, so they
+// need no AWS account.
+func WithCassette(rec *cassette.Recorder) Option {
+	return func(o *scannerOptions) {
+		o.cassette = rec
+	}
+}
+
+// WithIMDS lets credentials and region come from the EC2 Instance Metadata
+// Service, for instance profiles. It is off by default so that laptops fail
+// fast rather than wait for the metadata endpoint; on EC2 it is turned on
+// automatically.
+func WithIMDS() Option {
+	return func(o *scannerOptions) {
+		o.allowIMDS = true
+	}
+}
+
+// WithSTS sends STS calls, the credential check and every role assumption,
+// to region and, when set, endpoint (e.g. an STS interface VPC endpoint)
+// instead of the scan region's public STS endpoint.
+func WithSTS(region, endpoint string) Option {
+	return func(o *scannerOptions) {
+		o.stsRegion = region
+		o.stsEndpoint = endpoint
+	}
+}
+
+// stsClient is the STS client for cfg, honouring WithSTS and
+// AWS_STS_REGIONAL_ENDPOINTS=legacy, which asks for the global endpoint.
+func (o scannerOptions) stsClient(cfg awssdk.Config) *sts.Client {
+	return sts.NewFromConfig(cfg, func(so *sts.Options) {
+		if o.stsRegion != "" {
+			so.Region = o.stsRegion
+		}
+		switch {
+		case o.stsEndpoint != "":
+			so.BaseEndpoint = awssdk.String(o.stsEndpoint)
+		case o.stsRegion == "" && strings.EqualFold(os.Getenv("AWS_STS_REGIONAL_ENDPOINTS"), "legacy"):
+			so.Region = "us-east-1"
+			so.BaseEndpoint = awssdk.String("https://sts.amazonaws.com")
+		}
+	})
+}
+
+// NewScanner creates a new scanner instance
+func NewScanner(ctx context.Context, region, profile string, opts ...Option) (*Scanner, error) {
+	var o scannerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cfg, session, err := loadAWSConfig(ctx, region, profile, o)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, err
 	}
 
 	// Validate credentials by calling STS - this fails fast if not authenticated
-	stsClient := sts.NewFromConfig(cfg)
+	stsClient := o.stsClient(cfg)
 	identity, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
@@ -60,20 +226,165 @@ func NewScanner(ctx context.Context, region, profile string) (*Scanner, error) {
 	}
 
 	return &Scanner{
-		region:    region,
-		accountID: accountID,
-		ec2Client: aws.NewEC2Client(ec2.NewFromConfig(cfg)),
-		cwlClient: aws.NewCloudWatchLogsClient(cloudwatchlogs.NewFromConfig(cfg)),
-		iamClient: iam.NewFromConfig(cfg),
-		cwClient:  cloudwatch.NewFromConfig(cfg),
+		region:       region,
+		accountID:    accountID,
+		readOnly:     o.readOnly,
+		resourceTags: o.resourceTags,
+		resourceTTL:  o.resourceTTL,
+		operator:     o.operator,
+		backend:      o.trafficBackend,
+		session:      session,
+		ec2Client:    aws.NewEC2Client(ec2.NewFromConfig(cfg)),
+		cwlClient:    aws.NewCloudWatchLogsClient(cloudwatchlogs.NewFromConfig(cfg)),
+		iamClient:    iam.NewFromConfig(cfg),
+		cwClient:     cloudwatch.NewFromConfig(cfg),
+		cfnClient:    aws.NewCloudFormationClient(cloudformation.NewFromConfig(cfg)),
+		s3Client:     aws.NewS3Client(s3.NewFromConfig(cfg)),
+		schedClient:  aws.NewSchedulerClient(scheduler.NewFromConfig(cfg)),
+		ssmClient:    aws.NewSSMClient(ssm.NewFromConfig(cfg)),
+		dnsClient:    aws.NewDNSClient(route53.NewFromConfig(cfg), route53resolver.NewFromConfig(cfg)),
+		quotaClient:  aws.NewQuotasClient(servicequotas.NewFromConfig(cfg)),
+		// Cost Explorer is only served from us-east-1.
+		costClient: aws.NewCostExplorerClient(costexplorer.NewFromConfig(cfg, func(o *costexplorer.Options) { o.Region = "us-east-1" })),
 	}, nil
 }
 
+// GetParameter reads one SSM Parameter Store parameter, decrypting
+// SecureStrings, with the same credentials a scanner would use. It backs
+// --config ssm:///name, which is read before any scanner exists.
+func GetParameter(ctx context.Context, region, profile, name string, opts ...Option) (string, error) {
+	var o scannerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cfg, _, err := loadAWSConfig(ctx, region, profile, o)
+	if err != nil {
+		return "", err
+	}
+	return aws.NewSSMClient(ssm.NewFromConfig(cfg)).GetParameter(ctx, name)
+}
+
+// loadAWSConfig loads the profile and assumes the role chain, if any. It
+// also returns the credentials whose expiry bounds a scan.
+func loadAWSConfig(ctx context.Context, region, profile string, o scannerOptions) (awssdk.Config, sessionCredentials, error) {
+	// Disable IMDS for fast failure on non-EC2. Web identity (IRSA) and ECS
+	// task role credentials do not need it.
+	imdsState := imds.ClientDisabled
+	if o.allowIMDS || onEC2() {
+		imdsState = imds.ClientEnabled
+	}
+	configOpts := []func(*config.LoadOptions) error{
+		config.WithRegion(region),
+		config.WithEC2IMDSClientEnableState(imdsState),
+	}
+
+	// Add profile if specified
+	if profile != "" {
+		configOpts = append(configOpts, config.WithSharedConfigProfile(profile))
+	}
+
+	if o.cassette != nil {
+		configOpts = append(configOpts, config.WithHTTPClient(o.cassette.Client()))
+		if o.cassette.Mode() == cassette.ModeReplay {
+			configOpts = append(configOpts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("REPLAY", "REPLAY", "")))
+		}
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, configOpts...)
+	if err != nil {
+		return awssdk.Config{}, sessionCredentials{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	session := sessionCredentials{provider: cfg.Credentials, ssoCacheKey: ssoCacheKey(cfg)}
+
+	if o.auditLog != nil {
+		cfg.APIOptions = append(cfg.APIOptions, auditMiddleware(o.auditLog))
+	}
+
+	for i, roleARN := range o.assumeRoles {
+		provider := stscreds.NewAssumeRoleProvider(o.stsClient(cfg), roleARN, func(ao *stscreds.AssumeRoleOptions) {
+			ao.RoleSessionName = "termiNATor"
+			if i == 0 && o.mfaSerial != "" {
+				ao.SerialNumber = awssdk.String(o.mfaSerial)
+				ao.TokenProvider = o.mfaTokenProvider
+			}
+			// Transitive, so the tags follow the chain into every hop.
+			for _, k := range sortedTagKeys(o.sessionTags) {
+				ao.Tags = append(ao.Tags, ststypes.Tag{Key: awssdk.String(k), Value: awssdk.String(o.sessionTags[k])})
+				ao.TransitiveTagKeys = append(ao.TransitiveTagKeys, k)
+			}
+		})
+		cfg.Credentials = awssdk.NewCredentialsCache(provider)
+		if i == 0 && o.mfaSerial != "" {
+			session = sessionCredentials{provider: cfg.Credentials, mfa: true}
+		}
+	}
+
+	return cfg, session, nil
+}
+
+func sortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// auditMiddleware records every mutating call made with the config in log,
+// with its parameters, request ID and outcome.
+func auditMiddleware(log *audit.Log) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("TerminatAuditLog", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			operation := awsmiddleware.GetOperationName(ctx)
+			if !audit.Mutating(operation) {
+				return out, metadata, err
+			}
+			e := audit.Event{
+				Service:   awsmiddleware.GetServiceID(ctx),
+				Operation: operation,
+				Region:    awsmiddleware.GetRegion(ctx),
+			}
+			if params, merr := json.Marshal(in.Parameters); merr == nil {
+				e.Params = params
+			}
+			if id, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+				e.RequestID = id
+			}
+			if err != nil {
+				e.Error = err.Error()
+			}
+			// A call the audit log cannot record still ran; don't fail it.
+			_ = log.Record(e)
+			return out, metadata, err
+		}), middleware.After)
+	}
+}
+
 // GetAccountID returns the AWS account ID
 func (s *Scanner) GetAccountID() string {
 	return s.accountID
 }
 
+// FriendlyNames looks up the account alias and the Name tags of vpcIDs for
+// reports. Either lookup may be denied; the names are then left out and
+// reports show bare IDs.
+func (s *Scanner) FriendlyNames(ctx context.Context, vpcIDs []string) types.FriendlyNames {
+	var names types.FriendlyNames
+	if out, err := s.iamClient.ListAccountAliases(ctx, &iam.ListAccountAliasesInput{}); err == nil && len(out.AccountAliases) > 0 {
+		names.AccountAlias = out.AccountAliases[0]
+	}
+	names.VPCNames, _ = s.ec2Client.VPCNames(ctx, vpcIDs)
+	return names
+}
+
+// ReadOnly reports whether mutating calls are blocked
+func (s *Scanner) ReadOnly() bool {
+	return s.readOnly
+}
+
 // GetRegion returns the AWS region
 func (s *Scanner) GetRegion() string {
 	return s.region
@@ -161,6 +472,180 @@ func (s *Scanner) DiscoverRouteTables(ctx context.Context, vpcID string) ([]type
 	return s.ec2Client.DiscoverRouteTables(ctx, vpcID)
 }
 
+// DiscoverSubnets finds subnets for a VPC
+func (s *Scanner) DiscoverSubnets(ctx context.Context, vpcID string) ([]types.Subnet, error) {
+	return s.ec2Client.DiscoverSubnets(ctx, vpcID)
+}
+
+// DiscoverVPCDNS reads the DNS attributes, Resolver rules and private hosted
+// zones of a VPC. Resolver rules and hosted zones are best effort, so a role
+// without route53resolver or route53 permissions still gets the other checks.
+func (s *Scanner) DiscoverVPCDNS(ctx context.Context, vpcID string) (*types.VPCDNS, error) {
+	support, hostnames, err := s.ec2Client.VPCDNSAttributes(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+	dns := &types.VPCDNS{VPCID: vpcID, DNSSupport: support, DNSHostnames: hostnames}
+	dns.ResolverRules, _ = s.dnsClient.ResolverRules(ctx, vpcID)
+	dns.PrivateZones, _ = s.dnsClient.PrivateZones(ctx, vpcID, s.region)
+	return dns, nil
+}
+
+// WorkloadENI returns an in-use workload network interface in a subnet, ""
+// if there is none
+func (s *Scanner) WorkloadENI(ctx context.Context, subnetID string) (string, error) {
+	return s.ec2Client.WorkloadENI(ctx, subnetID)
+}
+
+// ServicePrefixListAddress returns the ID of a service's AWS-managed prefix
+// list ("s3", "dynamodb") in the scanner's region and an address inside it
+func (s *Scanner) ServicePrefixListAddress(ctx context.Context, service string) (string, string, error) {
+	return s.ec2Client.ServicePrefixListAddress(ctx, fmt.Sprintf("com.amazonaws.%s.%s", s.region, service))
+}
+
+// AnalyzeReachability runs a VPC Reachability Analyzer analysis from a
+// network interface to destIP on a TCP port. Analyses are billed per run.
+func (s *Scanner) AnalyzeReachability(ctx context.Context, sourceENI, destIP string, port int32, keep bool) (*types.ReachabilityResult, error) {
+	if s.readOnly {
+		return nil, ErrReadOnly
+	}
+	return s.ec2Client.AnalyzeReachability(ctx, sourceENI, destIP, port, keep, s.createTags())
+}
+
+// SubnetEgress finds the route table a subnet uses (its explicit
+// association, else the VPC's main route table), the NAT Gateway its default
+// route points at, and the gateway endpoints on that route table.
+func (s *Scanner) SubnetEgress(ctx context.Context, subnetID string) (*types.SubnetEgress, error) {
+	subnet, err := s.ec2Client.DescribeSubnet(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	routeTables, err := s.DiscoverRouteTables(ctx, subnet.VPCID)
+	if err != nil {
+		return nil, err
+	}
+	var rt *types.RouteTable
+	for i := range routeTables {
+		for _, id := range routeTables[i].Subnets {
+			if id == subnetID {
+				rt = &routeTables[i]
+			}
+		}
+		if rt == nil && routeTables[i].Main {
+			rt = &routeTables[i]
+		}
+	}
+	if rt == nil {
+		return nil, fmt.Errorf("no route table found for subnet %s", subnetID)
+	}
+
+	egress := &types.SubnetEgress{SubnetID: subnetID, VPCID: subnet.VPCID, RouteTableID: rt.ID}
+	for _, route := range rt.Routes {
+		if route.TargetType == "nat-gateway" && route.DestinationCIDR == "0.0.0.0/0" {
+			egress.NATGatewayID = route.Target
+		}
+	}
+	if egress.NATGatewayID == "" {
+		return nil, fmt.Errorf("subnet %s does not route to a NAT Gateway (route table %s has no 0.0.0.0/0 route to one)", subnetID, rt.ID)
+	}
+
+	endpoints, err := s.DiscoverVPCEndpoints(ctx, subnet.VPCID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ep := range endpoints {
+		if ep.Type != "Gateway" {
+			continue
+		}
+		for _, id := range ep.RouteTables {
+			if id != rt.ID {
+				continue
+			}
+			switch {
+			case strings.HasSuffix(ep.ServiceName, ".s3"):
+				egress.BypassedServices = append(egress.BypassedServices, "s3")
+			case strings.HasSuffix(ep.ServiceName, ".dynamodb"):
+				egress.BypassedServices = append(egress.BypassedServices, "dynamodb")
+			}
+		}
+	}
+	return egress, nil
+}
+
+// ENIEgress is SubnetEgress for one network interface. An interface
+// endpoint's network interface needs no NAT route: scanning it measures the
+// traffic the endpoint carries instead.
+func (s *Scanner) ENIEgress(ctx context.Context, eniID string) (*types.SubnetEgress, error) {
+	subnetID, endpointID, err := s.ec2Client.DescribeNetworkInterface(ctx, eniID)
+	if err != nil {
+		return nil, err
+	}
+	if endpointID != "" {
+		subnet, err := s.ec2Client.DescribeSubnet(ctx, subnetID)
+		if err != nil {
+			return nil, err
+		}
+		return &types.SubnetEgress{SubnetID: subnetID, VPCID: subnet.VPCID, ENIID: eniID, EndpointID: endpointID}, nil
+	}
+	egress, err := s.SubnetEgress(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	egress.ENIID = eniID
+	return egress, nil
+}
+
+// Inventory snapshots the subnets, route tables and VPC endpoints of each
+// VPC, in the order given.
+func (s *Scanner) Inventory(ctx context.Context, vpcIDs []string) ([]types.VPCInventory, error) {
+	inventory := make([]types.VPCInventory, 0, len(vpcIDs))
+	for _, vpcID := range vpcIDs {
+		subnets, err := s.DiscoverSubnets(ctx, vpcID)
+		if err != nil {
+			return nil, err
+		}
+		routeTables, err := s.DiscoverRouteTables(ctx, vpcID)
+		if err != nil {
+			return nil, err
+		}
+		endpoints, err := s.DiscoverVPCEndpoints(ctx, vpcID)
+		if err != nil {
+			return nil, err
+		}
+		inventory = append(inventory, types.VPCInventory{VPCID: vpcID, Subnets: subnets, RouteTables: routeTables, VPCEndpoints: endpoints})
+	}
+	return inventory, nil
+}
+
+// DiscoverEKSClusters finds EKS clusters whose subnets are in a VPC
+func (s *Scanner) DiscoverEKSClusters(ctx context.Context, vpcID string) ([]string, error) {
+	return s.ec2Client.DiscoverEKSClusters(ctx, vpcID)
+}
+
+// CountSSMManagedInstances counts running instances in a VPC whose SSM Agent is online
+func (s *Scanner) CountSSMManagedInstances(ctx context.Context, vpcID string) (int, error) {
+	managed, err := s.ssmClient.ManagedInstanceIDs(ctx)
+	if err != nil || len(managed) == 0 {
+		return 0, err
+	}
+	inVPC, err := s.ec2Client.InstanceIDsInVPC(ctx, vpcID)
+	if err != nil {
+		return 0, err
+	}
+
+	isManaged := make(map[string]bool, len(managed))
+	for _, id := range managed {
+		isManaged[id] = true
+	}
+	count := 0
+	for _, id := range inVPC {
+		if isManaged[id] {
+			count++
+		}
+	}
+	return count, nil
+}
+
 // AnalyzeVPCEndpoints analyzes VPC endpoint configuration for a VPC
 func (s *Scanner) AnalyzeVPCEndpoints(ctx context.Context, vpcID string) (*analysis.EndpointAnalysis, error) {
 	endpoints, err := s.DiscoverVPCEndpoints(ctx, vpcID)
@@ -176,23 +661,215 @@ func (s *Scanner) AnalyzeVPCEndpoints(ctx context.Context, vpcID string) (*analy
 	return analysis.AnalyzeEndpoints(s.region, vpcID, endpoints, routeTables), nil
 }
 
-// CreateFlowLogs creates Flow Logs for a NAT Gateway
-func (s *Scanner) CreateFlowLogs(ctx context.Context, nat types.NATGateway, logGroupName string, deliveryRoleArn string, runID string) (string, error) {
-	return s.ec2Client.CreateFlowLogs(ctx, nat, logGroupName, deliveryRoleArn, runID)
+// CheckEndpointQuotas counts the region's gateway endpoints and the VPC's
+// interface endpoints against their quotas, so remediation commands warn
+// before running into them. Quotas that cannot be read are assumed to be
+// the AWS defaults.
+func (s *Scanner) CheckEndpointQuotas(ctx context.Context, endpoints *analysis.EndpointAnalysis) error {
+	checks := []struct {
+		endpointType, vpcID, quotaCode string
+		defaultLimit                   int
+	}{
+		{"Gateway", "", analysis.GatewayEndpointQuotaCode, analysis.DefaultGatewayEndpointQuota},
+		{"Interface", endpoints.VPCID, analysis.InterfaceEndpointQuotaCode, analysis.DefaultInterfaceEndpointQuota},
+	}
+	var quotas []analysis.EndpointQuota
+	for _, c := range checks {
+		used, err := s.ec2Client.CountVPCEndpoints(ctx, c.vpcID, c.endpointType)
+		if err != nil {
+			return err
+		}
+		q := analysis.EndpointQuota{Type: c.endpointType, QuotaCode: c.quotaCode, Used: used, Limit: c.defaultLimit, Default: true}
+		if limit, err := s.quotaClient.Quota(ctx, "vpc", c.quotaCode); err == nil {
+			q.Limit, q.Default = int(limit), false
+		}
+		quotas = append(quotas, q)
+	}
+	endpoints.Quotas = quotas
+	return nil
+}
+
+// NATProcessingCost returns the account's daily NAT Gateway data processing
+// cost in the scanner's region from Cost Explorer, for the days from start up
+// to end. Each call is billed $0.01 per page.
+func (s *Scanner) NATProcessingCost(ctx context.Context, start, end time.Time) (map[string]float64, error) {
+	return s.costClient.NATProcessingCost(ctx, s.accountID, s.region, start, end)
+}
+
+// CostAnomalyMonitorName names the anomaly monitor CreateCostAnomalyMonitor
+// creates.
+const CostAnomalyMonitorName = "termiNATor services"
+
+// CostAnomalyMonitor returns the ARN of the account's Cost Anomaly Detection
+// monitor for AWS services, or "" when there is none.
+func (s *Scanner) CostAnomalyMonitor(ctx context.Context) (string, error) {
+	return s.costClient.ServiceAnomalyMonitor(ctx)
+}
+
+// CreateCostAnomalyMonitor creates a Cost Anomaly Detection monitor for AWS
+// services, which covers NAT Gateway charges, and returns its ARN.
+func (s *Scanner) CreateCostAnomalyMonitor(ctx context.Context) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	return s.costClient.CreateServiceAnomalyMonitor(ctx, CostAnomalyMonitorName, s.createTags())
+}
+
+// NATCostAnomalies returns the monitor's anomalies since start that NAT
+// Gateway usage in the scanner's region contributed to, newest first.
+func (s *Scanner) NATCostAnomalies(ctx context.Context, monitorARN string, start time.Time) ([]types.CostAnomaly, error) {
+	return s.costClient.NATAnomalies(ctx, monitorARN, s.region, start)
+}
+
+// PlaceEndpoints decides where the analyzed VPC's new interface endpoints go,
+// one subnet in each of activeAZs, and which security group they get, so
+// recommended commands can be run as is
+func (s *Scanner) PlaceEndpoints(ctx context.Context, endpoints *analysis.EndpointAnalysis, activeAZs []string) error {
+	var inv analysis.PlacementInventory
+	var err error
+	if inv.Subnets, err = s.DiscoverSubnets(ctx, endpoints.VPCID); err != nil {
+		return err
+	}
+	if inv.SecurityGroups, err = s.ec2Client.DiscoverSecurityGroups(ctx, endpoints.VPCID); err != nil {
+		return err
+	}
+	if inv.VPCCIDRs, err = s.ec2Client.VPCCIDRs(ctx, endpoints.VPCID); err != nil {
+		return err
+	}
+	if inv.WorkloadSecurityGroups, err = s.ec2Client.WorkloadSecurityGroups(ctx, endpoints.NATSubnetIDs()); err != nil {
+		return err
+	}
+	endpoints.Placement = analysis.PlanEndpointPlacement(endpoints, inv, activeAZs)
+	return nil
+}
+
+// CreateFlowLogs creates Flow Logs for a NAT Gateway. destination is a log
+// group name or a Firehose delivery stream ARN.
+func (s *Scanner) CreateFlowLogs(ctx context.Context, nat types.NATGateway, destination string, deliveryRoleArn string, runID string) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	return s.ec2Client.CreateFlowLogs(ctx, nat, destination, deliveryRoleArn, runID, s.createTags())
 }
 
 // DeleteFlowLogs deletes Flow Logs
 func (s *Scanner) DeleteFlowLogs(ctx context.Context, flowLogIDs []string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
 	return s.ec2Client.DeleteFlowLogs(ctx, flowLogIDs)
 }
 
+// CreateSubnetFlowLogs creates Flow Logs for a workload subnet
+func (s *Scanner) CreateSubnetFlowLogs(ctx context.Context, subnetID string, destination string, deliveryRoleArn string, runID string) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	return s.ec2Client.CreateSubnetFlowLogs(ctx, subnetID, destination, deliveryRoleArn, runID, s.createTags())
+}
+
+// CreateENIFlowLogs creates Flow Logs for one network interface
+func (s *Scanner) CreateENIFlowLogs(ctx context.Context, eniID string, destination string, deliveryRoleArn string, runID string) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	return s.ec2Client.CreateENIFlowLogs(ctx, eniID, destination, deliveryRoleArn, runID, s.createTags())
+}
+
+// ActiveFlowLogs returns which of the given Flow Logs are ACTIVE, for Flow
+// Logs without a log group to look them up by.
+func (s *Scanner) ActiveFlowLogs(ctx context.Context, flowLogIDs []string) ([]string, error) {
+	if len(flowLogIDs) == 0 {
+		return nil, nil
+	}
+	flowLogs, err := s.ec2Client.DescribeFlowLogs(ctx, flowLogIDs)
+	if err != nil {
+		return nil, err
+	}
+	var active []string
+	for _, fl := range flowLogs {
+		if fl.Status == "ACTIVE" {
+			active = append(active, fl.ID)
+		}
+	}
+	return active, nil
+}
+
+// CreateFlowLogsStack creates the log group and one Flow Log per NAT Gateway
+// through a short-lived CloudFormation stack named after runID
+func (s *Scanner) CreateFlowLogsStack(ctx context.Context, nats []types.NATGateway, logGroupName, deliveryRoleArn, runID string) ([]string, error) {
+	if s.readOnly {
+		return nil, ErrReadOnly
+	}
+	flowLogIDs, err := s.cfnClient.CreateFlowLogsStack(ctx, aws.FlowLogsStack{
+		StackName:       runID,
+		LogGroupName:    logGroupName,
+		DeliveryRoleArn: deliveryRoleArn,
+		RunID:           runID,
+		NATs:            nats,
+		Tags:            s.createTags(),
+	})
+	if err != nil {
+		// The failed stack is deleted, but the log group it retains is not.
+		// Detached from ctx so an interrupt does not orphan it either.
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+		defer cancel()
+		if delErr := s.cwlClient.DeleteLogGroup(cleanupCtx, logGroupName); delErr != nil {
+			return nil, fmt.Errorf("%w (log group %s was left behind: %v)", err, logGroupName, delErr)
+		}
+		return nil, err
+	}
+	return flowLogIDs, nil
+}
+
+// DeleteFlowLogsStack stops stack-managed Flow Logs by deleting their stack
+func (s *Scanner) DeleteFlowLogsStack(ctx context.Context, runID string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	return s.cfnClient.DeleteFlowLogsStack(ctx, runID)
+}
+
 // CreateLogGroup creates a CloudWatch Logs log group
 func (s *Scanner) CreateLogGroup(ctx context.Context, logGroupName string) error {
-	return s.cwlClient.CreateLogGroup(ctx, logGroupName)
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	// Tagging a log group needs logs:TagResource, so only tag when a naming
+	// policy or an expiry asks for it.
+	var tags map[string]string
+	if len(s.resourceTags) > 0 || s.resourceTTL > 0 {
+		tags = s.createTags()
+		tags["CreatedBy"] = "termiNATor"
+	}
+	return s.cwlClient.CreateLogGroup(ctx, logGroupName, tags)
+}
+
+// createTags returns the tags for created resources beyond the CreatedBy,
+// RunId and Timestamp tags every resource gets: the naming policy's, who
+// ran the scan, and when the resource expires.
+func (s *Scanner) createTags() map[string]string {
+	if len(s.resourceTags) == 0 && s.operator == "" && s.resourceTTL <= 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(s.resourceTags)+3)
+	for k, v := range s.resourceTags {
+		tags[k] = v
+	}
+	if s.operator != "" {
+		tags["Operator"] = s.operator
+	}
+	if s.resourceTTL > 0 {
+		tags[ExpiresAtTag] = expiryValue(time.Now().Add(s.resourceTTL))
+	}
+	return tags
 }
 
 // DeleteLogGroup deletes a CloudWatch Logs log group
 func (s *Scanner) DeleteLogGroup(ctx context.Context, logGroupName string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
 	return s.cwlClient.DeleteLogGroup(ctx, logGroupName)
 }
 
@@ -219,54 +896,282 @@ func (s *Scanner) AnalyzeTraffic(ctx context.Context, logGroupName string, start
 		queryEndTime = now
 	}
 
-	// Use aggregated query to avoid OOM on large datasets
-	query := `fields @message
-| parse @message "* * * * * * * * * * * * * *" as f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12, f13, f14
-| filter f13 = "ACCEPT"
-| fields coalesce(f5, f3) as resolved_dst, f10 as flow_bytes
-| stats sum(flow_bytes) as total_bytes by resolved_dst
-| sort total_bytes desc`
+	stats, err := s.queryTraffic(ctx, logGroupName, startTime, queryEndTime, "", nil)
+	if errors.Is(err, analysis.ErrNoRecords) {
+		return nil, errNoFlowLogsData
+	}
+	return stats, err
+}
 
-	queryID, err := s.cwlClient.StartQuery(ctx, logGroupName, startTime, queryEndTime, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to start query: %w", err)
+var errNoFlowLogsData = errors.New("no Flow Logs data found - query returned 0 results. This could mean: (1) No traffic during collection period, (2) Flow Logs not delivering data yet, or (3) All traffic was to private IPs (filtered out)")
+
+// maxConcurrentQueries bounds the per-NAT Logs Insights queries in flight,
+// well below the account's concurrent query quota.
+const maxConcurrentQueries = 4
+
+// AnalyzeSubnetTraffic classifies the traffic in Flow Logs of a workload
+// subnet or network interface created with CreateSubnetFlowLogs or
+// CreateENIFlowLogs, counting only what goes through the subnet's NAT
+// Gateway. For an interface endpoint's network interface every accepted
+// flow counts.
+func (s *Scanner) AnalyzeSubnetTraffic(ctx context.Context, logGroupName string, subnet *types.SubnetEgress, startTime, endTime int64) (*analysis.TrafficStats, error) {
+	if err := s.waitForFlowLogsData(ctx, logGroupName, startTime, 5*time.Minute); err != nil {
+		return nil, err
+	}
+	queryEndTime := endTime
+	if now := time.Now().Unix(); now > queryEndTime {
+		queryEndTime = now
+	}
+	stats, err := s.queryTraffic(ctx, logGroupName, startTime, queryEndTime, "", subnet)
+	if errors.Is(err, analysis.ErrNoRecords) {
+		return nil, errNoFlowLogsData
+	}
+	return stats, err
+}
+
+// NATTraffic is the traffic attributed to one NAT Gateway by its own query.
+// Err is set when that NAT's query failed; its traffic is then missing from
+// the merged totals.
+type NATTraffic struct {
+	NATID string
+	Stats *analysis.TrafficStats
+	Err   error
+}
+
+// AnalyzeTrafficPerNAT runs one bounded-concurrency query per NAT Gateway ENI
+// and merges the results, so a failing query only loses that NAT's traffic.
+// It falls back to a single query when there is only one NAT or when a NAT
+// has no ENI to filter on (regional NAT Gateways); perNAT is nil then.
+func (s *Scanner) AnalyzeTrafficPerNAT(ctx context.Context, logGroupName string, nats []types.NATGateway, startTime, endTime int64) (*analysis.TrafficStats, []NATTraffic, error) {
+	perENI := len(nats) > 1
+	for _, nat := range nats {
+		if nat.NetworkInterfaceID == "" {
+			perENI = false
+		}
+	}
+	if !perENI {
+		stats, err := s.AnalyzeTraffic(ctx, logGroupName, startTime, endTime)
+		return stats, nil, err
+	}
+
+	if err := s.waitForFlowLogsData(ctx, logGroupName, startTime, 5*time.Minute); err != nil {
+		return nil, nil, err
 	}
+	queryEndTime := endTime
+	if now := time.Now().Unix(); now > queryEndTime {
+		queryEndTime = now
+	}
+
+	perNAT := make([]NATTraffic, len(nats))
+	sem := make(chan struct{}, maxConcurrentQueries)
+	var wg sync.WaitGroup
+	for i, nat := range nats {
+		wg.Add(1)
+		go func(i int, nat types.NATGateway) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			stats, err := s.queryTraffic(ctx, logGroupName, startTime, queryEndTime, nat.NetworkInterfaceID, nil)
+			if errors.Is(err, analysis.ErrNoRecords) {
+				stats, err = &analysis.TrafficStats{SourceIPs: map[string]*analysis.SourceIPStats{}}, nil
+			}
+			perNAT[i] = NATTraffic{NATID: nat.ID, Stats: stats, Err: err}
+		}(i, nat)
+	}
+	wg.Wait()
+
+	total := &analysis.TrafficStats{SourceIPs: map[string]*analysis.SourceIPStats{}}
+	var failed []error
+	for _, t := range perNAT {
+		if t.Err != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", t.NATID, t.Err))
+			continue
+		}
+		total.Merge(t.Stats)
+	}
+	if len(failed) == len(perNAT) {
+		return nil, perNAT, fmt.Errorf("all per-NAT queries failed: %w", errors.Join(failed...))
+	}
+	if total.TotalRecords == 0 {
+		return nil, perNAT, errNoFlowLogsData
+	}
+	return total, perNAT, nil
+}
 
-	// Wait for query to complete and get results
-	results, err := s.cwlClient.WaitForQueryResults(ctx, queryID)
+// AnalyzeLogGroupWindow classifies the NAT Gateway traffic already stored in
+// a Flow Logs log group between startTime and endTime, without waiting for new
+// data. The group may hold the Flow Logs of every interface of the VPC, so
+// only the records of the region's NAT Gateway interfaces count; instance
+// records would count the same flows twice. A window with no records yields
+// empty stats.
+func (s *Scanner) AnalyzeLogGroupWindow(ctx context.Context, logGroupName string, startTime, endTime int64, nats []types.NATGateway) (*analysis.TrafficStats, error) {
+	q := analysis.TrafficQuery{Start: startTime, End: endTime, Region: s.region, AccountID: s.accountID}
+	for _, nat := range nats {
+		if nat.NetworkInterfaceID != "" {
+			q.ENIs = append(q.ENIs, nat.NetworkInterfaceID)
+		}
+	}
+	stats, err := s.trafficBackend(logGroupName).Traffic(ctx, q)
+	if errors.Is(err, analysis.ErrNoRecords) {
+		return &analysis.TrafficStats{SourceIPs: map[string]*analysis.SourceIPStats{}}, nil
+	}
+	return stats, err
+}
+
+// AttributeS3Traffic queries a CloudTrail log group for S3 data events sent
+// from the NAT Gateways' public IPs and sums the bytes per bucket and principal.
+func (s *Scanner) AttributeS3Traffic(ctx context.Context, trailLogGroup string, nats []types.NATGateway, startTime, endTime int64) ([]analysis.S3Attribution, error) {
+	var ips []string
+	for _, nat := range nats {
+		for _, ip := range nat.PublicIPs {
+			ips = append(ips, strconv.Quote(ip))
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no public NAT Gateway IPs to match CloudTrail events against")
+	}
+
+	query := `fields requestParameters.bucketName as bucket, userIdentity.arn as principal,
+  additionalEventData.bytesTransferredIn as in_bytes, additionalEventData.bytesTransferredOut as out_bytes
+| filter eventSource = "s3.amazonaws.com" and sourceIPAddress in [` + strings.Join(ips, ", ") + `]
+| stats sum(in_bytes) as bytes_in, sum(out_bytes) as bytes_out, count(*) as requests by bucket, principal
+| sort bytes_out desc
+| limit 100`
+
+	results, err := s.runQuery(ctx, trailLogGroup, startTime, endTime, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get query results: %w", err)
+		return nil, fmt.Errorf("failed to query CloudTrail log group %s: %w", trailLogGroup, err)
+	}
+	attributions := analysis.ParseS3Attribution(results)
+	for i := range attributions {
+		attributions[i].Principal = analysis.PrincipalName(attributions[i].Principal)
 	}
+	return attributions, nil
+}
 
-	// Diagnostic: check if query returned any results
-	if len(results) == 0 {
-		return nil, fmt.Errorf("no Flow Logs data found - query returned 0 results. This could mean: (1) No traffic during collection period, (2) Flow Logs not delivering data yet, or (3) All traffic was to private IPs (filtered out)")
+// LookupDynamoDBEndpoints queries a Route 53 Resolver query log group for
+// DynamoDB hostnames, marking those outside the scanner's region.
+func (s *Scanner) LookupDynamoDBEndpoints(ctx context.Context, resolverLogGroup string, startTime, endTime int64) ([]analysis.DynamoDBEndpoint, error) {
+	query := `fields query_name, srcaddr
+| filter query_name like /(^|\.)(dynamodb|dynamodb-fips|ddb)\./
+| stats count(*) as queries, count_distinct(srcaddr) as clients by query_name
+| sort queries desc
+| limit 100`
+
+	results, err := s.runQuery(ctx, resolverLogGroup, startTime, endTime, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Resolver log group %s: %w", resolverLogGroup, err)
 	}
+	return analysis.ParseDynamoDBEndpoints(results, s.region), nil
+}
 
-	// Process aggregated results
-	analyzer, err := analysis.NewTrafficAnalyzer()
+// SampleCoverage counts a Flow Logs log group's records by log status and
+// minute between startTime and endTime, to tell how complete the sample is.
+// created is set when termiNATor created the Flow Logs, so their aggregation
+// interval is known.
+func (s *Scanner) SampleCoverage(ctx context.Context, logGroupName string, startTime, endTime int64, created bool) (*analysis.SampleCoverage, error) {
+	query := `fields @message
+| parse @message "* * * * * * * * * * * * * *" as f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12, f13, f14
+| fields f14 as log_status
+| stats count(*) as records by log_status, bin(1m)`
+
+	results, err := s.runQuery(ctx, logGroupName, startTime, endTime, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create analyzer: %w", err)
+		return nil, fmt.Errorf("failed to query Flow Logs record status: %w", err)
+	}
+	interval := 0
+	if created {
+		interval = aws.FlowLogAggregationInterval
 	}
+	return analysis.ParseSampleCoverage(results, time.Unix(startTime, 0), time.Unix(endTime, 0), interval), nil
+}
+
+// CheckClassification classifies the NAT traffic of a Flow Logs log group by
+// IP range and by the AWS service Flow Logs recorded for each destination,
+// and reports where they disagree. It returns nil for log groups whose
+// records lack pkt-dst-aws-service.
+func (s *Scanner) CheckClassification(ctx context.Context, logGroupName string, startTime, endTime int64) (*analysis.ClassificationCheck, error) {
+	query := `fields @message
+| parse @message "* * * * * * * * * * * * * * *" as f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12, f13, f14, f15
+| filter f13 = "ACCEPT"
+| fields coalesce(f5, f3) as resolved_dst, f15 as aws_service, f10 as flow_bytes
+| stats sum(flow_bytes) as total_bytes, count(*) as flow_count by resolved_dst, aws_service
+| sort total_bytes desc
+| limit 10000`
 
-	stats, err := analyzer.AnalyzeAggregatedResults(results)
+	results, err := s.runQuery(ctx, logGroupName, startTime, endTime, query)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to query Flow Logs services: %w", err)
 	}
-	if stats.TotalRecords > 0 {
-		return stats, nil
+	analyzer, err := analysis.NewTrafficAnalyzer()
+	if err != nil {
+		return nil, err
 	}
+	return analyzer.CheckClassification(results), nil
+}
+
+// AnalyzeRejectedTraffic sums the REJECT records of a Flow Logs log group by
+// source, destination and port. It returns nil when nothing was rejected.
+func (s *Scanner) AnalyzeRejectedTraffic(ctx context.Context, logGroupName string, startTime, endTime int64) (*analysis.RejectedTraffic, error) {
+	query := `fields @message
+| parse @message "* * * * * * * * * * * * * *" as f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12, f13, f14
+| filter f13 = "REJECT"
+| fields coalesce(f4, f2) as resolved_src, coalesce(f5, f3) as resolved_dst, f7 as dst_port, f8 as protocol, f10 as flow_bytes
+| stats sum(flow_bytes) as total_bytes, count(*) as flow_count by resolved_src, resolved_dst, dst_port, protocol
+| sort flow_count desc
+| limit 1000`
 
-	// Fallback path: when aggregated parsing yields zero, parse raw log lines directly.
-	rawStats, err := s.analyzeTrafficFromRawMessages(ctx, logGroupName, startTime, queryEndTime, analyzer)
+	results, err := s.runQuery(ctx, logGroupName, startTime, endTime, query)
 	if err != nil {
-		return nil, fmt.Errorf("aggregated analysis returned zero records and fallback raw analysis failed: %w", err)
+		return nil, fmt.Errorf("failed to query rejected flows: %w", err)
 	}
-	if rawStats.TotalRecords > 0 {
-		return rawStats, nil
+	return analysis.ParseRejectedTraffic(results), nil
+}
+
+// NATTrafficBySource sums the NAT Gateway traffic in a Flow Logs log group
+// by interface, source address and the AWS service AWS reports for the
+// destination, to attribute it to the zones of the sending subnets.
+func (s *Scanner) NATTrafficBySource(ctx context.Context, logGroupName string, startTime, endTime int64) ([]analysis.SourceTraffic, error) {
+	query := `fields @message
+| parse @message "* * * * * * * * * * * * * *" as f1, f2, f3, f4, f5, f6, f7, f8, f9, f10, f11, f12, f13, f14
+| filter f13 = "ACCEPT"
+| fields f1 as interface_id, coalesce(f4, f2) as resolved_src, f14 as aws_service, f10 as flow_bytes
+| stats sum(flow_bytes) as total_bytes by interface_id, resolved_src, aws_service
+| sort total_bytes desc
+| limit 10000`
+
+	results, err := s.runQuery(ctx, logGroupName, startTime, endTime, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query traffic by source: %w", err)
 	}
+	return analysis.ParseSourceTraffic(results), nil
+}
 
-	return stats, nil
+// queryTraffic classifies the Flow Logs in a log group with the Logs
+// Insights backend. A non-empty eni limits the query to records of that
+// network interface. A non-nil subnet marks the log group as holding Flow
+// Logs of that workload subnet or network interface instead of NAT Gateways
+// (see analysis.TrafficAnalyzer.ScopeToSubnet).
+func (s *Scanner) queryTraffic(ctx context.Context, logGroupName string, startTime, queryEndTime int64, eni string, subnet *types.SubnetEgress) (*analysis.TrafficStats, error) {
+	return s.trafficBackend(logGroupName).Traffic(ctx, analysis.TrafficQuery{
+		Start:     startTime,
+		End:       queryEndTime,
+		ENI:       eni,
+		Subnet:    subnet,
+		Region:    s.region,
+		AccountID: s.accountID,
+	})
+}
+
+// trafficBackend returns the backend classifying a log group's traffic: the
+// one WithTrafficBackend set, or Logs Insights, recording its queries like
+// the scanner's own.
+func (s *Scanner) trafficBackend(logGroupName string) analysis.AnalysisBackend {
+	if s.backend != nil {
+		return s.backend(logGroupName)
+	}
+	return analysis.NewLogsInsightsBackend(queryRecorder{s}, logGroupName)
 }
 
 func (s *Scanner) waitForFlowLogsData(ctx context.Context, logGroupName string, startTime int64, timeout time.Duration) error {
@@ -289,41 +1194,56 @@ func (s *Scanner) waitForFlowLogsData(ctx context.Context, logGroupName string,
 
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("scan cancelled while waiting for Flow Logs data: %w", ctx.Err())
+			return fmt.Errorf("scan cancelled while waiting for Flow Logs data: %w", context.Cause(ctx))
 		case <-time.After(pollInterval):
 		}
 	}
 }
 
-func (s *Scanner) analyzeTrafficFromRawMessages(ctx context.Context, logGroupName string, startTime, endTime int64, analyzer *analysis.TrafficAnalyzer) (*analysis.TrafficStats, error) {
-	rawQuery := `fields @message
-| filter @message not like /NODATA|SKIPDATA/
-| limit 20000`
-
-	queryID, err := s.cwlClient.StartQuery(ctx, logGroupName, startTime, endTime, rawQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to start raw flow logs query: %w", err)
-	}
+// CleanupSchedulerRoleName is the role EventBridge Scheduler assumes to delete
+// kept log groups. It must trust scheduler.amazonaws.com and allow
+// logs:DeleteLogGroup on termiNATor log groups.
+const CleanupSchedulerRoleName = "termiNATor-CleanupSchedulerRole"
 
-	results, err := s.cwlClient.WaitForQueryResults(ctx, queryID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get raw flow logs results: %w", err)
+// ScheduleLogGroupDeletion arranges for a kept log group to be deleted after
+// the given delay by a one-time EventBridge Scheduler schedule, and returns
+// when that will happen.
+func (s *Scanner) ScheduleLogGroupDeletion(ctx context.Context, logGroupName string, after time.Duration) (time.Time, error) {
+	if s.readOnly {
+		return time.Time{}, ErrReadOnly
 	}
+	at := time.Now().Add(after).Truncate(time.Minute)
+	roleARN := fmt.Sprintf("arn:aws:iam::%s:role/%s", s.accountID, CleanupSchedulerRoleName)
+	return at, s.schedClient.ScheduleLogGroupDeletion(ctx, deletionScheduleName(logGroupName), logGroupName, at, roleARN)
+}
 
-	logLines := make([]string, 0, len(results))
-	for _, row := range results {
-		for _, field := range row {
-			if field.Field == nil || field.Value == nil {
-				continue
-			}
-			if *field.Field == "@message" && *field.Value != "" {
-				logLines = append(logLines, *field.Value)
-				break
-			}
+// deletionScheduleName derives a valid schedule name (letters, digits, - _ .,
+// at most 64 characters) from the log group's last path element. A name cut
+// to fit ends in a hash of the whole log group name, so groups differing only
+// past the cut get schedules of their own.
+func deletionScheduleName(logGroupName string) string {
+	base := logGroupName[strings.LastIndex(logGroupName, "/")+1:]
+	name := []rune{}
+	for _, r := range base {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			name = append(name, r)
+		default:
+			name = append(name, '-')
 		}
 	}
+	const suffix = "-expiry"
+	if len(name) > 64-len(suffix) {
+		sum := sha256.Sum256([]byte(logGroupName))
+		hash := "-" + hex.EncodeToString(sum[:4])
+		name = append(name[:64-len(suffix)-len(hash)], []rune(hash)...)
+	}
+	return string(name) + suffix
+}
 
-	return analyzer.AnalyzeFlowLogs(logLines)
+// QueryBytesScanned returns the Logs Insights bytes scanned by this scanner's queries.
+func (s *Scanner) QueryBytesScanned() float64 {
+	return s.cwlClient.BytesScanned()
 }
 
 // CalculateCosts calculates cost estimates based on traffic analysis
@@ -335,8 +1255,156 @@ func (s *Scanner) CalculateCosts(stats *analysis.TrafficStats, collectionMinutes
 // by querying recent NAT Gateway throughput from CloudWatch metrics.
 // Returns estimated GB of flow log data and cost in USD, or (0, 0, err) on failure.
 func (s *Scanner) EstimateFlowLogsCost(ctx context.Context, natIDs []string, durationMinutes int) (estimatedGB float64, estimatedCost float64, err error) {
+	totalBytes, err := s.GetNATProcessedBytes(ctx, natIDs, time.Hour)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Extrapolate: bytes in last hour → bytes during scan duration
+	// Flow Logs generate ~40-50 bytes per record, roughly 1:1 ratio with actual traffic bytes
+	// but we use a conservative 0.5x multiplier since flow log records are smaller than payload
+	bytesPerHour := totalBytes
+	scanHours := float64(durationMinutes+5) / 60.0 // include 5-min startup
+	estimatedFlowLogBytes := bytesPerHour * scanHours * 0.5
+	estimatedGB = units.BillingGB(estimatedFlowLogBytes)
+	estimatedCost = estimatedGB * 0.50 // $0.50/GB ingestion
+
+	return estimatedGB, estimatedCost, nil
+}
+
+// AnalyzeS3FlowLogsDay classifies the Flow Logs objects delivered to
+// s3://bucket/prefix for one UTC day. prefix is the vpcflowlogs/<region>/
+// folder; objects are read from prefix + YYYY/MM/DD/. Only the records of
+// the region's current NAT Gateways to addresses outside the VPC count.
+func (s *Scanner) AnalyzeS3FlowLogsDay(ctx context.Context, bucket, prefix string, day time.Time) (*analysis.TrafficStats, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	keys, err := s.s3Client.ListObjectKeys(ctx, bucket, prefix+day.UTC().Format("2006/01/02/"))
+	if err != nil {
+		return nil, err
+	}
+
+	analyzer, err := analysis.NewTrafficAnalyzer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analyzer: %w", err)
+	}
+	analyzer.RecognizeRegionalServices(s.region, s.accountID)
+
+	// The bucket may hold the Flow Logs of every interface of the VPC
+	nats, err := s.ec2Client.DiscoverNATGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+	natENIs := make(map[string]bool, len(nats))
+	for _, nat := range nats {
+		if nat.NetworkInterfaceID != "" {
+			natENIs[nat.NetworkInterfaceID] = true
+		}
+	}
+	read := func(r io.Reader) (*analysis.TrafficStats, error) {
+		return analyzer.AnalyzeNATFlowLogReader(r, natENIs)
+	}
+
+	total := &analysis.TrafficStats{SourceIPs: map[string]*analysis.SourceIPStats{}}
+	for _, key := range keys {
+		stats, err := s.analyzeS3Object(ctx, bucket, key, read)
+		if err != nil {
+			return nil, err
+		}
+		total.Merge(stats)
+	}
+	return total, nil
+}
+
+// FirehoseObjectKeys lists the objects a Kinesis Data Firehose stream
+// delivered to s3://bucket/prefix between from and to, assuming the stream's
+// default YYYY/MM/DD/HH/ (UTC) layout under prefix.
+func (s *Scanner) FirehoseObjectKeys(ctx context.Context, bucket, prefix string, from, to time.Time) ([]string, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	var keys []string
+	for hour := from.UTC().Truncate(time.Hour); !hour.After(to); hour = hour.Add(time.Hour) {
+		hourKeys, err := s.s3Client.ListObjectKeys(ctx, bucket, prefix+hour.Format("2006/01/02/15/"))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, hourKeys...)
+	}
+	return keys, nil
+}
+
+// AnalyzeFirehoseFlowLogs classifies the Flow Logs records a scan delivered
+// through a Kinesis Data Firehose stream, read back from the objects the
+// stream wrote to S3. Only records of interfaces (any when empty) that
+// started between startTime and endTime count; subnet is as for
+// AnalyzeSubnetTraffic.
+func (s *Scanner) AnalyzeFirehoseFlowLogs(ctx context.Context, bucket string, keys, interfaces []string, subnet *types.SubnetEgress, startTime, endTime int64) (*analysis.TrafficStats, error) {
+	analyzer, err := analysis.NewTrafficAnalyzer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analyzer: %w", err)
+	}
+	analyzer.RecognizeRegionalServices(s.region, s.accountID)
+	if subnet != nil && subnet.EndpointID == "" {
+		analyzer.ScopeToSubnet(subnet.BypassedServices...)
+	}
+
+	wanted := make(map[string]bool, len(interfaces))
+	for _, id := range interfaces {
+		wanted[id] = true
+	}
+	read := func(r io.Reader) (*analysis.TrafficStats, error) {
+		return analyzer.AnalyzeFirehoseReader(r, wanted, startTime, endTime)
+	}
+
+	total := &analysis.TrafficStats{SourceIPs: map[string]*analysis.SourceIPStats{}}
+	for _, key := range keys {
+		stats, err := s.analyzeS3Object(ctx, bucket, key, read)
+		if err != nil {
+			return nil, err
+		}
+		total.Merge(stats)
+	}
+	if total.TotalRecords == 0 {
+		return nil, errNoFlowLogsData
+	}
+	return total, nil
+}
+
+func (s *Scanner) analyzeS3Object(ctx context.Context, bucket, key string, read func(io.Reader) (*analysis.TrafficStats, error)) (*analysis.TrafficStats, error) {
+	body, err := s.s3Client.GetObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var r io.Reader = body
+	if strings.HasSuffix(key, ".gz") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress s3://%s/%s: %w", bucket, key, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	stats, err := read(r)
+	if err != nil {
+		return nil, fmt.Errorf("s3://%s/%s: %w", bucket, key, err)
+	}
+	return stats, nil
+}
+
+// GetNATProcessedBytes sums BytesOutToDestination and BytesInFromDestination
+// for the given NAT Gateways over the trailing window, from CloudWatch metrics.
+func (s *Scanner) GetNATProcessedBytes(ctx context.Context, natIDs []string, window time.Duration) (float64, error) {
 	now := time.Now()
-	startTime := now.Add(-1 * time.Hour)
+	startTime := now.Add(-window)
+	period := int32(window.Seconds())
+	if period > 86400 {
+		period = 86400
+	}
 
 	var totalBytes float64
 	for _, natID := range natIDs {
@@ -349,11 +1417,11 @@ func (s *Scanner) EstimateFlowLogsCost(ctx context.Context, natIDs []string, dur
 				},
 				StartTime:  &startTime,
 				EndTime:    &now,
-				Period:     int32Ptr(3600),
+				Period:     int32Ptr(period),
 				Statistics: []cloudwatchtypes.Statistic{cloudwatchtypes.StatisticSum},
 			})
 			if err != nil {
-				return 0, 0, fmt.Errorf("failed to get NAT metrics: %w", err)
+				return 0, fmt.Errorf("failed to get NAT metrics: %w", err)
 			}
 			for _, dp := range result.Datapoints {
 				if dp.Sum != nil {
@@ -362,17 +1430,7 @@ func (s *Scanner) EstimateFlowLogsCost(ctx context.Context, natIDs []string, dur
 			}
 		}
 	}
-
-	// Extrapolate: bytes in last hour → bytes during scan duration
-	// Flow Logs generate ~40-50 bytes per record, roughly 1:1 ratio with actual traffic bytes
-	// but we use a conservative 0.5x multiplier since flow log records are smaller than payload
-	bytesPerHour := totalBytes
-	scanHours := float64(durationMinutes+5) / 60.0 // include 5-min startup
-	estimatedFlowLogBytes := bytesPerHour * scanHours * 0.5
-	estimatedGB = estimatedFlowLogBytes / (1024 * 1024 * 1024)
-	estimatedCost = estimatedGB * 0.50 // $0.50/GB ingestion
-
-	return estimatedGB, estimatedCost, nil
+	return totalBytes, nil
 }
 
 func strPtr(s string) *string { return &s }
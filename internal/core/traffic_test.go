@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/aws"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// fakeLogs answers the Flow Logs presence check with one record. Other
+// calls panic through the nil embedded interface.
+type fakeLogs struct {
+	aws.CloudWatchLogsAPI
+}
+
+func (fakeLogs) FilterLogEvents(ctx context.Context, params *cloudwatchlogs.FilterLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	return &cloudwatchlogs.FilterLogEventsOutput{Events: []cwltypes.FilteredLogEvent{{}}}, nil
+}
+
+// fakeBackend returns canned results per network interface, "" being the
+// whole log group.
+type fakeBackend struct {
+	mu      sync.Mutex
+	results map[string]fakeResult
+}
+
+type fakeResult struct {
+	stats *analysis.TrafficStats
+	err   error
+}
+
+func (b *fakeBackend) Name() string { return "fake" }
+
+func (b *fakeBackend) Traffic(ctx context.Context, q analysis.TrafficQuery) (*analysis.TrafficStats, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r, ok := b.results[q.ENI]
+	if !ok {
+		return nil, analysis.ErrNoRecords
+	}
+	return r.stats, r.err
+}
+
+func s3Traffic(bytes int64) *analysis.TrafficStats {
+	stats := &analysis.TrafficStats{TotalBytes: bytes, TotalRecords: 1, SourceIPs: map[string]*analysis.SourceIPStats{}}
+	stats.AddService(analysis.ServiceS3, bytes, 1)
+	return stats
+}
+
+func TestAnalyzeTrafficPerNAT(t *testing.T) {
+	nats := []types.NATGateway{
+		{ID: "nat-a", NetworkInterfaceID: "eni-a"},
+		{ID: "nat-b", NetworkInterfaceID: "eni-b"},
+	}
+	tests := []struct {
+		name      string
+		nats      []types.NATGateway
+		results   map[string]fakeResult
+		wantBytes int64
+		wantErr   string
+		// wantNATErr lists the NATs whose entry carries an error
+		wantNATErr []string
+	}{
+		{
+			name:      "merges every NAT",
+			nats:      nats,
+			results:   map[string]fakeResult{"eni-a": {stats: s3Traffic(100)}, "eni-b": {stats: s3Traffic(50)}},
+			wantBytes: 150,
+		},
+		{
+			name:       "one NAT failing leaves the others",
+			nats:       nats,
+			results:    map[string]fakeResult{"eni-a": {stats: s3Traffic(100)}, "eni-b": {err: errors.New("query timed out")}},
+			wantBytes:  100,
+			wantNATErr: []string{"nat-b"},
+		},
+		{
+			name:      "a NAT without records counts as empty",
+			nats:      nats,
+			results:   map[string]fakeResult{"eni-a": {stats: s3Traffic(100)}},
+			wantBytes: 100,
+		},
+		{
+			name:       "all NATs failing",
+			nats:       nats,
+			results:    map[string]fakeResult{"eni-a": {err: errors.New("throttled")}, "eni-b": {err: errors.New("timed out")}},
+			wantErr:    "all per-NAT queries failed",
+			wantNATErr: []string{"nat-a", "nat-b"},
+		},
+		{
+			name:    "no records at all",
+			nats:    nats,
+			results: map[string]fakeResult{},
+			wantErr: "no Flow Logs data found",
+		},
+		{
+			name:      "a NAT without a network interface queries the whole group",
+			nats:      []types.NATGateway{{ID: "nat-a", NetworkInterfaceID: "eni-a"}, {ID: "nat-b"}},
+			results:   map[string]fakeResult{"": {stats: s3Traffic(150)}},
+			wantBytes: 150,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fakeBackend{results: tt.results}
+			s := &Scanner{
+				cwlClient: aws.NewCloudWatchLogsClient(fakeLogs{}),
+				backend:   func(string) analysis.AnalysisBackend { return backend },
+			}
+
+			total, perNAT, err := s.AnalyzeTrafficPerNAT(context.Background(), "/terminator/test", tt.nats, 0, 0)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				for _, id := range tt.wantNATErr {
+					if !strings.Contains(err.Error(), id) {
+						t.Errorf("error %q does not name %s", err, id)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total.TotalBytes != tt.wantBytes || total.Bytes(analysis.ServiceS3) != tt.wantBytes {
+				t.Errorf("total = %d bytes (S3 %d), want %d", total.TotalBytes, total.Bytes(analysis.ServiceS3), tt.wantBytes)
+			}
+
+			failed := map[string]bool{}
+			for _, id := range tt.wantNATErr {
+				failed[id] = true
+			}
+			for _, nt := range perNAT {
+				if (nt.Err != nil) != failed[nt.NATID] {
+					t.Errorf("%s: err = %v, want failure %v", nt.NATID, nt.Err, failed[nt.NATID])
+				}
+				if nt.Err == nil && nt.Stats == nil {
+					t.Errorf("%s: no stats", nt.NATID)
+				}
+			}
+			if len(perNAT) > 0 && len(perNAT) != len(tt.nats) {
+				t.Errorf("got %d per-NAT results, want %d", len(perNAT), len(tt.nats))
+			}
+		})
+	}
+}
+
+// queryBackend records the queries it is asked.
+type queryBackend struct {
+	queries []analysis.TrafficQuery
+}
+
+func (b *queryBackend) Name() string { return "recording" }
+
+func (b *queryBackend) Traffic(ctx context.Context, q analysis.TrafficQuery) (*analysis.TrafficStats, error) {
+	b.queries = append(b.queries, q)
+	return s3Traffic(100), nil
+}
+
+func TestAnalyzeLogGroupWindowFiltersNATInterfaces(t *testing.T) {
+	backend := &queryBackend{}
+	s := &Scanner{region: "us-east-1", backend: func(string) analysis.AnalysisBackend { return backend }}
+	nats := []types.NATGateway{
+		{ID: "nat-a", NetworkInterfaceID: "eni-a"},
+		{ID: "nat-b", NetworkInterfaceID: "eni-b"},
+		{ID: "nat-pending"},
+	}
+
+	if _, err := s.AnalyzeLogGroupWindow(context.Background(), "/vpc/flow-logs", 0, 86400, nats); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.queries) != 1 || strings.Join(backend.queries[0].ENIs, ",") != "eni-a,eni-b" {
+		t.Errorf("queries = %+v, want one limited to eni-a and eni-b", backend.queries)
+	}
+}
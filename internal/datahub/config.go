@@ -1,39 +1,42 @@
 package datahub
 
 import (
+	"fmt"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/doitintl/terminator/internal/config"
+	"github.com/doitintl/terminator/internal/secrets"
 )
 
 type Config struct {
 	APIKey          string
 	CustomerContext string
+	// Transport comes from api_url, timeout, max_retries and retry_backoff;
+	// the keys left out keep DefaultTransport's values.
+	Transport Transport
 }
 
-func configPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".terminat", "config.toml"), nil
-}
-
-// LoadConfig reads the [datahub] section from ~/.terminat/config.toml
+// LoadConfig reads the [datahub] section from ~/.terminat/config.toml, or the
+// document given with --config. Without an api_key there, the key comes from
+// the OS keyring.
 func LoadConfig() Config {
-	path, err := configPath()
-	if err != nil {
-		return Config{}
+	cfg := Config{Transport: DefaultTransport()}
+	if content, err := config.Read(); err == nil {
+		cfg = parseConfig(content)
 	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return Config{}
+	if cfg.APIKey == "" {
+		if key, err := secrets.Get(secrets.DataHubAPIKey); err == nil {
+			cfg.APIKey = key
+		}
 	}
-	return parseConfig(string(data))
+	return cfg
 }
 
 func parseConfig(content string) Config {
-	var cfg Config
+	cfg := Config{Transport: DefaultTransport()}
 	inSection := false
 	for _, line := range strings.Split(content, "\n") {
 		line = strings.TrimSpace(line)
@@ -59,25 +62,48 @@ func parseConfig(content string) Config {
 			cfg.APIKey = val
 		case "customer_context":
 			cfg.CustomerContext = val
+		case "api_url":
+			cfg.Transport.URL = val
+		case "timeout":
+			if d, err := time.ParseDuration(val); err == nil && d >= 0 {
+				cfg.Transport.Timeout = d
+			}
+		case "max_retries":
+			if n, err := strconv.Atoi(val); err == nil && n >= 0 {
+				cfg.Transport.MaxRetries = n
+			}
+		case "retry_backoff":
+			if d, err := time.ParseDuration(val); err == nil && d >= 0 {
+				cfg.Transport.RetryBackoff = d
+			}
 		}
 	}
 	return cfg
 }
 
 // SaveConfig writes the [datahub] section to ~/.terminat/config.toml, preserving other sections.
+// The API key goes to the OS keyring; it is written to the file in plain text
+// only when the keyring cannot be used and --insecure-config was given.
 func SaveConfig(cfg Config) error {
-	path, err := configPath()
+	path, err := config.DefaultPath()
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
 
+	var apiKeyLine string
+	if cfg.APIKey == "" {
+		_ = secrets.Delete(secrets.DataHubAPIKey)
+	} else if err := secrets.Set(secrets.DataHubAPIKey, cfg.APIKey); err != nil {
+		if !secrets.InsecureFallback() {
+			return fmt.Errorf("could not store the DataHub API key in the OS keyring: %w (rerun with --insecure-config to keep it in %s in plain text)", err, path)
+		}
+		apiKeyLine = "api_key = \"" + cfg.APIKey + "\"\n"
+	}
 	existing, _ := os.ReadFile(path)
 	content := string(existing)
 
-	section := "[datahub]\napi_key = \"" + cfg.APIKey + "\"\ncustomer_context = \"" + cfg.CustomerContext + "\"\n"
+	section := "[datahub]\n" + apiKeyLine + "customer_context = \"" + cfg.CustomerContext + "\"\n"
+	section += transportLines(cfg.Transport)
 
 	// Replace existing [datahub] section or append
 	if idx := strings.Index(content, "[datahub]"); idx >= 0 {
@@ -94,7 +120,7 @@ func SaveConfig(cfg Config) error {
 		content += section
 	}
 
-	return os.WriteFile(path, []byte(content), 0644)
+	return config.Write(content)
 }
 
 // ResolveAPIKey returns the API key from flag > env > config.
@@ -118,3 +144,26 @@ func ResolveCustomerContext(flagValue string) string {
 	}
 	return LoadConfig().CustomerContext
 }
+
+// transportLines are the [datahub] lines for the settings of t that differ
+// from DefaultTransport. A zero Transport writes none.
+func transportLines(t Transport) string {
+	def := DefaultTransport()
+	if t == (Transport{}) || t == def {
+		return ""
+	}
+	var lines string
+	if t.URL != def.URL {
+		lines += "api_url = \"" + t.URL + "\"\n"
+	}
+	if t.Timeout != def.Timeout {
+		lines += "timeout = \"" + t.Timeout.String() + "\"\n"
+	}
+	if t.MaxRetries != def.MaxRetries {
+		lines += "max_retries = " + strconv.Itoa(t.MaxRetries) + "\n"
+	}
+	if t.RetryBackoff != def.RetryBackoff {
+		lines += "retry_backoff = \"" + t.RetryBackoff.String() + "\"\n"
+	}
+	return lines
+}
@@ -1,11 +1,22 @@
 package datahub
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/doitintl/terminator/internal/secrets"
+	"github.com/zalando/go-keyring"
 )
 
+// TestMain keeps the tests off the real OS keyring.
+func TestMain(m *testing.M) {
+	keyring.MockInit()
+	os.Exit(m.Run())
+}
+
 func TestParseConfigEmpty(t *testing.T) {
 	cfg := parseConfig("")
 	if cfg.APIKey != "" || cfg.CustomerContext != "" {
@@ -53,6 +64,43 @@ api_key = plain-key
 	}
 }
 
+func TestParseConfigTransport(t *testing.T) {
+	if cfg := parseConfig(""); cfg.Transport != DefaultTransport() {
+		t.Fatalf("got %+v, want the default transport", cfg.Transport)
+	}
+
+	content := `[datahub]
+api_key = "k"
+api_url = "https://proxy.example.com/datahub/v1/events"
+timeout = "5s"
+max_retries = 0
+retry_backoff = "bogus"
+`
+	cfg := parseConfig(content)
+	want := DefaultTransport()
+	want.URL = "https://proxy.example.com/datahub/v1/events"
+	want.Timeout = 5 * time.Second
+	want.MaxRetries = 0
+	if cfg.Transport != want {
+		t.Fatalf("got %+v, want %+v", cfg.Transport, want)
+	}
+}
+
+func TestSaveConfigKeepsTransport(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+
+	cfg := Config{APIKey: "k", Transport: DefaultTransport()}
+	cfg.Transport.URL = "https://staging.example.com/events"
+	cfg.Transport.MaxRetries = 1
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if loaded := LoadConfig(); loaded.Transport != cfg.Transport {
+		t.Fatalf("round-trip failed: got %+v, want %+v", loaded.Transport, cfg.Transport)
+	}
+}
+
 func TestSaveAndLoadConfig(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("HOME", tmp)
@@ -93,9 +141,39 @@ func TestSaveConfigPreservesOtherSections(t *testing.T) {
 	if !contains(content, "[other]") || !contains(content, "foo") {
 		t.Fatalf("other section lost: %s", content)
 	}
-	if !contains(content, "api_key") {
+	if !contains(content, "[datahub]") {
 		t.Fatalf("datahub section missing: %s", content)
 	}
+	if contains(content, "api_key") {
+		t.Fatalf("API key written in plain text: %s", content)
+	}
+}
+
+func TestSaveConfigKeyringUnavailable(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	keyring.MockInitWithError(errors.New("no keyring"))
+	defer keyring.MockInit()
+
+	if err := SaveConfig(Config{APIKey: "k"}); err == nil || !contains(err.Error(), "--insecure-config") {
+		t.Fatalf("err = %v, want one pointing at --insecure-config", err)
+	}
+
+	secrets.SetInsecureFallback(true)
+	defer secrets.SetInsecureFallback(false)
+	if err := SaveConfig(Config{APIKey: "k"}); err != nil {
+		t.Fatalf("SaveConfig with --insecure-config: %v", err)
+	}
+	if loaded := LoadConfig(); loaded.APIKey != "k" {
+		t.Fatalf("got APIKey=%q from the file, want k", loaded.APIKey)
+	}
+	fi, err := os.Stat(filepath.Join(tmp, ".terminat", "config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("config.toml holding the plain-text key has mode %o, want 600", perm)
+	}
 }
 
 func TestResolveAPIKeyPrecedence(t *testing.T) {
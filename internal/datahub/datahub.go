@@ -5,13 +5,34 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/doitintl/terminator/internal/analysis"
 	"github.com/doitintl/terminator/pkg/types"
 )
 
-var apiURL = "https://api.doit.com/datahub/v1/events"
+// DefaultAPIURL is the DoiT DataHub events endpoint.
+const DefaultAPIURL = "https://api.doit.com/datahub/v1/events"
+
+// Transport is where and how events are posted. The zero value posts to
+// DefaultAPIURL without a timeout or retries.
+type Transport struct {
+	// URL is the events endpoint, e.g. a regional or staging one, or a
+	// proxy forwarding to it.
+	URL     string
+	Timeout time.Duration
+	// MaxRetries is how many times a rate-limited (429) batch is retried,
+	// waiting RetryBackoff longer before each attempt.
+	MaxRetries   int
+	RetryBackoff time.Duration
+}
+
+// DefaultTransport is the transport used unless the [datahub] section
+// overrides it.
+func DefaultTransport() Transport {
+	return Transport{URL: DefaultAPIURL, Timeout: 30 * time.Second, MaxRetries: 3, RetryBackoff: 10 * time.Second}
+}
 
 type Dimension struct {
 	Key   string `json:"key"`
@@ -36,10 +57,41 @@ type eventBatch struct {
 	Events []Event `json:"events"`
 }
 
-// BuildEvents creates DataHub events from scan results.
-// Produces 5 events per NAT: 1 aggregated + 4 per-service (S3, DynamoDB, ECR, Other).
-func BuildEvents(accountID, region string, nats []types.NATGateway, stats *analysis.TrafficStats, cost *analysis.CostEstimate, endpoints *analysis.EndpointAnalysis) []Event {
-	if stats == nil || cost == nil {
+// Granularity is what one set of DataHub events covers.
+type Granularity string
+
+const (
+	// GranularityNAT sends a set of events per NAT Gateway, with the traffic
+	// sampled on its own network interface.
+	GranularityNAT Granularity = "nat"
+	// GranularityAccount sends one set for all the NAT Gateways scanned.
+	GranularityAccount Granularity = "account"
+)
+
+// ParseGranularity validates a --datahub-granularity value.
+func ParseGranularity(s string) (Granularity, error) {
+	switch g := Granularity(strings.ToLower(strings.TrimSpace(s))); g {
+	case GranularityNAT, GranularityAccount:
+		return g, nil
+	}
+	return "", fmt.Errorf("unknown granularity %q (want nat or account)", s)
+}
+
+// NATUsage is one NAT Gateway's share of a scan: the traffic sampled on its
+// network interface and the monthly cost it projects to.
+type NATUsage struct {
+	Stats *analysis.TrafficStats
+	Cost  *analysis.CostEstimate
+}
+
+// BuildEvents creates DataHub events from scan results, 5 per set: 1
+// aggregated + 4 per-service (S3, DynamoDB, ECR, Other). Each NAT Gateway in
+// perNAT gets a set with its own traffic. Unless perNAT covers every NAT
+// Gateway (account granularity, traffic that could not be split per NAT, or
+// a NAT whose query failed) one set covers all of nats, so dashboards
+// neither count the totals more than once nor lose what was not attributed.
+func BuildEvents(accountID, region string, nats []types.NATGateway, stats *analysis.TrafficStats, cost *analysis.CostEstimate, endpoints *analysis.EndpointAnalysis, perNAT map[string]NATUsage) []Event {
+	if stats == nil || cost == nil || len(nats) == 0 {
 		return nil
 	}
 
@@ -56,7 +108,70 @@ func BuildEvents(accountID, region string, nats []types.NATGateway, stats *analy
 			dynamoStatus = "configured"
 		}
 	}
+	set := eventSet{now: now, date: date, s3Status: s3Status, dynamoStatus: dynamoStatus}
+
+	if !coversAll(nats, perNAT) {
+		key, resourceID, vpcID, name := nats[0].ID, nats[0].ID, nats[0].VPCID, nats[0].Name()
+		if len(nats) > 1 {
+			name = ""
+			ids := make([]string, 0, len(nats))
+			for _, nat := range nats {
+				ids = append(ids, nat.ID)
+				if nat.VPCID != vpcID {
+					vpcID = "multiple"
+				}
+			}
+			key, resourceID = accountID+"_"+region, strings.Join(ids, ", ")
+		}
+		return set.build(key, baseDimensions(accountID, region, resourceID, vpcID, name), stats, cost)
+	}
+
+	var events []Event
+	for _, nat := range nats {
+		usage := perNAT[nat.ID]
+		events = append(events, set.build(nat.ID, baseDimensions(accountID, region, nat.ID, nat.VPCID, nat.Name()), usage.Stats, usage.Cost)...)
+	}
+	return events
+}
+
+// coversAll reports whether perNAT has usage for every one of nats.
+func coversAll(nats []types.NATGateway, perNAT map[string]NATUsage) bool {
+	if len(perNAT) == 0 {
+		return false
+	}
+	for _, nat := range nats {
+		usage, ok := perNAT[nat.ID]
+		if !ok || usage.Stats == nil || usage.Cost == nil {
+			return false
+		}
+	}
+	return true
+}
 
+func baseDimensions(accountID, region, resourceID, vpcID, natName string) []Dimension {
+	dims := []Dimension{
+		{Key: "project_id", Value: accountID, Type: "fixed"},
+		{Key: "region", Value: region, Type: "fixed"},
+		{Key: "resource_id", Value: resourceID, Type: "fixed"},
+		{Key: "service_description", Value: "NAT Gateway Data Processing", Type: "fixed"},
+		{Key: "vpc_id", Value: vpcID, Type: "label"},
+		{Key: "scan_type", Value: "deep", Type: "label"},
+	}
+	if natName != "" {
+		dims = append(dims, Dimension{Key: "nat_name", Value: natName, Type: "label"})
+	}
+	return dims
+}
+
+// eventSet holds what every set of one scan's events shares.
+type eventSet struct {
+	now, date              string
+	s3Status, dynamoStatus string
+}
+
+// build returns the aggregated and per-service events for the traffic in
+// stats, priced as cost. key prefixes the event IDs.
+func (e eventSet) build(key string, baseDims []Dimension, stats *analysis.TrafficStats, cost *analysis.CostEstimate) []Event {
 	type svcData struct {
 		name     string
 		sku      string
@@ -67,9 +182,9 @@ func BuildEvents(accountID, region string, nats []types.NATGateway, stats *analy
 	}
 
 	services := []svcData{
-		{"S3", "S3 traffic via NAT", cost.S3SavingsMonthly, cost.S3SavingsMonthly, cost.S3DataGB, s3Status},
-		{"DynamoDB", "DynamoDB traffic via NAT", cost.DynamoSavingsMonthly, cost.DynamoSavingsMonthly, cost.DynamoDataGB, dynamoStatus},
-		{"ECR", "ECR traffic via NAT", cost.OtherDataGB * cost.NATGatewayPricePerGB * (stats.ECRPercentage() / cost.OtherPercentage()), 0, cost.OtherDataGB * (stats.ECRPercentage() / cost.OtherPercentage()), "n-a"},
+		{"S3", "S3 traffic via NAT", cost.S3SavingsMonthly, cost.S3SavingsMonthly, cost.S3DataGB, e.s3Status},
+		{"DynamoDB", "DynamoDB traffic via NAT", cost.DynamoSavingsMonthly, cost.DynamoSavingsMonthly, cost.DynamoDataGB, e.dynamoStatus},
+		{"ECR", "ECR traffic via NAT", cost.OtherDataGB * cost.NATGatewayPricePerGB * (stats.Percentage(analysis.ServiceECR) / cost.OtherPercentage()), 0, cost.OtherDataGB * (stats.Percentage(analysis.ServiceECR) / cost.OtherPercentage()), "n-a"},
 		{"Other", "Other traffic via NAT", cost.OtherDataGB * cost.NATGatewayPricePerGB, 0, cost.OtherDataGB, "n-a"},
 	}
 
@@ -79,81 +194,118 @@ func BuildEvents(accountID, region string, nats []types.NATGateway, stats *analy
 		services[2].usageGB = 0
 	}
 
-	var events []Event
-	for _, nat := range nats {
-		baseDims := []Dimension{
-			{Key: "project_id", Value: accountID, Type: "fixed"},
-			{Key: "region", Value: region, Type: "fixed"},
-			{Key: "resource_id", Value: nat.ID, Type: "fixed"},
-			{Key: "service_description", Value: "NAT Gateway Data Processing", Type: "fixed"},
-			{Key: "vpc_id", Value: nat.VPCID, Type: "label"},
-			{Key: "scan_type", Value: "deep", Type: "label"},
-		}
+	// Aggregated event
+	aggDims := append(append([]Dimension{}, baseDims...),
+		Dimension{Key: "sku_description", Value: "NAT Gateway - Avoidable Cost", Type: "fixed"},
+		Dimension{Key: "traffic_service", Value: "Total", Type: "label"},
+		Dimension{Key: "endpoint_status", Value: "n-a", Type: "label"},
+	)
+	events := []Event{{
+		Provider:   "termiNATor",
+		ID:         fmt.Sprintf("%s_total_%s", key, e.date),
+		Time:       e.now,
+		Dimensions: aggDims,
+		Metrics: []Metric{
+			{Type: "cost", Value: cost.CurrentMonthlyCost},
+			{Type: "savings", Value: cost.TotalSavingsMonthly},
+			{Type: "usage", Value: cost.TotalDataGB},
+		},
+	}}
 
-		// Aggregated event
-		aggDims := append(append([]Dimension{}, baseDims...),
-			Dimension{Key: "sku_description", Value: "NAT Gateway - Avoidable Cost", Type: "fixed"},
-			Dimension{Key: "traffic_service", Value: "Total", Type: "label"},
-			Dimension{Key: "endpoint_status", Value: "n-a", Type: "label"},
+	// Per-service events
+	for _, svc := range services {
+		dims := append(append([]Dimension{}, baseDims...),
+			Dimension{Key: "sku_description", Value: svc.sku, Type: "fixed"},
+			Dimension{Key: "traffic_service", Value: svc.name, Type: "label"},
+			Dimension{Key: "endpoint_status", Value: svc.epStatus, Type: "label"},
 		)
 		events = append(events, Event{
 			Provider:   "termiNATor",
-			ID:         fmt.Sprintf("%s_total_%s", nat.ID, date),
-			Time:       now,
-			Dimensions: aggDims,
+			ID:         fmt.Sprintf("%s_%s_%s", key, svc.name, e.date),
+			Time:       e.now,
+			Dimensions: dims,
 			Metrics: []Metric{
-				{Type: "cost", Value: cost.CurrentMonthlyCost},
-				{Type: "savings", Value: cost.TotalSavingsMonthly},
-				{Type: "usage", Value: cost.TotalDataGB},
+				{Type: "cost", Value: svc.costVal},
+				{Type: "savings", Value: svc.savings},
+				{Type: "usage", Value: svc.usageGB},
 			},
 		})
+	}
+	return events
+}
 
-		// Per-service events
-		for _, svc := range services {
-			dims := append(append([]Dimension{}, baseDims...),
-				Dimension{Key: "sku_description", Value: svc.sku, Type: "fixed"},
-				Dimension{Key: "traffic_service", Value: svc.name, Type: "label"},
-				Dimension{Key: "endpoint_status", Value: svc.epStatus, Type: "label"},
-			)
-			events = append(events, Event{
-				Provider:   "termiNATor",
-				ID:         fmt.Sprintf("%s_%s_%s", nat.ID, svc.name, date),
-				Time:       now,
-				Dimensions: dims,
-				Metrics: []Metric{
-					{Type: "cost", Value: svc.costVal},
-					{Type: "savings", Value: svc.savings},
-					{Type: "usage", Value: svc.usageGB},
-				},
-			})
-		}
+// BuildScanCostEvents creates DataHub events for what the scan itself cost:
+// one for the Flow Logs ingested into CloudWatch Logs and one for the data
+// Logs Insights scanned. Set against the savings events, they show the
+// return on running terminat.
+func BuildScanCostEvents(accountID, region, runID string, scanCost *analysis.ScanCost) []Event {
+	if scanCost == nil {
+		return nil
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	charges := []struct {
+		name    string
+		sku     string
+		costVal float64
+		usageGB float64
+	}{
+		{"ingestion", "CloudWatch Logs - Flow Logs Ingestion", scanCost.IngestionUSD, scanCost.IngestedGB},
+		{"insights", "CloudWatch Logs Insights - Data Scanned", scanCost.QueryUSD, scanCost.ScannedGB},
+	}
+
+	var events []Event
+	for _, c := range charges {
+		events = append(events, Event{
+			Provider: "termiNATor",
+			ID:       fmt.Sprintf("%s_scan_%s", runID, c.name),
+			Time:     now,
+			Dimensions: []Dimension{
+				{Key: "project_id", Value: accountID, Type: "fixed"},
+				{Key: "region", Value: region, Type: "fixed"},
+				{Key: "resource_id", Value: runID, Type: "fixed"},
+				{Key: "service_description", Value: "termiNATor Scan", Type: "fixed"},
+				{Key: "sku_description", Value: c.sku, Type: "fixed"},
+				{Key: "scan_type", Value: "deep", Type: "label"},
+			},
+			Metrics: []Metric{
+				{Type: "cost", Value: c.costVal},
+				{Type: "usage", Value: c.usageGB},
+			},
+		})
 	}
 	return events
 }
 
-// Send posts events to the DoiT DataHub API with retry on 429.
-func Send(apiKey, customerContext string, events []Event) error {
+// Send posts events to the DoiT DataHub API, retrying rate-limited batches
+// as the transport allows.
+func (t Transport) Send(apiKey, customerContext string, events []Event) error {
 	// Batch in groups of 255 (API limit)
 	for i := 0; i < len(events); i += 255 {
 		end := i + 255
 		if end > len(events) {
 			end = len(events)
 		}
-		if err := sendBatch(apiKey, customerContext, events[i:end]); err != nil {
+		if err := t.sendBatch(apiKey, customerContext, events[i:end]); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func sendBatch(apiKey, customerContext string, events []Event) error {
+func (t Transport) sendBatch(apiKey, customerContext string, events []Event) error {
 	body, err := json.Marshal(eventBatch{Events: events})
 	if err != nil {
 		return fmt.Errorf("failed to marshal events: %w", err)
 	}
 
-	for attempt := 0; attempt < 4; attempt++ {
-		req, err := http.NewRequest("POST", apiURL, bytes.NewReader(body))
+	url := t.URL
+	if url == "" {
+		url = DefaultAPIURL
+	}
+	client := &http.Client{Timeout: t.Timeout}
+	for attempt := 0; attempt <= t.MaxRetries; attempt++ {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body))
 		if err != nil {
 			return err
 		}
@@ -163,7 +315,7 @@ func sendBatch(apiKey, customerContext string, events []Event) error {
 			req.Header.Set("x-customer-context", customerContext)
 		}
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := client.Do(req)
 		if err != nil {
 			return fmt.Errorf("DataHub API request failed: %w", err)
 		}
@@ -172,8 +324,8 @@ func sendBatch(apiKey, customerContext string, events []Event) error {
 		if resp.StatusCode == 200 || resp.StatusCode == 201 {
 			return nil
 		}
-		if resp.StatusCode == 429 && attempt < 3 {
-			time.Sleep(time.Duration(10*(attempt+1)) * time.Second)
+		if resp.StatusCode == 429 && attempt < t.MaxRetries {
+			time.Sleep(time.Duration(attempt+1) * t.RetryBackoff)
 			continue
 		}
 		return fmt.Errorf("DataHub API returned %d", resp.StatusCode)
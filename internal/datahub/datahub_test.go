@@ -2,10 +2,12 @@ package datahub
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/doitintl/terminator/internal/analysis"
 	"github.com/doitintl/terminator/pkg/types"
@@ -14,11 +16,13 @@ import (
 func testData() ([]types.NATGateway, *analysis.TrafficStats, *analysis.CostEstimate, *analysis.EndpointAnalysis) {
 	nats := []types.NATGateway{{ID: "nat-123", VPCID: "vpc-abc"}}
 	stats := &analysis.TrafficStats{
-		S3Bytes:     1073741824, // 1 GB
-		DynamoBytes: 536870912,  // 0.5 GB
-		ECRBytes:    107374182,  // ~0.1 GB
-		OtherBytes:  214748365,  // ~0.2 GB
-		TotalBytes:  1932734783,
+		Services: map[analysis.Service]analysis.ServiceStats{
+			analysis.ServiceS3:       {Bytes: 1073741824}, // 1 GB
+			analysis.ServiceDynamoDB: {Bytes: 536870912},  // 0.5 GB
+			analysis.ServiceECR:      {Bytes: 107374182},  // ~0.1 GB
+			analysis.ServiceOther:    {Bytes: 214748365},  // ~0.2 GB
+		},
+		TotalBytes: 1932734783,
 	}
 	cost := &analysis.CostEstimate{
 		TotalDataGB:          1.8,
@@ -40,14 +44,14 @@ func testData() ([]types.NATGateway, *analysis.TrafficStats, *analysis.CostEstim
 
 func TestBuildEventsNil(t *testing.T) {
 	nats := []types.NATGateway{{ID: "nat-1"}}
-	if events := BuildEvents("acct", "us-east-1", nats, nil, nil, nil); events != nil {
+	if events := BuildEvents("acct", "us-east-1", nats, nil, nil, nil, nil); events != nil {
 		t.Fatal("expected nil for nil stats/cost")
 	}
 }
 
 func TestBuildEventsSingleNAT(t *testing.T) {
 	nats, stats, cost, endpoints := testData()
-	events := BuildEvents("123456789012", "us-east-1", nats, stats, cost, endpoints)
+	events := BuildEvents("123456789012", "us-east-1", nats, stats, cost, endpoints, nil)
 
 	if len(events) != 5 {
 		t.Fatalf("got %d events, want 5", len(events))
@@ -95,9 +99,25 @@ func TestBuildEventsSingleNAT(t *testing.T) {
 	}
 }
 
+func TestBuildEventsNATName(t *testing.T) {
+	nats, stats, cost, endpoints := testData()
+	nats[0].Tags = map[string]string{"Name": "egress-a"}
+	for _, e := range BuildEvents("123456789012", "us-east-1", nats, stats, cost, endpoints, nil) {
+		found := false
+		for _, d := range e.Dimensions {
+			if d.Key == "nat_name" && d.Value == "egress-a" && d.Type == "label" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("event %s lacks the nat_name label: %+v", e.ID, e.Dimensions)
+		}
+	}
+}
+
 func TestBuildEventsEndpointStatus(t *testing.T) {
 	nats, stats, cost, endpoints := testData()
-	events := BuildEvents("acct", "us-east-1", nats, stats, cost, endpoints)
+	events := BuildEvents("acct", "us-east-1", nats, stats, cost, endpoints, nil)
 
 	statusFor := func(svc string) string {
 		for _, e := range events {
@@ -130,19 +150,79 @@ func TestBuildEventsEndpointStatus(t *testing.T) {
 
 func TestBuildEventsMultipleNATs(t *testing.T) {
 	nats := []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1"}, {ID: "nat-2", VPCID: "vpc-2"}}
-	stats := &analysis.TrafficStats{TotalBytes: 1000, S3Bytes: 500, OtherBytes: 500}
-	cost := &analysis.CostEstimate{TotalDataGB: 1, S3DataGB: 0.5, OtherDataGB: 0.5, NATGatewayPricePerGB: 0.045}
-	events := BuildEvents("acct", "us-east-1", nats, stats, cost, nil)
+	stats := &analysis.TrafficStats{TotalBytes: 1000, Services: map[analysis.Service]analysis.ServiceStats{analysis.ServiceS3: {Bytes: 500}, analysis.ServiceOther: {Bytes: 500}}}
+	cost := &analysis.CostEstimate{TotalDataGB: 1, CurrentMonthlyCost: 0.045, S3DataGB: 0.5, OtherDataGB: 0.5, NATGatewayPricePerGB: 0.045}
+
+	totalCost := func(events []Event) float64 {
+		var sum float64
+		for _, e := range events {
+			for _, d := range e.Dimensions {
+				if d.Key == "traffic_service" && d.Value == "Total" {
+					sum += e.Metrics[0].Value
+				}
+			}
+		}
+		return sum
+	}
+
+	// Account granularity: one set, so the totals are not repeated per NAT
+	events := BuildEvents("acct", "us-east-1", nats, stats, cost, nil, nil)
+	if len(events) != 5 {
+		t.Fatalf("got %d events, want 5 for the account", len(events))
+	}
+	if got := totalCost(events); got != cost.CurrentMonthlyCost {
+		t.Errorf("account total cost=%f, want %f", got, cost.CurrentMonthlyCost)
+	}
+	for _, d := range events[0].Dimensions {
+		if d.Key == "resource_id" && d.Value != "nat-1, nat-2" {
+			t.Errorf("resource_id=%q, want both NATs", d.Value)
+		}
+		if d.Key == "vpc_id" && d.Value != "multiple" {
+			t.Errorf("vpc_id=%q, want multiple", d.Value)
+		}
+	}
+
+	// NAT granularity: each NAT's own share, summing to the account total
+	perNAT := map[string]NATUsage{
+		"nat-1": {Stats: &analysis.TrafficStats{TotalBytes: 750, Services: map[analysis.Service]analysis.ServiceStats{analysis.ServiceS3: {Bytes: 500}, analysis.ServiceOther: {Bytes: 250}}}, Cost: &analysis.CostEstimate{CurrentMonthlyCost: 0.03, NATGatewayPricePerGB: 0.045}},
+		"nat-2": {Stats: &analysis.TrafficStats{TotalBytes: 250, Services: map[analysis.Service]analysis.ServiceStats{analysis.ServiceOther: {Bytes: 250}}}, Cost: &analysis.CostEstimate{CurrentMonthlyCost: 0.015, NATGatewayPricePerGB: 0.045}},
+	}
+	events = BuildEvents("acct", "us-east-1", nats, stats, cost, nil, perNAT)
 	if len(events) != 10 {
 		t.Fatalf("got %d events, want 10 (5 per NAT)", len(events))
 	}
+	if got := totalCost(events); math.Abs(got-cost.CurrentMonthlyCost) > 1e-9 {
+		t.Errorf("per-NAT total cost=%f, want %f", got, cost.CurrentMonthlyCost)
+	}
+
+	// A NAT without usage (its query failed): one account set instead of
+	// dropping that NAT's traffic from the dashboards
+	delete(perNAT, "nat-2")
+	events = BuildEvents("acct", "us-east-1", nats, stats, cost, nil, perNAT)
+	if len(events) != 5 {
+		t.Fatalf("got %d events, want 5 for the account when a NAT has no usage", len(events))
+	}
+	if got := totalCost(events); got != cost.CurrentMonthlyCost {
+		t.Errorf("fallback total cost=%f, want %f", got, cost.CurrentMonthlyCost)
+	}
+}
+
+func TestParseGranularity(t *testing.T) {
+	for in, want := range map[string]Granularity{"nat": GranularityNAT, " Account ": GranularityAccount} {
+		if got, err := ParseGranularity(in); err != nil || got != want {
+			t.Errorf("ParseGranularity(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseGranularity("vpc"); err == nil {
+		t.Error("want an error for an unknown granularity")
+	}
 }
 
 func TestBuildEventsECRNaNGuard(t *testing.T) {
 	nats := []types.NATGateway{{ID: "nat-1"}}
-	stats := &analysis.TrafficStats{TotalBytes: 1000, S3Bytes: 1000} // no Other/ECR bytes
+	stats := &analysis.TrafficStats{TotalBytes: 1000, Services: map[analysis.Service]analysis.ServiceStats{analysis.ServiceS3: {Bytes: 1000}}} // no Other/ECR bytes
 	cost := &analysis.CostEstimate{TotalDataGB: 1, S3DataGB: 1, OtherDataGB: 0, NATGatewayPricePerGB: 0.045}
-	events := BuildEvents("acct", "us-east-1", nats, stats, cost, nil)
+	events := BuildEvents("acct", "us-east-1", nats, stats, cost, nil, nil)
 
 	for _, e := range events {
 		for _, m := range e.Metrics {
@@ -167,12 +247,9 @@ func TestSendSuccess(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	orig := apiURL
-	apiURL = srv.URL
-	defer func() { apiURL = orig }()
-
+	tr := Transport{URL: srv.URL}
 	events := []Event{{Provider: "test", ID: "e1"}}
-	if err := Send("test-key", "ctx", events); err != nil {
+	if err := tr.Send("test-key", "ctx", events); err != nil {
 		t.Fatalf("Send: %v", err)
 	}
 	if len(received.Events) != 1 {
@@ -189,11 +266,8 @@ func TestSendNoCustomerContext(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	orig := apiURL
-	apiURL = srv.URL
-	defer func() { apiURL = orig }()
-
-	Send("key", "", []Event{{ID: "e1"}})
+	tr := Transport{URL: srv.URL}
+	tr.Send("key", "", []Event{{ID: "e1"}})
 }
 
 func TestSendErrorStatus(t *testing.T) {
@@ -202,11 +276,8 @@ func TestSendErrorStatus(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	orig := apiURL
-	apiURL = srv.URL
-	defer func() { apiURL = orig }()
-
-	err := Send("key", "", []Event{{ID: "e1"}})
+	tr := Transport{URL: srv.URL}
+	err := tr.Send("key", "", []Event{{ID: "e1"}})
 	if err == nil {
 		t.Fatal("expected error for 403")
 	}
@@ -224,11 +295,8 @@ func TestSendRetry429(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	orig := apiURL
-	apiURL = srv.URL
-	defer func() { apiURL = orig }()
-
-	if err := Send("key", "", []Event{{ID: "e1"}}); err != nil {
+	tr := Transport{URL: srv.URL, MaxRetries: 1, RetryBackoff: time.Millisecond}
+	if err := tr.Send("key", "", []Event{{ID: "e1"}}); err != nil {
 		t.Fatalf("Send with retry: %v", err)
 	}
 	if atomic.LoadInt32(&calls) < 2 {
@@ -236,6 +304,57 @@ func TestSendRetry429(t *testing.T) {
 	}
 }
 
+func TestSendRetryLimit(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(429)
+	}))
+	defer srv.Close()
+
+	tr := Transport{URL: srv.URL, MaxRetries: 2, RetryBackoff: time.Millisecond}
+	if err := tr.Send("key", "", []Event{{ID: "e1"}}); err == nil {
+		t.Fatal("expected error once retries run out")
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("got %d calls, want 3 (1 + 2 retries)", got)
+	}
+}
+
+func TestBuildScanCostEvents(t *testing.T) {
+	if events := BuildScanCostEvents("acct", "us-east-1", "terminat-1", nil); events != nil {
+		t.Fatal("expected nil for nil scan cost")
+	}
+
+	scanCost := analysis.CalculateScanCost(2, 1<<30)
+	events := BuildScanCostEvents("123456789012", "us-east-1", "terminat-1", scanCost)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	var total float64
+	for _, e := range events {
+		for _, d := range e.Dimensions {
+			if d.Key == "resource_id" && d.Value != "terminat-1" {
+				t.Errorf("resource_id=%q, want the run ID", d.Value)
+			}
+			if d.Key == "service_description" && d.Value != "termiNATor Scan" {
+				t.Errorf("service_description=%q", d.Value)
+			}
+		}
+		for _, m := range e.Metrics {
+			if m.Type == "cost" {
+				total += m.Value
+			}
+		}
+	}
+	if events[0].ID == events[1].ID {
+		t.Fatalf("duplicate event ID %q", events[0].ID)
+	}
+	if diff := total - scanCost.Total(); diff > 1e-9 || diff < -1e-9 {
+		t.Fatalf("cost total=%f, want %f", total, scanCost.Total())
+	}
+}
+
 func TestSendBatching(t *testing.T) {
 	var batchCount int32
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -249,15 +368,12 @@ func TestSendBatching(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	orig := apiURL
-	apiURL = srv.URL
-	defer func() { apiURL = orig }()
-
+	tr := Transport{URL: srv.URL}
 	events := make([]Event, 300)
 	for i := range events {
 		events[i] = Event{ID: "e"}
 	}
-	if err := Send("key", "", events); err != nil {
+	if err := tr.Send("key", "", events); err != nil {
 		t.Fatalf("Send batching: %v", err)
 	}
 	if atomic.LoadInt32(&batchCount) != 2 {
@@ -0,0 +1,222 @@
+// Package fixtures holds canned deep scan results for rendering tests: no
+// traffic, one VPC, several VPCs and a sample too large for naive number
+// formatting. They are built by hand, without AWS or the IP ranges, so
+// rendered output is stable.
+package fixtures
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// Region and AccountID are where every fixture was "scanned".
+const (
+	Region    = "us-east-1"
+	AccountID = "123456789012"
+)
+
+// GeneratedAt is the fixtures' report time.
+var GeneratedAt = time.Date(2024, 3, 14, 15, 9, 26, 0, time.UTC)
+
+const gb = 1 << 30
+
+// Scan is one deep scan's results, as the renderers receive them.
+type Scan struct {
+	Name      string
+	Duration  int
+	NATs      []types.NATGateway
+	Stats     *analysis.TrafficStats
+	Cost      *analysis.CostEstimate
+	Endpoints *analysis.EndpointAnalysis
+	Findings  []types.Finding
+}
+
+// All returns every fixture.
+func All() []Scan {
+	return []Scan{Empty(), SingleVPC(), MultiVPC(), HugeTraffic()}
+}
+
+// Empty is a scan that collected no traffic in a VPC with both gateway
+// endpoints.
+func Empty() Scan {
+	nats := []types.NATGateway{nat("nat-0empty", "vpc-0empty", "us-east-1a")}
+	return Scan{
+		Name:      "empty",
+		Duration:  5,
+		NATs:      nats,
+		Stats:     &analysis.TrafficStats{},
+		Endpoints: endpoints("vpc-0empty", true),
+	}
+}
+
+// SingleVPC is a scan of one VPC missing its DynamoDB gateway endpoint.
+func SingleVPC() Scan {
+	nats := []types.NATGateway{nat("nat-0single", "vpc-0single", "us-east-1a")}
+	stats := traffic(3, map[string][4]int64{
+		"10.0.1.10": {gb, gb / 2, gb / 4, gb / 4},
+		"10.0.1.11": {gb / 2, 0, 0, gb / 8},
+		"10.0.2.20": {0, gb / 4, 0, gb / 8},
+	})
+	scan := Scan{
+		Name:      "single-vpc",
+		Duration:  15,
+		NATs:      nats,
+		Stats:     stats,
+		Cost:      analysis.CalculateCosts(Region, stats, 15),
+		Endpoints: endpoints("vpc-0single", false),
+	}
+	scan.Findings = []types.Finding{missingDynamoDB("vpc-0single")}
+	return scan
+}
+
+// MultiVPC is a scan of NAT Gateways in three VPCs, one of them deep
+// scanned and missing its DynamoDB gateway endpoint.
+func MultiVPC() Scan {
+	nats := []types.NATGateway{
+		nat("nat-0multia", "vpc-0multi1", "us-east-1a"),
+		nat("nat-0multib", "vpc-0multi1", "us-east-1b"),
+		nat("nat-0multic", "vpc-0multi2", "us-east-1a"),
+		nat("nat-0multid", "vpc-0multi3", "us-east-1c"),
+	}
+	stats := traffic(7, map[string][4]int64{
+		"10.1.1.10": {2 * gb, gb, gb / 2, gb},
+		"10.1.2.10": {gb, 0, gb / 2, gb / 2},
+		"10.1.3.10": {0, gb / 2, 0, gb / 4},
+	})
+	scan := Scan{
+		Name:      "multi-vpc",
+		Duration:  30,
+		NATs:      nats,
+		Stats:     stats,
+		Cost:      analysis.CalculateCosts(Region, stats, 30),
+		Endpoints: endpoints("vpc-0multi1", false),
+	}
+	scan.Findings = []types.Finding{
+		missingDynamoDB("vpc-0multi1"),
+		{
+			Type:        "missing-endpoint",
+			Severity:    "high",
+			Title:       "Missing S3 Gateway Endpoint",
+			Description: "VPC vpc-0multi2 has NAT Gateway(s) but no S3 Gateway endpoint",
+			VPCID:       "vpc-0multi2",
+			Service:     "S3",
+			Action:      "Create S3 Gateway VPC endpoint and associate with private route tables",
+			Impact:      "All S3 traffic is going through NAT Gateway, incurring $0.045/GB data processing charges",
+			Effort:      analysis.EffortLow,
+		},
+	}
+	return scan
+}
+
+// HugeTraffic is a short sample of several terabytes from more sources than
+// the reports list, projecting to millions of dollars a month.
+func HugeTraffic() Scan {
+	nats := []types.NATGateway{nat("nat-0huge", "vpc-0huge", "us-east-1a")}
+	sources := make(map[string][4]int64)
+	for i := 0; i < 14; i++ {
+		sources[fmt.Sprintf("10.9.%d.%d", i/4, 10+i)] = [4]int64{int64(200+i) * gb, int64(90+i) * gb, int64(40+i) * gb, int64(70+i) * gb}
+	}
+	stats := traffic(1_250_000, sources)
+	scan := Scan{
+		Name:      "huge-traffic",
+		Duration:  5,
+		NATs:      nats,
+		Stats:     stats,
+		Cost:      analysis.CalculateCosts(Region, stats, 5),
+		Endpoints: endpoints("vpc-0huge", false),
+	}
+	scan.Findings = []types.Finding{missingDynamoDB("vpc-0huge")}
+	return scan
+}
+
+func nat(id, vpcID, az string) types.NATGateway {
+	return types.NATGateway{
+		ID:               id,
+		VPCID:            vpcID,
+		SubnetID:         "subnet-" + id[len("nat-"):],
+		AvailabilityZone: az,
+		State:            "available",
+		ConnectivityType: "public",
+		AvailabilityMode: "zonal",
+		Tags:             map[string]string{"Name": id + "-egress"},
+		CreateTime:       time.Date(2021, 1, 12, 9, 30, 0, 0, time.UTC),
+	}
+}
+
+// traffic builds stats from per-source S3, DynamoDB, ECR and other bytes,
+// spreading recordsPerSource flows over each source.
+func traffic(recordsPerSource int, sources map[string][4]int64) *analysis.TrafficStats {
+	stats := &analysis.TrafficStats{SourceIPs: make(map[string]*analysis.SourceIPStats)}
+	for ip, b := range sources {
+		src := &analysis.SourceIPStats{Records: recordsPerSource, Services: map[analysis.Service]int64{}}
+		for i, n := range b {
+			if n == 0 {
+				continue
+			}
+			service := analysis.Services[i]
+			src.Services[service] = n
+			src.Bytes += n
+			stats.AddService(service, n, recordsPerSource/4)
+		}
+		stats.SourceIPs[ip] = src
+		stats.TotalBytes += src.Bytes
+		stats.TotalRecords += recordsPerSource
+	}
+	stats.Accuracy = analysis.ClassificationAccuracy{
+		ExactBytes:     stats.Bytes(analysis.ServiceS3) + stats.Bytes(analysis.ServiceDynamoDB),
+		BroadEC2Bytes:  stats.Bytes(analysis.ServiceECR),
+		UnmatchedBytes: stats.Bytes(analysis.ServiceOther),
+	}
+	return stats
+}
+
+// endpoints analyzes a VPC with one private route table through the NAT
+// Gateway and an S3 gateway endpoint on it, plus a DynamoDB one when both is
+// set.
+func endpoints(vpcID string, both bool) *analysis.EndpointAnalysis {
+	rt := types.RouteTable{
+		ID:      "rtb-" + vpcID[len("vpc-"):],
+		VPCID:   vpcID,
+		Subnets: []string{"subnet-private-" + vpcID[len("vpc-"):]},
+		Routes: []types.Route{
+			{DestinationCIDR: "10.0.0.0/16", Target: "local", TargetType: "local"},
+			{DestinationCIDR: "0.0.0.0/0", Target: "nat-" + vpcID[len("vpc-"):], TargetType: "nat-gateway"},
+		},
+	}
+	eps := []types.VPCEndpoint{{
+		ID:          "vpce-s3" + vpcID[len("vpc-"):],
+		VPCID:       vpcID,
+		ServiceName: "com.amazonaws." + Region + ".s3",
+		Type:        "Gateway",
+		State:       "available",
+		RouteTables: []string{rt.ID},
+	}}
+	if both {
+		eps = append(eps, types.VPCEndpoint{
+			ID:          "vpce-ddb" + vpcID[len("vpc-"):],
+			VPCID:       vpcID,
+			ServiceName: "com.amazonaws." + Region + ".dynamodb",
+			Type:        "Gateway",
+			State:       "available",
+			RouteTables: []string{rt.ID},
+		})
+	}
+	return analysis.AnalyzeEndpoints(Region, vpcID, eps, []types.RouteTable{rt})
+}
+
+func missingDynamoDB(vpcID string) types.Finding {
+	return types.Finding{
+		Type:        "missing-endpoint",
+		Severity:    "high",
+		Title:       "Missing DynamoDB Gateway Endpoint",
+		Description: fmt.Sprintf("VPC %s has NAT Gateway(s) but no DynamoDB Gateway endpoint", vpcID),
+		VPCID:       vpcID,
+		Service:     "DynamoDB",
+		Action:      "Create DynamoDB Gateway VPC endpoint and associate with private route tables",
+		Impact:      "All DynamoDB traffic is going through NAT Gateway, incurring $0.045/GB data processing charges",
+		Effort:      analysis.EffortLow,
+	}
+}
@@ -0,0 +1,152 @@
+// Package github posts termiNATor results to pull requests from GitHub
+// Actions, using the token and event the workflow provides.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Context is the repository, pull request and credentials of a workflow run.
+type Context struct {
+	APIURL string
+	Token  string
+	Repo   string // owner/name
+	PR     int
+	// RunURL links the workflow run, for the full report.
+	RunURL string
+}
+
+// FromEnv reads the GitHub Actions environment. pr overrides the pull
+// request from the event payload (0 = from the event).
+func FromEnv(pr int) (Context, error) {
+	c := Context{
+		APIURL: strings.TrimRight(os.Getenv("GITHUB_API_URL"), "/"),
+		Token:  os.Getenv("GITHUB_TOKEN"),
+		Repo:   os.Getenv("GITHUB_REPOSITORY"),
+		PR:     pr,
+	}
+	if c.APIURL == "" {
+		c.APIURL = "https://api.github.com"
+	}
+	if c.Token == "" {
+		return c, fmt.Errorf("GITHUB_TOKEN is not set; pass it to the step with env: GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }}")
+	}
+	if c.Repo == "" {
+		return c, fmt.Errorf("GITHUB_REPOSITORY is not set; run inside GitHub Actions or set it to owner/name")
+	}
+	if server, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_RUN_ID"); server != "" && runID != "" {
+		c.RunURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, c.Repo, runID)
+	}
+	if c.PR == 0 {
+		n, err := prFromEvent(os.Getenv("GITHUB_EVENT_PATH"))
+		if err != nil {
+			return c, err
+		}
+		c.PR = n
+	}
+	return c, nil
+}
+
+// prFromEvent reads the pull request number from a pull_request or
+// pull_request_target event payload.
+func prFromEvent(path string) (int, error) {
+	if path == "" {
+		return 0, fmt.Errorf("no pull request: GITHUB_EVENT_PATH is not set; pass --pr")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var event struct {
+		PullRequest struct {
+			Number int `json:"number"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if event.PullRequest.Number == 0 {
+		return 0, fmt.Errorf("the workflow event is not a pull request; pass --pr")
+	}
+	return event.PullRequest.Number, nil
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+type comment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// UpsertComment edits the pull request comment containing marker, or
+// creates one. It returns the comment's ID.
+func UpsertComment(ctx context.Context, c Context, marker, body string) (int64, error) {
+	for page := 1; ; page++ {
+		var comments []comment
+		path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100&page=%d", c.Repo, c.PR, page)
+		if err := c.do(ctx, http.MethodGet, path, nil, &comments); err != nil {
+			return 0, fmt.Errorf("failed to list comments on #%d: %w", c.PR, err)
+		}
+		for _, existing := range comments {
+			if strings.Contains(existing.Body, marker) {
+				var updated comment
+				if err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", c.Repo, existing.ID), map[string]string{"body": body}, &updated); err != nil {
+					return 0, fmt.Errorf("failed to update comment: %w", err)
+				}
+				return updated.ID, nil
+			}
+		}
+		if len(comments) < 100 {
+			break
+		}
+	}
+
+	var created comment
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", c.Repo, c.PR), map[string]string{"body": body}, &created); err != nil {
+		return 0, fmt.Errorf("failed to comment on #%d: %w", c.PR, err)
+	}
+	return created.ID, nil
+}
+
+func (c Context) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.APIURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GitHub API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFromEnvReadsPullRequestEvent(t *testing.T) {
+	event := filepath.Join(t.TempDir(), "event.json")
+	os.WriteFile(event, []byte(`{"action":"synchronize","pull_request":{"number":42}}`), 0o600)
+	t.Setenv("GITHUB_TOKEN", "tok")
+	t.Setenv("GITHUB_REPOSITORY", "acme/infra")
+	t.Setenv("GITHUB_EVENT_PATH", event)
+	t.Setenv("GITHUB_API_URL", "")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_RUN_ID", "7")
+
+	c, err := FromEnv(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.PR != 42 || c.APIURL != "https://api.github.com" || c.RunURL != "https://github.com/acme/infra/actions/runs/7" {
+		t.Fatalf("context = %+v", c)
+	}
+
+	os.WriteFile(event, []byte(`{"ref":"refs/heads/main"}`), 0o600)
+	if _, err := FromEnv(0); err == nil {
+		t.Fatal("push events have no pull request")
+	}
+	if c, err := FromEnv(9); err != nil || c.PR != 9 {
+		t.Fatalf("--pr should override the event: %+v %v", c, err)
+	}
+}
+
+func TestUpsertComment(t *testing.T) {
+	var existing []comment
+	var patched, posted int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("bad auth %q", r.Header.Get("Authorization"))
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/infra/issues/5/comments":
+			json.NewEncoder(w).Encode(existing)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/acme/infra/issues/comments/11":
+			patched++
+			w.Write([]byte(`{"id":11}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/infra/issues/5/comments":
+			posted++
+			w.Write([]byte(`{"id":12}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	c := Context{APIURL: srv.URL, Token: "tok", Repo: "acme/infra", PR: 5}
+
+	existing = []comment{{ID: 10, Body: "LGTM"}}
+	id, err := UpsertComment(context.Background(), c, "<!-- m -->", "<!-- m -->\nreport")
+	if err != nil || id != 12 || posted != 1 {
+		t.Fatalf("create: id=%d posted=%d err=%v", id, posted, err)
+	}
+
+	existing = append(existing, comment{ID: 11, Body: "<!-- m -->\nold report"})
+	id, err = UpsertComment(context.Background(), c, "<!-- m -->", "<!-- m -->\nnew report")
+	if err != nil || id != 11 || patched != 1 || posted != 1 {
+		t.Fatalf("update: id=%d patched=%d posted=%d err=%v", id, patched, posted, err)
+	}
+}
+
+func TestUpsertCommentReportsAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"Resource not accessible by integration"}`)
+	}))
+	defer srv.Close()
+	c := Context{APIURL: srv.URL, Token: "tok", Repo: "acme/infra", PR: 5}
+	if _, err := UpsertComment(context.Background(), c, "m", "body"); err == nil {
+		t.Fatal("expected error")
+	}
+}
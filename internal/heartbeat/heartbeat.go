@@ -0,0 +1,214 @@
+// Package heartbeat keeps a status file up to date while a deep scan runs,
+// so schedulers and wrappers can follow a long scan without parsing its
+// console output.
+package heartbeat
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// File is the status file kept in the run directory.
+const File = "status.json"
+
+// Interval is how often the status file is rewritten between phase changes.
+const Interval = 15 * time.Second
+
+// Phases of a deep scan, in order. Stages logged under other names (warn,
+// scan) don't change the phase.
+var Phases = []string{"discover", "setup", "startup", "collect", "analyze", "cleanup", "export"}
+
+// Final phases.
+const (
+	PhaseDone   = "done"
+	PhaseFailed = "failed"
+)
+
+// Typical length of the phases other than collect, which takes the scan
+// duration. They only shape the percentage and estimated completion.
+var typical = map[string]time.Duration{
+	"discover": 30 * time.Second,
+	"setup":    time.Minute,
+	"startup":  3 * time.Minute,
+	"analyze":  2 * time.Minute,
+	"cleanup":  30 * time.Second,
+	"export":   30 * time.Second,
+}
+
+// Status is the content of the status file.
+type Status struct {
+	RunID          string  `json:"run_id"`
+	Region         string  `json:"region"`
+	PID            int     `json:"pid"`
+	Phase          string  `json:"phase"`
+	Percent        float64 `json:"percent"`
+	ElapsedSeconds int64   `json:"elapsed_seconds"`
+	// EstimatedCompletion is unset once the scan has finished.
+	EstimatedCompletion *time.Time `json:"estimated_completion,omitempty"`
+	StartedAt           time.Time  `json:"started_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	Error               string     `json:"error,omitempty"`
+}
+
+// Heartbeat writes the status of one scan. A nil *Heartbeat is valid and
+// does nothing, so scans still run when no run directory is available.
+type Heartbeat struct {
+	path    string
+	collect time.Duration
+	now     func() time.Time
+
+	// writeMu keeps the ticker and phase changes from writing at once.
+	writeMu      sync.Mutex
+	mu           sync.Mutex
+	status       Status
+	phaseStarted time.Time
+	stop         chan struct{}
+	stopped      sync.Once
+}
+
+// New returns a heartbeat writing to path for a scan collecting traffic for
+// collect. Nothing is written until Start.
+func New(path, runID, region string, collect time.Duration) *Heartbeat {
+	h := &Heartbeat{path: path, collect: collect, now: time.Now, stop: make(chan struct{})}
+	now := h.now()
+	h.status = Status{RunID: runID, Region: region, PID: os.Getpid(), Phase: Phases[0], StartedAt: now}
+	h.phaseStarted = now
+	return h
+}
+
+// Start writes the status file and rewrites it every Interval until Finish.
+func (h *Heartbeat) Start() {
+	if h == nil {
+		return
+	}
+	h.write()
+	go func() {
+		ticker := time.NewTicker(Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-h.stop:
+				return
+			case <-ticker.C:
+				h.write()
+			}
+		}
+	}()
+}
+
+// Phase records that the scan entered phase. Names outside Phases, moves
+// back to an earlier phase and changes after Finish are ignored.
+func (h *Heartbeat) Phase(phase string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	if h.finished() || phaseIndex(phase) <= phaseIndex(h.status.Phase) {
+		h.mu.Unlock()
+		return
+	}
+	h.status.Phase = phase
+	h.phaseStarted = h.now()
+	h.mu.Unlock()
+	h.write()
+}
+
+// Finish stops the heartbeat and writes the final status: done, or failed
+// with err.
+func (h *Heartbeat) Finish(err error) {
+	if h == nil {
+		return
+	}
+	h.stopped.Do(func() { close(h.stop) })
+	h.mu.Lock()
+	if err != nil {
+		h.status.Phase, h.status.Error = PhaseFailed, err.Error()
+	} else {
+		h.status.Phase = PhaseDone
+	}
+	h.mu.Unlock()
+	h.write()
+}
+
+func (h *Heartbeat) finished() bool {
+	return h.status.Phase == PhaseDone || h.status.Phase == PhaseFailed
+}
+
+// Snapshot returns the current status, with progress as of now.
+func (h *Heartbeat) Snapshot() Status {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	s := h.status
+	s.UpdatedAt = now
+	s.ElapsedSeconds = int64(now.Sub(s.StartedAt).Seconds())
+	switch s.Phase {
+	case PhaseDone:
+		s.Percent = 100
+	case PhaseFailed:
+	default:
+		done, remaining := h.progress(now)
+		s.Percent = 100 * done.Seconds() / (done + remaining).Seconds()
+		eta := now.Add(remaining)
+		s.EstimatedCompletion = &eta
+	}
+	return s
+}
+
+// progress returns the expected time of the phases done so far, counting
+// the current one up to its expected length, and the expected time left.
+func (h *Heartbeat) progress(now time.Time) (done, remaining time.Duration) {
+	current := phaseIndex(h.status.Phase)
+	for i, phase := range Phases {
+		expected := h.expected(phase)
+		switch {
+		case i < current:
+			done += expected
+		case i == current:
+			in := min(now.Sub(h.phaseStarted), expected)
+			done += in
+			remaining += expected - in
+		default:
+			remaining += expected
+		}
+	}
+	return done, remaining
+}
+
+func (h *Heartbeat) expected(phase string) time.Duration {
+	if phase == "collect" {
+		return h.collect
+	}
+	return typical[phase]
+}
+
+// write replaces the status file through a rename, so readers never see
+// half of it. A failed write is dropped; the next one may succeed.
+func (h *Heartbeat) write() {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	data, err := json.MarshalIndent(h.Snapshot(), "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, h.path)
+}
+
+func phaseIndex(phase string) int {
+	for i, p := range Phases {
+		if p == phase {
+			return i
+		}
+	}
+	return -1
+}
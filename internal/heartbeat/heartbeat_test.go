@@ -0,0 +1,83 @@
+package heartbeat
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readStatus(t *testing.T, path string) Status {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Status
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestHeartbeatProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last-run", File)
+	h := New(path, "terminat-1", "us-east-1", 30*time.Minute)
+	clock := h.status.StartedAt
+	h.now = func() time.Time { return clock }
+
+	h.Start()
+	defer h.Finish(nil)
+	if s := readStatus(t, path); s.Phase != "discover" || s.Percent != 0 || s.EstimatedCompletion == nil {
+		t.Fatalf("unexpected initial status: %+v", s)
+	}
+
+	// Halfway through collection, with discover, setup and startup behind.
+	h.Phase("collect")
+	clock = clock.Add(15 * time.Minute)
+	s := h.Snapshot()
+	before := typical["discover"] + typical["setup"] + typical["startup"]
+	after := typical["analyze"] + typical["cleanup"] + typical["export"]
+	want := 100 * (before + 15*time.Minute).Seconds() / (before + 30*time.Minute + after).Seconds()
+	if s.Percent != want {
+		t.Errorf("percent = %.2f, want %.2f", s.Percent, want)
+	}
+	if eta := clock.Add(15*time.Minute + after); !s.EstimatedCompletion.Equal(eta) {
+		t.Errorf("estimated completion = %s, want %s", s.EstimatedCompletion, eta)
+	}
+	if s.ElapsedSeconds != 15*60 {
+		t.Errorf("elapsed = %d, want 900", s.ElapsedSeconds)
+	}
+
+	// Earlier phases and other stage names don't move the phase back.
+	h.Phase("setup")
+	h.Phase("warn")
+	if s := readStatus(t, path); s.Phase != "collect" {
+		t.Errorf("phase = %q, want collect", s.Phase)
+	}
+}
+
+func TestHeartbeatFinish(t *testing.T) {
+	dir := t.TempDir()
+	done := New(filepath.Join(dir, "done.json"), "terminat-1", "us-east-1", time.Minute)
+	done.Start()
+	done.Finish(nil)
+	done.Phase("export")
+	if s := readStatus(t, filepath.Join(dir, "done.json")); s.Phase != PhaseDone || s.Percent != 100 || s.EstimatedCompletion != nil {
+		t.Errorf("unexpected final status: %+v", s)
+	}
+
+	failed := New(filepath.Join(dir, "failed.json"), "terminat-2", "us-east-1", time.Minute)
+	failed.Start()
+	failed.Finish(errors.New("scan cancelled during traffic collection"))
+	if s := readStatus(t, filepath.Join(dir, "failed.json")); s.Phase != PhaseFailed || s.Error == "" {
+		t.Errorf("unexpected failed status: %+v", s)
+	}
+
+	var none *Heartbeat
+	none.Start()
+	none.Phase("collect")
+	none.Finish(nil)
+}
@@ -0,0 +1,100 @@
+package history
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/doitintl/terminator/internal/report"
+)
+
+const indexFile = "index.jsonl"
+
+// DirStore keeps reports as <entry-id>.json in a directory, indexed by an
+// append-only index.jsonl.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore returns a store rooted at dir.
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{dir: dir}
+}
+
+// Location returns the directory.
+func (s *DirStore) Location() string {
+	return s.dir
+}
+
+// Put writes the report and appends its entry to the index.
+func (s *DirStore) Put(ctx context.Context, e Entry, rep *report.Report) error {
+	data, err := encode(rep)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, e.ID()+".json"), data, 0o600); err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, indexFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// List reads the index; unreadable lines are skipped.
+func (s *DirStore) List(ctx context.Context, accountID, region string, limit int) ([]Entry, error) {
+	f, err := os.Open(filepath.Join(s.dir, indexFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if (accountID == "" || e.AccountID == accountID) && (region == "" || e.Region == region) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].GeneratedAt.After(entries[j].GeneratedAt) })
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// Get reads the report stored under id.
+func (s *DirStore) Get(ctx context.Context, id string) (*report.Report, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.Base(id)+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no scan %s in %s", id, s.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report.Parse(data, id)
+}
@@ -0,0 +1,147 @@
+// Package history keeps the JSON report of every deep scan, indexed by
+// account and region, so earlier scans can be listed and used as baselines.
+// Reports stay under the local state directory unless the [history] section
+// of the config points at an S3 bucket and DynamoDB table the team shares.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/doitintl/terminator/internal/config"
+	"github.com/doitintl/terminator/internal/manifest"
+	"github.com/doitintl/terminator/internal/report"
+)
+
+// Entry indexes one stored report.
+type Entry struct {
+	RunID       string    `json:"run_id"`
+	AccountID   string    `json:"account_id"`
+	Region      string    `json:"region"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// MonthlyNATCost and MonthlySavings are the report's projections, so a
+	// listing shows the trend without fetching every report.
+	MonthlyNATCost float64 `json:"monthly_nat_cost"`
+	MonthlySavings float64 `json:"monthly_savings"`
+	Host           string  `json:"host,omitempty"`
+}
+
+// ID identifies the entry across the history. Run IDs are timestamps, so
+// scans scheduled at the same time in several accounts share one.
+func (e Entry) ID() string {
+	return e.AccountID + "-" + e.Region + "-" + e.RunID
+}
+
+// NewEntry indexes rep under runID.
+func NewEntry(runID string, rep *report.Report) Entry {
+	host, _ := os.Hostname()
+	e := Entry{
+		RunID:       runID,
+		AccountID:   rep.AccountID,
+		Region:      rep.Region,
+		GeneratedAt: rep.GeneratedAt,
+		Host:        host,
+	}
+	if rep.CostEstimate != nil {
+		e.MonthlyNATCost = rep.CostEstimate.CurrentMonthlyCost
+		e.MonthlySavings = rep.CostEstimate.TotalSavingsMonthly
+	}
+	return e
+}
+
+// Store keeps reports and their index.
+type Store interface {
+	// Put stores a report as JSON.
+	Put(ctx context.Context, e Entry, rep *report.Report) error
+	// List returns the entries of an account and region, newest first, at
+	// most limit of them (0 = all). Empty accountID and region list every
+	// entry where the store allows it.
+	List(ctx context.Context, accountID, region string, limit int) ([]Entry, error)
+	// Get returns the report stored under an entry ID.
+	Get(ctx context.Context, id string) (*report.Report, error)
+	// Location describes where reports are kept, for messages.
+	Location() string
+}
+
+// Config is the [history] section of the config:
+//
+//	[history]
+//	bucket = "finops-terminat"     # reports, as <prefix><id>.json
+//	prefix = "history/"            # optional
+//	table = "terminat-history"     # DynamoDB index
+//	region = "us-east-1"           # optional; AWS_REGION otherwise
+//	profile = "shared-services"    # optional; default credential chain otherwise
+//
+// Without bucket and table, reports are kept in the local state directory.
+type Config struct {
+	Bucket  string
+	Prefix  string
+	Table   string
+	Region  string
+	Profile string
+}
+
+// Remote reports whether the config selects the S3/DynamoDB backend.
+func (c Config) Remote() bool {
+	return c.Bucket != "" || c.Table != ""
+}
+
+// LoadConfig reads the [history] section.
+func LoadConfig() (Config, error) {
+	content, err := config.Read()
+	if err != nil {
+		return Config{}, err
+	}
+	return parseConfig(content), nil
+}
+
+func parseConfig(content string) Config {
+	s := config.Section(content, "history")
+	return Config{
+		Bucket:  s["bucket"],
+		Prefix:  s["prefix"],
+		Table:   s["table"],
+		Region:  s["region"],
+		Profile: s["profile"],
+	}
+}
+
+// Open returns the store the config selects.
+func Open(ctx context.Context, cfg Config) (Store, error) {
+	if !cfg.Remote() {
+		dir, err := DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+		return NewDirStore(dir), nil
+	}
+	if cfg.Bucket == "" || cfg.Table == "" {
+		return nil, fmt.Errorf("history: the [history] section needs both bucket and table")
+	}
+	return newRemoteStore(ctx, cfg)
+}
+
+// DefaultDir is where reports are kept without a remote backend: history
+// under $TERMINAT_STATE_DIR, or <user config dir>/terminat/history.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(manifest.StateDirEnv); dir != "" {
+		return filepath.Join(dir, "history"), nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory for scan history: %w", err)
+	}
+	return filepath.Join(base, "terminat", "history"), nil
+}
+
+func encode(rep *report.Report) ([]byte, error) {
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,91 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/report"
+)
+
+func testReport(account, region string, at time.Time) *report.Report {
+	return &report.Report{
+		GeneratedAt:  at,
+		AccountID:    account,
+		Region:       region,
+		CostEstimate: &analysis.CostEstimate{CurrentMonthlyCost: 120, TotalSavingsMonthly: 80},
+	}
+}
+
+func TestDirStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := NewDirStore(t.TempDir())
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, run := range []struct {
+		id, account, region string
+	}{
+		{"run-1", "111111111111", "us-east-1"},
+		{"run-2", "111111111111", "us-east-1"},
+		{"run-3", "222222222222", "us-east-1"},
+	} {
+		rep := testReport(run.account, run.region, base.Add(time.Duration(i)*time.Hour))
+		if err := store.Put(ctx, NewEntry(run.id, rep), rep); err != nil {
+			t.Fatalf("Put %s: %v", run.id, err)
+		}
+	}
+
+	entries, err := store.List(ctx, "111111111111", "us-east-1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].RunID != "run-2" || entries[1].RunID != "run-1" {
+		t.Fatalf("expected the account's runs newest first, got %+v", entries)
+	}
+	if entries[0].MonthlyNATCost != 120 || entries[0].MonthlySavings != 80 {
+		t.Errorf("expected the cost projections in the index, got %+v", entries[0])
+	}
+	if all, _ := store.List(ctx, "", "", 1); len(all) != 1 || all[0].RunID != "run-3" {
+		t.Errorf("expected the newest run of any account, got %+v", all)
+	}
+
+	rep, err := store.Get(ctx, "222222222222-us-east-1-run-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.AccountID != "222222222222" {
+		t.Errorf("unexpected report %+v", rep)
+	}
+	if _, err := store.Get(ctx, "run-missing"); err == nil {
+		t.Error("expected an error for an unknown run")
+	}
+}
+
+func TestItemRoundTrip(t *testing.T) {
+	e := Entry{
+		RunID:          "terminator-1717243200",
+		AccountID:      "123456789012",
+		Region:         "eu-west-1",
+		GeneratedAt:    time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		MonthlyNATCost: 250.5,
+		MonthlySavings: 99.25,
+		Host:           "laptop",
+	}
+	it := item(e)
+	if got := it[attrScope]; got == nil {
+		t.Fatal("expected a partition key")
+	}
+	if got := entry(it); got != e {
+		t.Errorf("round trip = %+v, want %+v", got, e)
+	}
+}
+
+func TestOpenNeedsBucketAndTable(t *testing.T) {
+	cfg := parseConfig("[history]\nbucket = \"finops-terminat\"\n")
+	if !cfg.Remote() {
+		t.Fatal("expected a bucket to select the shared history")
+	}
+	if _, err := Open(context.Background(), cfg); err == nil {
+		t.Error("expected an error without a table")
+	}
+}
@@ -0,0 +1,198 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/doitintl/terminator/internal/report"
+)
+
+// Attributes of the DynamoDB index. The table's partition key is scope
+// (<account>/<region>) and its sort key generated_at (RFC 3339), both
+// strings, so the newest scans of one environment are one Query away.
+const (
+	attrScope       = "scope"
+	attrGeneratedAt = "generated_at"
+	attrRunID       = "run_id"
+	attrAccountID   = "account_id"
+	attrRegion      = "region"
+	attrNATCost     = "monthly_nat_cost"
+	attrSavings     = "monthly_savings"
+	attrHost        = "host"
+)
+
+// sortKeyLayout is RFC 3339 with fixed-width nanoseconds, so sort keys
+// order as strings the way they do as times.
+const sortKeyLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// remoteStore keeps reports in S3 and indexes them in DynamoDB.
+type remoteStore struct {
+	cfg Config
+	s3  *s3.Client
+	ddb *dynamodb.Client
+}
+
+func newRemoteStore(ctx context.Context, cfg Config) (*remoteStore, error) {
+	var opts []func(*config.LoadOptions) error
+	if cfg.Region != "" {
+		opts = append(opts, config.WithRegion(cfg.Region))
+	}
+	if cfg.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(cfg.Profile))
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &remoteStore{cfg: cfg, s3: s3.NewFromConfig(awsCfg), ddb: dynamodb.NewFromConfig(awsCfg)}, nil
+}
+
+func (s *remoteStore) key(id string) string {
+	return s.cfg.Prefix + id + ".json"
+}
+
+// Location returns the bucket, prefix and table.
+func (s *remoteStore) Location() string {
+	return fmt.Sprintf("s3://%s/%s (index: %s)", s.cfg.Bucket, s.cfg.Prefix, s.cfg.Table)
+}
+
+// Put uploads the report before indexing it, so the index never points at
+// a missing object.
+func (s *remoteStore) Put(ctx context.Context, e Entry, rep *report.Report) error {
+	data, err := encode(rep)
+	if err != nil {
+		return err
+	}
+	_, err = s.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.cfg.Bucket),
+		Key:         aws.String(s.key(e.ID())),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload report to s3://%s/%s: %w", s.cfg.Bucket, s.key(e.ID()), err)
+	}
+	_, err = s.ddb.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.cfg.Table),
+		Item:      item(e),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index report in %s: %w", s.cfg.Table, err)
+	}
+	return nil
+}
+
+// List queries one account and region; the index is partitioned by both.
+func (s *remoteStore) List(ctx context.Context, accountID, region string, limit int) ([]Entry, error) {
+	if accountID == "" || region == "" {
+		return nil, fmt.Errorf("listing the shared history needs an account ID and a region")
+	}
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.cfg.Table),
+		KeyConditionExpression: aws.String("#scope = :scope"),
+		ExpressionAttributeNames: map[string]string{
+			"#scope": attrScope,
+		},
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":scope": &ddbtypes.AttributeValueMemberS{Value: scope(accountID, region)},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+	if limit > 0 {
+		input.Limit = aws.Int32(int32(limit))
+	}
+
+	var entries []Entry
+	paginator := dynamodb.NewQueryPaginator(s.ddb, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s: %w", s.cfg.Table, err)
+		}
+		for _, it := range page.Items {
+			entries = append(entries, entry(it))
+		}
+		if limit > 0 && len(entries) >= limit {
+			return entries[:limit], nil
+		}
+	}
+	return entries, nil
+}
+
+// Get downloads the report stored under id.
+func (s *remoteStore) Get(ctx context.Context, id string) (*report.Report, error) {
+	out, err := s.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(s.key(id)),
+	})
+	var noKey *s3types.NoSuchKey
+	if errors.As(err, &noKey) {
+		return nil, fmt.Errorf("no scan %s in s3://%s/%s", id, s.cfg.Bucket, s.cfg.Prefix)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download s3://%s/%s: %w", s.cfg.Bucket, s.key(id), err)
+	}
+	defer out.Body.Close()
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, err
+	}
+	return report.Parse(data, id)
+}
+
+func scope(accountID, region string) string {
+	return accountID + "/" + region
+}
+
+func item(e Entry) map[string]ddbtypes.AttributeValue {
+	it := map[string]ddbtypes.AttributeValue{
+		attrScope:       &ddbtypes.AttributeValueMemberS{Value: scope(e.AccountID, e.Region)},
+		attrGeneratedAt: &ddbtypes.AttributeValueMemberS{Value: e.GeneratedAt.UTC().Format(sortKeyLayout)},
+		attrRunID:       &ddbtypes.AttributeValueMemberS{Value: e.RunID},
+		attrAccountID:   &ddbtypes.AttributeValueMemberS{Value: e.AccountID},
+		attrRegion:      &ddbtypes.AttributeValueMemberS{Value: e.Region},
+		attrNATCost:     &ddbtypes.AttributeValueMemberN{Value: strconv.FormatFloat(e.MonthlyNATCost, 'f', 2, 64)},
+		attrSavings:     &ddbtypes.AttributeValueMemberN{Value: strconv.FormatFloat(e.MonthlySavings, 'f', 2, 64)},
+	}
+	if e.Host != "" {
+		it[attrHost] = &ddbtypes.AttributeValueMemberS{Value: e.Host}
+	}
+	return it
+}
+
+func entry(it map[string]ddbtypes.AttributeValue) Entry {
+	str := func(name string) string {
+		if v, ok := it[name].(*ddbtypes.AttributeValueMemberS); ok {
+			return v.Value
+		}
+		return ""
+	}
+	num := func(name string) float64 {
+		if v, ok := it[name].(*ddbtypes.AttributeValueMemberN); ok {
+			f, _ := strconv.ParseFloat(v.Value, 64)
+			return f
+		}
+		return 0
+	}
+	generatedAt, _ := time.Parse(sortKeyLayout, str(attrGeneratedAt))
+	return Entry{
+		RunID:          str(attrRunID),
+		AccountID:      str(attrAccountID),
+		Region:         str(attrRegion),
+		GeneratedAt:    generatedAt,
+		MonthlyNATCost: num(attrNATCost),
+		MonthlySavings: num(attrSavings),
+		Host:           str(attrHost),
+	}
+}
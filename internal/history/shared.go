@@ -0,0 +1,85 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+var (
+	// ErrNotFound is returned by Shared.Read for a file never written.
+	ErrNotFound = errors.New("not found in the shared history")
+	// ErrConflict is returned by Shared.Write when someone else wrote the
+	// file since it was read.
+	ErrConflict = errors.New("changed in the shared history since it was read")
+)
+
+// Shared reads and writes team-wide files, such as the findings baseline,
+// next to the reports of the shared history.
+type Shared interface {
+	// Read returns a file and a version tag for Write.
+	Read(ctx context.Context, name string) (data []byte, version string, err error)
+	// Write replaces a file if it is still at version, or creates it if
+	// version is empty and it doesn't exist yet.
+	Write(ctx context.Context, name string, data []byte, version string) error
+}
+
+// OpenShared returns the shared backend the [history] section configures.
+func OpenShared(ctx context.Context, cfg Config) (Shared, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("history: no shared backend configured; set bucket in the [history] section")
+	}
+	return newRemoteStore(ctx, cfg)
+}
+
+// Read downloads <prefix><name>; its ETag is the version.
+func (s *remoteStore) Read(ctx context.Context, name string) ([]byte, string, error) {
+	key := s.cfg.Prefix + name
+	out, err := s.s3.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.cfg.Bucket), Key: aws.String(key)})
+	var noKey *s3types.NoSuchKey
+	if errors.As(err, &noKey) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download s3://%s/%s: %w", s.cfg.Bucket, key, err)
+	}
+	defer out.Body.Close()
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, aws.ToString(out.ETag), nil
+}
+
+// Write uploads <prefix><name> with an S3 conditional write, so two
+// concurrent pushes can't silently overwrite each other.
+func (s *remoteStore) Write(ctx context.Context, name string, data []byte, version string) error {
+	key := s.cfg.Prefix + name
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.cfg.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	}
+	if version == "" {
+		input.IfNoneMatch = aws.String("*")
+	} else {
+		input.IfMatch = aws.String(version)
+	}
+	_, err := s.s3.PutObject(ctx, input)
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && (apiErr.ErrorCode() == "PreconditionFailed" || apiErr.ErrorCode() == "ConditionalRequestConflict") {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upload s3://%s/%s: %w", s.cfg.Bucket, key, err)
+	}
+	return nil
+}
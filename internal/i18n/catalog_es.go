@@ -0,0 +1,117 @@
+package i18n
+
+func init() {
+	register("es", map[string]string{
+		// Report layout
+		"termiNATor Deep Dive Report":                       "Informe de análisis detallado de termiNATor",
+		"**Generated:** %s":                                 "**Generado:** %s",
+		"**Region:** %s":                                    "**Región:** %s",
+		"**Account:** %s":                                   "**Cuenta:** %s",
+		"**Sample Duration:** %d minutes":                   "**Duración de la muestra:** %d minutos",
+		"**Collection Window:** %s":                         "**Ventana de recolección:** %s",
+		"**Duration Preset:** %s (expected accuracy: %s)":   "**Preajuste de duración:** %s (precisión esperada: %s)",
+		"**Log Group:** %s":                                 "**Grupo de logs:** %s",
+		"**VPC:** %s":                                       "**VPC:** %s",
+		"VPC %s":                                            "VPC %s",
+		"Executive Summary":                                 "Resumen ejecutivo",
+		"**Potential Monthly Savings: $%.2f** ($%.2f/year)": "**Ahorro mensual potencial: $%.2f** ($%.2f/año)",
+		"Estimates projected from traffic sample. Actual savings depend on real traffic patterns.": "Estimaciones proyectadas a partir de una muestra de tráfico. El ahorro real depende de los patrones de tráfico reales.",
+		"NAT Gateway Topology":                                "Topología de NAT Gateway",
+		"VPC Endpoint Configuration":                          "Configuración de endpoints de VPC",
+		"Gateway Endpoints":                                   "Endpoints de puerta de enlace",
+		"ECR Interface Endpoints (Paid)":                      "Endpoints de interfaz de ECR (de pago)",
+		"Missing Route Table Associations":                    "Asociaciones de tablas de rutas faltantes",
+		"Collected Traffic Sample":                            "Muestra de tráfico recopilada",
+		"Data Transfer vs API Calls":                          "Transferencia de datos frente a llamadas a la API",
+		"Classification Confidence":                           "Fiabilidad de la clasificación",
+		"Cost Estimate":                                       "Estimación de costes",
+		"Projected from %d-minute sample to monthly estimate": "Proyectado a un mes a partir de una muestra de %d minutos",
+		"Traffic by Availability Zone":                        "Tráfico por zona de disponibilidad",
+		"Public Registry Pulls":                               "Descargas de registros públicos",
+		"EKS Endpoint Bundle (%s)":                            "Paquete de endpoints para EKS (%s)",
+		"Interface Endpoint Break-Even":                       "Punto de equilibrio de los endpoints de interfaz",
+		"Public IPv4 Addresses":                               "Direcciones IPv4 públicas",
+		"DynamoDB Endpoints Resolved":                         "Endpoints de DynamoDB resueltos",
+		"S3 Traffic by Bucket":                                "Tráfico de S3 por bucket",
+		"Scan Coverage":                                       "Cobertura del análisis",
+		"What Gateway Endpoints Remove":                       "Qué eliminan los endpoints de gateway",
+		"Findings":                                            "Hallazgos",
+		"Recommendations":                                     "Recomendaciones",
+		"Remediation Plan":                                    "Plan de corrección",
+		"Remediation Steps":                                   "Pasos de corrección",
+		"Create Missing VPC Endpoints":                        "Crear los endpoints de VPC faltantes",
+		"Add Missing Route Table Associations":                "Añadir las asociaciones de tablas de rutas faltantes",
+		"Action: %s":                                          "Acción: %s",
+		"Impact: %s":                                          "Impacto: %s",
+		"Console: %s":                                         "Consola: %s",
+		"Savings: %s":                                         "Ahorro: %s",
+		"%d finding(s)/recommendation(s) projected to save less than $%.2f/month are hidden (`--min-savings`).": "Se ocultan %d hallazgo(s)/recomendación(es) con un ahorro proyectado inferior a $%.2f/mes (`--min-savings`).",
+
+		// Findings
+		"Missing S3 Gateway Endpoint":                                                                                               "Falta el endpoint de puerta de enlace de S3",
+		"VPC %s has NAT Gateway(s) but no S3 Gateway endpoint":                                                                      "La VPC %s tiene NAT Gateway pero no tiene endpoint de puerta de enlace de S3",
+		"Create S3 Gateway VPC endpoint and associate with private route tables":                                                    "Cree un endpoint de VPC de puerta de enlace de S3 y asócielo a las tablas de rutas privadas",
+		"All S3 traffic is going through NAT Gateway, incurring $0.045/GB data processing charges":                                  "Todo el tráfico de S3 pasa por el NAT Gateway, con cargos de procesamiento de datos de $0.045/GB",
+		"S3 Gateway Endpoint Missing Route Table Associations":                                                                      "Al endpoint de puerta de enlace de S3 le faltan asociaciones de tablas de rutas",
+		"VPC %s: S3 endpoint not associated with %d route table(s)":                                                                 "VPC %s: el endpoint de S3 no está asociado a %d tabla(s) de rutas",
+		"Associate S3 endpoint with: %s":                                                                                            "Asocie el endpoint de S3 con: %s",
+		"S3 traffic from some subnets still goes through NAT Gateway":                                                               "El tráfico de S3 de algunas subredes sigue pasando por el NAT Gateway",
+		"Missing DynamoDB Gateway Endpoint":                                                                                         "Falta el endpoint de puerta de enlace de DynamoDB",
+		"VPC %s has NAT Gateway(s) but no DynamoDB Gateway endpoint":                                                                "La VPC %s tiene NAT Gateway pero no tiene endpoint de puerta de enlace de DynamoDB",
+		"Create DynamoDB Gateway VPC endpoint and associate with private route tables":                                              "Cree un endpoint de VPC de puerta de enlace de DynamoDB y asócielo a las tablas de rutas privadas",
+		"All DynamoDB traffic is going through NAT Gateway, incurring $0.045/GB data processing charges":                            "Todo el tráfico de DynamoDB pasa por el NAT Gateway, con cargos de procesamiento de datos de $0.045/GB",
+		"DynamoDB Gateway Endpoint Missing Route Table Associations":                                                                "Al endpoint de puerta de enlace de DynamoDB le faltan asociaciones de tablas de rutas",
+		"VPC %s: DynamoDB endpoint not associated with %d route table(s)":                                                           "VPC %s: el endpoint de DynamoDB no está asociado a %d tabla(s) de rutas",
+		"Associate DynamoDB endpoint with: %s":                                                                                      "Asocie el endpoint de DynamoDB con: %s",
+		"DynamoDB traffic from some subnets still goes through NAT Gateway":                                                         "El tráfico de DynamoDB de algunas subredes sigue pasando por el NAT Gateway",
+		"%s interface endpoint would save ~$%.2f/month":                                                                             "El endpoint de interfaz de %s ahorraría ~$%.2f/mes",
+		"%s endpoints already exist":                                                                                                "Los endpoints de %s ya existen",
+		"%s endpoints pay off: %.1f GB/month is above the %.1f GB/month break-even (~$%.2f/month net)":                              "Los endpoints de %s son rentables: %.1f GB/mes supera el punto de equilibrio de %.1f GB/mes (~$%.2f/mes netos)",
+		"%s endpoints are not worth it for cost: %.1f GB/month is below the %.1f GB/month break-even (they would add ~$%.2f/month)": "Los endpoints de %s no compensan en coste: %.1f GB/mes está por debajo del punto de equilibrio de %.1f GB/mes (añadirían ~$%.2f/mes)",
+		"~$%.2f/month": "~$%.2f/mes",
+
+		// Recommendations
+		"Consider Regional NAT Gateway for VPC %s": "Considere un NAT Gateway regional para la VPC %s",
+		"You have %d zonal NAT Gateways in this VPC. AWS Regional NAT Gateway can simplify your architecture by replacing multiple zonal NAT Gateways with a single regional resource that automatically spans all Availability Zones.": "Tiene %d NAT Gateway zonales en esta VPC. El NAT Gateway regional de AWS puede simplificar su arquitectura al sustituir varios NAT Gateway zonales por un único recurso regional que abarca automáticamente todas las zonas de disponibilidad.",
+		"Eliminates cross-AZ data transfer costs ($0.01/GB) and simplifies operations": "Elimina los costes de transferencia de datos entre zonas ($0.01/GB) y simplifica la operación",
+		"Cache public container images with ECR pull-through cache":                    "Almacene en caché las imágenes públicas de contenedores con la caché de extracción de ECR",
+		"About %.1f GB/month of image pulls from %s go through the NAT Gateway ($%.2f/month). A pull-through cache serves them from ECR, reached over the ecr.api/ecr.dkr interface endpoints and the S3 gateway endpoint.": "Unos %.1f GB/mes de descargas de imágenes desde %s pasan por el NAT Gateway ($%.2f/mes). Una caché de extracción las sirve desde ECR, accesible mediante los endpoints de interfaz ecr.api/ecr.dkr y el endpoint de puerta de enlace de S3.",
+		"~$%.2f/month net of $%.2f/month endpoint hours and $%.2f/month endpoint data":                                                   "~$%.2f/mes netos, descontando $%.2f/mes de horas de endpoint y $%.2f/mes de datos de endpoint",
+		"Add the EKS endpoint bundle for %s":                                                                                             "Añada el paquete de endpoints para EKS de %s",
+		"EKS nodes pull images and call STS and CloudWatch Logs through the NAT Gateway (projected monthly: %s). Missing endpoints: %s.": "Los nodos de EKS descargan imágenes y llaman a STS y CloudWatch Logs a través del NAT Gateway (proyección mensual: %s). Endpoints faltantes: %s.",
+		"none": "ninguno",
+		"SSM endpoints for %d managed instance(s) in %s":                               "Endpoints de SSM para %d instancia(s) administrada(s) en %s",
+		"SSM Agent and Session Manager traffic (%s) goes through the NAT Gateway. %s.": "El tráfico de SSM Agent y Session Manager (%s) pasa por el NAT Gateway. %s.",
+		"~$%.2f/month net (break-even at %.1f GB/month)":                               "~$%.2f/mes netos (punto de equilibrio en %.1f GB/mes)",
+
+		// Billing context
+		"Billing Context": "Contexto de facturación",
+		"No NAT Gateway cost anomalies in the last %d days.":                                                             "No hubo anomalías de costes de NAT Gateway en los últimos %d días.",
+		"NAT Gateway cost anomalies in the last %d days (AWS Cost Anomaly Detection):":                                   "Anomalías de costes de NAT Gateway en los últimos %d días (AWS Cost Anomaly Detection):",
+		"The traffic sample was collected during a NAT Gateway cost anomaly; projections may not reflect usual traffic.": "La muestra de tráfico se recopiló durante una anomalía de costes de NAT Gateway; es posible que las proyecciones no reflejen el tráfico habitual.",
+
+		// Traffic by port
+		"Traffic by Port": "Tráfico por puerto",
+
+		// Rejected traffic
+		"Rejected Traffic": "Tráfico rechazado",
+		"Flows blocked by security groups or network ACLs during the sample: misconfigured rules, or callbacks that were rightly blocked.": "Flujos bloqueados por grupos de seguridad o ACL de red durante la muestra: reglas mal configuradas o conexiones salientes que se bloquearon con razón.",
+		"Top Rejected Destinations": "Principales destinos rechazados",
+		"Top Rejected Sources":      "Principales orígenes rechazados",
+
+		// Sample completeness
+		"Sample Completeness": "Completitud de la muestra",
+		"Complete":            "Completa",
+		"Projections scale the traffic that was captured; the gaps below mean the figures undercount.": "Las proyecciones escalan el tráfico capturado; las carencias indicadas a continuación significan que las cifras se quedan cortas.",
+
+		// Classifier cross-check
+		"Classifier Cross-Check": "Verificación cruzada del clasificador",
+
+		// Methodology appendix
+		"Appendix: Methodology": "Apéndice: metodología",
+		"Projection":            "Proyección",
+		"Prices":                "Precios",
+		"AWS IP Ranges":         "Rangos de IP de AWS",
+		"Queries":               "Consultas",
+	})
+}
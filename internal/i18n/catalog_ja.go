@@ -0,0 +1,117 @@
+package i18n
+
+func init() {
+	register("ja", map[string]string{
+		// Report layout
+		"termiNATor Deep Dive Report":                       "termiNATor 詳細分析レポート",
+		"**Generated:** %s":                                 "**作成日時:** %s",
+		"**Region:** %s":                                    "**リージョン:** %s",
+		"**Account:** %s":                                   "**アカウント:** %s",
+		"**Sample Duration:** %d minutes":                   "**サンプル期間:** %d 分",
+		"**Collection Window:** %s":                         "**収集期間:** %s",
+		"**Duration Preset:** %s (expected accuracy: %s)":   "**期間プリセット:** %s (想定精度: %s)",
+		"**Log Group:** %s":                                 "**ロググループ:** %s",
+		"**VPC:** %s":                                       "**VPC:** %s",
+		"VPC %s":                                            "VPC %s",
+		"Executive Summary":                                 "エグゼクティブサマリー",
+		"**Potential Monthly Savings: $%.2f** ($%.2f/year)": "**月間削減見込み額: $%.2f** (年間 $%.2f)",
+		"Estimates projected from traffic sample. Actual savings depend on real traffic patterns.": "トラフィックのサンプルから推計した見積もりです。実際の削減額は実際のトラフィック傾向によって異なります。",
+		"NAT Gateway Topology":                                "NAT Gateway の構成",
+		"VPC Endpoint Configuration":                          "VPC エンドポイントの設定",
+		"Gateway Endpoints":                                   "ゲートウェイエンドポイント",
+		"ECR Interface Endpoints (Paid)":                      "ECR インターフェイスエンドポイント (有料)",
+		"Missing Route Table Associations":                    "不足しているルートテーブルの関連付け",
+		"Collected Traffic Sample":                            "収集したトラフィックサンプル",
+		"Data Transfer vs API Calls":                          "データ転送と API 呼び出しの内訳",
+		"Classification Confidence":                           "分類の信頼度",
+		"Cost Estimate":                                       "コスト見積もり",
+		"Projected from %d-minute sample to monthly estimate": "%d 分間のサンプルから月額を推計",
+		"Traffic by Availability Zone":                        "アベイラビリティーゾーン別トラフィック",
+		"Public Registry Pulls":                               "パブリックレジストリからのプル",
+		"EKS Endpoint Bundle (%s)":                            "EKS 向けエンドポイント一式 (%s)",
+		"Interface Endpoint Break-Even":                       "インターフェイスエンドポイントの損益分岐点",
+		"Public IPv4 Addresses":                               "パブリック IPv4 アドレス",
+		"DynamoDB Endpoints Resolved":                         "名前解決された DynamoDB エンドポイント",
+		"S3 Traffic by Bucket":                                "バケット別 S3 トラフィック",
+		"Scan Coverage":                                       "スキャン範囲",
+		"What Gateway Endpoints Remove":                       "ゲートウェイエンドポイントで削減される料金",
+		"Findings":                                            "検出事項",
+		"Recommendations":                                     "推奨事項",
+		"Remediation Plan":                                    "対応計画",
+		"Remediation Steps":                                   "対応手順",
+		"Create Missing VPC Endpoints":                        "不足している VPC エンドポイントの作成",
+		"Add Missing Route Table Associations":                "不足しているルートテーブルの関連付けの追加",
+		"Action: %s":                                          "対応: %s",
+		"Impact: %s":                                          "影響: %s",
+		"Console: %s":                                         "コンソール: %s",
+		"Savings: %s":                                         "削減額: %s",
+		"%d finding(s)/recommendation(s) projected to save less than $%.2f/month are hidden (`--min-savings`).": "削減見込みが月 $%.2[2]f 未満の検出事項・推奨事項 %[1]d 件を非表示にしています (`--min-savings`)。",
+
+		// Findings
+		"Missing S3 Gateway Endpoint":                                                                                               "S3 ゲートウェイエンドポイントがありません",
+		"VPC %s has NAT Gateway(s) but no S3 Gateway endpoint":                                                                      "VPC %s には NAT Gateway がありますが、S3 ゲートウェイエンドポイントがありません",
+		"Create S3 Gateway VPC endpoint and associate with private route tables":                                                    "S3 ゲートウェイ VPC エンドポイントを作成し、プライベートルートテーブルに関連付けてください",
+		"All S3 traffic is going through NAT Gateway, incurring $0.045/GB data processing charges":                                  "S3 トラフィックはすべて NAT Gateway を経由しており、$0.045/GB のデータ処理料金が発生しています",
+		"S3 Gateway Endpoint Missing Route Table Associations":                                                                      "S3 ゲートウェイエンドポイントのルートテーブル関連付けが不足しています",
+		"VPC %s: S3 endpoint not associated with %d route table(s)":                                                                 "VPC %s: S3 エンドポイントが %d 個のルートテーブルに関連付けられていません",
+		"Associate S3 endpoint with: %s":                                                                                            "S3 エンドポイントを次に関連付けてください: %s",
+		"S3 traffic from some subnets still goes through NAT Gateway":                                                               "一部のサブネットの S3 トラフィックはまだ NAT Gateway を経由しています",
+		"Missing DynamoDB Gateway Endpoint":                                                                                         "DynamoDB ゲートウェイエンドポイントがありません",
+		"VPC %s has NAT Gateway(s) but no DynamoDB Gateway endpoint":                                                                "VPC %s には NAT Gateway がありますが、DynamoDB ゲートウェイエンドポイントがありません",
+		"Create DynamoDB Gateway VPC endpoint and associate with private route tables":                                              "DynamoDB ゲートウェイ VPC エンドポイントを作成し、プライベートルートテーブルに関連付けてください",
+		"All DynamoDB traffic is going through NAT Gateway, incurring $0.045/GB data processing charges":                            "DynamoDB トラフィックはすべて NAT Gateway を経由しており、$0.045/GB のデータ処理料金が発生しています",
+		"DynamoDB Gateway Endpoint Missing Route Table Associations":                                                                "DynamoDB ゲートウェイエンドポイントのルートテーブル関連付けが不足しています",
+		"VPC %s: DynamoDB endpoint not associated with %d route table(s)":                                                           "VPC %s: DynamoDB エンドポイントが %d 個のルートテーブルに関連付けられていません",
+		"Associate DynamoDB endpoint with: %s":                                                                                      "DynamoDB エンドポイントを次に関連付けてください: %s",
+		"DynamoDB traffic from some subnets still goes through NAT Gateway":                                                         "一部のサブネットの DynamoDB トラフィックはまだ NAT Gateway を経由しています",
+		"%s interface endpoint would save ~$%.2f/month":                                                                             "%s インターフェイスエンドポイントで月 ~$%.2f 削減できます",
+		"%s endpoints already exist":                                                                                                "%s エンドポイントは作成済みです",
+		"%s endpoints pay off: %.1f GB/month is above the %.1f GB/month break-even (~$%.2f/month net)":                              "%s エンドポイントは採算が取れます: 月 %.1f GB は損益分岐点の月 %.1f GB を上回ります (正味 月 ~$%.2f)",
+		"%s endpoints are not worth it for cost: %.1f GB/month is below the %.1f GB/month break-even (they would add ~$%.2f/month)": "%s エンドポイントはコスト面では見合いません: 月 %.1f GB は損益分岐点の月 %.1f GB を下回ります (月 ~$%.2f の増加)",
+		"~$%.2f/month": "月 ~$%.2f",
+
+		// Recommendations
+		"Consider Regional NAT Gateway for VPC %s": "VPC %s ではリージョナル NAT Gateway を検討してください",
+		"You have %d zonal NAT Gateways in this VPC. AWS Regional NAT Gateway can simplify your architecture by replacing multiple zonal NAT Gateways with a single regional resource that automatically spans all Availability Zones.": "この VPC にはゾーン NAT Gateway が %d 個あります。AWS のリージョナル NAT Gateway を使うと、複数のゾーン NAT Gateway を、すべてのアベイラビリティーゾーンに自動的に展開される単一のリージョナルリソースに置き換え、構成を簡素化できます。",
+		"Eliminates cross-AZ data transfer costs ($0.01/GB) and simplifies operations": "AZ 間のデータ転送料金 ($0.01/GB) がなくなり、運用も簡素化されます",
+		"Cache public container images with ECR pull-through cache":                    "ECR プルスルーキャッシュでパブリックコンテナイメージをキャッシュする",
+		"About %.1f GB/month of image pulls from %s go through the NAT Gateway ($%.2f/month). A pull-through cache serves them from ECR, reached over the ecr.api/ecr.dkr interface endpoints and the S3 gateway endpoint.": "%[2]s からのイメージのプル 月約 %.1[1]f GB が NAT Gateway を経由しています (月 $%.2[3]f)。プルスルーキャッシュを使うと、ecr.api/ecr.dkr インターフェイスエンドポイントと S3 ゲートウェイエンドポイント経由で ECR から配信されます。",
+		"~$%.2f/month net of $%.2f/month endpoint hours and $%.2f/month endpoint data":                                                   "正味 月 ~$%.2f (エンドポイント時間料金 月 $%.2f とデータ料金 月 $%.2f を差し引き後)",
+		"Add the EKS endpoint bundle for %s":                                                                                             "%s に EKS 向けエンドポイント一式を追加する",
+		"EKS nodes pull images and call STS and CloudWatch Logs through the NAT Gateway (projected monthly: %s). Missing endpoints: %s.": "EKS ノードは NAT Gateway 経由でイメージをプルし、STS と CloudWatch Logs を呼び出しています (月間推計: %s)。不足しているエンドポイント: %s。",
+		"none": "なし",
+		"SSM endpoints for %d managed instance(s) in %s":                               "%[2]s のマネージドインスタンス %[1]d 台向けの SSM エンドポイント",
+		"SSM Agent and Session Manager traffic (%s) goes through the NAT Gateway. %s.": "SSM Agent と Session Manager のトラフィック (%s) が NAT Gateway を経由しています。%s。",
+		"~$%.2f/month net (break-even at %.1f GB/month)":                               "正味 月 ~$%.2f (損益分岐点 月 %.1f GB)",
+
+		// Billing context
+		"Billing Context": "請求のコンテキスト",
+		"No NAT Gateway cost anomalies in the last %d days.":                                                             "過去 %d 日間に NAT Gateway のコスト異常はありません。",
+		"NAT Gateway cost anomalies in the last %d days (AWS Cost Anomaly Detection):":                                   "過去 %d 日間の NAT Gateway のコスト異常 (AWS Cost Anomaly Detection):",
+		"The traffic sample was collected during a NAT Gateway cost anomaly; projections may not reflect usual traffic.": "トラフィックサンプルは NAT Gateway のコスト異常の発生中に収集されました。予測は通常のトラフィックを反映していない可能性があります。",
+
+		// Traffic by port
+		"Traffic by Port": "ポート別トラフィック",
+
+		// Rejected traffic
+		"Rejected Traffic": "拒否されたトラフィック",
+		"Flows blocked by security groups or network ACLs during the sample: misconfigured rules, or callbacks that were rightly blocked.": "サンプル期間中にセキュリティグループまたはネットワーク ACL によってブロックされたフロー: 設定ミスのルール、または正しくブロックされたコールバックです。",
+		"Top Rejected Destinations": "拒否された主な宛先",
+		"Top Rejected Sources":      "拒否された主な送信元",
+
+		// Sample completeness
+		"Sample Completeness": "サンプルの完全性",
+		"Complete":            "完全",
+		"Projections scale the traffic that was captured; the gaps below mean the figures undercount.": "予測は取得できたトラフィックを基に算出されます。以下の欠落は数値が過小であることを意味します。",
+
+		// Classifier cross-check
+		"Classifier Cross-Check": "分類のクロスチェック",
+
+		// Methodology appendix
+		"Appendix: Methodology": "付録: 算出方法",
+		"Projection":            "予測",
+		"Prices":                "料金",
+		"AWS IP Ranges":         "AWS の IP 範囲",
+		"Queries":               "クエリ",
+	})
+}
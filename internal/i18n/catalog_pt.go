@@ -0,0 +1,117 @@
+package i18n
+
+func init() {
+	register("pt", map[string]string{
+		// Report layout
+		"termiNATor Deep Dive Report":                       "Relatório de análise detalhada do termiNATor",
+		"**Generated:** %s":                                 "**Gerado em:** %s",
+		"**Region:** %s":                                    "**Região:** %s",
+		"**Account:** %s":                                   "**Conta:** %s",
+		"**Sample Duration:** %d minutes":                   "**Duração da amostra:** %d minutos",
+		"**Collection Window:** %s":                         "**Janela de coleta:** %s",
+		"**Duration Preset:** %s (expected accuracy: %s)":   "**Predefinição de duração:** %s (precisão esperada: %s)",
+		"**Log Group:** %s":                                 "**Grupo de logs:** %s",
+		"**VPC:** %s":                                       "**VPC:** %s",
+		"VPC %s":                                            "VPC %s",
+		"Executive Summary":                                 "Resumo executivo",
+		"**Potential Monthly Savings: $%.2f** ($%.2f/year)": "**Economia mensal potencial: $%.2f** ($%.2f/ano)",
+		"Estimates projected from traffic sample. Actual savings depend on real traffic patterns.": "Estimativas projetadas a partir de uma amostra de tráfego. A economia real depende dos padrões reais de tráfego.",
+		"NAT Gateway Topology":                                "Topologia de NAT Gateway",
+		"VPC Endpoint Configuration":                          "Configuração de endpoints de VPC",
+		"Gateway Endpoints":                                   "Endpoints de gateway",
+		"ECR Interface Endpoints (Paid)":                      "Endpoints de interface do ECR (pagos)",
+		"Missing Route Table Associations":                    "Associações de tabelas de rotas ausentes",
+		"Collected Traffic Sample":                            "Amostra de tráfego coletada",
+		"Data Transfer vs API Calls":                          "Transferência de dados x chamadas de API",
+		"Classification Confidence":                           "Confiabilidade da classificação",
+		"Cost Estimate":                                       "Estimativa de custos",
+		"Projected from %d-minute sample to monthly estimate": "Projetado para um mês a partir de uma amostra de %d minutos",
+		"Traffic by Availability Zone":                        "Tráfego por zona de disponibilidade",
+		"Public Registry Pulls":                               "Downloads de registros públicos",
+		"EKS Endpoint Bundle (%s)":                            "Pacote de endpoints para EKS (%s)",
+		"Interface Endpoint Break-Even":                       "Ponto de equilíbrio dos endpoints de interface",
+		"Public IPv4 Addresses":                               "Endereços IPv4 públicos",
+		"DynamoDB Endpoints Resolved":                         "Endpoints do DynamoDB resolvidos",
+		"S3 Traffic by Bucket":                                "Tráfego do S3 por bucket",
+		"Scan Coverage":                                       "Cobertura da análise",
+		"What Gateway Endpoints Remove":                       "O que os endpoints de gateway eliminam",
+		"Findings":                                            "Constatações",
+		"Recommendations":                                     "Recomendações",
+		"Remediation Plan":                                    "Plano de correção",
+		"Remediation Steps":                                   "Etapas de correção",
+		"Create Missing VPC Endpoints":                        "Criar os endpoints de VPC ausentes",
+		"Add Missing Route Table Associations":                "Adicionar as associações de tabelas de rotas ausentes",
+		"Action: %s":                                          "Ação: %s",
+		"Impact: %s":                                          "Impacto: %s",
+		"Console: %s":                                         "Console: %s",
+		"Savings: %s":                                         "Economia: %s",
+		"%d finding(s)/recommendation(s) projected to save less than $%.2f/month are hidden (`--min-savings`).": "%d constatação(ões)/recomendação(ões) com economia projetada inferior a $%.2f/mês estão ocultas (`--min-savings`).",
+
+		// Findings
+		"Missing S3 Gateway Endpoint":                                                                                               "Endpoint de gateway do S3 ausente",
+		"VPC %s has NAT Gateway(s) but no S3 Gateway endpoint":                                                                      "A VPC %s tem NAT Gateway, mas não tem endpoint de gateway do S3",
+		"Create S3 Gateway VPC endpoint and associate with private route tables":                                                    "Crie um endpoint de VPC de gateway do S3 e associe-o às tabelas de rotas privadas",
+		"All S3 traffic is going through NAT Gateway, incurring $0.045/GB data processing charges":                                  "Todo o tráfego do S3 passa pelo NAT Gateway, gerando cobranças de processamento de dados de $0.045/GB",
+		"S3 Gateway Endpoint Missing Route Table Associations":                                                                      "Faltam associações de tabelas de rotas no endpoint de gateway do S3",
+		"VPC %s: S3 endpoint not associated with %d route table(s)":                                                                 "VPC %s: o endpoint do S3 não está associado a %d tabela(s) de rotas",
+		"Associate S3 endpoint with: %s":                                                                                            "Associe o endpoint do S3 a: %s",
+		"S3 traffic from some subnets still goes through NAT Gateway":                                                               "O tráfego do S3 de algumas sub-redes ainda passa pelo NAT Gateway",
+		"Missing DynamoDB Gateway Endpoint":                                                                                         "Endpoint de gateway do DynamoDB ausente",
+		"VPC %s has NAT Gateway(s) but no DynamoDB Gateway endpoint":                                                                "A VPC %s tem NAT Gateway, mas não tem endpoint de gateway do DynamoDB",
+		"Create DynamoDB Gateway VPC endpoint and associate with private route tables":                                              "Crie um endpoint de VPC de gateway do DynamoDB e associe-o às tabelas de rotas privadas",
+		"All DynamoDB traffic is going through NAT Gateway, incurring $0.045/GB data processing charges":                            "Todo o tráfego do DynamoDB passa pelo NAT Gateway, gerando cobranças de processamento de dados de $0.045/GB",
+		"DynamoDB Gateway Endpoint Missing Route Table Associations":                                                                "Faltam associações de tabelas de rotas no endpoint de gateway do DynamoDB",
+		"VPC %s: DynamoDB endpoint not associated with %d route table(s)":                                                           "VPC %s: o endpoint do DynamoDB não está associado a %d tabela(s) de rotas",
+		"Associate DynamoDB endpoint with: %s":                                                                                      "Associe o endpoint do DynamoDB a: %s",
+		"DynamoDB traffic from some subnets still goes through NAT Gateway":                                                         "O tráfego do DynamoDB de algumas sub-redes ainda passa pelo NAT Gateway",
+		"%s interface endpoint would save ~$%.2f/month":                                                                             "O endpoint de interface de %s economizaria ~$%.2f/mês",
+		"%s endpoints already exist":                                                                                                "Os endpoints de %s já existem",
+		"%s endpoints pay off: %.1f GB/month is above the %.1f GB/month break-even (~$%.2f/month net)":                              "Os endpoints de %s compensam: %.1f GB/mês está acima do ponto de equilíbrio de %.1f GB/mês (~$%.2f/mês líquidos)",
+		"%s endpoints are not worth it for cost: %.1f GB/month is below the %.1f GB/month break-even (they would add ~$%.2f/month)": "Os endpoints de %s não compensam em custo: %.1f GB/mês está abaixo do ponto de equilíbrio de %.1f GB/mês (acrescentariam ~$%.2f/mês)",
+		"~$%.2f/month": "~$%.2f/mês",
+
+		// Recommendations
+		"Consider Regional NAT Gateway for VPC %s": "Considere um NAT Gateway regional para a VPC %s",
+		"You have %d zonal NAT Gateways in this VPC. AWS Regional NAT Gateway can simplify your architecture by replacing multiple zonal NAT Gateways with a single regional resource that automatically spans all Availability Zones.": "Você tem %d NAT Gateways zonais nesta VPC. O NAT Gateway regional da AWS pode simplificar sua arquitetura substituindo vários NAT Gateways zonais por um único recurso regional que abrange automaticamente todas as zonas de disponibilidade.",
+		"Eliminates cross-AZ data transfer costs ($0.01/GB) and simplifies operations": "Elimina os custos de transferência de dados entre zonas ($0.01/GB) e simplifica a operação",
+		"Cache public container images with ECR pull-through cache":                    "Armazene em cache as imagens públicas de contêiner com o cache pull-through do ECR",
+		"About %.1f GB/month of image pulls from %s go through the NAT Gateway ($%.2f/month). A pull-through cache serves them from ECR, reached over the ecr.api/ecr.dkr interface endpoints and the S3 gateway endpoint.": "Cerca de %.1f GB/mês de downloads de imagens de %s passam pelo NAT Gateway ($%.2f/mês). Um cache pull-through as entrega a partir do ECR, acessado pelos endpoints de interface ecr.api/ecr.dkr e pelo endpoint de gateway do S3.",
+		"~$%.2f/month net of $%.2f/month endpoint hours and $%.2f/month endpoint data":                                                   "~$%.2f/mês líquidos, descontados $%.2f/mês de horas de endpoint e $%.2f/mês de dados de endpoint",
+		"Add the EKS endpoint bundle for %s":                                                                                             "Adicione o pacote de endpoints para EKS de %s",
+		"EKS nodes pull images and call STS and CloudWatch Logs through the NAT Gateway (projected monthly: %s). Missing endpoints: %s.": "Os nós do EKS baixam imagens e chamam o STS e o CloudWatch Logs pelo NAT Gateway (projeção mensal: %s). Endpoints ausentes: %s.",
+		"none": "nenhum",
+		"SSM endpoints for %d managed instance(s) in %s":                               "Endpoints do SSM para %d instância(s) gerenciada(s) em %s",
+		"SSM Agent and Session Manager traffic (%s) goes through the NAT Gateway. %s.": "O tráfego do SSM Agent e do Session Manager (%s) passa pelo NAT Gateway. %s.",
+		"~$%.2f/month net (break-even at %.1f GB/month)":                               "~$%.2f/mês líquidos (ponto de equilíbrio em %.1f GB/mês)",
+
+		// Billing context
+		"Billing Context": "Contexto de faturamento",
+		"No NAT Gateway cost anomalies in the last %d days.":                                                             "Nenhuma anomalia de custo do NAT Gateway nos últimos %d dias.",
+		"NAT Gateway cost anomalies in the last %d days (AWS Cost Anomaly Detection):":                                   "Anomalias de custo do NAT Gateway nos últimos %d dias (AWS Cost Anomaly Detection):",
+		"The traffic sample was collected during a NAT Gateway cost anomaly; projections may not reflect usual traffic.": "A amostra de tráfego foi coletada durante uma anomalia de custo do NAT Gateway; as projeções podem não refletir o tráfego habitual.",
+
+		// Traffic by port
+		"Traffic by Port": "Tráfego por porta",
+
+		// Rejected traffic
+		"Rejected Traffic": "Tráfego rejeitado",
+		"Flows blocked by security groups or network ACLs during the sample: misconfigured rules, or callbacks that were rightly blocked.": "Fluxos bloqueados por grupos de segurança ou ACLs de rede durante a amostra: regras mal configuradas ou conexões de saída bloqueadas com razão.",
+		"Top Rejected Destinations": "Principais destinos rejeitados",
+		"Top Rejected Sources":      "Principais origens rejeitadas",
+
+		// Sample completeness
+		"Sample Completeness": "Completude da amostra",
+		"Complete":            "Completa",
+		"Projections scale the traffic that was captured; the gaps below mean the figures undercount.": "As projeções escalam o tráfego capturado; as lacunas abaixo significam que os números ficam abaixo do real.",
+
+		// Classifier cross-check
+		"Classifier Cross-Check": "Verificação cruzada do classificador",
+
+		// Methodology appendix
+		"Appendix: Methodology": "Apêndice: metodologia",
+		"Projection":            "Projeção",
+		"Prices":                "Preços",
+		"AWS IP Ranges":         "Intervalos de IP da AWS",
+		"Queries":               "Consultas",
+	})
+}
@@ -0,0 +1,198 @@
+// Package i18n translates the text of exported reports.
+//
+// Catalogs are keyed by the English text, or by the English format string for
+// text that carries values. Already-rendered findings and recommendations are
+// translated by matching them against those format strings, so analysis code
+// keeps producing plain English and needs no message IDs.
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// English is the source language; it needs no catalog.
+const English = "en"
+
+// catalogs maps a base language to its English-keyed translations.
+var catalogs = map[string]map[string]string{}
+
+func register(lang string, entries map[string]string) {
+	catalogs[lang] = entries
+}
+
+// Languages lists the supported report languages.
+func Languages() []string {
+	langs := []string{English}
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// Translator renders report text in one language. A nil Translator, like
+// the English one, returns text unchanged.
+type Translator struct {
+	lang    string
+	exact   map[string]string
+	formats []formatEntry
+}
+
+type formatEntry struct {
+	pattern     *regexp.Regexp
+	verbs       []byte // verb letter per capture group
+	translation string // with every verb rewritten to an indexed %s
+}
+
+// New returns a Translator for a language tag such as "es", "pt-BR" or "ja".
+// An empty tag means English.
+func New(lang string) (*Translator, error) {
+	lang = strings.TrimSpace(lang)
+	if lang == "" {
+		return &Translator{lang: English}, nil
+	}
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report language %q (valid: %s)", lang, strings.Join(Languages(), ", "))
+	}
+	base, _ := tag.Base()
+	if base.String() == English {
+		return &Translator{lang: English}, nil
+	}
+	entries, ok := catalogs[base.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported report language %q (valid: %s)", lang, strings.Join(Languages(), ", "))
+	}
+
+	t := &Translator{lang: base.String(), exact: entries}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		if hasVerbs(key) {
+			keys = append(keys, key)
+		}
+	}
+	// Longer formats are more specific; try them first
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		pattern, verbs := compileFormat(key)
+		t.formats = append(t.formats, formatEntry{pattern: pattern, verbs: verbs, translation: indexVerbs(entries[key])})
+	}
+	return t, nil
+}
+
+// Lang is the base language code, e.g. "es".
+func (t *Translator) Lang() string {
+	if t == nil {
+		return English
+	}
+	return t.lang
+}
+
+// Sprintf formats with the translated format string.
+func (t *Translator) Sprintf(format string, args ...any) string {
+	if t != nil {
+		if translated, ok := t.exact[format]; ok {
+			format = translated
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Text translates rendered English text. Text matching a catalogued format
+// string is rebuilt from the translated format with its values, which are
+// themselves translated when they are catalogued text. Unknown text is
+// returned unchanged.
+func (t *Translator) Text(s string) string {
+	if t == nil || len(t.exact) == 0 || s == "" {
+		return s
+	}
+	if translated, ok := t.exact[s]; ok && !hasVerbs(s) {
+		return translated
+	}
+	for _, f := range t.formats {
+		m := f.pattern.FindStringSubmatch(s)
+		if m == nil {
+			continue
+		}
+		args := make([]any, len(m)-1)
+		for i, v := range m[1:] {
+			if f.verbs[i] == 's' {
+				v = t.Text(v)
+			}
+			args[i] = v
+		}
+		return fmt.Sprintf(f.translation, args...)
+	}
+	return s
+}
+
+// verbRe matches the fmt verbs used in catalog keys and translations.
+var verbRe = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?(\[\d+\])?[a-zA-Z%]`)
+
+var verbIndexRe = regexp.MustCompile(`\[(\d+)\]`)
+
+func hasVerbs(s string) bool {
+	for _, v := range verbRe.FindAllString(s, -1) {
+		if v != "%%" {
+			return true
+		}
+	}
+	return false
+}
+
+// compileFormat turns an English format string into an anchored pattern
+// with one capture group per verb.
+func compileFormat(format string) (*regexp.Regexp, []byte) {
+	var b strings.Builder
+	var verbs []byte
+	last := 0
+	b.WriteString("^")
+	for _, loc := range verbRe.FindAllStringIndex(format, -1) {
+		b.WriteString(regexp.QuoteMeta(format[last:loc[0]]))
+		verb := format[loc[0]:loc[1]]
+		last = loc[1]
+		switch verb[len(verb)-1] {
+		case '%':
+			b.WriteString("%")
+			continue
+		case 'd':
+			b.WriteString(`(-?[\d,]+)`)
+		case 'f', 'g', 'e':
+			b.WriteString(`(-?[\d,]*\.?\d+)`)
+		default:
+			b.WriteString(`(.+?)`)
+		}
+		verbs = append(verbs, verb[len(verb)-1])
+	}
+	b.WriteString(regexp.QuoteMeta(format[last:]))
+	b.WriteString("$")
+	return regexp.MustCompile(b.String()), verbs
+}
+
+// indexVerbs rewrites each verb of a translation as an explicitly indexed
+// %s, so captured text is reinserted as-is and translations may reorder values.
+func indexVerbs(translation string) string {
+	next := 1
+	return verbRe.ReplaceAllStringFunc(translation, func(verb string) string {
+		if verb == "%%" {
+			return verb
+		}
+		idx := next
+		if m := verbIndexRe.FindStringSubmatch(verb); m != nil {
+			idx, _ = strconv.Atoi(m[1])
+		}
+		next = idx + 1
+		return fmt.Sprintf("%%[%d]s", idx)
+	})
+}
@@ -0,0 +1,110 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// verbTypes lists the verb letter for each argument index a format uses.
+func verbTypes(t *testing.T, format string) map[int]byte {
+	t.Helper()
+	types := map[int]byte{}
+	next := 1
+	for _, verb := range verbRe.FindAllString(format, -1) {
+		if verb == "%%" {
+			continue
+		}
+		idx := next
+		if m := verbIndexRe.FindStringSubmatch(verb); m != nil {
+			idx, _ = strconv.Atoi(m[1])
+		}
+		next = idx + 1
+		types[idx] = verb[len(verb)-1]
+	}
+	return types
+}
+
+func TestCatalogsMatchEnglishKeysAndVerbs(t *testing.T) {
+	var reference []string
+	for key := range catalogs["es"] {
+		reference = append(reference, key)
+	}
+	sort.Strings(reference)
+
+	for lang, entries := range catalogs {
+		if len(entries) != len(reference) {
+			t.Errorf("%s catalog has %d entries, es has %d", lang, len(entries), len(reference))
+		}
+		for _, key := range reference {
+			translation, ok := entries[key]
+			if !ok {
+				t.Errorf("%s catalog is missing %q", lang, key)
+				continue
+			}
+			want, got := verbTypes(t, key), verbTypes(t, translation)
+			if len(want) != len(got) {
+				t.Errorf("%s %q: translation uses %d values, English uses %d", lang, key, len(got), len(want))
+				continue
+			}
+			for idx, verb := range want {
+				if got[idx] != verb {
+					t.Errorf("%s %q: value %d is %%%c in English but %%%c in the translation", lang, key, idx, verb, got[idx])
+				}
+			}
+		}
+	}
+}
+
+func TestNewMatchesLanguageTags(t *testing.T) {
+	for tag, want := range map[string]string{"": "en", "en-GB": "en", "es": "es", "es-419": "es", "pt-BR": "pt", "ja": "ja"} {
+		tr, err := New(tag)
+		if err != nil {
+			t.Fatalf("New(%q): %v", tag, err)
+		}
+		if tr.Lang() != want {
+			t.Errorf("New(%q).Lang() = %s, want %s", tag, tr.Lang(), want)
+		}
+	}
+	if _, err := New("fr"); err == nil {
+		t.Error("expected an error for a language without a catalog")
+	}
+}
+
+func TestText(t *testing.T) {
+	es, _ := New("es")
+	ja, _ := New("ja")
+
+	if got := es.Text("Recommendations"); got != "Recomendaciones" {
+		t.Errorf("exact text: got %q", got)
+	}
+	if got := es.Text("VPC vpc-123 has NAT Gateway(s) but no S3 Gateway endpoint"); got != "La VPC vpc-123 tiene NAT Gateway pero no tiene endpoint de puerta de enlace de S3" {
+		t.Errorf("formatted text: got %q", got)
+	}
+	if got := ja.Text("SSM endpoints for 4 managed instance(s) in vpc-1"); got != "vpc-1 のマネージドインスタンス 4 台向けの SSM エンドポイント" {
+		t.Errorf("reordered values: got %q", got)
+	}
+
+	nested := "SSM Agent and Session Manager traffic (ssm, ec2messages) goes through the NAT Gateway. SSM endpoints already exist."
+	if got := es.Text(nested); got != "El tráfico de SSM Agent y Session Manager (ssm, ec2messages) pasa por el NAT Gateway. Los endpoints de SSM ya existen." {
+		t.Errorf("nested text: got %q", got)
+	}
+
+	for _, s := range []string{"not in any catalog, 40% of it", ""} {
+		if got := es.Text(s); got != s {
+			t.Errorf("unknown text should pass through, got %q", got)
+		}
+	}
+	var none *Translator
+	if got := none.Text("Recommendations"); got != "Recommendations" {
+		t.Errorf("nil translator should be English, got %q", got)
+	}
+}
+
+func TestSprintf(t *testing.T) {
+	ja, _ := New("ja")
+	got := ja.Sprintf("%d finding(s)/recommendation(s) projected to save less than $%.2f/month are hidden (`--min-savings`).", 3, 5.0)
+	if got != "削減見込みが月 $5.00 未満の検出事項・推奨事項 3 件を非表示にしています (`--min-savings`)。" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,224 @@
+package iampolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/naming"
+)
+
+// Modes lists the supported --mode values in display order.
+var Modes = []string{"quick", "read-only", "deep", "deep-cloudformation", "apply", "verify", "backfill", "savings", "anomalies"}
+
+// FlowLogsRoleName is the delivery role deep scans pass to VPC Flow Logs.
+const FlowLogsRoleName = "termiNATor-FlowLogsRole"
+
+// CleanupSchedulerRoleName is the role EventBridge Scheduler assumes to delete
+// kept log groups (scan deep --expire-kept-log-group).
+const CleanupSchedulerRoleName = "termiNATor-CleanupSchedulerRole"
+
+// LogGroupPrefix is the prefix of every log group created by a deep scan
+// with the default naming prefix.
+var LogGroupPrefix = naming.LogGroupName(naming.DefaultPrefix)
+
+type Statement struct {
+	Sid      string   `json:"Sid"`
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource []string `json:"Resource"`
+}
+
+type Document struct {
+	Version   string      `json:"Version"`
+	Statement []Statement `json:"Statement"`
+}
+
+// Keep these lists in sync with the calls made in internal/aws and internal/core.
+// sts:GetCallerIdentity needs no permission and is not listed.
+var (
+	discoverActions = []string{
+		"ec2:DescribeInstances",
+		"ec2:DescribeNatGateways",
+		"ec2:DescribeNetworkInterfaces", // interface endpoint placement; placeholders when denied
+		"ec2:DescribeRouteTables",
+		"ec2:DescribeSecurityGroups", // interface endpoint placement; placeholders when denied
+		"ec2:DescribeSubnets",
+		"ec2:DescribeVpcEndpoints",
+		"ec2:DescribeVpcs",       // interface endpoint placement and VPC names; placeholders when denied
+		"iam:ListAccountAliases", // account alias in reports; bare account ID when denied
+		"servicequotas:GetAWSDefaultServiceQuota",
+		"servicequotas:GetServiceQuota", // endpoint quota pre-check; AWS defaults when denied
+		"ssm:DescribeInstanceInformation",
+		"ssm:GetParameter", // only used with --config ssm:///name
+	}
+	// dnsActions read how VPCs resolve endpoint hostnames; the deep scan
+	// skips the Resolver and hosted zone checks when they are denied.
+	dnsActions = []string{
+		"ec2:DescribeVpcAttribute",
+		"route53:ListHostedZonesByVPC",
+		"route53resolver:GetResolverRule",
+		"route53resolver:ListResolverRuleAssociations",
+	}
+	metricsActions = []string{
+		"cloudwatch:GetMetricStatistics",
+	}
+	queryActions = []string{
+		"logs:DescribeLogGroups",
+		"logs:DescribeLogStreams",
+		"logs:FilterLogEvents",
+		"logs:GetQueryResults",
+		"logs:StartQuery",
+		"logs:StopQuery",
+	}
+	flowLogsActions = []string{
+		"ec2:CreateFlowLogs",
+		"ec2:CreateTags",
+		"ec2:DeleteFlowLogs",
+		"ec2:DescribeFlowLogs",
+		"ec2:DescribeNetworkInterfaces", // only used with --eni-id
+	}
+	logGroupActions = []string{
+		"logs:CreateLogGroup",
+		"logs:DeleteLogGroup",
+		"logs:ListTagsForResource", // only used by cleanup --expired and watch
+		"logs:PutRetentionPolicy",
+		"logs:TagResource",
+	}
+	roleCheckActions = []string{
+		"iam:GetRole",
+		"iam:ListAttachedRolePolicies",
+		"iam:ListRolePolicies",
+	}
+	stackActions = []string{
+		"cloudformation:CreateChangeSet",
+		"cloudformation:DeleteStack",
+		"cloudformation:DescribeChangeSet",
+		"cloudformation:DescribeStackResources",
+		"cloudformation:DescribeStacks",
+		"cloudformation:ExecuteChangeSet",
+	}
+	// firehoseActions deliver Flow Logs to a Kinesis Data Firehose stream
+	// through the log delivery service-linked role, and read the records back
+	// from the stream's bucket.
+	firehoseActions = []string{
+		"firehose:TagDeliveryStream",
+		"logs:CreateLogDelivery",
+		"logs:DeleteLogDelivery",
+		"logs:GetLogDelivery",
+		"logs:ListLogDeliveries",
+		"s3:GetObject",
+		"s3:ListBucket",
+	}
+	s3ReadActions = []string{
+		"s3:GetObject",
+		"s3:ListBucket",
+	}
+	// verifyActions run Reachability Analyzer; the tiros actions are the
+	// analyzer's own API, called on the caller's behalf.
+	verifyActions = []string{
+		"ec2:CreateNetworkInsightsPath",
+		"ec2:CreateTags",
+		"ec2:DeleteNetworkInsightsAnalysis",
+		"ec2:DeleteNetworkInsightsPath",
+		"ec2:DescribeManagedPrefixLists",
+		"ec2:DescribeNetworkInsightsAnalyses",
+		"ec2:DescribeNetworkInterfaces",
+		"ec2:GetManagedPrefixListEntries",
+		"ec2:StartNetworkInsightsAnalysis",
+		"tiros:CreateQuery",
+		"tiros:GetQueryAnswer",
+		"tiros:GetQueryExplanation",
+	}
+	anomalyActions = []string{
+		"ce:GetAnomalies",
+		"ce:GetAnomalyMonitors",
+	}
+	applyActions = []string{
+		"ec2:CreateTags",
+		"ec2:CreateVpcEndpoint",
+		"ec2:DescribeSecurityGroups",
+		"ec2:ModifyVpcEndpoint",
+	}
+)
+
+// ForMode returns the least-privilege policy for running terminat in mode.
+// namePrefix is the prefix the scans name their log groups and stacks with
+// (a naming policy's prefix); empty means naming.DefaultPrefix.
+func ForMode(mode, namePrefix string) (*Document, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+
+	doc := &Document{Version: "2012-10-17"}
+	add := func(sid string, actions []string, resources ...string) {
+		sorted := append([]string(nil), actions...)
+		sort.Strings(sorted)
+		doc.Statement = append(doc.Statement, Statement{Sid: sid, Effect: "Allow", Action: sorted, Resource: resources})
+	}
+
+	if namePrefix == "" {
+		namePrefix = naming.DefaultPrefix
+	}
+	logGroupARN := "arn:aws:logs:*:*:log-group:" + naming.LogGroupName(namePrefix) + "*"
+	roleARN := "arn:aws:iam::*:role/" + FlowLogsRoleName
+
+	switch mode {
+	case "quick":
+		add("TerminatDiscover", discoverActions, "*")
+	case "read-only":
+		add("TerminatDiscover", append(append(append([]string{}, discoverActions...), metricsActions...), dnsActions...), "*")
+		add("TerminatQueryExistingFlowLogs", queryActions, "*")
+		// Only used with --cost-anomalies
+		add("TerminatCostAnomalies", anomalyActions, "*")
+	case "deep", "deep-cloudformation":
+		add("TerminatDiscover", append(append(append([]string{}, discoverActions...), metricsActions...), dnsActions...), "*")
+		add("TerminatFlowLogs", flowLogsActions, "*")
+		add("TerminatLogGroups", append(append([]string{}, logGroupActions...), "logs:DescribeLogStreams", "logs:FilterLogEvents", "logs:StartQuery"), logGroupARN, logGroupARN+":*")
+		add("TerminatLogQueries", []string{"logs:DescribeLogGroups", "logs:GetQueryResults", "logs:StopQuery"}, "*")
+		add("TerminatFlowLogsRole", append(append([]string{}, roleCheckActions...), "iam:PassRole"), roleARN)
+		// Only used with --expire-kept-log-group
+		add("TerminatScheduleLogGroupExpiry", []string{"scheduler:CreateSchedule"}, "arn:aws:scheduler:*:*:schedule/default/*-expiry")
+		add("TerminatPassCleanupSchedulerRole", []string{"iam:PassRole"}, "arn:aws:iam::*:role/"+CleanupSchedulerRoleName)
+		// Only used with --cloudtrail-log-group or --resolver-log-group
+		add("TerminatQueryExternalLogGroups", []string{"logs:StartQuery"}, "*")
+		// Only used with --cost-anomalies
+		add("TerminatCostAnomalies", anomalyActions, "*")
+		// Only used with --firehose-stream
+		add("TerminatFirehoseFlowLogs", firehoseActions, "*")
+		add("TerminatLogDeliveryServiceRole", []string{"iam:CreateServiceLinkedRole"}, "arn:aws:iam::*:role/aws-service-role/delivery.logs.amazonaws.com/*")
+		if mode == "deep-cloudformation" {
+			// The stack creates resources with the caller's credentials, so the
+			// statements above still apply.
+			add("TerminatFlowLogsStack", stackActions, "arn:aws:cloudformation:*:*:stack/"+namePrefix+"*/*")
+		}
+	case "apply":
+		add("TerminatDiscover", discoverActions, "*")
+		add("TerminatCreateEndpoints", applyActions, "*")
+	case "verify":
+		add("TerminatDiscover", discoverActions, "*")
+		// Paths through load balancers, firewalls or transit gateways also
+		// need the AmazonVPCReachabilityAnalyzerPathComponentReadPolicy.
+		add("TerminatReachabilityAnalyzer", verifyActions, "*")
+	case "backfill":
+		add("TerminatQueryExistingFlowLogs", queryActions, "*")
+		add("TerminatReadS3FlowLogs", s3ReadActions, "*")
+	case "savings":
+		add("TerminatReadNATCosts", []string{"ce:GetCostAndUsage"}, "*")
+	case "anomalies":
+		add("TerminatCostAnomalies", anomalyActions, "*")
+		// Only used with --create-monitor
+		add("TerminatCreateAnomalyMonitor", []string{"ce:CreateAnomalyMonitor", "ce:TagResource"}, "*")
+	default:
+		return nil, fmt.Errorf("invalid mode %q (valid: %s)", mode, strings.Join(Modes, ", "))
+	}
+	return doc, nil
+}
+
+// JSON renders the policy as indented JSON suitable for aws iam create-policy.
+func (d *Document) JSON() (string, error) {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,87 @@
+package iampolicy
+
+import (
+	"strings"
+	"testing"
+)
+
+func actions(d *Document) map[string]bool {
+	out := map[string]bool{}
+	for _, st := range d.Statement {
+		for _, a := range st.Action {
+			out[a] = true
+		}
+	}
+	return out
+}
+
+func TestForModeQuickHasNoMutatingActions(t *testing.T) {
+	for _, mode := range []string{"quick", "read-only", "backfill", "savings"} {
+		doc, err := ForMode(mode, "")
+		if err != nil {
+			t.Fatalf("ForMode(%q) returned error: %v", mode, err)
+		}
+		for a := range actions(doc) {
+			verb := a[strings.Index(a, ":")+1:]
+			if !strings.HasPrefix(verb, "Describe") && !strings.HasPrefix(verb, "Get") && !strings.HasPrefix(verb, "Filter") && !strings.HasPrefix(verb, "List") && verb != "StartQuery" && verb != "StopQuery" {
+				t.Fatalf("%s policy contains mutating action %s", mode, a)
+			}
+		}
+	}
+}
+
+func TestForModeDeepScopesRoleAndLogGroups(t *testing.T) {
+	doc, err := ForMode("deep", "")
+	if err != nil {
+		t.Fatalf("ForMode returned error: %v", err)
+	}
+	acts := actions(doc)
+	for _, want := range []string{"ec2:CreateFlowLogs", "logs:CreateLogGroup", "iam:PassRole", "cloudwatch:GetMetricStatistics"} {
+		if !acts[want] {
+			t.Fatalf("deep policy missing %s", want)
+		}
+	}
+	for _, st := range doc.Statement {
+		for _, a := range st.Action {
+			if a == "iam:PassRole" && (len(st.Resource) != 1 || !(strings.HasSuffix(st.Resource[0], "role/"+FlowLogsRoleName) || strings.HasSuffix(st.Resource[0], "role/"+CleanupSchedulerRoleName))) {
+				t.Fatalf("iam:PassRole not scoped to a termiNATor role: %v", st.Resource)
+			}
+			if a == "logs:DeleteLogGroup" {
+				for _, r := range st.Resource {
+					if !strings.Contains(r, LogGroupPrefix) {
+						t.Fatalf("logs:DeleteLogGroup not scoped to termiNATor log groups: %v", st.Resource)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestForModeInvalid(t *testing.T) {
+	if _, err := ForMode("admin", ""); err == nil {
+		t.Fatal("expected invalid mode error")
+	}
+}
+
+func TestForModeUsesNamePrefix(t *testing.T) {
+	doc, err := ForMode("deep-cloudformation", "netops-terminat-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logGroups, stacks []string
+	for _, st := range doc.Statement {
+		switch st.Sid {
+		case "TerminatLogGroups":
+			logGroups = st.Resource
+		case "TerminatFlowLogsStack":
+			stacks = st.Resource
+		}
+	}
+	want := []string{"arn:aws:logs:*:*:log-group:/aws/vpc/flowlogs/netops-terminat-*", "arn:aws:logs:*:*:log-group:/aws/vpc/flowlogs/netops-terminat-*:*"}
+	if strings.Join(logGroups, " ") != strings.Join(want, " ") {
+		t.Errorf("log group resources = %v, want %v", logGroups, want)
+	}
+	if len(stacks) != 1 || stacks[0] != "arn:aws:cloudformation:*:*:stack/netops-terminat-*/*" {
+		t.Errorf("stack resources = %v", stacks)
+	}
+}
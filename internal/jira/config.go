@@ -0,0 +1,93 @@
+package jira
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/config"
+)
+
+// Config is the [jira] section of ~/.terminat/config.toml:
+//
+//	[jira]
+//	url = "https://example.atlassian.net"
+//	email = "finops-bot@example.com"   # Jira Cloud; omit to use a Data Center PAT
+//	project = "NET"
+//	issue_type = "Task"
+//
+// The API token comes from JIRA_API_TOKEN, or api_token in the section.
+type Config struct {
+	URL       string
+	Email     string
+	APIToken  string
+	Project   string
+	IssueType string
+}
+
+// LoadConfig reads the [jira] section and applies defaults and the
+// JIRA_API_TOKEN override.
+func LoadConfig() Config {
+	var cfg Config
+	if content, err := config.Read(); err == nil {
+		cfg = parseConfig(content)
+	}
+	if v := os.Getenv("JIRA_API_TOKEN"); v != "" {
+		cfg.APIToken = v
+	}
+	if cfg.IssueType == "" {
+		cfg.IssueType = "Task"
+	}
+	return cfg
+}
+
+func parseConfig(content string) Config {
+	var cfg Config
+	inSection := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "[jira]" {
+			inSection = true
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inSection = false
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		val := strings.Trim(strings.TrimSpace(parts[1]), "\"")
+		switch key {
+		case "url":
+			cfg.URL = strings.TrimRight(val, "/")
+		case "email":
+			cfg.Email = val
+		case "api_token":
+			cfg.APIToken = val
+		case "project":
+			cfg.Project = val
+		case "issue_type":
+			cfg.IssueType = val
+		}
+	}
+	return cfg
+}
+
+// Validate reports the first missing setting.
+func (c Config) Validate() error {
+	switch {
+	case c.URL == "":
+		return fmt.Errorf("jira: url is not set in the [jira] section of ~/.terminat/config.toml")
+	case c.Project == "":
+		return fmt.Errorf("jira: project is not set in the [jira] section of ~/.terminat/config.toml")
+	case c.APIToken == "":
+		return fmt.Errorf("jira: no API token; set JIRA_API_TOKEN or api_token in the [jira] section")
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// Package jira files high-severity findings as Jira issues. Each finding
+// carries a dedupe label, so rerunning a scan updates the open issue instead
+// of creating another one.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// labelPrefix marks issues created by termiNATor; the dedupe key follows it.
+const labelPrefix = "terminat-"
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Client talks to the Jira REST API v2, which Cloud and Data Center share.
+type Client struct {
+	cfg Config
+}
+
+// NewClient validates cfg and returns a Client.
+func NewClient(cfg Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &Client{cfg: cfg}, nil
+}
+
+// SyncResult counts what Sync did.
+type SyncResult struct {
+	Created []string
+	Updated []string
+}
+
+// DedupeKey identifies a finding across scans: the same issue type for the
+// same account, region, VPC and service maps to the same key.
+func DedupeKey(accountID, region string, f types.Finding) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{accountID, region, f.Type, f.VPCID, f.Service}, "|")))
+	return labelPrefix + hex.EncodeToString(sum[:6])
+}
+
+// Sync creates or updates one issue per high-severity finding.
+func (c *Client) Sync(ctx context.Context, accountID, region string, findings []types.Finding) (SyncResult, error) {
+	var res SyncResult
+	for _, f := range findings {
+		if f.Severity != "high" {
+			continue
+		}
+		key := DedupeKey(accountID, region, f)
+		fields := map[string]any{
+			"summary":     fmt.Sprintf("[termiNATor] %s (%s, %s)", f.Title, accountID, region),
+			"description": Description(accountID, region, key, f),
+		}
+
+		existing, err := c.findOpen(ctx, key)
+		if err != nil {
+			return res, err
+		}
+		if existing != "" {
+			if err := c.do(ctx, http.MethodPut, "/rest/api/2/issue/"+existing, map[string]any{"fields": fields}, nil); err != nil {
+				return res, fmt.Errorf("jira: failed to update %s: %w", existing, err)
+			}
+			res.Updated = append(res.Updated, existing)
+			continue
+		}
+
+		fields["project"] = map[string]string{"key": c.cfg.Project}
+		fields["issuetype"] = map[string]string{"name": c.cfg.IssueType}
+		fields["labels"] = []string{"terminat", key}
+		var created struct {
+			Key string `json:"key"`
+		}
+		if err := c.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{"fields": fields}, &created); err != nil {
+			return res, fmt.Errorf("jira: failed to create issue for %q: %w", f.Title, err)
+		}
+		res.Created = append(res.Created, created.Key)
+	}
+	return res, nil
+}
+
+// Description renders a finding in Jira wiki markup, with the remediation
+// commands in a code block.
+func Description(accountID, region, key string, f types.Finding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", f.Description)
+	fmt.Fprintf(&b, "*Account:* %s\n*Region:* %s\n", accountID, region)
+	if f.VPCID != "" {
+		fmt.Fprintf(&b, "*VPC:* %s\n", f.VPCID)
+	}
+	if f.Service != "" {
+		fmt.Fprintf(&b, "*Service:* %s\n", f.Service)
+	}
+	if f.Impact != "" {
+		fmt.Fprintf(&b, "*Impact:* %s\n", f.Impact)
+	}
+	if f.SavingsEstimated {
+		fmt.Fprintf(&b, "*Projected savings:* ~$%.2f/month\n", f.MonthlySavings)
+	}
+	if f.Action != "" {
+		fmt.Fprintf(&b, "\nh3. Remediation\n{code:bash}\n%s\n{code}\n", strings.TrimSpace(f.Action))
+	}
+	fmt.Fprintf(&b, "\n_Filed by termiNATor; later scans update this issue while it is open (dedupe key %s)._\n", key)
+	return b.String()
+}
+
+// findOpen returns the key of the unresolved issue labelled key, if any.
+func (c *Client) findOpen(ctx context.Context, key string) (string, error) {
+	jql := fmt.Sprintf(`project = "%s" AND labels = "%s" AND statusCategory != Done ORDER BY created DESC`, c.cfg.Project, key)
+	query := "?maxResults=1&fields=key&jql=" + url.QueryEscape(jql)
+	var found struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	// Jira Cloud replaced /search with /search/jql; Data Center only has /search.
+	err := c.do(ctx, http.MethodGet, "/rest/api/2/search/jql"+query, nil, &found)
+	if isStatus(err, http.StatusNotFound) {
+		err = c.do(ctx, http.MethodGet, "/rest/api/2/search"+query, nil, &found)
+	}
+	if err != nil {
+		return "", fmt.Errorf("jira: search failed: %w", err)
+	}
+	if len(found.Issues) == 0 {
+		return "", nil
+	}
+	return found.Issues[0].Key, nil
+}
+
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	if e.body == "" {
+		return fmt.Sprintf("Jira API returned %d", e.code)
+	}
+	return fmt.Sprintf("Jira API returned %d: %s", e.code, e.body)
+}
+
+func isStatus(err error, code int) bool {
+	se, ok := err.(*statusError)
+	return ok && se.code == code
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.URL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.Email != "" {
+		req.SetBasicAuth(c.cfg.Email, c.cfg.APIToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIToken)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(msg))}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,111 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg := parseConfig(`[datahub]
+api_key = "x"
+
+[jira]
+url = "https://example.atlassian.net/"
+email = "bot@example.com"
+project = "NET"
+`)
+	if cfg.URL != "https://example.atlassian.net" || cfg.Email != "bot@example.com" || cfg.Project != "NET" {
+		t.Fatalf("got %+v", cfg)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "API token") {
+		t.Fatalf("expected missing token error, got %v", err)
+	}
+}
+
+func TestDedupeKeyStableAcrossScans(t *testing.T) {
+	f := types.Finding{Type: "missing-endpoint", VPCID: "vpc-1", Service: "S3", MonthlySavings: 10}
+	g := f
+	g.MonthlySavings = 20
+	g.Title = "different wording"
+	if DedupeKey("123456789012", "us-east-1", f) != DedupeKey("123456789012", "us-east-1", g) {
+		t.Fatal("key should not depend on savings or wording")
+	}
+	g.VPCID = "vpc-2"
+	if DedupeKey("123456789012", "us-east-1", f) == DedupeKey("123456789012", "us-east-1", g) {
+		t.Fatal("key should differ per VPC")
+	}
+}
+
+func TestSyncCreatesAndUpdates(t *testing.T) {
+	findings := []types.Finding{
+		{Type: "missing-endpoint", Severity: "high", Title: "Missing S3 gateway", VPCID: "vpc-1", Service: "S3", Action: "aws ec2 create-vpc-endpoint ..."},
+		{Type: "missing-endpoint", Severity: "high", Title: "Missing DynamoDB gateway", VPCID: "vpc-1", Service: "DynamoDB"},
+		{Type: "interface-endpoint", Severity: "medium", Title: "Consider ECR endpoint", VPCID: "vpc-1", Service: "ECR"},
+	}
+	existingKey := DedupeKey("123456789012", "us-east-1", findings[0])
+
+	var created []map[string]any
+	var updated []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			t.Error("missing auth")
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/search/jql":
+			// Behave like Data Center: only the legacy endpoint exists.
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/search":
+			if strings.Contains(r.URL.Query().Get("jql"), existingKey) {
+				w.Write([]byte(`{"issues":[{"key":"NET-7"}]}`))
+				return
+			}
+			w.Write([]byte(`{"issues":[]}`))
+		case r.Method == http.MethodPut:
+			updated = append(updated, strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/"))
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			var body map[string]map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body["fields"])
+			w.Write([]byte(`{"key":"NET-8"}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(Config{URL: srv.URL, Project: "NET", IssueType: "Task", APIToken: "pat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Sync(context.Background(), "123456789012", "us-east-1", findings)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(res.Updated) != 1 || res.Updated[0] != "NET-7" || len(updated) != 1 {
+		t.Fatalf("updated = %v", res.Updated)
+	}
+	if len(res.Created) != 1 || len(created) != 1 {
+		t.Fatalf("created = %v, want only the DynamoDB finding (medium is skipped)", res.Created)
+	}
+	labels, _ := created[0]["labels"].([]any)
+	if len(labels) != 2 || labels[1] != DedupeKey("123456789012", "us-east-1", findings[1]) {
+		t.Fatalf("labels = %v", labels)
+	}
+}
+
+func TestDescriptionIncludesRemediation(t *testing.T) {
+	f := types.Finding{Description: "S3 traffic crosses the NAT", Action: "aws ec2 create-vpc-endpoint --vpc-id vpc-1\n", MonthlySavings: 12.5, SavingsEstimated: true}
+	d := Description("123456789012", "us-east-1", "terminat-abc", f)
+	for _, want := range []string{"{code:bash}\naws ec2 create-vpc-endpoint --vpc-id vpc-1\n{code}", "~$12.50/month", "terminat-abc"} {
+		if !strings.Contains(d, want) {
+			t.Errorf("description missing %q:\n%s", want, d)
+		}
+	}
+}
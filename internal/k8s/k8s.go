@@ -0,0 +1,384 @@
+// Package k8s renders the Kubernetes manifests that run terminat serve or
+// terminat watch in a cluster: a ServiceAccount annotated for IAM Roles for
+// Service Accounts (IRSA), the config as a ConfigMap with its credentials in
+// a Secret, and a Deployment and Service for serve or a CronJob for watch
+// --once.
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Modes that can be rendered.
+const (
+	ModeServe = "serve"
+	ModeWatch = "watch"
+)
+
+const (
+	configDir = "/etc/terminat"
+	stateDir  = "/var/lib/terminat"
+)
+
+// Options describe what to render.
+type Options struct {
+	Mode      string
+	Name      string
+	Namespace string
+	Image     string
+	// RoleARN is the IAM role the pods assume through IRSA.
+	RoleARN string
+	Region  string
+	// Config is the config.toml to mount.
+	Config string
+	// Args are passed to terminat after the ones rendered here.
+	Args []string
+	// Schedule is the watch CronJob's schedule.
+	Schedule string
+	// Port is the port serve listens on.
+	Port int
+	// EnvSecret names a Secret whose keys become environment variables,
+	// for the API keys and tokens kept out of the ConfigMap
+	// (DOIT_DATAHUB_API_KEY, JIRA_API_TOKEN, token_env variables).
+	EnvSecret string
+	// StatePVC names a PersistentVolumeClaim for the state directory;
+	// without it the state lives in an emptyDir and is lost with the pod.
+	StatePVC string
+	// Credentials are rendered into a <name>-credentials Secret whose keys
+	// become environment variables; see MoveCredentials.
+	Credentials []Credential
+}
+
+func (o Options) validate() error {
+	switch o.Mode {
+	case ModeServe, ModeWatch:
+	default:
+		return fmt.Errorf("mode %q is neither serve nor watch", o.Mode)
+	}
+	if o.Image == "" {
+		return fmt.Errorf("an image is required")
+	}
+	if o.Name == "" || o.Namespace == "" {
+		return fmt.Errorf("a name and namespace are required")
+	}
+	if o.Mode == ModeWatch && o.Schedule == "" {
+		return fmt.Errorf("watch needs a CronJob schedule")
+	}
+	if o.Mode == ModeServe && (o.Port < 1 || o.Port > 65535) {
+		return fmt.Errorf("port %d is out of range", o.Port)
+	}
+	return nil
+}
+
+// Render writes the manifests as one multi-document YAML stream.
+func Render(w io.Writer, o Options) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+	args := []string{o.Mode, "--config", configDir + "/config.toml"}
+	if o.Region != "" {
+		args = append(args, "--region", o.Region)
+	}
+	switch o.Mode {
+	case ModeServe:
+		args = append(args, "--listen", fmt.Sprintf("0.0.0.0:%d", o.Port))
+	case ModeWatch:
+		args = append(args, "--once")
+	}
+	args = append(args, o.Args...)
+	return manifests.Execute(w, struct {
+		Options
+		ContainerArgs []string
+		ConfigDir     string
+		StateDir      string
+	}{o, args, configDir, stateDir})
+}
+
+// secretKey matches the config keys that hold credentials, including the
+// custom headers of webhook targets (header.Authorization = "Bearer ...").
+// token_sha256 and <key>_env lines name no secret.
+var secretKey = regexp.MustCompile(`^(api_key|api_token|routing_key|token|secret|password|webhook_url|header\..+)$`)
+
+func isSecretKey(key string) bool {
+	return secretKey.MatchString(key) && !strings.HasSuffix(key, "_env")
+}
+
+// Credential is a config value moved out of the ConfigMap into the
+// generated Secret, and passed to the pods as the environment variable Env.
+type Credential struct {
+	Section string
+	Key     string
+	Env     string
+	Value   string
+}
+
+// sectionEnv are the credentials terminat reads from a fixed environment
+// variable instead of its config line.
+var sectionEnv = map[string]map[string]string{
+	"datahub": {"api_key": "DOIT_DATAHUB_API_KEY"},
+	"jira":    {"api_token": "JIRA_API_TOKEN"},
+}
+
+// MoveCredentials takes the credentials terminat can read from the
+// environment out of config.toml: [notify.<name>] settings become
+// <key>_env lines naming a TERMINAT_NOTIFY_... variable, and the DataHub and
+// Jira keys are dropped in favour of DOIT_DATAHUB_API_KEY and
+// JIRA_API_TOKEN. Credentials it cannot move stay, for Credentials to
+// report.
+func MoveCredentials(content string) (string, []Credential) {
+	var moved []Credential
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	section := ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			section = strings.Trim(trimmed, "[]")
+			kept = append(kept, line)
+			continue
+		}
+		key, raw, ok := strings.Cut(trimmed, "=")
+		key = strings.TrimSpace(key)
+		if !ok || strings.HasPrefix(trimmed, "#") || !isSecretKey(key) {
+			kept = append(kept, line)
+			continue
+		}
+		value := configValue(raw)
+		if env := sectionEnv[section][key]; env != "" {
+			moved = append(moved, Credential{Section: section, Key: key, Env: env, Value: value})
+			continue
+		}
+		if !strings.HasPrefix(section, "notify.") {
+			kept = append(kept, line)
+			continue
+		}
+		env := envName(section, key)
+		moved = append(moved, Credential{Section: section, Key: key, Env: env, Value: value})
+		kept = append(kept, fmt.Sprintf("%s_env = %q", key, env))
+	}
+	return strings.Join(kept, "\n"), moved
+}
+
+// configValue is the value of a key = value line: the quoted string, or
+// the text before a trailing comment.
+func configValue(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if rest, ok := strings.CutPrefix(raw, "\""); ok {
+		if end := strings.Index(rest, "\""); end >= 0 {
+			return rest[:end]
+		}
+	}
+	value, _, _ := strings.Cut(raw, "#")
+	return strings.TrimSpace(value)
+}
+
+var nonAlnum = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// envName is the variable a moved notify setting is read from:
+// [notify.oncall] routing_key becomes TERMINAT_NOTIFY_ONCALL_ROUTING_KEY.
+func envName(section, key string) string {
+	return "TERMINAT_" + strings.ToUpper(nonAlnum.ReplaceAllString(section+"_"+key, "_"))
+}
+
+// Credentials returns "[section] key" for each line of a config.toml that
+// holds a credential, which would end up readable by everyone who can read
+// ConfigMaps in the namespace.
+func Credentials(content string) []string {
+	var found []string
+	section := ""
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+		if key, _, ok := strings.Cut(line, "="); ok && !strings.HasPrefix(line, "#") && isSecretKey(strings.TrimSpace(key)) {
+			found = append(found, "["+section+"] "+strings.TrimSpace(key))
+		}
+	}
+	return found
+}
+
+var manifests = template.Must(template.New("k8s").Funcs(template.FuncMap{
+	"include": func(string, any) (string, error) { return "", nil },
+	"quote": func(s string) string {
+		b, _ := json.Marshal(s)
+		return string(b)
+	},
+	"indent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		lines := strings.Split(strings.Trim(s, "\n"), "\n")
+		for i, l := range lines {
+			if l != "" {
+				lines[i] = pad + l
+			}
+		}
+		return strings.Join(lines, "\n")
+	},
+}).Parse(`# Generated by terminat generate k8s --mode {{.Mode}}
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: {{.Name}}
+  namespace: {{.Namespace}}
+{{- if .RoleARN}}
+  annotations:
+    eks.amazonaws.com/role-arn: {{quote .RoleARN}}
+{{- end}}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{.Name}}-config
+  namespace: {{.Namespace}}
+data:
+  config.toml: |
+{{indent 4 (printf "%s\n" .Config)}}
+{{- if .Credentials}}
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{.Name}}-credentials
+  namespace: {{.Namespace}}
+type: Opaque
+stringData:
+{{- range .Credentials}}
+  {{.Env}}: {{quote .Value}}
+{{- end}}
+{{- end}}
+{{- define "pod"}}
+serviceAccountName: {{.Name}}
+securityContext:
+  runAsNonRoot: true
+  runAsUser: 65532
+containers:
+  - name: terminat
+    image: {{quote .Image}}
+    args:
+{{- range .ContainerArgs}}
+      - {{quote .}}
+{{- end}}
+    env:
+      - name: TERMINAT_STATE_DIR
+        value: {{quote .StateDir}}
+{{- if .Region}}
+      - name: AWS_REGION
+        value: {{quote .Region}}
+{{- end}}
+{{- if or .EnvSecret .Credentials}}
+    envFrom:
+{{- if .Credentials}}
+      - secretRef:
+          name: {{.Name}}-credentials
+{{- end}}
+{{- if .EnvSecret}}
+      - secretRef:
+          name: {{.EnvSecret}}
+{{- end}}
+{{- end}}
+{{- if eq .Mode "serve"}}
+    ports:
+      - name: http
+        containerPort: {{.Port}}
+    readinessProbe:
+      tcpSocket:
+        port: http
+{{- end}}
+    securityContext:
+      allowPrivilegeEscalation: false
+      readOnlyRootFilesystem: true
+    volumeMounts:
+      - name: config
+        mountPath: {{.ConfigDir}}
+        readOnly: true
+      - name: state
+        mountPath: {{.StateDir}}
+volumes:
+  - name: config
+    configMap:
+      name: {{.Name}}-config
+  - name: state
+{{- if .StatePVC}}
+    persistentVolumeClaim:
+      claimName: {{.StatePVC}}
+{{- else}}
+    emptyDir: {}
+{{- end}}
+{{- end}}
+{{- if eq .Mode "serve"}}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.Name}}
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: {{.Name}}
+spec:
+  replicas: 1
+  strategy:
+    type: Recreate
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{.Name}}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: {{.Name}}
+    spec:
+{{indent 6 (include "pod" .)}}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{.Name}}
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: {{.Name}}
+spec:
+  selector:
+    app.kubernetes.io/name: {{.Name}}
+  ports:
+    - name: http
+      port: {{.Port}}
+      targetPort: http
+{{- else}}
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: {{.Name}}
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: {{.Name}}
+spec:
+  schedule: {{quote .Schedule}}
+  concurrencyPolicy: Forbid
+  jobTemplate:
+    spec:
+      backoffLimit: 0
+      template:
+        metadata:
+          labels:
+            app.kubernetes.io/name: {{.Name}}
+        spec:
+          restartPolicy: Never
+{{indent 10 (include "pod" .)}}
+{{- end}}
+`))
+
+// include renders a named template into a string, so it can be indented
+// into the Deployment and CronJob pod specs alike.
+func init() {
+	manifests.Funcs(template.FuncMap{"include": func(name string, data any) (string, error) {
+		var b strings.Builder
+		err := manifests.ExecuteTemplate(&b, name, data)
+		return b.String(), err
+	}})
+}
@@ -0,0 +1,115 @@
+package k8s
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/internal/testutil/golden"
+)
+
+const testConfig = `[tenant.acme]
+profile = "acme"
+
+[notify.oncall]
+type = "pagerduty"
+routing_key = "R0UT1NG"
+
+[serve.token.portal]
+role = "viewer"
+token_sha256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+`
+
+func TestRenderGolden(t *testing.T) {
+	base := Options{
+		Name:      "terminat",
+		Namespace: "finops",
+		Image:     "registry.example.com/terminat:v1.0.0",
+		RoleARN:   "arn:aws:iam::123456789012:role/Terminator",
+		Region:    "us-east-1",
+		Config:    testConfig,
+		EnvSecret: "terminat-env",
+	}
+	serve := base
+	serve.Mode, serve.Port = ModeServe, 8080
+	watch := base
+	watch.Mode, watch.Schedule, watch.StatePVC = ModeWatch, "*/15 * * * *", "terminat-state"
+	watch.Args = []string{"--max-gb", "50", "--alert", "oncall"}
+	watch.Config, watch.Credentials = MoveCredentials(testConfig)
+
+	for name, o := range map[string]Options{"serve.yaml": serve, "watch.yaml": watch} {
+		t.Run(name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := Render(&b, o); err != nil {
+				t.Fatal(err)
+			}
+			golden.Check(t, name, b.Bytes())
+		})
+	}
+}
+
+func TestRenderRejectsIncompleteOptions(t *testing.T) {
+	for _, o := range []Options{
+		{Mode: "cron", Name: "terminat", Namespace: "x", Image: "i"},
+		{Mode: ModeServe, Name: "terminat", Namespace: "x", Port: 8080},
+		{Mode: ModeWatch, Name: "terminat", Namespace: "x", Image: "i"},
+		{Mode: ModeServe, Name: "terminat", Namespace: "x", Image: "i"},
+	} {
+		if err := Render(&bytes.Buffer{}, o); err == nil {
+			t.Errorf("Render(%+v) succeeded", o)
+		}
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	got := Credentials(testConfig + "# token = \"commented out\"\n")
+	if len(got) != 1 || got[0] != "[notify.oncall] routing_key" {
+		t.Errorf("Credentials = %v", got)
+	}
+	got = Credentials("[notify.hook]\nurl = \"https://example.com\"\nheader.Authorization = \"Bearer abc\"\n")
+	if len(got) != 1 || got[0] != "[notify.hook] header.Authorization" {
+		t.Errorf("Credentials = %v, want the Authorization header", got)
+	}
+}
+
+func TestMoveCredentials(t *testing.T) {
+	content := `[datahub]
+api_key = "dh-key"
+customer_context = "acme"
+
+[notify.hook]
+type = "webhook"
+url = "https://example.com/hook"
+header.Authorization = "Bearer abc" # from the vault
+secret = "s3cr3t"
+
+[serve.token.ci]
+token = "plain"
+`
+	config, moved := MoveCredentials(content)
+	want := map[string]string{
+		"DOIT_DATAHUB_API_KEY":                      "dh-key",
+		"TERMINAT_NOTIFY_HOOK_HEADER_AUTHORIZATION": "Bearer abc",
+		"TERMINAT_NOTIFY_HOOK_SECRET":               "s3cr3t",
+	}
+	if len(moved) != len(want) {
+		t.Fatalf("moved %+v, want %v", moved, want)
+	}
+	for _, c := range moved {
+		if want[c.Env] != c.Value {
+			t.Errorf("%s = %q, want %q", c.Env, c.Value, want[c.Env])
+		}
+	}
+	for _, line := range []string{`header.Authorization_env = "TERMINAT_NOTIFY_HOOK_HEADER_AUTHORIZATION"`, `secret_env = "TERMINAT_NOTIFY_HOOK_SECRET"`, `customer_context = "acme"`} {
+		if !strings.Contains(config, line) {
+			t.Errorf("config lacks %s:\n%s", line, config)
+		}
+	}
+	if strings.Contains(config, "dh-key") || strings.Contains(config, "abc") || strings.Contains(config, "s3cr3t") {
+		t.Errorf("config still holds moved credentials:\n%s", config)
+	}
+	// Nothing reads serve tokens from the environment, so they stay and are reported
+	if got := Credentials(config); len(got) != 1 || got[0] != "[serve.token.ci] token" {
+		t.Errorf("Credentials after moving = %v", got)
+	}
+}
@@ -0,0 +1,166 @@
+// Package manifest records the AWS resources a deep scan is about to create,
+// so a later run can find and clean up after a scan that died without running
+// its own cleanup (kill -9, OOM, a laptop losing power).
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StateDirEnv overrides where manifests are stored.
+const StateDirEnv = "TERMINAT_STATE_DIR"
+
+// Manifest describes one deep scan's resources. It is written before the
+// first resource is created and removed once the scan has cleaned up.
+type Manifest struct {
+	RunID        string   `json:"run_id"`
+	AccountID    string   `json:"account_id"`
+	Region       string   `json:"region"`
+	LogGroupName string   `json:"log_group_name"`
+	ProvisionVia string   `json:"provision_via"` // "direct" or "cloudformation" (stack named RunID)
+	FlowLogIDs   []string `json:"flow_log_ids,omitempty"`
+	// FirehoseStream is set, and LogGroupName empty, when the Flow Logs
+	// delivered to a Kinesis Data Firehose stream instead of a log group.
+	FirehoseStream string    `json:"firehose_stream,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	Host           string    `json:"host"`
+	PID            int       `json:"pid"`
+}
+
+// Running reports whether the process that wrote m is still alive on this
+// host, in which case its resources are not orphaned.
+func (m *Manifest) Running() bool {
+	host, _ := os.Hostname()
+	if m.PID <= 0 || m.Host != host || m.PID == os.Getpid() {
+		return false
+	}
+	return processAlive(m.PID)
+}
+
+// Store keeps manifests as one JSON file per run in a directory. A nil
+// *Store is valid and does nothing, so scans still run when no state
+// directory is available.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store rooted at dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultStore returns the store under $TERMINAT_STATE_DIR, or
+// <user config dir>/terminat/runs.
+func DefaultStore() (*Store, error) {
+	if dir := os.Getenv(StateDirEnv); dir != "" {
+		return NewStore(dir), nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("no directory for cleanup manifests: %w", err)
+	}
+	return NewStore(filepath.Join(base, "terminat", "runs")), nil
+}
+
+// New returns a manifest for runID stamped with this process and host.
+func New(runID, accountID, region, logGroupName, provisionVia string) *Manifest {
+	host, _ := os.Hostname()
+	return &Manifest{
+		RunID:        runID,
+		AccountID:    accountID,
+		Region:       region,
+		LogGroupName: logGroupName,
+		ProvisionVia: provisionVia,
+		StartedAt:    time.Now().UTC(),
+		Host:         host,
+		PID:          os.Getpid(),
+	}
+}
+
+func (s *Store) path(runID string) string {
+	return filepath.Join(s.dir, runID+".json")
+}
+
+// Save writes m atomically, replacing any earlier version for the same run.
+func (s *Store) Save(m *Manifest) error {
+	if s == nil {
+		return nil
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, m.RunID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	// fsync so the manifest survives the crash it exists for
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path(m.RunID))
+}
+
+// Remove deletes the manifest for runID; a missing manifest is not an error.
+func (s *Store) Remove(runID string) error {
+	if s == nil {
+		return nil
+	}
+	if err := os.Remove(s.path(runID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove manifest: %w", err)
+	}
+	return nil
+}
+
+// List returns every stored manifest, oldest first. Unreadable files are skipped.
+func (s *Store) List() ([]*Manifest, error) {
+	if s == nil {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest directory: %w", err)
+	}
+
+	var manifests []*Manifest
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil || m.RunID == "" {
+			continue
+		}
+		manifests = append(manifests, &m)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].StartedAt.Before(manifests[j].StartedAt) })
+	return manifests, nil
+}
@@ -0,0 +1,88 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "runs"))
+
+	older := New("terminat-100", "123456789012", "us-east-1", "/aws/vpc/flowlogs/terminat-100", "direct")
+	older.StartedAt = time.Unix(100, 0)
+	newer := New("terminat-200", "123456789012", "eu-west-1", "/aws/vpc/flowlogs/terminat-200", "cloudformation")
+	newer.StartedAt = time.Unix(200, 0)
+
+	for _, m := range []*Manifest{newer, older} {
+		if err := store.Save(m); err != nil {
+			t.Fatalf("Save returned error: %v", err)
+		}
+	}
+	older.FlowLogIDs = []string{"fl-1"}
+	if err := store.Save(older); err != nil {
+		t.Fatalf("Save (update) returned error: %v", err)
+	}
+
+	got, err := store.List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(got) != 2 || got[0].RunID != "terminat-100" || got[1].RunID != "terminat-200" {
+		t.Fatalf("List = %+v, want terminat-100 then terminat-200", got)
+	}
+	if len(got[0].FlowLogIDs) != 1 || got[0].FlowLogIDs[0] != "fl-1" {
+		t.Fatalf("updated manifest not persisted: %+v", got[0])
+	}
+
+	if err := store.Remove("terminat-100"); err != nil {
+		t.Fatalf("Remove returned error: %v", err)
+	}
+	if err := store.Remove("terminat-100"); err != nil {
+		t.Fatalf("Remove of missing manifest returned error: %v", err)
+	}
+	got, _ = store.List()
+	if len(got) != 1 || got[0].RunID != "terminat-200" {
+		t.Fatalf("List after Remove = %+v", got)
+	}
+}
+
+func TestStoreListSkipsJunk(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hi"), 0o600)
+
+	got, err := NewStore(dir).List()
+	if err != nil || len(got) != 0 {
+		t.Fatalf("List = %v, %v; want no manifests", got, err)
+	}
+	if got, err := NewStore(filepath.Join(dir, "missing")).List(); err != nil || got != nil {
+		t.Fatalf("List of missing dir = %v, %v", got, err)
+	}
+}
+
+func TestNilStoreIsNoop(t *testing.T) {
+	var store *Store
+	if err := store.Save(New("terminat-1", "", "", "", "")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Remove("terminat-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.List(); err != nil || got != nil {
+		t.Fatalf("List = %v, %v", got, err)
+	}
+}
+
+func TestRunningIgnoresOtherHostsAndSelf(t *testing.T) {
+	m := New("terminat-1", "", "", "", "")
+	if m.Running() {
+		t.Fatal("manifest written by this process should not count as another live run")
+	}
+	m.PID = os.Getppid()
+	m.Host = "some-other-host"
+	if m.Running() {
+		t.Fatal("manifest from another host cannot be checked and should not count as running")
+	}
+}
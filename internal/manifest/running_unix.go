@@ -0,0 +1,18 @@
+//go:build !windows
+
+package manifest
+
+import (
+	"os"
+	"syscall"
+)
+
+// processAlive probes pid with signal 0, which checks for the process
+// without delivering anything to it.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
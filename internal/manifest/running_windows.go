@@ -0,0 +1,25 @@
+//go:build windows
+
+package manifest
+
+import "golang.org/x/sys/windows"
+
+// stillActive is the exit code GetExitCodeProcess reports for a process
+// that has not exited.
+const stillActive = 259
+
+// processAlive opens pid and asks for its exit code. Windows has no
+// signal 0, so os.Process.Signal cannot be used as a probe.
+func processAlive(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access denied still means some process holds the PID
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
@@ -0,0 +1,163 @@
+package naming
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultPrefix starts the name of every resource a deep scan creates.
+const DefaultPrefix = "terminat-"
+
+// Policy is an organization's naming and tagging rules for created resources,
+// loaded from a file like:
+//
+//	[naming]
+//	prefix = "netops-terminat-"
+//	forbidden_chars = "_"
+//	max_length = 64
+//
+//	[tags]
+//	CostCenter = "1234"
+//	Owner = "platform-team"
+type Policy struct {
+	Prefix         string
+	ForbiddenChars string
+	MaxLength      int
+	Tags           map[string]string
+}
+
+// Default returns the policy used when no file is given.
+func Default() *Policy {
+	return &Policy{Prefix: DefaultPrefix, Tags: map[string]string{}}
+}
+
+// LoadPolicy reads a naming/tagging policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read naming policy: %w", err)
+	}
+	return parsePolicy(string(data))
+}
+
+func parsePolicy(content string) (*Policy, error) {
+	p := Default()
+	section := ""
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("naming policy line %d: expected key = value", i+1)
+		}
+		key := strings.Trim(strings.TrimSpace(parts[0]), "\"")
+		val := strings.Trim(strings.TrimSpace(parts[1]), "\"")
+
+		switch section {
+		case "naming":
+			switch key {
+			case "prefix":
+				p.Prefix = val
+			case "forbidden_chars":
+				p.ForbiddenChars = val
+			case "max_length":
+				n, err := strconv.Atoi(val)
+				if err != nil || n < 1 {
+					return nil, fmt.Errorf("naming policy line %d: max_length must be a positive integer", i+1)
+				}
+				p.MaxLength = n
+			default:
+				return nil, fmt.Errorf("naming policy line %d: unknown key %q in [naming]", i+1, key)
+			}
+		case "tags":
+			p.Tags[key] = val
+		default:
+			return nil, fmt.Errorf("naming policy line %d: key %q outside [naming] or [tags]", i+1, key)
+		}
+	}
+	return p, nil
+}
+
+// RunID returns the run identifier for a scan started at unix time ts. It
+// names the CloudFormation stack and is the last path element of the log group.
+func (p *Policy) RunID(ts int64) string {
+	return fmt.Sprintf("%s%d", p.Prefix, ts)
+}
+
+// LogGroupName returns the Flow Logs log group name for runID.
+func LogGroupName(runID string) string {
+	return "/aws/vpc/flowlogs/" + runID
+}
+
+// ResourceTags returns every tag applied to created resources: the policy's
+// mandatory tags plus termiNATor's own CreatedBy and RunId, which take precedence.
+func (p *Policy) ResourceTags(runID string) map[string]string {
+	tags := make(map[string]string, len(p.Tags)+2)
+	for k, v := range p.Tags {
+		tags[k] = v
+	}
+	tags["CreatedBy"] = "termiNATor"
+	tags["RunId"] = runID
+	return tags
+}
+
+var stackNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+
+// Validate checks the resource names and tags of a run against the policy and
+// against AWS naming rules, so a violation is reported before anything is created.
+func (p *Policy) Validate(runID string) error {
+	var problems []string
+	tags := p.ResourceTags(runID)
+
+	names := map[string]string{"run ID / stack name": runID, "log group name": LogGroupName(runID)}
+	if !stackNamePattern.MatchString(runID) || len(runID) > 128 {
+		problems = append(problems, fmt.Sprintf("run ID %q is not a valid CloudFormation stack name (prefix must start with a letter and use only letters, digits, and hyphens)", runID))
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.HasPrefix(strings.ToLower(k), "aws:") {
+			problems = append(problems, fmt.Sprintf("tag key %q uses the reserved aws: prefix", k))
+		}
+		if tags[k] == "" {
+			problems = append(problems, fmt.Sprintf("mandatory tag %q has an empty value", k))
+		}
+		names["tag "+k] = k + tags[k]
+	}
+
+	labels := make([]string, 0, len(names))
+	for label := range names {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		value := names[label]
+		if p.ForbiddenChars != "" {
+			if i := strings.IndexAny(value, p.ForbiddenChars); i >= 0 {
+				problems = append(problems, fmt.Sprintf("%s contains forbidden character %q", label, value[i]))
+			}
+		}
+		if p.MaxLength > 0 && !strings.HasPrefix(label, "tag ") && len(value) > p.MaxLength {
+			problems = append(problems, fmt.Sprintf("%s %q is longer than %d characters", label, value, p.MaxLength))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("naming policy violations:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
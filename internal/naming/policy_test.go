@@ -0,0 +1,66 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePolicy(t *testing.T) {
+	p, err := parsePolicy(`
+# org rules
+[naming]
+prefix = "netops-"
+forbidden_chars = "_"
+max_length = 40
+
+[tags]
+CostCenter = "1234"
+Owner = "platform"
+`)
+	if err != nil {
+		t.Fatalf("parsePolicy returned error: %v", err)
+	}
+	if p.Prefix != "netops-" || p.ForbiddenChars != "_" || p.MaxLength != 40 {
+		t.Fatalf("unexpected naming section: %+v", p)
+	}
+	if p.Tags["CostCenter"] != "1234" || p.Tags["Owner"] != "platform" {
+		t.Fatalf("unexpected tags: %v", p.Tags)
+	}
+	if got := p.RunID(1700000000); got != "netops-1700000000" {
+		t.Fatalf("unexpected run ID %q", got)
+	}
+}
+
+func TestParsePolicyRejectsUnknownKey(t *testing.T) {
+	if _, err := parsePolicy("[naming]\nsuffix = \"x\"\n"); err == nil {
+		t.Fatal("expected unknown key error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	p := Default()
+	if err := p.Validate(p.RunID(1700000000)); err != nil {
+		t.Fatalf("default policy should validate: %v", err)
+	}
+
+	p.ForbiddenChars = "/"
+	p.Tags["aws:owner"] = "x"
+	p.Tags["Team"] = ""
+	err := p.Validate(p.RunID(1700000000))
+	if err == nil {
+		t.Fatal("expected violations")
+	}
+	for _, want := range []string{"log group name contains forbidden character", "reserved aws: prefix", `"Team" has an empty value`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in error:\n%v", want, err)
+		}
+	}
+}
+
+func TestValidateStackName(t *testing.T) {
+	p := Default()
+	p.Prefix = "1bad_"
+	if err := p.Validate(p.RunID(1)); err == nil || !strings.Contains(err.Error(), "CloudFormation stack name") {
+		t.Fatalf("expected stack name violation, got %v", err)
+	}
+}
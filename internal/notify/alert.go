@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Alert is an operational event, such as NAT traffic over a watch
+// threshold. Alerts sharing a DedupKey are one incident: a later alert with
+// Resolved set closes it.
+type Alert struct {
+	DedupKey string
+	Summary  string
+	// Severity is "critical", "error", "warning" or "info".
+	Severity string
+	Source   string
+	Details  map[string]string
+	Resolved bool
+}
+
+// Alerter is implemented by backends that can open and resolve incidents.
+type Alerter interface {
+	Alert(ctx context.Context, a Alert) error
+}
+
+// CheckAlerters builds each target and rejects those that can't take
+// alerts, so watch mode fails at startup rather than at the first spike.
+func CheckAlerters(targets []Target) error {
+	for _, t := range targets {
+		n, err := New(t)
+		if err != nil {
+			return err
+		}
+		if _, ok := n.(Alerter); !ok {
+			return fmt.Errorf("notify target %q: type %q cannot receive alerts (use pagerduty or opsgenie)", t.Name, t.Type)
+		}
+	}
+	return nil
+}
+
+// SendAlert delivers a to every target with the shared retry policy. As with
+// Send, every target is attempted and the failures are joined.
+func SendAlert(ctx context.Context, targets []Target, a Alert) error {
+	var errs []error
+	for _, t := range targets {
+		n, err := New(t)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		alerter, ok := n.(Alerter)
+		if !ok {
+			errs = append(errs, fmt.Errorf("notify target %q: type %q cannot receive alerts", t.Name, t.Type))
+			continue
+		}
+		if err := withRetry(ctx, func() error { return alerter.Alert(ctx, a) }); err != nil {
+			errs = append(errs, fmt.Errorf("notify target %q: %w", t.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/config"
+	"github.com/doitintl/terminator/internal/secrets"
+)
+
+// Target is a named notification destination from config.toml:
+//
+//	[notify.team-chat]
+//	type = "slack"
+//	webhook_url = "https://hooks.slack.com/services/..."
+//
+// Type defaults to the name, so [notify.slack] needs no type line.
+// Credentials can be kept out of the file: in the OS keyring (terminat
+// secret set notify.team-chat webhook_url), or in an environment variable
+// named by <setting>_env (routing_key_env = "PAGERDUTY_ROUTING_KEY").
+type Target struct {
+	Name     string
+	Type     string
+	Settings map[string]string
+}
+
+// LoadTargets reads the [notify.<name>] sections from ~/.terminat/config.toml,
+// or the document given with --config.
+func LoadTargets() ([]Target, error) {
+	content, err := config.Read()
+	if err != nil {
+		return nil, err
+	}
+	targets := parseTargets(content)
+	for _, t := range targets {
+		addStoredSettings(t)
+		if err := resolveEnvSettings(t, os.Getenv); err != nil {
+			return nil, err
+		}
+	}
+	return targets, nil
+}
+
+// addStoredSettings adds the credentials kept in the OS keyring for t
+// (terminat secret set) that config.toml does not set itself. Without a
+// keyring, only the file's settings are used.
+func addStoredSettings(t Target) {
+	stored, err := secrets.Settings(secrets.NotifyTarget(t.Name))
+	if err != nil {
+		return
+	}
+	for key, value := range stored {
+		if _, ok := t.Settings[key]; !ok {
+			t.Settings[key] = value
+		}
+	}
+}
+
+// envSuffix marks a setting whose value names the environment variable
+// holding the real value.
+const envSuffix = "_env"
+
+// resolveEnvSettings replaces each <setting>_env of t with <setting> read
+// from the variable it names. An unset variable is an error, so a missing
+// Secret is not mistaken for a setting left out on purpose.
+func resolveEnvSettings(t Target, getenv func(string) string) error {
+	for key, variable := range t.Settings {
+		name, ok := strings.CutSuffix(key, envSuffix)
+		if !ok || name == "" {
+			continue
+		}
+		value := getenv(variable)
+		if value == "" {
+			return fmt.Errorf("notify target %q: %s names %s, which is not set", t.Name, key, variable)
+		}
+		t.Settings[name] = value
+		delete(t.Settings, key)
+	}
+	return nil
+}
+
+func parseTargets(content string) []Target {
+	byName := map[string]*Target{}
+	var current *Target
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			current = nil
+			name, ok := strings.CutPrefix(strings.Trim(line, "[]"), "notify.")
+			if !ok || name == "" {
+				continue
+			}
+			if byName[name] == nil {
+				byName[name] = &Target{Name: name, Type: name, Settings: map[string]string{}}
+			}
+			current = byName[name]
+			continue
+		}
+		if current == nil || line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		val := strings.Trim(strings.TrimSpace(parts[1]), "\"")
+		if key == "type" {
+			current.Type = val
+			continue
+		}
+		current.Settings[key] = val
+	}
+
+	targets := make([]Target, 0, len(byName))
+	for _, t := range byName {
+		targets = append(targets, *t)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+	return targets
+}
+
+// Select returns the targets named in names, in that order. An unknown
+// name is an error listing what is configured.
+func Select(targets []Target, names []string) ([]Target, error) {
+	var selected []Target
+	for _, name := range names {
+		found := false
+		for _, t := range targets {
+			if t.Name == name {
+				selected = append(selected, t)
+				found = true
+				break
+			}
+		}
+		if !found {
+			configured := make([]string, 0, len(targets))
+			for _, t := range targets {
+				configured = append(configured, t.Name)
+			}
+			if len(configured) == 0 {
+				return nil, fmt.Errorf("notify target %q is not configured: add a [notify.%s] section to ~/.terminat/config.toml", name, name)
+			}
+			return nil, fmt.Errorf("notify target %q is not configured (configured: %s)", name, strings.Join(configured, ", "))
+		}
+	}
+	return selected, nil
+}
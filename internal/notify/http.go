@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// postJSON posts v to endpoint as JSON.
+func postJSON(ctx context.Context, endpoint string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return post(ctx, endpoint, body, nil)
+}
+
+// post sends a JSON body with any extra headers. Network errors, 429 and
+// 5xx responses are retryable; other non-2xx responses are not.
+func post(ctx context.Context, endpoint string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Retryable(fmt.Errorf("request failed: %w", err))
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return Retryable(fmt.Errorf("endpoint returned %d", resp.StatusCode))
+	default:
+		return fmt.Errorf("endpoint returned %d", resp.StatusCode)
+	}
+}
+
+// requireURL reads an http(s) URL setting.
+func requireURL(settings map[string]string, key string) (string, error) {
+	raw := settings[key]
+	if raw == "" {
+		return "", fmt.Errorf("%s is required", key)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("%s must be an http(s) URL", key)
+	}
+	return raw, nil
+}
@@ -0,0 +1,209 @@
+// Package notify sends a short scan summary to chat, email and HTTP
+// backends. Backends register a Factory under a type name; targets are
+// configured in ~/.terminat/config.toml and share the summary formatting and
+// retry policy defined here.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+)
+
+// Notifier delivers a summary to one backend.
+type Notifier interface {
+	Notify(ctx context.Context, s Summary) error
+}
+
+// Factory builds a Notifier from a target's settings. It should reject
+// missing or malformed settings so mistakes surface before a scan starts.
+type Factory func(settings map[string]string) (Notifier, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a backend available under typ. It panics on duplicates,
+// as two backends claiming one name is a programming error.
+func Register(typ string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[typ]; ok {
+		panic("notify: backend registered twice: " + typ)
+	}
+	registry[typ] = f
+}
+
+// Types lists the registered backend types, sorted.
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	types := make([]string, 0, len(registry))
+	for typ := range registry {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// New builds the Notifier for a configured target.
+func New(t Target) (Notifier, error) {
+	registryMu.RLock()
+	f, ok := registry[t.Type]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("notify target %q: unknown type %q (valid: %s)", t.Name, t.Type, strings.Join(Types(), ", "))
+	}
+	n, err := f(t.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("notify target %q: %w", t.Name, err)
+	}
+	return n, nil
+}
+
+// Summary is what every backend reports after a scan.
+type Summary struct {
+	AccountID      string                    `json:"account_id"`
+	AccountAlias   string                    `json:"account_alias,omitempty"`
+	Region         string                    `json:"region"`
+	MonthlyNATCost float64                   `json:"monthly_nat_cost"`
+	MonthlySavings float64                   `json:"monthly_savings"`
+	Actions        []analysis.HeadlineAction `json:"actions,omitempty"`
+	Confidence     string                    `json:"confidence"`
+	// Report is the full JSON report, for backends that can carry it.
+	Report json.RawMessage `json:"-"`
+	// Changes, when set, makes this a findings diff against the previous
+	// scan (watch mode) instead of a scan summary.
+	Changes *FindingChanges `json:"changes,omitempty"`
+}
+
+// FindingChanges are the findings that appeared and disappeared between
+// two scans, one line each.
+type FindingChanges struct {
+	New      []string `json:"new,omitempty"`
+	Resolved []string `json:"resolved,omitempty"`
+}
+
+// NewSummary builds a Summary from a scan's headline.
+func NewSummary(accountID, region string, h analysis.Headline) Summary {
+	return Summary{
+		AccountID:      accountID,
+		Region:         region,
+		MonthlyNATCost: h.MonthlyNATCost,
+		MonthlySavings: h.MonthlySavings,
+		Actions:        h.Actions,
+		Confidence:     h.Confidence,
+	}
+}
+
+// account is the account ID, after its alias when known.
+func (s Summary) account() string {
+	if s.AccountAlias == "" {
+		return s.AccountID
+	}
+	return s.AccountAlias + " " + s.AccountID
+}
+
+// Subject is a one-line title for backends that have one.
+func (s Summary) Subject() string {
+	if c := s.Changes; c != nil {
+		return fmt.Sprintf("termiNATor: %d new, %d resolved finding(s) in %s (%s)", len(c.New), len(c.Resolved), s.account(), s.Region)
+	}
+	return fmt.Sprintf("termiNATor: $%.2f/month NAT savings in %s (%s)", s.MonthlySavings, s.account(), s.Region)
+}
+
+// Text is the plain-text body shared by chat and email backends.
+func (s Summary) Text() string {
+	var b strings.Builder
+	if c := s.Changes; c != nil {
+		fmt.Fprintf(&b, "NAT Gateway findings in account %s in %s changed since the previous scan\n", s.account(), s.Region)
+		for _, line := range c.New {
+			fmt.Fprintf(&b, "New: %s\n", line)
+		}
+		for _, line := range c.Resolved {
+			fmt.Fprintf(&b, "Resolved: %s\n", line)
+		}
+		return b.String()
+	}
+	fmt.Fprintf(&b, "NAT Gateway scan of account %s in %s\n", s.account(), s.Region)
+	fmt.Fprintf(&b, "NAT spend: $%.2f/month (projected)\n", s.MonthlyNATCost)
+	fmt.Fprintf(&b, "Savings potential: $%.2f/month ($%.2f/year)\n", s.MonthlySavings, s.MonthlySavings*12)
+	if len(s.Actions) == 0 {
+		b.WriteString("Top actions: none, no issues found\n")
+	} else {
+		b.WriteString("Top actions:\n")
+		for i, a := range s.Actions {
+			if a.SavingsEstimated {
+				fmt.Fprintf(&b, "%d. %s (~$%.2f/month)\n", i+1, a.Title, a.MonthlySavings)
+			} else {
+				fmt.Fprintf(&b, "%d. %s\n", i+1, a.Title)
+			}
+		}
+	}
+	fmt.Fprintf(&b, "Confidence: %s\n", s.Confidence)
+	return b.String()
+}
+
+// Retry policy shared by all backends. retryDelay is a variable so tests
+// don't wait.
+var (
+	maxAttempts = 3
+	retryDelay  = 2 * time.Second
+)
+
+// retryableError marks a failure worth another attempt, such as throttling
+// or a 5xx response.
+type retryableError struct{ err error }
+
+func (e retryableError) Error() string { return e.err.Error() }
+func (e retryableError) Unwrap() error { return e.err }
+
+// Retryable wraps err so Send tries again.
+func Retryable(err error) error { return retryableError{err} }
+
+// Send delivers s to every target, retrying retryable failures with linear
+// backoff. Every target is attempted; the errors of those that failed are
+// joined.
+func Send(ctx context.Context, targets []Target, s Summary) error {
+	var errs []error
+	for _, t := range targets {
+		n, err := New(t)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := withRetry(ctx, func() error { return n.Notify(ctx, s) }); err != nil {
+			errs = append(errs, fmt.Errorf("notify target %q: %w", t.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withRetry calls fn until it succeeds, returns a non-retryable error or
+// runs out of attempts.
+func withRetry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		var re retryableError
+		if !errors.As(err, &re) || attempt == maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * retryDelay):
+		}
+	}
+	return err
+}
@@ -0,0 +1,334 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/config"
+	"github.com/doitintl/terminator/internal/secrets"
+	"github.com/zalando/go-keyring"
+)
+
+func testSummary() Summary {
+	return NewSummary("123456789012", "us-east-1", analysis.Headline{
+		MonthlyNATCost: 120,
+		MonthlySavings: 45.5,
+		Actions:        []analysis.HeadlineAction{{Title: "Add S3 gateway endpoint", MonthlySavings: 45.5, SavingsEstimated: true}},
+		Confidence:     "medium",
+	})
+}
+
+func TestParseTargets(t *testing.T) {
+	content := `[datahub]
+api_key = "k"
+
+[notify.slack]
+webhook_url = "https://hooks.slack.com/services/x"
+
+[notify.ops]
+type = "webhook"
+# comment
+url = "https://example.com/hook"
+`
+	targets := parseTargets(content)
+	if len(targets) != 2 {
+		t.Fatalf("got %d targets, want 2: %+v", len(targets), targets)
+	}
+	if targets[0].Name != "ops" || targets[0].Type != "webhook" || targets[0].Settings["url"] != "https://example.com/hook" {
+		t.Fatalf("ops target = %+v", targets[0])
+	}
+	if targets[1].Name != "slack" || targets[1].Type != "slack" || targets[1].Settings["webhook_url"] == "" {
+		t.Fatalf("slack target = %+v", targets[1])
+	}
+	if _, ok := targets[0].Settings["type"]; ok {
+		t.Fatal("type should not be kept as a setting")
+	}
+}
+
+func TestLoadTargetsReadsKeyring(t *testing.T) {
+	keyring.MockInit()
+	config.Use("[notify.oncall]\ntype = \"pagerduty\"\n\n[notify.ops]\ntype = \"webhook\"\nurl = \"https://example.com\"\nsecret = \"from-file\"\n")
+	defer config.Use("")
+	if err := secrets.SetSetting(secrets.NotifyTarget("oncall"), "routing_key", "R0UT1NG"); err != nil {
+		t.Fatal(err)
+	}
+	if err := secrets.SetSetting(secrets.NotifyTarget("ops"), "secret", "from-keyring"); err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := LoadTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := targets[0].Settings["routing_key"]; got != "R0UT1NG" {
+		t.Errorf("oncall routing_key = %q, want the keyring's", got)
+	}
+	if got := targets[1].Settings["secret"]; got != "from-file" {
+		t.Errorf("ops secret = %q, want the file's to take precedence", got)
+	}
+}
+
+func TestResolveEnvSettings(t *testing.T) {
+	env := map[string]string{"PD_KEY": "R0UT1NG", "HOOK_AUTH": "Bearer abc"}
+	getenv := func(k string) string { return env[k] }
+
+	target := Target{Name: "oncall", Settings: map[string]string{"routing_key_env": "PD_KEY", "header.Authorization_env": "HOOK_AUTH"}}
+	if err := resolveEnvSettings(target, getenv); err != nil {
+		t.Fatal(err)
+	}
+	if target.Settings["routing_key"] != "R0UT1NG" || target.Settings["header.Authorization"] != "Bearer abc" || len(target.Settings) != 2 {
+		t.Fatalf("settings = %v", target.Settings)
+	}
+
+	missing := Target{Name: "oncall", Settings: map[string]string{"routing_key_env": "UNSET"}}
+	if err := resolveEnvSettings(missing, getenv); err == nil || !strings.Contains(err.Error(), "UNSET") {
+		t.Fatalf("expected an error naming the unset variable, got %v", err)
+	}
+}
+
+func TestSelectUnknownTarget(t *testing.T) {
+	targets := []Target{{Name: "slack", Type: "slack"}}
+	if _, err := Select(targets, []string{"teams"}); err == nil || !strings.Contains(err.Error(), "configured: slack") {
+		t.Fatalf("expected unknown target error listing slack, got %v", err)
+	}
+}
+
+func TestNewRejectsBadSettings(t *testing.T) {
+	cases := []Target{
+		{Name: "a", Type: "pager"},
+		{Name: "b", Type: "slack"},
+		{Name: "c", Type: "webhook", Settings: map[string]string{"url": "ftp://example.com"}},
+		{Name: "d", Type: "sns", Settings: map[string]string{"topic_arn": "arn:aws:sqs:us-east-1:123456789012:q"}},
+	}
+	for _, tc := range cases {
+		if _, err := New(tc); err == nil {
+			t.Errorf("New(%+v) should fail", tc)
+		}
+	}
+}
+
+func TestSendSlack(t *testing.T) {
+	var payload map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer srv.Close()
+
+	targets := []Target{{Name: "slack", Type: "slack", Settings: map[string]string{"webhook_url": srv.URL}}}
+	if err := Send(context.Background(), targets, testSummary()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	for _, want := range []string{"$45.50/month", "1. Add S3 gateway endpoint", "Confidence: medium"} {
+		if !strings.Contains(payload["text"], want) {
+			t.Errorf("slack text missing %q:\n%s", want, payload["text"])
+		}
+	}
+}
+
+func TestFindingChangesSummary(t *testing.T) {
+	s := Summary{AccountID: "123456789012", Region: "us-east-1", Changes: &FindingChanges{
+		New:      []string{"[high] Missing S3 Gateway Endpoint in vpc-1"},
+		Resolved: []string{"[high] Missing DynamoDB Gateway Endpoint in vpc-1"},
+	}}
+	if got := s.Subject(); got != "termiNATor: 1 new, 1 resolved finding(s) in 123456789012 (us-east-1)" {
+		t.Errorf("Subject() = %q", got)
+	}
+	text := s.Text()
+	for _, want := range []string{"New: [high] Missing S3 Gateway Endpoint", "Resolved: [high] Missing DynamoDB"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Savings potential") {
+		t.Errorf("a findings diff should not read as a scan summary:\n%s", text)
+	}
+}
+
+func TestSummaryNamesAccountAlias(t *testing.T) {
+	s := Summary{AccountID: "123456789012", AccountAlias: "payments-prod", Region: "us-east-1", MonthlySavings: 10}
+	if got := s.Subject(); got != "termiNATor: $10.00/month NAT savings in payments-prod 123456789012 (us-east-1)" {
+		t.Errorf("Subject() = %q", got)
+	}
+	if text := s.Text(); !strings.Contains(text, "account payments-prod 123456789012 in us-east-1") {
+		t.Errorf("text lacks the alias:\n%s", text)
+	}
+}
+
+func TestSendRetriesServerErrors(t *testing.T) {
+	retryDelay = 0
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	targets := []Target{{Name: "hook", Type: "webhook", Settings: map[string]string{"url": srv.URL}}}
+	if err := Send(context.Background(), targets, testSummary()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("server got %d calls, want 3", calls.Load())
+	}
+}
+
+func TestSendDoesNotRetryClientErrors(t *testing.T) {
+	retryDelay = 0
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	targets := []Target{
+		{Name: "bad", Type: "teams", Settings: map[string]string{"webhook_url": srv.URL}},
+		{Name: "missing", Type: "webhook"},
+	}
+	err := Send(context.Background(), targets, testSummary())
+	if err == nil || !strings.Contains(err.Error(), `"bad"`) || !strings.Contains(err.Error(), `"missing"`) {
+		t.Fatalf("expected errors for both targets, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("server got %d calls, want 1", calls.Load())
+	}
+}
+
+func TestWebhookSignsReportWithHeaders(t *testing.T) {
+	secret := []byte("s3cret")
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	s := testSummary()
+	s.Report = json.RawMessage(`{"region":"us-east-1"}`)
+	targets := []Target{{Name: "portal", Type: "webhook", Settings: map[string]string{
+		WebhookURL:                        srv.URL,
+		WebhookPayload:                    "report",
+		WebhookSecret:                     string(secret),
+		WebhookHeaderPrefix + "X-Api-Key": "abc",
+	}}}
+	if err := Send(context.Background(), targets, s); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if string(body) != string(s.Report) {
+		t.Fatalf("body = %s, want the report", body)
+	}
+	if header.Get("X-Api-Key") != "abc" {
+		t.Fatalf("X-Api-Key = %q", header.Get("X-Api-Key"))
+	}
+	ts := header.Get(TimestampHeader)
+	if ts == "" || header.Get(SignatureHeader) != Sign(secret, ts, body) {
+		t.Fatalf("bad signature %q for timestamp %q", header.Get(SignatureHeader), ts)
+	}
+}
+
+func TestWebhookSummaryPayload(t *testing.T) {
+	var got Summary
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	targets := []Target{{Name: "hook", Type: "webhook", Settings: map[string]string{WebhookURL: srv.URL}}}
+	if err := Send(context.Background(), targets, testSummary()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.MonthlySavings != 45.5 || len(got.Actions) != 1 {
+		t.Fatalf("summary = %+v", got)
+	}
+	if header.Get(SignatureHeader) != "" {
+		t.Fatal("unsigned webhook should not send a signature")
+	}
+}
+
+func TestPagerDutyTriggerAndResolve(t *testing.T) {
+	var events []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/enqueue" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var e map[string]any
+		json.NewDecoder(r.Body).Decode(&e)
+		events = append(events, e)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	orig := pagerDutyURL
+	pagerDutyURL = srv.URL
+	defer func() { pagerDutyURL = orig }()
+
+	targets := []Target{{Name: "pd", Type: "pagerduty", Settings: map[string]string{"routing_key": "rk"}}}
+	if err := CheckAlerters(targets); err != nil {
+		t.Fatal(err)
+	}
+	a := Alert{DedupKey: "terminat-watch-nat-1", Summary: "NAT spike", Severity: "warning", Source: "terminat", Details: map[string]string{"nat_gateway_id": "nat-1"}}
+	if err := SendAlert(context.Background(), targets, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := SendAlert(context.Background(), targets, Alert{DedupKey: a.DedupKey, Resolved: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0]["event_action"] != "trigger" || events[1]["event_action"] != "resolve" {
+		t.Fatalf("events = %v", events)
+	}
+	if events[0]["dedup_key"] != a.DedupKey || events[1]["dedup_key"] != a.DedupKey {
+		t.Fatalf("dedup keys differ: %v", events)
+	}
+	payload, _ := events[0]["payload"].(map[string]any)
+	if payload["severity"] != "warning" || payload["summary"] != "NAT spike" {
+		t.Fatalf("payload = %v", payload)
+	}
+}
+
+func TestOpsgenieCreateAndClose(t *testing.T) {
+	var paths []string
+	var created map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "GenieKey k" {
+			t.Errorf("bad auth %q", r.Header.Get("Authorization"))
+		}
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Path == "/v2/alerts" {
+			json.NewDecoder(r.Body).Decode(&created)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	targets := []Target{{Name: "og", Type: "opsgenie", Settings: map[string]string{"api_key": "k", "api_url": srv.URL}}}
+	a := Alert{DedupKey: "terminat-watch-nat-1", Summary: "NAT spike", Severity: "error", Source: "terminat"}
+	if err := SendAlert(context.Background(), targets, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := SendAlert(context.Background(), targets, Alert{DedupKey: a.DedupKey, Source: "terminat", Resolved: true}); err != nil {
+		t.Fatal(err)
+	}
+	if created["alias"] != a.DedupKey || created["priority"] != "P2" {
+		t.Fatalf("created = %v", created)
+	}
+	if len(paths) != 2 || paths[1] != "/v2/alerts/terminat-watch-nat-1/close?identifierType=alias" {
+		t.Fatalf("paths = %v", paths)
+	}
+}
+
+func TestCheckAlertersRejectsChatTargets(t *testing.T) {
+	targets := []Target{{Name: "slack", Type: "slack", Settings: map[string]string{"webhook_url": "https://hooks.slack.com/x"}}}
+	if err := CheckAlerters(targets); err == nil || !strings.Contains(err.Error(), "cannot receive alerts") {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	Register("opsgenie", newOpsgenie)
+}
+
+// opsgenie creates and closes Opsgenie alerts, keyed by alias. api_url
+// selects the EU instance (https://api.eu.opsgenie.com).
+type opsgenie struct {
+	apiURL string
+	apiKey string
+}
+
+func newOpsgenie(settings map[string]string) (Notifier, error) {
+	key := settings["api_key"]
+	if key == "" {
+		return nil, fmt.Errorf("api_key is required")
+	}
+	apiURL := "https://api.opsgenie.com"
+	if settings["api_url"] != "" {
+		u, err := requireURL(settings, "api_url")
+		if err != nil {
+			return nil, err
+		}
+		apiURL = strings.TrimRight(u, "/")
+	}
+	return &opsgenie{apiURL: apiURL, apiKey: key}, nil
+}
+
+// opsgeniePriority maps alert severities to Opsgenie priorities.
+var opsgeniePriority = map[string]string{"critical": "P1", "error": "P2", "warning": "P3", "info": "P5"}
+
+func (n *opsgenie) Notify(ctx context.Context, s Summary) error {
+	return n.post(ctx, "/v2/alerts", map[string]any{
+		"message":     truncate(s.Subject(), 130),
+		"alias":       fmt.Sprintf("terminat-summary-%s-%s", s.AccountID, s.Region),
+		"description": s.Text(),
+		"source":      "terminat",
+		"priority":    "P5",
+	})
+}
+
+func (n *opsgenie) Alert(ctx context.Context, a Alert) error {
+	if a.Resolved {
+		return n.post(ctx, "/v2/alerts/"+url.PathEscape(a.DedupKey)+"/close?identifierType=alias", map[string]any{"source": a.Source})
+	}
+	priority := opsgeniePriority[a.Severity]
+	if priority == "" {
+		priority = "P3"
+	}
+	return n.post(ctx, "/v2/alerts", map[string]any{
+		"message":  truncate(a.Summary, 130),
+		"alias":    a.DedupKey,
+		"details":  a.Details,
+		"source":   a.Source,
+		"priority": priority,
+	})
+}
+
+func (n *opsgenie) post(ctx context.Context, path string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return post(ctx, n.apiURL+path, body, http.Header{"Authorization": {"GenieKey " + n.apiKey}})
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+)
+
+func init() {
+	Register("pagerduty", newPagerDuty)
+}
+
+// pagerDutyURL is the Events API v2 base; a variable so tests can redirect it.
+var pagerDutyURL = "https://events.pagerduty.com"
+
+// pagerDuty sends alerts as Events API v2 trigger/resolve events and scan
+// summaries as change events, which show on the service without paging.
+type pagerDuty struct {
+	routingKey string
+}
+
+func newPagerDuty(settings map[string]string) (Notifier, error) {
+	key := settings["routing_key"]
+	if key == "" {
+		return nil, fmt.Errorf("routing_key is required")
+	}
+	return &pagerDuty{routingKey: key}, nil
+}
+
+func (n *pagerDuty) Notify(ctx context.Context, s Summary) error {
+	return postJSON(ctx, pagerDutyURL+"/v2/change/enqueue", map[string]any{
+		"routing_key": n.routingKey,
+		"payload": map[string]any{
+			"summary":        s.Subject(),
+			"source":         "terminat",
+			"custom_details": s,
+		},
+	})
+}
+
+func (n *pagerDuty) Alert(ctx context.Context, a Alert) error {
+	event := map[string]any{
+		"routing_key":  n.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    a.DedupKey,
+	}
+	if a.Resolved {
+		event["event_action"] = "resolve"
+	} else {
+		event["payload"] = map[string]any{
+			"summary":        a.Summary,
+			"source":         a.Source,
+			"severity":       a.Severity,
+			"component":      "nat-gateway",
+			"custom_details": a.Details,
+		}
+	}
+	return postJSON(ctx, pagerDutyURL+"/v2/enqueue", event)
+}
@@ -0,0 +1,24 @@
+package notify
+
+import "context"
+
+func init() {
+	Register("slack", newSlack)
+}
+
+// slack posts to a Slack incoming webhook.
+type slack struct {
+	webhookURL string
+}
+
+func newSlack(settings map[string]string) (Notifier, error) {
+	u, err := requireURL(settings, "webhook_url")
+	if err != nil {
+		return nil, err
+	}
+	return &slack{webhookURL: u}, nil
+}
+
+func (n *slack) Notify(ctx context.Context, s Summary) error {
+	return postJSON(ctx, n.webhookURL, map[string]string{"text": "*" + s.Subject() + "*\n" + s.Text()})
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+func init() {
+	Register("sns", newSNS)
+}
+
+// snsTopic publishes to an SNS topic, using the profile setting or the
+// default credential chain and the topic's own region.
+type snsTopic struct {
+	topicARN string
+	region   string
+	profile  string
+}
+
+func newSNS(settings map[string]string) (Notifier, error) {
+	topic := settings["topic_arn"]
+	if topic == "" {
+		return nil, fmt.Errorf("topic_arn is required")
+	}
+	parsed, err := arn.Parse(topic)
+	if err != nil || parsed.Service != "sns" {
+		return nil, fmt.Errorf("topic_arn must be an SNS topic ARN")
+	}
+	return &snsTopic{topicARN: topic, region: parsed.Region, profile: settings["profile"]}, nil
+}
+
+func (n *snsTopic) Notify(ctx context.Context, s Summary) error {
+	opts := []func(*config.LoadOptions) error{config.WithRegion(n.region)}
+	if n.profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(n.profile))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	// Send owns retries, so the SDK makes a single attempt.
+	client := sns.NewFromConfig(cfg, func(o *sns.Options) { o.Retryer = aws.NopRetryer{} })
+
+	subject := s.Subject()
+	if len(subject) > 100 {
+		subject = subject[:100]
+	}
+	_, err = client.Publish(ctx, &sns.PublishInput{
+		TopicArn: aws.String(n.topicARN),
+		Subject:  aws.String(subject),
+		Message:  aws.String(s.Text()),
+	})
+	if err != nil && retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary {
+		return Retryable(err)
+	}
+	return err
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"context"
+	"strings"
+)
+
+func init() {
+	Register("teams", newTeams)
+}
+
+// teams posts to a Microsoft Teams incoming webhook or workflow URL.
+type teams struct {
+	webhookURL string
+}
+
+func newTeams(settings map[string]string) (Notifier, error) {
+	u, err := requireURL(settings, "webhook_url")
+	if err != nil {
+		return nil, err
+	}
+	return &teams{webhookURL: u}, nil
+}
+
+func (n *teams) Notify(ctx context.Context, s Summary) error {
+	// Teams renders text as markdown, where single newlines are collapsed.
+	text := "**" + s.Subject() + "**\n\n" + strings.ReplaceAll(strings.TrimSpace(s.Text()), "\n", "\n\n")
+	return postJSON(ctx, n.webhookURL, map[string]string{"text": text})
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("webhook", newWebhook)
+}
+
+// Webhook settings. Headers are given as header.<Name> = "value".
+const (
+	WebhookURL          = "url"
+	WebhookPayload      = "payload"
+	WebhookSecret       = "secret"
+	WebhookHeaderPrefix = "header."
+)
+
+// Signature headers set when a secret is configured. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)), so receivers
+// can reject both forged and replayed deliveries.
+const (
+	SignatureHeader = "X-Terminat-Signature"
+	TimestampHeader = "X-Terminat-Timestamp"
+)
+
+// webhook POSTs the Summary, or the full JSON report, to any HTTP endpoint.
+type webhook struct {
+	url    string
+	report bool
+	secret []byte
+	header http.Header
+}
+
+func newWebhook(settings map[string]string) (Notifier, error) {
+	u, err := requireURL(settings, WebhookURL)
+	if err != nil {
+		return nil, err
+	}
+	w := &webhook{url: u, header: http.Header{}}
+	switch settings[WebhookPayload] {
+	case "", "summary":
+	case "report":
+		w.report = true
+	default:
+		return nil, fmt.Errorf("payload must be summary or report")
+	}
+	if secret := settings[WebhookSecret]; secret != "" {
+		w.secret = []byte(secret)
+	}
+	for key, value := range settings {
+		name, ok := strings.CutPrefix(key, WebhookHeaderPrefix)
+		if !ok {
+			continue
+		}
+		if name == "" || strings.ContainsAny(name, " :") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		w.header.Set(name, value)
+	}
+	return w, nil
+}
+
+func (w *webhook) Notify(ctx context.Context, s Summary) error {
+	var body []byte
+	if w.report {
+		if len(s.Report) == 0 {
+			return fmt.Errorf("no report to send")
+		}
+		body = s.Report
+	} else {
+		var err error
+		if body, err = json.Marshal(s); err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+	}
+
+	header := w.header.Clone()
+	if w.secret != nil {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		header.Set(TimestampHeader, ts)
+		header.Set(SignatureHeader, Sign(w.secret, ts, body))
+	}
+	return post(ctx, w.url, body, header)
+}
+
+// Sign computes the SignatureHeader value for a delivery.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
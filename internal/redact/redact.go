@@ -0,0 +1,111 @@
+// Package redact obfuscates environment identifiers in exported reports so
+// they can be shared outside the account.
+//
+// Identifiers are replaced by keyed hashes that keep their shape: a VPC ID
+// stays a vpc- ID, an account ID stays twelve digits and an IPv4 address stays
+// an address (in the 198.18.0.0/15 benchmarking range). The same input maps to
+// the same output within one Redactor, so tables and cross-references still
+// line up. The key is random per Redactor, so short inputs like account IDs
+// can't be recovered by hashing every candidate.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// resourceIDRe matches EC2-style resource IDs, keeping the type prefix.
+	resourceIDRe = regexp.MustCompile(`\b(vpc|nat|vpce|subnet|rtb|eni|sg|igw|fl|i|eipalloc|eipassoc)-([0-9a-f]{8}|[0-9a-f]{17})\b`)
+	// arnAccountRe matches the account field of an ARN.
+	arnAccountRe = regexp.MustCompile(`(arn:aws[a-z-]*:[a-z0-9-]*:[a-z0-9-]*:)(\d{12})\b`)
+	ipv4Re       = regexp.MustCompile(`\b(25[0-5]|2[0-4]\d|1?\d?\d)(\.(25[0-5]|2[0-4]\d|1?\d?\d)){3}\b`)
+)
+
+// Redactor replaces identifiers consistently for one report.
+type Redactor struct {
+	key      []byte
+	accounts []*regexp.Regexp
+	names    []string
+}
+
+// New returns a Redactor with a random key. Account IDs that appear outside
+// ARNs are only recognized when passed here, since any twelve-digit number
+// could be one.
+func New(accountIDs ...string) (*Redactor, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate redaction key: %w", err)
+	}
+	return newWithKey(key, accountIDs...), nil
+}
+
+func newWithKey(key []byte, accountIDs ...string) *Redactor {
+	r := &Redactor{key: key}
+	for _, id := range accountIDs {
+		if id != "" {
+			r.accounts = append(r.accounts, regexp.MustCompile(`\b`+regexp.QuoteMeta(id)+`\b`))
+		}
+	}
+	return r
+}
+
+// WithNames makes r also replace names, such as the account alias and VPC
+// Name tags, which identify an environment as surely as its IDs. They become
+// name- followed by a hash.
+func (r *Redactor) WithNames(names ...string) *Redactor {
+	for _, name := range names {
+		if name != "" {
+			r.names = append(r.names, name)
+		}
+	}
+	// Longest first, so a name containing another is replaced whole.
+	sort.Slice(r.names, func(i, j int) bool { return len(r.names[i]) > len(r.names[j]) })
+	return r
+}
+
+func (r *Redactor) sum(kind, value string) []byte {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(kind + ":" + value))
+	return mac.Sum(nil)
+}
+
+func (r *Redactor) account(id string) string {
+	return fmt.Sprintf("%012d", binary.BigEndian.Uint64(r.sum("account", id))%1_000_000_000_000)
+}
+
+func (r *Redactor) resourceID(prefix, suffix string) string {
+	return prefix + "-" + hex.EncodeToString(r.sum(prefix, suffix))[:len(suffix)]
+}
+
+func (r *Redactor) ip(addr string) string {
+	n := binary.BigEndian.Uint32(r.sum("ip", addr)) % (1 << 17)
+	return fmt.Sprintf("198.%d.%d.%d", 18+(n>>16), (n>>8)&0xff, n&0xff)
+}
+
+// String redacts account IDs, resource IDs, IPv4 addresses and the names
+// given to WithNames in s. Numbers and everything else are left as they are.
+func (r *Redactor) String(s string) string {
+	for _, name := range r.names {
+		s = strings.ReplaceAll(s, name, "name-"+hex.EncodeToString(r.sum("name", name))[:8])
+	}
+	s = arnAccountRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := arnAccountRe.FindStringSubmatch(m)
+		return parts[1] + r.account(parts[2])
+	})
+	for _, re := range r.accounts {
+		s = re.ReplaceAllStringFunc(s, r.account)
+	}
+	s = resourceIDRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := resourceIDRe.FindStringSubmatch(m)
+		return r.resourceID(parts[1], parts[2])
+	})
+	return ipv4Re.ReplaceAllStringFunc(s, r.ip)
+}
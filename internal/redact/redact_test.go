@@ -0,0 +1,59 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestStringKeepsShapeAndNumbers(t *testing.T) {
+	r := newWithKey([]byte("test-key"), "123456789012")
+	in := "Account 123456789012, arn:aws:iam::123456789012:role/Audit, vpc-0a1b2c3d4e5f60718 via nat-0123456789abcdef0 from 10.0.1.25, 9876543210123 bytes, 42.5 GB"
+	out := r.String(in)
+
+	for _, leaked := range []string{"123456789012", "vpc-0a1b2c3d4e5f60718", "nat-0123456789abcdef0", "10.0.1.25"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("%q leaked into %q", leaked, out)
+		}
+	}
+	for _, kept := range []string{"9876543210123 bytes", "42.5 GB", ":role/Audit"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("%q should be kept in %q", kept, out)
+		}
+	}
+	for _, shape := range []string{`Account \d{12},`, `arn:aws:iam::\d{12}:role`, `vpc-[0-9a-f]{17} `, `nat-[0-9a-f]{17} `, `from 198\.1[89]\.\d+\.\d+,`} {
+		if !regexp.MustCompile(shape).MatchString(out) {
+			t.Errorf("expected %s in %q", shape, out)
+		}
+	}
+}
+
+func TestStringIsConsistentPerRedactor(t *testing.T) {
+	r := newWithKey([]byte("test-key"), "123456789012")
+	account := r.String("123456789012")
+	if got := r.String("arn:aws:sts::123456789012:assumed-role/x"); !strings.Contains(got, "::"+account+":") {
+		t.Errorf("ARN and standalone account IDs should redact alike, got %q and %q", account, got)
+	}
+	if r.String("vpc-0a1b2c3d") != r.String("vpc-0a1b2c3d") {
+		t.Error("the same ID should redact to the same value")
+	}
+
+	other := newWithKey([]byte("other-key"))
+	if other.String("vpc-0a1b2c3d") == r.String("vpc-0a1b2c3d") {
+		t.Error("different keys should give different values")
+	}
+}
+
+func TestStringRedactsNames(t *testing.T) {
+	r := newWithKey([]byte("test-key"), "123456789012").WithNames("payments", "payments-prod", "")
+	out := r.String("payments-prod (123456789012), vpc-0a1b2c3d (payments)")
+	if strings.Contains(out, "payments") {
+		t.Fatalf("name leaked into %q", out)
+	}
+	if !regexp.MustCompile(`^name-[0-9a-f]{8} \(\d{12}\), vpc-[0-9a-f]{8} \(name-[0-9a-f]{8}\)$`).MatchString(out) {
+		t.Fatalf("unexpected shape %q", out)
+	}
+	if r.String("payments") == r.String("payments-prod") {
+		t.Error("different names should redact differently")
+	}
+}
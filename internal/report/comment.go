@@ -0,0 +1,148 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// CommentMarker tags the PR comment termiNATor owns, so reruns edit it
+// instead of adding another.
+const CommentMarker = "<!-- terminat-report -->"
+
+// Load reads a report saved with SaveJSON.
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, path)
+}
+
+// Parse decodes a JSON report; name identifies it in errors.
+func Parse(data []byte, name string) (*Report, error) {
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s is not a termiNATor JSON report: %w", name, err)
+	}
+	return &r, nil
+}
+
+// monthlyNATCost is the projected NAT data processing cost, 0 if unknown.
+func (r *Report) monthlyNATCost() float64 {
+	if r.CostEstimate == nil {
+		return 0
+	}
+	return r.CostEstimate.CurrentMonthlyCost
+}
+
+func (r *Report) monthlySavings() float64 {
+	if r.CostEstimate == nil {
+		return 0
+	}
+	return r.CostEstimate.TotalSavingsMonthly
+}
+
+// findingKey identifies a finding across two reports of the same environment.
+func findingKey(f types.Finding) string {
+	return f.Type + "|" + f.VPCID + "|" + f.Service
+}
+
+// PRComment renders a compact markdown summary for a pull request comment.
+// With a baseline (typically the report from the base branch's environment)
+// it shows the projected cost change and which findings the change added or
+// fixed. runURL, when set, links the CI run with the full report.
+func (r *Report) PRComment(baseline *Report, runURL string) string {
+	var b strings.Builder
+	b.WriteString(CommentMarker + "\n")
+	b.WriteString("### termiNATor NAT Gateway report\n\n")
+	fmt.Fprintf(&b, "Account `%s`, region `%s`, %d-minute sample.\n\n", r.Account(r.AccountID), r.Region, r.ScanDuration)
+
+	b.WriteString("| | This change |")
+	if baseline != nil {
+		b.WriteString(" Baseline | Δ |")
+	}
+	b.WriteString("\n|---|---:|")
+	if baseline != nil {
+		b.WriteString("---:|---:|")
+	}
+	b.WriteString("\n")
+	writeRow := func(label string, cur, base float64) {
+		fmt.Fprintf(&b, "| %s | $%.2f/mo |", label, cur)
+		if baseline != nil {
+			fmt.Fprintf(&b, " $%.2f/mo | %s |", base, signedDollars(cur-base))
+		}
+		b.WriteString("\n")
+	}
+	var baseCost, baseSavings float64
+	if baseline != nil {
+		baseCost, baseSavings = baseline.monthlyNATCost(), baseline.monthlySavings()
+	}
+	writeRow("Projected NAT data processing", r.monthlyNATCost(), baseCost)
+	writeRow("Savings potential", r.monthlySavings(), baseSavings)
+	b.WriteString("\n")
+
+	if baseline != nil {
+		before := map[string]bool{}
+		for _, f := range baseline.Findings {
+			before[findingKey(f)] = true
+		}
+		after := map[string]bool{}
+		var added []types.Finding
+		for _, f := range r.Findings {
+			after[findingKey(f)] = true
+			if !before[findingKey(f)] {
+				added = append(added, f)
+			}
+		}
+		var fixed []types.Finding
+		for _, f := range baseline.Findings {
+			if !after[findingKey(f)] {
+				fixed = append(fixed, f)
+			}
+		}
+		r.writeFindings(&b, "New findings in this change", added)
+		r.writeFindings(&b, "Fixed by this change", fixed)
+		if len(added) == 0 && len(fixed) == 0 {
+			b.WriteString("No change in findings compared to the baseline.\n\n")
+		}
+	} else {
+		r.writeFindings(&b, "Findings", r.Findings)
+		if len(r.Findings) == 0 {
+			b.WriteString("No findings.\n\n")
+		}
+	}
+
+	if runURL != "" {
+		fmt.Fprintf(&b, "[Full report and remediation commands](%s)\n", runURL)
+	}
+	return b.String()
+}
+
+func (r *Report) writeFindings(b *strings.Builder, title string, findings []types.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "**%s**\n\n", title)
+	for _, f := range findings {
+		fmt.Fprintf(b, "- **[%s]** %s", strings.ToUpper(f.Severity), f.Title)
+		if f.VPCID != "" {
+			fmt.Fprintf(b, " (`%s`)", r.VPC(f.VPCID))
+		}
+		if f.SavingsEstimated {
+			fmt.Fprintf(b, " ~$%.2f/mo", f.MonthlySavings)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
+
+func signedDollars(v float64) string {
+	if v < 0 {
+		return fmt.Sprintf("-$%.2f", -v)
+	}
+	return fmt.Sprintf("+$%.2f", v)
+}
@@ -0,0 +1,54 @@
+package report
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestPRCommentAgainstBaseline(t *testing.T) {
+	s3 := types.Finding{Type: "missing-endpoint", Severity: "high", Title: "Missing S3 gateway endpoint", VPCID: "vpc-1", Service: "S3", MonthlySavings: 40, SavingsEstimated: true}
+	ddb := types.Finding{Type: "missing-endpoint", Severity: "high", Title: "Missing DynamoDB gateway endpoint", VPCID: "vpc-1", Service: "DynamoDB"}
+
+	base := &Report{AccountID: "123456789012", Region: "us-east-1", CostEstimate: &analysis.CostEstimate{CurrentMonthlyCost: 100}, Findings: []types.Finding{ddb}}
+	cur := &Report{AccountID: "123456789012", Region: "us-east-1", ScanDuration: 10, CostEstimate: &analysis.CostEstimate{CurrentMonthlyCost: 130, TotalSavingsMonthly: 40}, Findings: []types.Finding{s3}}
+
+	got := cur.PRComment(base, "https://github.com/acme/infra/actions/runs/7")
+	for _, want := range []string{
+		CommentMarker,
+		"| Projected NAT data processing | $130.00/mo | $100.00/mo | +$30.00 |",
+		"**New findings in this change**\n\n- **[HIGH]** Missing S3 gateway endpoint (`vpc-1`) ~$40.00/mo",
+		"**Fixed by this change**\n\n- **[HIGH]** Missing DynamoDB gateway endpoint",
+		"(https://github.com/acme/infra/actions/runs/7)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("comment missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPRCommentWithoutBaseline(t *testing.T) {
+	r := &Report{AccountID: "123456789012", Region: "us-east-1"}
+	got := r.PRComment(nil, "")
+	if strings.Contains(got, "Baseline") || !strings.Contains(got, "No findings.") {
+		t.Fatalf("unexpected comment:\n%s", got)
+	}
+}
+
+func TestLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "r.json")
+	r := &Report{AccountID: "123456789012", Region: "eu-west-1", Findings: []types.Finding{{Type: "missing-endpoint", VPCID: "vpc-1"}}}
+	if err := r.SaveJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Region != "eu-west-1" || len(got.Findings) != 1 || got.Findings[0].VPCID != "vpc-1" {
+		t.Fatalf("loaded %+v", got)
+	}
+}
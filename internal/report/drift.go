@@ -0,0 +1,251 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// Change is one configuration difference between two scans.
+type Change struct {
+	Kind     string `json:"kind"`     // "added", "removed" or "changed"
+	Resource string `json:"resource"` // "nat-gateway", "vpc-endpoint", "route-table", "route", "subnet-association", "subnet"
+	ID       string `json:"id"`
+	VPCID    string `json:"vpc_id,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	// Regression marks changes that undo NAT cost remediation, such as a
+	// deleted gateway endpoint or a route table losing its endpoint route.
+	Regression bool `json:"regression,omitempty"`
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf("%-8s %-18s %s", c.Kind, c.Resource, c.ID)
+	if c.VPCID != "" {
+		s += " (" + c.VPCID + ")"
+	}
+	if c.Detail != "" {
+		s += ": " + c.Detail
+	}
+	if c.Regression {
+		s += "  [REGRESSION]"
+	}
+	return s
+}
+
+// Drift compares the NAT Gateways and inventory of two reports of the same
+// account and region. Both need inventory (--include-inventory).
+func Drift(before, after *Report) ([]Change, error) {
+	if before.AccountID != after.AccountID || before.Region != after.Region {
+		return nil, fmt.Errorf("reports cover different environments: %s/%s and %s/%s", before.AccountID, before.Region, after.AccountID, after.Region)
+	}
+	for _, r := range []*Report{before, after} {
+		if len(r.Inventory) == 0 {
+			return nil, fmt.Errorf("report from %s has no inventory; rerun the deep scan with --export json --include-inventory", r.GeneratedAt.Format("2006-01-02 15:04"))
+		}
+	}
+
+	var changes []Change
+	changes = append(changes, natDrift(before.NATGateways, after.NATGateways)...)
+
+	oldVPCs, newVPCs := inventoryByVPC(before.Inventory), inventoryByVPC(after.Inventory)
+	for _, vpcID := range unionKeys(oldVPCs, newVPCs) {
+		old, cur := oldVPCs[vpcID], newVPCs[vpcID]
+		// A VPC scanned only once says nothing about drift in it.
+		if old == nil || cur == nil {
+			continue
+		}
+		changes = append(changes, endpointDrift(vpcID, old.VPCEndpoints, cur.VPCEndpoints)...)
+		changes = append(changes, routeTableDrift(vpcID, old.RouteTables, cur.RouteTables)...)
+		changes = append(changes, subnetDrift(vpcID, old.Subnets, cur.Subnets)...)
+	}
+	return changes, nil
+}
+
+func natDrift(before, after []types.NATGateway) []Change {
+	old := map[string]types.NATGateway{}
+	for _, n := range before {
+		old[n.ID] = n
+	}
+	cur := map[string]types.NATGateway{}
+	for _, n := range after {
+		cur[n.ID] = n
+	}
+	var changes []Change
+	for _, id := range unionKeys(old, cur) {
+		o, inOld := old[id]
+		n, inNew := cur[id]
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Kind: "added", Resource: "nat-gateway", ID: id, VPCID: n.VPCID, Detail: n.AvailabilityZone})
+		case !inNew:
+			changes = append(changes, Change{Kind: "removed", Resource: "nat-gateway", ID: id, VPCID: o.VPCID})
+		case o.State != n.State:
+			changes = append(changes, Change{Kind: "changed", Resource: "nat-gateway", ID: id, VPCID: n.VPCID, Detail: fmt.Sprintf("state %s → %s", o.State, n.State)})
+		}
+	}
+	return changes
+}
+
+func endpointDrift(vpcID string, before, after []types.VPCEndpoint) []Change {
+	old := map[string]types.VPCEndpoint{}
+	for _, e := range before {
+		old[e.ID] = e
+	}
+	cur := map[string]types.VPCEndpoint{}
+	for _, e := range after {
+		cur[e.ID] = e
+	}
+	var changes []Change
+	for _, id := range unionKeys(old, cur) {
+		o, inOld := old[id]
+		n, inNew := cur[id]
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Kind: "added", Resource: "vpc-endpoint", ID: id, VPCID: vpcID, Detail: n.Type + " " + n.ServiceName})
+		case !inNew:
+			changes = append(changes, Change{Kind: "removed", Resource: "vpc-endpoint", ID: id, VPCID: vpcID, Detail: o.Type + " " + o.ServiceName, Regression: true})
+		default:
+			if added, removed := diffStrings(o.RouteTables, n.RouteTables); len(added)+len(removed) > 0 {
+				changes = append(changes, Change{Kind: "changed", Resource: "vpc-endpoint", ID: id, VPCID: vpcID,
+					Detail: describeSetChange("route tables", added, removed), Regression: len(removed) > 0})
+			}
+			if added, removed := diffStrings(o.SubnetIDs, n.SubnetIDs); len(added)+len(removed) > 0 {
+				changes = append(changes, Change{Kind: "changed", Resource: "vpc-endpoint", ID: id, VPCID: vpcID,
+					Detail: describeSetChange("subnets", added, removed), Regression: len(removed) > 0})
+			}
+			if o.PrivateDNS && !n.PrivateDNS {
+				changes = append(changes, Change{Kind: "changed", Resource: "vpc-endpoint", ID: id, VPCID: vpcID, Detail: "private DNS disabled", Regression: true})
+			}
+		}
+	}
+	return changes
+}
+
+func routeTableDrift(vpcID string, before, after []types.RouteTable) []Change {
+	old := map[string]types.RouteTable{}
+	for _, rt := range before {
+		old[rt.ID] = rt
+	}
+	cur := map[string]types.RouteTable{}
+	for _, rt := range after {
+		cur[rt.ID] = rt
+	}
+	var changes []Change
+	for _, id := range unionKeys(old, cur) {
+		o, inOld := old[id]
+		n, inNew := cur[id]
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Kind: "added", Resource: "route-table", ID: id, VPCID: vpcID})
+			continue
+		case !inNew:
+			changes = append(changes, Change{Kind: "removed", Resource: "route-table", ID: id, VPCID: vpcID})
+			continue
+		}
+
+		oldRoutes, newRoutes := routeKeys(o.Routes), routeKeys(n.Routes)
+		added, removed := diffStrings(oldRoutes, newRoutes)
+		for _, r := range added {
+			changes = append(changes, Change{Kind: "added", Resource: "route", ID: id, VPCID: vpcID, Detail: r})
+		}
+		for _, r := range removed {
+			changes = append(changes, Change{Kind: "removed", Resource: "route", ID: id, VPCID: vpcID, Detail: r, Regression: strings.Contains(r, "vpce-")})
+		}
+
+		added, removed = diffStrings(o.Subnets, n.Subnets)
+		for _, s := range added {
+			changes = append(changes, Change{Kind: "added", Resource: "subnet-association", ID: id, VPCID: vpcID, Detail: s})
+		}
+		for _, s := range removed {
+			changes = append(changes, Change{Kind: "removed", Resource: "subnet-association", ID: id, VPCID: vpcID, Detail: s})
+		}
+	}
+	return changes
+}
+
+func subnetDrift(vpcID string, before, after []types.Subnet) []Change {
+	old := map[string]types.Subnet{}
+	for _, s := range before {
+		old[s.ID] = s
+	}
+	cur := map[string]types.Subnet{}
+	for _, s := range after {
+		cur[s.ID] = s
+	}
+	var changes []Change
+	for _, id := range unionKeys(old, cur) {
+		if _, ok := old[id]; !ok {
+			changes = append(changes, Change{Kind: "added", Resource: "subnet", ID: id, VPCID: vpcID, Detail: cur[id].CIDR})
+		} else if _, ok := cur[id]; !ok {
+			changes = append(changes, Change{Kind: "removed", Resource: "subnet", ID: id, VPCID: vpcID, Detail: old[id].CIDR})
+		}
+	}
+	return changes
+}
+
+func inventoryByVPC(inv []types.VPCInventory) map[string]*types.VPCInventory {
+	m := map[string]*types.VPCInventory{}
+	for i := range inv {
+		m[inv[i].VPCID] = &inv[i]
+	}
+	return m
+}
+
+func routeKeys(routes []types.Route) []string {
+	keys := make([]string, 0, len(routes))
+	for _, r := range routes {
+		keys = append(keys, r.DestinationCIDR+" → "+r.Target)
+	}
+	return keys
+}
+
+// diffStrings returns the values only in after and only in before, sorted.
+func diffStrings(before, after []string) (added, removed []string) {
+	inBefore := map[string]bool{}
+	for _, s := range before {
+		inBefore[s] = true
+	}
+	inAfter := map[string]bool{}
+	for _, s := range after {
+		inAfter[s] = true
+		if !inBefore[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range before {
+		if !inAfter[s] {
+			removed = append(removed, s)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func describeSetChange(what string, added, removed []string) string {
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, fmt.Sprintf("%s added: %s", what, strings.Join(added, ", ")))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, fmt.Sprintf("%s removed: %s", what, strings.Join(removed, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
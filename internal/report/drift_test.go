@@ -0,0 +1,89 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func driftReports() (*Report, *Report) {
+	before := &Report{AccountID: "123456789012", Region: "us-east-1",
+		NATGateways: []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1", State: "available"}},
+		Inventory: []types.VPCInventory{{
+			VPCID: "vpc-1",
+			VPCEndpoints: []types.VPCEndpoint{
+				{ID: "vpce-s3", Type: "Gateway", ServiceName: "com.amazonaws.us-east-1.s3", RouteTables: []string{"rtb-1", "rtb-2"}},
+				{ID: "vpce-ddb", Type: "Gateway", ServiceName: "com.amazonaws.us-east-1.dynamodb", RouteTables: []string{"rtb-1"}},
+			},
+			RouteTables: []types.RouteTable{{ID: "rtb-1", Subnets: []string{"subnet-1"}, Routes: []types.Route{
+				{DestinationCIDR: "0.0.0.0/0", Target: "nat-1"},
+				{DestinationCIDR: "pl-63a5400a", Target: "vpce-ddb"},
+			}}},
+			Subnets: []types.Subnet{{ID: "subnet-1", CIDR: "10.0.1.0/24"}},
+		}},
+	}
+	after := &Report{AccountID: "123456789012", Region: "us-east-1",
+		NATGateways: []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1", State: "available"}, {ID: "nat-2", VPCID: "vpc-1", State: "available"}},
+		Inventory: []types.VPCInventory{{
+			VPCID: "vpc-1",
+			VPCEndpoints: []types.VPCEndpoint{
+				{ID: "vpce-s3", Type: "Gateway", ServiceName: "com.amazonaws.us-east-1.s3", RouteTables: []string{"rtb-1"}},
+			},
+			RouteTables: []types.RouteTable{{ID: "rtb-1", Subnets: []string{"subnet-1", "subnet-2"}, Routes: []types.Route{
+				{DestinationCIDR: "0.0.0.0/0", Target: "nat-1"},
+			}}},
+			Subnets: []types.Subnet{{ID: "subnet-1", CIDR: "10.0.1.0/24"}, {ID: "subnet-2", CIDR: "10.0.2.0/24"}},
+		}},
+	}
+	return before, after
+}
+
+func TestDrift(t *testing.T) {
+	before, after := driftReports()
+	changes, err := Drift(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	regressions := 0
+	for _, c := range changes {
+		lines = append(lines, c.String())
+		if c.Regression {
+			regressions++
+		}
+	}
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"added    nat-gateway        nat-2",
+		"removed  vpc-endpoint       vpce-ddb (vpc-1): Gateway com.amazonaws.us-east-1.dynamodb  [REGRESSION]",
+		"changed  vpc-endpoint       vpce-s3 (vpc-1): route tables removed: rtb-2  [REGRESSION]",
+		"removed  route              rtb-1 (vpc-1): pl-63a5400a → vpce-ddb  [REGRESSION]",
+		"added    subnet-association rtb-1 (vpc-1): subnet-2",
+		"added    subnet             subnet-2 (vpc-1): 10.0.2.0/24",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("drift missing %q:\n%s", want, got)
+		}
+	}
+	if regressions != 3 {
+		t.Errorf("regressions = %d, want 3:\n%s", regressions, got)
+	}
+
+	if changes, _ := Drift(after, after); len(changes) != 0 {
+		t.Errorf("identical reports should not drift: %v", changes)
+	}
+}
+
+func TestDriftNeedsInventoryAndSameEnvironment(t *testing.T) {
+	before, after := driftReports()
+	after.Inventory = nil
+	if _, err := Drift(before, after); err == nil || !strings.Contains(err.Error(), "--include-inventory") {
+		t.Fatalf("expected missing inventory error, got %v", err)
+	}
+	before, after = driftReports()
+	after.Region = "eu-west-1"
+	if _, err := Drift(before, after); err == nil {
+		t.Fatal("expected different environment error")
+	}
+}
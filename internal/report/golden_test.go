@@ -0,0 +1,33 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/doitintl/terminator/internal/fixtures"
+	"github.com/doitintl/terminator/internal/testutil/golden"
+)
+
+func fixtureReport(s fixtures.Scan) *Report {
+	r := New(fixtures.Region, fixtures.AccountID, s.Duration, s.NATs, s.Stats, s.Cost, s.Endpoints)
+	r.GeneratedAt = fixtures.GeneratedAt
+	r.Findings = s.Findings
+	return r
+}
+
+func TestRenderGolden(t *testing.T) {
+	for _, s := range fixtures.All() {
+		t.Run(s.Name, func(t *testing.T) {
+			r := fixtureReport(s)
+			var md, js bytes.Buffer
+			if err := r.WriteMarkdown(&md); err != nil {
+				t.Fatal(err)
+			}
+			golden.Check(t, s.Name+".md", md.Bytes())
+			if err := r.WriteJSON(&js); err != nil {
+				t.Fatal(err)
+			}
+			golden.Check(t, s.Name+".json", js.Bytes())
+		})
+	}
+}
@@ -1,28 +1,107 @@
 package report
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/console"
+	"github.com/doitintl/terminator/internal/i18n"
+	"github.com/doitintl/terminator/internal/redact"
+	"github.com/doitintl/terminator/internal/units"
 	"github.com/doitintl/terminator/pkg/types"
 )
 
 type Report struct {
-	GeneratedAt      time.Time                  `json:"generated_at"`
-	Region           string                     `json:"region"`
-	AccountID        string                     `json:"account_id"`
-	ScanDuration     int                        `json:"scan_duration_minutes"`
-	NATGateways      []types.NATGateway         `json:"nat_gateways,omitempty"`
-	TrafficStats     *analysis.TrafficStats     `json:"traffic_stats,omitempty"`
-	CostEstimate     *analysis.CostEstimate     `json:"cost_estimate,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Region      string    `json:"region"`
+	AccountID   string    `json:"account_id"`
+	// FriendlyNames label the account and VPC IDs where they are shown.
+	types.FriendlyNames
+	ScanDuration int `json:"scan_duration_minutes"`
+	// DurationPreset is the --duration preset the scan used, if any.
+	DurationPreset *analysis.DurationPreset `json:"duration_preset,omitempty"`
+	// CollectionWindow is when the sample was collected.
+	CollectionWindow *CollectionWindow `json:"collection_window,omitempty"`
+	// LogGroupName is the CloudWatch Logs log group the sample was read from.
+	LogGroupName string             `json:"log_group_name,omitempty"`
+	NATGateways  []types.NATGateway `json:"nat_gateways,omitempty"`
+	// ScanCoverage says which NAT Gateways and VPCs the sample measured.
+	ScanCoverage *analysis.ScanCoverage `json:"scan_coverage,omitempty"`
+	TrafficStats *analysis.TrafficStats `json:"traffic_stats,omitempty"`
+	CostEstimate *analysis.CostEstimate `json:"cost_estimate,omitempty"`
+	// Charges itemize which charges of the S3 and DynamoDB traffic the
+	// gateway endpoints remove.
+	Charges          []analysis.ChargeComponent `json:"charge_components,omitempty"`
 	EndpointAnalysis *analysis.EndpointAnalysis `json:"endpoint_analysis,omitempty"`
+	ScanCost         *analysis.ScanCost         `json:"scan_cost,omitempty"`
+	AZTraffic        []analysis.AZTraffic       `json:"az_traffic,omitempty"`
+	NATCosts         []analysis.NATCost         `json:"nat_costs,omitempty"`
+	S3Attribution    []analysis.S3Attribution   `json:"s3_attribution,omitempty"`
+	// DynamoDBEndpoints are DynamoDB hostnames from Route 53 Resolver query logs.
+	DynamoDBEndpoints []analysis.DynamoDBEndpoint    `json:"dynamodb_endpoints,omitempty"`
+	RegistryPulls     *analysis.RegistryPullEstimate `json:"registry_pulls,omitempty"`
+	EKSBundle         *analysis.EKSBundleEstimate    `json:"eks_bundle,omitempty"`
+	// PublicIPv4 prices the NAT Gateways' public IPv4 addresses.
+	PublicIPv4 *analysis.PublicIPv4Estimate `json:"public_ipv4,omitempty"`
+	// EndpointCases weigh paid interface endpoints against the NAT cost they avoid.
+	EndpointCases []*analysis.InterfaceEndpointCase `json:"interface_endpoint_cases,omitempty"`
+	Findings      []types.Finding                   `json:"findings,omitempty"`
+	// VPCRemediation fixes the endpoint findings of the VPCs other than
+	// EndpointAnalysis's.
+	VPCRemediation []analysis.VPCRemediation `json:"vpc_remediation,omitempty"`
+	// Inventory is the discovered configuration of the scanned VPCs (--include-inventory).
+	Inventory []types.VPCInventory `json:"inventory,omitempty"`
+	// Subnet is set when the scan covered one workload subnet (--subnet-id)
+	// or network interface (--eni-id).
+	Subnet *types.SubnetEgress `json:"subnet,omitempty"`
+	// EndpointENI is the projection of an --eni-id scan of an interface endpoint.
+	EndpointENI     *analysis.EndpointENITraffic `json:"endpoint_eni,omitempty"`
+	Recommendations []analysis.Recommendation    `json:"recommendations,omitempty"`
+	// MinSavings is the --min-savings threshold; HiddenBelowMinSavings counts what it hid.
+	MinSavings            float64 `json:"min_savings,omitempty"`
+	HiddenBelowMinSavings int     `json:"hidden_below_min_savings,omitempty"`
+	// HiddenByBaseline counts findings hidden as accepted in the findings baseline.
+	HiddenByBaseline int `json:"hidden_by_baseline,omitempty"`
+	// CostAnomalies are the NAT Gateway cost anomalies of the last
+	// CostAnomalyDays days; the billing context is only shown when they were
+	// looked up.
+	CostAnomalies   []types.CostAnomaly `json:"cost_anomalies,omitempty"`
+	CostAnomalyDays int                 `json:"cost_anomaly_days,omitempty"`
+	// RejectedTraffic summarizes the sample's REJECT flows (--include-rejected).
+	RejectedTraffic *analysis.RejectedTraffic `json:"rejected_traffic,omitempty"`
+	// SampleCoverage is how complete the Flow Logs sample is.
+	SampleCoverage *analysis.SampleCoverage `json:"sample_coverage,omitempty"`
+	// ClassificationCheck compares the IP-range classification with the
+	// service Flow Logs recorded for each destination.
+	ClassificationCheck *analysis.ClassificationCheck `json:"classification_check,omitempty"`
+	// Methodology says how the figures were derived; the markdown report
+	// prints it as an appendix.
+	Methodology *analysis.Methodology `json:"methodology,omitempty"`
+	// Lang is the markdown report language (see i18n.Languages); JSON stays English.
+	Lang string `json:"-"`
+	// Redact obfuscates account, resource IDs and IPs in saved reports.
+	Redact bool `json:"-"`
+	// MaxRows caps the rows of each markdown table (0 = all); the JSON
+	// report keeps every row.
+	MaxRows int `json:"-"`
 }
 
+// maxPortRows caps the Traffic by Port table; the JSON report keeps every port.
+const maxPortRows = 10
+
 func New(region, accountID string, duration int, nats []types.NATGateway, stats *analysis.TrafficStats, cost *analysis.CostEstimate, endpoints *analysis.EndpointAnalysis) *Report {
+	var charges []analysis.ChargeComponent
+	if cost != nil {
+		charges = cost.ChargeComponents()
+	}
 	return &Report{
 		GeneratedAt:      time.Now(),
 		Region:           region,
@@ -31,95 +110,350 @@ func New(region, accountID string, duration int, nats []types.NATGateway, stats
 		NATGateways:      nats,
 		TrafficStats:     stats,
 		CostEstimate:     cost,
+		Charges:          charges,
 		EndpointAnalysis: endpoints,
 	}
 }
 
 func (r *Report) SaveJSON(path string) error {
-	data, err := json.MarshalIndent(r, "", "  ")
+	return r.save(path, r.WriteJSON)
+}
+
+func (r *Report) SaveMarkdown(path string) error {
+	return r.save(path, r.WriteMarkdown)
+}
+
+// save streams a report to path with write.
+func (r *Report) save(path string, write func(io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	bw := bufio.NewWriter(f)
+	if err := write(bw); err != nil {
+		f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
-func (r *Report) SaveMarkdown(path string) error {
-	return os.WriteFile(path, []byte(r.ToMarkdown()), 0644)
+// WriteJSON encodes the report to w, redacted when Redact is set.
+func (r *Report) WriteJSON(w io.Writer) error {
+	out, err := r.redacting(w)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// WriteMarkdown renders the markdown report to w as it goes, redacted when
+// Redact is set.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	out, err := r.redacting(w)
+	if err != nil {
+		return err
+	}
+	b := &mdWriter{w: out}
+	r.writeMarkdown(b, r.Redact)
+	if b.err != nil {
+		return b.err
+	}
+	return out.Close()
+}
+
+// ToMarkdown returns the markdown report, unredacted.
+func (r *Report) ToMarkdown() string {
+	var sb strings.Builder
+	r.writeMarkdown(&mdWriter{w: &sb}, false)
+	return sb.String()
+}
+
+// redacting returns w, or when Redact is set, a writer redacting what
+// passes through it line by line; identifiers never span lines. Close
+// flushes a last unterminated line.
+func (r *Report) redacting(w io.Writer) (io.WriteCloser, error) {
+	if !r.Redact {
+		return nopCloser{w}, nil
+	}
+	red, err := redact.New(r.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	return &lineRedactor{w: w, red: red.WithNames(r.Names()...)}, nil
+}
+
+// Names lists the account, VPC and NAT Gateway names in the report, for
+// redaction.
+func (r *Report) Names() []string {
+	return append(r.FriendlyNames.All(), types.NATNames(r.NATGateways)...)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+type lineRedactor struct {
+	w       io.Writer
+	red     *redact.Redactor
+	pending []byte
+}
+
+func (l *lineRedactor) Write(p []byte) (int, error) {
+	l.pending = append(l.pending, p...)
+	if i := bytes.LastIndexByte(l.pending, '\n'); i >= 0 {
+		if _, err := io.WriteString(l.w, l.red.String(string(l.pending[:i+1]))); err != nil {
+			return 0, err
+		}
+		l.pending = append(l.pending[:0], l.pending[i+1:]...)
+	}
+	return len(p), nil
+}
+
+func (l *lineRedactor) Close() error {
+	if len(l.pending) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(l.w, l.red.String(string(l.pending)))
+	l.pending = l.pending[:0]
+	return err
+}
+
+// natLabels joins the labels of the NAT Gateways with ids.
+func (r *Report) natLabels(ids []string) string {
+	labels := make([]string, 0, len(ids))
+	for _, id := range ids {
+		labels = append(labels, types.NATLabel(r.NATGateways, id))
+	}
+	return strings.Join(labels, ", ")
+}
+
+// consoleLink links text to url in the AWS console. Redacted reports, whose
+// links would not resolve, and resources without a console page keep text.
+func (r *Report) consoleLink(text, url string) string {
+	if r.Redact || url == "" {
+		return text
+	}
+	return "[" + text + "](" + url + ")"
+}
+
+// endpointLink is an endpoint ID linked to its console page.
+func (r *Report) endpointLink(id string) string {
+	return r.consoleLink(id, console.URL(r.Region, id))
+}
+
+// consoleLinks links each resource of ids that has a console page, once,
+// comma-separated; "" when there is none.
+func (r *Report) consoleLinks(ids ...string) string {
+	if r.Redact {
+		return ""
+	}
+	var links []string
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if url := console.URL(r.Region, id); url != "" && !seen[id] {
+			seen[id] = true
+			links = append(links, r.consoleLink("`"+id+"`", url))
+		}
+	}
+	return strings.Join(links, ", ")
+}
+
+// mdWriter writes the markdown report, keeping the first error.
+type mdWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (m *mdWriter) WriteString(s string) {
+	if m.err == nil {
+		_, m.err = io.WriteString(m.w, s)
+	}
+}
+
+// capped reports whether row i (0-based) of a table of n rows is past
+// MaxRows (or limit, when lower and positive), and if so writes the row
+// counting the rest, with empty cells up to cols columns.
+func (r *Report) capped(b *mdWriter, i, n, limit, cols int) bool {
+	max := r.MaxRows
+	if limit > 0 && (max <= 0 || limit < max) {
+		max = limit
+	}
+	if max <= 0 || i < max {
+		return false
+	}
+	b.WriteString(fmt.Sprintf("| … | %d more |%s\n", n-i, strings.Repeat(" |", cols-2)))
+	return true
+}
+
+// AnomaliesDuringSample returns the cost anomalies whose dates include the
+// day the traffic sample was collected.
+func (r *Report) AnomaliesDuringSample() []types.CostAnomaly {
+	start := r.GeneratedAt.Add(-time.Duration(r.ScanDuration) * time.Minute)
+	var during []types.CostAnomaly
+	for _, a := range r.CostAnomalies {
+		if a.During(start, r.GeneratedAt) {
+			during = append(during, a)
+		}
+	}
+	return during
 }
 
 func (r *Report) estimateMonthlyECRDataGB() float64 {
-	if r.TrafficStats == nil || r.TrafficStats.ECRBytes <= 0 {
+	if r.TrafficStats == nil || r.TrafficStats.Bytes(analysis.ServiceECR) <= 0 {
 		return 0
 	}
 
 	if r.CostEstimate != nil && r.CostEstimate.OtherPercentage() > 0 {
-		return r.CostEstimate.OtherDataGB * (r.TrafficStats.ECRPercentage() / r.CostEstimate.OtherPercentage())
+		return r.CostEstimate.OtherDataGB * (r.TrafficStats.Percentage(analysis.ServiceECR) / r.CostEstimate.OtherPercentage())
 	}
 
 	// Fallback if cost estimate is unavailable.
-	sampleECRGB := float64(r.TrafficStats.ECRBytes) / (1024 * 1024 * 1024)
+	sampleECRGB := units.BillingGB(r.TrafficStats.Bytes(analysis.ServiceECR))
 	return sampleECRGB * (43200.0 / float64(r.ScanDuration))
 }
 
 func (r *Report) estimateMonthlyECRNATCost() float64 {
-	if r.CostEstimate == nil || r.TrafficStats == nil || r.TrafficStats.ECRBytes <= 0 || r.CostEstimate.OtherPercentage() <= 0 {
+	if r.CostEstimate == nil || r.TrafficStats == nil || r.TrafficStats.Bytes(analysis.ServiceECR) <= 0 || r.CostEstimate.OtherPercentage() <= 0 {
 		return 0
 	}
-	return r.CostEstimate.OtherDataGB * r.CostEstimate.NATGatewayPricePerGB * (r.TrafficStats.ECRPercentage() / r.CostEstimate.OtherPercentage())
+	return r.CostEstimate.OtherDataGB * r.CostEstimate.NATGatewayPricePerGB * (r.TrafficStats.Percentage(analysis.ServiceECR) / r.CostEstimate.OtherPercentage())
 }
 
-func (r *Report) ToMarkdown() string {
-	var b strings.Builder
+// writeMarkdown renders the report; redactNote says the identifiers in it
+// are redacted.
+func (r *Report) writeMarkdown(b *mdWriter, redactNote bool) {
+	// Lang is validated when flags are parsed; a bad value falls back to English
+	t, _ := i18n.New(r.Lang)
 
-	b.WriteString("# termiNATor Deep Dive Report\n\n")
-	b.WriteString(fmt.Sprintf("**Generated:** %s  \n", r.GeneratedAt.Format(time.RFC1123)))
-	b.WriteString(fmt.Sprintf("**Region:** %s  \n", r.Region))
-	b.WriteString(fmt.Sprintf("**Account:** %s  \n", r.AccountID))
-	b.WriteString(fmt.Sprintf("**Sample Duration:** %d minutes\n\n", r.ScanDuration))
+	b.WriteString("# " + t.Text("termiNATor Deep Dive Report") + "\n\n")
+	if redactNote {
+		b.WriteString("> Account IDs, resource IDs, names and IP addresses in this report are redacted.\n\n")
+	}
+	generated := r.GeneratedAt.UTC().Format(time.RFC1123)
+	if w := r.CollectionWindow; w != nil && !isUTC(w.TimeZone) {
+		generated += " (" + w.Local(r.GeneratedAt) + ")"
+	}
+	b.WriteString(t.Sprintf("**Generated:** %s", generated) + "  \n")
+	b.WriteString(t.Sprintf("**Region:** %s", r.Region) + "  \n")
+	b.WriteString(t.Sprintf("**Account:** %s", r.Account(r.AccountID)) + "  \n")
+	b.WriteString(t.Sprintf("**Sample Duration:** %d minutes", r.ScanDuration))
+	if r.CollectionWindow != nil {
+		b.WriteString("  \n" + t.Sprintf("**Collection Window:** %s", r.CollectionWindow))
+	}
+	if p := r.DurationPreset; p != nil {
+		b.WriteString("  \n" + t.Sprintf("**Duration Preset:** %s (expected accuracy: %s)", p, p.Accuracy))
+	}
+	if r.LogGroupName != "" {
+		b.WriteString("  \n" + t.Sprintf("**Log Group:** %s", r.consoleLink(r.LogGroupName, console.LogGroupURL(r.Region, r.LogGroupName))))
+	}
+	b.WriteString("\n\n")
+
+	if r.CostAnomalyDays > 0 {
+		b.WriteString("## " + t.Text("Billing Context") + "\n\n")
+		if len(r.CostAnomalies) == 0 {
+			b.WriteString(t.Sprintf("No NAT Gateway cost anomalies in the last %d days.", r.CostAnomalyDays) + "\n\n")
+		} else {
+			b.WriteString(t.Sprintf("NAT Gateway cost anomalies in the last %d days (AWS Cost Anomaly Detection):", r.CostAnomalyDays) + "\n\n")
+			b.WriteString("| Start | End | Usage types | Impact | Expected | Actual |\n")
+			b.WriteString("|-------|-----|-------------|--------|----------|--------|\n")
+			for _, a := range r.CostAnomalies {
+				end := a.EndDate
+				if end == "" {
+					end = "ongoing"
+				}
+				b.WriteString(fmt.Sprintf("| %s | %s | %s | $%.2f | $%.2f | $%.2f |\n",
+					a.StartDate, end, strings.Join(a.UsageTypes, ", "), a.TotalImpact, a.ExpectedSpend, a.ActualSpend))
+			}
+			b.WriteString("\n")
+			if len(r.AnomaliesDuringSample()) > 0 {
+				b.WriteString("> ⚠️ " + t.Text("The traffic sample was collected during a NAT Gateway cost anomaly; projections may not reflect usual traffic.") + "\n\n")
+			}
+		}
+	}
 
 	// Executive Summary
 	if r.CostEstimate != nil && r.CostEstimate.TotalSavingsMonthly > 0 {
-		b.WriteString("## 💰 Executive Summary\n\n")
-		b.WriteString(fmt.Sprintf("**Potential Monthly Savings: $%.2f** ($%.2f/year)\n\n",
-			r.CostEstimate.TotalSavingsMonthly, r.CostEstimate.TotalSavingsMonthly*12))
-		b.WriteString("> ⚠️ Estimates projected from traffic sample. Actual savings depend on real traffic patterns.\n\n")
+		b.WriteString("## 💰 " + t.Text("Executive Summary") + "\n\n")
+		b.WriteString(t.Sprintf("**Potential Monthly Savings: $%.2f** ($%.2f/year)",
+			r.CostEstimate.TotalSavingsMonthly, r.CostEstimate.TotalSavingsMonthly*12) + "\n\n")
+		b.WriteString("> ⚠️ " + t.Text("Estimates projected from traffic sample. Actual savings depend on real traffic patterns.") + "\n\n")
 	}
 
 	if len(r.NATGateways) > 0 {
-		b.WriteString("## NAT Gateway Topology\n\n")
-		b.WriteString("| NAT Gateway | Mode | VPC | Subnet |\n")
-		b.WriteString("|-------------|------|-----|--------|\n")
-		for _, nat := range r.NATGateways {
+		b.WriteString("## " + t.Text("NAT Gateway Topology") + "\n\n")
+		b.WriteString("| NAT Gateway | Mode | VPC | Subnet | Created | Console |\n")
+		b.WriteString("|-------------|------|-----|--------|---------|---------|\n")
+		for i, nat := range r.NATGateways {
+			if r.capped(b, i, len(r.NATGateways), 0, 5) {
+				break
+			}
 			mode := nat.AvailabilityMode
 			if mode == "" {
 				mode = "zonal"
 			}
-			b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", nat.ID, mode, nat.VPCID, nat.SubnetID))
+			link := "-"
+			if url := console.URL(r.Region, nat.ID); url != "" && !r.Redact {
+				link = r.consoleLink("open", url)
+			}
+			created := analysis.NATCreated(nat, r.GeneratedAt)
+			if created == "" {
+				created = "-"
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n", nat.Label(), mode, r.VPC(nat.VPCID), nat.SubnetID, created, link))
+		}
+		b.WriteString("\n")
+	}
+
+	if c := r.ScanCoverage; c != nil {
+		b.WriteString("## " + t.Text("Scan Coverage") + "\n\n")
+		b.WriteString(fmt.Sprintf("**Source:** %s\n\n", c.Source))
+		b.WriteString("| VPC | Coverage | Details |\n")
+		b.WriteString("|-----|----------|---------|\n")
+		for _, v := range c.VPCs {
+			b.WriteString(fmt.Sprintf("| %s | %s | %s |\n", r.VPC(v.ID), v.Status, v.Reason))
+		}
+		b.WriteString("\n| NAT Gateway | VPC | Coverage | Details |\n")
+		b.WriteString("|-------------|-----|----------|---------|\n")
+		for _, n := range c.NATGateways {
+			reason := n.Reason
+			if reason == "" {
+				reason = "-"
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", types.NATLabel(r.NATGateways, n.ID), r.VPC(n.VPCID), n.Status, reason))
 		}
 		b.WriteString("\n")
 	}
 
 	// VPC Endpoint Status
 	if r.EndpointAnalysis != nil {
-		b.WriteString("## VPC Endpoint Configuration\n\n")
-		b.WriteString(fmt.Sprintf("**VPC:** %s\n\n", r.EndpointAnalysis.VPCID))
+		b.WriteString("## " + t.Text("VPC Endpoint Configuration") + "\n\n")
+		b.WriteString(t.Sprintf("**VPC:** %s", r.VPC(r.EndpointAnalysis.VPCID)) + "\n\n")
 
-		b.WriteString("### Gateway Endpoints\n\n")
+		b.WriteString("### " + t.Text("Gateway Endpoints") + "\n\n")
 		b.WriteString("| Service | Status | Endpoint ID |\n")
 		b.WriteString("|---------|--------|-------------|\n")
 		if r.EndpointAnalysis.S3Endpoint != nil {
-			b.WriteString(fmt.Sprintf("| S3 | ✅ Configured | %s |\n", r.EndpointAnalysis.S3Endpoint.ID))
+			b.WriteString(fmt.Sprintf("| S3 | ✅ Configured | %s |\n", r.endpointLink(r.EndpointAnalysis.S3Endpoint.ID)))
 		} else {
 			b.WriteString("| S3 | ❌ Missing | - |\n")
 		}
 		if r.EndpointAnalysis.DynamoEndpoint != nil {
-			b.WriteString(fmt.Sprintf("| DynamoDB | ✅ Configured | %s |\n", r.EndpointAnalysis.DynamoEndpoint.ID))
+			b.WriteString(fmt.Sprintf("| DynamoDB | ✅ Configured | %s |\n", r.endpointLink(r.EndpointAnalysis.DynamoEndpoint.ID)))
 		} else {
 			b.WriteString("| DynamoDB | ❌ Missing | - |\n")
 		}
 		b.WriteString("\n")
 
-		b.WriteString("### ECR Interface Endpoints (Paid)\n\n")
+		b.WriteString("### " + t.Text("ECR Interface Endpoints (Paid)") + "\n\n")
 		ecrHourlyPerAZ, ecrDataPerGB := r.EndpointAnalysis.GetECRInterfaceEndpointPricing()
 		b.WriteString(fmt.Sprintf("> Regional price estimate for `%s`: **$%.4f per AZ-hour** + **$%.4f per GB**\n\n",
 			r.Region,
@@ -129,21 +463,21 @@ func (r *Report) ToMarkdown() string {
 		b.WriteString("| Service | Status | Endpoint ID |\n")
 		b.WriteString("|---------|--------|-------------|\n")
 		if r.EndpointAnalysis.ECRAPIEndpoint != nil {
-			b.WriteString(fmt.Sprintf("| ECR API (`ecr.api`) | ✅ Configured | %s |\n", r.EndpointAnalysis.ECRAPIEndpoint.ID))
+			b.WriteString(fmt.Sprintf("| ECR API (`ecr.api`) | ✅ Configured | %s |\n", r.endpointLink(r.EndpointAnalysis.ECRAPIEndpoint.ID)))
 		} else {
 			b.WriteString("| ECR API (`ecr.api`) | ⚠️ Missing (optional, paid) | - |\n")
 		}
 		if r.EndpointAnalysis.ECRDKREndpoint != nil {
-			b.WriteString(fmt.Sprintf("| ECR DKR (`ecr.dkr`) | ✅ Configured | %s |\n", r.EndpointAnalysis.ECRDKREndpoint.ID))
+			b.WriteString(fmt.Sprintf("| ECR DKR (`ecr.dkr`) | ✅ Configured | %s |\n", r.endpointLink(r.EndpointAnalysis.ECRDKREndpoint.ID)))
 		} else {
 			b.WriteString("| ECR DKR (`ecr.dkr`) | ⚠️ Missing (optional, paid) | - |\n")
 		}
 		b.WriteString("\n")
 
 		if len(r.EndpointAnalysis.MissingRoutes) > 0 {
-			b.WriteString("### Missing Route Table Associations\n\n")
+			b.WriteString("### " + t.Text("Missing Route Table Associations") + "\n\n")
 			for _, mr := range r.EndpointAnalysis.MissingRoutes {
-				b.WriteString(fmt.Sprintf("- `%s`: missing %s route\n", mr.RouteTableID, mr.Service))
+				b.WriteString(fmt.Sprintf("- %s: missing %s route\n", r.consoleLink("`"+mr.RouteTableID+"`", console.URL(r.Region, mr.RouteTableID)), mr.Service))
 			}
 			b.WriteString("\n")
 		}
@@ -151,26 +485,116 @@ func (r *Report) ToMarkdown() string {
 
 	// Traffic Analysis
 	if r.TrafficStats != nil && r.TrafficStats.TotalRecords > 0 {
-		b.WriteString("## Collected Traffic Sample\n\n")
-		b.WriteString(fmt.Sprintf("**Total:** %d records, %.2f GB\n\n",
-			r.TrafficStats.TotalRecords, float64(r.TrafficStats.TotalBytes)/(1024*1024*1024)))
-
-		b.WriteString("| Service | Data (GB) | Percentage |\n")
-		b.WriteString("|---------|-----------|------------|\n")
-		b.WriteString(fmt.Sprintf("| S3 | %.2f | %.1f%% |\n",
-			float64(r.TrafficStats.S3Bytes)/(1024*1024*1024), r.TrafficStats.S3Percentage()))
-		b.WriteString(fmt.Sprintf("| DynamoDB | %.2f | %.1f%% |\n",
-			float64(r.TrafficStats.DynamoBytes)/(1024*1024*1024), r.TrafficStats.DynamoPercentage()))
-		b.WriteString(fmt.Sprintf("| ECR | %.2f | %.1f%% |\n",
-			float64(r.TrafficStats.ECRBytes)/(1024*1024*1024), r.TrafficStats.ECRPercentage()))
-		b.WriteString(fmt.Sprintf("| Other | %.2f | %.1f%% |\n\n",
-			float64(r.TrafficStats.OtherBytes)/(1024*1024*1024), r.TrafficStats.OtherPercentage()))
+		b.WriteString("## " + t.Text("Collected Traffic Sample") + "\n\n")
+		b.WriteString(fmt.Sprintf("**Total:** %s records, %s\n\n",
+			units.Count(r.TrafficStats.TotalRecords), units.Format(r.TrafficStats.TotalBytes)))
+
+		b.WriteString("| Service | Data | Percentage |\n")
+		b.WriteString("|---------|------|------------|\n")
+		for _, service := range r.TrafficStats.ServiceOrder() {
+			b.WriteString(fmt.Sprintf("| %s | %s | %.1f%% |\n", service.Name(),
+				units.Format(r.TrafficStats.Bytes(service)), r.TrafficStats.Percentage(service)))
+		}
+		b.WriteString("\n")
+
+		if len(r.TrafficStats.Planes) > 0 {
+			b.WriteString("### " + t.Text("Data Transfer vs API Calls") + "\n\n")
+			b.WriteString("> Heuristic: HTTPS flows averaging under 64 KB per record are counted as API calls\n\n")
+			b.WriteString("| Service | Data Transfer | API Calls | Data Share |\n")
+			b.WriteString("|---------|---------------|-----------|------------|\n")
+			for _, svc := range r.TrafficStats.PlaneServices() {
+				p := r.TrafficStats.Planes[svc]
+				b.WriteString(fmt.Sprintf("| %s | %s | %s | %.1f%% |\n", svc,
+					units.Format(p.DataBytes), units.Format(p.ControlBytes), p.DataShare()))
+			}
+			b.WriteString("\n")
+		}
+
+		if ports := r.TrafficStats.PortBreakdown(); len(ports) > 0 {
+			b.WriteString("### " + t.Text("Traffic by Port") + "\n\n")
+			b.WriteString("> Flows are counted under their service port, the lower of source and destination\n\n")
+			b.WriteString("| Port | Service | Data | Share | Remediation |\n")
+			b.WriteString("|------|---------|------|-------|-------------|\n")
+			for i, p := range ports {
+				if r.capped(b, i, len(ports), maxPortRows, 5) {
+					break
+				}
+				name := p.Name
+				if name == "" {
+					name = "-"
+				}
+				b.WriteString(fmt.Sprintf("| %s | %s | %s | %.1f%% | %s |\n", p.Key, name,
+					units.Format(p.Bytes), p.SharePct, p.Remediation()))
+			}
+			b.WriteString("\n")
+		}
+
+		exact, broad, unmatched := r.TrafficStats.AccuracyPercentages()
+		b.WriteString("### " + t.Text("Classification Confidence") + "\n\n")
+		b.WriteString("| Match | Share of Bytes |\n")
+		b.WriteString("|-------|----------------|\n")
+		b.WriteString(fmt.Sprintf("| Exact service range (S3, DynamoDB) | %.1f%% |\n", exact))
+		b.WriteString(fmt.Sprintf("| Broad EC2 range only (counted as ECR, upper bound) | %.1f%% |\n", broad))
+		b.WriteString(fmt.Sprintf("| No AWS range matched | %.1f%% |\n", unmatched))
+		b.WriteString(fmt.Sprintf("| Resolved by DNS (registry and AWS API hostnames) | %.1f%% |\n\n", r.TrafficStats.ResolvedPercentage()))
+		b.WriteString("> Treat the ECR share as an upper bound. The DNS-resolved share overlaps the range matches and is a lower bound: registries and AWS APIs answer from large address pools.\n\n")
+
+		if c := r.ClassificationCheck; c != nil && c.ComparedBytes > 0 {
+			b.WriteString("### " + t.Text("Classifier Cross-Check") + "\n\n")
+			b.WriteString(fmt.Sprintf("The IP-range classification agrees with the service Flow Logs recorded (pkt-dst-aws-service) on **%.1f%%** of %s.\n\n",
+				c.AgreementPct(), units.Format(c.ComparedBytes)))
+			if len(c.Disagreements) > 0 {
+				b.WriteString("| Destination | IP Ranges | Flow Logs | Flows | Data |\n")
+				b.WriteString("|-------------|-----------|-----------|-------|------|\n")
+				for i, d := range c.Disagreements {
+					if r.capped(b, i, len(c.Disagreements), 0, 5) {
+						break
+					}
+					b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", d.Address, d.IPRanges, d.FlowLogs, units.Count(d.Flows), units.Format(d.Bytes)))
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	if c := r.SampleCoverage; c != nil {
+		b.WriteString("## " + t.Text("Sample Completeness") + "\n\n")
+		if c.Complete() {
+			b.WriteString("**" + t.Text("Complete") + ":** every minute of the sample has records and none were skipped.\n\n")
+		} else {
+			b.WriteString("> " + t.Text("Projections scale the traffic that was captured; the gaps below mean the figures undercount.") + "\n\n")
+		}
+		for _, note := range c.Notes() {
+			b.WriteString("- " + note + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if rej := r.RejectedTraffic; rej != nil {
+		b.WriteString("## " + t.Text("Rejected Traffic") + "\n\n")
+		b.WriteString("> " + t.Text("Flows blocked by security groups or network ACLs during the sample: misconfigured rules, or callbacks that were rightly blocked.") + "\n\n")
+		b.WriteString(fmt.Sprintf("**Total:** %s flows, %s\n\n", units.Count(rej.Flows), units.Format(rej.Bytes)))
+		for _, peers := range []struct {
+			title string
+			list  []analysis.RejectedPeer
+		}{{"Top Rejected Destinations", rej.Destinations}, {"Top Rejected Sources", rej.Sources}} {
+			b.WriteString("### " + t.Text(peers.title) + "\n\n")
+			b.WriteString("| Address | Ports | Flows | Data |\n")
+			b.WriteString("|---------|-------|-------|------|\n")
+			for i, p := range peers.list {
+				if r.capped(b, i, len(peers.list), 0, 4) {
+					break
+				}
+				b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", p.Address, strings.Join(p.Ports, ", "), units.Count(p.Flows), units.Format(p.Bytes)))
+			}
+			b.WriteString("\n")
+		}
 	}
 
 	// Cost Estimate
 	if r.CostEstimate != nil {
-		b.WriteString("## Cost Estimate\n\n")
-		b.WriteString(fmt.Sprintf("> Projected from %d-minute sample to monthly estimate\n\n", r.ScanDuration))
+		b.WriteString("## " + t.Text("Cost Estimate") + "\n\n")
+		b.WriteString("> " + t.Sprintf("Projected from %d-minute sample to monthly estimate", r.ScanDuration) + "\n\n")
 		b.WriteString(fmt.Sprintf("**NAT Gateway Rate:** $%.4f per GB\n\n", r.CostEstimate.NATGatewayPricePerGB))
 
 		b.WriteString("| Metric | Amount |\n")
@@ -178,6 +602,9 @@ func (r *Report) ToMarkdown() string {
 		b.WriteString(fmt.Sprintf("| Current NAT Gateway Cost | $%.2f/month |\n", r.CostEstimate.CurrentMonthlyCost))
 		b.WriteString(fmt.Sprintf("| S3 Endpoint Savings | $%.2f/month |\n", r.CostEstimate.S3SavingsMonthly))
 		b.WriteString(fmt.Sprintf("| DynamoDB Endpoint Savings | $%.2f/month |\n", r.CostEstimate.DynamoSavingsMonthly))
+		if r.CostEstimate.CrossRegionDynamoGB > 0 {
+			b.WriteString(fmt.Sprintf("|  └ Excludes cross-region DynamoDB traffic | %s GB/month |\n", units.Number(r.CostEstimate.CrossRegionDynamoGB)))
+		}
 		if ecrCost := r.estimateMonthlyECRNATCost(); ecrCost > 0 {
 			b.WriteString(fmt.Sprintf("| ECR Traffic Cost over NAT (no free endpoint) | $%.2f/month |\n", ecrCost))
 		}
@@ -186,35 +613,297 @@ func (r *Report) ToMarkdown() string {
 			fixed, data, total, azCount, endpointCount := r.EndpointAnalysis.EstimateECRInterfaceEndpointMonthlyCost(monthlyECRGB)
 			b.WriteString(fmt.Sprintf("| Estimated ECR Interface Endpoint Cost (%d endpoint(s), %d AZ) | $%.2f/month |\n", endpointCount, azCount, total))
 			b.WriteString(fmt.Sprintf("|  └ Fixed hourly component | $%.2f/month |\n", fixed))
-			b.WriteString(fmt.Sprintf("|  └ Data processing component (%.2f GB/month) | $%.2f/month |\n", monthlyECRGB, data))
+			b.WriteString(fmt.Sprintf("|  └ Data processing component (%s GB/month) | $%.2f/month |\n", units.Number(monthlyECRGB), data))
 		}
 		b.WriteString(fmt.Sprintf("| **Total Potential Savings** | **$%.2f/month** |\n\n", r.CostEstimate.TotalSavingsMonthly))
+		if r.CostEstimate.CrossRegionS3GB > 0 {
+			b.WriteString(fmt.Sprintf("S3 savings exclude %s GB/month of traffic to buckets in other regions.\n\n", units.Number(r.CostEstimate.CrossRegionS3GB)))
+		}
+		r.writeCharges(b, t)
+	}
+
+	if r.ScanCost != nil {
+		b.WriteString(fmt.Sprintf("**Cost of this scan:** %s\n\n", r.ScanCost))
+	}
+
+	if len(r.AZTraffic) > 0 {
+		b.WriteString("### " + t.Text("Traffic by Availability Zone") + "\n\n")
+		b.WriteString("| Availability Zone | NAT Gateways | Share | Projected Cost | Endpoint Savings |\n")
+		b.WriteString("|-------------------|--------------|-------|----------------|------------------|\n")
+		for _, z := range r.AZTraffic {
+			b.WriteString(fmt.Sprintf("| %s | %s | %.1f%% | $%.2f/month | $%.2f/month |\n",
+				z.AvailabilityZone, r.natLabels(z.NATGateways), z.SharePct, z.MonthlyCost, z.MonthlySavings))
+		}
+		b.WriteString("\n")
+		if msg := analysis.AZImbalance(r.AZTraffic); msg != "" {
+			b.WriteString(fmt.Sprintf("> ⚠️ %s\n\n", msg))
+		}
+	}
+
+	if r.RegistryPulls != nil {
+		p := r.RegistryPulls
+		b.WriteString("### " + t.Text("Public Registry Pulls") + "\n\n")
+		b.WriteString("> Image pulls from public registries recognized by resolved registry addresses (a lower bound)\n\n")
+		b.WriteString("| Registry | Projected Data |\n")
+		b.WriteString("|----------|----------------|\n")
+		for _, name := range p.Registries() {
+			b.WriteString(fmt.Sprintf("| %s | %s GB/month |\n", name, units.Number(p.MonthlyGBByRegistry[name])))
+		}
+		b.WriteString("\n| ECR Pull-Through Cache Model | Amount |\n")
+		b.WriteString("|------------------------------|--------|\n")
+		b.WriteString(fmt.Sprintf("| NAT Gateway cost today | $%.2f/month |\n", p.NATCostMonthly))
+		b.WriteString(fmt.Sprintf("| ECR interface endpoint hours (missing endpoints only) | $%.2f/month |\n", p.EndpointFixedMonthly))
+		b.WriteString(fmt.Sprintf("| ECR interface endpoint data | $%.2f/month |\n", p.EndpointDataMonthly))
+		b.WriteString(fmt.Sprintf("| **Net savings** | **$%.2f/month** |\n\n", p.NetSavingsMonthly))
+	}
+
+	if r.EKSBundle != nil {
+		e := r.EKSBundle
+		b.WriteString("### " + t.Sprintf("EKS Endpoint Bundle (%s)", strings.Join(e.Clusters, ", ")) + "\n\n")
+		b.WriteString("| Endpoint | Projected Data | Status |\n")
+		b.WriteString("|----------|----------------|--------|\n")
+		missing := make(map[string]bool, len(e.MissingEndpoints))
+		for _, svc := range e.MissingEndpoints {
+			missing[svc] = true
+		}
+		for _, svc := range []string{"s3", "ecr.api", "ecr.dkr", "sts", "logs"} {
+			status := "✅ Present"
+			if missing[svc] {
+				status = "❌ Missing"
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s GB/month | %s |\n", svc, units.Number(e.MonthlyGBByService[svc]), status))
+		}
+		b.WriteString(fmt.Sprintf("\n**Bundle net savings:** $%.2f/month (NAT $%.2f/month avoided, endpoints $%.2f/month hours + $%.2f/month data)\n\n",
+			e.NetSavingsMonthly, e.NATCostMonthly, e.EndpointFixedMonthly, e.EndpointDataMonthly))
+	}
+
+	if r.PublicIPv4 != nil {
+		p := r.PublicIPv4
+		b.WriteString("### " + t.Text("Public IPv4 Addresses") + "\n\n")
+		if len(p.Addresses) > 0 {
+			b.WriteString("| Resource | Address | Cost |\n")
+			b.WriteString("|----------|---------|------|\n")
+			for _, a := range p.Addresses {
+				b.WriteString(fmt.Sprintf("| %s | %s | $%.2f/month |\n", r.natLabels([]string{a.Resource}), a.Address, a.MonthlyCost))
+			}
+			b.WriteString(fmt.Sprintf("\n**Public IPv4 total:** $%.2f/month ($%.3f/hour per address)\n\n", p.MonthlyCost, analysis.PublicIPv4PricePerHour))
+		}
+		if p.IPv6CapableGB > 0 {
+			b.WriteString(fmt.Sprintf("%s GB/month of the NAT traffic goes to AWS APIs that also answer over IPv6 (%s), $%.2f/month of NAT data processing an egress-only internet gateway would not charge.\n\n",
+				units.Number(p.IPv6CapableGB), strings.Join(p.IPv6CapableServices(), ", "), p.IPv6CapableNATCostMonthly))
+		}
+	}
+
+	if len(r.EndpointCases) > 0 {
+		b.WriteString("### " + t.Text("Interface Endpoint Break-Even") + "\n\n")
+		b.WriteString("| Endpoints | Traffic | Break-Even | Net | Verdict |\n")
+		b.WriteString("|-----------|---------|------------|-----|---------|\n")
+		for _, c := range r.EndpointCases {
+			verdict := "Not worth it"
+			switch {
+			case len(c.MissingEndpoints) == 0:
+				verdict = "Already present"
+			case c.Worthwhile():
+				verdict = "✅ Add"
+			}
+			b.WriteString(fmt.Sprintf("| %s (%s) | %s GB/month | %s GB/month | $%.2f/month | %s |\n",
+				c.Name, strings.Join(c.Services, ", "), units.Number(c.MonthlyGB), units.Number(c.BreakEvenGB), c.NetSavingsMonthly, verdict))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.DynamoDBEndpoints) > 0 {
+		b.WriteString("### " + t.Text("DynamoDB Endpoints Resolved") + "\n\n")
+		b.WriteString("> From Route 53 Resolver query logs; cross-region endpoints are not reachable through a gateway endpoint in this region\n\n")
+		b.WriteString("| Hostname | Region | Queries | Clients | Cross-Region |\n")
+		b.WriteString("|----------|--------|---------|---------|--------------|\n")
+		for i, ep := range r.DynamoDBEndpoints {
+			if r.capped(b, i, len(r.DynamoDBEndpoints), 0, 5) {
+				break
+			}
+			cross := "No"
+			if ep.CrossRegion {
+				cross = "⚠️ Yes"
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %s |\n", ep.Hostname, ep.Region, ep.Queries, ep.Clients, cross))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.S3Attribution) > 0 {
+		b.WriteString("### " + t.Text("S3 Traffic by Bucket") + "\n\n")
+		b.WriteString("> From CloudTrail S3 data events sent from the NAT Gateway public IPs during the sample\n\n")
+		b.WriteString("| Bucket | Principal | Data | Requests |\n")
+		b.WriteString("|--------|-----------|------|----------|\n")
+		for i, a := range r.S3Attribution {
+			if r.capped(b, i, len(r.S3Attribution), 0, 4) {
+				break
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", a.Bucket, a.Principal, units.Format(a.Bytes), units.Count(a.Requests)))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Findings) > 0 {
+		b.WriteString("## " + t.Text("Findings") + "\n\n")
+		for i, f := range r.Findings {
+			b.WriteString(fmt.Sprintf("%d. **%s** [%s] score %.1f, effort %s\n", i+1, t.Text(f.Title), strings.ToUpper(f.Severity), f.Score, analysis.EffortLabel(f.Effort)))
+			b.WriteString(fmt.Sprintf("   %s\n", t.Text(f.Description)))
+			switch {
+			case strings.Contains(f.Action, "\n"):
+				b.WriteString(fmt.Sprintf("\n   ```bash\n   %s\n   ```\n", strings.ReplaceAll(f.Action, "\n", "\n   ")))
+			case f.Action != "":
+				b.WriteString("   " + t.Sprintf("Action: %s", t.Text(f.Action)) + "\n")
+			}
+			if f.Impact != "" {
+				b.WriteString("   " + t.Sprintf("Impact: %s", t.Text(f.Impact)) + "\n")
+			}
+			if links := r.consoleLinks(append([]string{f.VPCID}, console.IDs(f.Description+"\n"+f.Action)...)...); links != "" {
+				b.WriteString("   " + t.Sprintf("Console: %s", links) + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Recommendations) > 0 {
+		b.WriteString("## " + t.Text("Recommendations") + "\n\n")
+		for i, rec := range r.Recommendations {
+			b.WriteString(fmt.Sprintf("%d. **%s** [%s] score %.1f, effort %s\n", i+1, t.Text(rec.Title), strings.ToUpper(rec.Priority), rec.Score, analysis.EffortLabel(rec.Effort)))
+			b.WriteString(fmt.Sprintf("   %s\n", t.Text(rec.Description)))
+			if rec.Savings != "" {
+				b.WriteString("   " + t.Sprintf("Savings: %s", t.Text(rec.Savings)) + "\n")
+			}
+			if links := r.consoleLinks(console.IDs(rec.Description + "\n" + strings.Join(rec.Commands, "\n"))...); links != "" {
+				b.WriteString("   " + t.Sprintf("Console: %s", links) + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+	if plan := analysis.PlanRemediation(r.Findings, r.Recommendations); len(plan.Items) > 0 {
+		b.WriteString("## " + t.Text("Remediation Plan") + "\n\n")
+		b.WriteString("| # | Action | Effort | Hours | Savings/month | Savings/month per hour |\n")
+		b.WriteString("|---|--------|--------|-------|---------------|------------------------|\n")
+		for i, item := range plan.Items {
+			b.WriteString(fmt.Sprintf("| %d | %s | %s | %g | $%.2f | $%.2f |\n", i+1, t.Text(item.Title), item.Effort, item.Hours, item.MonthlySavings, item.SavingsPerHour))
+		}
+		b.WriteString(fmt.Sprintf("| | **Total** | | **%g** | **$%.2f** | |\n\n", plan.Hours, plan.MonthlySavings))
+	}
+	if r.HiddenBelowMinSavings > 0 {
+		b.WriteString("> " + t.Sprintf("%d finding(s)/recommendation(s) projected to save less than $%.2f/month are hidden (`--min-savings`).",
+			r.HiddenBelowMinSavings, r.MinSavings) + "\n\n")
 	}
 
 	// Remediation
-	if r.EndpointAnalysis != nil && r.EndpointAnalysis.HasIssues() {
-		b.WriteString("## Remediation Steps\n\n")
+	hasIssues := r.EndpointAnalysis != nil && r.EndpointAnalysis.HasIssues()
+	if hasIssues || len(r.VPCRemediation) > 0 {
+		b.WriteString("## " + t.Text("Remediation Steps") + "\n\n")
+	}
+	if hasIssues {
+		if len(r.VPCRemediation) > 0 {
+			b.WriteString(t.Sprintf("**VPC:** %s", r.VPC(r.EndpointAnalysis.VPCID)) + "\n\n")
+		}
+		for _, note := range r.EndpointAnalysis.Prechecks() {
+			b.WriteString("> ⚠️ " + note + "\n\n")
+		}
 
 		if cmds := r.EndpointAnalysis.GetCreateEndpointCommands(); len(cmds) > 0 {
-			b.WriteString("### Create Missing VPC Endpoints\n\n")
+			b.WriteString("### " + t.Text("Create Missing VPC Endpoints") + "\n\n")
 			for _, cmd := range cmds {
 				b.WriteString(fmt.Sprintf("```bash\n%s\n```\n\n", cmd))
 			}
-			if r.EndpointAnalysis.HasMissingECRInterfaceEndpoints() {
+			r.writeConsoleLinks(b, t, r.EndpointAnalysis.VPCID, cmds)
+			if p := r.EndpointAnalysis.Placement; p != nil && p.SecurityGroupID == "" && r.EndpointAnalysis.HasMissingECRInterfaceEndpoints() {
+				b.WriteString("> Run these commands in order in one shell: the first creates the endpoints' security group and the endpoint commands use its ID (`$SG_ID`).\n\n")
+			} else if p == nil && r.EndpointAnalysis.HasMissingECRInterfaceEndpoints() {
 				b.WriteString("> For ECR interface endpoints, replace `<security-group-id>` with a security group that allows HTTPS (443) from your private workloads.\n\n")
 			}
 		}
 
 		if cmds := r.EndpointAnalysis.GetAddRouteCommands(); len(cmds) > 0 {
-			b.WriteString("### Add Missing Route Table Associations\n\n")
+			b.WriteString("### " + t.Text("Add Missing Route Table Associations") + "\n\n")
 			for _, cmd := range cmds {
 				b.WriteString(fmt.Sprintf("```bash\n%s\n```\n\n", cmd))
 			}
+			r.writeConsoleLinks(b, t, r.EndpointAnalysis.VPCID, cmds)
+		}
+	}
+	for _, fix := range r.VPCRemediation {
+		b.WriteString("### " + t.Sprintf("VPC %s", r.VPC(fix.VPCID)) + "\n\n")
+		cmds := append(slices.Clone(fix.CreateEndpointCommands), fix.AddRouteCommands...)
+		for _, cmd := range cmds {
+			b.WriteString(fmt.Sprintf("```bash\n%s\n```\n\n", cmd))
 		}
+		r.writeConsoleLinks(b, t, fix.VPCID, cmds)
+	}
+
+	if m := r.Methodology; m != nil {
+		r.writeMethodology(b, t, m)
 	}
 
 	b.WriteString("---\n")
 	b.WriteString("*Generated by [termiNATor](https://github.com/doitintl/terminator)*\n")
+}
+
+// writeCharges itemizes which charges of the S3 and DynamoDB traffic the
+// gateway endpoints remove, so the savings can be checked for double
+// counting.
+func (r *Report) writeCharges(b *mdWriter, t *i18n.Translator) {
+	if len(r.Charges) == 0 {
+		return
+	}
+	b.WriteString("### " + t.Text("What Gateway Endpoints Remove") + "\n\n")
+	b.WriteString("| Service | Charge | GB/month | Rate | Removed | Savings | Note |\n")
+	b.WriteString("|---------|--------|----------|------|---------|---------|------|\n")
+	for _, c := range r.Charges {
+		rate, removed, savings := "free", "no", "-"
+		if c.PricePerGB > 0 {
+			rate = fmt.Sprintf("$%.4f/GB", c.PricePerGB)
+		}
+		if c.Removed {
+			removed, savings = "yes", fmt.Sprintf("$%.2f/month", c.MonthlySavings)
+		}
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s |\n", c.Service, c.Component, units.Number(c.GB), rate, removed, savings, c.Note))
+	}
+	b.WriteString("\n> Savings count only the NAT data processing a gateway endpoint removes. The NAT Gateway's hourly charge stays while other traffic uses it.\n\n")
+}
+
+// writeConsoleLinks writes the console links of vpcID and the resources
+// its remediation commands change.
+func (r *Report) writeConsoleLinks(b *mdWriter, t *i18n.Translator, vpcID string, cmds []string) {
+	ids := append([]string{vpcID}, console.IDs(strings.Join(cmds, "\n"))...)
+	if links := r.consoleLinks(ids...); links != "" {
+		b.WriteString(t.Sprintf("Console: %s", links) + "\n\n")
+	}
+}
+
+// writeMethodology writes the appendix showing how the report's figures
+// were derived.
+func (r *Report) writeMethodology(b *mdWriter, t *i18n.Translator, m *analysis.Methodology) {
+	b.WriteString("## " + t.Text("Appendix: Methodology") + "\n\n")
+
+	b.WriteString("### " + t.Text("Projection") + "\n\n")
+	b.WriteString(fmt.Sprintf("**Method:** %s\n\n", m.Projection))
+	b.WriteString(fmt.Sprintf("```\n%s\n```\n\n", strings.ReplaceAll(m.Formula, "; ", "\n")))
 
-	return b.String()
+	b.WriteString("### " + t.Text("Prices") + "\n\n")
+	b.WriteString("| Item | Price (USD) | Unit |\n")
+	b.WriteString("|------|-------------|------|\n")
+	for _, p := range m.Prices {
+		b.WriteString(fmt.Sprintf("| %s | $%.4f | %s |\n", p.Item, p.USD, p.Unit))
+	}
+	b.WriteString("\n")
+
+	if s := m.IPRanges; s != nil {
+		b.WriteString("### " + t.Text("AWS IP Ranges") + "\n\n")
+		b.WriteString(fmt.Sprintf("- **Source:** %s\n", s.Source))
+		b.WriteString(fmt.Sprintf("- **Created:** %s\n", s.CreateDate))
+		b.WriteString(fmt.Sprintf("- **Sync token:** %s\n\n", s.SyncToken))
+	}
+
+	if len(m.Queries) > 0 {
+		b.WriteString("### " + t.Text("Queries") + "\n\n")
+		for _, q := range m.Queries {
+			b.WriteString(fmt.Sprintf("```\n%s\n```\n\n", q))
+		}
+	}
 }
@@ -1,8 +1,13 @@
 package report
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/doitintl/terminator/internal/analysis"
 	"github.com/doitintl/terminator/pkg/types"
@@ -10,10 +15,12 @@ import (
 
 func TestMarkdownContainsECRCost(t *testing.T) {
 	stats := &analysis.TrafficStats{
-		S3Bytes:      1073741824,
-		DynamoBytes:  536870912,
-		ECRBytes:     107374182,
-		OtherBytes:   214748365,
+		Services: map[analysis.Service]analysis.ServiceStats{
+			analysis.ServiceS3:       {Bytes: 1073741824},
+			analysis.ServiceDynamoDB: {Bytes: 536870912},
+			analysis.ServiceECR:      {Bytes: 107374182},
+			analysis.ServiceOther:    {Bytes: 214748365},
+		},
 		TotalBytes:   1932734783,
 		TotalRecords: 100,
 	}
@@ -41,7 +48,9 @@ func TestMarkdownContainsECRCost(t *testing.T) {
 
 func TestMarkdownOmitsECRWhenZero(t *testing.T) {
 	stats := &analysis.TrafficStats{
-		S3Bytes:    1073741824,
+		Services: map[analysis.Service]analysis.ServiceStats{
+			analysis.ServiceS3: {Bytes: 1073741824},
+		},
 		TotalBytes: 1073741824,
 	}
 	cost := &analysis.CostEstimate{
@@ -59,8 +68,10 @@ func TestMarkdownOmitsECRWhenZero(t *testing.T) {
 
 func TestMarkdownIncludesNATModeAndECREndpointRemediation(t *testing.T) {
 	stats := &analysis.TrafficStats{
-		ECRBytes:     1073741824,
-		OtherBytes:   1073741824,
+		Services: map[analysis.Service]analysis.ServiceStats{
+			analysis.ServiceECR:   {Bytes: 1073741824},
+			analysis.ServiceOther: {Bytes: 1073741824},
+		},
 		TotalBytes:   2147483648,
 		TotalRecords: 20,
 	}
@@ -103,3 +114,459 @@ func TestMarkdownIncludesNATModeAndECREndpointRemediation(t *testing.T) {
 		t.Error("markdown report missing ECR remediation command with security group placeholder")
 	}
 }
+
+func TestMarkdownIncludesScanCost(t *testing.T) {
+	r := New("us-east-1", "123456789012", 5, nil, nil, &analysis.CostEstimate{NATGatewayPricePerGB: 0.045}, nil)
+	if strings.Contains(r.ToMarkdown(), "Cost of this scan") {
+		t.Error("scan cost line should not appear without a scan cost")
+	}
+
+	r.ScanCost = analysis.CalculateScanCost(1, 2*1024*1024*1024)
+	md := r.ToMarkdown()
+	if !strings.Contains(md, "**Cost of this scan:** ~$0.51") {
+		t.Errorf("markdown report missing scan cost line:\n%s", md)
+	}
+}
+
+func TestMarkdownIncludesAZBreakdown(t *testing.T) {
+	r := New("us-east-1", "123456789012", 5, nil, nil, &analysis.CostEstimate{NATGatewayPricePerGB: 0.045}, nil)
+	r.AZTraffic = []analysis.AZTraffic{
+		{AvailabilityZone: "us-east-1a", NATGateways: []string{"nat-a"}, SharePct: 80, MonthlyCost: 120},
+		{AvailabilityZone: "us-east-1b", NATGateways: []string{"nat-b"}, SharePct: 20, MonthlyCost: 30},
+	}
+	md := r.ToMarkdown()
+	if !strings.Contains(md, "| us-east-1a | nat-a | 80.0% | $120.00/month |") {
+		t.Errorf("markdown report missing AZ row:\n%s", md)
+	}
+	if !strings.Contains(md, "us-east-1a carries 80%") {
+		t.Errorf("markdown report missing AZ imbalance warning:\n%s", md)
+	}
+}
+
+func TestMarkdownIncludesS3Attribution(t *testing.T) {
+	r := New("us-east-1", "123456789012", 5, nil, nil, nil, nil)
+	r.S3Attribution = []analysis.S3Attribution{
+		{Bucket: "analytics-raw", Principal: "role etl-runner", Bytes: 28 * 1024 * 1024 * 1024, Requests: 1200},
+	}
+	md := r.ToMarkdown()
+	if !strings.Contains(md, "| analytics-raw | role etl-runner | 28.00 GiB | 1,200 |") {
+		t.Errorf("markdown report missing S3 attribution row:\n%s", md)
+	}
+}
+
+func TestMarkdownIncludesDynamoDBEndpoints(t *testing.T) {
+	r := New("us-east-1", "123456789012", 5, nil, nil, nil, nil)
+	r.DynamoDBEndpoints = []analysis.DynamoDBEndpoint{
+		{Hostname: "dynamodb.eu-west-1.amazonaws.com", Region: "eu-west-1", Queries: 300, Clients: 2, CrossRegion: true},
+	}
+	md := r.ToMarkdown()
+	if !strings.Contains(md, "| dynamodb.eu-west-1.amazonaws.com | eu-west-1 | 300 | 2 | ⚠️ Yes |") {
+		t.Errorf("markdown report missing DynamoDB endpoint row:\n%s", md)
+	}
+}
+
+func TestMarkdownIncludesBillingContext(t *testing.T) {
+	r := New("us-east-1", "123456789012", 60, nil, nil, nil, nil)
+	r.GeneratedAt = time.Date(2026, 9, 14, 12, 0, 0, 0, time.UTC)
+	r.CostAnomalyDays = 30
+	if md := r.ToMarkdown(); !strings.Contains(md, "No NAT Gateway cost anomalies in the last 30 days.") {
+		t.Errorf("markdown report missing the empty billing context:\n%s", md)
+	}
+
+	r.CostAnomalies = []types.CostAnomaly{
+		{ID: "a-2", StartDate: "2026-09-13", UsageTypes: []string{"NatGateway-Bytes"}, TotalImpact: 84.5, ExpectedSpend: 40, ActualSpend: 124.5},
+		{ID: "a-1", StartDate: "2026-08-20", EndDate: "2026-08-22", UsageTypes: []string{"NatGateway-Hours"}, TotalImpact: 12},
+	}
+	if got := r.AnomaliesDuringSample(); len(got) != 1 || got[0].ID != "a-2" {
+		t.Errorf("AnomaliesDuringSample() = %+v, want the ongoing anomaly", got)
+	}
+	md := r.ToMarkdown()
+	if !strings.Contains(md, "| 2026-09-13 | ongoing | NatGateway-Bytes | $84.50 | $40.00 | $124.50 |") {
+		t.Errorf("markdown report missing the anomaly row:\n%s", md)
+	}
+	if !strings.Contains(md, "collected during a NAT Gateway cost anomaly") {
+		t.Errorf("markdown report missing the sample warning:\n%s", md)
+	}
+}
+
+func TestMarkdownIncludesRegistryPulls(t *testing.T) {
+	r := New("us-east-1", "123456789012", 5, nil, nil, nil, nil)
+	r.RegistryPulls = &analysis.RegistryPullEstimate{
+		MonthlyGBByRegistry:  map[string]float64{"Docker Hub": 500},
+		MonthlyGB:            500,
+		NATCostMonthly:       22.5,
+		EndpointFixedMonthly: 14.4,
+		EndpointDataMonthly:  5,
+		NetSavingsMonthly:    3.1,
+	}
+	md := r.ToMarkdown()
+	for _, want := range []string{"| Docker Hub | 500.00 GB/month |", "| **Net savings** | **$3.10/month** |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMarkdownIncludesEKSBundle(t *testing.T) {
+	r := New("us-east-1", "123456789012", 5, nil, nil, nil, nil)
+	r.EKSBundle = &analysis.EKSBundleEstimate{
+		Clusters:           []string{"prod"},
+		MonthlyGBByService: map[string]float64{"s3": 100, "sts": 2},
+		MissingEndpoints:   []string{"sts"},
+		NetSavingsMonthly:  12.5,
+	}
+	md := r.ToMarkdown()
+	for _, want := range []string{"### EKS Endpoint Bundle (prod)", "| sts | 2.00 GB/month | ❌ Missing |", "| s3 | 100.00 GB/month | ✅ Present |", "**Bundle net savings:** $12.50/month"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMarkdownIncludesEndpointCases(t *testing.T) {
+	r := New("us-east-1", "123456789012", 5, nil, nil, nil, nil)
+	r.EndpointCases = []*analysis.InterfaceEndpointCase{
+		{Name: "SSM / Session Manager", Services: []string{"ssm"}, MissingEndpoints: []string{"ssm"}, MonthlyGB: 2, BreakEvenGB: 205.71, NetSavingsMonthly: -7.14},
+	}
+	md := r.ToMarkdown()
+	if !strings.Contains(md, "| SSM / Session Manager (ssm) | 2.00 GB/month | 205.71 GB/month | $-7.14/month | Not worth it |") {
+		t.Errorf("markdown report missing endpoint case row:\n%s", md)
+	}
+}
+
+func TestMarkdownIncludesPlaneSplit(t *testing.T) {
+	stats := &analysis.TrafficStats{TotalRecords: 2, TotalBytes: 3 << 30, Planes: map[string]*analysis.PlaneSplit{
+		"s3": {DataBytes: 2 << 30, ControlBytes: 1 << 30},
+	}}
+	md := New("us-east-1", "123456789012", 5, nil, stats, nil, nil).ToMarkdown()
+	if !strings.Contains(md, "| s3 | 2.00 GiB | 1.00 GiB | 66.7% |") {
+		t.Errorf("markdown report missing plane split row:\n%s", md)
+	}
+}
+
+func TestMarkdownIncludesRecommendationsAndHiddenCount(t *testing.T) {
+	r := New("us-east-1", "123456789012", 5, nil, nil, nil, nil)
+	r.Recommendations = []analysis.Recommendation{{Title: "Add the EKS endpoint bundle for prod", Priority: "high", Description: "d", Savings: "~$40.00/month", Score: 24}}
+	r.MinSavings = 5
+	r.HiddenBelowMinSavings = 3
+	md := r.ToMarkdown()
+	for _, want := range []string{"1. **Add the EKS endpoint bundle for prod** [HIGH] score 24.0", "> 3 finding(s)/recommendation(s) projected to save less than $5.00/month are hidden"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMarkdownTranslatesFindingsAndRecommendations(t *testing.T) {
+	r := New("us-east-1", "123456789012", 15, nil, nil, nil, nil)
+	r.Lang = "es"
+	r.Findings = []types.Finding{{
+		Severity:    "high",
+		Title:       "Missing S3 Gateway Endpoint",
+		Description: "VPC vpc-123 has NAT Gateway(s) but no S3 Gateway endpoint",
+		Action:      "Create S3 Gateway VPC endpoint and associate with private route tables",
+		Score:       81,
+	}}
+	r.Recommendations = []analysis.Recommendation{{Title: "Consider Regional NAT Gateway for VPC vpc-123", Priority: "high", Description: "untranslated text stays as is"}}
+	md := r.ToMarkdown()
+	for _, want := range []string{
+		"# Informe de análisis detallado de termiNATor",
+		"**Duración de la muestra:** 15 minutos",
+		"## Hallazgos",
+		"1. **Falta el endpoint de puerta de enlace de S3** [HIGH] score 81.0",
+		"   La VPC vpc-123 tiene NAT Gateway pero no tiene endpoint de puerta de enlace de S3",
+		"   Acción: Cree un endpoint de VPC de puerta de enlace de S3",
+		"## Recomendaciones",
+		"1. **Considere un NAT Gateway regional para la VPC vpc-123**",
+		"   untranslated text stays as is",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("spanish markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMarkdownLabelsAccountAndVPCs(t *testing.T) {
+	nats := []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1", SubnetID: "subnet-1"}}
+	r := New("us-east-1", "123456789012", 5, nats, nil, nil, &analysis.EndpointAnalysis{VPCID: "vpc-1"})
+	r.FriendlyNames = types.FriendlyNames{AccountAlias: "payments-prod", VPCNames: map[string]string{"vpc-1": "payments"}}
+
+	md := r.ToMarkdown()
+	for _, want := range []string{"**Account:** payments-prod (123456789012)", "| nat-1 | zonal | vpc-1 (payments) | subnet-1 |", "**VPC:** vpc-1 (payments)"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, md)
+		}
+	}
+	if comment := r.PRComment(nil, ""); !strings.Contains(comment, "Account `payments-prod (123456789012)`") {
+		t.Errorf("PR comment lacks the account alias:\n%s", comment)
+	}
+}
+
+func TestSaveRedactsIdentifiers(t *testing.T) {
+	nats := []types.NATGateway{{ID: "nat-0123456789abcdef0", VPCID: "vpc-0a1b2c3d", SubnetID: "subnet-11112222", PublicIPs: []string{"203.0.113.7"}, Tags: map[string]string{"Name": "payments-egress"}}}
+	r := New("us-east-1", "123456789012", 5, nats, nil, nil, nil)
+	r.FriendlyNames = types.FriendlyNames{AccountAlias: "payments-prod", VPCNames: map[string]string{"vpc-0a1b2c3d": "payments-vpc"}}
+	r.Redact = true
+
+	dir := t.TempDir()
+	for _, save := range []func(string) error{r.SaveMarkdown, r.SaveJSON} {
+		path := filepath.Join(dir, "report")
+		if err := save(path); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, leaked := range []string{"123456789012", "nat-0123456789abcdef0", "vpc-0a1b2c3d", "subnet-11112222", "203.0.113.7", "payments-prod", "payments-vpc", "payments-egress"} {
+			if strings.Contains(string(data), leaked) {
+				t.Errorf("%q leaked into redacted report:\n%s", leaked, data)
+			}
+		}
+		if !strings.Contains(string(data), "us-east-1") {
+			t.Errorf("redaction should keep non-identifying fields:\n%s", data)
+		}
+	}
+}
+
+func TestMaxRowsCapsMarkdownTables(t *testing.T) {
+	rej := &analysis.RejectedTraffic{Flows: 5, Bytes: 5 << 20}
+	for i := 1; i <= 5; i++ {
+		rej.Destinations = append(rej.Destinations, analysis.RejectedPeer{Address: fmt.Sprintf("198.51.100.%d", i), Ports: []string{"22"}, Flows: 1, Bytes: 1 << 20})
+	}
+	r := New("us-east-1", "123456789012", 5, nil, nil, nil, nil)
+	r.RejectedTraffic = rej
+	r.MaxRows = 2
+
+	var md bytes.Buffer
+	if err := r.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "| 198.51.100.2 |") || strings.Contains(md.String(), "| 198.51.100.3 |") ||
+		!strings.Contains(md.String(), "| … | 3 more | | |\n") {
+		t.Fatalf("expected two rows and a count of the rest:\n%s", md.String())
+	}
+
+	var js bytes.Buffer
+	if err := r.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(js.String(), "198.51.100.5") {
+		t.Fatalf("JSON report should keep every row:\n%s", js.String())
+	}
+}
+
+func TestSaveJSONIncludesInventory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	r := &Report{Region: "us-east-1", Inventory: []types.VPCInventory{{
+		VPCID:       "vpc-1",
+		Subnets:     []types.Subnet{{ID: "subnet-1", VPCID: "vpc-1", CIDR: "10.0.0.0/24", AvailabilityZone: "us-east-1a"}},
+		RouteTables: []types.RouteTable{{ID: "rtb-1", Routes: []types.Route{{DestinationCIDR: "0.0.0.0/0", Target: "nat-1", TargetType: "nat-gateway"}}}},
+	}}}
+	if err := r.SaveJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"inventory"`) || !strings.Contains(string(data), `"10.0.0.0/24"`) {
+		t.Fatalf("inventory missing from JSON:\n%s", data)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Inventory) != 1 || loaded.Inventory[0].RouteTables[0].Routes[0].Target != "nat-1" {
+		t.Fatalf("round trip lost inventory: %+v", loaded.Inventory)
+	}
+
+	r.Inventory = nil
+	r.SaveJSON(path)
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), `"inventory"`) {
+		t.Fatal("inventory should be omitted when not collected")
+	}
+}
+
+func TestMarkdownIncludesTrafficByPort(t *testing.T) {
+	stats := &analysis.TrafficStats{TotalBytes: 4 << 30, TotalRecords: 3, PortBytes: map[string]int64{
+		"tcp/443":  3 << 30,
+		"tcp/5432": 1 << 30,
+	}}
+	r := New("us-east-1", "123456789012", 15, nil, stats, nil, nil)
+	md := r.ToMarkdown()
+	if !strings.Contains(md, "| tcp/443 | HTTPS | 3.00 GiB | 75.0% |") {
+		t.Errorf("markdown report missing the HTTPS row:\n%s", md)
+	}
+	if !strings.Contains(md, "| tcp/5432 | PostgreSQL | 1.00 GiB | 25.0% | Database or replication traffic") {
+		t.Errorf("markdown report missing the PostgreSQL row:\n%s", md)
+	}
+}
+
+func TestMarkdownIncludesRejectedTraffic(t *testing.T) {
+	r := New("us-east-1", "123456789012", 15, nil, nil, nil, nil)
+	if strings.Contains(r.ToMarkdown(), "Rejected Traffic") {
+		t.Error("rejected traffic should only be reported when summarized")
+	}
+	r.RejectedTraffic = &analysis.RejectedTraffic{
+		Flows:        42,
+		Bytes:        2 << 20,
+		Destinations: []analysis.RejectedPeer{{Address: "198.51.100.7", Ports: []string{"tcp/4444"}, Flows: 40, Bytes: 2 << 20}},
+		Sources:      []analysis.RejectedPeer{{Address: "10.0.1.5", Ports: []string{"tcp/4444"}, Flows: 40, Bytes: 2 << 20}},
+	}
+	md := r.ToMarkdown()
+	for _, want := range []string{"## Rejected Traffic", "**Total:** 42 flows, 2.00 MiB", "| 198.51.100.7 | tcp/4444 | 40 | 2.00 MiB |", "### Top Rejected Sources"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMarkdownIncludesMethodologyAppendix(t *testing.T) {
+	r := New("us-east-1", "123456789012", 15, nil, nil, nil, nil)
+	r.Methodology = &analysis.Methodology{
+		Projection: "linear extrapolation of a 15-minute sample",
+		Formula:    "monthly GB = sample bytes / 2^30 × 43,200 / 15; monthly cost = monthly GB × $0.0450",
+		Prices:     []analysis.Price{{Item: "NAT Gateway data processing (us-east-1)", USD: 0.045, Unit: "per GB"}},
+		IPRanges:   &analysis.IPRangesSnapshot{Source: "https://ip-ranges.amazonaws.com/ip-ranges.json", SyncToken: "1700000000", CreateDate: "2024-01-01-00-00-00"},
+		Queries:    []string{"fields @message\n| stats count(*)"},
+	}
+	md := r.ToMarkdown()
+	for _, want := range []string{
+		"## Appendix: Methodology",
+		"**Method:** linear extrapolation of a 15-minute sample",
+		"monthly GB = sample bytes / 2^30 × 43,200 / 15\nmonthly cost = monthly GB × $0.0450\n",
+		"| NAT Gateway data processing (us-east-1) | $0.0450 | per GB |",
+		"- **Sync token:** 1700000000",
+		"```\nfields @message\n| stats count(*)\n```",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Index(md, "## Appendix: Methodology") > strings.Index(md, "*Generated by") {
+		t.Error("appendix should come before the footer")
+	}
+}
+
+func TestMarkdownIncludesSampleCompleteness(t *testing.T) {
+	r := New("us-east-1", "123456789012", 15, nil, nil, nil, nil)
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	r.SampleCoverage = &analysis.SampleCoverage{
+		AggregationIntervalSeconds: 60,
+		OKRecords:                  900,
+		SkipDataRecords:            3,
+		WindowStart:                start,
+		WindowEnd:                  start.Add(15 * time.Minute),
+		FirstRecord:                start,
+		LastRecord:                 start.Add(14 * time.Minute),
+	}
+	md := r.ToMarkdown()
+	for _, want := range []string{"## Sample Completeness", "the figures undercount", "- Flow Logs aggregated records over at most 60 seconds", "900 OK, 0 NODATA (no traffic in an interval) and 3 SKIPDATA record(s)"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMarkdownIncludesClassifierCrossCheck(t *testing.T) {
+	r := New("us-east-1", "123456789012", 15, nil, &analysis.TrafficStats{TotalRecords: 10, TotalBytes: 1 << 30}, nil, nil)
+	r.ClassificationCheck = &analysis.ClassificationCheck{
+		ComparedBytes: 1 << 30,
+		AgreedBytes:   1 << 29,
+		Disagreements: []analysis.ClassificationDisagreement{{Address: "3.80.0.3", IPRanges: "ecr", FlowLogs: "DYNAMODB", Bytes: 2 << 20, Flows: 3}},
+	}
+	md := r.ToMarkdown()
+	for _, want := range []string{"### Classifier Cross-Check", "**50.0%** of 1.00 GiB", "| 3.80.0.3 | ecr | DYNAMODB | 3 | 2.00 MiB |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMarkdownLinksConsole(t *testing.T) {
+	nats := []types.NATGateway{{ID: "nat-0123456789abcdef0", VPCID: "vpc-0a1b2c3d", SubnetID: "subnet-11112222"}}
+	r := New("eu-west-1", "123456789012", 15, nats, nil, nil, nil)
+	r.LogGroupName = "/aws/vpc/terminat"
+	r.Findings = []types.Finding{{
+		Title:  "S3 Gateway Endpoint Missing Route Table Associations",
+		VPCID:  "vpc-0a1b2c3d",
+		Action: "Associate S3 endpoint with: rtb-0aaa1111",
+	}}
+	md := r.ToMarkdown()
+	for _, want := range []string{
+		"**Log Group:** [/aws/vpc/terminat](https://eu-west-1.console.aws.amazon.com/cloudwatch/home?region=eu-west-1#logsV2:log-groups/log-group/$252Faws$252Fvpc$252Fterminat)",
+		"| subnet-11112222 | - | [open](https://eu-west-1.console.aws.amazon.com/vpcconsole/home?region=eu-west-1#NatGatewayDetails:natGatewayId=nat-0123456789abcdef0) |",
+		"   Console: [`vpc-0a1b2c3d`](https://eu-west-1.console.aws.amazon.com/vpcconsole/home?region=eu-west-1#VpcDetails:VpcId=vpc-0a1b2c3d), [`rtb-0aaa1111`](https://eu-west-1.console.aws.amazon.com/vpcconsole/home?region=eu-west-1#RouteTableDetails:RouteTableId=rtb-0aaa1111)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+
+	r.Redact = true
+	if md := r.ToMarkdown(); strings.Contains(md, "console.aws.amazon.com") {
+		t.Errorf("redacted report should not link the console:\n%s", md)
+	}
+}
+
+func TestMarkdownIncludesScanCoverage(t *testing.T) {
+	nats := []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1"}}
+	r := New("us-east-1", "123456789012", 15, nats, nil, nil, nil)
+	r.FriendlyNames = types.FriendlyNames{VPCNames: map[string]string{"vpc-2": "staging"}}
+	r.ScanCoverage = analysis.BuildScanCoverage(nats, "Flow Logs created for this scan on the NAT Gateways", nil,
+		[]analysis.SkippedNAT{{NAT: types.NATGateway{ID: "nat-2", VPCID: "vpc-2"}, Reason: "not selected for this scan"}}, "vpc-1")
+	md := r.ToMarkdown()
+	for _, want := range []string{
+		"## Scan Coverage",
+		"**Source:** Flow Logs created for this scan on the NAT Gateways",
+		"| vpc-1 | measured | traffic sampled; endpoints analyzed in detail |",
+		"| vpc-2 (staging) | skipped | not selected for this scan |",
+		"| nat-1 | vpc-1 | measured | - |",
+		"| nat-2 | vpc-2 (staging) | skipped | not selected for this scan |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMarkdownIncludesVPCRemediation(t *testing.T) {
+	nats := []types.NATGateway{{ID: "nat-1", VPCID: "vpc-0aaa1111"}, {ID: "nat-2", VPCID: "vpc-0bbb2222"}}
+	r := New("us-east-1", "123456789012", 15, nats, nil, nil, nil)
+	r.VPCRemediation = []analysis.VPCRemediation{{
+		VPCID:            "vpc-0bbb2222",
+		AddRouteCommands: []string{"aws ec2 modify-vpc-endpoint \\\n  --vpc-endpoint-id 'vpce-0ccc3333' \\\n  --add-route-table-ids 'rtb-0ddd4444'"},
+	}}
+	md := r.ToMarkdown()
+	for _, want := range []string{
+		"## Remediation Steps",
+		"### VPC vpc-0bbb2222",
+		"--add-route-table-ids 'rtb-0ddd4444'",
+		"#RouteTableDetails:RouteTableId=rtb-0ddd4444",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMarkdownRecordsDurationPreset(t *testing.T) {
+	p, _ := analysis.LookupDurationPreset("standard")
+	r := New("us-east-1", "123456789012", p.Minutes, nil, nil, nil, nil)
+	r.DurationPreset = &p
+	if md := r.ToMarkdown(); !strings.Contains(md, "**Duration Preset:** standard (30m) (expected accuracy: ") {
+		t.Errorf("markdown report missing the duration preset:\n%s", md)
+	}
+}
+
+func TestMarkdownListsPublicIPv4Charges(t *testing.T) {
+	nats := []types.NATGateway{{ID: "nat-1", PublicIPs: []string{"203.0.113.10"}}}
+	stats := &analysis.TrafficStats{TotalBytes: 1 << 30, ServiceBytes: map[string]int64{"sts": 1 << 30}}
+	r := New("us-east-1", "123456789012", 43200, nats, stats, nil, nil)
+	r.PublicIPv4 = analysis.EstimatePublicIPv4("us-east-1", nats, stats, 43200)
+	md := r.ToMarkdown()
+	for _, want := range []string{"### Public IPv4 Addresses", "| nat-1 | 203.0.113.10 | $3.60/month |", "goes to AWS APIs that also answer over IPv6 (sts)"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
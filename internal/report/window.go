@@ -0,0 +1,104 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// CollectionWindow is when a deep scan's sample was collected, in UTC and
+// in the operator's time zone, so the window can be matched with team
+// activity such as nightly batches.
+type CollectionWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// TimeZone names the operator's zone: an IANA name such as
+	// Europe/Berlin, or an abbreviation when the system zone has no name.
+	TimeZone string `json:"time_zone"`
+	// LocalStart and LocalEnd carry the zone's offset, so a saved report
+	// renders the same local times on another machine.
+	LocalStart time.Time `json:"local_start"`
+	LocalEnd   time.Time `json:"local_end"`
+}
+
+// NewCollectionWindow returns the window from start to end, with local
+// times in loc.
+func NewCollectionWindow(start, end time.Time, loc *time.Location) *CollectionWindow {
+	w := &CollectionWindow{Start: start.UTC(), End: end.UTC()}
+	return w.In(loc)
+}
+
+// In returns the window with its local times in loc, to render a saved
+// report in another time zone.
+func (w *CollectionWindow) In(loc *time.Location) *CollectionWindow {
+	c := *w
+	c.LocalStart, c.LocalEnd = w.Start.In(loc), w.End.In(loc)
+	c.TimeZone = zoneName(loc, w.Start)
+	return &c
+}
+
+// String is the window in UTC followed by the local times, e.g.
+// "2024-03-14 14:39–15:09 UTC (15:39–16:09 CET, Europe/Berlin)".
+func (w *CollectionWindow) String() string {
+	s := formatSpan(w.Start, w.End, true)
+	if _, offset := w.LocalStart.Zone(); offset == 0 && isUTC(w.TimeZone) {
+		return s
+	}
+	local := formatSpan(w.LocalStart, w.LocalEnd, w.LocalStart.Format(time.DateOnly) != w.Start.Format(time.DateOnly))
+	if abbrev, _ := w.LocalStart.Zone(); w.TimeZone != "" && w.TimeZone != abbrev {
+		local += ", " + w.TimeZone
+	}
+	return s + " (" + local + ")"
+}
+
+// Local formats t in the window's local zone, e.g. "2024-03-14 16:09 CET".
+func (w *CollectionWindow) Local(t time.Time) string {
+	return t.In(w.LocalStart.Location()).Format("2006-01-02 15:04 MST")
+}
+
+// formatSpan formats start to end, leaving out the date of end when it is
+// the same as start's, and start's too unless withDate.
+func formatSpan(start, end time.Time, withDate bool) string {
+	layout := "15:04"
+	if withDate {
+		layout = "2006-01-02 15:04"
+	}
+	if end.Format(time.DateOnly) != start.Format(time.DateOnly) {
+		return start.Format("2006-01-02 15:04") + " – " + end.Format("2006-01-02 15:04 MST")
+	}
+	return start.Format(layout) + "–" + end.Format("15:04 MST")
+}
+
+// LoadTimeZone returns the zone --timezone names: an IANA name such as
+// America/New_York, UTC, or "local" (or empty) for the system zone.
+func LoadTimeZone(name string) (*time.Location, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "local":
+		return time.Local, nil
+	case "utc":
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(strings.TrimSpace(name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q (use an IANA name such as Europe/Berlin, UTC or local)", name)
+	}
+	return loc, nil
+}
+
+// zoneName names loc, falling back to $TZ or the zone's abbreviation at t
+// for the system zone, which Go calls "Local".
+func zoneName(loc *time.Location, t time.Time) string {
+	if name := loc.String(); name != "Local" {
+		return name
+	}
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" && !strings.HasPrefix(tz, "/") {
+		return tz
+	}
+	abbrev, _ := t.In(loc).Zone()
+	return abbrev
+}
+
+func isUTC(name string) bool {
+	return name == "UTC" || name == "Etc/UTC" || name == ""
+}
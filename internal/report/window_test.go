@@ -0,0 +1,65 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectionWindowString(t *testing.T) {
+	berlin, err := LoadTimeZone("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	start := time.Date(2024, 3, 14, 14, 39, 0, 0, time.UTC)
+	w := NewCollectionWindow(start, start.Add(30*time.Minute), berlin)
+	if got, want := w.String(), "2024-03-14 14:39–15:09 UTC (15:39–16:09 CET, Europe/Berlin)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// A saved report renders the same local times after a round trip.
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved CollectionWindow
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if got := saved.String(); !strings.HasPrefix(got, "2024-03-14 14:39–15:09 UTC (15:39–16:09 +0100, Europe/Berlin)") {
+		t.Errorf("saved String() = %q", got)
+	}
+
+	// Local dates show when the window crosses midnight there.
+	tokyo, _ := LoadTimeZone("Asia/Tokyo")
+	if got, want := w.In(tokyo).String(), "2024-03-14 14:39–15:09 UTC (2024-03-14 23:39 – 2024-03-15 00:09 JST, Asia/Tokyo)"; got != want {
+		t.Errorf("Tokyo String() = %q, want %q", got, want)
+	}
+	if got, want := w.In(time.UTC).String(), "2024-03-14 14:39–15:09 UTC"; got != want {
+		t.Errorf("UTC String() = %q, want %q", got, want)
+	}
+
+	if _, err := LoadTimeZone("Mars/Olympus_Mons"); err == nil {
+		t.Error("expected an error for an unknown time zone")
+	}
+}
+
+func TestMarkdownShowsCollectionWindow(t *testing.T) {
+	ny, err := LoadTimeZone("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	r := New("us-east-1", "123456789012", 30, nil, nil, nil, nil)
+	r.GeneratedAt = time.Date(2024, 6, 1, 2, 31, 0, 0, time.UTC)
+	r.CollectionWindow = NewCollectionWindow(r.GeneratedAt.Add(-31*time.Minute), r.GeneratedAt.Add(-time.Minute), ny)
+	md := r.ToMarkdown()
+	for _, want := range []string{
+		"**Generated:** Sat, 01 Jun 2024 02:31:00 UTC (2024-05-31 22:31 EDT)",
+		"**Collection Window:** 2024-06-01 02:00–02:30 UTC (2024-05-31 22:00–22:30 EDT, America/New_York)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package savings
+
+import (
+	"fmt"
+	"time"
+)
+
+// Month is the realized and projected savings of one calendar month.
+type Month struct {
+	Month string `json:"month"` // 2006-01
+	Days  int    `json:"days"`
+	// Actual is the NAT Gateway data processing cost Cost Explorer reports.
+	Actual float64 `json:"actual"`
+	// Expected is what the month would have cost at the baseline rate.
+	Expected float64 `json:"expected"`
+	// Realized is Expected minus Actual.
+	Realized float64 `json:"realized"`
+	// Projected is the savings the remediations applied by each day were
+	// projected to bring.
+	Projected float64 `json:"projected"`
+}
+
+// Realization compares what the remediations in one account and region were
+// projected to save with what the NAT Gateway data processing cost did since
+// the first of them.
+type Realization struct {
+	AccountID     string    `json:"account_id"`
+	Region        string    `json:"region"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	BaselineStart string    `json:"baseline_start"`
+	BaselineEnd   string    `json:"baseline_end"`
+	// BaselineDaily is the average daily cost before the first remediation.
+	BaselineDaily float64 `json:"baseline_daily"`
+	Entries       []Entry `json:"entries"`
+	Months        []Month `json:"months"`
+	Realized      float64 `json:"realized"`
+	Projected     float64 `json:"projected"`
+}
+
+// Ratio is the realized share of the projected savings, 0 when nothing was
+// projected.
+func (r *Realization) Ratio() float64 {
+	if r.Projected <= 0 {
+		return 0
+	}
+	return r.Realized / r.Projected
+}
+
+// EstimatedDays is how many days before today Cost Explorer still reports
+// estimated, incomplete costs for. Realize leaves them out, along with today,
+// so that a partial day does not count as savings.
+const EstimatedDays = 2
+
+// settledEnd is the first day, counting from end, whose cost may still be an
+// estimate.
+func settledEnd(end time.Time) time.Time {
+	return day(end).AddDate(0, 0, -EstimatedDays)
+}
+
+// Window is the range of daily costs Realize needs for entries: baselineDays
+// before the first remediation up to end.
+func Window(entries []Entry, baselineDays int, end time.Time) (start time.Time, err error) {
+	if len(entries) == 0 {
+		return time.Time{}, fmt.Errorf("no remediations recorded")
+	}
+	first := day(entries[0].AppliedAt)
+	for _, e := range entries[1:] {
+		if d := day(e.AppliedAt); d.Before(first) {
+			first = d
+		}
+	}
+	if !first.Before(settledEnd(end)) {
+		return time.Time{}, fmt.Errorf("the first remediation was applied on %s; there is no settled cost data since (Cost Explorer's last %d days are estimates)", first.Format(dateLayout), EstimatedDays)
+	}
+	return first.AddDate(0, 0, -baselineDays), nil
+}
+
+// Realize measures the savings of entries from the daily NAT Gateway data
+// processing costs (by date, see Window for the range). The baseline is the
+// average day of the baselineDays before the first remediation; every day
+// from then up to end, less the EstimatedDays, is compared with it. Traffic
+// growth or decline since shows up in the realized figure as well.
+func Realize(entries []Entry, daily map[string]float64, baselineDays int, end time.Time) (*Realization, error) {
+	if baselineDays <= 0 {
+		return nil, fmt.Errorf("the baseline needs at least one day")
+	}
+	start, err := Window(entries, baselineDays, end)
+	if err != nil {
+		return nil, err
+	}
+	first := start.AddDate(0, 0, baselineDays)
+	end = settledEnd(end)
+
+	r := &Realization{
+		AccountID:     entries[0].AccountID,
+		Region:        entries[0].Region,
+		GeneratedAt:   time.Now(),
+		BaselineStart: start.Format(dateLayout),
+		BaselineEnd:   first.AddDate(0, 0, -1).Format(dateLayout),
+		Entries:       entries,
+	}
+	for d := start; d.Before(first); d = d.AddDate(0, 0, 1) {
+		r.BaselineDaily += daily[d.Format(dateLayout)]
+	}
+	r.BaselineDaily /= float64(baselineDays)
+
+	for d := first; d.Before(end); d = d.AddDate(0, 0, 1) {
+		var projected float64
+		for _, e := range entries {
+			if !day(e.AppliedAt).After(d) {
+				projected += e.ProjectedMonthly / DaysPerMonth
+			}
+		}
+		key := d.Format("2006-01")
+		if len(r.Months) == 0 || r.Months[len(r.Months)-1].Month != key {
+			r.Months = append(r.Months, Month{Month: key})
+		}
+		m := &r.Months[len(r.Months)-1]
+		actual := daily[d.Format(dateLayout)]
+		m.Days++
+		m.Actual += actual
+		m.Expected += r.BaselineDaily
+		m.Realized += r.BaselineDaily - actual
+		m.Projected += projected
+		r.Realized += r.BaselineDaily - actual
+		r.Projected += projected
+	}
+	return r, nil
+}
+
+// day truncates t to its UTC date.
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
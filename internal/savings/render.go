@@ -0,0 +1,54 @@
+package savings
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (e Entry) vpc() string {
+	if e.VPCID == "" {
+		return "-"
+	}
+	return e.VPCID
+}
+
+// Text renders the realization for the terminal.
+func (r *Realization) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Realized savings in %s/%s (NAT Gateway data processing, Cost Explorer)\n\n", r.AccountID, r.Region)
+	for _, e := range r.Entries {
+		fmt.Fprintf(&b, "  #%-3d %s  %-22s %-40s projected $%.2f/mo\n", e.ID, e.AppliedAt.Format(dateLayout), e.vpc(), e.Action, e.ProjectedMonthly)
+	}
+	fmt.Fprintf(&b, "\nBaseline: $%.2f/day (%s to %s)\n\n", r.BaselineDaily, r.BaselineStart, r.BaselineEnd)
+	fmt.Fprintf(&b, "  %-8s %4s %12s %12s %12s %12s\n", "Month", "Days", "NAT cost", "Expected", "Realized", "Projected")
+	for _, m := range r.Months {
+		fmt.Fprintf(&b, "  %-8s %4d %12s %12s %12s %12s\n", m.Month, m.Days, dollars(m.Actual), dollars(m.Expected), dollars(m.Realized), dollars(m.Projected))
+	}
+	fmt.Fprintf(&b, "\nRealized $%.2f of $%.2f projected (%.0f%%)\n", r.Realized, r.Projected, r.Ratio()*100)
+	return b.String()
+}
+
+// Markdown renders the realization for FinOps reviews.
+func (r *Realization) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## NAT Gateway remediation: realized savings\n\n")
+	fmt.Fprintf(&b, "Account `%s`, region `%s`. NAT Gateway data processing cost from Cost Explorer, compared with the average day of %s to %s ($%.2f/day).\n\n", r.AccountID, r.Region, r.BaselineStart, r.BaselineEnd, r.BaselineDaily)
+	b.WriteString("| # | Applied | VPC | Remediation | Projected/mo |\n|---|---|---|---|---|\n")
+	for _, e := range r.Entries {
+		fmt.Fprintf(&b, "| %d | %s | `%s` | %s | $%.2f |\n", e.ID, e.AppliedAt.Format(dateLayout), e.vpc(), e.Action, e.ProjectedMonthly)
+	}
+	b.WriteString("\n| Month | Days | NAT cost | Expected | Realized | Projected |\n|---|---|---|---|---|---|\n")
+	for _, m := range r.Months {
+		fmt.Fprintf(&b, "| %s | %d | %s | %s | %s | %s |\n", m.Month, m.Days, dollars(m.Actual), dollars(m.Expected), dollars(m.Realized), dollars(m.Projected))
+	}
+	fmt.Fprintf(&b, "| **Total** | | | | **%s** | **%s** |\n\n", dollars(r.Realized), dollars(r.Projected))
+	fmt.Fprintf(&b, "%.0f%% of the projected savings realized. Changes in traffic since the baseline count toward the realized figure.\n", r.Ratio()*100)
+	return b.String()
+}
+
+func dollars(v float64) string {
+	if v < 0 {
+		return fmt.Sprintf("-$%.2f", -v)
+	}
+	return fmt.Sprintf("$%.2f", v)
+}
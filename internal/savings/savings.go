@@ -0,0 +1,117 @@
+// Package savings keeps a ledger of the remediations applied after scans and
+// the savings they were projected to bring, and measures the savings realized
+// since then from the NAT Gateway data processing cost in Cost Explorer.
+package savings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/doitintl/terminator/internal/manifest"
+)
+
+// DaysPerMonth turns monthly projections into daily ones, as the cost
+// estimates do.
+const DaysPerMonth = 30
+
+// dateLayout is how days are keyed, as Cost Explorer reports them.
+const dateLayout = "2006-01-02"
+
+// Entry is one applied remediation.
+type Entry struct {
+	ID        int       `json:"id"`
+	AppliedAt time.Time `json:"applied_at"`
+	AccountID string    `json:"account_id"`
+	Region    string    `json:"region"`
+	VPCID     string    `json:"vpc_id,omitempty"`
+	Action    string    `json:"action"`
+	// ProjectedMonthly is the monthly saving the scan projected.
+	ProjectedMonthly float64 `json:"projected_monthly"`
+	Note             string  `json:"note,omitempty"`
+	RecordedBy       string  `json:"recorded_by,omitempty"`
+}
+
+// Ledger is the list of applied remediations, oldest first.
+type Ledger struct {
+	Entries []Entry `json:"entries"`
+}
+
+// DefaultPath is the ledger file: savings.json under $TERMINAT_STATE_DIR, or
+// <user config dir>/terminat/savings.json.
+func DefaultPath() (string, error) {
+	if dir := os.Getenv(manifest.StateDirEnv); dir != "" {
+		return filepath.Join(dir, "savings.json"), nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory for the savings ledger: %w", err)
+	}
+	return filepath.Join(base, "terminat", "savings.json"), nil
+}
+
+// Load reads a ledger file; a missing file is an empty ledger.
+func Load(path string) (*Ledger, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Ledger{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var l Ledger
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("%s is not a termiNATor savings ledger: %w", path, err)
+	}
+	return &l, nil
+}
+
+// Save writes the ledger file.
+func (l *Ledger) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Record adds an entry with the next free ID and returns it.
+func (l *Ledger) Record(e Entry) Entry {
+	e.ID = 1
+	for _, existing := range l.Entries {
+		if existing.ID >= e.ID {
+			e.ID = existing.ID + 1
+		}
+	}
+	l.Entries = append(l.Entries, e)
+	sort.SliceStable(l.Entries, func(i, j int) bool { return l.Entries[i].AppliedAt.Before(l.Entries[j].AppliedAt) })
+	return e
+}
+
+// Remove deletes the entry with id and reports whether there was one.
+func (l *Ledger) Remove(id int) bool {
+	for i, e := range l.Entries {
+		if e.ID == id {
+			l.Entries = append(l.Entries[:i], l.Entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// For returns the entries in accountID/region, oldest first.
+func (l *Ledger) For(accountID, region string) []Entry {
+	var out []Entry
+	for _, e := range l.Entries {
+		if e.AccountID == accountID && e.Region == region {
+			out = append(out, e)
+		}
+	}
+	return out
+}
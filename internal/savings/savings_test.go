@@ -0,0 +1,99 @@
+package savings
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestLedgerRecordRemoveAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "savings.json")
+	l, err := Load(path)
+	if err != nil || len(l.Entries) != 0 {
+		t.Fatalf("a missing ledger should load empty, got %+v, %v", l, err)
+	}
+	second := l.Record(Entry{AppliedAt: date("2026-09-10"), AccountID: "111111111111", Region: "us-east-1", Action: "DynamoDB gateway endpoint"})
+	first := l.Record(Entry{AppliedAt: date("2026-09-01"), AccountID: "111111111111", Region: "us-east-1", Action: "S3 gateway endpoint"})
+	l.Record(Entry{AppliedAt: date("2026-09-05"), AccountID: "222222222222", Region: "us-east-1", Action: "S3 gateway endpoint"})
+	if first.ID != 2 || second.ID != 1 {
+		t.Errorf("IDs = %d, %d; want them in recording order", first.ID, second.ID)
+	}
+	if err := l.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := l.For("111111111111", "us-east-1")
+	if len(got) != 2 || got[0].ID != 2 || got[1].ID != 1 {
+		t.Errorf("For() = %+v, want the account's entries oldest first", got)
+	}
+	if !l.Remove(2) || l.Remove(2) {
+		t.Error("expected Remove to delete the entry once")
+	}
+	if next := l.Record(Entry{AppliedAt: date("2026-09-20")}); next.ID != 4 {
+		t.Errorf("next ID = %d, want 4", next.ID)
+	}
+}
+
+func TestRealize(t *testing.T) {
+	entries := []Entry{
+		{ID: 1, AppliedAt: date("2026-08-30"), AccountID: "111111111111", Region: "us-east-1", Action: "S3 gateway endpoint", ProjectedMonthly: 300},
+		{ID: 2, AppliedAt: date("2026-09-01"), AccountID: "111111111111", Region: "us-east-1", Action: "ECR interface endpoints", ProjectedMonthly: 60},
+	}
+	// The 3rd and 4th are still estimates
+	end := date("2026-09-05")
+	start, err := Window(entries, 3, end)
+	if err != nil || !start.Equal(date("2026-08-27")) {
+		t.Fatalf("Window() = %v, %v", start, err)
+	}
+
+	daily := map[string]float64{
+		"2026-08-27": 20, "2026-08-28": 22, "2026-08-29": 18, // baseline: $20/day
+		"2026-08-30": 12, "2026-08-31": 10,
+		"2026-09-01": 9, "2026-09-02": 8,
+		"2026-09-03": 3, "2026-09-04": 1,
+	}
+	r, err := Realize(entries, daily, 3, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.BaselineDaily != 20 || r.BaselineStart != "2026-08-27" || r.BaselineEnd != "2026-08-29" {
+		t.Errorf("baseline = %.2f from %s to %s", r.BaselineDaily, r.BaselineStart, r.BaselineEnd)
+	}
+	if len(r.Months) != 2 || r.Months[0].Month != "2026-08" || r.Months[0].Days != 2 || r.Months[1].Days != 2 {
+		t.Fatalf("months = %+v", r.Months)
+	}
+	if r.Months[0].Realized != 18 || r.Months[1].Realized != 23 || r.Realized != 41 {
+		t.Errorf("realized = %+v, total %.2f", r.Months, r.Realized)
+	}
+	// $10/day from August 30, $12/day once the second one is applied.
+	if math.Abs(r.Months[0].Projected-20) > 1e-9 || math.Abs(r.Projected-44) > 1e-9 {
+		t.Errorf("projected = %.2f in August, %.2f in total", r.Months[0].Projected, r.Projected)
+	}
+	if !strings.Contains(r.Text(), "Realized $41.00 of $44.00 projected (93%)") {
+		t.Errorf("unexpected text:\n%s", r.Text())
+	}
+	if !strings.Contains(r.Markdown(), "| **Total** | | | | **$41.00** | **$44.00** |") {
+		t.Errorf("unexpected markdown:\n%s", r.Markdown())
+	}
+
+	if _, err := Realize(entries, daily, 3, date("2026-08-30")); err == nil {
+		t.Error("expected an error without cost data after the first remediation")
+	}
+	if _, err := Realize(entries, daily, 3, date("2026-09-01")); err == nil {
+		t.Error("expected an error with only estimated cost data after the first remediation")
+	}
+}
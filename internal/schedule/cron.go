@@ -0,0 +1,149 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed five-field cron expression: minute, hour, day of month,
+// month and day of week.
+type Cron struct {
+	minute, hour, dom, month, dow uint64 // bit i set = value i matches
+	// domAny and dowAny record a "*" day field; when both day fields are
+	// restricted, a day matching either runs, as in cron(8).
+	domAny, dowAny bool
+	loc            *time.Location
+}
+
+var macros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// ParseCron parses expr, e.g. "0 6 * * 1-5" or "@daily", with times in loc
+// (nil = UTC). Fields take *, lists, ranges and steps such as */15 or 1-5/2;
+// day of week 0 and 7 are both Sunday.
+func ParseCron(expr string, loc *time.Location) (*Cron, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	spec := strings.TrimSpace(expr)
+	if m, ok := macros[spec]; ok {
+		spec = m
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields (minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+	c := &Cron{loc: loc, domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	for i, f := range []struct {
+		bits     *uint64
+		min, max int
+		name     string
+	}{
+		{&c.minute, 0, 59, "minute"},
+		{&c.hour, 0, 23, "hour"},
+		{&c.dom, 1, 31, "day of month"},
+		{&c.month, 1, 12, "month"},
+		{&c.dow, 0, 7, "day of week"},
+	} {
+		bits, err := parseField(fields[i], f.min, f.max)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %s: %w", expr, f.name, err)
+		}
+		*f.bits = bits
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("bad step %q", stepText)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("bad value %q", a)
+			}
+			if hi, err = strconv.Atoi(b); err != nil {
+				return 0, fmt.Errorf("bad value %q", b)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("bad value %q", rng)
+			}
+			lo, hi = n, n
+			if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first time after t the expression matches, or the zero
+// time when it never does (e.g. "0 0 31 2 *").
+func (c *Cron) Next(t time.Time) time.Time {
+	t = t.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	// Five years covers every day-of-month and weekday combination, leap
+	// days included.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,139 @@
+// Package schedule reads the scans terminat serve runs on its own. Each
+// [schedule.<name>] section is a cron expression and what to scan:
+//
+//	[schedule.nightly-prod]
+//	cron = "0 2 * * *"             # minute hour day-of-month month day-of-week
+//	timezone = "Europe/Berlin"     # optional; UTC otherwise
+//	type = "deep"                  # quick (default) or deep
+//	regions = ["us-east-1", "eu-west-1"]   # optional; serve's --region otherwise
+//	profile = "prod"               # optional; serve's credentials otherwise
+//	role_arn = "arn:aws:iam::111111111111:role/TerminatorScan"   # optional, a list for a chain
+//	duration = 15                  # deep scans, minutes of Flow Logs
+//
+// Every region is scanned at each time the expression matches.
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doitintl/terminator/internal/config"
+)
+
+// Scan types.
+const (
+	TypeQuick = "quick"
+	TypeDeep  = "deep"
+)
+
+// DefaultDeepDuration is the Flow Logs collection of a deep scan schedule
+// without a duration, in minutes.
+const DefaultDeepDuration = 15
+
+// Schedule is one [schedule.<name>] section.
+type Schedule struct {
+	Name     string
+	Cron     *Cron
+	Type     string
+	Regions  []string
+	Profile  string
+	RoleARNs []string
+	// Duration is the deep scan's Flow Logs collection, in minutes.
+	Duration int
+}
+
+// Load reads the schedules of ~/.terminat/config.toml, or the document
+// given with --config.
+func Load() ([]Schedule, error) {
+	content, err := config.Read()
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content)
+}
+
+// Parse returns the schedules of a configuration document, sorted by name.
+func Parse(content string) ([]Schedule, error) {
+	seen := map[string]bool{}
+	var names []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+		name, ok := strings.CutPrefix(strings.Trim(line, "[]"), "schedule.")
+		if !ok || name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	schedules := make([]Schedule, 0, len(names))
+	for _, name := range names {
+		s, err := parseSchedule(name, config.Section(content, "schedule."+name))
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", name, err)
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, nil
+}
+
+func parseSchedule(name string, values map[string]string) (Schedule, error) {
+	s := Schedule{
+		Name:     name,
+		Type:     values["type"],
+		Regions:  splitList(values["regions"]),
+		Profile:  values["profile"],
+		RoleARNs: splitList(values["role_arn"]),
+	}
+	if values["cron"] == "" {
+		return s, fmt.Errorf("no cron expression")
+	}
+	loc := time.UTC
+	if tz := values["timezone"]; tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return s, fmt.Errorf("timezone: %w", err)
+		}
+	}
+	cron, err := ParseCron(values["cron"], loc)
+	if err != nil {
+		return s, err
+	}
+	s.Cron = cron
+
+	switch s.Type {
+	case "":
+		s.Type = TypeQuick
+	case TypeQuick, TypeDeep:
+	default:
+		return s, fmt.Errorf("type %q is neither quick nor deep", s.Type)
+	}
+	if d := values["duration"]; d != "" {
+		if s.Type != TypeDeep {
+			return s, fmt.Errorf("duration only applies to deep scans")
+		}
+		if s.Duration, err = strconv.Atoi(d); err != nil || s.Duration < 5 || s.Duration > 60 {
+			return s, fmt.Errorf("duration must be between 5 and 60 minutes")
+		}
+	} else if s.Type == TypeDeep {
+		s.Duration = DefaultDeepDuration
+	}
+	return s, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,100 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, 6, 1, 10, 30, 45, 0, time.UTC) // a Saturday
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 6, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 6 * * *", time.Date(2024, 6, 2, 6, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC)},
+		{"0 6 * * 1-5", time.Date(2024, 6, 3, 6, 0, 0, 0, time.UTC)},
+		{"30 2 1 * *", time.Date(2024, 7, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 15th or any Sunday, whichever is first.
+		{"0 0 15 * 7", time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, c := range cases {
+		cron, err := ParseCron(c.expr, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", c.expr, err)
+		}
+		if got := cron.Next(from); !got.Equal(c.want) {
+			t.Errorf("%s: next = %v, want %v", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestCronTimezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	cron, err := ParseCron("0 6 * * *", berlin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cron.Next(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2024, 6, 1, 4, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("next = %v, want %v", got.UTC(), want)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr, nil); err == nil {
+			t.Errorf("%q parsed", expr)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	schedules, err := Parse(`
+[schedule.nightly]
+cron = "0 2 * * *"
+type = "deep"
+regions = ["us-east-1", "eu-west-1"]
+role_arn = "arn:aws:iam::111111111111:role/Scan"
+
+[schedule.hourly]
+cron = "@hourly"
+regions = ["us-east-1"]
+profile = "prod"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedules) != 2 || schedules[0].Name != "hourly" || schedules[1].Name != "nightly" {
+		t.Fatalf("schedules = %+v", schedules)
+	}
+	hourly, nightly := schedules[0], schedules[1]
+	if hourly.Type != TypeQuick || hourly.Profile != "prod" || hourly.Duration != 0 {
+		t.Errorf("hourly = %+v", hourly)
+	}
+	if nightly.Type != TypeDeep || nightly.Duration != DefaultDeepDuration || len(nightly.Regions) != 2 || len(nightly.RoleARNs) != 1 {
+		t.Errorf("nightly = %+v", nightly)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct{ section, want string }{
+		{`regions = ["us-east-1"]`, "no cron expression"},
+		{"cron = \"@daily\"\ntype = \"full\"", "neither quick nor deep"},
+		{"cron = \"@daily\"\nduration = 15", "only applies to deep scans"},
+		{"cron = \"@daily\"\ntype = \"deep\"\nduration = 90", "between 5 and 60"},
+		{"cron = \"@daily\"\ntimezone = \"Mars/Olympus\"", "timezone"},
+	} {
+		_, err := Parse("[schedule.bad]\n" + tc.section)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q: err = %v, want %q", tc.section, err, tc.want)
+		}
+	}
+}
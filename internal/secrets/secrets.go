@@ -0,0 +1,91 @@
+// Package secrets keeps credentials such as the DataHub API key and the
+// keys and tokens of notification targets in the OS keyring (macOS
+// Keychain, Secret Service on Linux, Windows Credential Manager) instead of
+// the plain-text config file.
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// service is the keyring service every secret is stored under.
+const service = "terminat"
+
+// Names of the stored secrets.
+const (
+	DataHubAPIKey = "datahub-api-key"
+)
+
+// NotifyTarget names the secret holding the credentials of a
+// [notify.<target>] section (routing_key, api_key, secret, webhook_url,
+// header.<Name>, ...), stored together as a JSON object.
+func NotifyTarget(target string) string {
+	return "notify." + target
+}
+
+// Settings returns the settings stored under name with SetSetting.
+func Settings(name string) (map[string]string, error) {
+	data, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+	settings := map[string]string{}
+	if err := json.Unmarshal([]byte(data), &settings); err != nil {
+		return nil, fmt.Errorf("secret %s is not a settings object: %w", name, err)
+	}
+	return settings, nil
+}
+
+// SetSetting stores key = value among the settings under name, keeping the
+// others.
+func SetSetting(name, key, value string) error {
+	settings, err := Settings(name)
+	if errors.Is(err, ErrNotFound) {
+		settings, err = map[string]string{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	settings[key] = value
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return Set(name, string(data))
+}
+
+// ErrNotFound is returned by Get when the secret is not stored.
+var ErrNotFound = keyring.ErrNotFound
+
+// insecureFallback is set by --insecure-config.
+var insecureFallback bool
+
+// SetInsecureFallback records whether secrets may be written to the config
+// file in plain text when the keyring cannot be used.
+func SetInsecureFallback(ok bool) { insecureFallback = ok }
+
+// InsecureFallback reports whether --insecure-config was given.
+func InsecureFallback() bool { return insecureFallback }
+
+// Get returns the named secret from the keyring.
+func Get(name string) (string, error) {
+	return keyring.Get(service, name)
+}
+
+// Set stores the named secret in the keyring, replacing any earlier value.
+func Set(name, value string) error {
+	return keyring.Set(service, name, value)
+}
+
+// Delete removes the named secret from the keyring. A secret that is not
+// stored is not an error.
+func Delete(name string) error {
+	if err := keyring.Delete(service, name); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	keyring.MockInit()
+
+	if _, err := Get(DataHubAPIKey); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get before Set: err = %v, want ErrNotFound", err)
+	}
+	if err := Set(DataHubAPIKey, "key-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Get(DataHubAPIKey); err != nil || got != "key-1" {
+		t.Fatalf("Get = %q, %v; want key-1", got, err)
+	}
+	if err := Delete(DataHubAPIKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(DataHubAPIKey); err != nil {
+		t.Fatalf("deleting a missing secret: %v", err)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	keyring.MockInit()
+	name := NotifyTarget("ops")
+
+	if _, err := Settings(name); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Settings before SetSetting: err = %v, want ErrNotFound", err)
+	}
+	if err := SetSetting(name, "secret", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSetting(name, "header.Authorization", "Bearer abc"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Settings(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["secret"] != "s3cr3t" || got["header.Authorization"] != "Bearer abc" || len(got) != 2 {
+		t.Fatalf("Settings = %v", got)
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/config"
+)
+
+// Roles a token grants. Viewers list runs and read reports; operators can
+// also trigger scans.
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+)
+
+// Token is one [serve.token.<name>] section of the config:
+//
+//	[serve.token.portal]
+//	role = "viewer"                 # viewer or operator
+//	token_sha256 = "9f86d08..."     # hex SHA-256 of the token
+//
+//	[serve.token.ci]
+//	role = "operator"
+//	token_env = "TERMINAT_CI_TOKEN" # or read the token from this variable
+//
+// Clients send it as "Authorization: Bearer <token>".
+type Token struct {
+	Name string
+	Role string
+	hash [sha256.Size]byte
+}
+
+// NewToken returns a token named name granting role, for tests and callers
+// that hold the secret themselves.
+func NewToken(name, role, secret string) Token {
+	return Token{Name: name, Role: role, hash: sha256.Sum256([]byte(secret))}
+}
+
+// LoadTokens reads the tokens of ~/.terminat/config.toml, or the document
+// given with --config.
+func LoadTokens() ([]Token, error) {
+	content, err := config.Read()
+	if err != nil {
+		return nil, err
+	}
+	return ParseTokens(content)
+}
+
+// ParseTokens returns the tokens of a configuration document, sorted by
+// name. A token without a role or a secret is an error rather than left
+// out, so a typo cannot lock everyone out or open the API.
+func ParseTokens(content string) ([]Token, error) {
+	seen := map[string]bool{}
+	var names []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+		name, ok := strings.CutPrefix(strings.Trim(line, "[]"), "serve.token.")
+		if !ok || name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tokens := make([]Token, 0, len(names))
+	for _, name := range names {
+		values := config.Section(content, "serve.token."+name)
+		t := Token{Name: name, Role: values["role"]}
+		if t.Role != RoleViewer && t.Role != RoleOperator {
+			return nil, fmt.Errorf("token %q: role must be viewer or operator", name)
+		}
+		switch {
+		case values["token_sha256"] != "":
+			sum, err := hex.DecodeString(values["token_sha256"])
+			if err != nil || len(sum) != sha256.Size {
+				return nil, fmt.Errorf("token %q: token_sha256 is not a hex SHA-256", name)
+			}
+			copy(t.hash[:], sum)
+		case values["token_env"] != "":
+			secret := os.Getenv(values["token_env"])
+			if secret == "" {
+				return nil, fmt.Errorf("token %q: %s is not set", name, values["token_env"])
+			}
+			t.hash = sha256.Sum256([]byte(secret))
+		default:
+			return nil, fmt.Errorf("token %q: set token_sha256 or token_env", name)
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+// authenticate returns the token a request carries. Every configured token
+// is compared, in constant time, so the response time does not tell which
+// one nearly matched.
+func (s *Server) authenticate(req *http.Request) (Token, bool) {
+	secret, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || secret == "" {
+		return Token{}, false
+	}
+	sum := sha256.Sum256([]byte(secret))
+	var found Token
+	matched := false
+	for _, t := range s.opts.Tokens {
+		if subtle.ConstantTimeCompare(sum[:], t.hash[:]) == 1 {
+			found, matched = t, true
+		}
+	}
+	return found, matched
+}
+
+// require wraps h so it only runs for a token granting role; operators may
+// do everything viewers may. Without configured tokens every request is let
+// through.
+func (s *Server) require(role string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if len(s.opts.Tokens) == 0 {
+			h(w, req)
+			return
+		}
+		t, ok := s.authenticate(req)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="terminat"`)
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("a valid bearer token is required"))
+			return
+		}
+		if role == RoleOperator && t.Role != RoleOperator {
+			writeError(w, http.StatusForbidden, fmt.Errorf("token %s is a viewer; triggering scans needs an operator token", t.Name))
+			return
+		}
+		h(w, req.WithContext(withToken(req.Context(), t)))
+	}
+}
+
+type tokenKey struct{}
+
+func withToken(ctx context.Context, t Token) context.Context {
+	return context.WithValue(ctx, tokenKey{}, t)
+}
+
+// tokenFrom returns the token a request was let through with.
+func tokenFrom(ctx context.Context) (Token, bool) {
+	t, ok := ctx.Value(tokenKey{}).(Token)
+	return t, ok
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/internal/report"
+)
+
+func TestParseTokens(t *testing.T) {
+	t.Setenv("TERMINAT_CI_TOKEN", "s3cret")
+	tokens, err := ParseTokens(`
+[serve.token.portal]
+role = "viewer"
+token_sha256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+[serve.token.ci]
+role = "operator"
+token_env = "TERMINAT_CI_TOKEN"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 || tokens[0].Name != "ci" || tokens[1].Name != "portal" {
+		t.Fatalf("tokens = %+v", tokens)
+	}
+	if tokens[0] != NewToken("ci", RoleOperator, "s3cret") || tokens[1] != NewToken("portal", RoleViewer, "test") {
+		t.Errorf("tokens = %+v", tokens)
+	}
+}
+
+func TestParseTokensErrors(t *testing.T) {
+	for _, tc := range []struct{ section, want string }{
+		{`token_sha256 = "00"`, "role must be viewer or operator"},
+		{`role = "admin"`, "role must be viewer or operator"},
+		{`role = "viewer"`, "set token_sha256 or token_env"},
+		{"role = \"viewer\"\ntoken_sha256 = \"abc\"", "not a hex SHA-256"},
+		{"role = \"viewer\"\ntoken_env = \"TERMINAT_UNSET_TOKEN\"", "TERMINAT_UNSET_TOKEN is not set"},
+	} {
+		_, err := ParseTokens("[serve.token.bad]\n" + tc.section)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q: err = %v, want %q", tc.section, err, tc.want)
+		}
+	}
+}
+
+func TestTokenRoles(t *testing.T) {
+	s := New(Options{
+		Region: "us-east-1",
+		Scan: func(ctx context.Context, req Request) (*report.Report, error) {
+			return report.New(req.Region, "123456789012", 0, nil, nil, nil, nil), nil
+		},
+		NewRunID: func() string { return "terminat-1717243200" },
+		Tokens:   []Token{NewToken("portal", RoleViewer, "view"), NewToken("ci", RoleOperator, "operate")},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Serve(ctx)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	call := func(method, path, token string) *http.Response {
+		req, _ := http.NewRequest(method, ts.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	for _, tc := range []struct {
+		method, path, token string
+		want                int
+	}{
+		{"GET", "/runs", "", http.StatusUnauthorized},
+		{"GET", "/runs", "wrong", http.StatusUnauthorized},
+		{"GET", "/runs", "view", http.StatusOK},
+		{"POST", "/runs", "view", http.StatusForbidden},
+		{"POST", "/runs", "operate", http.StatusAccepted},
+		{"GET", "/runs", "operate", http.StatusOK},
+	} {
+		if resp := call(tc.method, tc.path, tc.token); resp.StatusCode != tc.want {
+			t.Errorf("%s %s with %q = %d, want %d", tc.method, tc.path, tc.token, resp.StatusCode, tc.want)
+		}
+	}
+	if runs := s.Runs(); len(runs) != 1 || runs[0].TriggeredBy != "ci" {
+		t.Errorf("runs = %+v", runs)
+	}
+}
@@ -0,0 +1,406 @@
+// Package server is the HTTP API of terminat serve. Internal portals list
+// runs, trigger quick scans and fetch their JSON reports over it instead of
+// shelling out:
+//
+//	GET  /runs                   runs, newest first
+//	POST /runs                   queue a quick scan, {"region": "eu-west-1"} optional
+//	GET  /runs/{id}              one run, for polling
+//	GET  /runs/{id}/report.json  the report of a finished run
+//
+// With tokens configured, every request needs a viewer or operator token,
+// and POST /runs an operator one. Schedules queue quick and deep scans on
+// their own. Runs are kept in
+// memory; quick scans run one at a time, and deep scans one at a time
+// beside them, so a deep scan's collection does not hold up quick scans.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/doitintl/terminator/internal/schedule"
+)
+
+// Run states.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// DefaultMaxRuns is how many finished runs are kept when Options.MaxRuns is 0.
+const DefaultMaxRuns = 100
+
+// Request is what a scan is asked to cover. Only schedules choose the
+// credentials and the deep scan duration.
+type Request struct {
+	Region string `json:"region,omitempty"`
+	// Type is schedule.TypeQuick (empty) or schedule.TypeDeep.
+	Type     string   `json:"type,omitempty"`
+	Profile  string   `json:"-"`
+	RoleARNs []string `json:"-"`
+	Duration int      `json:"-"`
+}
+
+// ScanFunc runs a scan and returns its report.
+type ScanFunc func(ctx context.Context, req Request) (*report.Report, error)
+
+// Run is one triggered scan.
+type Run struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Region string `json:"region"`
+	// Schedule names the schedule that queued the run; empty for POST /runs.
+	Schedule string `json:"schedule,omitempty"`
+	// TriggeredBy names the token that queued the run with POST /runs.
+	TriggeredBy string     `json:"triggered_by,omitempty"`
+	AccountID   string     `json:"account_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	// NATGateways and Findings count what a finished scan found.
+	NATGateways int    `json:"nat_gateways"`
+	Findings    int    `json:"findings"`
+	Error       string `json:"error,omitempty"`
+	ReportURL   string `json:"report_url,omitempty"`
+
+	req    Request
+	report *report.Report
+}
+
+// Options configure a Server.
+type Options struct {
+	// Region is scanned when a request or schedule names none.
+	Region string
+	Scan   ScanFunc
+	// Schedules queue runs whenever their cron expression matches.
+	Schedules []schedule.Schedule
+	// Tokens, when set, are required on every request (see Token).
+	Tokens []Token
+	// NewRunID names runs; an ID returned again gets a -2, -3... suffix.
+	NewRunID func() string
+	// MaxRuns caps the runs kept; the oldest finished ones are dropped first.
+	MaxRuns int
+	// Log is told about every run that starts and finishes (nil = silent).
+	Log func(format string, args ...any)
+
+	// after waits for the next scheduled run; tests replace time.After.
+	after func(time.Duration) <-chan time.Time
+}
+
+// Server queues and runs scans and serves their results. Start the worker
+// with Serve before handing Handler to an http.Server.
+type Server struct {
+	opts  Options
+	mu    sync.Mutex
+	runs  map[string]*Run
+	order []string // run IDs, oldest first
+	queue chan string
+	deep  chan string
+	// lastID and repeats suffix IDs issued within the same second, so a
+	// dropped run's ID is never handed out again.
+	lastID  string
+	repeats int
+}
+
+// New returns a Server.
+func New(opts Options) *Server {
+	if opts.MaxRuns <= 0 {
+		opts.MaxRuns = DefaultMaxRuns
+	}
+	if opts.Log == nil {
+		opts.Log = func(string, ...any) {}
+	}
+	if opts.after == nil {
+		opts.after = time.After
+	}
+	return &Server{
+		opts:  opts,
+		runs:  map[string]*Run{},
+		queue: make(chan string, opts.MaxRuns),
+		deep:  make(chan string, opts.MaxRuns),
+	}
+}
+
+// Serve runs the schedules and the queued scans until ctx is done. A deep
+// scan cut short by ctx still stops its Flow Logs before it returns.
+func (s *Server) Serve(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, sc := range s.opts.Schedules {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runSchedule(ctx, sc)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.work(ctx, s.deep)
+	}()
+	s.work(ctx, s.queue)
+	wg.Wait()
+}
+
+// work runs the scans of one queue, one at a time.
+func (s *Server) work(ctx context.Context, queue chan string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-queue:
+			s.run(ctx, id)
+		}
+	}
+}
+
+// runSchedule queues sc's scans each time its cron expression matches. A
+// region whose previous scheduled run has not finished is skipped, so a
+// slow account does not pile up runs.
+func (s *Server) runSchedule(ctx context.Context, sc schedule.Schedule) {
+	regions := sc.Regions
+	if len(regions) == 0 {
+		regions = []string{s.opts.Region}
+	}
+	for {
+		next := sc.Cron.Next(time.Now())
+		if next.IsZero() {
+			s.opts.Log("Schedule %s never runs", sc.Name)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.opts.after(time.Until(next)):
+		}
+		for _, region := range regions {
+			req := Request{Region: region, Type: sc.Type, Profile: sc.Profile, RoleARNs: sc.RoleARNs, Duration: sc.Duration}
+			r, err := s.enqueue(req, sc.Name, "")
+			if err != nil {
+				s.opts.Log("Schedule %s: %s scan of %s skipped: %v", sc.Name, sc.Type, region, err)
+				continue
+			}
+			s.opts.Log("Schedule %s: queued run %s, %s scan of %s", sc.Name, r.ID, sc.Type, region)
+		}
+	}
+}
+
+func (s *Server) run(ctx context.Context, id string) {
+	s.mu.Lock()
+	r := s.runs[id]
+	if r == nil {
+		s.mu.Unlock()
+		return
+	}
+	started := time.Now().UTC()
+	r.Status, r.StartedAt = StatusRunning, &started
+	req := r.req
+	s.mu.Unlock()
+	s.opts.Log("Run %s: %s scan of %s started", id, r.Type, req.Region)
+
+	rep, err := s.opts.Scan(ctx, req)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	finished := time.Now().UTC()
+	r.FinishedAt = &finished
+	if err != nil {
+		r.Status, r.Error = StatusFailed, err.Error()
+		s.opts.Log("Run %s failed: %v", id, err)
+		return
+	}
+	r.Status, r.report = StatusSucceeded, rep
+	r.AccountID = rep.AccountID
+	r.NATGateways, r.Findings = len(rep.NATGateways), len(rep.Findings)
+	r.ReportURL = "/runs/" + id + "/report.json"
+	s.opts.Log("Run %s finished: %d NAT Gateway(s), %d finding(s)", id, r.NATGateways, r.Findings)
+}
+
+// Handler serves the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /runs", s.require(RoleViewer, s.listRuns))
+	mux.HandleFunc("POST /runs", s.require(RoleOperator, s.createRun))
+	mux.HandleFunc("GET /runs/{id}", s.require(RoleViewer, s.getRun))
+	mux.HandleFunc("GET /runs/{id}/report.json", s.require(RoleViewer, s.getReport))
+	return mux
+}
+
+func (s *Server) listRuns(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	runs := make([]Run, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		runs = append(runs, *s.runs[s.order[i]])
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"runs": runs})
+}
+
+func (s *Server) createRun(w http.ResponseWriter, req *http.Request) {
+	var body Request
+	if err := json.NewDecoder(io.LimitReader(req.Body, 1<<16)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("request body is not JSON: %w", err))
+		return
+	}
+	if body.Type != "" && body.Type != schedule.TypeQuick {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("only quick scans can be triggered; deep scans run from [schedule.<name>] sections"))
+		return
+	}
+	if body.Region == "" && s.opts.Region == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("region is required: the server has no default region"))
+		return
+	}
+
+	var triggeredBy string
+	if t, ok := tokenFrom(req.Context()); ok {
+		triggeredBy = t.Name
+	}
+	created, err := s.enqueue(body, "", triggeredBy)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	w.Header().Set("Location", "/runs/"+created.ID)
+	writeJSON(w, http.StatusAccepted, created)
+}
+
+// enqueue queues a run of req and returns a copy of it.
+func (s *Server) enqueue(req Request, scheduleName, triggeredBy string) (Run, error) {
+	if req.Region == "" {
+		req.Region = s.opts.Region
+	}
+	if req.Type == "" {
+		req.Type = schedule.TypeQuick
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if scheduleName != "" {
+		for _, id := range s.order {
+			if r := s.runs[id]; r.Schedule == scheduleName && r.Region == req.Region && (r.Status == StatusQueued || r.Status == StatusRunning) {
+				return Run{}, fmt.Errorf("run %s of the schedule is still %s", r.ID, r.Status)
+			}
+		}
+	}
+	if !s.makeRoom() {
+		return Run{}, fmt.Errorf("%d runs are queued or running; try again later", s.opts.MaxRuns)
+	}
+	r := &Run{
+		ID:          s.uniqueID(),
+		Type:        req.Type,
+		Status:      StatusQueued,
+		Region:      req.Region,
+		Schedule:    scheduleName,
+		TriggeredBy: triggeredBy,
+		CreatedAt:   time.Now().UTC(),
+		req:         req,
+	}
+	s.runs[r.ID] = r
+	s.order = append(s.order, r.ID)
+	if r.Type == schedule.TypeDeep {
+		s.deep <- r.ID
+	} else {
+		s.queue <- r.ID
+	}
+	return *r, nil
+}
+
+// makeRoom drops the oldest finished runs until a new one fits. It reports
+// false when every kept run is still queued or running.
+func (s *Server) makeRoom() bool {
+	for len(s.order) >= s.opts.MaxRuns {
+		dropped := false
+		for i, id := range s.order {
+			if st := s.runs[id].Status; st == StatusSucceeded || st == StatusFailed {
+				delete(s.runs, id)
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				dropped = true
+				break
+			}
+		}
+		if !dropped {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) uniqueID() string {
+	base := s.opts.NewRunID()
+	if base != s.lastID {
+		s.lastID, s.repeats = base, 0
+		return base
+	}
+	s.repeats++
+	return base + "-" + strconv.Itoa(s.repeats+1)
+}
+
+func (s *Server) getRun(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lookup(req.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no run %s", req.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, r)
+}
+
+func (s *Server) getReport(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lookup(req.PathValue("id"))
+	switch {
+	case !ok:
+		writeError(w, http.StatusNotFound, fmt.Errorf("no run %s", req.PathValue("id")))
+	case r.Status == StatusFailed:
+		writeError(w, http.StatusConflict, fmt.Errorf("run %s failed: %s", r.ID, r.Error))
+	case r.report == nil:
+		writeError(w, http.StatusConflict, fmt.Errorf("run %s is %s; poll /runs/%s until it has succeeded", r.ID, r.Status, r.ID))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		_ = r.report.WriteJSON(w)
+	}
+}
+
+// lookup returns a copy of a run, safe to read without the lock.
+func (s *Server) lookup(id string) (Run, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.runs[id]
+	if !ok {
+		return Run{}, false
+	}
+	return *r, true
+}
+
+// Runs returns the kept runs sorted by ID, for tests and shutdown messages.
+func (s *Server) Runs() []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := make([]Run, 0, len(s.runs))
+	for _, r := range s.runs {
+		runs = append(runs, *r)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID < runs[j].ID })
+	return runs
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/doitintl/terminator/internal/schedule"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func testServer(t *testing.T, scan ScanFunc) (*Server, *httptest.Server) {
+	t.Helper()
+	s := New(Options{
+		Region:   "us-east-1",
+		Scan:     scan,
+		NewRunID: func() string { return "terminat-1717243200" },
+		MaxRuns:  2,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.Serve(ctx)
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(func() {
+		ts.Close()
+		cancel()
+	})
+	return s, ts
+}
+
+func do(t *testing.T, method, url, body string, v any) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp
+}
+
+// waitFor polls a run until it is no longer queued or running.
+func waitFor(t *testing.T, ts *httptest.Server, id string) Run {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		var r Run
+		do(t, "GET", ts.URL+"/runs/"+id, "", &r)
+		if r.Status == StatusSucceeded || r.Status == StatusFailed {
+			return r
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("run %s did not finish", id)
+	return Run{}
+}
+
+func TestTriggerAndFetchReport(t *testing.T) {
+	_, ts := testServer(t, func(ctx context.Context, req Request) (*report.Report, error) {
+		rep := report.New(req.Region, "123456789012", 0, []types.NATGateway{{ID: "nat-1"}}, nil, nil, nil)
+		rep.Findings = []types.Finding{{Type: "missing-endpoint", VPCID: "vpc-1", Service: "S3"}}
+		return rep, nil
+	})
+
+	var created Run
+	resp := do(t, "POST", ts.URL+"/runs", `{"region": "eu-west-1"}`, &created)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /runs = %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Location") != "/runs/"+created.ID || created.Region != "eu-west-1" {
+		t.Errorf("created = %+v, Location %q", created, resp.Header.Get("Location"))
+	}
+
+	r := waitFor(t, ts, created.ID)
+	if r.Status != StatusSucceeded || r.AccountID != "123456789012" || r.NATGateways != 1 || r.Findings != 1 {
+		t.Fatalf("run = %+v", r)
+	}
+
+	var rep report.Report
+	if resp := do(t, "GET", ts.URL+r.ReportURL, "", &rep); resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s = %d", r.ReportURL, resp.StatusCode)
+	}
+	if rep.Region != "eu-west-1" || len(rep.Findings) != 1 {
+		t.Errorf("report = %+v", rep)
+	}
+}
+
+func TestListRunsNewestFirst(t *testing.T) {
+	_, ts := testServer(t, func(ctx context.Context, req Request) (*report.Report, error) {
+		return nil, errors.New("no credentials")
+	})
+
+	var first, second Run
+	do(t, "POST", ts.URL+"/runs", "", &first)
+	do(t, "POST", ts.URL+"/runs", "", &second)
+	if first.ID == second.ID || second.ID != first.ID+"-2" {
+		t.Fatalf("run IDs %q and %q", first.ID, second.ID)
+	}
+	if first.Region != "us-east-1" {
+		t.Errorf("default region = %q", first.Region)
+	}
+	waitFor(t, ts, second.ID)
+
+	var list struct{ Runs []Run }
+	do(t, "GET", ts.URL+"/runs", "", &list)
+	if len(list.Runs) != 2 || list.Runs[0].ID != second.ID {
+		t.Fatalf("runs = %+v", list.Runs)
+	}
+	if list.Runs[0].Status != StatusFailed || list.Runs[0].Error != "no credentials" {
+		t.Errorf("failed run = %+v", list.Runs[0])
+	}
+
+	// A third run drops the oldest finished one.
+	var third Run
+	do(t, "POST", ts.URL+"/runs", "", &third)
+	if resp := do(t, "GET", ts.URL+"/runs/"+first.ID, "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("oldest run still kept: %d", resp.StatusCode)
+	}
+}
+
+func TestReportNotReady(t *testing.T) {
+	release := make(chan struct{})
+	_, ts := testServer(t, func(ctx context.Context, req Request) (*report.Report, error) {
+		<-release
+		return nil, errors.New("access denied")
+	})
+
+	var created Run
+	do(t, "POST", ts.URL+"/runs", "", &created)
+	if resp := do(t, "GET", ts.URL+"/runs/"+created.ID+"/report.json", "", nil); resp.StatusCode != http.StatusConflict {
+		t.Errorf("report of an unfinished run = %d", resp.StatusCode)
+	}
+	close(release)
+	waitFor(t, ts, created.ID)
+
+	var body map[string]string
+	if resp := do(t, "GET", ts.URL+"/runs/"+created.ID+"/report.json", "", &body); resp.StatusCode != http.StatusConflict || !strings.Contains(body["error"], "access denied") {
+		t.Errorf("report of a failed run = %d %v", resp.StatusCode, body)
+	}
+	if resp := do(t, "GET", ts.URL+"/runs/nope/report.json", "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("report of an unknown run = %d", resp.StatusCode)
+	}
+}
+
+func TestCreateRunRejectsBadJSON(t *testing.T) {
+	_, ts := testServer(t, nil)
+	if resp := do(t, "POST", ts.URL+"/runs", "{", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("POST /runs with bad JSON = %d", resp.StatusCode)
+	}
+}
+
+func TestSchedulesQueueDeepScans(t *testing.T) {
+	cron, err := schedule.ParseCron("0 2 * * *", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tick := make(chan time.Time)
+	release := make(chan struct{})
+	scanned := make(chan Request, 4)
+	s := New(Options{
+		Region: "us-east-1",
+		Scan: func(ctx context.Context, req Request) (*report.Report, error) {
+			scanned <- req
+			<-release
+			return report.New(req.Region, "123456789012", req.Duration, nil, nil, nil, nil), nil
+		},
+		Schedules: []schedule.Schedule{{Name: "nightly", Cron: cron, Type: schedule.TypeDeep, Regions: []string{"us-east-1", "eu-west-1"}, RoleARNs: []string{"arn:aws:iam::111111111111:role/Scan"}, Duration: 30}},
+		NewRunID:  func() string { return "terminat-1717243200" },
+		after:     func(time.Duration) <-chan time.Time { return tick },
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Serve(ctx)
+
+	tick <- time.Now()
+	req := <-scanned
+	if req.Type != schedule.TypeDeep || req.Region != "us-east-1" || req.Duration != 30 || len(req.RoleARNs) != 1 {
+		t.Fatalf("scheduled request = %+v", req)
+	}
+
+	// Both regions are still running or queued, so the next firings skip
+	// them; the last send waits for the one before it to be handled.
+	tick <- time.Now()
+	tick <- time.Now()
+	runs := s.Runs()
+	if len(runs) != 2 {
+		t.Fatalf("runs after an overlapping firing = %+v", runs)
+	}
+	for _, r := range runs {
+		if r.Schedule != "nightly" || r.Type != schedule.TypeDeep {
+			t.Errorf("run = %+v", r)
+		}
+	}
+
+	close(release)
+	if req := <-scanned; req.Region != "eu-west-1" {
+		t.Errorf("second region = %q", req.Region)
+	}
+}
+
+func TestCreateRunRejectsDeepScans(t *testing.T) {
+	_, ts := testServer(t, nil)
+	if resp := do(t, "POST", ts.URL+"/runs", `{"type": "deep"}`, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("POST /runs of a deep scan = %d", resp.StatusCode)
+	}
+}
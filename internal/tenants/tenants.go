@@ -0,0 +1,135 @@
+// Package tenants reads the customer organizations an MSP or consultancy
+// scans on their behalf. Each [tenant.<name>] section maps a customer to the
+// role to assume in their account and the regions to scan:
+//
+//	[tenant.acme]
+//	role_arn = "arn:aws:iam::111111111111:role/TerminatorReadOnly"
+//	regions = ["us-east-1", "eu-west-1"]
+//	profile = "msp-hub"
+//	doit_customer_context = "acme.com"
+//
+// role_arn may be a list, assumed in order as a chain. profile is the base
+// credentials the role is assumed from; without it the default chain is used.
+// doit_customer_context files the tenant's DataHub events under its DoiT
+// customer.
+package tenants
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/doitintl/terminator/internal/config"
+)
+
+// Tenant is one customer organization.
+type Tenant struct {
+	Name     string
+	RoleARNs []string
+	Regions  []string
+	Profile  string
+	// CustomerContext is the DoiT customer the tenant's DataHub events are
+	// sent under; empty uses --doit-customer-context or the [datahub] one.
+	CustomerContext string
+}
+
+// Load reads the tenants from path, or from the [tenant.<name>] sections of
+// ~/.terminat/config.toml (or the document given with --config) when path is
+// empty.
+func Load(path string) ([]Tenant, error) {
+	if path == "" {
+		content, err := config.Read()
+		if err != nil {
+			return nil, err
+		}
+		return Parse(content)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(data))
+}
+
+// Parse returns the tenants of a configuration document, sorted by name. A
+// tenant without a role is an error: scanning it would scan the caller's own
+// account instead.
+func Parse(content string) ([]Tenant, error) {
+	seen := map[string]bool{}
+	var names []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+		name, ok := strings.CutPrefix(strings.Trim(line, "[]"), "tenant.")
+		if !ok || name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tenants := make([]Tenant, 0, len(names))
+	for _, name := range names {
+		values := config.Section(content, "tenant."+name)
+		t := Tenant{
+			Name:            name,
+			RoleARNs:        splitList(values["role_arn"]),
+			Regions:         splitList(values["regions"]),
+			Profile:         values["profile"],
+			CustomerContext: values["doit_customer_context"],
+		}
+		if len(t.RoleARNs) == 0 {
+			return nil, fmt.Errorf("tenant %q has no role_arn", name)
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, nil
+}
+
+// Select returns the tenants named in names, in that order, or every tenant
+// when all is set. An unknown name is an error listing what is configured.
+func Select(tenants []Tenant, names []string, all bool) ([]Tenant, error) {
+	if len(tenants) == 0 {
+		return nil, fmt.Errorf("no tenants configured: add [tenant.<name>] sections with a role_arn and regions")
+	}
+	if all {
+		return tenants, nil
+	}
+	var selected []Tenant
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, t := range tenants {
+			if t.Name == name {
+				selected = append(selected, t)
+				found = true
+				break
+			}
+		}
+		if !found {
+			configured := make([]string, 0, len(tenants))
+			for _, t := range tenants {
+				configured = append(configured, t.Name)
+			}
+			return nil, fmt.Errorf("tenant %q is not configured (configured: %s)", name, strings.Join(configured, ", "))
+		}
+	}
+	return selected, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,63 @@
+package tenants
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := `
+[scan]
+region = "us-east-1"
+
+[tenant.globex]
+role_arn = ["arn:aws:iam::111111111111:role/Hub", "arn:aws:iam::222222222222:role/TerminatorReadOnly"]
+
+[tenant.acme]
+# Production org
+role_arn = "arn:aws:iam::333333333333:role/TerminatorReadOnly"
+regions = ["us-east-1", "eu-west-1"]
+profile = "msp-hub"
+doit_customer_context = "acme.com"
+`
+	tenants, err := Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tenants) != 2 {
+		t.Fatalf("got %d tenants, want 2: %+v", len(tenants), tenants)
+	}
+	acme := tenants[0]
+	if acme.Name != "acme" || acme.Profile != "msp-hub" || acme.CustomerContext != "acme.com" || len(acme.RoleARNs) != 1 || strings.Join(acme.Regions, ",") != "us-east-1,eu-west-1" {
+		t.Fatalf("acme = %+v", acme)
+	}
+	globex := tenants[1]
+	if globex.Name != "globex" || len(globex.RoleARNs) != 2 || len(globex.Regions) != 0 || globex.CustomerContext != "" {
+		t.Fatalf("globex = %+v", globex)
+	}
+}
+
+func TestParseRequiresRole(t *testing.T) {
+	if _, err := Parse("[tenant.acme]\nregions = [\"us-east-1\"]\n"); err == nil || !strings.Contains(err.Error(), "role_arn") {
+		t.Fatalf("expected missing role_arn error, got %v", err)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tenants := []Tenant{{Name: "acme"}, {Name: "globex"}}
+
+	all, err := Select(tenants, nil, true)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("all tenants = %+v, %v", all, err)
+	}
+	one, err := Select(tenants, []string{"globex"}, false)
+	if err != nil || len(one) != 1 || one[0].Name != "globex" {
+		t.Fatalf("globex = %+v, %v", one, err)
+	}
+	if _, err := Select(tenants, []string{"initech"}, false); err == nil || !strings.Contains(err.Error(), "configured: acme, globex") {
+		t.Fatalf("expected unknown tenant error, got %v", err)
+	}
+	if _, err := Select(nil, nil, true); err == nil {
+		t.Fatal("expected an error without configured tenants")
+	}
+}
@@ -0,0 +1,38 @@
+// Package golden compares rendered output with files under testdata/golden
+// in the calling test's package. Running the tests with -update rewrites the
+// files instead:
+//
+//	go test ./internal/report/ -update
+package golden
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Check compares got with testdata/golden/name; go test -update rewrites
+// the file instead.
+func Check(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s differs from the rendered output (run go test -update if the change is intended):\n%s", path, got)
+	}
+}
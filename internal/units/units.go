@@ -0,0 +1,123 @@
+// Package units converts byte counts for costs and for display.
+//
+// AWS prices NAT Gateway data processing, transfer and endpoint data per
+// "GB", and bills a GB as 2^30 bytes. Every cost and every monthly
+// projection priced per GB therefore uses BillingGB, whatever the display
+// system. Sizes shown to users (sample volumes, per-destination traffic) go
+// through Format, which labels them in the configured system: binary
+// (KiB, MiB, GiB) by default, or SI (kB, MB, GB) with --units si. Count
+// does the same for record, flow and request counts.
+package units
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+var printer = message.NewPrinter(language.English)
+
+// Binary and SI unit sizes in bytes.
+const (
+	KiB = 1 << 10
+	MiB = 1 << 20
+	GiB = 1 << 30
+	TiB = 1 << 40
+
+	KB = 1e3
+	MB = 1e6
+	GB = 1e9
+	TB = 1e12
+)
+
+// BillingGB converts bytes to the GB AWS prices data by (2^30 bytes).
+func BillingGB[T int64 | float64](bytes T) float64 {
+	return float64(bytes) / GiB
+}
+
+// System is how sizes are displayed.
+type System int
+
+const (
+	Binary System = iota // powers of 1024, labelled KiB, MiB, GiB, TiB
+	SI                   // powers of 1000, labelled kB, MB, GB, TB
+)
+
+var display = Binary
+
+// SetSystem sets how Format displays sizes.
+func SetSystem(s System) {
+	display = s
+}
+
+// ParseSystem reads a --units value: "binary" or "si".
+func ParseSystem(name string) (System, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "binary", "iec":
+		return Binary, nil
+	case "si", "decimal":
+		return SI, nil
+	}
+	return Binary, fmt.Errorf("unknown units %q (want binary or si)", name)
+}
+
+func (s System) String() string {
+	if s == SI {
+		return "si"
+	}
+	return "binary"
+}
+
+// Format shows bytes in the configured system, in the largest unit that
+// keeps the value at 1 or more, with two decimals: "1.50 GiB", "640 B".
+func Format[T int64 | float64](bytes T) string {
+	return display.Format(float64(bytes))
+}
+
+// Format shows bytes in s; see the package-level Format.
+func (s System) Format(bytes float64) string {
+	base, labels := float64(KiB), []string{"KiB", "MiB", "GiB", "TiB"}
+	if s == SI {
+		base, labels = KB, []string{"kB", "MB", "GB", "TB"}
+	}
+	sign := ""
+	if bytes < 0 {
+		sign, bytes = "-", -bytes
+	}
+	if bytes < base {
+		return printer.Sprintf("%s%.0f B", sign, bytes)
+	}
+	value, unit := bytes/base, labels[0]
+	for _, next := range labels[1:] {
+		if value < base {
+			break
+		}
+		value, unit = value/base, next
+	}
+	return printer.Sprintf("%s%.2f %s", sign, value, unit)
+}
+
+// Number shows a quantity with two decimals and thousands separators, for
+// billing GB and other amounts that keep their own label: "12,345.67".
+func Number(v float64) string {
+	return printer.Sprintf("%.2f", v)
+}
+
+// Count shows a count with thousands separators below a million and in
+// compact notation from there: "987,654", "1.2 M", "3.4 B".
+func Count[T int | int64](n T) string {
+	v := float64(n)
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	switch {
+	case v >= 1e9:
+		return printer.Sprintf("%s%.1f B", sign, v/1e9)
+	case v >= 1e6:
+		return printer.Sprintf("%s%.1f M", sign, v/1e6)
+	}
+	return printer.Sprintf("%d", n)
+}
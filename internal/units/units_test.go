@@ -0,0 +1,76 @@
+package units
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		system System
+		bytes  float64
+		want   string
+	}{
+		{Binary, 0, "0 B"},
+		{Binary, 640, "640 B"},
+		{Binary, 1536, "1.50 KiB"},
+		{Binary, 1.5 * GiB, "1.50 GiB"},
+		// The value that once printed as "12297595.75 MB".
+		{Binary, 12297595.75 * MiB, "11.73 TiB"},
+		{Binary, 3000 * TiB, "3,000.00 TiB"},
+		{SI, 1.5 * GiB, "1.61 GB"},
+		{SI, 999, "999 B"},
+		{SI, 2.5 * MB, "2.50 MB"},
+		{Binary, -2 * MiB, "-2.00 MiB"},
+	}
+	for _, tt := range tests {
+		if got := tt.system.Format(tt.bytes); got != tt.want {
+			t.Errorf("%s.Format(%v) = %q, want %q", tt.system, tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{12345, "12,345"},
+		{999999, "999,999"},
+		{1234567, "1.2 M"},
+		{3400000000, "3.4 B"},
+		{-5000, "-5,000"},
+	}
+	for _, tt := range tests {
+		if got := Count(tt.n); got != tt.want {
+			t.Errorf("Count(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	if got := Number(12297595.75); got != "12,297,595.75" {
+		t.Fatalf("Number = %q", got)
+	}
+}
+
+func TestBillingGBIgnoresDisplaySystem(t *testing.T) {
+	defer SetSystem(Binary)
+	SetSystem(SI)
+	if got := BillingGB(int64(3 * GiB)); got != 3 {
+		t.Fatalf("BillingGB = %v, want 3", got)
+	}
+	if got := Format(int64(GiB)); got != "1.07 GB" {
+		t.Fatalf("Format under SI = %q", got)
+	}
+}
+
+func TestParseSystem(t *testing.T) {
+	for name, want := range map[string]System{"": Binary, "binary": Binary, "SI": SI, "decimal": SI} {
+		if got, err := ParseSystem(name); err != nil || got != want {
+			t.Errorf("ParseSystem(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := ParseSystem("bits"); err == nil {
+		t.Error("expected an error for unknown units")
+	}
+}
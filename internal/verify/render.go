@@ -0,0 +1,127 @@
+package verify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// row is one path of a run, with its verdict in the baseline if any.
+type row struct {
+	Path
+	Before string
+}
+
+func (r *Report) rows(baseline *Report) []row {
+	before := map[string]string{}
+	if baseline != nil {
+		for _, p := range baseline.Paths {
+			before[p.Key()] = p.Verdict
+		}
+	}
+	rows := make([]row, 0, len(r.Paths))
+	for _, p := range r.Paths {
+		b := ""
+		if baseline != nil {
+			if b = before[p.Key()]; b == "" {
+				b = "-"
+			}
+		}
+		rows = append(rows, row{Path: p, Before: b})
+	}
+	return rows
+}
+
+// Outcome summarizes a row's change: "fixed" when the path moved from NAT to
+// a gateway endpoint, "regressed" when it moved back.
+func (rw row) Outcome() string {
+	switch {
+	case rw.Before == VerdictNAT && rw.Verdict == VerdictEndpoint:
+		return "fixed"
+	case rw.Before == VerdictEndpoint && rw.Verdict != VerdictEndpoint:
+		return "regressed"
+	}
+	return ""
+}
+
+func (rw row) detail() string {
+	switch {
+	case rw.Via != "":
+		return "via " + rw.Via
+	case rw.Explanation != "":
+		return rw.Explanation
+	}
+	return ""
+}
+
+// Count returns how many paths have a verdict.
+func (r *Report) Count(verdict string) int {
+	n := 0
+	for _, p := range r.Paths {
+		if p.Verdict == verdict {
+			n++
+		}
+	}
+	return n
+}
+
+// Text renders the verdicts for the terminal, compared to baseline if set.
+func (r *Report) Text(baseline *Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Reachability to S3 and DynamoDB in %s/%s (%s)\n", r.AccountID, r.Region, r.GeneratedAt.Format("2006-01-02 15:04"))
+	if baseline != nil {
+		fmt.Fprintf(&b, "compared to %s\n", baseline.GeneratedAt.Format("2006-01-02 15:04"))
+	}
+	b.WriteString("\n")
+	for _, rw := range r.rows(baseline) {
+		verdict := rw.Verdict
+		if baseline != nil {
+			verdict = rw.Before + " → " + rw.Verdict
+		}
+		fmt.Fprintf(&b, "  %-22s %-24s %-9s %-22s %s", rw.VPCID, rw.RouteTableID, rw.Service, verdict, rw.detail())
+		if o := rw.Outcome(); o != "" {
+			fmt.Fprintf(&b, "  [%s]", strings.ToUpper(o))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "\n%d path(s): %d via endpoint, %d via NAT Gateway, %d other, %d unreachable, %d skipped\n",
+		len(r.Paths), r.Count(VerdictEndpoint), r.Count(VerdictNAT), r.Count(VerdictOther), r.Count(VerdictUnreachable), r.Count(VerdictSkipped))
+	return b.String()
+}
+
+// Markdown renders the verdicts as evidence for a change request, compared
+// to baseline (the run before the change) if set.
+func (r *Report) Markdown(baseline *Report) string {
+	var b strings.Builder
+	b.WriteString("## NAT Gateway remediation: reachability verification\n\n")
+	fmt.Fprintf(&b, "Account `%s`, region `%s`. VPC Reachability Analyzer, TCP %d from a workload network interface in each NAT-routed route table to the service prefix list.\n\n", r.AccountID, r.Region, Port)
+	if baseline != nil {
+		fmt.Fprintf(&b, "| VPC | Route table | Source | Service | Before (%s) | After (%s) | Path |\n", baseline.GeneratedAt.Format(time.DateOnly), r.GeneratedAt.Format(time.DateOnly))
+		b.WriteString("|---|---|---|---|---|---|---|\n")
+	} else {
+		fmt.Fprintf(&b, "| VPC | Route table | Source | Service | Verdict (%s) | Path |\n", r.GeneratedAt.Format(time.DateOnly))
+		b.WriteString("|---|---|---|---|---|---|\n")
+	}
+	for _, rw := range r.rows(baseline) {
+		verdict := rw.Verdict
+		if o := rw.Outcome(); o != "" {
+			verdict = fmt.Sprintf("**%s** (%s)", rw.Verdict, o)
+		}
+		source := rw.Source
+		if source == "" {
+			source = "-"
+		}
+		fmt.Fprintf(&b, "| `%s` | `%s` | `%s` | %s |", rw.VPCID, rw.RouteTableID, source, rw.Service)
+		if baseline != nil {
+			fmt.Fprintf(&b, " %s |", rw.Before)
+		}
+		fmt.Fprintf(&b, " %s | %s |\n", verdict, rw.detail())
+	}
+	b.WriteString("\n")
+	if n := r.Count(VerdictNAT); n > 0 {
+		fmt.Fprintf(&b, "%d path(s) still reach the service through a NAT Gateway.\n", n)
+	} else if r.Count(VerdictEndpoint) > 0 {
+		b.WriteString("All analyzed paths reach the services through gateway endpoints.\n")
+	}
+	return b.String()
+}
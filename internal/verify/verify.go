@@ -0,0 +1,267 @@
+// Package verify checks with VPC Reachability Analyzer which way workload
+// subnets reach S3 and DynamoDB, so a remediation can be proven before and
+// after the change: through the NAT Gateway before, through the gateway
+// endpoint after.
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// PricePerAnalysis is what AWS bills for one Reachability Analyzer analysis.
+const PricePerAnalysis = 0.10
+
+// Verdicts of a path.
+const (
+	VerdictEndpoint    = "endpoint"    // reaches the service through a gateway endpoint
+	VerdictNAT         = "nat"         // reaches the service through a NAT Gateway
+	VerdictOther       = "other"       // reaches the service another way (internet or transit gateway)
+	VerdictUnreachable = "unreachable" // no path
+	VerdictSkipped     = "skipped"     // no workload to start the path from
+)
+
+// Services maps the --services names to the prefix list suffix and the label
+// findings use.
+var Services = map[string]string{"s3": "S3", "dynamodb": "DynamoDB"}
+
+// Port is the destination port analyzed: both services are HTTPS APIs.
+const Port = 443
+
+// Scanner is what verification needs from core.Scanner.
+type Scanner interface {
+	DiscoverNATGateways(ctx context.Context) ([]types.NATGateway, error)
+	DiscoverRouteTables(ctx context.Context, vpcID string) ([]types.RouteTable, error)
+	DiscoverSubnets(ctx context.Context, vpcID string) ([]types.Subnet, error)
+	WorkloadENI(ctx context.Context, subnetID string) (string, error)
+	ServicePrefixListAddress(ctx context.Context, service string) (string, string, error)
+	AnalyzeReachability(ctx context.Context, sourceENI, destIP string, port int32, keep bool) (*types.ReachabilityResult, error)
+}
+
+// Path is one workload route table to one service.
+type Path struct {
+	VPCID        string `json:"vpc_id"`
+	RouteTableID string `json:"route_table_id"`
+	SubnetID     string `json:"subnet_id,omitempty"`
+	Source       string `json:"source,omitempty"` // network interface
+	Service      string `json:"service"`
+	PrefixListID string `json:"prefix_list_id,omitempty"`
+	Destination  string `json:"destination,omitempty"`
+	Verdict      string `json:"verdict,omitempty"`
+	Via          string `json:"via,omitempty"`
+	Explanation  string `json:"explanation,omitempty"`
+	// AnalysisID is kept for reference; the analysis itself is only kept
+	// in the account with --keep-analyses.
+	AnalysisID string `json:"analysis_id,omitempty"`
+}
+
+// Key identifies a path across two reports of the same environment.
+func (p Path) Key() string {
+	return p.RouteTableID + "|" + p.Service
+}
+
+// Report is the result of one verification run.
+type Report struct {
+	AccountID   string    `json:"account_id"`
+	Region      string    `json:"region"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Paths       []Path    `json:"paths"`
+}
+
+// Plan lists the paths to analyze: one per service for every route table
+// that sends traffic to a NAT Gateway, in the VPCs given (all VPCs with NAT
+// Gateways when empty). Each path starts from a workload network interface in
+// one of the route table's subnets; route tables without one are skipped.
+func Plan(ctx context.Context, s Scanner, accountID, region string, vpcIDs, services []string) (*Report, error) {
+	nats, err := s.DiscoverNATGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wanted := map[string]bool{}
+	for _, id := range vpcIDs {
+		wanted[id] = true
+	}
+	vpcSet := map[string]bool{}
+	for _, n := range nats {
+		if len(wanted) == 0 || wanted[n.VPCID] {
+			vpcSet[n.VPCID] = true
+		}
+	}
+	if len(vpcSet) == 0 {
+		return nil, fmt.Errorf("no NAT Gateways found in the selected VPCs")
+	}
+	vpcs := make([]string, 0, len(vpcSet))
+	for id := range vpcSet {
+		vpcs = append(vpcs, id)
+	}
+	sort.Strings(vpcs)
+
+	type destination struct{ prefixList, address string }
+	destinations := map[string]destination{}
+	for _, svc := range services {
+		id, addr, err := s.ServicePrefixListAddress(ctx, svc)
+		if err != nil {
+			return nil, err
+		}
+		destinations[svc] = destination{id, addr}
+	}
+
+	r := &Report{AccountID: accountID, Region: region, GeneratedAt: time.Now()}
+	for _, vpcID := range vpcs {
+		routeTables, err := s.DiscoverRouteTables(ctx, vpcID)
+		if err != nil {
+			return nil, err
+		}
+		implicit, err := implicitSubnets(ctx, s, vpcID, routeTables)
+		if err != nil {
+			return nil, err
+		}
+		for _, rt := range routeTables {
+			if !routesToNAT(rt) {
+				continue
+			}
+			subnets := rt.Subnets
+			if rt.Main {
+				subnets = append(append([]string{}, subnets...), implicit...)
+			}
+			subnetID, source := "", ""
+			for _, sn := range subnets {
+				eni, err := s.WorkloadENI(ctx, sn)
+				if err != nil {
+					return nil, err
+				}
+				if eni != "" {
+					subnetID, source = sn, eni
+					break
+				}
+			}
+			for _, svc := range services {
+				p := Path{
+					VPCID:        vpcID,
+					RouteTableID: rt.ID,
+					SubnetID:     subnetID,
+					Source:       source,
+					Service:      Services[svc],
+					PrefixListID: destinations[svc].prefixList,
+					Destination:  destinations[svc].address,
+				}
+				if source == "" {
+					p.Verdict = VerdictSkipped
+					p.Explanation = "no in-use network interface in the route table's subnets"
+				}
+				r.Paths = append(r.Paths, p)
+			}
+		}
+	}
+	return r, nil
+}
+
+// Pending is the number of paths Analyze will run, each billed.
+func (r *Report) Pending() int {
+	n := 0
+	for _, p := range r.Paths {
+		if p.Verdict == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Analyze runs Reachability Analyzer for every planned path and records the
+// verdicts. A failed analysis is recorded in the path and doesn't stop the
+// others. progress, when set, is called after each path.
+func (r *Report) Analyze(ctx context.Context, s Scanner, keep bool, progress func(Path)) {
+	for i := range r.Paths {
+		p := &r.Paths[i]
+		if p.Verdict != "" {
+			continue
+		}
+		result, err := s.AnalyzeReachability(ctx, p.Source, p.Destination, Port, keep)
+		if err != nil {
+			p.Verdict = VerdictUnreachable
+			p.Explanation = "analysis failed: " + err.Error()
+		} else {
+			p.AnalysisID = result.AnalysisID
+			p.Via = result.Via
+			p.Explanation = result.Explanation
+			p.Verdict = Classify(result.PathFound, result.Via)
+		}
+		if progress != nil {
+			progress(*p)
+		}
+	}
+}
+
+// Classify turns an analysis outcome into a verdict.
+func Classify(pathFound bool, via string) string {
+	switch {
+	case !pathFound:
+		return VerdictUnreachable
+	case strings.HasPrefix(via, "vpce-"):
+		return VerdictEndpoint
+	case strings.HasPrefix(via, "nat-"):
+		return VerdictNAT
+	default:
+		return VerdictOther
+	}
+}
+
+func routesToNAT(rt types.RouteTable) bool {
+	for _, route := range rt.Routes {
+		if route.TargetType == "nat-gateway" {
+			return true
+		}
+	}
+	return false
+}
+
+// implicitSubnets are the subnets of a VPC without an explicit route table
+// association, which use the main route table.
+func implicitSubnets(ctx context.Context, s Scanner, vpcID string, routeTables []types.RouteTable) ([]string, error) {
+	associated := map[string]bool{}
+	for _, rt := range routeTables {
+		for _, sn := range rt.Subnets {
+			associated[sn] = true
+		}
+	}
+	subnets, err := s.DiscoverSubnets(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+	var implicit []string
+	for _, sn := range subnets {
+		if !associated[sn.ID] {
+			implicit = append(implicit, sn.ID)
+		}
+	}
+	return implicit, nil
+}
+
+// SaveJSON writes the report for use as a later run's baseline.
+func (r *Report) SaveJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Load reads a report saved with SaveJSON.
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s is not a termiNATor verification report: %w", path, err)
+	}
+	return &r, nil
+}
@@ -0,0 +1,171 @@
+package verify
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+type fakeScanner struct {
+	routeTables []types.RouteTable
+	subnets     []types.Subnet
+	enis        map[string]string // subnet -> ENI
+	via         map[string]string // destination -> first hop
+	analyzed    []string
+}
+
+func (f *fakeScanner) DiscoverNATGateways(ctx context.Context) ([]types.NATGateway, error) {
+	return []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1"}}, nil
+}
+
+func (f *fakeScanner) DiscoverRouteTables(ctx context.Context, vpcID string) ([]types.RouteTable, error) {
+	return f.routeTables, nil
+}
+
+func (f *fakeScanner) DiscoverSubnets(ctx context.Context, vpcID string) ([]types.Subnet, error) {
+	return f.subnets, nil
+}
+
+func (f *fakeScanner) WorkloadENI(ctx context.Context, subnetID string) (string, error) {
+	return f.enis[subnetID], nil
+}
+
+func (f *fakeScanner) ServicePrefixListAddress(ctx context.Context, service string) (string, string, error) {
+	return "pl-" + service, service + "-ip", nil
+}
+
+func (f *fakeScanner) AnalyzeReachability(ctx context.Context, sourceENI, destIP string, port int32, keep bool) (*types.ReachabilityResult, error) {
+	f.analyzed = append(f.analyzed, sourceENI+"->"+destIP)
+	via := f.via[destIP]
+	return &types.ReachabilityResult{AnalysisID: "nia-" + destIP, PathFound: via != "", Via: via}, nil
+}
+
+func natRoute() []types.Route {
+	return []types.Route{{DestinationCIDR: "0.0.0.0/0", Target: "nat-1", TargetType: "nat-gateway"}}
+}
+
+func TestPlanAndAnalyze(t *testing.T) {
+	s := &fakeScanner{
+		routeTables: []types.RouteTable{
+			{ID: "rtb-main", Main: true, Routes: natRoute()},
+			{ID: "rtb-private", Subnets: []string{"subnet-empty", "subnet-app"}, Routes: natRoute()},
+			{ID: "rtb-public", Subnets: []string{"subnet-public"}, Routes: []types.Route{{DestinationCIDR: "0.0.0.0/0", Target: "igw-1", TargetType: "internet-gateway"}}},
+		},
+		subnets: []types.Subnet{{ID: "subnet-empty"}, {ID: "subnet-app"}, {ID: "subnet-public"}, {ID: "subnet-implicit"}},
+		enis:    map[string]string{"subnet-app": "eni-app"},
+		via:     map[string]string{"s3-ip": "vpce-s3", "dynamodb-ip": "nat-1"},
+	}
+
+	r, err := Plan(context.Background(), s, "123456789012", "us-east-1", nil, []string{"dynamodb", "s3"})
+	if err != nil {
+		t.Fatalf("Plan returned error: %v", err)
+	}
+	if len(r.Paths) != 4 {
+		t.Fatalf("expected 4 paths (2 NAT route tables x 2 services), got %+v", r.Paths)
+	}
+	if r.Pending() != 2 {
+		t.Fatalf("expected the main route table to be skipped without a workload, got %d pending", r.Pending())
+	}
+	for _, p := range r.Paths {
+		if p.RouteTableID == "rtb-main" && p.Verdict != VerdictSkipped {
+			t.Errorf("main route table path should be skipped: %+v", p)
+		}
+		if p.RouteTableID == "rtb-private" && (p.SubnetID != "subnet-app" || p.Source != "eni-app") {
+			t.Errorf("expected the path to start from the subnet with a workload: %+v", p)
+		}
+	}
+
+	r.Analyze(context.Background(), s, false, nil)
+	if len(s.analyzed) != 2 {
+		t.Fatalf("expected 2 analyses, got %v", s.analyzed)
+	}
+	if r.Count(VerdictEndpoint) != 1 || r.Count(VerdictNAT) != 1 {
+		t.Errorf("unexpected verdicts: %+v", r.Paths)
+	}
+}
+
+func TestPlanUsesImplicitSubnetsForMainRouteTable(t *testing.T) {
+	s := &fakeScanner{
+		routeTables: []types.RouteTable{{ID: "rtb-main", Main: true, Routes: natRoute()}},
+		subnets:     []types.Subnet{{ID: "subnet-implicit"}},
+		enis:        map[string]string{"subnet-implicit": "eni-1"},
+	}
+	r, err := Plan(context.Background(), s, "123456789012", "us-east-1", nil, []string{"s3"})
+	if err != nil {
+		t.Fatalf("Plan returned error: %v", err)
+	}
+	if len(r.Paths) != 1 || r.Paths[0].Source != "eni-1" {
+		t.Fatalf("expected a path from the implicitly associated subnet, got %+v", r.Paths)
+	}
+}
+
+func TestPlanFiltersVPCs(t *testing.T) {
+	if _, err := Plan(context.Background(), &fakeScanner{}, "123456789012", "us-east-1", []string{"vpc-other"}, []string{"s3"}); err == nil {
+		t.Fatal("expected an error for a VPC without NAT Gateways")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		found bool
+		via   string
+		want  string
+	}{
+		{true, "vpce-123", VerdictEndpoint},
+		{true, "nat-123", VerdictNAT},
+		{true, "igw-123", VerdictOther},
+		{false, "nat-123", VerdictUnreachable},
+	}
+	for _, c := range cases {
+		if got := Classify(c.found, c.via); got != c.want {
+			t.Errorf("Classify(%v, %q) = %q, want %q", c.found, c.via, got, c.want)
+		}
+	}
+}
+
+func TestMarkdownComparesToBaseline(t *testing.T) {
+	before := &Report{AccountID: "123456789012", Region: "us-east-1", Paths: []Path{
+		{VPCID: "vpc-1", RouteTableID: "rtb-1", Service: "S3", Verdict: VerdictNAT, Via: "nat-1"},
+		{VPCID: "vpc-1", RouteTableID: "rtb-1", Service: "DynamoDB", Verdict: VerdictEndpoint, Via: "vpce-ddb"},
+	}}
+	after := &Report{AccountID: "123456789012", Region: "us-east-1", Paths: []Path{
+		{VPCID: "vpc-1", RouteTableID: "rtb-1", Source: "eni-1", Service: "S3", Verdict: VerdictEndpoint, Via: "vpce-s3"},
+		{VPCID: "vpc-1", RouteTableID: "rtb-1", Source: "eni-1", Service: "DynamoDB", Verdict: VerdictNAT, Via: "nat-1"},
+		{VPCID: "vpc-1", RouteTableID: "rtb-2", Service: "S3", Verdict: VerdictSkipped},
+	}}
+
+	md := after.Markdown(before)
+	for _, want := range []string{
+		"| `vpc-1` | `rtb-1` | `eni-1` | S3 | nat | **endpoint** (fixed) | via vpce-s3 |",
+		"| `vpc-1` | `rtb-1` | `eni-1` | DynamoDB | endpoint | **nat** (regressed) | via nat-1 |",
+		"| `vpc-1` | `rtb-2` | `-` | S3 | - | skipped |",
+		"1 path(s) still reach the service through a NAT Gateway.",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	text := after.Text(before)
+	if !strings.Contains(text, "nat → endpoint") || !strings.Contains(text, "[REGRESSED]") {
+		t.Errorf("unexpected text output:\n%s", text)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "before.json")
+	r := &Report{AccountID: "123456789012", Region: "us-east-1", Paths: []Path{{RouteTableID: "rtb-1", Service: "S3", Verdict: VerdictNAT}}}
+	if err := r.SaveJSON(path); err != nil {
+		t.Fatalf("SaveJSON returned error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(loaded.Paths) != 1 || loaded.Paths[0].Key() != "rtb-1|S3" {
+		t.Errorf("unexpected round trip: %+v", loaded)
+	}
+}
@@ -0,0 +1,63 @@
+// Package recommend lets a build of terminat add its own recommendations to
+// deep scans, such as "route egress through our shared egress VPC", without
+// forking. Register a generator from a main package that wraps terminat's:
+//
+//	package main
+//
+//	import (
+//		"github.com/doitintl/terminator/cmd"
+//		"github.com/doitintl/terminator/pkg/recommend"
+//	)
+//
+//	func main() {
+//		recommend.Register("shared-egress", func(scan recommend.Context) ([]recommend.Recommendation, error) {
+//			if len(scan.NATs) == 0 {
+//				return nil, nil
+//			}
+//			return []recommend.Recommendation{{
+//				Priority:    "medium",
+//				Title:       "Route egress through the shared egress VPC",
+//				Description: "NAT Gateways outside the network account are not allowed; attach the VPC to the egress Transit Gateway.",
+//				Effort:      recommend.EffortMedium,
+//			}}, nil
+//		})
+//		cmd.Execute()
+//	}
+//
+// Registered generators run once a deep scan has analyzed its traffic. Their
+// recommendations are ranked and reported with the built-in ones.
+package recommend
+
+import "github.com/doitintl/terminator/internal/analysis"
+
+type (
+	// Context is the scan a Func generates recommendations for.
+	Context = analysis.ScanContext
+	// Recommendation is one piece of advice in the report.
+	Recommendation = analysis.Recommendation
+	// Func generates recommendations for a scan. It must not modify it.
+	Func = analysis.Recommender
+
+	// TrafficStats, EndpointAnalysis and CostEstimate are the parts of a
+	// Context the deep scan analyzed.
+	TrafficStats     = analysis.TrafficStats
+	EndpointAnalysis = analysis.EndpointAnalysis
+	CostEstimate     = analysis.CostEstimate
+	// Service is a class of traffic in TrafficStats.
+	Service = analysis.Service
+)
+
+// Effort of a Recommendation, from a configuration change to an
+// architecture change.
+const (
+	EffortLow    = analysis.EffortLow
+	EffortMedium = analysis.EffortMedium
+	EffortHigh   = analysis.EffortHigh
+)
+
+// Register adds a generator every deep scan runs. A Recommendation without
+// a Type gets name. A generator that fails or panics is skipped with a
+// warning.
+func Register(name string, fn Func) {
+	analysis.RegisterRecommender(name, fn)
+}
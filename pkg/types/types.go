@@ -7,11 +7,60 @@ type NATGateway struct {
 	ID                 string
 	VPCID              string
 	SubnetID           string
+	AvailabilityZone   string // Empty for regional NAT
 	State              string
 	ConnectivityType   string
 	AvailabilityMode   string // "zonal" or "regional"
 	NetworkInterfaceID string // For zonal NAT
+	PublicIPs          []string
+	PrivateIPs         []string
 	Tags               map[string]string
+	CreateTime         time.Time // Zero when unknown
+}
+
+// Age is how long before now the NAT Gateway was created, zero when its
+// creation time is unknown.
+func (n NATGateway) Age(now time.Time) time.Duration {
+	if n.CreateTime.IsZero() {
+		return 0
+	}
+	return now.Sub(n.CreateTime)
+}
+
+// Name is the NAT Gateway's Name tag, empty when it has none.
+func (n NATGateway) Name() string {
+	return n.Tags["Name"]
+}
+
+// Label is the NAT Gateway ID followed by its Name tag, e.g.
+// "nat-0abc (egress-a)", or the bare ID without one.
+func (n NATGateway) Label() string {
+	if name := n.Name(); name != "" {
+		return n.ID + " (" + name + ")"
+	}
+	return n.ID
+}
+
+// NATLabel is the Label of the NAT Gateway with id among nats, or id when
+// it is not one of them.
+func NATLabel(nats []NATGateway, id string) string {
+	for _, nat := range nats {
+		if nat.ID == id {
+			return nat.Label()
+		}
+	}
+	return id
+}
+
+// NATNames lists the Name tags of nats, for redaction.
+func NATNames(nats []NATGateway) []string {
+	var names []string
+	for _, nat := range nats {
+		if name := nat.Name(); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 // VPCEndpoint represents a VPC endpoint
@@ -23,8 +72,61 @@ type VPCEndpoint struct {
 	State       string
 	RouteTables []string
 	SubnetIDs   []string // Subnets = AZs for Interface endpoints
-	PrivateDNS  bool
-	Tags        map[string]string
+	// SecurityGroups are the groups of an Interface endpoint.
+	SecurityGroups []string
+	PrivateDNS     bool
+	Tags           map[string]string
+}
+
+// VPCDNS is the DNS configuration that decides where a VPC's instances
+// resolve AWS service hostnames.
+type VPCDNS struct {
+	VPCID        string
+	DNSSupport   bool // enableDnsSupport
+	DNSHostnames bool // enableDnsHostnames
+	// ResolverRules are the Route 53 Resolver rules associated with the VPC.
+	ResolverRules []ResolverRule
+	// PrivateZones are the names of private hosted zones associated with
+	// the VPC, without the trailing dot.
+	PrivateZones []string
+}
+
+// ResolverRule is a Route 53 Resolver rule associated with a VPC
+type ResolverRule struct {
+	ID         string
+	Name       string
+	DomainName string // without the trailing dot; "." matches every name
+	RuleType   string // "FORWARD", "SYSTEM" or "RECURSIVE"
+}
+
+// ReachabilityResult is the outcome of a VPC Reachability Analyzer analysis
+type ReachabilityResult struct {
+	PathID     string
+	AnalysisID string
+	PathFound  bool
+	// Via is the first route target on the path: a NAT Gateway, a gateway
+	// endpoint (vpce-), an internet gateway or a transit gateway.
+	Via string
+	// Explanation lists why no path was found.
+	Explanation string
+}
+
+// SubnetEgress is how a workload subnet reaches the internet: the route
+// table it uses and the NAT Gateway of its default route.
+type SubnetEgress struct {
+	SubnetID     string
+	VPCID        string
+	RouteTableID string
+	NATGatewayID string
+	// ENIID narrows the scope to one network interface in the subnet.
+	ENIID string
+	// EndpointID is set when ENIID belongs to an interface endpoint. Its
+	// traffic never reaches a NAT Gateway, so RouteTableID and NATGatewayID
+	// are empty.
+	EndpointID string
+	// BypassedServices are services ("s3", "dynamodb") the subnet reaches
+	// through gateway endpoints on its route table, not the NAT Gateway.
+	BypassedServices []string
 }
 
 // RouteTable represents a VPC route table
@@ -37,6 +139,35 @@ type RouteTable struct {
 	Tags    map[string]string
 }
 
+// Subnet represents a VPC subnet
+type Subnet struct {
+	ID               string
+	VPCID            string
+	CIDR             string
+	AvailabilityZone string
+	MapPublicIP      bool
+	Tags             map[string]string
+}
+
+// SecurityGroup is a VPC security group and the IPv4 ranges and security
+// groups its inbound rules admit to HTTPS (TCP 443).
+type SecurityGroup struct {
+	ID          string
+	VPCID       string
+	Name        string
+	HTTPSCIDRs  []string
+	HTTPSGroups []string
+}
+
+// VPCInventory is the discovered network configuration of one VPC, kept in
+// JSON reports with --include-inventory.
+type VPCInventory struct {
+	VPCID        string
+	Subnets      []Subnet
+	RouteTables  []RouteTable
+	VPCEndpoints []VPCEndpoint
+}
+
 // Route represents a single route in a route table
 type Route struct {
 	DestinationCIDR string
@@ -54,6 +185,28 @@ type Finding struct {
 	Service     string // "S3", "DynamoDB", etc.
 	Action      string
 	Impact      string
+	// MonthlySavings is the projected saving; only meaningful when SavingsEstimated.
+	MonthlySavings   float64
+	SavingsEstimated bool
+	// Confidence (0-1) and Effort (1 low - 3 high) feed Score, the
+	// prioritization score set by analysis.RankFindings.
+	Confidence float64
+	Effort     int
+	Score      float64
+	// Remediation are the AWS API calls that fix the finding, in order, for
+	// automation to run instead of parsing Action; empty when the fix needs
+	// a judgment call.
+	Remediation []RemediationAction `json:",omitempty"`
+}
+
+// RemediationAction is one AWS API call of a finding's remediation.
+// Parameters are named as in the API; values in angle brackets, such as
+// "<security-group-id>", are placeholders to fill in first.
+type RemediationAction struct {
+	// Service is the API namespace, e.g. "ec2".
+	Service    string         `json:"service"`
+	Action     string         `json:"action"`
+	Parameters map[string]any `json:"parameters"`
 }
 
 // TrafficAnalysis represents analyzed traffic data
@@ -81,4 +234,64 @@ type FlowLog struct {
 	Status       string
 	LogGroupName string
 	CreationTime time.Time
+	Tags         map[string]string
+}
+
+// CostAnomaly is a Cost Anomaly Detection anomaly with NAT Gateway usage
+// among its root causes
+type CostAnomaly struct {
+	ID            string   `json:"id"`
+	StartDate     string   `json:"start_date"`
+	EndDate       string   `json:"end_date,omitempty"` // empty while ongoing
+	TotalImpact   float64  `json:"total_impact"`
+	MaxImpact     float64  `json:"max_impact"`
+	ExpectedSpend float64  `json:"expected_spend,omitempty"`
+	ActualSpend   float64  `json:"actual_spend,omitempty"`
+	UsageTypes    []string `json:"usage_types"`
+	Feedback      string   `json:"feedback,omitempty"`
+}
+
+// During reports whether the anomaly's dates include any day from start to
+// end
+func (a CostAnomaly) During(start, end time.Time) bool {
+	return a.StartDate <= end.UTC().Format(time.DateOnly) && (a.EndDate == "" || a.EndDate >= start.UTC().Format(time.DateOnly))
+}
+
+// FriendlyNames are what reviewers recognize an environment by: the
+// account's IAM alias and the VPCs' Name tags. Reports show them next to
+// the raw IDs.
+type FriendlyNames struct {
+	AccountAlias string            `json:"account_alias,omitempty"`
+	VPCNames     map[string]string `json:"vpc_names,omitempty"`
+}
+
+// Account is the account ID after its alias, e.g. "payments-prod
+// (123456789012)", or the bare ID without one.
+func (n FriendlyNames) Account(id string) string {
+	if n.AccountAlias == "" {
+		return id
+	}
+	return n.AccountAlias + " (" + id + ")"
+}
+
+// VPC is the VPC ID followed by its Name tag, or the bare ID without one.
+func (n FriendlyNames) VPC(id string) string {
+	if name := n.VPCNames[id]; name != "" {
+		return id + " (" + name + ")"
+	}
+	return id
+}
+
+// All lists every name, for redaction.
+func (n FriendlyNames) All() []string {
+	var names []string
+	if n.AccountAlias != "" {
+		names = append(names, n.AccountAlias)
+	}
+	for _, name := range n.VPCNames {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
 }
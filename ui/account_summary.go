@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccountSummary is one row of the per-account table printed after a multi-profile run.
+type AccountSummary struct {
+	Profile        string
+	Tenant         string // multi-tenant runs only
+	AccountID      string
+	Region         string
+	NATGateways    int
+	Findings       int
+	MonthlySavings float64 // deep scans only
+	Err            error
+}
+
+// RenderAccountSummary writes the combined per-account summary table for a multi-profile run.
+func RenderAccountSummary(w io.Writer, rows []AccountSummary, includeSavings bool) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "========== MULTI-ACCOUNT SUMMARY ==========")
+
+	header := fmt.Sprintf("%-20s %-14s %-14s %5s %9s", "PROFILE", "ACCOUNT", "REGION", "NATS", "FINDINGS")
+	if includeSavings {
+		header += fmt.Sprintf(" %14s", "SAVINGS/MO")
+	}
+	fmt.Fprintln(w, header+"  STATUS")
+	fmt.Fprintln(w, strings.Repeat("-", len(header)+8))
+
+	var totalNATs, totalFindings, failed int
+	var totalSavings float64
+	for _, row := range rows {
+		status := "ok"
+		if row.Err != nil {
+			status = fmt.Sprintf("failed: %v", row.Err)
+			failed++
+		}
+		line := fmt.Sprintf("%-20s %-14s %-14s %5d %9d", truncate(row.Profile, 20), orDash(row.AccountID), orDash(row.Region), row.NATGateways, row.Findings)
+		if includeSavings {
+			line += fmt.Sprintf(" %14s", formatCurrency(row.MonthlySavings))
+		}
+		fmt.Fprintln(w, line+"  "+status)
+
+		totalNATs += row.NATGateways
+		totalFindings += row.Findings
+		totalSavings += row.MonthlySavings
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", len(header)+8))
+	total := fmt.Sprintf("%-20s %-14s %-14s %5d %9d", "TOTAL", fmt.Sprintf("%d account(s)", len(rows)-failed), "", totalNATs, totalFindings)
+	if includeSavings {
+		total += fmt.Sprintf(" %14s", formatCurrency(totalSavings))
+	}
+	fmt.Fprintln(w, total)
+}
+
+// RenderTenantSummary writes the roll-up of a multi-tenant run: one line per
+// tenant, adding up its accounts and regions, in the order tenants were scanned.
+func RenderTenantSummary(w io.Writer, rows []AccountSummary, includeSavings bool) {
+	type rollup struct {
+		name                  string
+		scans, failed         int
+		natGateways, findings int
+		monthlySavings        float64
+	}
+	var order []*rollup
+	byTenant := map[string]*rollup{}
+	for _, row := range rows {
+		r := byTenant[row.Tenant]
+		if r == nil {
+			r = &rollup{name: row.Tenant}
+			byTenant[row.Tenant] = r
+			order = append(order, r)
+		}
+		r.scans++
+		if row.Err != nil {
+			r.failed++
+		}
+		r.natGateways += row.NATGateways
+		r.findings += row.Findings
+		r.monthlySavings += row.MonthlySavings
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "========== TENANT ROLL-UP ==========")
+	header := fmt.Sprintf("%-20s %6s %5s %9s", "TENANT", "SCANS", "NATS", "FINDINGS")
+	if includeSavings {
+		header += fmt.Sprintf(" %14s", "SAVINGS/MO")
+	}
+	fmt.Fprintln(w, header+"  STATUS")
+	fmt.Fprintln(w, strings.Repeat("-", len(header)+8))
+	for _, r := range order {
+		status := "ok"
+		if r.failed > 0 {
+			status = fmt.Sprintf("%d of %d failed", r.failed, r.scans)
+		}
+		line := fmt.Sprintf("%-20s %6d %5d %9d", truncate(orDash(r.name), 20), r.scans, r.natGateways, r.findings)
+		if includeSavings {
+			line += fmt.Sprintf(" %14s", formatCurrency(r.monthlySavings))
+		}
+		fmt.Fprintln(w, line+"  "+status)
+	}
+}
+
+// RunSummary is the outcome of one run of a command, across every account
+// it scanned.
+type RunSummary struct {
+	Command  string
+	RunID    string
+	Duration time.Duration
+	Rows     []AccountSummary
+	ExitCode int
+	Err      error
+}
+
+// WriteRunSummary writes s as a single logfmt line starting with
+// "terminat_run", so scheduled runs can be grepped and alerted on in
+// centralized logs:
+//
+//	terminat_run status=ok command="terminat scan deep" run_id=... duration_s=912 accounts=1 failed_accounts=0 nats=2 findings=3 savings_usd_month=412.50 exit_code=0
+func WriteRunSummary(w io.Writer, s RunSummary) {
+	var nats, findings, failed int
+	var savings float64
+	for _, row := range s.Rows {
+		if row.Err != nil {
+			failed++
+		}
+		nats += row.NATGateways
+		findings += row.Findings
+		savings += row.MonthlySavings
+	}
+	status := "ok"
+	if s.ExitCode != 0 {
+		status = "failed"
+	}
+
+	fields := []string{
+		"status=" + status,
+		"command=" + logfmtValue(s.Command),
+	}
+	if s.RunID != "" {
+		fields = append(fields, "run_id="+logfmtValue(s.RunID))
+	}
+	fields = append(fields,
+		fmt.Sprintf("duration_s=%d", int64(s.Duration.Round(time.Second)/time.Second)),
+		fmt.Sprintf("accounts=%d", len(s.Rows)),
+		fmt.Sprintf("failed_accounts=%d", failed),
+		fmt.Sprintf("nats=%d", nats),
+		fmt.Sprintf("findings=%d", findings),
+		fmt.Sprintf("savings_usd_month=%.2f", savings),
+		fmt.Sprintf("exit_code=%d", s.ExitCode),
+	)
+	if s.Err != nil {
+		fields = append(fields, "error="+logfmtValue(s.Err.Error()))
+	}
+	fmt.Fprintln(w, "terminat_run "+strings.Join(fields, " "))
+}
+
+// logfmtValue quotes v when it is empty or has spaces, quotes or an equals
+// sign, and keeps it on one line either way.
+func logfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " \t\n\r\"=") {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderAccountSummary(t *testing.T) {
+	rows := []AccountSummary{
+		{Profile: "prod", AccountID: "111111111111", Region: "us-east-1", NATGateways: 3, Findings: 2, MonthlySavings: 1234.5},
+		{Profile: "staging", AccountID: "222222222222", Region: "eu-west-1", NATGateways: 1, Findings: 0, MonthlySavings: 10},
+		{Profile: "dev", Err: errors.New("authentication failed")},
+	}
+
+	var buf bytes.Buffer
+	RenderAccountSummary(&buf, rows, true)
+	out := buf.String()
+
+	for _, want := range []string{"MULTI-ACCOUNT SUMMARY", "prod", "111111111111", "$1,234.50", "failed: authentication failed", "2 account(s)", "$1,244.50"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderAccountSummaryWithoutSavings(t *testing.T) {
+	var buf bytes.Buffer
+	RenderAccountSummary(&buf, []AccountSummary{{Profile: "prod", AccountID: "111111111111"}}, false)
+	if strings.Contains(buf.String(), "SAVINGS/MO") {
+		t.Error("quick scan summary should not include savings column")
+	}
+}
+
+func TestRenderTenantSummary(t *testing.T) {
+	rows := []AccountSummary{
+		{Tenant: "globex", Region: "us-east-1", NATGateways: 2, Findings: 1, MonthlySavings: 100},
+		{Tenant: "acme", Region: "us-east-1", NATGateways: 1, Findings: 2, MonthlySavings: 50},
+		{Tenant: "acme", Region: "eu-west-1", NATGateways: 3, Findings: 1, MonthlySavings: 25.5},
+		{Tenant: "initech", Err: errors.New("access denied")},
+	}
+
+	var buf bytes.Buffer
+	RenderTenantSummary(&buf, rows, true)
+	out := buf.String()
+
+	for _, want := range []string{"TENANT ROLL-UP", "$75.50", "1 of 1 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("roll-up missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "globex") > strings.Index(out, "acme") {
+		t.Errorf("tenants should stay in scan order:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "acme") && !strings.Contains(line, "     2     4         3") {
+			t.Errorf("acme roll-up = %q", line)
+		}
+	}
+}
+
+func TestWriteRunSummary(t *testing.T) {
+	var buf bytes.Buffer
+	WriteRunSummary(&buf, RunSummary{
+		Command:  "terminat scan deep",
+		RunID:    "terminat-1717243200",
+		Duration: 954*time.Second + 400*time.Millisecond,
+		Rows: []AccountSummary{
+			{NATGateways: 3, Findings: 2, MonthlySavings: 800},
+			{NATGateways: 2, Findings: 2, MonthlySavings: 12.4},
+			{Err: errors.New("authentication failed")},
+		},
+		ExitCode: 1,
+		Err:      errors.New("1 of 3 profile scan(s) failed"),
+	})
+
+	want := `terminat_run status=failed command="terminat scan deep" run_id=terminat-1717243200 duration_s=954 accounts=3 failed_accounts=1 nats=5 findings=4 savings_usd_month=812.40 exit_code=1 error="1 of 3 profile scan(s) failed"` + "\n"
+	if buf.String() != want {
+		t.Errorf("summary line = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteRunSummaryKeepsOneLine(t *testing.T) {
+	var buf bytes.Buffer
+	WriteRunSummary(&buf, RunSummary{Command: "terminat", ExitCode: 1, Err: errors.New("unknown flag\nsee --help")})
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("summary should be a single line: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "status=failed") || strings.Contains(buf.String(), "run_id=") {
+		t.Errorf("summary = %q", buf.String())
+	}
+}
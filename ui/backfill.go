@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/units"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// BackfillOptions selects the existing Flow Logs to analyze and the date range.
+type BackfillOptions struct {
+	Region   string
+	LogGroup string // CloudWatch Logs source
+	S3Bucket string // S3 source
+	S3Prefix string
+	From     time.Time // inclusive, UTC day
+	To       time.Time // exclusive, UTC day
+}
+
+// ParseBackfillSource fills the source fields of opts from a --source value:
+// either s3://bucket/prefix or a CloudWatch Logs log group name.
+func ParseBackfillSource(source string, opts *BackfillOptions) error {
+	if source == "" {
+		return fmt.Errorf("--source is required")
+	}
+	if !strings.HasPrefix(source, "s3://") {
+		opts.LogGroup = source
+		return nil
+	}
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(source, "s3://"), "/")
+	if bucket == "" {
+		return fmt.Errorf("invalid S3 source %q: expected s3://bucket/prefix", source)
+	}
+	opts.S3Bucket = bucket
+	opts.S3Prefix = prefix
+	return nil
+}
+
+// RunBackfill analyzes existing Flow Logs one UTC day at a time and prints the
+// month-by-month traffic and cost trend.
+func RunBackfill(ctx context.Context, scanner Scanner, opts BackfillOptions) error {
+	if !opts.To.After(opts.From) {
+		return fmt.Errorf("--to (%s) must be after --from (%s)", opts.To.Format("2006-01-02"), opts.From.Format("2006-01-02"))
+	}
+
+	source := opts.LogGroup
+	if opts.S3Bucket != "" {
+		source = fmt.Sprintf("s3://%s/%s", opts.S3Bucket, opts.S3Prefix)
+	}
+	fmt.Printf("📚 Backfilling %s from %s to %s\n\n", source, opts.From.Format("2006-01-02"), opts.To.Format("2006-01-02"))
+
+	// A log group may hold the Flow Logs of every interface of the VPC; only
+	// the NAT Gateways' count (AnalyzeS3FlowLogsDay does the same per day)
+	var nats []types.NATGateway
+	if opts.S3Bucket == "" {
+		var err error
+		if nats, err = scanner.DiscoverNATGateways(ctx); err != nil {
+			return fmt.Errorf("failed to discover NAT Gateways: %w", err)
+		}
+	}
+
+	var days []analysis.DailyTraffic
+	var samples []analysis.TrafficSample
+	for day := opts.From; day.Before(opts.To); day = day.AddDate(0, 0, 1) {
+		var stats *analysis.TrafficStats
+		var err error
+		if opts.S3Bucket != "" {
+			stats, err = scanner.AnalyzeS3FlowLogsDay(ctx, opts.S3Bucket, opts.S3Prefix, day)
+		} else {
+			stats, err = scanner.AnalyzeLogGroupWindow(ctx, opts.LogGroup, day.Unix(), day.AddDate(0, 0, 1).Unix(), nats)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to analyze %s: %w", day.Format("2006-01-02"), err)
+		}
+		fmt.Printf("  %s  %10s  %s records\n", day.Format("2006-01-02"), formatGB(stats.TotalBytes), units.Count(stats.TotalRecords))
+		days = append(days, analysis.DailyTraffic{Day: day, Stats: stats})
+		samples = append(samples, analysis.TrafficSample{Start: day, Duration: 24 * time.Hour, Stats: stats})
+	}
+
+	RenderBackfillTrend(os.Stdout, analysis.GroupByMonth(days), opts.Region)
+	RenderProjection(os.Stdout, analysis.CalculateSeasonalCosts(opts.Region, samples))
+	return nil
+}
+
+// RenderProjection writes the projected monthly cost and the method used to get it.
+func RenderProjection(w io.Writer, est *analysis.CostEstimate) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Projected monthly NAT traffic: %s GB (~%s/month, %s/month avoidable with gateway endpoints)\n",
+		units.Number(est.TotalDataGB), formatCurrency(est.CurrentMonthlyCost), formatCurrency(est.TotalSavingsMonthly))
+	fmt.Fprintln(w, tipStyle.Render(est.ProjectionMethod))
+}
+
+// RenderBackfillTrend writes the month-by-month traffic and NAT cost table.
+func RenderBackfillTrend(w io.Writer, months []analysis.MonthlyTraffic, region string) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "========== MONTHLY NAT TRAFFIC TREND ==========")
+
+	var all analysis.TrafficStats
+	for _, m := range months {
+		all.Merge(m.Stats)
+	}
+	services := all.ServiceOrder()
+
+	header := fmt.Sprintf("%-8s %4s %10s", "MONTH", "DAYS", "TOTAL")
+	for _, service := range services {
+		header += fmt.Sprintf(" %10s", strings.ToUpper(service.Name()))
+	}
+	header += fmt.Sprintf(" %12s %12s", "NAT COST", "GW SAVINGS")
+	fmt.Fprintln(w, header)
+	fmt.Fprintln(w, strings.Repeat("-", len(header)))
+
+	var totalCost, totalSavings float64
+	for _, m := range months {
+		s := m.Stats
+		cost := m.NATCost(region)
+		savings := m.GatewaySavings(region)
+		fmt.Fprintf(w, "%-8s %4d %10s", m.Month, m.Days, formatGB(s.TotalBytes))
+		for _, service := range services {
+			fmt.Fprintf(w, " %10s", formatGB(s.Bytes(service)))
+		}
+		fmt.Fprintf(w, " %12s %12s\n", formatCurrency(cost), formatCurrency(savings))
+		totalCost += cost
+		totalSavings += savings
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", len(header)))
+	fmt.Fprintf(w, "%-8s %4s %10s %10s %10s %10s %10s %12s %12s\n", "TOTAL", "", "", "", "", "", "", formatCurrency(totalCost), formatCurrency(totalSavings))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, tipStyle.Render("Months only include days with Flow Logs data; compare DAYS before reading the trend."))
+}
+
+func formatGB(bytes int64) string {
+	return units.Format(bytes)
+}
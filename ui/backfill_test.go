@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+)
+
+func TestParseBackfillSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		logGroup string
+		bucket   string
+		prefix   string
+		wantErr  bool
+	}{
+		{source: "/aws/vpc/flowlogs/terminat-123", logGroup: "/aws/vpc/flowlogs/terminat-123"},
+		{source: "s3://logs-bucket/AWSLogs/123456789012/vpcflowlogs/us-east-1/", bucket: "logs-bucket", prefix: "AWSLogs/123456789012/vpcflowlogs/us-east-1/"},
+		{source: "s3://logs-bucket", bucket: "logs-bucket"},
+		{source: "s3:///prefix", wantErr: true},
+		{source: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var opts BackfillOptions
+		err := ParseBackfillSource(tt.source, &opts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseBackfillSource(%q) expected error", tt.source)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseBackfillSource(%q) returned error: %v", tt.source, err)
+		}
+		if opts.LogGroup != tt.logGroup || opts.S3Bucket != tt.bucket || opts.S3Prefix != tt.prefix {
+			t.Errorf("ParseBackfillSource(%q) = %+v", tt.source, opts)
+		}
+	}
+}
+
+func TestRenderBackfillTrend(t *testing.T) {
+	gb := int64(1024 * 1024 * 1024)
+	months := analysis.GroupByMonth([]analysis.DailyTraffic{
+		{Day: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), Stats: &analysis.TrafficStats{TotalRecords: 1, TotalBytes: 10 * gb, Services: map[analysis.Service]analysis.ServiceStats{analysis.ServiceS3: {Bytes: 10 * gb}}}},
+		{Day: time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC), Stats: &analysis.TrafficStats{TotalRecords: 1, TotalBytes: 20 * gb, Services: map[analysis.Service]analysis.ServiceStats{analysis.ServiceOther: {Bytes: 20 * gb}}}},
+	})
+
+	var buf bytes.Buffer
+	RenderBackfillTrend(&buf, months, "us-east-1")
+	out := buf.String()
+
+	for _, want := range []string{"2024-01", "2024-02", "10.00 GiB", "20.00 GiB", "$0.45", "$0.90", "$1.35"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trend output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "2024-01") > strings.Index(out, "2024-02") {
+		t.Errorf("months not in chronological order:\n%s", out)
+	}
+}
+
+func TestRenderProjectionShowsMethod(t *testing.T) {
+	var buf bytes.Buffer
+	RenderProjection(&buf, &analysis.CostEstimate{TotalDataGB: 100, CurrentMonthlyCost: 4.5, ProjectionMethod: "projected using 4 samples across 2 weekdays + 1 weekend day"})
+	out := buf.String()
+	for _, want := range []string{"100.00 GB", "$4.50", "2 weekdays + 1 weekend day"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("projection output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package ui
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -15,9 +18,16 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/baseline"
 	"github.com/doitintl/terminator/internal/core"
 	"github.com/doitintl/terminator/internal/datahub"
+	"github.com/doitintl/terminator/internal/history"
+	"github.com/doitintl/terminator/internal/jira"
+	"github.com/doitintl/terminator/internal/manifest"
+	"github.com/doitintl/terminator/internal/naming"
+	"github.com/doitintl/terminator/internal/notify"
 	"github.com/doitintl/terminator/internal/report"
+	"github.com/doitintl/terminator/internal/units"
 	"github.com/doitintl/terminator/pkg/types"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
@@ -47,28 +57,40 @@ const (
 )
 
 type deepScanModel struct {
-	scanner              *core.Scanner
+	scanner              Scanner
 	ctx                  context.Context
 	duration             int
+	durationPreset       *analysis.DurationPreset
 	natIDs               []string
 	vpcID                string
+	excludeVPCIDs        []string
+	baseline             *baseline.Baseline
 	autoApprove          bool
 	autoCleanup          bool
 	spinner              spinner.Model
 	phase                phase
 	step                 string
 	nats                 []types.NATGateway
+	skippedNATs          []analysis.SkippedNAT
+	scanCoverage         *analysis.ScanCoverage
 	flowLogIDs           []string
 	logGroupName         string
 	runID                string
 	trafficStats         *analysis.TrafficStats
+	natTraffic           []core.NATTraffic
 	costEstimate         *analysis.CostEstimate
+	scanCost             *analysis.ScanCost
+	manifests            *manifest.Store
 	endpointAnalysis     *analysis.EndpointAnalysis
+	vpcRemediation       []analysis.VPCRemediation
 	allFindings          []types.Finding // Quick scan findings for ALL VPCs
 	deepScannedVPC       string          // VPC that was deep scanned
+	timeZone             *time.Location
+	collectionWindow     *report.CollectionWindow
 	recommendations      []analysis.Recommendation
 	region               string
 	accountID            string
+	names                types.FriendlyNames
 	estimatedScanCostGB  float64
 	estimatedScanCostUSD float64
 	err                  error
@@ -77,11 +99,16 @@ type deepScanModel struct {
 	phaseStartTime       time.Time
 	tipIndex             int
 	flowLogsStopped      bool
+	cleanupMsg           string
 	exportMsg            string
 	exportFormat         string
 	outputFile           string
+	reportLang           string
+	redact               bool
+	maxRows              int
 	datahubAPIKey        string
 	datahubCustomerCtx   string
+	datahubGranularity   datahub.Granularity
 	datahubMsg           string
 	datahubInputBuf      string
 	datahubPhase         int // 0=none, 1=prompting-key, 2=prompting-context, 3=prompting-save, 4=sending
@@ -94,84 +121,263 @@ type deepScanModel struct {
 type tickMsg time.Time
 type deepNatsDiscoveredMsg struct {
 	nats            []types.NATGateway
+	names           types.FriendlyNames
 	recommendations []analysis.Recommendation
 	estGB           float64
 	estCost         float64
+	skipped         []analysis.SkippedNAT
 }
 type flowLogsCreatedMsg struct{ flowLogIDs []string }
 type collectionCompleteMsg struct{}
 type trafficAnalyzedMsg struct {
 	stats            *analysis.TrafficStats
+	natTraffic       []core.NATTraffic
 	cost             *analysis.CostEstimate
+	scanCost         *analysis.ScanCost
 	endpointAnalysis *analysis.EndpointAnalysis
+	vpcRemediation   []analysis.VPCRemediation
 	allFindings      []types.Finding
 	deepScannedVPC   string
+	recommendations  []analysis.Recommendation
+	coverage         *analysis.ScanCoverage
+	window           *report.CollectionWindow
 }
 type flowLogsStoppedMsg struct{}
 type deepScanErrorMsg struct{ err error }
 type deepScanCompleteMsg struct{}
 type datahubResultMsg struct{ err error }
 
-func RunDeepScan(ctx context.Context, scanner *core.Scanner, region string, duration int, natIDs []string, vpcID, uiMode string, autoApprove, autoCleanup bool, exportFormat, outputFile string, datahubAPIKey, datahubCustomerCtx string) error {
-	switch strings.ToLower(strings.TrimSpace(uiMode)) {
+// Values accepted by --provision-via.
+const (
+	ProvisionDirect         = "direct"
+	ProvisionCloudFormation = "cloudformation"
+)
+
+// DeepScanOptions holds the user-facing settings of a deep scan.
+type DeepScanOptions struct {
+	Region   string
+	Duration int
+	// DurationPreset is the --duration preset Duration came from, if any.
+	DurationPreset     *analysis.DurationPreset
+	NATIDs             []string
+	VPCID              string
+	ExcludeVPCIDs      []string
+	UIMode             string
+	AutoApprove        bool
+	AutoCleanup        bool
+	ExportFormat       string
+	OutputFile         string
+	DataHubAPIKey      string
+	DataHubCustomerCtx string
+	// DataHubGranularity is what one set of DataHub events covers; empty
+	// means per NAT Gateway.
+	DataHubGranularity datahub.Granularity
+	// ProvisionVia is "direct" (default) or "cloudformation".
+	ProvisionVia string
+	// ReadOnly analyzes LogGroup (or NAT metrics when empty) instead of creating Flow Logs.
+	ReadOnly bool
+	LogGroup string
+	// RunID names the created resources; empty means naming.DefaultPrefix + unix time.
+	RunID string
+	// ExpireKeptDays schedules deletion of a kept log group after this many days (0 = off).
+	ExpireKeptDays int
+	// CloudTrailLogGroup holds S3 data events used to attribute S3 traffic to buckets (optional).
+	CloudTrailLogGroup string
+	// ResolverLogGroup holds Route 53 Resolver query logs used to name DynamoDB endpoints (optional).
+	ResolverLogGroup string
+	// MinSavings hides recommendations and findings projected to save less per month (0 = show all).
+	MinSavings float64
+	// ReportTxt duplicates the stream report to a .txt file.
+	ReportTxt bool
+	// ReportLang is the language of exported markdown reports (default English).
+	ReportLang string
+	// Redact obfuscates account, resource IDs and IPs in exported reports.
+	Redact bool
+	// MaxRows caps each table of exported markdown reports (0 = all rows).
+	MaxRows int
+	// NotifyTargets receive a summary once the scan completes.
+	NotifyTargets []notify.Target
+	// Jira, when set, files high-severity findings as Jira issues.
+	Jira *jira.Config
+	// IncludeInventory adds the scanned VPCs' configuration to the JSON report.
+	IncludeInventory bool
+	// History, when set, keeps the JSON report of the scan.
+	History history.Store
+	// Baseline, when set, hides the findings the team has accepted.
+	Baseline *baseline.Baseline
+	// SubnetID, when set, puts Flow Logs on this workload subnet instead of
+	// the NAT Gateway ENIs and reports only what it sends through its NAT
+	// Gateway.
+	SubnetID string
+	// ENIID, when set, does the same for one network interface. For an
+	// interface endpoint's interface it measures the traffic the endpoint
+	// carries.
+	ENIID string
+	// FirehoseStream, when set, delivers the Flow Logs to this Kinesis Data
+	// Firehose stream ARN instead of a new log group; they are read back
+	// from FirehoseS3 (s3://bucket/prefix), where the stream writes them.
+	FirehoseStream string
+	FirehoseS3     string
+	// CostAnomalyDays, when set, looks up the NAT Gateway cost anomalies of
+	// that many days for the report's billing context.
+	CostAnomalyDays int
+	// IncludeRejected also summarizes the flows security groups and network
+	// ACLs rejected during the sample.
+	IncludeRejected bool
+	// TimeZone is the operator's zone the collection window is also shown
+	// in; nil means the system zone.
+	TimeZone *time.Location
+	// HeartbeatFile is where the stream scan keeps its status file; empty
+	// means status.json in the last run's artifact directory.
+	HeartbeatFile string
+}
+
+func (o *DeepScanOptions) runID() string {
+	if o.RunID == "" {
+		o.RunID = naming.Default().RunID(time.Now().Unix())
+	}
+	return o.RunID
+}
+
+func RunDeepScan(ctx context.Context, scanner Scanner, opts DeepScanOptions) error {
+	switch strings.ToLower(strings.TrimSpace(opts.UIMode)) {
 	case "", "stream":
-		return RunDeepScanStream(ctx, scanner, region, duration, natIDs, vpcID, autoApprove, autoCleanup, exportFormat, outputFile, datahubAPIKey, datahubCustomerCtx)
+		return RunDeepScanStream(ctx, scanner, opts)
 	case "tui":
-		return runDeepScanTUI(ctx, scanner, region, duration, natIDs, vpcID, autoApprove, autoCleanup, exportFormat, outputFile, datahubAPIKey, datahubCustomerCtx)
+		if opts.ReadOnly {
+			return fmt.Errorf("--read-only deep scans require --ui stream")
+		}
+		if opts.ProvisionVia == ProvisionCloudFormation {
+			return fmt.Errorf("--provision-via cloudformation requires --ui stream")
+		}
+		if opts.ExpireKeptDays > 0 {
+			return fmt.Errorf("--expire-kept-log-group requires --ui stream")
+		}
+		if opts.CloudTrailLogGroup != "" {
+			return fmt.Errorf("--cloudtrail-log-group requires --ui stream")
+		}
+		if opts.ResolverLogGroup != "" {
+			return fmt.Errorf("--resolver-log-group requires --ui stream")
+		}
+		if opts.MinSavings > 0 {
+			return fmt.Errorf("--min-savings requires --ui stream")
+		}
+		if opts.ReportTxt {
+			return fmt.Errorf("--report-txt requires --ui stream")
+		}
+		if opts.IncludeInventory {
+			return fmt.Errorf("--include-inventory requires --ui stream")
+		}
+		if opts.SubnetID != "" {
+			return fmt.Errorf("--subnet-id requires --ui stream")
+		}
+		if opts.ENIID != "" {
+			return fmt.Errorf("--eni-id requires --ui stream")
+		}
+		if opts.CostAnomalyDays > 0 {
+			return fmt.Errorf("--cost-anomalies requires --ui stream")
+		}
+		if opts.FirehoseStream != "" {
+			return fmt.Errorf("--firehose-stream requires --ui stream")
+		}
+		if opts.IncludeRejected {
+			return fmt.Errorf("--include-rejected requires --ui stream")
+		}
+		if opts.HeartbeatFile != "" {
+			return fmt.Errorf("--heartbeat-file requires --ui stream")
+		}
+		if opts.Jira != nil {
+			return fmt.Errorf("--jira requires --ui stream")
+		}
+		if len(opts.NotifyTargets) > 0 {
+			return fmt.Errorf("--notify and --notify-webhook require --ui stream")
+		}
+		return runDeepScanTUI(ctx, scanner, opts)
 	default:
-		return fmt.Errorf("invalid --ui value %q (valid: stream, tui)", uiMode)
+		return fmt.Errorf("invalid --ui value %q (valid: stream, tui)", opts.UIMode)
 	}
 }
 
-func runDeepScanTUI(ctx context.Context, scanner *core.Scanner, region string, duration int, natIDs []string, vpcID string, autoApprove, autoCleanup bool, exportFormat, outputFile string, datahubAPIKey, datahubCustomerCtx string) error {
+func runDeepScanTUI(ctx context.Context, scanner Scanner, opts DeepScanOptions) error {
+	// SIGINT/SIGTERM cancel the scan context, which stops the program through
+	// bubbletea's own lifecycle so the terminal is restored before cleanup runs.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4"))
 
+	manifests, _ := manifest.DefaultStore()
+	reader := bufio.NewReader(os.Stdin)
+	confirm := func(prompt string, defaultYes bool) (bool, error) { return confirmPrompt(reader, prompt, defaultYes) }
+	logf := func(format string, args ...any) { fmt.Printf(format+"\n", args...) }
+	if err := recoverUncleanRuns(ctx, scanner, manifests, opts.AutoApprove, confirm, logf); err != nil {
+		return err
+	}
+
 	m := &deepScanModel{
+		manifests:          manifests,
 		scanner:            scanner,
 		ctx:                ctx,
-		duration:           duration,
-		natIDs:             natIDs,
-		vpcID:              vpcID,
-		autoApprove:        autoApprove,
-		autoCleanup:        autoCleanup,
+		duration:           opts.Duration,
+		durationPreset:     opts.DurationPreset,
+		natIDs:             opts.NATIDs,
+		vpcID:              opts.VPCID,
+		excludeVPCIDs:      opts.ExcludeVPCIDs,
+		baseline:           opts.Baseline,
+		autoApprove:        opts.AutoApprove,
+		autoCleanup:        opts.AutoCleanup,
 		spinner:            s,
 		phase:              phaseInit,
-		region:             region,
+		region:             opts.Region,
 		accountID:          scanner.GetAccountID(),
-		runID:              fmt.Sprintf("terminat-%d", time.Now().Unix()),
-		logGroupName:       fmt.Sprintf("/aws/vpc/flowlogs/terminat-%d", time.Now().Unix()),
+		runID:              opts.runID(),
+		logGroupName:       naming.LogGroupName(opts.runID()),
 		startTime:          time.Now(),
-		exportFormat:       exportFormat,
-		outputFile:         outputFile,
-		datahubAPIKey:      datahub.ResolveAPIKey(datahubAPIKey),
-		datahubCustomerCtx: datahub.ResolveCustomerContext(datahubCustomerCtx),
-	}
-
-	// Set up signal handler for cleanup on interrupt
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		fmt.Println("\n\n⚠️  Interrupt received - cleaning up Flow Logs...")
-		m.cleanupFlowLogs()
-		os.Exit(1)
-	}()
-	defer signal.Stop(sigChan)
-
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
-	if _, err := p.Run(); err != nil {
-		m.cleanupFlowLogs()
-		return err
+		exportFormat:       opts.ExportFormat,
+		outputFile:         opts.OutputFile,
+		reportLang:         opts.ReportLang,
+		redact:             opts.Redact,
+		maxRows:            opts.MaxRows,
+		datahubAPIKey:      datahub.ResolveAPIKey(opts.DataHubAPIKey),
+		datahubCustomerCtx: datahub.ResolveCustomerContext(opts.DataHubCustomerCtx),
+		datahubGranularity: opts.DataHubGranularity,
+		timeZone:           opts.TimeZone,
 	}
-	return nil
+	if m.timeZone == nil {
+		m.timeZone = time.Local
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithContext(ctx))
+	_, err := p.Run()
+
+	// Flow Logs are only stopped once the program has exited, whatever ended
+	// it (quit key, error, or signal), so output never lands in the alt screen.
+	if ctx.Err() != nil {
+		fmt.Println("\n⚠️  Interrupt received - cleaning up Flow Logs...")
+	}
+	m.cleanupFlowLogs()
+	// Keep the manifest if the process may still have created resources it
+	// never heard back about (interrupted mid-creation) or could not stop them.
+	if m.flowLogsStopped || (len(m.flowLogIDs) == 0 && m.phase != phaseCreatingResources) {
+		_ = m.manifests.Remove(m.runID)
+	}
+
+	if errors.Is(err, tea.ErrProgramKilled) {
+		return fmt.Errorf("scan interrupted")
+	}
+	return err
 }
 
+// cleanupFlowLogs stops any Flow Logs still running. It uses a context detached
+// from the scan's, since that one is already cancelled after an interrupt.
 func (m *deepScanModel) cleanupFlowLogs() {
 	if len(m.flowLogIDs) > 0 && !m.flowLogsStopped {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(m.ctx), 2*time.Minute)
+		defer cancel()
 		fmt.Printf("🧹 Stopping Flow Logs: %v\n", m.flowLogIDs)
-		if err := m.scanner.DeleteFlowLogs(m.ctx, m.flowLogIDs); err != nil {
+		if err := m.scanner.DeleteFlowLogs(ctx, m.flowLogIDs); err != nil {
 			fmt.Printf("⚠️  Warning: Failed to delete Flow Logs: %v\n", err)
 		} else {
 			fmt.Println("✓ Flow Logs stopped successfully")
@@ -182,6 +388,18 @@ func (m *deepScanModel) cleanupFlowLogs() {
 
 func (m *deepScanModel) exportReport(format string) {
 	r := report.New(m.region, m.accountID, m.duration, m.nats, m.trafficStats, m.costEstimate, m.endpointAnalysis)
+	r.FriendlyNames = m.names
+	r.LogGroupName = m.logGroupName
+	r.DurationPreset = m.durationPreset
+	r.CollectionWindow = m.collectionWindow
+	r.ScanCoverage = m.scanCoverage
+	r.ScanCost = m.scanCost
+	r.Findings = rankedFindings(m.allFindings, m.costEstimate, trafficByVPC(m.nats, m.natTraffic, m.trafficStats))
+	r.VPCRemediation = m.vpcRemediation
+	r.Lang = m.reportLang
+	r.Redact = m.redact
+	r.MaxRows = m.maxRows
+	r.Methodology = reportMethodology(m.scanner, m.region, m.duration, m.costEstimate)
 
 	var filename string
 	var err error
@@ -219,16 +437,47 @@ func (m *deepScanModel) exportReport(format string) {
 }
 
 func (m *deepScanModel) sendToDataHub() tea.Msg {
-	events := datahub.BuildEvents(m.accountID, m.region, m.nats, m.trafficStats, m.costEstimate, m.endpointAnalysis)
-	err := datahub.Send(m.datahubAPIKey, m.datahubCustomerCtx, events)
+	usage := dataHubUsage(m.scanner, m.datahubGranularity, m.natTraffic, m.duration)
+	events := datahub.BuildEvents(m.accountID, m.region, m.nats, m.trafficStats, m.costEstimate, m.endpointAnalysis, usage)
+	events = append(events, datahub.BuildScanCostEvents(m.accountID, m.region, m.runID, m.scanCost)...)
+	err := datahub.LoadConfig().Transport.Send(m.datahubAPIKey, m.datahubCustomerCtx, events)
 	return datahubResultMsg{err: err}
 }
 
+// dataHubUsage prices each NAT Gateway's own traffic for NAT-granularity
+// DataHub events. It is nil for account granularity, when the traffic was
+// not split per NAT, and when a NAT's query failed, so its traffic is sent
+// at account level instead of being dropped.
+func dataHubUsage(scanner Scanner, granularity datahub.Granularity, perNAT []core.NATTraffic, minutes int) map[string]datahub.NATUsage {
+	if granularity == datahub.GranularityAccount || len(perNAT) == 0 {
+		return nil
+	}
+	usage := make(map[string]datahub.NATUsage, len(perNAT))
+	for _, t := range perNAT {
+		if t.Err != nil || t.Stats == nil {
+			return nil
+		}
+		usage[t.NATID] = datahub.NATUsage{Stats: t.Stats, Cost: scanner.CalculateCosts(t.Stats, minutes)}
+	}
+	return usage
+}
+
+// enterPhaseAwaitingCleanup shows the final report with the cleanup prompt
+// beneath it, so there is one report whatever the user decides.
+func (m *deepScanModel) enterPhaseAwaitingCleanup() {
+	m.phase = phaseAwaitingCleanup
+	if m.viewportReady {
+		m.viewport.SetContent(m.renderReportBody())
+		m.viewport.GotoTop()
+	}
+}
+
+// enterPhaseDone swaps the cleanup prompt for the export footer, keeping the
+// report and scroll position as they were.
 func (m *deepScanModel) enterPhaseDone() {
 	m.phase = phaseDone
 	if m.viewportReady {
 		m.viewport.SetContent(m.renderReportBody())
-		m.viewport.GotoTop()
 	}
 }
 
@@ -248,7 +497,6 @@ func (m *deepScanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
-			m.cleanupFlowLogs()
 			return m, tea.Quit
 		}
 
@@ -286,6 +534,7 @@ func (m *deepScanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if len(selected) == 0 {
 					return m, nil // don't proceed with nothing selected
 				}
+				m.skippedNATs = append(m.skippedNATs, leftOut(m.nats, selected, notSelected)...)
 				m.nats = selected
 				m.phase = phaseAwaitingApproval
 			}
@@ -298,7 +547,8 @@ func (m *deepScanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.phase = phaseCreatingResources
 				return m, m.createFlowLogs
 			}
-			if m.phase == phaseAwaitingCleanup {
+			if m.phase == phaseAwaitingCleanup && m.cleanupMsg == "" {
+				m.cleanupMsg = fmt.Sprintf("⏳ Deleting log group %s...", m.logGroupName)
 				return m, m.deleteLogGroup
 			}
 		case "n", "N":
@@ -306,11 +556,13 @@ func (m *deepScanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.done = true
 				return m, tea.Quit
 			}
-			if m.phase == phaseAwaitingCleanup {
+			if m.phase == phaseAwaitingCleanup && m.cleanupMsg == "" {
 				// Auto-export if --export flag was provided
 				if m.exportFormat != "" {
 					m.exportReport(m.exportFormat)
 				}
+				m.cleanupMsg = fmt.Sprintf("Log group kept: %s", m.logGroupName)
+				m.keepLogGroup()
 				m.enterPhaseDone()
 				return m, nil
 			}
@@ -380,15 +632,20 @@ func (m *deepScanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 				if msg.String() == "y" || msg.String() == "Y" {
-					_ = datahub.SaveConfig(datahub.Config{APIKey: m.datahubAPIKey, CustomerContext: m.datahubCustomerCtx})
-					m.datahubMsg += "\n  ✓ Saved to ~/.terminat/config.toml"
+					cfg := datahub.LoadConfig()
+					cfg.APIKey, cfg.CustomerContext = m.datahubAPIKey, m.datahubCustomerCtx
+					if err := datahub.SaveConfig(cfg); err != nil {
+						m.datahubMsg += fmt.Sprintf("\n  ✗ Not saved: %v", err)
+					} else {
+						m.datahubMsg += "\n  ✓ Saved for future scans"
+					}
 					m.datahubPhase = 0
 					return m, nil
 				}
 			}
 		}
 		// Forward to viewport for scrolling
-		if m.phase == phaseDone && m.viewportReady && m.datahubPhase == 0 {
+		if (m.phase == phaseDone || m.phase == phaseAwaitingCleanup) && m.viewportReady && m.datahubPhase == 0 {
 			var cmd tea.Cmd
 			m.viewport, cmd = m.viewport.Update(msg)
 			return m, cmd
@@ -398,13 +655,13 @@ func (m *deepScanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		footerHeight := 6
 		m.viewport = viewport.New(msg.Width, msg.Height-footerHeight)
 		m.viewportReady = true
-		if m.phase == phaseDone {
+		if m.phase == phaseDone || m.phase == phaseAwaitingCleanup {
 			m.viewport.SetContent(m.renderReportBody())
 		}
 		return m, nil
 
 	case tea.MouseMsg:
-		if m.phase == phaseDone && m.viewportReady {
+		if (m.phase == phaseDone || m.phase == phaseAwaitingCleanup) && m.viewportReady {
 			var cmd tea.Cmd
 			m.viewport, cmd = m.viewport.Update(msg)
 			return m, cmd
@@ -421,6 +678,8 @@ func (m *deepScanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case deepNatsDiscoveredMsg:
 		m.nats = msg.nats
+		m.skippedNATs = msg.skipped
+		m.names = msg.names
 		m.recommendations = msg.recommendations
 		m.estimatedScanCostGB = msg.estGB
 		m.estimatedScanCostUSD = msg.estCost
@@ -452,10 +711,16 @@ func (m *deepScanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case trafficAnalyzedMsg:
 		m.trafficStats = msg.stats
+		m.natTraffic = msg.natTraffic
 		m.costEstimate = msg.cost
+		m.scanCost = msg.scanCost
 		m.endpointAnalysis = msg.endpointAnalysis
+		m.vpcRemediation = msg.vpcRemediation
 		m.allFindings = msg.allFindings
 		m.deepScannedVPC = msg.deepScannedVPC
+		m.scanCoverage = msg.coverage
+		m.collectionWindow = msg.window
+		m.recommendations = append(m.recommendations, msg.recommendations...)
 		return m, m.stopFlowLogs
 
 	case flowLogsStoppedMsg:
@@ -467,20 +732,21 @@ func (m *deepScanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.autoCleanup {
 				return m, m.deleteLogGroup
 			}
+			m.keepLogGroup()
 			m.done = true
 			m.phase = phaseDone
 			return m, tea.Quit
 		}
-		m.phase = phaseAwaitingCleanup
+		m.enterPhaseAwaitingCleanup()
 		return m, nil
 
 	case deepScanErrorMsg:
 		m.err = msg.err
 		m.done = true
-		m.cleanupFlowLogs()
 		return m, tea.Quit
 
 	case deepScanCompleteMsg:
+		m.cleanupMsg = fmt.Sprintf("✓ Log group deleted: %s", m.logGroupName)
 		if m.exportFormat != "" {
 			m.exportReport(m.exportFormat)
 		}
@@ -514,7 +780,7 @@ func (m *deepScanModel) View() string {
 	b.WriteString(titleStyle.Render("termiNATor - Deep Dive Scan"))
 	b.WriteString("\n\n")
 	b.WriteString(infoStyle.Render(fmt.Sprintf("Region: %s  |  Account: %s  |  Elapsed: %s\n\n",
-		m.region, m.accountID, formatDuration(time.Since(m.startTime)))))
+		m.region, m.names.Account(m.accountID), formatDuration(time.Since(m.startTime)))))
 
 	switch m.phase {
 	case phaseInit, phaseDiscovering:
@@ -530,6 +796,13 @@ func (m *deepScanModel) View() string {
 	case phaseAnalyzing:
 		b.WriteString(fmt.Sprintf("%s Analyzing traffic patterns...\n", m.spinner.View()))
 	case phaseAwaitingCleanup:
+		if m.viewportReady {
+			b.WriteString(m.viewport.View())
+			b.WriteString("\n")
+		} else {
+			b.WriteString(m.renderReportBody())
+			b.WriteString("\n")
+		}
 		b.WriteString(m.renderCleanupPrompt())
 	case phaseDone:
 		if m.viewportReady {
@@ -562,11 +835,7 @@ func (m *deepScanModel) renderNATSelection() string {
 		if mode == "" {
 			mode = "zonal"
 		}
-		name := nat.Tags["Name"]
-		label := fmt.Sprintf("%s (%s, VPC: %s)", nat.ID, mode, nat.VPCID)
-		if name != "" {
-			label = fmt.Sprintf("%s - %s (%s, VPC: %s)", nat.ID, name, mode, nat.VPCID)
-		}
+		label := fmt.Sprintf("%s (%s, VPC: %s%s)", nat.Label(), mode, m.names.VPC(nat.VPCID), createdSuffix(nat))
 		b.WriteString(fmt.Sprintf("%s%s %s\n", cursor, check, label))
 	}
 
@@ -586,7 +855,7 @@ func (m *deepScanModel) renderApprovalPrompt() string {
 		if mode == "" {
 			mode = "zonal"
 		}
-		b.WriteString(fmt.Sprintf("   • NAT Gateway: %s (%s, VPC: %s)\n", nat.ID, mode, nat.VPCID))
+		b.WriteString(fmt.Sprintf("   • NAT Gateway: %s (%s, VPC: %s)\n", nat.Label(), mode, m.names.VPC(nat.VPCID)))
 	}
 	b.WriteString(infoStyle.Render("   → Flow Logs will be AUTOMATICALLY STOPPED after analysis\n"))
 
@@ -596,7 +865,7 @@ func (m *deepScanModel) renderApprovalPrompt() string {
 
 	b.WriteString(stepStyle.Render("\n📊 Estimated Costs:\n"))
 	if m.estimatedScanCostGB > 0 {
-		b.WriteString(fmt.Sprintf("   • Estimated flow log data: ~%.2f GB (based on current NAT throughput)\n", m.estimatedScanCostGB))
+		b.WriteString(fmt.Sprintf("   • Estimated flow log data: ~%s GB (based on current NAT throughput)\n", units.Number(m.estimatedScanCostGB)))
 		b.WriteString(fmt.Sprintf("   • Flow Logs ingestion (~$0.50/GB): ~$%.2f\n", m.estimatedScanCostUSD))
 		b.WriteString(fmt.Sprintf("   • CloudWatch storage (~$0.03/GB/month): ~$%.4f/month\n", m.estimatedScanCostGB*0.03))
 	} else {
@@ -607,7 +876,12 @@ func (m *deepScanModel) renderApprovalPrompt() string {
 
 	b.WriteString(stepStyle.Render(fmt.Sprintf("\n⏱️  Total scan time: %d minutes\n", m.duration+5)))
 	b.WriteString("   • 5 min startup delay (Flow Logs initialization)\n")
-	b.WriteString(fmt.Sprintf("   • %d min traffic collection\n\n", m.duration))
+	b.WriteString(fmt.Sprintf("   • %d min traffic collection\n", m.duration))
+	if p := m.durationPreset; p != nil {
+		b.WriteString(fmt.Sprintf("   • Preset %s: %s\n", p, p.Tradeoff))
+		b.WriteString(fmt.Sprintf("   • Expected accuracy: %s\n", p.Accuracy))
+	}
+	b.WriteString("\n")
 
 	b.WriteString(highlightStyle.Render("Proceed with scan? [Y/n] "))
 	return b.String()
@@ -653,7 +927,7 @@ func (m *deepScanModel) renderProgress() string {
 
 	b.WriteString(infoStyle.Render("Monitoring:\n"))
 	for _, nat := range m.nats {
-		b.WriteString(fmt.Sprintf("  • %s (%s)\n", nat.ID, nat.VPCID))
+		b.WriteString(fmt.Sprintf("  • %s (%s)\n", nat.Label(), m.names.VPC(nat.VPCID)))
 	}
 	b.WriteString("\n")
 	b.WriteString(tipStyle.Render(tips[m.tipIndex]))
@@ -662,17 +936,17 @@ func (m *deepScanModel) renderProgress() string {
 	return b.String()
 }
 
+// renderCleanupPrompt is the footer shown beneath the final report until the
+// log group decision is made. It fits the footer height reserved for the
+// viewport.
 func (m *deepScanModel) renderCleanupPrompt() string {
+	if m.cleanupMsg != "" {
+		return fmt.Sprintf("  %s\n", m.cleanupMsg)
+	}
 	var b strings.Builder
-	b.WriteString(successStyle.Render("✓ Flow Logs STOPPED\n\n"))
-
-	b.WriteString(warningStyle.Render("CloudWatch Log Group Cleanup\n\n"))
-	b.WriteString(fmt.Sprintf("Log Group: %s\n\n", m.logGroupName))
-	b.WriteString("This log group contains the collected traffic data.\n")
-	b.WriteString("• Keep it to analyze traffic patterns in CloudWatch Logs Insights\n")
-	b.WriteString("• Delete it to avoid storage costs (~$0.03/GB/month)\n\n")
-
-	b.WriteString(highlightStyle.Render("Delete CloudWatch Log Group? [Y/n] "))
+	b.WriteString(warningStyle.Render(fmt.Sprintf("  Log group %s holds the collected traffic data.", m.logGroupName)))
+	b.WriteString("\n  Keep it for CloudWatch Logs Insights, or delete it to avoid storage costs (~$0.03/GB/month).  [↑↓] Scroll\n")
+	b.WriteString(highlightStyle.Render("  Delete CloudWatch Log Group? [Y/n] "))
 	return b.String()
 }
 
@@ -681,6 +955,7 @@ func (m *deepScanModel) discoverNATs() tea.Msg {
 	if err != nil {
 		return deepScanErrorMsg{err: err}
 	}
+	discovered := nats
 
 	// Filter by --vpc-id
 	if m.vpcID != "" {
@@ -692,6 +967,7 @@ func (m *deepScanModel) discoverNATs() tea.Msg {
 		}
 		nats = filtered
 	}
+	nats = excludeVPCs(nats, m.excludeVPCIDs)
 
 	// Filter by --nat-gateway-ids
 	if len(m.natIDs) > 0 {
@@ -712,7 +988,7 @@ func (m *deepScanModel) discoverNATs() tea.Msg {
 	}
 
 	// Generate recommendations based on NAT Gateway setup
-	recommendations := analysis.AnalyzeNATGatewaySetup(nats)
+	recommendations := natSetupRecommendations(m.ctx, m.scanner, nats)
 
 	// Estimate scan cost from recent NAT throughput
 	var natIDs []string
@@ -721,7 +997,11 @@ func (m *deepScanModel) discoverNATs() tea.Msg {
 	}
 	estGB, estCost, _ := m.scanner.EstimateFlowLogsCost(m.ctx, natIDs, m.duration)
 
-	return deepNatsDiscoveredMsg{nats: nats, recommendations: recommendations, estGB: estGB, estCost: estCost}
+	names := m.scanner.FriendlyNames(m.ctx, uniqueVPCIDs(nats))
+	skipped := leftOut(discovered, nats, func(nat types.NATGateway) string {
+		return filterReason(nat, m.vpcID, m.excludeVPCIDs)
+	})
+	return deepNatsDiscoveredMsg{nats: nats, names: names, recommendations: recommendations, estGB: estGB, estCost: estCost, skipped: skipped}
 }
 
 func (m *deepScanModel) createFlowLogs() tea.Msg {
@@ -733,7 +1013,12 @@ func (m *deepScanModel) createFlowLogs() tea.Msg {
 		return deepScanErrorMsg{err: err}
 	}
 
+	// Record what is about to be created so a later run can clean up if this process dies
+	record := manifest.New(m.runID, m.accountID, m.region, m.logGroupName, ProvisionDirect)
+	_ = m.manifests.Save(record)
+
 	if err := m.scanner.CreateLogGroup(m.ctx, m.logGroupName); err != nil {
+		_ = m.manifests.Remove(m.runID)
 		return deepScanErrorMsg{err: fmt.Errorf("failed to create log group: %w", err)}
 	}
 
@@ -741,13 +1026,18 @@ func (m *deepScanModel) createFlowLogs() tea.Msg {
 	for _, nat := range m.nats {
 		flowLogID, err := m.scanner.CreateFlowLogs(m.ctx, nat, m.logGroupName, roleARN, m.runID)
 		if err != nil {
-			if len(flowLogIDs) > 0 {
-				m.scanner.DeleteFlowLogs(m.ctx, flowLogIDs)
+			rollbackErr := m.scanner.DeleteFlowLogs(m.ctx, flowLogIDs)
+			if rollbackErr == nil {
+				rollbackErr = m.scanner.DeleteLogGroup(m.ctx, m.logGroupName)
+			}
+			if rollbackErr == nil {
+				_ = m.manifests.Remove(m.runID)
 			}
-			m.scanner.DeleteLogGroup(m.ctx, m.logGroupName)
 			return deepScanErrorMsg{err: fmt.Errorf("failed to create flow logs: %w", err)}
 		}
 		flowLogIDs = append(flowLogIDs, flowLogID)
+		record.FlowLogIDs = flowLogIDs
+		_ = m.manifests.Save(record)
 	}
 	return flowLogsCreatedMsg{flowLogIDs: flowLogIDs}
 }
@@ -764,14 +1054,18 @@ func (m *deepScanModel) waitForStartup() tea.Msg {
 			// Flow Logs are active, proceed to collection
 			m.phase = phaseCollecting
 			m.phaseStartTime = time.Now()
-			time.Sleep(time.Duration(m.duration) * time.Minute)
+			select {
+			case <-m.ctx.Done():
+				return deepScanErrorMsg{err: fmt.Errorf("scan cancelled during traffic collection: %w", context.Cause(m.ctx))}
+			case <-time.After(time.Duration(m.duration) * time.Minute):
+			}
 			return collectionCompleteMsg{}
 		}
 
 		// Check if context was cancelled
 		select {
 		case <-m.ctx.Done():
-			return deepScanErrorMsg{err: fmt.Errorf("scan cancelled during Flow Logs startup")}
+			return deepScanErrorMsg{err: fmt.Errorf("scan cancelled during Flow Logs startup: %w", context.Cause(m.ctx))}
 		case <-time.After(pollInterval):
 			// Continue polling
 		}
@@ -784,7 +1078,7 @@ func (m *deepScanModel) analyzeTraffic() tea.Msg {
 	endTime := time.Now().Unix()
 	startTime := endTime - int64(m.duration*60) - 300
 
-	stats, err := m.scanner.AnalyzeTraffic(m.ctx, m.logGroupName, startTime, endTime)
+	stats, perNAT, err := m.scanner.AnalyzeTrafficPerNAT(m.ctx, m.logGroupName, m.nats, startTime, endTime)
 	if err != nil {
 		return deepScanErrorMsg{err: fmt.Errorf("failed to analyze traffic: %w", err)}
 	}
@@ -797,17 +1091,44 @@ func (m *deepScanModel) analyzeTraffic() tea.Msg {
 	if len(m.nats) > 0 {
 		deepScannedVPC = m.nats[0].VPCID
 		endpointAnalysis, _ = m.scanner.AnalyzeVPCEndpoints(m.ctx, deepScannedVPC)
+		if endpointAnalysis != nil {
+			// Without a placement or quotas, recommended commands keep
+			// placeholders and no quota warnings
+			_ = m.scanner.PlaceEndpoints(m.ctx, endpointAnalysis, nil)
+			_ = m.scanner.CheckEndpointQuotas(m.ctx, endpointAnalysis)
+		}
 	}
 
 	// Run quick scan analysis on ALL VPCs (not just the deep scanned one)
 	allFindings := analysis.AnalyzeAllVPCEndpoints(m.ctx, m.scanner, m.nats)
+	allFindings, _ = m.baseline.Filter(m.accountID, m.region, allFindings)
+
+	// Generators registered with pkg/recommend; the TUI has no log to warn
+	// in, so a failing one only loses its recommendations.
+	recommendations, _ := analysis.RunRecommenders(analysis.ScanContext{
+		Region:    m.region,
+		AccountID: m.accountID,
+		Duration:  m.duration,
+		NATs:      m.nats,
+		VPCID:     deepScannedVPC,
+		Endpoints: endpointAnalysis,
+		Traffic:   stats,
+		Cost:      costEstimate,
+		Findings:  allFindings,
+	})
 
 	return trafficAnalyzedMsg{
 		stats:            stats,
+		natTraffic:       perNAT,
 		cost:             costEstimate,
+		scanCost:         analysis.CalculateScanCost(m.estimatedScanCostGB, m.scanner.QueryBytesScanned()),
 		endpointAnalysis: endpointAnalysis,
+		vpcRemediation:   analysis.RemediateVPCs(m.ctx, m.scanner.AnalyzeVPCEndpoints, allFindings, deepScannedVPC),
 		allFindings:      allFindings,
 		deepScannedVPC:   deepScannedVPC,
+		recommendations:  recommendations,
+		coverage:         analysis.BuildScanCoverage(m.nats, "Flow Logs created for this scan on the NAT Gateways", unmeasuredNATs(perNAT), m.skippedNATs, deepScannedVPC),
+		window:           report.NewCollectionWindow(time.Unix(endTime-int64(m.duration*60), 0), time.Unix(endTime, 0), m.timeZone),
 	}
 }
 
@@ -820,6 +1141,14 @@ func (m *deepScanModel) stopFlowLogs() tea.Msg {
 	return flowLogsStoppedMsg{}
 }
 
+// keepLogGroup tags the kept log group to never expire, so expiry sweeps
+// leave it alone.
+func (m *deepScanModel) keepLogGroup() {
+	if err := m.scanner.SetLogGroupExpiry(m.ctx, m.logGroupName, time.Time{}); err != nil {
+		m.cleanupMsg += fmt.Sprintf(" (could not update its %s tag, so cleanup --expired may delete it: %v)", core.ExpiresAtTag, err)
+	}
+}
+
 func (m *deepScanModel) deleteLogGroup() tea.Msg {
 	if err := m.scanner.DeleteLogGroup(m.ctx, m.logGroupName); err != nil {
 		return deepScanErrorMsg{err: fmt.Errorf("failed to delete log group: %w", err)}
@@ -827,6 +1156,67 @@ func (m *deepScanModel) deleteLogGroup() tea.Msg {
 	return deepScanCompleteMsg{}
 }
 
+// excludeVPCs drops the NAT Gateways in the --exclude-vpc-ids VPCs.
+func excludeVPCs(nats []types.NATGateway, vpcIDs []string) []types.NATGateway {
+	if len(vpcIDs) == 0 {
+		return nats
+	}
+	excluded := make(map[string]bool, len(vpcIDs))
+	for _, id := range vpcIDs {
+		excluded[id] = true
+	}
+	kept := make([]types.NATGateway, 0, len(nats))
+	for _, nat := range nats {
+		if !excluded[nat.VPCID] {
+			kept = append(kept, nat)
+		}
+	}
+	return kept
+}
+
+// leftOut lists the NAT Gateways of all that are not in kept, with the
+// reason each was left out.
+func leftOut(all, kept []types.NATGateway, reason func(types.NATGateway) string) []analysis.SkippedNAT {
+	in := make(map[string]bool, len(kept))
+	for _, nat := range kept {
+		in[nat.ID] = true
+	}
+	var skipped []analysis.SkippedNAT
+	for _, nat := range all {
+		if !in[nat.ID] {
+			skipped = append(skipped, analysis.SkippedNAT{NAT: nat, Reason: reason(nat)})
+		}
+	}
+	return skipped
+}
+
+// filterReason says which deep scan filter left nat out.
+func filterReason(nat types.NATGateway, vpcID string, excludeVPCIDs []string) string {
+	switch {
+	case vpcID != "" && nat.VPCID != vpcID:
+		return "outside --vpc-id " + vpcID
+	case slices.Contains(excludeVPCIDs, nat.VPCID):
+		return "VPC excluded by --exclude-vpc-ids"
+	default:
+		return "not in --nat-gateway-ids"
+	}
+}
+
+// unmeasuredNATs gives the NAT Gateways whose Flow Logs query failed, and
+// so have no traffic in the sample, with the error.
+func unmeasuredNATs(perNAT []core.NATTraffic) map[string]string {
+	unmeasured := map[string]string{}
+	for _, t := range perNAT {
+		if t.Err != nil {
+			unmeasured[t.NATID] = fmt.Sprintf("Flow Logs query failed, traffic excluded: %v", t.Err)
+		}
+	}
+	return unmeasured
+}
+
+// notSelected is the reason of NAT Gateways deselected at the prompt.
+func notSelected(types.NATGateway) string { return "not selected for this scan" }
+
 func formatDuration(d time.Duration) string {
 	d = d.Round(time.Second)
 	m := d / time.Minute
@@ -3,10 +3,12 @@ package ui
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -14,73 +16,242 @@ import (
 
 	"github.com/charmbracelet/x/term"
 	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/baseline"
+	"github.com/doitintl/terminator/internal/bundle"
 	"github.com/doitintl/terminator/internal/core"
 	"github.com/doitintl/terminator/internal/datahub"
+	"github.com/doitintl/terminator/internal/heartbeat"
+	"github.com/doitintl/terminator/internal/history"
+	"github.com/doitintl/terminator/internal/jira"
+	"github.com/doitintl/terminator/internal/manifest"
+	"github.com/doitintl/terminator/internal/naming"
+	"github.com/doitintl/terminator/internal/notify"
+	"github.com/doitintl/terminator/internal/redact"
 	"github.com/doitintl/terminator/internal/report"
+	"github.com/doitintl/terminator/internal/units"
 	"github.com/doitintl/terminator/pkg/types"
 )
 
+// Kinesis Data Firehose buffers up to 15 minutes before writing to S3.
+const (
+	firehoseFlushTimeout = 15 * time.Minute
+	firehosePollInterval = 30 * time.Second
+)
+
 type streamDeepScanRunner struct {
 	ctx                context.Context
-	scanner            *core.Scanner
+	scanner            Scanner
 	region             string
 	duration           int
+	durationPreset     *analysis.DurationPreset
 	natIDs             []string
 	vpcID              string
+	excludeVPCIDs      []string
 	autoApprove        bool
 	autoCleanup        bool
 	exportFormat       string
 	outputFile         string
 	datahubAPIKey      string
 	datahubCustomerCtx string
+	datahubGranularity datahub.Granularity
 	interactive        bool
 	reader             *bufio.Reader
 	startedAt          time.Time
 	runID              string
 	logGroupName       string
 	outputWidth        int
+	provisionVia       string
+	readOnly           bool
+	existingLogGroup   bool
 
 	nats                 []types.NATGateway
+	skippedNATs          []analysis.SkippedNAT
+	scanCoverage         *analysis.ScanCoverage
 	flowLogIDs           []string
 	flowLogsStopped      bool
 	estimatedScanCostGB  float64
 	estimatedScanCostUSD float64
 	recommendations      []analysis.Recommendation
 	trafficStats         *analysis.TrafficStats
+	natTraffic           []core.NATTraffic
+	azTraffic            []analysis.AZTraffic
+	natCosts             []analysis.NATCost
+	cloudTrailLogGroup   string
+	s3Attribution        []analysis.S3Attribution
+	resolverLogGroup     string
+	dynamoEndpoints      []analysis.DynamoDBEndpoint
+	registryPulls        *analysis.RegistryPullEstimate
+	eksBundle            *analysis.EKSBundleEstimate
+	publicIPv4           *analysis.PublicIPv4Estimate
+	endpointCases        []*analysis.InterfaceEndpointCase
+	minSavings           float64
+	hiddenBelowMin       int
+	expireKeptDays       int
+	manifests            *manifest.Store
+	manifest             *manifest.Manifest
+	heartbeat            *heartbeat.Heartbeat
+	timeZone             *time.Location
+	collectionWindow     *report.CollectionWindow
+	scanCost             *analysis.ScanCost
 	costEstimate         *analysis.CostEstimate
 	endpointAnalysis     *analysis.EndpointAnalysis
+	vpcRemediation       []analysis.VPCRemediation
 	allFindings          []types.Finding
+	names                types.FriendlyNames
 	deepScannedVPC       string
+	reportTxt            bool
+	reportLang           string
+	redact               bool
+	maxRows              int
+	notifyTargets        []notify.Target
+	jira                 *jira.Config
+	includeInventory     bool
+	history              history.Store
+	baseline             *baseline.Baseline
+	hiddenByBaseline     int
+	inventory            []types.VPCInventory
+	subnetID             string
+	eniID                string
+	subnetEgress         *types.SubnetEgress
+	endpointENI          *analysis.EndpointENITraffic
+	firehoseStream       string
+	firehoseBucket       string
+	firehosePrefix       string
+	costAnomalyDays      int
+	costAnomalies        []types.CostAnomaly
+	includeRejected      bool
+	rejected             *analysis.RejectedTraffic
+	coverage             *analysis.SampleCoverage
+	classCheck           *analysis.ClassificationCheck
+	// reportBuf captures the final report while it prints, so section offsets
+	// can be listed and the report saved as text.
+	reportBuf      *strings.Builder
+	reportSections []reportSection
+	lastReport     string
+}
+
+// reportSection is a numbered final report section and the byte offset of
+// its marker within the report.
+type reportSection struct {
+	Title  string
+	Offset int
 }
 
-func RunDeepScanStream(ctx context.Context, scanner *core.Scanner, region string, duration int, natIDs []string, vpcID string, autoApprove, autoCleanup bool, exportFormat, outputFile string, datahubAPIKey, datahubCustomerCtx string) error {
+func RunDeepScanStream(ctx context.Context, scanner Scanner, opts DeepScanOptions) error {
+	_, err := RunDeepScanStreamSummary(ctx, scanner, opts)
+	return err
+}
+
+// RunDeepScanStreamSummary runs a stream deep scan and returns its per-account summary row.
+func RunDeepScanStreamSummary(ctx context.Context, scanner Scanner, opts DeepScanOptions) (AccountSummary, error) {
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	r := newStreamDeepScanRunner(ctx, scanner, opts)
+	err := r.run()
+	return r.summary(), err
+}
+
+// RunDeepScanStreamReport runs an unattended stream deep scan, approving
+// the Flow Logs and deleting the log group without asking, and returns its
+// report, for the scheduled deep scans of terminat serve.
+func RunDeepScanStreamReport(ctx context.Context, scanner Scanner, opts DeepScanOptions) (*report.Report, error) {
+	opts.AutoApprove, opts.AutoCleanup = true, true
+	r := newStreamDeepScanRunner(ctx, scanner, opts)
+	if err := r.run(); err != nil {
+		return nil, err
+	}
+	if r.trafficStats == nil {
+		return nil, fmt.Errorf("deep scan of %s finished without traffic to report", r.region)
+	}
+	return r.buildReport(), nil
+}
+
+func newStreamDeepScanRunner(ctx context.Context, scanner Scanner, opts DeepScanOptions) *streamDeepScanRunner {
 	r := &streamDeepScanRunner{
 		ctx:                ctx,
 		scanner:            scanner,
-		region:             region,
-		duration:           duration,
-		natIDs:             natIDs,
-		vpcID:              vpcID,
-		autoApprove:        autoApprove,
-		autoCleanup:        autoCleanup,
-		exportFormat:       strings.ToLower(strings.TrimSpace(exportFormat)),
-		outputFile:         outputFile,
-		datahubAPIKey:      datahub.ResolveAPIKey(datahubAPIKey),
-		datahubCustomerCtx: datahub.ResolveCustomerContext(datahubCustomerCtx),
+		region:             opts.Region,
+		duration:           opts.Duration,
+		durationPreset:     opts.DurationPreset,
+		natIDs:             opts.NATIDs,
+		vpcID:              opts.VPCID,
+		excludeVPCIDs:      opts.ExcludeVPCIDs,
+		autoApprove:        opts.AutoApprove,
+		autoCleanup:        opts.AutoCleanup,
+		exportFormat:       strings.ToLower(strings.TrimSpace(opts.ExportFormat)),
+		outputFile:         opts.OutputFile,
+		datahubAPIKey:      datahub.ResolveAPIKey(opts.DataHubAPIKey),
+		datahubCustomerCtx: datahub.ResolveCustomerContext(opts.DataHubCustomerCtx),
+		datahubGranularity: opts.DataHubGranularity,
+		provisionVia:       opts.ProvisionVia,
+		readOnly:           opts.ReadOnly,
+		expireKeptDays:     opts.ExpireKeptDays,
+		cloudTrailLogGroup: opts.CloudTrailLogGroup,
+		resolverLogGroup:   opts.ResolverLogGroup,
+		minSavings:         opts.MinSavings,
+		reportTxt:          opts.ReportTxt,
+		reportLang:         opts.ReportLang,
+		redact:             opts.Redact,
+		maxRows:            opts.MaxRows,
+		notifyTargets:      opts.NotifyTargets,
+		jira:               opts.Jira,
+		includeInventory:   opts.IncludeInventory,
+		history:            opts.History,
+		baseline:           opts.Baseline,
+		subnetID:           opts.SubnetID,
+		eniID:              opts.ENIID,
+		costAnomalyDays:    opts.CostAnomalyDays,
+		includeRejected:    opts.IncludeRejected,
+		timeZone:           opts.TimeZone,
 		interactive:        isTerminal(os.Stdin),
 		reader:             bufio.NewReader(os.Stdin),
 		startedAt:          time.Now(),
-		runID:              fmt.Sprintf("terminat-%d", time.Now().Unix()),
-		logGroupName:       fmt.Sprintf("/aws/vpc/flowlogs/terminat-%d", time.Now().Unix()),
+		runID:              opts.runID(),
+		logGroupName:       naming.LogGroupName(opts.runID()),
 		outputWidth:        detectOutputWidth(os.Stdout),
 	}
-	return r.run()
+	if r.timeZone == nil {
+		r.timeZone = time.Local
+	}
+	if opts.LogGroup != "" {
+		r.logGroupName = opts.LogGroup
+		r.existingLogGroup = true
+	}
+	if opts.FirehoseStream != "" {
+		r.firehoseStream = opts.FirehoseStream
+		r.firehoseBucket, r.firehosePrefix, _ = strings.Cut(strings.TrimPrefix(opts.FirehoseS3, "s3://"), "/")
+	}
+	// Without a state directory scans still run, just without crash recovery
+	r.manifests, _ = manifest.DefaultStore()
+	heartbeatFile := opts.HeartbeatFile
+	if heartbeatFile == "" {
+		if dir, err := bundle.DefaultDir(); err == nil {
+			heartbeatFile = filepath.Join(dir, heartbeat.File)
+		}
+	}
+	if heartbeatFile != "" {
+		r.heartbeat = heartbeat.New(heartbeatFile, r.runID, r.region, time.Duration(r.duration)*time.Minute)
+	}
+	return r
+}
+
+func (r *streamDeepScanRunner) summary() AccountSummary {
+	s := AccountSummary{
+		AccountID:   r.scanner.GetAccountID(),
+		Region:      r.region,
+		NATGateways: len(r.nats),
+		Findings:    len(r.allFindings),
+	}
+	if r.costEstimate != nil {
+		s.MonthlySavings = r.costEstimate.TotalSavingsMonthly
+	}
+	return s
 }
 
-func (r *streamDeepScanRunner) run() error {
+func (r *streamDeepScanRunner) run() (err error) {
+	r.heartbeat.Start()
+	defer func() { r.heartbeat.Finish(err) }()
 	r.logStage("scan", "Deep scan started (region=%s account=%s duration=%dm ui=stream)", r.region, r.scanner.GetAccountID(), r.duration)
 
 	if !r.autoApprove && !r.interactive {
@@ -91,11 +262,20 @@ func (r *streamDeepScanRunner) run() error {
 		return err
 	}
 
-	if len(r.nats) > 1 && len(r.natIDs) == 0 && !r.autoApprove {
+	if r.readOnly {
+		return r.runReadOnly()
+	}
+
+	if err := recoverUncleanRuns(r.ctx, r.scanner, r.manifests, r.autoApprove, r.confirm, r.logLine); err != nil {
+		return err
+	}
+
+	if len(r.nats) > 1 && len(r.natIDs) == 0 && r.subnetEgress == nil && !r.autoApprove {
 		selected, err := r.promptNATSelection()
 		if err != nil {
 			return err
 		}
+		r.skippedNATs = append(r.skippedNATs, leftOut(r.nats, selected, notSelected)...)
 		r.nats = selected
 	}
 
@@ -120,7 +300,9 @@ func (r *streamDeepScanRunner) run() error {
 		}
 		if err := r.stopFlowLogs(); err != nil {
 			r.logStage("warn", "Failed to stop Flow Logs during deferred cleanup: %v", err)
+			return
 		}
+		r.forgetManifest()
 	}()
 
 	if err := r.waitForFlowLogsStartup(); err != nil {
@@ -142,8 +324,13 @@ func (r *streamDeepScanRunner) run() error {
 	if err := r.handleLogGroupCleanup(); err != nil {
 		return err
 	}
+	r.forgetManifest()
 
 	r.renderFinalSummary()
+	r.saveReportText()
+	r.collectInventory()
+	r.saveLastRun()
+	r.saveHistory()
 
 	if err := r.exportIfRequested(); err != nil {
 		return err
@@ -152,7 +339,76 @@ func (r *streamDeepScanRunner) run() error {
 	if err := r.sendDataHubIfConfigured(); err != nil {
 		return err
 	}
+	r.notifyIfConfigured()
+	r.syncJiraIfConfigured()
+
+	r.logStage("scan", "Completed in %s", formatDuration(time.Since(r.startedAt)))
+	return nil
+}
+
+// runReadOnly finishes the scan without creating or deleting anything. With
+// --log-group it analyzes that existing Flow Logs group; otherwise it falls
+// back to an estimate from NAT Gateway CloudWatch metrics.
+func (r *streamDeepScanRunner) runReadOnly() error {
+	r.logStage("scan", "Read-only mode: no Flow Logs or log groups will be created")
+
+	if !r.existingLogGroup {
+		return r.estimateFromMetrics()
+	}
+
+	r.logStage("analyze", "Using existing log group %s (last %d minute(s))", r.logGroupName, r.duration)
+	if err := r.analyzeTraffic(); err != nil {
+		return err
+	}
+
+	r.renderFinalSummary()
+	r.saveReportText()
+	r.collectInventory()
+	r.saveLastRun()
+	r.saveHistory()
+
+	if err := r.exportIfRequested(); err != nil {
+		return err
+	}
+
+	if err := r.sendDataHubIfConfigured(); err != nil {
+		return err
+	}
+	r.notifyIfConfigured()
+	r.syncJiraIfConfigured()
+
+	r.logStage("scan", "Completed in %s", formatDuration(time.Since(r.startedAt)))
+	return nil
+}
+
+func (r *streamDeepScanRunner) estimateFromMetrics() error {
+	const window = 24 * time.Hour
+	r.logStage("estimate", "Estimating NAT data processing from CloudWatch metrics (last %s)", formatDuration(window))
+
+	natIDs := make([]string, 0, len(r.nats))
+	for _, nat := range r.nats {
+		natIDs = append(natIDs, nat.ID)
+	}
+	processed, err := r.scanner.GetNATProcessedBytes(r.ctx, natIDs, window)
+	if err != nil {
+		return err
+	}
+
+	windowGB := units.BillingGB(processed)
+	monthlyGB := windowGB * (30 * 24 * time.Hour).Hours() / window.Hours()
+	pricePerGB := analysis.NATGatewayPricePerGB(r.region)
+	r.logLine("  processed in window: %s GB", units.Number(windowGB))
+	r.logLine("  projected monthly:   %s GB (~%s/month NAT data processing at $%.3f/GB)", units.Number(monthlyGB), formatCurrency(monthlyGB*pricePerGB), pricePerGB)
 
+	r.allFindings = analysis.AnalyzeAllVPCEndpoints(r.ctx, r.scanner, r.nats)
+	r.logLine("")
+	r.logLine("Configuration findings: %d", len(r.allFindings))
+	for _, f := range r.allFindings {
+		r.logLine("  - [%s] %s", f.Severity, f.Title)
+	}
+
+	r.logLine("")
+	r.logLine("Per-service breakdown needs Flow Logs data; pass --log-group to analyze an existing termiNATor log group.")
 	r.logStage("scan", "Completed in %s", formatDuration(time.Since(r.startedAt)))
 	return nil
 }
@@ -163,6 +419,24 @@ func (r *streamDeepScanRunner) discoverNATs() error {
 	if err != nil {
 		return err
 	}
+	discovered := nats
+
+	switch {
+	case r.subnetID != "":
+		r.subnetEgress, err = r.scanner.SubnetEgress(r.ctx, r.subnetID)
+	case r.eniID != "":
+		r.subnetEgress, err = r.scanner.ENIEgress(r.ctx, r.eniID)
+	}
+	if err != nil {
+		return err
+	}
+	if e := r.subnetEgress; e != nil && e.EndpointID != "" {
+		// No NAT Gateway carries an endpoint's traffic; its VPC's are kept
+		// for the configuration findings.
+		r.vpcID = e.VPCID
+	} else if e != nil {
+		r.natIDs = []string{e.NATGatewayID}
+	}
 
 	if r.vpcID != "" {
 		filtered := make([]types.NATGateway, 0, len(nats))
@@ -173,6 +447,7 @@ func (r *streamDeepScanRunner) discoverNATs() error {
 		}
 		nats = filtered
 	}
+	nats = excludeVPCs(nats, r.excludeVPCIDs)
 
 	if len(r.natIDs) > 0 {
 		byID := make(map[string]types.NATGateway, len(nats))
@@ -193,15 +468,24 @@ func (r *streamDeepScanRunner) discoverNATs() error {
 	}
 
 	r.nats = nats
-	r.recommendations = analysis.AnalyzeNATGatewaySetup(nats)
+	r.skippedNATs = leftOut(discovered, nats, func(nat types.NATGateway) string {
+		if r.subnetEgress != nil {
+			return "outside the --subnet-id/--eni-id scope"
+		}
+		return filterReason(nat, r.vpcID, r.excludeVPCIDs)
+	})
+	r.recommendations = natSetupRecommendations(r.ctx, r.scanner, nats)
 
 	natIDs := make([]string, 0, len(nats))
 	for _, nat := range nats {
 		natIDs = append(natIDs, nat.ID)
 	}
-	estGB, estCost, _ := r.scanner.EstimateFlowLogsCost(r.ctx, natIDs, r.duration)
-	r.estimatedScanCostGB = estGB
-	r.estimatedScanCostUSD = estCost
+	r.names = r.scanner.FriendlyNames(r.ctx, uniqueVPCIDs(nats))
+	if !r.scansEndpoint() {
+		estGB, estCost, _ := r.scanner.EstimateFlowLogsCost(r.ctx, natIDs, r.duration)
+		r.estimatedScanCostGB = estGB
+		r.estimatedScanCostUSD = estCost
+	}
 
 	r.logStage("discover", "Found %d NAT Gateway(s)", len(r.nats))
 	for _, nat := range r.nats {
@@ -209,7 +493,20 @@ func (r *streamDeepScanRunner) discoverNATs() error {
 		if mode == "" {
 			mode = "zonal"
 		}
-		r.logLine("  - %s (%s, vpc=%s)", nat.ID, mode, nat.VPCID)
+		r.logLine("  - %s (%s, vpc=%s%s)", nat.Label(), mode, r.names.VPC(nat.VPCID), createdSuffix(nat))
+	}
+	if e := r.subnetEgress; e != nil {
+		switch {
+		case e.EndpointID != "":
+			r.logStage("discover", "Scoped to network interface %s of endpoint %s (subnet %s)", e.ENIID, e.EndpointID, e.SubnetID)
+		case e.ENIID != "":
+			r.logStage("discover", "Scoped to network interface %s (subnet %s, route table %s)", e.ENIID, e.SubnetID, e.RouteTableID)
+		default:
+			r.logStage("discover", "Scoped to subnet %s (route table %s)", e.SubnetID, e.RouteTableID)
+		}
+		if len(e.BypassedServices) > 0 {
+			r.logLine("  - %s reached through gateway endpoints, excluded", strings.Join(e.BypassedServices, ", "))
+		}
 	}
 	return nil
 }
@@ -222,12 +519,7 @@ func (r *streamDeepScanRunner) promptNATSelection() ([]types.NATGateway, error)
 		if mode == "" {
 			mode = "zonal"
 		}
-		name := nat.Tags["Name"]
-		if name == "" {
-			r.logLine("  %d) %s (%s, vpc=%s)", i+1, nat.ID, mode, nat.VPCID)
-			continue
-		}
-		r.logLine("  %d) %s (%s) (%s, vpc=%s)", i+1, nat.ID, name, mode, nat.VPCID)
+		r.logLine("  %d) %s (%s, vpc=%s)", i+1, nat.Label(), mode, r.names.VPC(nat.VPCID))
 	}
 
 	r.logLine("Enter comma-separated indexes or press Enter for all")
@@ -265,44 +557,161 @@ func (r *streamDeepScanRunner) promptNATSelection() ([]types.NATGateway, error)
 func (r *streamDeepScanRunner) promptFlowLogsApproval() (bool, error) {
 	r.logLine("")
 	r.logLine("Resource creation summary:")
-	r.logLine("  - Temporary VPC Flow Logs on selected NAT Gateways")
-	r.logLine("  - CloudWatch Log Group: %s", r.logGroupName)
-	if r.estimatedScanCostGB > 0 {
+	if e := r.subnetEgress; e != nil && e.ENIID != "" {
+		r.logLine("  - Temporary VPC Flow Logs on network interface %s", e.ENIID)
+	} else if e != nil {
+		r.logLine("  - Temporary VPC Flow Logs on subnet %s", e.SubnetID)
+	} else {
+		r.logLine("  - Temporary VPC Flow Logs on selected NAT Gateways")
+	}
+	if r.provisionVia == ProvisionCloudFormation {
+		r.logLine("  - Provisioned through CloudFormation stack: %s", r.runID)
+	}
+	if r.firehoseStream != "" {
+		r.logLine("  - Delivered to Firehose stream %s, read back from s3://%s/%s", r.firehoseStream, r.firehoseBucket, r.firehosePrefix)
+	} else {
+		r.logLine("  - CloudWatch Log Group: %s", r.logGroupName)
+	}
+	if r.estimatedScanCostGB > 0 && r.subnetEgress != nil {
+		// Estimated from the whole NAT Gateway's traffic; the subnet sends part of it
+		r.logLine("  - Estimated ingestion: at most %.2f GB (~$%.2f)", r.estimatedScanCostGB, r.estimatedScanCostUSD)
+	} else if r.estimatedScanCostGB > 0 {
 		r.logLine("  - Estimated ingestion: %.2f GB (~$%.2f)", r.estimatedScanCostGB, r.estimatedScanCostUSD)
 	} else {
 		r.logLine("  - Estimated ingestion cost: ~$0.50 per GB")
 	}
 	r.logLine("  - Total scan time estimate: %d minutes (%d startup + %d collection)", r.duration+5, 5, r.duration)
+	if p := r.durationPreset; p != nil {
+		r.logLine("  - Duration preset: %s, %s", p, p.Tradeoff)
+		r.logLine("  - Expected accuracy: %s", p.Accuracy)
+	}
 	return r.confirm("Proceed with scan?", true)
 }
 
 func (r *streamDeepScanRunner) createFlowLogs() error {
+	if r.firehoseStream != "" {
+		return r.createFirehoseFlowLogs()
+	}
+
 	r.logStage("setup", "Validating IAM role and creating Flow Logs resources")
 	roleARN := fmt.Sprintf("arn:aws:iam::%s:role/termiNATor-FlowLogsRole", r.scanner.GetAccountID())
 
 	if err := r.scanner.ValidateFlowLogsRole(r.ctx, roleARN); err != nil {
 		return err
 	}
+
+	// Record what is about to be created so a later run can clean up if this process dies
+	r.manifest = manifest.New(r.runID, r.scanner.GetAccountID(), r.region, r.logGroupName, r.provisionVia)
+	r.recordManifest()
+
+	if r.provisionVia == ProvisionCloudFormation {
+		r.logStage("setup", "Creating CloudFormation stack %s (change set)", r.runID)
+		flowLogIDs, err := r.scanner.CreateFlowLogsStack(r.ctx, r.nats, r.logGroupName, roleARN, r.runID)
+		if err != nil {
+			return fmt.Errorf("failed to create flow logs stack: %w", err)
+		}
+		r.flowLogIDs = flowLogIDs
+		r.manifest.FlowLogIDs = flowLogIDs
+		r.recordManifest()
+		r.logStage("setup", "Stack %s created %d Flow Log(s) in %s", r.runID, len(r.flowLogIDs), r.logGroupName)
+		return nil
+	}
+
 	if err := r.scanner.CreateLogGroup(r.ctx, r.logGroupName); err != nil {
+		r.forgetManifest()
 		return fmt.Errorf("failed to create log group: %w", err)
 	}
 
-	for _, nat := range r.nats {
-		flowLogID, err := r.scanner.CreateFlowLogs(r.ctx, nat, r.logGroupName, roleARN, r.runID)
+	nats := r.nats
+	if r.subnetEgress != nil {
+		// One Flow Log on the subnet or interface instead
+		nats = nats[:1]
+	}
+	for _, nat := range nats {
+		flowLogID, err := r.createFlowLog(nat, roleARN)
 		if err != nil {
-			if len(r.flowLogIDs) > 0 {
-				_ = r.scanner.DeleteFlowLogs(r.ctx, r.flowLogIDs)
+			rollbackErr := r.scanner.DeleteFlowLogs(r.ctx, r.flowLogIDs)
+			if rollbackErr == nil {
+				rollbackErr = r.scanner.DeleteLogGroup(r.ctx, r.logGroupName)
+			}
+			if rollbackErr == nil {
+				r.forgetManifest()
 			}
-			_ = r.scanner.DeleteLogGroup(r.ctx, r.logGroupName)
 			return fmt.Errorf("failed to create flow logs: %w", err)
 		}
 		r.flowLogIDs = append(r.flowLogIDs, flowLogID)
+		r.manifest.FlowLogIDs = r.flowLogIDs
+		r.recordManifest()
 	}
 
 	r.logStage("setup", "Created %d Flow Log(s) in %s", len(r.flowLogIDs), r.logGroupName)
 	return nil
 }
 
+// createFirehoseFlowLogs creates the Flow Logs with a Firehose stream as
+// their destination: no log group and no delivery role.
+func (r *streamDeepScanRunner) createFirehoseFlowLogs() error {
+	r.logStage("setup", "Creating Flow Logs delivering to %s", r.firehoseStream)
+	r.manifest = manifest.New(r.runID, r.scanner.GetAccountID(), r.region, "", r.provisionVia)
+	r.manifest.FirehoseStream = r.firehoseStream
+	r.recordManifest()
+
+	nats := r.nats
+	if r.subnetEgress != nil {
+		nats = nats[:1]
+	}
+	for _, nat := range nats {
+		flowLogID, err := r.createFlowLog(nat, "")
+		if err != nil {
+			if rollbackErr := r.scanner.DeleteFlowLogs(r.ctx, r.flowLogIDs); rollbackErr == nil {
+				r.forgetManifest()
+			}
+			return fmt.Errorf("failed to create flow logs: %w", err)
+		}
+		r.flowLogIDs = append(r.flowLogIDs, flowLogID)
+		r.manifest.FlowLogIDs = r.flowLogIDs
+		r.recordManifest()
+	}
+
+	r.logStage("setup", "Created %d Flow Log(s) delivering to Firehose", len(r.flowLogIDs))
+	return nil
+}
+
+// createFlowLog creates the Flow Log of a NAT Gateway, or of the subnet or
+// network interface a scoped scan covers.
+func (r *streamDeepScanRunner) createFlowLog(nat types.NATGateway, roleARN string) (string, error) {
+	destination := r.logGroupName
+	if r.firehoseStream != "" {
+		destination = r.firehoseStream
+	}
+	switch e := r.subnetEgress; {
+	case e == nil:
+		return r.scanner.CreateFlowLogs(r.ctx, nat, destination, roleARN, r.runID)
+	case e.ENIID != "":
+		return r.scanner.CreateENIFlowLogs(r.ctx, e.ENIID, destination, roleARN, r.runID)
+	default:
+		return r.scanner.CreateSubnetFlowLogs(r.ctx, e.SubnetID, destination, roleARN, r.runID)
+	}
+}
+
+// scansEndpoint reports whether the scan covers an interface endpoint's
+// network interface, whose traffic doesn't go through a NAT Gateway.
+func (r *streamDeepScanRunner) scansEndpoint() bool {
+	return r.subnetEgress != nil && r.subnetEgress.EndpointID != ""
+}
+
+func (r *streamDeepScanRunner) recordManifest() {
+	if err := r.manifests.Save(r.manifest); err != nil {
+		r.logLine("  ⚠️  could not write cleanup manifest, a crash would leave resources behind: %v", err)
+	}
+}
+
+func (r *streamDeepScanRunner) forgetManifest() {
+	if err := r.manifests.Remove(r.runID); err != nil {
+		r.logLine("  ⚠️  %v", err)
+	}
+}
+
 func (r *streamDeepScanRunner) waitForFlowLogsStartup() error {
 	r.logStage("startup", "Waiting for Flow Logs to become ACTIVE")
 	timeout := 10 * time.Minute
@@ -311,7 +720,13 @@ func (r *streamDeepScanRunner) waitForFlowLogsStartup() error {
 	started := time.Now()
 
 	for time.Now().Before(deadline) {
-		activeFlowLogs, err := r.scanner.CheckActiveFlowLogs(r.ctx, r.logGroupName)
+		var activeFlowLogs []string
+		var err error
+		if r.firehoseStream != "" {
+			activeFlowLogs, err = r.scanner.ActiveFlowLogs(r.ctx, r.flowLogIDs)
+		} else {
+			activeFlowLogs, err = r.scanner.CheckActiveFlowLogs(r.ctx, r.logGroupName)
+		}
 		if err == nil && len(activeFlowLogs) > 0 {
 			r.logStage("startup", "Flow Logs are ACTIVE after %s", formatDuration(time.Since(started)))
 			return nil
@@ -320,7 +735,7 @@ func (r *streamDeepScanRunner) waitForFlowLogsStartup() error {
 
 		select {
 		case <-r.ctx.Done():
-			return fmt.Errorf("scan cancelled during Flow Logs startup")
+			return fmt.Errorf("scan cancelled during Flow Logs startup: %w", context.Cause(r.ctx))
 		case <-time.After(pollInterval):
 		}
 	}
@@ -331,7 +746,8 @@ func (r *streamDeepScanRunner) collectTraffic() error {
 	r.logStage("collect", "Collecting traffic for %d minute(s)", r.duration)
 	total := time.Duration(r.duration) * time.Minute
 	started := time.Now()
-	ticker := time.NewTicker(30 * time.Second)
+	// Long presets report progress less often than every 30 seconds
+	ticker := time.NewTicker(max(30*time.Second, total/120))
 	defer ticker.Stop()
 	timer := time.NewTimer(total)
 	defer timer.Stop()
@@ -339,7 +755,7 @@ func (r *streamDeepScanRunner) collectTraffic() error {
 	for {
 		select {
 		case <-r.ctx.Done():
-			return fmt.Errorf("scan cancelled during traffic collection")
+			return fmt.Errorf("scan cancelled during traffic collection: %w", context.Cause(r.ctx))
 		case <-ticker.C:
 			elapsed := time.Since(started)
 			if elapsed > total {
@@ -363,29 +779,465 @@ func (r *streamDeepScanRunner) analyzeTraffic() error {
 	endTime := time.Now().Unix()
 	startTime := endTime - int64(r.duration*60) - 300
 
-	stats, err := r.scanner.AnalyzeTraffic(r.ctx, r.logGroupName, startTime, endTime)
+	var (
+		stats  *analysis.TrafficStats
+		perNAT []core.NATTraffic
+		err    error
+	)
+	if r.firehoseStream != "" {
+		stats, err = r.analyzeFirehoseTraffic(startTime, endTime)
+	} else if r.subnetEgress != nil {
+		stats, err = r.scanner.AnalyzeSubnetTraffic(r.ctx, r.logGroupName, r.subnetEgress, startTime, endTime)
+	} else {
+		stats, perNAT, err = r.scanner.AnalyzeTrafficPerNAT(r.ctx, r.logGroupName, r.nats, startTime, endTime)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to analyze traffic: %w", err)
 	}
+	r.collectionWindow = report.NewCollectionWindow(time.Unix(endTime-int64(r.duration*60), 0), time.Unix(endTime, 0), r.timeZone)
+	for _, t := range perNAT {
+		if t.Err != nil {
+			r.logLine("  ⚠️  query for %s failed, its traffic is excluded: %v", types.NATLabel(r.nats, t.NATID), t.Err)
+		}
+	}
 	r.trafficStats = stats
-	r.costEstimate = r.scanner.CalculateCosts(stats, r.duration)
+	r.natTraffic = perNAT
+	r.checkSampleCoverage(endTime, perNAT)
+	r.crossCheckClassification(startTime, endTime)
+	r.scanCost = analysis.CalculateScanCost(r.estimatedScanCostGB, r.scanner.QueryBytesScanned())
+	if r.scansEndpoint() {
+		// Nothing here is NAT cost; project what the endpoint carries instead
+		e := r.subnetEgress
+		r.endpointENI = analysis.ProjectEndpointENI(r.region, e.ENIID, e.EndpointID, stats, r.duration)
+	} else {
+		samples := zoneSamples(r.nats, perNAT, stats)
+		azSamples := samples
+		if bySource := r.sourceZoneSamples(startTime, endTime, stats); bySource != nil {
+			azSamples = bySource
+		}
+		r.azTraffic = analysis.BreakdownByAZ(r.region, azSamples, r.duration)
+		r.natCosts = analysis.BreakdownByNAT(r.region, r.nats, samples, r.duration)
+		r.costEstimate = r.scanner.CalculateCosts(stats, r.duration)
+	}
+	r.attributeS3Traffic(startTime, endTime)
+	r.lookupDynamoDBEndpoints(startTime, endTime)
+	r.lookupCostAnomalies()
+	r.analyzeRejectedTraffic(startTime, endTime)
 
 	if len(r.nats) > 0 {
 		r.deepScannedVPC = r.nats[0].VPCID
 		r.endpointAnalysis, _ = r.scanner.AnalyzeVPCEndpoints(r.ctx, r.deepScannedVPC)
+		r.placeEndpoints()
+		r.checkEndpointQuotas()
 	}
 	r.allFindings = analysis.AnalyzeAllVPCEndpoints(r.ctx, r.scanner, r.nats)
+	r.checkScanCoverage(perNAT)
+	r.registryPulls = analysis.EstimateRegistryPullThrough(r.region, stats, r.duration, r.endpointAnalysis)
+	if r.registryPulls != nil {
+		r.recommendations = append(r.recommendations, r.registryPulls.Recommendation(r.region))
+	}
+	if !r.scansEndpoint() {
+		r.publicIPv4 = analysis.EstimatePublicIPv4(r.region, r.nats, stats, r.duration)
+	}
+	if r.deepScannedVPC != "" {
+		// Subnet tags are a hint only; without them no EKS bundle is suggested
+		clusters, _ := r.scanner.DiscoverEKSClusters(r.ctx, r.deepScannedVPC)
+		r.eksBundle = analysis.EstimateEKSBundle(r.region, clusters, stats, r.duration, r.endpointAnalysis)
+		if r.eksBundle != nil {
+			r.recommendations = append(r.recommendations, r.eksBundle.Recommendation(r.region, r.deepScannedVPC))
+		}
+		managed, _ := r.scanner.CountSSMManagedInstances(r.ctx, r.deepScannedVPC)
+		if ssm := analysis.EvaluateSSMEndpoints(r.region, managed, stats, r.duration, r.endpointAnalysis); ssm != nil {
+			r.endpointCases = append(r.endpointCases, ssm)
+			if len(ssm.MissingEndpoints) > 0 {
+				r.recommendations = append(r.recommendations, ssm.SSMRecommendation(r.region, r.deepScannedVPC, managed))
+			}
+		}
+		if r.publicIPv4 != nil {
+			if rec, ok := r.publicIPv4.Recommendation(r.region, r.deepScannedVPC); ok {
+				r.recommendations = append(r.recommendations, rec)
+			}
+		}
+		for _, c := range analysis.EvaluateTrafficDrivenEndpoints(r.region, stats, r.duration, r.endpointAnalysis) {
+			r.endpointCases = append(r.endpointCases, c)
+			if finding, ok := c.Finding(r.region, r.deepScannedVPC); ok {
+				r.allFindings = append(r.allFindings, finding)
+			}
+		}
+	}
+	r.allFindings = analysis.PrioritizeFindings(r.allFindings, stats)
+	analysis.AttachFindingSavings(r.allFindings, r.costEstimate, trafficByVPC(r.nats, perNAT, stats))
+	r.allFindings = analysis.RankFindings(r.allFindings)
+	r.runRecommenders(stats)
+	r.recommendations = analysis.RankRecommendations(r.recommendations)
+	r.allFindings, r.hiddenByBaseline = r.baseline.Filter(r.scanner.GetAccountID(), r.region, r.allFindings)
+	r.applyMinSavings()
+	r.vpcRemediation = analysis.RemediateVPCs(r.ctx, r.scanner.AnalyzeVPCEndpoints, r.allFindings, r.deepScannedVPC)
 
-	r.logStage("analyze", "Analysis complete: records=%d total=%.2fGB", stats.TotalRecords, float64(stats.TotalBytes)/(1024*1024*1024))
+	r.logStage("analyze", "Analysis complete: records=%s total=%s", units.Count(stats.TotalRecords), units.Format(stats.TotalBytes))
 	return nil
 }
 
+// checkScanCoverage states which NAT Gateways and VPCs the sample measured,
+// which were only config-checked and which were skipped.
+func (r *streamDeepScanRunner) checkScanCoverage(perNAT []core.NATTraffic) {
+	unmeasured := unmeasuredNATs(perNAT)
+	source := "Flow Logs created for this scan on the NAT Gateways"
+	switch e := r.subnetEgress; {
+	case r.existingLogGroup:
+		source = "existing log group " + r.logGroupName
+	case r.firehoseStream != "":
+		source = "Flow Logs created for this scan, delivered through Firehose stream " + r.firehoseStream
+	case e != nil && e.ENIID != "":
+		source = "Flow Logs created for this scan on network interface " + e.ENIID
+		if e.EndpointID != "" {
+			for _, nat := range r.nats {
+				unmeasured[nat.ID] = "the scan measured endpoint " + e.EndpointID + ", which bypasses NAT Gateways"
+			}
+		}
+	case e != nil:
+		source = "Flow Logs created for this scan on subnet " + e.SubnetID
+	}
+	r.scanCoverage = analysis.BuildScanCoverage(r.nats, source, unmeasured, r.skippedNATs, r.deepScannedVPC)
+}
+
+// runRecommenders adds the recommendations of the generators registered
+// with pkg/recommend. A failing generator only costs its recommendations.
+func (r *streamDeepScanRunner) runRecommenders(stats *analysis.TrafficStats) {
+	recs, err := analysis.RunRecommenders(analysis.ScanContext{
+		Region:    r.region,
+		AccountID: r.scanner.GetAccountID(),
+		Duration:  r.duration,
+		NATs:      r.nats,
+		VPCID:     r.deepScannedVPC,
+		Endpoints: r.endpointAnalysis,
+		Traffic:   stats,
+		Cost:      r.costEstimate,
+		Findings:  r.allFindings,
+	})
+	if err != nil {
+		r.logLine("  ⚠️  %v", err)
+	}
+	r.recommendations = append(r.recommendations, recs...)
+}
+
+// analyzeFirehoseTraffic stops the Flow Logs, waits for the Firehose stream
+// to deliver what it buffered, and classifies the records read back from S3.
+func (r *streamDeepScanRunner) analyzeFirehoseTraffic(startTime, endTime int64) (*analysis.TrafficStats, error) {
+	if err := r.stopFlowLogs(); err != nil {
+		return nil, err
+	}
+	keys, err := r.waitForFirehoseDelivery(time.Unix(startTime, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	var interfaces []string
+	switch e := r.subnetEgress; {
+	case e != nil && e.ENIID != "":
+		interfaces = []string{e.ENIID}
+	case e == nil:
+		for _, nat := range r.nats {
+			if nat.NetworkInterfaceID == "" {
+				// A regional NAT Gateway's interfaces aren't known up front
+				interfaces = nil
+				break
+			}
+			interfaces = append(interfaces, nat.NetworkInterfaceID)
+		}
+	}
+	r.logLine("  reading %d object(s) from s3://%s/%s", len(keys), r.firehoseBucket, r.firehosePrefix)
+	return r.scanner.AnalyzeFirehoseFlowLogs(r.ctx, r.firehoseBucket, keys, interfaces, r.subnetEgress, startTime, time.Now().Unix())
+}
+
+// waitForFirehoseDelivery waits until the stream writes an object after the
+// Flow Logs stopped, a sign it flushed what it buffered while they ran, or
+// for the longest Firehose buffering interval. It returns every object the
+// stream wrote since from.
+func (r *streamDeepScanRunner) waitForFirehoseDelivery(from time.Time) ([]string, error) {
+	r.logStage("analyze", "Waiting for Firehose to deliver the buffered records (up to %s)", formatDuration(firehoseFlushTimeout))
+	before, err := r.scanner.FirehoseObjectKeys(r.ctx, r.firehoseBucket, r.firehosePrefix, from, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(before))
+	for _, key := range before {
+		seen[key] = true
+	}
+
+	started := time.Now()
+	for time.Since(started) < firehoseFlushTimeout {
+		select {
+		case <-r.ctx.Done():
+			return nil, fmt.Errorf("scan cancelled while waiting for Firehose delivery: %w", context.Cause(r.ctx))
+		case <-time.After(firehosePollInterval):
+		}
+		keys, err := r.scanner.FirehoseObjectKeys(r.ctx, r.firehoseBucket, r.firehosePrefix, from, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if !seen[key] {
+				r.logStage("analyze", "Firehose delivered after %s", formatDuration(time.Since(started)))
+				return keys, nil
+			}
+		}
+		r.logLine("  delivery progress: elapsed=%s", formatDuration(time.Since(started)))
+	}
+	r.logLine("  ⚠️  nothing delivered since the Flow Logs stopped; records still buffered are missing")
+	return r.scanner.FirehoseObjectKeys(r.ctx, r.firehoseBucket, r.firehosePrefix, from, time.Now())
+}
+
+// applyMinSavings hides findings and recommendations projected to save less
+// than --min-savings per month.
+func (r *streamDeepScanRunner) applyMinSavings() {
+	if r.minSavings <= 0 {
+		return
+	}
+	var droppedFindings, droppedRecs int
+	r.allFindings, droppedFindings = analysis.FilterFindings(r.allFindings, r.minSavings)
+	r.recommendations, droppedRecs = analysis.FilterRecommendations(r.recommendations, r.minSavings)
+	r.hiddenBelowMin = droppedFindings + droppedRecs
+}
+
+// placeEndpoints picks subnets and a security group for new interface
+// endpoints in the zones NAT traffic came from. Without them, recommended
+// commands keep placeholders.
+func (r *streamDeepScanRunner) placeEndpoints() {
+	if r.endpointAnalysis == nil {
+		return
+	}
+	if err := r.scanner.PlaceEndpoints(r.ctx, r.endpointAnalysis, activeZones(r.azTraffic)); err != nil {
+		r.logLine("  ⚠️  interface endpoint placement skipped: %v", err)
+		return
+	}
+	if p := r.endpointAnalysis.Placement; p != nil {
+		group := "a new security group allowing HTTPS from the VPC"
+		if p.SecurityGroupID != "" {
+			group = p.SecurityGroupID + " (" + p.SecurityGroupReason + ")"
+		}
+		r.logLine("  interface endpoints placed in %s (%s), with %s", strings.Join(p.SubnetIDs, ", "), strings.Join(p.AvailabilityZones, ", "), group)
+	}
+}
+
+// lookupCostAnomalies reads the recent NAT Gateway cost anomalies from the
+// account's Cost Anomaly Detection monitor. Without a monitor, or when the
+// lookup fails, the report has no billing context.
+func (r *streamDeepScanRunner) lookupCostAnomalies() {
+	if r.costAnomalyDays <= 0 {
+		return
+	}
+	days := r.costAnomalyDays
+	r.costAnomalyDays = 0
+	monitor, err := r.scanner.CostAnomalyMonitor(r.ctx)
+	if err != nil {
+		r.logLine("  ⚠️  cost anomaly lookup skipped: %v", err)
+		return
+	}
+	if monitor == "" {
+		r.logLine("  ⚠️  cost anomaly lookup skipped: no Cost Anomaly Detection monitor for AWS services (create one with terminat anomalies --create-monitor)")
+		return
+	}
+	anomalies, err := r.scanner.NATCostAnomalies(r.ctx, monitor, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		r.logLine("  ⚠️  cost anomaly lookup skipped: %v", err)
+		return
+	}
+	r.costAnomalyDays, r.costAnomalies = days, anomalies
+}
+
+// checkSampleCoverage records how complete the sample is: the records Flow
+// Logs could not capture, the minutes without records and the NAT Gateways
+// whose query failed. Firehose samples are not covered. Failures only cost
+// the disclosure, not the scan.
+func (r *streamDeepScanRunner) checkSampleCoverage(endTime int64, perNAT []core.NATTraffic) {
+	if r.firehoseStream != "" {
+		return
+	}
+	coverage, err := r.scanner.SampleCoverage(r.ctx, r.logGroupName, endTime-int64(r.duration*60), endTime, !r.existingLogGroup)
+	if err != nil {
+		r.logLine("  ⚠️  sample completeness check skipped: %v", err)
+		return
+	}
+	for _, t := range perNAT {
+		if t.Err != nil {
+			coverage.FailedNATGateways = append(coverage.FailedNATGateways, t.NATID)
+		}
+	}
+	r.coverage = coverage
+}
+
+// crossCheckClassification compares the IP-range classification with the
+// AWS service Flow Logs recorded for each destination. Subnet and interface
+// scans, whose flows run both ways, and Firehose samples are not checked.
+// Failures only cost the cross-check, not the scan.
+func (r *streamDeepScanRunner) crossCheckClassification(startTime, endTime int64) {
+	if r.firehoseStream != "" || r.subnetEgress != nil {
+		return
+	}
+	check, err := r.scanner.CheckClassification(r.ctx, r.logGroupName, startTime, endTime)
+	if err != nil {
+		r.logLine("  ⚠️  classification cross-check skipped: %v", err)
+		return
+	}
+	r.classCheck = check
+}
+
+// analyzeRejectedTraffic summarizes the flows security groups and network
+// ACLs rejected when --include-rejected is set. Failures only cost the
+// summary, not the scan.
+func (r *streamDeepScanRunner) analyzeRejectedTraffic(startTime, endTime int64) {
+	if !r.includeRejected || r.firehoseStream != "" {
+		return
+	}
+	rejected, err := r.scanner.AnalyzeRejectedTraffic(r.ctx, r.logGroupName, startTime, endTime)
+	if err != nil {
+		r.logLine("  ⚠️  rejected traffic summary skipped: %v", err)
+		return
+	}
+	r.rejected = rejected
+}
+
+// checkEndpointQuotas warns before remediation commands run into the VPC
+// endpoint quotas. A failed check leaves the commands unannotated.
+func (r *streamDeepScanRunner) checkEndpointQuotas() {
+	if r.endpointAnalysis == nil {
+		return
+	}
+	if err := r.scanner.CheckEndpointQuotas(r.ctx, r.endpointAnalysis); err != nil {
+		r.logLine("  ⚠️  endpoint quota check skipped: %v", err)
+	}
+}
+
+// activeZones are the availability zones the sample saw NAT traffic in.
+func activeZones(zones []analysis.AZTraffic) []string {
+	var active []string
+	for _, z := range zones {
+		if z.TotalBytes > 0 && z.AvailabilityZone != "regional" {
+			active = append(active, z.AvailabilityZone)
+		}
+	}
+	return active
+}
+
+// attributeS3Traffic correlates S3 traffic with CloudTrail data events when
+// --cloudtrail-log-group is set. Failures only cost the breakdown, not the scan.
+func (r *streamDeepScanRunner) attributeS3Traffic(startTime, endTime int64) {
+	if r.cloudTrailLogGroup == "" || r.trafficStats == nil || r.trafficStats.Bytes(analysis.ServiceS3) == 0 {
+		return
+	}
+	r.logLine("  correlating S3 traffic with CloudTrail data events in %s", r.cloudTrailLogGroup)
+	attributions, err := r.scanner.AttributeS3Traffic(r.ctx, r.cloudTrailLogGroup, r.nats, startTime, endTime)
+	if err != nil {
+		r.logLine("  ⚠️  S3 bucket attribution skipped: %v", err)
+		return
+	}
+	if len(attributions) == 0 {
+		r.logLine("  ⚠️  no S3 data events from the NAT Gateway IPs; are S3 data events enabled on the trail?")
+	}
+	r.s3Attribution = attributions
+}
+
+// lookupDynamoDBEndpoints names the DynamoDB endpoints clients resolved when
+// --resolver-log-group is set. Failures only cost the breakdown, not the scan.
+func (r *streamDeepScanRunner) lookupDynamoDBEndpoints(startTime, endTime int64) {
+	if r.resolverLogGroup == "" || r.trafficStats == nil || r.trafficStats.Bytes(analysis.ServiceDynamoDB) == 0 {
+		return
+	}
+	r.logLine("  looking up DynamoDB endpoints in Resolver query logs %s", r.resolverLogGroup)
+	endpoints, err := r.scanner.LookupDynamoDBEndpoints(r.ctx, r.resolverLogGroup, startTime, endTime)
+	if err != nil {
+		r.logLine("  ⚠️  DynamoDB endpoint lookup skipped: %v", err)
+		return
+	}
+	r.dynamoEndpoints = endpoints
+}
+
+func sortedRegions(m map[string]int64) []string {
+	regions := make([]string, 0, len(m))
+	for r := range m {
+		regions = append(regions, r)
+	}
+	sort.Slice(regions, func(i, j int) bool { return m[regions[i]] > m[regions[j]] })
+	return regions
+}
+
+// sourceZoneSamples attributes the sampled traffic to the zones of the
+// subnets that sent it, which the NAT Gateway zones hide when one NAT
+// Gateway serves several zones. Nil means the caller falls back to NAT
+// Gateway zones; failures only cost the finer breakdown.
+func (r *streamDeepScanRunner) sourceZoneSamples(startTime, endTime int64, total *analysis.TrafficStats) []analysis.ZoneSample {
+	if r.firehoseStream != "" || r.logGroupName == "" || total == nil {
+		return nil
+	}
+	var subnets []types.Subnet
+	for _, vpcID := range uniqueVPCIDs(r.nats) {
+		vpcSubnets, err := r.scanner.DiscoverSubnets(r.ctx, vpcID)
+		if err != nil {
+			r.logLine("  ⚠️  AZ breakdown by source subnet skipped: %v", err)
+			return nil
+		}
+		subnets = append(subnets, vpcSubnets...)
+	}
+	sources, err := r.scanner.NATTrafficBySource(r.ctx, r.logGroupName, startTime, endTime)
+	if err != nil {
+		r.logLine("  ⚠️  AZ breakdown by source subnet skipped: %v", err)
+		return nil
+	}
+	return analysis.ZoneSamplesBySource(sources, subnets, r.nats, total)
+}
+
+// zoneSamples attributes sampled traffic to availability zones. Without
+// per-NAT results the total can only be attributed when every NAT Gateway
+// sits in the same zone.
+func zoneSamples(nats []types.NATGateway, perNAT []core.NATTraffic, total *analysis.TrafficStats) []analysis.ZoneSample {
+	azByNAT := make(map[string]string, len(nats))
+	for _, nat := range nats {
+		azByNAT[nat.ID] = nat.AvailabilityZone
+	}
+
+	if len(perNAT) > 0 {
+		samples := make([]analysis.ZoneSample, 0, len(perNAT))
+		for _, t := range perNAT {
+			if t.Err != nil {
+				continue
+			}
+			samples = append(samples, analysis.ZoneSample{NATID: t.NATID, AvailabilityZone: azByNAT[t.NATID], Stats: t.Stats})
+		}
+		return samples
+	}
+
+	if len(nats) == 0 || total == nil {
+		return nil
+	}
+	for _, nat := range nats[1:] {
+		if nat.AvailabilityZone != nats[0].AvailabilityZone {
+			return nil
+		}
+	}
+	if len(nats) == 1 {
+		return []analysis.ZoneSample{{NATID: nats[0].ID, AvailabilityZone: nats[0].AvailabilityZone, Stats: total}}
+	}
+	ids := make([]string, 0, len(nats))
+	for _, nat := range nats {
+		ids = append(ids, nat.ID)
+	}
+	return []analysis.ZoneSample{{NATID: strings.Join(ids, ", "), AvailabilityZone: nats[0].AvailabilityZone, Stats: total}}
+}
+
 func (r *streamDeepScanRunner) stopFlowLogs() error {
 	if len(r.flowLogIDs) == 0 || r.flowLogsStopped {
 		return nil
 	}
 	r.logStage("cleanup", "Stopping Flow Logs")
-	if err := r.scanner.DeleteFlowLogs(r.ctx, r.flowLogIDs); err != nil {
+	// Detached from r.ctx so the deferred cleanup still works after an interrupt cancelled it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.ctx), 5*time.Minute)
+	defer cancel()
+	if r.provisionVia == ProvisionCloudFormation {
+		if err := r.scanner.DeleteFlowLogsStack(ctx, r.runID); err != nil {
+			return fmt.Errorf("failed to stop flow logs: %w", err)
+		}
+	} else if err := r.scanner.DeleteFlowLogs(ctx, r.flowLogIDs); err != nil {
 		return fmt.Errorf("failed to stop flow logs: %w", err)
 	}
 	r.flowLogsStopped = true
@@ -394,6 +1246,9 @@ func (r *streamDeepScanRunner) stopFlowLogs() error {
 }
 
 func (r *streamDeepScanRunner) handleLogGroupCleanup() error {
+	if r.firehoseStream != "" {
+		return nil
+	}
 	deleteLogGroup := r.autoCleanup
 	if !r.autoApprove {
 		answer, err := r.confirm(fmt.Sprintf("Delete CloudWatch Log Group %s?", r.logGroupName), true)
@@ -404,6 +1259,7 @@ func (r *streamDeepScanRunner) handleLogGroupCleanup() error {
 	}
 	if !deleteLogGroup {
 		r.logStage("cleanup", "Keeping log group: %s", r.logGroupName)
+		r.scheduleKeptLogGroupExpiry()
 		return nil
 	}
 
@@ -414,56 +1270,316 @@ func (r *streamDeepScanRunner) handleLogGroupCleanup() error {
 	return nil
 }
 
+// scheduleKeptLogGroupExpiry arranges deletion of the kept log group when
+// --expire-kept-log-group is set, and retags its ExpiresAt to match so that
+// expiry sweeps don't delete it earlier. Failure only warns: the scan
+// succeeded and the group's 1-day retention still expires its data.
+func (r *streamDeepScanRunner) scheduleKeptLogGroupExpiry() {
+	if r.expireKeptDays <= 0 {
+		r.keepLogGroupUntil(time.Time{})
+		r.logLine("  tip: delete it later with: terminat cleanup --region %s --log-group %s", r.region, r.logGroupName)
+		return
+	}
+	at, err := r.scanner.ScheduleLogGroupDeletion(r.ctx, r.logGroupName, time.Duration(r.expireKeptDays)*24*time.Hour)
+	if err != nil {
+		r.keepLogGroupUntil(time.Time{})
+		r.logLine("  ⚠️  could not schedule deletion (needs role %s): %v", core.CleanupSchedulerRoleName, err)
+		r.logLine("  delete it later with: terminat cleanup --region %s --log-group %s", r.region, r.logGroupName)
+		return
+	}
+	r.keepLogGroupUntil(at)
+	r.logStage("cleanup", "Log group will be deleted automatically at %s", at.UTC().Format(time.RFC3339))
+}
+
+// keepLogGroupUntil sets the kept log group's ExpiresAt tag to at, or never
+// when at is zero.
+func (r *streamDeepScanRunner) keepLogGroupUntil(at time.Time) {
+	if err := r.scanner.SetLogGroupExpiry(r.ctx, r.logGroupName, at); err != nil {
+		r.logLine("  ⚠️  could not update the log group's %s tag, so cleanup --expired may delete it: %v", core.ExpiresAtTag, err)
+	}
+}
+
 func (r *streamDeepScanRunner) renderFinalSummary() {
+	r.reportBuf = &strings.Builder{}
+	r.reportSections = nil
+
 	r.logLine("")
 	r.logLine("========== DEEP SCAN REPORT ==========")
+	r.logLine("Account: %s  Region: %s", r.names.Account(r.scanner.GetAccountID()), r.region)
+	r.renderHeadline()
 
-	r.logLine("NAT Gateways")
+	r.section("NAT Gateways")
 	for _, nat := range r.nats {
 		mode := nat.AvailabilityMode
 		if mode == "" {
 			mode = "zonal"
 		}
-		r.logLine("  - %s (%s, vpc=%s)", nat.ID, mode, nat.VPCID)
+		r.logLine("  - %s (%s, vpc=%s%s)", nat.Label(), mode, r.names.VPC(nat.VPCID), createdSuffix(nat))
+	}
+	if e := r.subnetEgress; e != nil && e.ENIID != "" {
+		r.logLine("  - Traffic of network interface %s only (subnet %s)", e.ENIID, e.SubnetID)
+	} else if e != nil {
+		r.logLine("  - Traffic from subnet %s only (route table %s)", e.SubnetID, e.RouteTableID)
+	}
+	r.renderScanCoverage()
+
+	if r.costAnomalyDays > 0 {
+		r.section("Billing Context")
+		if len(r.costAnomalies) == 0 {
+			r.logLine("  - No NAT Gateway cost anomalies in the last %d days", r.costAnomalyDays)
+		}
+		sampleEnd := time.Now()
+		sampleStart := sampleEnd.Add(-time.Duration(r.duration) * time.Minute)
+		for _, a := range r.costAnomalies {
+			end := a.EndDate
+			if end == "" {
+				end = "ongoing"
+			}
+			during := ""
+			if a.During(sampleStart, sampleEnd) {
+				during = " ⚠️ during the traffic sample"
+			}
+			r.logLine("  - %s to %s: +$%.2f (%s)%s", a.StartDate, end, a.TotalImpact, strings.Join(a.UsageTypes, ", "), during)
+		}
 	}
 
 	if len(r.allFindings) == 0 {
-		r.logLine("\nEndpoint Findings")
+		r.section("Endpoint Findings")
 		r.logLine("  - No endpoint issues found across scanned VPCs")
 	} else {
-		r.logLine("\nEndpoint Findings (%d)", len(r.allFindings))
+		r.section("Endpoint Findings (%d)", len(r.allFindings))
 		for _, finding := range r.allFindings {
-			r.logLine("  - [%s] %s", strings.ToUpper(finding.Severity), finding.Title)
+			r.logLine("  - [%s] %s (score %.1f, effort %s)", strings.ToUpper(finding.Severity), finding.Title, finding.Score, analysis.EffortLabel(finding.Effort))
 			r.logLine("    %s", finding.Description)
 			r.logLine("    Action: %s", finding.Action)
 		}
 	}
 
 	if r.trafficStats != nil && r.trafficStats.TotalRecords > 0 {
-		totalGB := float64(r.trafficStats.TotalBytes) / (1024 * 1024 * 1024)
-		r.logLine("\nTraffic Sample")
+		r.section("Traffic Sample")
 		r.logLine("  - Duration: %d minute(s)", r.duration)
-		r.logLine("  - Total: %d records, %.2f GB", r.trafficStats.TotalRecords, totalGB)
-		r.logLine("  - S3: %.2f GB (%.1f%%)", float64(r.trafficStats.S3Bytes)/(1024*1024*1024), r.trafficStats.S3Percentage())
-		r.logLine("  - DynamoDB: %.2f GB (%.1f%%)", float64(r.trafficStats.DynamoBytes)/(1024*1024*1024), r.trafficStats.DynamoPercentage())
-		r.logLine("  - ECR: %.2f GB (%.1f%%)", float64(r.trafficStats.ECRBytes)/(1024*1024*1024), r.trafficStats.ECRPercentage())
-		r.logLine("  - Other: %.2f GB (%.1f%%)", float64(r.trafficStats.OtherBytes)/(1024*1024*1024), r.trafficStats.OtherPercentage())
+		if r.collectionWindow != nil {
+			r.logLine("  - Window: %s", r.collectionWindow)
+		}
+		r.logLine("  - Total: %s records, %s", units.Count(r.trafficStats.TotalRecords), units.Format(r.trafficStats.TotalBytes))
+		for _, service := range r.trafficStats.ServiceOrder() {
+			r.logLine("  - %s: %s (%.1f%%)", service.Name(), units.Format(r.trafficStats.Bytes(service)), r.trafficStats.Percentage(service))
+		}
+
+		if len(r.natTraffic) > 0 {
+			r.section("Traffic by NAT Gateway")
+			for _, t := range r.natTraffic {
+				if t.Err != nil {
+					r.logLine("  - %s: query failed", types.NATLabel(r.nats, t.NATID))
+					continue
+				}
+				r.logLine("  - %s: %s (S3 %s, DynamoDB %s)", types.NATLabel(r.nats, t.NATID),
+					units.Format(t.Stats.TotalBytes), units.Format(t.Stats.Bytes(analysis.ServiceS3)), units.Format(t.Stats.Bytes(analysis.ServiceDynamoDB)))
+			}
+		}
+
+		if len(r.azTraffic) > 0 {
+			r.section("Traffic by Availability Zone")
+			for _, z := range r.azTraffic {
+				r.logLine("  - %s: %s (%.1f%%), projected $%.2f/month, endpoint savings $%.2f/month [%s]", z.AvailabilityZone,
+					units.Format(z.TotalBytes), z.SharePct, z.MonthlyCost, z.MonthlySavings, strings.Join(z.NATGateways, ", "))
+			}
+			if msg := analysis.AZImbalance(r.azTraffic); msg != "" {
+				r.logLine("  ⚠️  %s", msg)
+			}
+		}
+
+		if len(r.trafficStats.DynamoBytesByRegion) > 1 || r.trafficStats.CrossRegionDynamoBytes(r.region) > 0 || len(r.dynamoEndpoints) > 0 {
+			r.section("DynamoDB Traffic by Region")
+			for _, region := range sortedRegions(r.trafficStats.DynamoBytesByRegion) {
+				label := region
+				if label == "" {
+					label = "unknown"
+				}
+				note := ""
+				if region != "" && region != r.region {
+					note = " (cross-region: not carried by a gateway endpoint in " + r.region + ")"
+				}
+				r.logLine("  - %s: %s%s", label, units.Format(r.trafficStats.DynamoBytesByRegion[region]), note)
+			}
+			for _, ep := range r.dynamoEndpoints {
+				note := ""
+				if ep.CrossRegion {
+					note = " ⚠️  cross-region"
+				}
+				r.logLine("  - resolved %s: %d queries from %d client(s)%s", ep.Hostname, ep.Queries, ep.Clients, note)
+			}
+		}
+
+		if r.registryPulls != nil {
+			r.section("Public Registry Pulls (projected monthly)")
+			for _, name := range r.registryPulls.Registries() {
+				r.logLine("  - %s: %s GB", name, units.Number(r.registryPulls.MonthlyGBByRegistry[name]))
+			}
+			r.logLine("  - NAT cost today: $%.2f/month; with ECR pull-through cache: $%.2f/month endpoint hours + $%.2f/month endpoint data",
+				r.registryPulls.NATCostMonthly, r.registryPulls.EndpointFixedMonthly, r.registryPulls.EndpointDataMonthly)
+		}
+
+		if r.eksBundle != nil {
+			r.section("EKS Endpoint Bundle (%s)", strings.Join(r.eksBundle.Clusters, ", "))
+			for _, svc := range []string{"s3", "ecr.api", "ecr.dkr", "sts", "logs"} {
+				r.logLine("  - %s: %s GB/month", svc, units.Number(r.eksBundle.MonthlyGBByService[svc]))
+			}
+			r.logLine("  - Net: $%.2f/month (NAT $%.2f saved, endpoints $%.2f hours + $%.2f data)",
+				r.eksBundle.NetSavingsMonthly, r.eksBundle.NATCostMonthly, r.eksBundle.EndpointFixedMonthly, r.eksBundle.EndpointDataMonthly)
+		}
+
+		if r.publicIPv4 != nil {
+			p := r.publicIPv4
+			r.section("Public IPv4 Addresses")
+			for _, a := range p.Addresses {
+				r.logLine("  - %s on %s: $%.2f/month", a.Address, types.NATLabel(r.nats, a.Resource), a.MonthlyCost)
+			}
+			r.logLine("  - Total: $%.2f/month for %d address(es) at $%.3f/hour each", p.MonthlyCost, len(p.Addresses), analysis.PublicIPv4PricePerHour)
+			if p.IPv6CapableGB > 0 {
+				r.logLine("  - IPv6-capable traffic: %s GB/month to %s ($%.2f/month NAT data processing)",
+					units.Number(p.IPv6CapableGB), strings.Join(p.IPv6CapableServices(), ", "), p.IPv6CapableNATCostMonthly)
+			}
+		}
+
+		if len(r.trafficStats.Planes) > 0 {
+			r.section("Data Transfer vs API Calls")
+			for _, svc := range r.trafficStats.PlaneServices() {
+				p := r.trafficStats.Planes[svc]
+				r.logLine("  - %s: %s data, %s API calls (%.1f%% data)", svc,
+					units.Format(p.DataBytes), units.Format(p.ControlBytes), p.DataShare())
+			}
+		}
+
+		if ports := r.trafficStats.PortBreakdown(); len(ports) > 1 {
+			r.section("Traffic by Port")
+			for i, p := range ports {
+				if i == 5 {
+					r.logLine("  - ... %d more", len(ports)-i)
+					break
+				}
+				label := p.Key
+				if p.Name != "" {
+					label += " (" + p.Name + ")"
+				}
+				r.logLine("  - %s: %s (%.1f%%)", label, units.Format(p.Bytes), p.SharePct)
+				if p.Category != analysis.PortHTTPS {
+					r.logLine("      %s", p.Remediation())
+				}
+			}
+		}
+
+		if len(r.endpointCases) > 0 {
+			r.section("Interface Endpoint Break-Even")
+			for _, c := range r.endpointCases {
+				r.logLine("  - %s", c.Verdict())
+			}
+		}
+
+		if len(r.s3Attribution) > 0 {
+			r.section("S3 Traffic by Bucket (CloudTrail data events)")
+			for i, a := range r.s3Attribution {
+				if i == 10 {
+					r.logLine("  - ... %d more", len(r.s3Attribution)-i)
+					break
+				}
+				r.logLine("  - %s to %s by %s (%s requests)", units.Format(a.Bytes), a.Bucket, a.Principal, units.Count(a.Requests))
+			}
+		}
+
+		exact, broad, unmatched := r.trafficStats.AccuracyPercentages()
+		r.section("Classification Confidence")
+		r.logLine("  - Exact service range (S3, DynamoDB): %.1f%%", exact)
+		r.logLine("  - Broad EC2 range only (counted as ECR, upper bound): %.1f%%", broad)
+		r.logLine("  - No AWS range matched: %.1f%%", unmatched)
+		r.logLine("  - Resolved by DNS (registry and AWS API hostnames): %.1f%%", r.trafficStats.ResolvedPercentage())
+		if c := r.classCheck; c != nil && c.ComparedBytes > 0 {
+			r.logLine("  - Agrees with Flow Logs pkt-dst-aws-service on %.1f%% of %s", c.AgreementPct(), units.Format(c.ComparedBytes))
+			for i, d := range c.Disagreements {
+				if i == 3 {
+					r.logLine("    ... %d more in the report", len(c.Disagreements)-i)
+					break
+				}
+				r.logLine("    %-40s IP ranges: %-8s Flow Logs: %-10s %s", d.Address, d.IPRanges, d.FlowLogs, units.Format(d.Bytes))
+			}
+		}
 	} else {
-		r.logLine("\nTraffic Sample")
+		r.section("Traffic Sample")
 		r.logLine("  - No traffic records were collected in this run")
 	}
 
+	if c := r.coverage; c != nil {
+		r.section("Sample Completeness")
+		if c.Complete() {
+			r.logLine("  - Complete: every minute of the sample has records and none were skipped")
+		}
+		for _, note := range c.Notes() {
+			r.logLine("  - %s", note)
+		}
+	}
+
+	if r.includeRejected {
+		r.section("Rejected Traffic")
+		if r.rejected == nil {
+			r.logLine("  - No flows were rejected during the sample")
+		} else {
+			r.logLine("  - %s rejected flow(s), %s", units.Count(r.rejected.Flows), units.Format(r.rejected.Bytes))
+			r.logLine("  Top destinations:")
+			for _, p := range r.rejected.Destinations {
+				r.logLine("    %-40s %7s flow(s)  %s", p.Address, units.Count(p.Flows), strings.Join(p.Ports, ", "))
+			}
+			r.logLine("  Top sources:")
+			for _, p := range r.rejected.Sources {
+				r.logLine("    %-40s %7s flow(s)  %s", p.Address, units.Count(p.Flows), strings.Join(p.Ports, ", "))
+			}
+		}
+	}
+
+	if t := r.endpointENI; t != nil {
+		r.section("Endpoint Traffic (projected from sample)")
+		r.logLine("  - %s via %s: %s GB/month", t.EndpointID, t.ENIID, units.Number(t.MonthlyGB))
+		r.logLine("  - Endpoint data processing: $%.2f/month", t.EndpointCostMonthly)
+		r.logLine("  - Same traffic through a NAT Gateway: $%.2f/month ($%.2f/month avoided)", t.NATCostMonthly, t.AvoidedMonthly())
+	}
 	if r.costEstimate != nil {
-		r.logLine("\nCost Estimate (projected from sample)")
+		r.section("Cost Estimate (projected from sample)")
 		r.logLine("  - NAT data processing rate: $%.4f per GB", r.costEstimate.NATGatewayPricePerGB)
 		r.logLine("  - Current NAT cost: $%.2f/month", r.costEstimate.CurrentMonthlyCost)
 		r.logLine("  - S3 savings potential: $%.2f/month", r.costEstimate.S3SavingsMonthly)
+		if r.costEstimate.CrossRegionS3GB > 0 {
+			r.logLine("    excludes %s GB/month of traffic to S3 buckets in other regions", units.Number(r.costEstimate.CrossRegionS3GB))
+		}
 		r.logLine("  - DynamoDB savings potential: $%.2f/month", r.costEstimate.DynamoSavingsMonthly)
+		if r.costEstimate.CrossRegionDynamoGB > 0 {
+			r.logLine("    excludes %s GB/month of cross-region DynamoDB traffic", units.Number(r.costEstimate.CrossRegionDynamoGB))
+		}
 		r.logLine("  - Total savings potential: $%.2f/month ($%.2f/year)", r.costEstimate.TotalSavingsMonthly, r.costEstimate.TotalSavingsMonthly*12)
+		if charges := r.costEstimate.ChargeComponents(); len(charges) > 0 {
+			r.logLine("  - What gateway endpoints remove:")
+			for _, c := range charges {
+				mark, effect := "✗", "kept"
+				if c.Removed {
+					mark, effect = "✓", fmt.Sprintf("removed, saves $%.2f/month", c.MonthlySavings)
+				}
+				r.logLine("    %s %s %s (%s GB/month): %s; %s", mark, c.Service, c.Component, units.Number(c.GB), effect, c.Note)
+			}
+		}
+	}
+	if r.scanCost != nil {
+		r.logLine("  - Cost of this scan: %s", r.scanCost)
 	}
 
-	if r.endpointAnalysis != nil && r.endpointAnalysis.HasIssues() {
-		r.logLine("\nRemediation Commands")
+	hasIssues := r.endpointAnalysis != nil && r.endpointAnalysis.HasIssues()
+	if hasIssues || len(r.vpcRemediation) > 0 {
+		r.section("Remediation Commands")
+	}
+	if hasIssues {
+		if len(r.vpcRemediation) > 0 {
+			r.logLine("  # VPC %s", r.names.VPC(r.endpointAnalysis.VPCID))
+		}
+		for _, note := range r.endpointAnalysis.Prechecks() {
+			r.logLine("  ⚠️  %s", note)
+		}
 		for _, cmd := range r.endpointAnalysis.GetCreateEndpointCommands() {
 			r.logLine("  %s", cmd)
 		}
@@ -471,17 +1587,261 @@ func (r *streamDeepScanRunner) renderFinalSummary() {
 			r.logLine("  %s", cmd)
 		}
 	}
+	for _, fix := range r.vpcRemediation {
+		r.logLine("  # VPC %s", r.names.VPC(fix.VPCID))
+		for _, cmd := range fix.CreateEndpointCommands {
+			r.logLine("  %s", cmd)
+		}
+		for _, cmd := range fix.AddRouteCommands {
+			r.logLine("  %s", cmd)
+		}
+	}
+
+	if r.hiddenByBaseline > 0 {
+		r.logLine("\n%d accepted finding(s) hidden (terminat baseline list)", r.hiddenByBaseline)
+	}
+	if r.hiddenBelowMin > 0 {
+		r.logLine("\n%d finding(s)/recommendation(s) projected to save less than $%.2f/month hidden (--min-savings)", r.hiddenBelowMin, r.minSavings)
+	}
 
 	if len(r.recommendations) > 0 {
-		r.logLine("\nRecommendations")
+		r.section("Recommendations")
 		for i, rec := range r.recommendations {
-			r.logLine("  %d. %s [%s]", i+1, rec.Title, strings.ToUpper(rec.Priority))
+			r.logLine("  %d. %s [%s] (score %.1f, effort %s)", i+1, rec.Title, strings.ToUpper(rec.Priority), rec.Score, analysis.EffortLabel(rec.Effort))
 			r.logLine("     %s", rec.Description)
 			if rec.Savings != "" {
 				r.logLine("     Savings: %s", rec.Savings)
 			}
 		}
 	}
+
+	if plan := analysis.PlanRemediation(r.allFindings, r.recommendations); len(plan.Items) > 0 {
+		r.section("Remediation Plan")
+		for i, item := range plan.Items {
+			r.logLine("  %d. %s (%s, ~%gh, ~$%.2f/month, $%.2f/month per hour)", i+1, item.Title, item.Effort, item.Hours, item.MonthlySavings, item.SavingsPerHour)
+		}
+		r.logLine("  Total: ~%gh for ~$%.2f/month", plan.Hours, plan.MonthlySavings)
+	}
+
+	r.logLine("")
+	r.logLine("Report sections (byte offsets): %s", r.sectionIndex())
+	r.lastReport = r.reportBuf.String()
+	r.reportBuf = nil
+}
+
+// renderScanCoverage says which NAT Gateways and VPCs the sample measured,
+// so no one mistakes a config-checked or skipped VPC for a measured one.
+func (r *streamDeepScanRunner) renderScanCoverage() {
+	c := r.scanCoverage
+	if c == nil {
+		return
+	}
+	r.section("Scan Coverage")
+	r.logLine("  Source: %s", c.Source)
+	for _, v := range c.VPCs {
+		r.logLine("  - VPC %s: %s (%s)", r.names.VPC(v.ID), v.Status, v.Reason)
+		for _, n := range c.NATGateways {
+			if n.VPCID != v.ID {
+				continue
+			}
+			if n.Reason != "" {
+				r.logLine("    - %s: %s (%s)", types.NATLabel(r.nats, n.ID), n.Status, n.Reason)
+			} else {
+				r.logLine("    - %s: %s", types.NATLabel(r.nats, n.ID), n.Status)
+			}
+		}
+	}
+}
+
+// renderHeadline prints the key numbers first so they don't scroll away
+// behind the detailed sections.
+func (r *streamDeepScanRunner) renderHeadline() {
+	h := analysis.BuildHeadline(r.costEstimate, r.trafficStats, r.allFindings, r.recommendations, r.duration)
+	r.section("Headline")
+	if r.costEstimate != nil {
+		r.logLine("  - NAT spend: $%.2f/month (projected)", h.MonthlyNATCost)
+		r.logLine("  - Savings potential: $%.2f/month ($%.2f/year)", h.MonthlySavings, h.MonthlySavings*12)
+	}
+	if len(h.Actions) == 0 {
+		r.logLine("  - Top actions: none, no issues found")
+	} else {
+		r.logLine("  - Top actions:")
+		for i, a := range h.Actions {
+			if a.SavingsEstimated {
+				r.logLine("    %d. %s (~$%.2f/month, score %.1f)", i+1, a.Title, a.MonthlySavings, a.Score)
+			} else {
+				r.logLine("    %d. %s", i+1, a.Title)
+			}
+		}
+	}
+	r.logLine("  - Confidence: %s (%s)", h.Confidence, h.ConfidenceReason)
+}
+
+// section prints a numbered section marker, noting its offset in the
+// report so long output can be navigated after scroll-back is lost.
+func (r *streamDeepScanRunner) section(format string, args ...any) {
+	title := fmt.Sprintf(format, args...)
+	r.logLine("")
+	if r.reportBuf != nil {
+		r.reportSections = append(r.reportSections, reportSection{Title: title, Offset: r.reportBuf.Len()})
+	}
+	r.logLine("== [%d] %s ==", len(r.reportSections), title)
+}
+
+// sectionIndex is the table-of-contents line printed after the report.
+func (r *streamDeepScanRunner) sectionIndex() string {
+	parts := make([]string, 0, len(r.reportSections))
+	for i, sec := range r.reportSections {
+		parts = append(parts, fmt.Sprintf("[%d] %s @%d", i+1, sec.Title, sec.Offset))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// saveReportText duplicates the report just printed to a .txt file when
+// --report-txt is set. A failed write is reported but doesn't fail the scan.
+func (r *streamDeepScanRunner) saveReportText() {
+	if !r.reportTxt || r.lastReport == "" {
+		return
+	}
+	filename := fmt.Sprintf("terminat-report-%s.txt", time.Now().Format("20060102-150405"))
+	if r.outputFile != "" {
+		filename = strings.TrimSuffix(r.outputFile, filepath.Ext(r.outputFile)) + ".txt"
+	}
+	content := r.lastReport
+	if r.redact {
+		red, err := redact.New(r.scanner.GetAccountID())
+		if err != nil {
+			r.logStage("export", "Could not save text report: %v", err)
+			return
+		}
+		content = red.WithNames(r.redactedNames()...).String(content)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		r.logStage("export", "Could not save text report: %v", err)
+		return
+	}
+	absPath, _ := filepath.Abs(filename)
+	if absPath == "" {
+		absPath = filename
+	}
+	r.logStage("export", "Saved text report: %s", absPath)
+}
+
+// buildReport collects the run's results for export.
+func (r *streamDeepScanRunner) buildReport() *report.Report {
+	rep := report.New(r.region, r.scanner.GetAccountID(), r.duration, r.nats, r.trafficStats, r.costEstimate, r.endpointAnalysis)
+	rep.LogGroupName = r.logGroupName
+	rep.DurationPreset = r.durationPreset
+	rep.CollectionWindow = r.collectionWindow
+	rep.ScanCoverage = r.scanCoverage
+	rep.ScanCost = r.scanCost
+	rep.AZTraffic = r.azTraffic
+	rep.NATCosts = r.natCosts
+	rep.S3Attribution = r.s3Attribution
+	rep.DynamoDBEndpoints = r.dynamoEndpoints
+	rep.RegistryPulls = r.registryPulls
+	rep.EKSBundle = r.eksBundle
+	rep.PublicIPv4 = r.publicIPv4
+	rep.EndpointCases = r.endpointCases
+	rep.FriendlyNames = r.names
+	rep.Findings = r.allFindings
+	rep.VPCRemediation = r.vpcRemediation
+	rep.Inventory = r.inventory
+	rep.Subnet = r.subnetEgress
+	rep.EndpointENI = r.endpointENI
+	rep.Recommendations = r.recommendations
+	rep.Lang = r.reportLang
+	rep.Redact = r.redact
+	rep.MaxRows = r.maxRows
+	rep.MinSavings = r.minSavings
+	rep.HiddenBelowMinSavings = r.hiddenBelowMin
+	rep.HiddenByBaseline = r.hiddenByBaseline
+	rep.CostAnomalies = r.costAnomalies
+	rep.CostAnomalyDays = r.costAnomalyDays
+	rep.RejectedTraffic = r.rejected
+	rep.SampleCoverage = r.coverage
+	rep.ClassificationCheck = r.classCheck
+	rep.Methodology = reportMethodology(r.scanner, r.region, r.duration, r.costEstimate)
+	return rep
+}
+
+// collectInventory snapshots the scanned VPCs for --include-inventory. A
+// failure leaves the inventory out of the report rather than failing the scan.
+func (r *streamDeepScanRunner) collectInventory() {
+	if !r.includeInventory {
+		return
+	}
+	inventory, err := r.scanner.Inventory(r.ctx, uniqueVPCIDs(r.nats))
+	if err != nil {
+		r.logStage("export", "Inventory not included: %v", err)
+		return
+	}
+	r.inventory = inventory
+	r.logStage("export", "Collected inventory of %d VPC(s)", len(inventory))
+}
+
+// uniqueVPCIDs lists the VPCs of nats, in order, once each.
+func uniqueVPCIDs(nats []types.NATGateway) []string {
+	var vpcIDs []string
+	seen := map[string]bool{}
+	for _, nat := range nats {
+		if !seen[nat.VPCID] {
+			seen[nat.VPCID] = true
+			vpcIDs = append(vpcIDs, nat.VPCID)
+		}
+	}
+	return vpcIDs
+}
+
+// createdSuffix is ", created 2021-03-04 (3y 2mo ago)" for NAT Gateway
+// listings, or "" when nat's creation time is unknown.
+func createdSuffix(nat types.NATGateway) string {
+	if created := analysis.NATCreated(nat, time.Now()); created != "" {
+		return ", created " + created
+	}
+	return ""
+}
+
+// natSetupRecommendations recommends Regional NAT Gateways for the VPCs of
+// nats, flagging old zonal NAT Gateways their VPCs have outgrown. Subnets
+// are best effort: without them, only the NAT Gateway count is looked at.
+func natSetupRecommendations(ctx context.Context, scanner Discoverer, nats []types.NATGateway) []analysis.Recommendation {
+	var subnets []types.Subnet
+	for _, vpcID := range uniqueVPCIDs(nats) {
+		vpcSubnets, err := scanner.DiscoverSubnets(ctx, vpcID)
+		if err != nil {
+			return analysis.AnalyzeNATGatewaySetup(nats, nil, time.Now())
+		}
+		subnets = append(subnets, vpcSubnets...)
+	}
+	return analysis.AnalyzeNATGatewaySetup(nats, analysis.VPCAvailabilityZones(subnets), time.Now())
+}
+
+// saveLastRun keeps this run's report and raw query results for
+// `terminat bundle`. Failures only cost the bundle, not the scan.
+func (r *streamDeepScanRunner) saveLastRun() {
+	dir, err := bundle.DefaultDir()
+	if err == nil {
+		err = bundle.SaveLastRun(dir, r.buildReport(), r.scanner.QueryRecords())
+	}
+	if err != nil {
+		r.logStage("export", "Could not keep scan artifacts for terminat bundle: %v", err)
+	}
+}
+
+// saveHistory keeps the report in the scan history, local or shared.
+// Failures are logged without failing the scan.
+func (r *streamDeepScanRunner) saveHistory() {
+	if r.history == nil {
+		return
+	}
+	rep := r.buildReport()
+	entry := history.NewEntry(r.runID, rep)
+	if err := r.history.Put(r.ctx, entry, rep); err != nil {
+		r.logStage("export", "Could not save the report to the scan history: %v", err)
+		return
+	}
+	r.logStage("export", "Report kept in scan history as %s (%s)", entry.ID(), r.history.Location())
 }
 
 func (r *streamDeepScanRunner) exportIfRequested() error {
@@ -489,7 +1849,7 @@ func (r *streamDeepScanRunner) exportIfRequested() error {
 		return nil
 	}
 
-	rep := report.New(r.region, r.scanner.GetAccountID(), r.duration, r.nats, r.trafficStats, r.costEstimate, r.endpointAnalysis)
+	rep := r.buildReport()
 	filename := r.outputFile
 	if filename == "" {
 		timestamp := time.Now().Format("20060102-150405")
@@ -527,21 +1887,88 @@ func (r *streamDeepScanRunner) sendDataHubIfConfigured() error {
 	}
 
 	r.logStage("datahub", "Sending events to DoiT DataHub")
-	events := datahub.BuildEvents(r.scanner.GetAccountID(), r.region, r.nats, r.trafficStats, r.costEstimate, r.endpointAnalysis)
-	if err := datahub.Send(r.datahubAPIKey, r.datahubCustomerCtx, events); err != nil {
+	usage := dataHubUsage(r.scanner, r.datahubGranularity, r.natTraffic, r.duration)
+	events := datahub.BuildEvents(r.scanner.GetAccountID(), r.region, r.nats, r.trafficStats, r.costEstimate, r.endpointAnalysis, usage)
+	events = append(events, datahub.BuildScanCostEvents(r.scanner.GetAccountID(), r.region, r.runID, r.scanCost)...)
+	if err := datahub.LoadConfig().Transport.Send(r.datahubAPIKey, r.datahubCustomerCtx, events); err != nil {
 		return err
 	}
 	r.logStage("datahub", "Sent %d event(s)", len(events))
 	return nil
 }
 
+// notifyIfConfigured sends the headline to the --notify targets. The
+// report is already printed and saved, so failed deliveries are only logged.
+func (r *streamDeepScanRunner) notifyIfConfigured() {
+	if len(r.notifyTargets) == 0 {
+		return
+	}
+	h := analysis.BuildHeadline(r.costEstimate, r.trafficStats, r.allFindings, r.recommendations, r.duration)
+	summary := notify.NewSummary(r.scanner.GetAccountID(), r.region, h)
+	summary.AccountAlias = r.names.AccountAlias
+	if data, err := r.reportJSON(); err == nil {
+		summary.Report = data
+	} else {
+		r.logStage("notify", "Report not attached: %v", err)
+	}
+	if err := notify.Send(r.ctx, r.notifyTargets, summary); err != nil {
+		r.logStage("notify", "Some notifications failed: %v", err)
+		return
+	}
+	r.logStage("notify", "Sent summary to %d target(s)", len(r.notifyTargets))
+}
+
+// syncJiraIfConfigured files high-severity findings as Jira issues for
+// --jira. Like notifications, a failure is logged without failing the scan.
+func (r *streamDeepScanRunner) syncJiraIfConfigured() {
+	if r.jira == nil {
+		return
+	}
+	client, err := jira.NewClient(*r.jira)
+	if err == nil {
+		var res jira.SyncResult
+		res, err = client.Sync(r.ctx, r.scanner.GetAccountID(), r.region, r.allFindings)
+		if len(res.Created)+len(res.Updated) > 0 || err == nil {
+			r.logStage("jira", "Created %d and updated %d issue(s) %s", len(res.Created), len(res.Updated), strings.Join(append(res.Created, res.Updated...), " "))
+		}
+	}
+	if err != nil {
+		r.logStage("jira", "Jira sync failed: %v", err)
+	}
+}
+
+// reportJSON is the exported JSON report for notification payloads,
+// redacted when --redact is set.
+func (r *streamDeepScanRunner) reportJSON() ([]byte, error) {
+	data, err := json.Marshal(r.buildReport())
+	if err != nil || !r.redact {
+		return data, err
+	}
+	red, err := redact.New(r.scanner.GetAccountID())
+	if err != nil {
+		return nil, err
+	}
+	return []byte(red.WithNames(r.redactedNames()...).String(string(data))), nil
+}
+
+// redactedNames are the account, VPC and NAT Gateway names --redact hides.
+func (r *streamDeepScanRunner) redactedNames() []string {
+	return append(r.names.All(), types.NATNames(r.nats)...)
+}
+
 func (r *streamDeepScanRunner) confirm(prompt string, defaultYes bool) (bool, error) {
+	return confirmPrompt(r.reader, prompt, defaultYes)
+}
+
+// confirmPrompt asks a yes/no question on stdout and reads the answer from reader.
+func confirmPrompt(reader *bufio.Reader, prompt string, defaultYes bool) (bool, error) {
 	defaultText := "y/N"
 	if defaultYes {
 		defaultText = "Y/n"
 	}
 
-	answer, err := r.prompt(fmt.Sprintf("%s [%s]: ", prompt, defaultText))
+	fmt.Printf("%s [%s]: ", prompt, defaultText)
+	answer, err := reader.ReadString('\n')
 	if err != nil {
 		return false, err
 	}
@@ -562,6 +1989,7 @@ func (r *streamDeepScanRunner) prompt(q string) (string, error) {
 }
 
 func (r *streamDeepScanRunner) logStage(stage, format string, args ...any) {
+	r.heartbeat.Phase(stage)
 	ts := time.Now().Format("15:04:05")
 	prefix := fmt.Sprintf("[%s] %-8s ", ts, stage)
 	r.printWrapped(prefix, fmt.Sprintf(format, args...))
@@ -611,6 +2039,14 @@ func detectOutputWidth(file *os.File) int {
 	return defaultWidth
 }
 
+// emit writes to stdout and, while the final report renders, to reportBuf.
+func (r *streamDeepScanRunner) emit(text string) {
+	fmt.Print(text)
+	if r.reportBuf != nil {
+		r.reportBuf.WriteString(text)
+	}
+}
+
 func (r *streamDeepScanRunner) printWrapped(prefix, text string) {
 	width := r.outputWidth
 	if width <= 0 {
@@ -620,10 +2056,10 @@ func (r *streamDeepScanRunner) printWrapped(prefix, text string) {
 	for _, rawLine := range strings.Split(text, "\n") {
 		for i, line := range wrapLine(rawLine, maxInt(20, width-visibleLen(prefix))) {
 			if i == 0 {
-				fmt.Printf("%s%s\n", prefix, line)
+				r.emit(prefix + line + "\n")
 				continue
 			}
-			fmt.Printf("%s%s\n", strings.Repeat(" ", visibleLen(prefix)), line)
+			r.emit(strings.Repeat(" ", visibleLen(prefix)) + line + "\n")
 		}
 	}
 }
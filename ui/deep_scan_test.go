@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/datahub"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestCleanupPromptRendersBeneathFinalReport(t *testing.T) {
+	scanner := &fakeScanner{}
+	m := &deepScanModel{scanner: scanner, logGroupName: "/terminator/run-1", flowLogIDs: []string{"fl-1"}}
+	m.Update(flowLogsStoppedMsg{})
+	if m.phase != phaseAwaitingCleanup {
+		t.Fatalf("expected cleanup phase, got %d", m.phase)
+	}
+
+	view := m.View()
+	report := strings.Index(view, "HEADLINE")
+	prompt := strings.Index(view, "Delete CloudWatch Log Group?")
+	if report < 0 || prompt < report {
+		t.Fatalf("expected the report with the cleanup prompt beneath it:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m.phase != phaseDone {
+		t.Fatalf("expected done phase after keeping the log group, got %d", m.phase)
+	}
+	view = m.View()
+	if strings.Count(view, "HEADLINE") != 1 || strings.Contains(view, "Delete CloudWatch Log Group?") {
+		t.Fatalf("expected a single report without the cleanup prompt:\n%s", view)
+	}
+	if !strings.Contains(view, "Log group kept: /terminator/run-1") {
+		t.Errorf("expected the cleanup outcome in the footer:\n%s", view)
+	}
+	if want := []string{"SetLogGroupExpiry /terminator/run-1 never"}; strings.Join(scanner.calls, ";") != strings.Join(want, ";") {
+		t.Errorf("calls = %v, want the kept log group tagged to never expire", scanner.calls)
+	}
+}
+
+func TestExcludeVPCs(t *testing.T) {
+	nats := []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1"}, {ID: "nat-2", VPCID: "vpc-2"}, {ID: "nat-3", VPCID: "vpc-1"}}
+	kept := excludeVPCs(nats, []string{"vpc-1"})
+	if len(kept) != 1 || kept[0].ID != "nat-2" {
+		t.Fatalf("expected only nat-2 to be kept, got %+v", kept)
+	}
+	if len(excludeVPCs(nats, nil)) != 3 {
+		t.Fatal("expected no exclusions to keep every NAT Gateway")
+	}
+}
+
+func TestTrafficByVPC(t *testing.T) {
+	nats := []types.NATGateway{{ID: "nat-a", VPCID: "vpc-1"}, {ID: "nat-b", VPCID: "vpc-1"}, {ID: "nat-c", VPCID: "vpc-2"}}
+	s3 := func(bytes int64) *analysis.TrafficStats {
+		return &analysis.TrafficStats{Services: map[analysis.Service]analysis.ServiceStats{analysis.ServiceS3: {Bytes: bytes}}}
+	}
+	perNAT := []core.NATTraffic{
+		{NATID: "nat-a", Stats: s3(100)},
+		{NATID: "nat-b", Stats: s3(50)},
+		{NATID: "nat-c", Err: errors.New("query failed")},
+	}
+
+	byVPC := trafficByVPC(nats, perNAT, s3(150))
+	if got := byVPC["vpc-1"].Bytes(analysis.ServiceS3); got != 150 {
+		t.Errorf("vpc-1 S3 bytes = %d, want 150", got)
+	}
+	if byVPC["vpc-2"] != nil {
+		t.Errorf("vpc-2 has traffic from a failed query: %+v", byVPC["vpc-2"])
+	}
+	if trafficByVPC(nats, nil, s3(150)) != nil {
+		t.Error("traffic of several VPCs without per-NAT queries was attributed")
+	}
+	if got := trafficByVPC(nats[:1], nil, s3(150)); got["vpc-1"] == nil {
+		t.Error("single-VPC traffic was not attributed to it")
+	}
+}
+
+func TestDataHubUsageFailedNAT(t *testing.T) {
+	stats := &analysis.TrafficStats{TotalBytes: 100}
+	perNAT := []core.NATTraffic{
+		{NATID: "nat-a", Stats: stats},
+		{NATID: "nat-b", Err: errors.New("query failed")},
+	}
+	// A failed NAT falls back to account-level events rather than leaving
+	// its traffic out of per-NAT ones
+	if usage := dataHubUsage(&fakeScanner{}, datahub.GranularityNAT, perNAT, 60); usage != nil {
+		t.Errorf("usage with a failed NAT query = %+v, want nil", usage)
+	}
+	if usage := dataHubUsage(&fakeScanner{}, datahub.GranularityAccount, perNAT[:1], 60); usage != nil {
+		t.Errorf("usage at account granularity = %+v, want nil", usage)
+	}
+}
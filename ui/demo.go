@@ -51,16 +51,19 @@ func demoDeepScanModel() *deepScanModel {
 			{ID: "nat-0a1b2c3d4e5f67890", VPCID: "vpc-0abc123def456789"},
 		},
 		trafficStats: &analysis.TrafficStats{
-			S3Bytes:       34359738368, // 32 GB
-			DynamoBytes:   10737418240, // 10 GB
-			ECRBytes:      3221225472,  // 3 GB
-			OtherBytes:    5368709120,  // 5 GB
-			TotalBytes:    53687091200, // 50 GB
-			S3Records:     42000,
-			DynamoRecords: 18500,
-			ECRRecords:    3200,
-			OtherRecords:  6300,
-			TotalRecords:  70000,
+			Services: map[analysis.Service]analysis.ServiceStats{
+				analysis.ServiceS3:       {Bytes: 34359738368, Records: 42000}, // 32 GB
+				analysis.ServiceDynamoDB: {Bytes: 10737418240, Records: 18500}, // 10 GB
+				analysis.ServiceECR:      {Bytes: 3221225472, Records: 3200},   // 3 GB
+				analysis.ServiceOther:    {Bytes: 5368709120, Records: 6300},   // 5 GB
+			},
+			TotalBytes:   53687091200, // 50 GB
+			TotalRecords: 70000,
+			Accuracy: analysis.ClassificationAccuracy{
+				ExactBytes:     45097156608, // S3 + DynamoDB
+				BroadEC2Bytes:  3221225472,
+				UnmatchedBytes: 5368709120,
+			},
 		},
 		costEstimate: &analysis.CostEstimate{
 			Region:               "us-east-1",
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/fixtures"
+	"github.com/doitintl/terminator/internal/testutil/golden"
+)
+
+func TestReportTemplateGolden(t *testing.T) {
+	for _, s := range fixtures.All() {
+		t.Run(s.Name, func(t *testing.T) {
+			m := &deepScanModel{
+				nats:             s.NATs,
+				allFindings:      s.Findings,
+				endpointAnalysis: s.Endpoints,
+				trafficStats:     s.Stats,
+				costEstimate:     s.Cost,
+				duration:         s.Duration,
+				logGroupName:     "/terminator/" + s.Name,
+			}
+			if s.Endpoints != nil {
+				m.deepScannedVPC = s.Endpoints.VPCID
+			}
+			m.scanCoverage = analysis.BuildScanCoverage(s.NATs, "Flow Logs created for this scan on the NAT Gateways", nil, nil, m.deepScannedVPC)
+			golden.Check(t, s.Name+".txt", []byte(m.renderReportBody()))
+		})
+	}
+}
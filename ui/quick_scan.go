@@ -8,7 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/analysis"
 	"github.com/doitintl/terminator/pkg/types"
 )
 
@@ -35,11 +35,12 @@ var (
 )
 
 type quickScanModel struct {
-	scanner  *core.Scanner
+	scanner  Scanner
 	ctx      context.Context
 	spinner  spinner.Model
 	step     string
 	nats     []types.NATGateway
+	names    types.FriendlyNames
 	findings []types.Finding
 	err      error
 	done     bool
@@ -50,7 +51,8 @@ type scanStepMsg struct {
 }
 
 type natsDiscoveredMsg struct {
-	nats []types.NATGateway
+	nats  []types.NATGateway
+	names types.FriendlyNames
 }
 
 type findingsMsg struct {
@@ -63,7 +65,7 @@ type scanErrorMsg struct {
 
 type scanCompleteMsg struct{}
 
-func RunQuickScan(ctx context.Context, scanner *core.Scanner, uiMode string) error {
+func RunQuickScan(ctx context.Context, scanner Scanner, uiMode string) error {
 	switch strings.ToLower(strings.TrimSpace(uiMode)) {
 	case "", "stream":
 		return RunQuickScanStream(ctx, scanner)
@@ -74,7 +76,7 @@ func RunQuickScan(ctx context.Context, scanner *core.Scanner, uiMode string) err
 	}
 }
 
-func runQuickScanTUI(ctx context.Context, scanner *core.Scanner) error {
+func runQuickScanTUI(ctx context.Context, scanner Scanner) error {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4"))
@@ -122,6 +124,7 @@ func (m quickScanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case natsDiscoveredMsg:
 		m.nats = msg.nats
+		m.names = msg.names
 		return m, m.analyzeConfiguration
 
 	case findingsMsg:
@@ -166,11 +169,12 @@ func (m quickScanModel) View() string {
 func (m quickScanModel) renderResults() string {
 	var b strings.Builder
 
+	b.WriteString(infoStyle.Render(fmt.Sprintf("Account: %s\n\n", m.names.Account(m.scanner.GetAccountID()))))
 	b.WriteString(stepStyle.Render(fmt.Sprintf("Found %d NAT Gateway(s)\n\n", len(m.nats))))
 
 	for _, nat := range m.nats {
-		b.WriteString(fmt.Sprintf("  • %s (%s, %s)\n", nat.ID, nat.AvailabilityMode, nat.State))
-		b.WriteString(fmt.Sprintf("    VPC: %s\n", nat.VPCID))
+		b.WriteString(fmt.Sprintf("  • %s (%s, %s%s)\n", nat.Label(), nat.AvailabilityMode, nat.State, createdSuffix(nat)))
+		b.WriteString(fmt.Sprintf("    VPC: %s\n", m.names.VPC(nat.VPCID)))
 	}
 
 	b.WriteString("\n")
@@ -201,7 +205,7 @@ func (m quickScanModel) discoverNATs() tea.Msg {
 		return scanErrorMsg{err: err}
 	}
 
-	return natsDiscoveredMsg{nats: nats}
+	return natsDiscoveredMsg{nats: nats, names: m.scanner.FriendlyNames(m.ctx, uniqueVPCIDs(nats))}
 }
 
 func (m quickScanModel) analyzeConfiguration() tea.Msg {
@@ -219,11 +223,11 @@ func (m quickScanModel) complete() tea.Msg {
 	return scanCompleteMsg{}
 }
 
-func discoverNATsForQuickScan(ctx context.Context, scanner *core.Scanner) ([]types.NATGateway, error) {
+func discoverNATsForQuickScan(ctx context.Context, scanner Scanner) ([]types.NATGateway, error) {
 	return scanner.DiscoverNATGateways(ctx)
 }
 
-func analyzeQuickFindings(ctx context.Context, scanner *core.Scanner, nats []types.NATGateway) ([]types.Finding, error) {
+func analyzeQuickFindings(ctx context.Context, scanner Scanner, nats []types.NATGateway) ([]types.Finding, error) {
 	var findings []types.Finding
 
 	// Group NATs by VPC
@@ -265,6 +269,7 @@ func analyzeQuickFindings(ctx context.Context, scanner *core.Scanner, nats []typ
 				Service:     "S3",
 				Action:      "Create S3 Gateway VPC endpoint and associate with private route tables",
 				Impact:      "All S3 traffic is going through NAT Gateway, incurring $0.045/GB data processing charges",
+				Effort:      analysis.EffortLow,
 			})
 		} else {
 			// Check if endpoint is associated with route tables that route to NAT
@@ -303,6 +308,7 @@ func analyzeQuickFindings(ctx context.Context, scanner *core.Scanner, nats []typ
 					Service:     "S3",
 					Action:      fmt.Sprintf("Associate S3 endpoint with route tables: %s", strings.Join(missingAssociations, ", ")),
 					Impact:      "S3 traffic from some subnets is still going through NAT Gateway",
+					Effort:      analysis.EffortLow,
 				})
 			}
 		}
@@ -328,6 +334,7 @@ func analyzeQuickFindings(ctx context.Context, scanner *core.Scanner, nats []typ
 				Service:     "DynamoDB",
 				Action:      "Create DynamoDB Gateway VPC endpoint and associate with private route tables",
 				Impact:      "All DynamoDB traffic is going through NAT Gateway, incurring $0.045/GB data processing charges",
+				Effort:      analysis.EffortLow,
 			})
 		} else {
 			// Similar check for DynamoDB endpoint associations
@@ -365,6 +372,7 @@ func analyzeQuickFindings(ctx context.Context, scanner *core.Scanner, nats []typ
 					Service:     "DynamoDB",
 					Action:      fmt.Sprintf("Associate DynamoDB endpoint with route tables: %s", strings.Join(missingAssociations, ", ")),
 					Impact:      "DynamoDB traffic from some subnets is still going through NAT Gateway",
+					Effort:      analysis.EffortLow,
 				})
 			}
 		}
@@ -2,39 +2,53 @@ package ui
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"time"
 
-	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/doitintl/terminator/pkg/types"
 )
 
-func RunQuickScanStream(ctx context.Context, scanner *core.Scanner) error {
+func RunQuickScanStream(ctx context.Context, scanner Scanner) error {
+	_, err := RunQuickScanStreamSummary(ctx, scanner)
+	return err
+}
+
+// RunQuickScanStreamSummary runs a stream quick scan and returns its per-account summary row.
+func RunQuickScanStreamSummary(ctx context.Context, scanner Scanner) (AccountSummary, error) {
+	summary := AccountSummary{Region: scanner.GetRegion(), AccountID: scanner.GetAccountID()}
 	started := time.Now()
 	quickLog("scan", "Quick scan started (region=%s account=%s ui=stream)", scanner.GetRegion(), scanner.GetAccountID())
 
 	quickLog("discover", "Discovering NAT Gateways")
 	nats, err := discoverNATsForQuickScan(ctx, scanner)
 	if err != nil {
-		return err
+		return summary, err
 	}
+	summary.NATGateways = len(nats)
+	names := scanner.FriendlyNames(ctx, uniqueVPCIDs(nats))
 	quickLog("discover", "Found %d NAT Gateway(s)", len(nats))
 
 	quickLog("analyze", "Analyzing VPC endpoint configuration")
 	findings, err := analyzeQuickFindings(ctx, scanner, nats)
 	if err != nil {
-		return err
+		return summary, err
 	}
+	summary.Findings = len(findings)
 	quickLog("analyze", "Analysis complete: findings=%d", len(findings))
 
 	fmt.Println()
 	fmt.Println("========== QUICK SCAN REPORT ==========")
+	fmt.Printf("Account: %s\n", names.Account(scanner.GetAccountID()))
 	fmt.Printf("NAT Gateways: %d\n", len(nats))
 	for _, nat := range nats {
 		mode := nat.AvailabilityMode
 		if mode == "" {
 			mode = "zonal"
 		}
-		fmt.Printf("  - %s (%s, %s, vpc=%s)\n", nat.ID, mode, nat.State, nat.VPCID)
+		fmt.Printf("  - %s (%s, %s, vpc=%s%s)\n", nat.Label(), mode, nat.State, names.VPC(nat.VPCID), createdSuffix(nat))
 	}
 
 	fmt.Printf("\nFindings: %d\n", len(findings))
@@ -49,7 +63,66 @@ func RunQuickScanStream(ctx context.Context, scanner *core.Scanner) error {
 	}
 
 	quickLog("scan", "Completed in %s", formatDuration(time.Since(started)))
-	return nil
+	return summary, nil
+}
+
+// QuickScanReport runs a quick scan without printing anything and returns
+// its NAT Gateways and findings as a report, for terminat serve.
+func QuickScanReport(ctx context.Context, scanner Scanner) (*report.Report, error) {
+	nats, err := discoverNATsForQuickScan(ctx, scanner)
+	if err != nil {
+		return nil, err
+	}
+	findings, err := analyzeQuickFindings(ctx, scanner, nats)
+	if err != nil {
+		return nil, err
+	}
+	rep := report.New(scanner.GetRegion(), scanner.GetAccountID(), 0, nats, nil, nil, nil)
+	rep.FriendlyNames = scanner.FriendlyNames(ctx, uniqueVPCIDs(nats))
+	rep.Findings = findings
+	return rep, nil
+}
+
+// QuickScanResult is what scan quick --format json prints. The lists are
+// never null, so scripts can iterate them without checks.
+type QuickScanResult struct {
+	Region      string               `json:"region"`
+	AccountID   string               `json:"account_id"`
+	NATGateways []types.NATGateway   `json:"nat_gateways"`
+	Findings    []types.Finding      `json:"findings"`
+	Inventory   []types.VPCInventory `json:"inventory"`
+}
+
+// RunQuickScanJSON runs a quick scan and writes its NAT Gateways, findings
+// and the inventory of their VPCs to w as JSON, with nothing else, and
+// returns its per-account summary row.
+func RunQuickScanJSON(ctx context.Context, scanner Scanner, w io.Writer) (AccountSummary, error) {
+	summary := AccountSummary{Region: scanner.GetRegion(), AccountID: scanner.GetAccountID()}
+	nats, err := discoverNATsForQuickScan(ctx, scanner)
+	if err != nil {
+		return summary, err
+	}
+	summary.NATGateways = len(nats)
+	findings, err := analyzeQuickFindings(ctx, scanner, nats)
+	if err != nil {
+		return summary, err
+	}
+	summary.Findings = len(findings)
+	inventory, err := scanner.Inventory(ctx, uniqueVPCIDs(nats))
+	if err != nil {
+		return summary, fmt.Errorf("failed to collect inventory: %w", err)
+	}
+
+	result := QuickScanResult{
+		Region:      scanner.GetRegion(),
+		AccountID:   scanner.GetAccountID(),
+		NATGateways: append([]types.NATGateway{}, nats...),
+		Findings:    append([]types.Finding{}, findings...),
+		Inventory:   append([]types.VPCInventory{}, inventory...),
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return summary, enc.Encode(result)
 }
 
 func quickLog(stage, format string, args ...any) {
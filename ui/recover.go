@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doitintl/terminator/internal/manifest"
+)
+
+// recoverUncleanRuns looks for manifests left behind by deep scans that died
+// before cleaning up and offers to remove their resources. Only runs in the
+// scanner's account and region can be cleaned; others are listed. With
+// autoApprove the orphaned Flow Logs are stopped and log groups are kept.
+func recoverUncleanRuns(ctx context.Context, scanner Scanner, store *manifest.Store, autoApprove bool,
+	confirm func(prompt string, defaultYes bool) (bool, error), logf func(format string, args ...any)) error {
+	manifests, err := store.List()
+	if err != nil {
+		logf("  ⚠️  could not check for unfinished scans: %v", err)
+		return nil
+	}
+
+	for _, m := range manifests {
+		if m.Running() {
+			continue
+		}
+		if m.AccountID != scanner.GetAccountID() || m.Region != scanner.GetRegion() {
+			logf("⚠️  Unfinished scan %s from %s in account %s, region %s; run a deep scan there to clean it up",
+				m.RunID, m.StartedAt.Local().Format(time.RFC1123), m.AccountID, m.Region)
+			continue
+		}
+		if err := recoverRun(ctx, scanner, store, m, autoApprove, confirm, logf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func recoverRun(ctx context.Context, scanner Scanner, store *manifest.Store, m *manifest.Manifest, autoApprove bool,
+	confirm func(prompt string, defaultYes bool) (bool, error), logf func(format string, args ...any)) error {
+	logf("⚠️  Previous scan %s (started %s) did not finish cleaning up", m.RunID, m.StartedAt.Local().Format(time.RFC1123))
+
+	if m.FirehoseStream != "" {
+		return recoverFirehoseRun(ctx, scanner, store, m, autoApprove, confirm, logf)
+	}
+
+	stop := true
+	if !autoApprove {
+		var err error
+		if stop, err = confirm(fmt.Sprintf("Stop its Flow Logs and remove log group %s?", m.LogGroupName), true); err != nil {
+			return err
+		}
+	}
+	if !stop {
+		logf("  Leaving it in place. Clean up later with: terminat cleanup --region %s --log-group %s", m.Region, m.LogGroupName)
+		return store.Remove(m.RunID)
+	}
+
+	if m.ProvisionVia == ProvisionCloudFormation {
+		// The stack owns the Flow Logs; its log group is retained on deletion
+		// and removed below.
+		if err := scanner.DeleteFlowLogsStack(ctx, m.RunID); err != nil {
+			return fmt.Errorf("failed to delete stack %s of unfinished scan: %w", m.RunID, err)
+		}
+		logf("  Deleted CloudFormation stack %s", m.RunID)
+	} else {
+		active, err := scanner.CheckActiveFlowLogs(ctx, m.LogGroupName)
+		if err != nil {
+			return fmt.Errorf("failed to find Flow Logs of unfinished scan %s: %w", m.RunID, err)
+		}
+		if err := scanner.DeleteFlowLogs(ctx, active); err != nil {
+			return fmt.Errorf("failed to stop Flow Logs of unfinished scan %s: %w", m.RunID, err)
+		}
+		logf("  Stopped %d Flow Log(s)", len(active))
+	}
+
+	if autoApprove {
+		logf("  Kept log group %s (delete with: terminat cleanup --region %s --log-group %s)", m.LogGroupName, m.Region, m.LogGroupName)
+	} else if err := scanner.DeleteLogGroup(ctx, m.LogGroupName); err != nil {
+		return fmt.Errorf("failed to delete log group of unfinished scan %s: %w", m.RunID, err)
+	} else {
+		logf("  Deleted log group %s", m.LogGroupName)
+	}
+	return store.Remove(m.RunID)
+}
+
+// recoverFirehoseRun stops the Flow Logs of an unfinished scan that delivered
+// to a Firehose stream. There is no log group; what the stream delivered
+// belongs to its own pipeline.
+func recoverFirehoseRun(ctx context.Context, scanner Scanner, store *manifest.Store, m *manifest.Manifest, autoApprove bool,
+	confirm func(prompt string, defaultYes bool) (bool, error), logf func(format string, args ...any)) error {
+	stop := true
+	if !autoApprove {
+		var err error
+		if stop, err = confirm(fmt.Sprintf("Stop its %d Flow Log(s) delivering to %s?", len(m.FlowLogIDs), m.FirehoseStream), true); err != nil {
+			return err
+		}
+	}
+	if !stop {
+		logf("  Leaving them in place. Stop them later with: aws ec2 delete-flow-logs --region %s --flow-log-ids %s", m.Region, strings.Join(m.FlowLogIDs, " "))
+		return store.Remove(m.RunID)
+	}
+	if err := scanner.DeleteFlowLogs(ctx, m.FlowLogIDs); err != nil {
+		return fmt.Errorf("failed to stop Flow Logs of unfinished scan %s: %w", m.RunID, err)
+	}
+	logf("  Stopped %d Flow Log(s)", len(m.FlowLogIDs))
+	return store.Remove(m.RunID)
+}
@@ -8,6 +8,9 @@ import (
 	"text/template"
 
 	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/internal/report"
+	"github.com/doitintl/terminator/internal/units"
 	"github.com/doitintl/terminator/pkg/types"
 )
 
@@ -24,7 +27,11 @@ var tmplFuncs = template.FuncMap{
 	"dim":       func(s string) string { return infoStyle.Render(s) },
 	"header":    sectionHeader,
 	"currency":  formatCurrency,
+	"bytes":     units.Format[int64],
+	"count":     units.Count[int],
+	"gb":        units.Number,
 	"upper":     strings.ToUpper,
+	"effort":    analysis.EffortLabel,
 	"hasPrefix": strings.HasPrefix,
 	"inc":       func(i int) int { return i + 1 },
 	"indent": func(cmd string) string {
@@ -47,32 +54,39 @@ func sectionHeader(title string) string {
 
 // reportData holds all data needed by the report template.
 type reportData struct {
+	Names            types.FriendlyNames
 	VPCNATs          map[string][]types.NATGateway
 	DeepScannedVPC   string
 	AllFindings      []types.Finding
 	EndpointAnalysis *analysis.EndpointAnalysis
 	TrafficStats     *analysis.TrafficStats
 	CostEstimate     *analysis.CostEstimate
+	ScanCost         *analysis.ScanCost
 	Recommendations  []analysis.Recommendation
 	Duration         int
+	Window           *report.CollectionWindow
 	LogGroupName     string
+	Headline         analysis.Headline
+	ScanCoverage     *analysis.ScanCoverage
+	Plan             analysis.RemediationPlan
+	VPCRemediation   []analysis.VPCRemediation
 
 	// Computed fields
-	HasTraffic                         bool
-	HasRemediation                     bool
-	HasInterfaceEndpoints              bool
-	MissingRoutes                      []analysis.MissingRoute
-	InterfaceEndpointCosts             []epCostDisplay
-	TotalInterfaceEndpointCost         float64
-	TotalTrafficGB                     float64
-	S3GB, DynamoGB, ECRGB, OtherGB     float64
-	S3Pct, DynamoPct, ECRPct, OtherPct float64
-	TopSourceIPs                       []sourceIPDisplay
-	MoreSources                        int
-	ECRCost                            float64
-	AnnualSavings                      float64
-	CreateEndpointCmds                 []string
-	AddRouteCmds                       []string
+	HasTraffic                       bool
+	HasRemediation                   bool
+	HasInterfaceEndpoints            bool
+	MissingRoutes                    []analysis.MissingRoute
+	InterfaceEndpointCosts           []epCostDisplay
+	TotalInterfaceEndpointCost       float64
+	ServiceRows                      []serviceDisplay
+	ExactPct, BroadPct, UnmatchedPct float64
+	ResolvedPct                      float64
+	TopSourceIPs                     []sourceIPDisplay
+	MoreSources                      int
+	ECRCost                          float64
+	AnnualSavings                    float64
+	CreateEndpointCmds               []string
+	AddRouteCmds                     []string
 }
 
 type epCostDisplay struct {
@@ -81,25 +95,78 @@ type epCostDisplay struct {
 	MonthlyCost float64
 }
 
+type serviceDisplay struct {
+	Name  string
+	Bytes int64
+	Pct   float64
+}
+
 type sourceIPDisplay struct {
 	IP      string
-	GB      float64
+	Bytes   int64
 	Records int
 }
 
+// rankedFindings attaches savings projections to a copy of findings and
+// orders it by prioritization score.
+func rankedFindings(findings []types.Finding, est *analysis.CostEstimate, byVPC map[string]*analysis.TrafficStats) []types.Finding {
+	out := append([]types.Finding(nil), findings...)
+	analysis.AttachFindingSavings(out, est, byVPC)
+	return analysis.RankFindings(out)
+}
+
+// trafficByVPC is the sampled traffic of each VPC: the sum of its NAT
+// Gateways' own queries, or all of it when every NAT Gateway is in one VPC.
+// It is nil when the traffic can't be split by VPC.
+func trafficByVPC(nats []types.NATGateway, perNAT []core.NATTraffic, total *analysis.TrafficStats) map[string]*analysis.TrafficStats {
+	vpcOf := make(map[string]string, len(nats))
+	vpcs := map[string]bool{}
+	for _, nat := range nats {
+		vpcOf[nat.ID] = nat.VPCID
+		vpcs[nat.VPCID] = true
+	}
+	if len(perNAT) == 0 {
+		if len(vpcs) != 1 || total == nil {
+			return nil
+		}
+		return map[string]*analysis.TrafficStats{nats[0].VPCID: total}
+	}
+	byVPC := map[string]*analysis.TrafficStats{}
+	for _, t := range perNAT {
+		if t.Err != nil || t.Stats == nil || vpcOf[t.NATID] == "" {
+			continue
+		}
+		vpc := vpcOf[t.NATID]
+		if byVPC[vpc] == nil {
+			byVPC[vpc] = &analysis.TrafficStats{SourceIPs: map[string]*analysis.SourceIPStats{}}
+		}
+		byVPC[vpc].Merge(t.Stats)
+	}
+	return byVPC
+}
+
 func (m *deepScanModel) buildReportData() reportData {
 	d := reportData{
+		Names:            m.names,
 		VPCNATs:          make(map[string][]types.NATGateway),
 		DeepScannedVPC:   m.deepScannedVPC,
-		AllFindings:      m.allFindings,
+		AllFindings:      rankedFindings(m.allFindings, m.costEstimate, trafficByVPC(m.nats, m.natTraffic, m.trafficStats)),
 		EndpointAnalysis: m.endpointAnalysis,
 		TrafficStats:     m.trafficStats,
 		CostEstimate:     m.costEstimate,
-		Recommendations:  m.recommendations,
+		ScanCost:         m.scanCost,
+		Recommendations:  analysis.RankRecommendations(m.recommendations),
 		Duration:         m.duration,
+		Window:           m.collectionWindow,
 		LogGroupName:     m.logGroupName,
+		ScanCoverage:     m.scanCoverage,
+		VPCRemediation:   m.vpcRemediation,
 	}
 
+	d.Headline = analysis.BuildHeadline(d.CostEstimate, d.TrafficStats, d.AllFindings, d.Recommendations, d.Duration)
+	d.Plan = analysis.PlanRemediation(d.AllFindings, d.Recommendations)
+	d.HasRemediation = len(d.VPCRemediation) > 0
+
 	for _, nat := range m.nats {
 		d.VPCNATs[nat.VPCID] = append(d.VPCNATs[nat.VPCID], nat)
 	}
@@ -107,8 +174,8 @@ func (m *deepScanModel) buildReportData() reportData {
 	if m.endpointAnalysis != nil {
 		d.MissingRoutes = m.endpointAnalysis.MissingRoutes
 		d.HasInterfaceEndpoints = m.endpointAnalysis.HasInterfaceEndpoints()
-		d.HasRemediation = m.endpointAnalysis.HasIssues()
-		if d.HasRemediation {
+		if m.endpointAnalysis.HasIssues() {
+			d.HasRemediation = true
 			d.CreateEndpointCmds = m.endpointAnalysis.GetCreateEndpointCommands()
 			d.AddRouteCmds = m.endpointAnalysis.GetAddRouteCommands()
 		}
@@ -130,21 +197,21 @@ func (m *deepScanModel) buildReportData() reportData {
 
 	if m.trafficStats != nil && m.trafficStats.TotalRecords > 0 {
 		d.HasTraffic = true
-		d.TotalTrafficGB = float64(m.trafficStats.TotalBytes) / (1024 * 1024 * 1024)
-		d.S3GB = float64(m.trafficStats.S3Bytes) / (1024 * 1024 * 1024)
-		d.DynamoGB = float64(m.trafficStats.DynamoBytes) / (1024 * 1024 * 1024)
-		d.ECRGB = float64(m.trafficStats.ECRBytes) / (1024 * 1024 * 1024)
-		d.OtherGB = float64(m.trafficStats.OtherBytes) / (1024 * 1024 * 1024)
-		d.S3Pct = m.trafficStats.S3Percentage()
-		d.DynamoPct = m.trafficStats.DynamoPercentage()
-		d.ECRPct = m.trafficStats.ECRPercentage()
-		d.OtherPct = m.trafficStats.OtherPercentage()
+		for _, service := range m.trafficStats.ServiceOrder() {
+			d.ServiceRows = append(d.ServiceRows, serviceDisplay{
+				Name:  service.Name(),
+				Bytes: m.trafficStats.Bytes(service),
+				Pct:   m.trafficStats.Percentage(service),
+			})
+		}
+		d.ExactPct, d.BroadPct, d.UnmatchedPct = m.trafficStats.AccuracyPercentages()
+		d.ResolvedPct = m.trafficStats.ResolvedPercentage()
 
 		top := m.trafficStats.TopSourceIPs(10)
 		for _, e := range top {
 			d.TopSourceIPs = append(d.TopSourceIPs, sourceIPDisplay{
 				IP:      e.IP,
-				GB:      float64(e.Stats.Bytes) / (1024 * 1024 * 1024),
+				Bytes:   e.Stats.Bytes,
 				Records: e.Stats.Records,
 			})
 		}
@@ -155,8 +222,8 @@ func (m *deepScanModel) buildReportData() reportData {
 
 	if m.costEstimate != nil {
 		d.AnnualSavings = m.costEstimate.TotalSavingsMonthly * 12
-		if m.trafficStats != nil && m.trafficStats.ECRBytes > 0 && m.costEstimate.OtherPercentage() > 0 {
-			d.ECRCost = m.costEstimate.OtherDataGB * m.costEstimate.NATGatewayPricePerGB * (m.trafficStats.ECRPercentage() / m.costEstimate.OtherPercentage())
+		if m.trafficStats != nil && m.trafficStats.Bytes(analysis.ServiceECR) > 0 && m.costEstimate.OtherPercentage() > 0 {
+			d.ECRCost = m.costEstimate.OtherDataGB * m.costEstimate.NATGatewayPricePerGB * (m.trafficStats.Percentage(analysis.ServiceECR) / m.costEstimate.OtherPercentage())
 		}
 	}
 
@@ -179,6 +246,9 @@ func (m *deepScanModel) renderReportBody() string {
 func (m *deepScanModel) renderFooter() string {
 	var b strings.Builder
 	b.WriteString("  [M] Markdown  [J] JSON  [D] DoiT DataHub  [↑↓] Scroll  [Enter] Exit\n")
+	if m.cleanupMsg != "" {
+		b.WriteString(fmt.Sprintf("  %s\n", m.cleanupMsg))
+	}
 	if m.exportMsg != "" {
 		b.WriteString(fmt.Sprintf("  %s\n", m.exportMsg))
 	}
@@ -191,9 +261,19 @@ func (m *deepScanModel) renderFooter() string {
 	case 2:
 		b.WriteString(fmt.Sprintf("  Customer context (optional, Enter to skip): %s█\n", m.datahubInputBuf))
 	case 3:
-		b.WriteString("  Save API key for future use? [Y/n] ")
+		b.WriteString("  Save API key to the OS keyring for future use? [Y/n] ")
 	case 4:
 		b.WriteString("  ⏳ Sending to DoiT DataHub...\n")
 	}
 	return b.String()
 }
+
+// reportMethodology describes how an exported report's figures were derived,
+// from the queries scanner ran.
+func reportMethodology(scanner TrafficAnalyzer, region string, duration int, cost *analysis.CostEstimate) *analysis.Methodology {
+	var queries []string
+	for _, q := range scanner.QueryRecords() {
+		queries = append(queries, q.Query)
+	}
+	return analysis.NewMethodology(region, duration, cost, queries)
+}
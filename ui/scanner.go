@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// Scanner is what the scan flows need from core.Scanner, so they can run
+// against fakes in tests.
+type Scanner interface {
+	Discoverer
+	FlowLogManager
+	TrafficAnalyzer
+}
+
+// Discoverer reads the account's network configuration.
+type Discoverer interface {
+	GetAccountID() string
+	GetRegion() string
+	DiscoverNATGateways(ctx context.Context) ([]types.NATGateway, error)
+	DiscoverVPCEndpoints(ctx context.Context, vpcID string) ([]types.VPCEndpoint, error)
+	DiscoverRouteTables(ctx context.Context, vpcID string) ([]types.RouteTable, error)
+	DiscoverSubnets(ctx context.Context, vpcID string) ([]types.Subnet, error)
+	DiscoverEKSClusters(ctx context.Context, vpcID string) ([]string, error)
+	CountSSMManagedInstances(ctx context.Context, vpcID string) (int, error)
+	Inventory(ctx context.Context, vpcIDs []string) ([]types.VPCInventory, error)
+	SubnetEgress(ctx context.Context, subnetID string) (*types.SubnetEgress, error)
+	ENIEgress(ctx context.Context, eniID string) (*types.SubnetEgress, error)
+	AnalyzeVPCEndpoints(ctx context.Context, vpcID string) (*analysis.EndpointAnalysis, error)
+	CheckEndpointQuotas(ctx context.Context, endpoints *analysis.EndpointAnalysis) error
+	PlaceEndpoints(ctx context.Context, endpoints *analysis.EndpointAnalysis, activeAZs []string) error
+	FriendlyNames(ctx context.Context, vpcIDs []string) types.FriendlyNames
+}
+
+// FlowLogManager creates and removes the temporary Flow Logs and their
+// destinations.
+type FlowLogManager interface {
+	ValidateFlowLogsRole(ctx context.Context, roleARN string) error
+	EstimateFlowLogsCost(ctx context.Context, natIDs []string, durationMinutes int) (estimatedGB float64, estimatedCost float64, err error)
+	CreateLogGroup(ctx context.Context, logGroupName string) error
+	DeleteLogGroup(ctx context.Context, logGroupName string) error
+	ScheduleLogGroupDeletion(ctx context.Context, logGroupName string, after time.Duration) (time.Time, error)
+	SetLogGroupExpiry(ctx context.Context, logGroupName string, at time.Time) error
+	FindExpiredResources(ctx context.Context, now time.Time) (*core.ExpiredResources, error)
+	DeleteExpiredResources(ctx context.Context, expired *core.ExpiredResources) (skipped []string, err error)
+	CreateFlowLogs(ctx context.Context, nat types.NATGateway, destination string, deliveryRoleArn string, runID string) (string, error)
+	CreateSubnetFlowLogs(ctx context.Context, subnetID string, destination string, deliveryRoleArn string, runID string) (string, error)
+	CreateENIFlowLogs(ctx context.Context, eniID string, destination string, deliveryRoleArn string, runID string) (string, error)
+	DeleteFlowLogs(ctx context.Context, flowLogIDs []string) error
+	ActiveFlowLogs(ctx context.Context, flowLogIDs []string) ([]string, error)
+	CheckActiveFlowLogs(ctx context.Context, logGroupName string) ([]string, error)
+	CreateFlowLogsStack(ctx context.Context, nats []types.NATGateway, logGroupName, deliveryRoleArn, runID string) ([]string, error)
+	DeleteFlowLogsStack(ctx context.Context, runID string) error
+}
+
+// TrafficAnalyzer queries the collected Flow Logs, and the logs and billing
+// data around them, and prices the traffic.
+type TrafficAnalyzer interface {
+	AnalyzeTrafficPerNAT(ctx context.Context, logGroupName string, nats []types.NATGateway, startTime, endTime int64) (*analysis.TrafficStats, []core.NATTraffic, error)
+	AnalyzeSubnetTraffic(ctx context.Context, logGroupName string, subnet *types.SubnetEgress, startTime, endTime int64) (*analysis.TrafficStats, error)
+	AnalyzeLogGroupWindow(ctx context.Context, logGroupName string, startTime, endTime int64, nats []types.NATGateway) (*analysis.TrafficStats, error)
+	FirehoseObjectKeys(ctx context.Context, bucket, prefix string, from, to time.Time) ([]string, error)
+	AnalyzeFirehoseFlowLogs(ctx context.Context, bucket string, keys, interfaces []string, subnet *types.SubnetEgress, startTime, endTime int64) (*analysis.TrafficStats, error)
+	AnalyzeS3FlowLogsDay(ctx context.Context, bucket, prefix string, day time.Time) (*analysis.TrafficStats, error)
+	AttributeS3Traffic(ctx context.Context, trailLogGroup string, nats []types.NATGateway, startTime, endTime int64) ([]analysis.S3Attribution, error)
+	LookupDynamoDBEndpoints(ctx context.Context, resolverLogGroup string, startTime, endTime int64) ([]analysis.DynamoDBEndpoint, error)
+	SampleCoverage(ctx context.Context, logGroupName string, startTime, endTime int64, created bool) (*analysis.SampleCoverage, error)
+	CheckClassification(ctx context.Context, logGroupName string, startTime, endTime int64) (*analysis.ClassificationCheck, error)
+	AnalyzeRejectedTraffic(ctx context.Context, logGroupName string, startTime, endTime int64) (*analysis.RejectedTraffic, error)
+	NATTrafficBySource(ctx context.Context, logGroupName string, startTime, endTime int64) ([]analysis.SourceTraffic, error)
+	QueryBytesScanned() float64
+	QueryRecords() []core.QueryRecord
+	CalculateCosts(stats *analysis.TrafficStats, collectionMinutes int) *analysis.CostEstimate
+	GetNATProcessedBytes(ctx context.Context, natIDs []string, window time.Duration) (float64, error)
+	CostAnomalyMonitor(ctx context.Context) (string, error)
+	NATCostAnomalies(ctx context.Context, monitorARN string, start time.Time) ([]types.CostAnomaly, error)
+}
+
+var _ Scanner = (*core.Scanner)(nil)
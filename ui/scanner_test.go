@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/manifest"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// fakeScanner implements Scanner for tests. Methods a test does not set up
+// panic through the nil embedded interface.
+type fakeScanner struct {
+	Scanner
+	endpoints   map[string][]types.VPCEndpoint
+	routeTables map[string][]types.RouteTable
+	activeLogs  []string
+	calls       []string
+}
+
+func (f *fakeScanner) GetAccountID() string { return "123456789012" }
+func (f *fakeScanner) GetRegion() string    { return "us-east-1" }
+
+func (f *fakeScanner) CalculateCosts(stats *analysis.TrafficStats, collectionMinutes int) *analysis.CostEstimate {
+	return &analysis.CostEstimate{}
+}
+
+func (f *fakeScanner) DiscoverVPCEndpoints(ctx context.Context, vpcID string) ([]types.VPCEndpoint, error) {
+	return f.endpoints[vpcID], nil
+}
+
+func (f *fakeScanner) DiscoverRouteTables(ctx context.Context, vpcID string) ([]types.RouteTable, error) {
+	return f.routeTables[vpcID], nil
+}
+
+func (f *fakeScanner) CheckActiveFlowLogs(ctx context.Context, logGroupName string) ([]string, error) {
+	f.calls = append(f.calls, "CheckActiveFlowLogs "+logGroupName)
+	return f.activeLogs, nil
+}
+
+func (f *fakeScanner) DeleteFlowLogs(ctx context.Context, flowLogIDs []string) error {
+	f.calls = append(f.calls, "DeleteFlowLogs "+strings.Join(flowLogIDs, ","))
+	return nil
+}
+
+func (f *fakeScanner) DeleteLogGroup(ctx context.Context, logGroupName string) error {
+	f.calls = append(f.calls, "DeleteLogGroup "+logGroupName)
+	return nil
+}
+
+func (f *fakeScanner) SetLogGroupExpiry(ctx context.Context, logGroupName string, at time.Time) error {
+	f.calls = append(f.calls, "SetLogGroupExpiry "+logGroupName+" "+expiryString(at))
+	return nil
+}
+
+func expiryString(at time.Time) string {
+	if at.IsZero() {
+		return "never"
+	}
+	return at.UTC().Format(time.RFC3339)
+}
+
+func TestAnalyzeQuickFindingsMissingEndpoints(t *testing.T) {
+	scanner := &fakeScanner{}
+	nats := []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1"}}
+
+	findings, err := analyzeQuickFindings(context.Background(), scanner, nats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	services := map[string]bool{}
+	for _, f := range findings {
+		if f.Type == "missing-endpoint" && f.VPCID == "vpc-1" {
+			services[f.Service] = true
+		}
+	}
+	if !services["S3"] || !services["DynamoDB"] {
+		t.Fatalf("want missing S3 and DynamoDB endpoints, got %+v", findings)
+	}
+}
+
+// inventoryScanner is a fakeScanner with NAT Gateways and an inventory.
+type inventoryScanner struct {
+	fakeScanner
+	nats []types.NATGateway
+}
+
+func (f *inventoryScanner) DiscoverNATGateways(ctx context.Context) ([]types.NATGateway, error) {
+	return f.nats, nil
+}
+
+func (f *inventoryScanner) Inventory(ctx context.Context, vpcIDs []string) ([]types.VPCInventory, error) {
+	var inv []types.VPCInventory
+	for _, id := range vpcIDs {
+		inv = append(inv, types.VPCInventory{VPCID: id})
+	}
+	return inv, nil
+}
+
+func TestRunQuickScanJSON(t *testing.T) {
+	scanner := &inventoryScanner{nats: []types.NATGateway{{ID: "nat-1", VPCID: "vpc-1"}}}
+	var out bytes.Buffer
+	summary, err := RunQuickScanJSON(context.Background(), scanner, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result QuickScanResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("output is not a JSON document: %v\n%s", err, out.String())
+	}
+	if result.AccountID != "123456789012" || len(result.NATGateways) != 1 || len(result.Inventory) != 1 || result.Inventory[0].VPCID != "vpc-1" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(result.Findings) == 0 || summary.Findings != len(result.Findings) {
+		t.Errorf("findings = %d, summary = %d; want the missing endpoints in both", len(result.Findings), summary.Findings)
+	}
+
+	out.Reset()
+	scanner.nats = nil
+	if _, err := RunQuickScanJSON(context.Background(), scanner, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"findings": []`) || !strings.Contains(out.String(), `"inventory": []`) {
+		t.Errorf("want empty lists rather than null:\n%s", out.String())
+	}
+}
+
+func TestRecoverUncleanRuns(t *testing.T) {
+	store := manifest.NewStore(t.TempDir())
+	here := manifest.New("terminat-1", "123456789012", "us-east-1", "/terminat/1", ProvisionDirect)
+	elsewhere := manifest.New("terminat-2", "123456789012", "eu-west-1", "/terminat/2", ProvisionDirect)
+	for _, m := range []*manifest.Manifest{here, elsewhere} {
+		if err := store.Save(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scanner := &fakeScanner{activeLogs: []string{"fl-1", "fl-2"}}
+	confirm := func(string, bool) (bool, error) { return true, nil }
+	var logged []string
+	logf := func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }
+
+	if err := recoverUncleanRuns(context.Background(), scanner, store, false, confirm, logf); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"CheckActiveFlowLogs /terminat/1", "DeleteFlowLogs fl-1,fl-2", "DeleteLogGroup /terminat/1"}
+	if strings.Join(scanner.calls, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("calls = %v, want %v", scanner.calls, want)
+	}
+
+	left, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 1 || left[0].RunID != "terminat-2" {
+		t.Fatalf("manifests left = %+v, want only the other region's", left)
+	}
+	if !strings.Contains(strings.Join(logged, "\n"), "region eu-west-1") {
+		t.Fatalf("other region's run not listed: %v", logged)
+	}
+}
@@ -3,8 +3,11 @@ package ui
 import (
 	"bufio"
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/doitintl/terminator/pkg/types"
 )
@@ -20,7 +23,7 @@ func TestRunQuickScanInvalidUIMode(t *testing.T) {
 }
 
 func TestRunDeepScanInvalidUIMode(t *testing.T) {
-	err := RunDeepScan(context.Background(), nil, "us-east-1", 5, nil, "", "invalid", false, false, "", "", "", "")
+	err := RunDeepScan(context.Background(), nil, DeepScanOptions{Region: "us-east-1", Duration: 5, UIMode: "invalid"})
 	if err == nil {
 		t.Fatal("expected invalid UI mode error")
 	}
@@ -39,6 +42,18 @@ func TestRunDemoScanInvalidUIMode(t *testing.T) {
 	}
 }
 
+func TestCollectTrafficStopsAtRunTimeout(t *testing.T) {
+	timeout := errors.New("--timeout reached after 1ms")
+	ctx, cancel := context.WithTimeoutCause(context.Background(), time.Millisecond, timeout)
+	defer cancel()
+	r := &streamDeepScanRunner{ctx: ctx, duration: 60}
+
+	err := r.collectTraffic()
+	if !errors.Is(err, timeout) {
+		t.Fatalf("collectTraffic = %v, want the timeout cause", err)
+	}
+}
+
 func TestPromptNATSelectionAllByDefault(t *testing.T) {
 	r := &streamDeepScanRunner{
 		nats: []types.NATGateway{
@@ -93,3 +108,32 @@ func TestPromptNATSelectionInvalidIndex(t *testing.T) {
 		t.Fatal("expected invalid index error")
 	}
 }
+
+func TestFinalSummarySectionOffsets(t *testing.T) {
+	r := &streamDeepScanRunner{
+		scanner:     &fakeScanner{},
+		nats:        []types.NATGateway{{ID: "nat-a", VPCID: "vpc-1"}},
+		names:       types.FriendlyNames{AccountAlias: "payments-prod", VPCNames: map[string]string{"vpc-1": "main"}},
+		allFindings: []types.Finding{{Severity: "high", Title: "Missing S3 Gateway Endpoint"}},
+	}
+	r.renderFinalSummary()
+
+	for _, want := range []string{"Account: payments-prod (123456789012)", "nat-a (zonal, vpc=vpc-1 (main))"} {
+		if !strings.Contains(r.lastReport, want) {
+			t.Errorf("report lacks %q:\n%s", want, r.lastReport)
+		}
+	}
+
+	if len(r.reportSections) < 3 {
+		t.Fatalf("expected numbered sections, got %+v", r.reportSections)
+	}
+	for i, sec := range r.reportSections {
+		marker := fmt.Sprintf("== [%d] %s ==", i+1, sec.Title)
+		if !strings.HasPrefix(r.lastReport[sec.Offset:], marker) {
+			t.Errorf("offset %d for %q does not point at its marker", sec.Offset, sec.Title)
+		}
+	}
+	if !strings.Contains(r.lastReport, "Report sections (byte offsets): [1] Headline @") {
+		t.Errorf("expected a table of contents line at the end:\n%s", r.lastReport)
+	}
+}
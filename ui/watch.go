@@ -0,0 +1,261 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/notify"
+	"github.com/doitintl/terminator/internal/units"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// WatchOptions configures `terminat watch`.
+type WatchOptions struct {
+	Region   string
+	Interval time.Duration
+	// Window is the trailing CloudWatch metrics window checked each interval.
+	Window time.Duration
+	// MaxGB is the per-NAT data processed in Window above which to alert (0 = off).
+	MaxGB float64
+	// MaxMonthlyCost is the per-NAT projected monthly data processing cost in USD above which to alert (0 = off).
+	MaxMonthlyCost float64
+	// AlertTargets receive trigger and resolve events; they must implement notify.Alerter.
+	AlertTargets []notify.Target
+	// QuickScanEvery runs a quick scan this often (0 = off) and sends
+	// NotifyTargets only the findings that appeared or were resolved since
+	// the previous one.
+	QuickScanEvery time.Duration
+	NotifyTargets  []notify.Target
+	// SampleMinutes enables Flow Logs on every NAT Gateway for this many
+	// minutes a day (0 = off), at an hour that rotates from day to day, and
+	// projects the month from the samples of the last five weeks.
+	SampleMinutes int
+	// DeleteExpired deletes, every interval, the Flow Logs and log groups
+	// termiNATor created whose ExpiresAt tag has passed.
+	DeleteExpired bool
+	// NewRunID names each sample's resources; nil uses the default naming.
+	NewRunID func() string
+	// Once runs a single check, for cron jobs. Nothing is remembered between
+	// runs, so recoveries aren't resolved; the dedup key keeps repeat
+	// triggers on one incident.
+	Once bool
+}
+
+// natUsage is one NAT Gateway's traffic over the watch window.
+type natUsage struct {
+	NAT         types.NATGateway
+	GB          float64
+	MonthlyCost float64
+}
+
+// RunWatch polls NAT Gateway CloudWatch metrics and raises an alert when a
+// NAT crosses a threshold, resolving it once traffic falls back, and runs
+// the periodic quick scans, daily samples and expiry sweeps. Only the
+// samples create anything in the account, and only while they run.
+func RunWatch(ctx context.Context, scanner Scanner, opts WatchOptions) error {
+	thresholds := opts.MaxGB > 0 || opts.MaxMonthlyCost > 0
+	if !thresholds && opts.QuickScanEvery <= 0 && opts.SampleMinutes <= 0 && !opts.DeleteExpired {
+		return fmt.Errorf("set --max-gb, --max-monthly-cost, --quick-scan-every, --sample-minutes and/or --delete-expired")
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if thresholds {
+		logWatch("watch", "Watching NAT Gateways in %s every %s (window %s, %s)", opts.Region, watchDuration(opts.Interval), watchDuration(opts.Window), describeThresholds(opts))
+	}
+	if opts.QuickScanEvery > 0 {
+		logWatch("watch", "Quick scan every %s, notifying on finding changes", watchDuration(opts.QuickScanEvery))
+	}
+	if opts.SampleMinutes > 0 {
+		logWatch("watch", "Sampling Flow Logs for %d minute(s) a day; today's sample is due at %s UTC", opts.SampleMinutes, sampleSlot(time.Now()).Format("15:04"))
+	}
+	if opts.DeleteExpired {
+		logWatch("watch", "Deleting expired termiNATor Flow Logs and log groups every %s", watchDuration(opts.Interval))
+	}
+	active := map[string]bool{}
+	for {
+		var err error
+		if thresholds {
+			err = watchOnce(ctx, scanner, opts, active)
+		}
+		if opts.QuickScanEvery > 0 {
+			if scanErr := watchFindings(ctx, scanner, opts, time.Now()); scanErr != nil {
+				err = errors.Join(err, scanErr)
+			}
+		}
+		if opts.SampleMinutes > 0 {
+			if sampleErr := watchSampling(ctx, scanner, opts, time.Now()); sampleErr != nil {
+				err = errors.Join(err, sampleErr)
+			}
+		}
+		if opts.DeleteExpired {
+			if sweepErr := deleteExpired(ctx, scanner, time.Now()); sweepErr != nil {
+				err = errors.Join(err, sweepErr)
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if opts.Once {
+				return err
+			}
+			logWatch("watch", "Check failed: %v", err)
+		}
+		if opts.Once {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			logWatch("watch", "Stopped")
+			return nil
+		case <-time.After(opts.Interval):
+		}
+	}
+}
+
+// watchOnce checks every NAT and sends trigger/resolve events for those whose
+// breach state changed. active tracks open alerts by dedup key.
+func watchOnce(ctx context.Context, scanner Scanner, opts WatchOptions, active map[string]bool) error {
+	nats, err := scanner.DiscoverNATGateways(ctx)
+	if err != nil {
+		return err
+	}
+	pricePerGB := analysis.NATGatewayPricePerGB(opts.Region)
+	for _, nat := range nats {
+		processed, err := scanner.GetNATProcessedBytes(ctx, []string{nat.ID}, opts.Window)
+		if err != nil {
+			return err
+		}
+		usage := natUsage{NAT: nat, GB: units.BillingGB(processed)}
+		usage.MonthlyCost = usage.GB * (30 * 24 * time.Hour).Hours() / opts.Window.Hours() * pricePerGB
+
+		key := "terminat-watch-" + scanner.GetAccountID() + "-" + nat.ID
+		breaches := checkThresholds(usage, opts)
+		switch {
+		case len(breaches) > 0 && !active[key]:
+			logWatch("alert", "%s over threshold: %s", nat.Label(), strings.Join(breaches, "; "))
+			if err := notify.SendAlert(ctx, opts.AlertTargets, watchAlert(key, scanner.GetAccountID(), opts, usage, breaches)); err != nil {
+				logWatch("alert", "Alert delivery failed: %v", err)
+				continue
+			}
+			active[key] = true
+		case len(breaches) == 0 && active[key]:
+			logWatch("alert", "%s back under threshold (%s GB, ~%s/month)", nat.Label(), units.Number(usage.GB), formatCurrency(usage.MonthlyCost))
+			if err := notify.SendAlert(ctx, opts.AlertTargets, notify.Alert{DedupKey: key, Source: "terminat", Resolved: true}); err != nil {
+				logWatch("alert", "Resolve delivery failed: %v", err)
+				continue
+			}
+			delete(active, key)
+		default:
+			logWatch("check", "%s: %s GB in %s, ~%s/month projected", nat.Label(), units.Number(usage.GB), watchDuration(opts.Window), formatCurrency(usage.MonthlyCost))
+		}
+	}
+	return nil
+}
+
+// deleteExpired deletes the termiNATor resources whose ExpiresAt tag is at
+// or before now, logging what it removes.
+func deleteExpired(ctx context.Context, scanner Scanner, now time.Time) error {
+	expired, err := scanner.FindExpiredResources(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to find expired resources: %w", err)
+	}
+	if expired.Empty() {
+		return nil
+	}
+	for _, id := range expired.FlowLogIDs {
+		logWatch("expiry", "Deleting expired Flow Log %s", id)
+	}
+	for _, stack := range expired.Stacks {
+		logWatch("expiry", "Deleting expired stack %s", stack)
+	}
+	for _, name := range expired.LogGroups {
+		logWatch("expiry", "Deleting expired log group %s", name)
+	}
+	skipped, err := scanner.DeleteExpiredResources(ctx, expired)
+	for _, name := range skipped {
+		logWatch("expiry", "Kept expired log group %s: Flow Logs still write to it", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete expired resources: %w", err)
+	}
+	return nil
+}
+
+// checkThresholds describes each threshold usage exceeds.
+func checkThresholds(usage natUsage, opts WatchOptions) []string {
+	var breaches []string
+	if opts.MaxGB > 0 && usage.GB > opts.MaxGB {
+		breaches = append(breaches, fmt.Sprintf("%s GB processed in %s (limit %s GB)", units.Number(usage.GB), watchDuration(opts.Window), units.Number(opts.MaxGB)))
+	}
+	if opts.MaxMonthlyCost > 0 && usage.MonthlyCost > opts.MaxMonthlyCost {
+		breaches = append(breaches, fmt.Sprintf("~%s/month projected data processing (limit %s)", formatCurrency(usage.MonthlyCost), formatCurrency(opts.MaxMonthlyCost)))
+	}
+	return breaches
+}
+
+// watchAlert describes a breaching NAT. Metrics can't split traffic by
+// service, so the details point at the deep scan that can.
+func watchAlert(key, accountID string, opts WatchOptions, usage natUsage, breaches []string) notify.Alert {
+	details := map[string]string{
+		"account_id":           accountID,
+		"region":               opts.Region,
+		"nat_gateway_id":       usage.NAT.ID,
+		"vpc_id":               usage.NAT.VPCID,
+		"availability_zone":    usage.NAT.AvailabilityZone,
+		"window":               watchDuration(opts.Window),
+		"processed_gb":         fmt.Sprintf("%.2f", usage.GB),
+		"projected_monthly":    fmt.Sprintf("%.2f", usage.MonthlyCost),
+		"breaches":             strings.Join(breaches, "; "),
+		"service_breakdown_by": fmt.Sprintf("terminat scan deep --region %s --nat-gateway-ids %s", opts.Region, usage.NAT.ID),
+	}
+	if name := usage.NAT.Name(); name != "" {
+		details["nat_gateway_name"] = name
+	}
+	severity := "warning"
+	if len(breaches) > 1 {
+		severity = "error"
+	}
+	return notify.Alert{
+		DedupKey: key,
+		Summary:  fmt.Sprintf("NAT Gateway %s in %s: %s", usage.NAT.Label(), opts.Region, breaches[0]),
+		Severity: severity,
+		Source:   "terminat",
+		Details:  details,
+	}
+}
+
+func describeThresholds(opts WatchOptions) string {
+	var parts []string
+	if opts.MaxGB > 0 {
+		parts = append(parts, fmt.Sprintf("max %.2f GB per NAT", opts.MaxGB))
+	}
+	if opts.MaxMonthlyCost > 0 {
+		parts = append(parts, fmt.Sprintf("max %s/month per NAT", formatCurrency(opts.MaxMonthlyCost)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// watchDuration prints whole hours and minutes compactly: 1h, 30m, 1h30m.
+func watchDuration(d time.Duration) string {
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func logWatch(stage, format string, args ...any) {
+	fmt.Printf("[%s] %-8s %s\n", time.Now().Format("15:04:05"), stage, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/doitintl/terminator/internal/manifest"
+	"github.com/doitintl/terminator/internal/notify"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+// watchState is what watch keeps between checks, restarts and --once runs:
+// when the last quick scan ran and what it found, and the daily samples.
+type watchState struct {
+	LastQuickScan time.Time       `json:"last_quick_scan"`
+	Findings      []types.Finding `json:"findings"`
+	Samples       []watchSample   `json:"samples,omitempty"`
+}
+
+// watchStatePath is watch/<account>-<region>.json under $TERMINAT_STATE_DIR
+// or <user config dir>/terminat.
+func watchStatePath(accountID, region string) (string, error) {
+	dir := os.Getenv(manifest.StateDirEnv)
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("no directory for watch state: %w", err)
+		}
+		dir = filepath.Join(base, "terminat")
+	}
+	return filepath.Join(dir, "watch", accountID+"-"+region+".json"), nil
+}
+
+// loadWatchState reads the state file; a missing file is an empty state.
+func loadWatchState(path string) (*watchState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &watchState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s watchState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s is not a termiNATor watch state: %w", path, err)
+	}
+	return &s, nil
+}
+
+func (s *watchState) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// watchFindingKey identifies a finding across quick scans. The action names
+// the route tables involved, so an association removed from one more route
+// table reads as a new finding replacing the old one.
+func watchFindingKey(f types.Finding) string {
+	return f.Type + "|" + f.VPCID + "|" + f.Service + "|" + f.Action
+}
+
+// diffFindings returns the findings in after but not before, and those in
+// before but not after.
+func diffFindings(before, after []types.Finding) (added, resolved []types.Finding) {
+	seen := make(map[string]bool, len(before))
+	for _, f := range before {
+		seen[watchFindingKey(f)] = true
+	}
+	current := make(map[string]bool, len(after))
+	for _, f := range after {
+		current[watchFindingKey(f)] = true
+		if !seen[watchFindingKey(f)] {
+			added = append(added, f)
+		}
+	}
+	for _, f := range before {
+		if !current[watchFindingKey(f)] {
+			resolved = append(resolved, f)
+		}
+	}
+	return added, resolved
+}
+
+// findingChanges describes added and resolved findings one line each.
+func findingChanges(added, resolved []types.Finding) *notify.FindingChanges {
+	line := func(f types.Finding) string {
+		return fmt.Sprintf("[%s] %s in %s: %s", f.Severity, f.Title, f.VPCID, f.Action)
+	}
+	c := &notify.FindingChanges{}
+	for _, f := range added {
+		c.New = append(c.New, line(f))
+	}
+	for _, f := range resolved {
+		c.Resolved = append(c.Resolved, line(f))
+	}
+	sort.Strings(c.New)
+	sort.Strings(c.Resolved)
+	return c
+}
+
+// watchFindings runs a quick scan once QuickScanEvery has passed since the
+// last one and notifies the targets only when its findings differ from the
+// last scan's. The first scan only records the findings. State is saved
+// after delivery, so a failed notification is retried at the next check.
+func watchFindings(ctx context.Context, scanner Scanner, opts WatchOptions, now time.Time) error {
+	path, err := watchStatePath(scanner.GetAccountID(), opts.Region)
+	if err != nil {
+		return err
+	}
+	state, err := loadWatchState(path)
+	if err != nil {
+		return err
+	}
+	if !state.LastQuickScan.IsZero() && now.Sub(state.LastQuickScan) < opts.QuickScanEvery {
+		return nil
+	}
+
+	nats, err := discoverNATsForQuickScan(ctx, scanner)
+	if err != nil {
+		return err
+	}
+	findings, err := analyzeQuickFindings(ctx, scanner, nats)
+	if err != nil {
+		return err
+	}
+
+	first := state.LastQuickScan.IsZero()
+	added, resolved := diffFindings(state.Findings, findings)
+	switch {
+	case first:
+		logWatch("scan", "Quick scan: %d finding(s) recorded; later scans notify on changes", len(findings))
+	case len(added) == 0 && len(resolved) == 0:
+		logWatch("scan", "Quick scan: %d finding(s), unchanged", len(findings))
+	default:
+		logWatch("scan", "Quick scan: %d new, %d resolved finding(s)", len(added), len(resolved))
+		s := notify.Summary{AccountID: scanner.GetAccountID(), Region: opts.Region, Changes: findingChanges(added, resolved)}
+		if err := notify.Send(ctx, opts.NotifyTargets, s); err != nil {
+			return fmt.Errorf("change notification failed, retrying at the next check: %w", err)
+		}
+	}
+
+	state.LastQuickScan, state.Findings = now, findings
+	return state.save(path)
+}
@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/manifest"
+	"github.com/doitintl/terminator/internal/naming"
+	"github.com/doitintl/terminator/internal/units"
+)
+
+// sampleHistory is how long daily samples count towards the monthly picture.
+const sampleHistory = 35 * 24 * time.Hour
+
+// sampleHourStep is how many hours later each day's sample starts than the
+// previous day's. It shares no factor with 24, so 24 consecutive days sample
+// every hour of the day once.
+const sampleHourStep = 7
+
+// watchSample is one daily Flow Logs sample: its window and what the NAT
+// Gateways carried in it.
+type watchSample struct {
+	Start       time.Time `json:"start"`
+	Minutes     int       `json:"minutes"`
+	Records     int       `json:"records"`
+	TotalBytes  int64     `json:"total_bytes"`
+	S3Bytes     int64     `json:"s3_bytes"`
+	DynamoBytes int64     `json:"dynamo_bytes"`
+}
+
+// sampleSlot is when the sample of now's UTC day is due.
+func sampleSlot(now time.Time) time.Time {
+	day := now.UTC().Truncate(24 * time.Hour)
+	n := day.Unix() / int64(24*time.Hour/time.Second)
+	return day.Add(time.Duration(n*sampleHourStep%24) * time.Hour)
+}
+
+// sampledOn reports whether a sample was already taken on now's UTC day.
+func (s *watchState) sampledOn(now time.Time) bool {
+	today := now.UTC().Format("2006-01-02")
+	for _, sample := range s.Samples {
+		if sample.Start.UTC().Format("2006-01-02") == today {
+			return true
+		}
+	}
+	return false
+}
+
+// addSample records sample and drops those older than sampleHistory.
+func (s *watchState) addSample(sample watchSample, now time.Time) {
+	kept := s.Samples[:0]
+	for _, old := range s.Samples {
+		if now.Sub(old.Start) <= sampleHistory {
+			kept = append(kept, old)
+		}
+	}
+	s.Samples = append(kept, sample)
+}
+
+// trafficSamples returns the kept samples for the seasonal projection.
+func (s *watchState) trafficSamples() []analysis.TrafficSample {
+	var samples []analysis.TrafficSample
+	for _, sample := range s.Samples {
+		samples = append(samples, analysis.TrafficSample{
+			Start:    sample.Start,
+			Duration: time.Duration(sample.Minutes) * time.Minute,
+			Stats: &analysis.TrafficStats{
+				TotalRecords: sample.Records,
+				TotalBytes:   sample.TotalBytes,
+				Services: map[analysis.Service]analysis.ServiceStats{
+					analysis.ServiceS3:       {Bytes: sample.S3Bytes},
+					analysis.ServiceDynamoDB: {Bytes: sample.DynamoBytes},
+				},
+			},
+		})
+	}
+	return samples
+}
+
+// watchSampling takes the day's Flow Logs sample once its slot has come,
+// unless one was already taken today, and logs the monthly picture the kept
+// samples add up to.
+func watchSampling(ctx context.Context, scanner Scanner, opts WatchOptions, now time.Time) error {
+	path, err := watchStatePath(scanner.GetAccountID(), opts.Region)
+	if err != nil {
+		return err
+	}
+	state, err := loadWatchState(path)
+	if err != nil {
+		return err
+	}
+	if now.Before(sampleSlot(now)) || state.sampledOn(now) {
+		return nil
+	}
+
+	sample, err := collectWatchSample(ctx, scanner, opts)
+	if err != nil {
+		return fmt.Errorf("daily sample failed, retrying at the next check: %w", err)
+	}
+	// Reload: the quick scan may have saved the state while the sample ran.
+	if state, err = loadWatchState(path); err != nil {
+		return err
+	}
+	state.addSample(sample, now)
+	if err := state.save(path); err != nil {
+		return err
+	}
+
+	est := analysis.CalculateSeasonalCosts(opts.Region, state.trafficSamples())
+	logWatch("sample", "Monthly picture: %s GB, ~%s/month NAT data processing, %s/month avoidable with gateway endpoints (%s)",
+		units.Number(est.TotalDataGB), formatCurrency(est.CurrentMonthlyCost), formatCurrency(est.TotalSavingsMonthly), est.ProjectionMethod)
+	return nil
+}
+
+// collectWatchSample enables Flow Logs on every NAT Gateway for
+// SampleMinutes, classifies what they recorded and removes them again. Like
+// a deep scan, it keeps a cleanup manifest while the resources exist.
+func collectWatchSample(ctx context.Context, scanner Scanner, opts WatchOptions) (watchSample, error) {
+	nats, err := scanner.DiscoverNATGateways(ctx)
+	if err != nil {
+		return watchSample{}, err
+	}
+	if len(nats) == 0 {
+		return watchSample{}, fmt.Errorf("no NAT Gateways to sample")
+	}
+	roleARN := fmt.Sprintf("arn:aws:iam::%s:role/termiNATor-FlowLogsRole", scanner.GetAccountID())
+	if err := scanner.ValidateFlowLogsRole(ctx, roleARN); err != nil {
+		return watchSample{}, err
+	}
+
+	runID := naming.Default().RunID(time.Now().Unix())
+	if opts.NewRunID != nil {
+		runID = opts.NewRunID()
+	}
+	logGroup := naming.LogGroupName(runID)
+	store, _ := manifest.DefaultStore()
+	m := manifest.New(runID, scanner.GetAccountID(), opts.Region, logGroup, ProvisionDirect)
+	if err := store.Save(m); err != nil {
+		logWatch("sample", "Could not write cleanup manifest, a crash would leave resources behind: %v", err)
+	}
+
+	logWatch("sample", "Sampling %d NAT Gateway(s) for %d minute(s) into %s", len(nats), opts.SampleMinutes, logGroup)
+	if err := scanner.CreateLogGroup(ctx, logGroup); err != nil {
+		store.Remove(runID)
+		return watchSample{}, fmt.Errorf("failed to create log group: %w", err)
+	}
+	defer func() {
+		// Clean up even when interrupted.
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		err := scanner.DeleteFlowLogs(cleanupCtx, m.FlowLogIDs)
+		if err == nil {
+			err = scanner.DeleteLogGroup(cleanupCtx, logGroup)
+		}
+		if err != nil {
+			logWatch("sample", "Cleanup failed, the next deep scan will offer to finish it: %v", err)
+			return
+		}
+		store.Remove(runID)
+	}()
+
+	for _, nat := range nats {
+		id, err := scanner.CreateFlowLogs(ctx, nat, logGroup, roleARN, runID)
+		if err != nil {
+			return watchSample{}, fmt.Errorf("failed to create flow logs: %w", err)
+		}
+		m.FlowLogIDs = append(m.FlowLogIDs, id)
+		store.Save(m)
+	}
+	if err := waitForWatchFlowLogs(ctx, scanner, logGroup); err != nil {
+		return watchSample{}, err
+	}
+
+	start := time.Now()
+	select {
+	case <-ctx.Done():
+		return watchSample{}, ctx.Err()
+	case <-time.After(time.Duration(opts.SampleMinutes) * time.Minute):
+	}
+	endTime := time.Now().Unix()
+	stats, _, err := scanner.AnalyzeTrafficPerNAT(ctx, logGroup, nats, start.Unix()-300, endTime)
+	if err != nil {
+		return watchSample{}, fmt.Errorf("failed to analyze traffic: %w", err)
+	}
+	logWatch("sample", "Sampled %s records, %s", units.Count(stats.TotalRecords), units.Format(stats.TotalBytes))
+	return watchSample{
+		Start:       start.UTC(),
+		Minutes:     opts.SampleMinutes,
+		Records:     stats.TotalRecords,
+		TotalBytes:  stats.TotalBytes,
+		S3Bytes:     stats.Bytes(analysis.ServiceS3),
+		DynamoBytes: stats.Bytes(analysis.ServiceDynamoDB),
+	}, nil
+}
+
+// waitForWatchFlowLogs waits up to 10 minutes for the sample's Flow Logs to
+// become active.
+func waitForWatchFlowLogs(ctx context.Context, scanner Scanner, logGroup string) error {
+	deadline := time.Now().Add(10 * time.Minute)
+	for time.Now().Before(deadline) {
+		if active, err := scanner.CheckActiveFlowLogs(ctx, logGroup); err == nil && len(active) > 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(30 * time.Second):
+		}
+	}
+	return fmt.Errorf("timeout waiting for Flow Logs to become ACTIVE")
+}
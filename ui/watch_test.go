@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doitintl/terminator/internal/analysis"
+	"github.com/doitintl/terminator/internal/core"
+	"github.com/doitintl/terminator/pkg/types"
+)
+
+func TestCheckThresholds(t *testing.T) {
+	opts := WatchOptions{Region: "us-east-1", Window: time.Hour, MaxGB: 10, MaxMonthlyCost: 300}
+	nat := types.NATGateway{ID: "nat-1", VPCID: "vpc-1", Tags: map[string]string{"Name": "egress-a"}}
+
+	if got := checkThresholds(natUsage{NAT: nat, GB: 5, MonthlyCost: 100}, opts); len(got) != 0 {
+		t.Fatalf("under both thresholds, got %v", got)
+	}
+	got := checkThresholds(natUsage{NAT: nat, GB: 12, MonthlyCost: 100}, opts)
+	if len(got) != 1 || !strings.Contains(got[0], "12.00 GB processed in 1h") {
+		t.Fatalf("GB breach = %v", got)
+	}
+
+	usage := natUsage{NAT: nat, GB: 12, MonthlyCost: 388.8}
+	breaches := checkThresholds(usage, opts)
+	if len(breaches) != 2 {
+		t.Fatalf("want both breaches, got %v", breaches)
+	}
+	a := watchAlert("terminat-watch-123-nat-1", "123", opts, usage, breaches)
+	if a.Severity != "error" || a.Details["nat_gateway_name"] != "egress-a" || a.Details["vpc_id"] != "vpc-1" {
+		t.Fatalf("alert = %+v", a)
+	}
+	if !strings.Contains(a.Details["service_breakdown_by"], "--nat-gateway-ids nat-1") {
+		t.Fatalf("alert should point at a deep scan: %v", a.Details)
+	}
+}
+
+func TestWatchDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{time.Hour: "1h", 30 * time.Minute: "30m", 90 * time.Minute: "1h30m", 30 * time.Second: "30s"} {
+		if got := watchDuration(d); got != want {
+			t.Errorf("watchDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestDiffFindings(t *testing.T) {
+	missingDDB := types.Finding{Type: "missing-endpoint", Severity: "high", Title: "Missing DynamoDB Gateway Endpoint", VPCID: "vpc-1", Service: "DynamoDB", Action: "Create DynamoDB Gateway VPC endpoint"}
+	before := []types.Finding{
+		missingDDB,
+		{Type: "misconfigured-endpoint", Severity: "high", Title: "S3 Gateway Endpoint Not Associated with NAT Route Tables", VPCID: "vpc-1", Service: "S3", Action: "Associate S3 endpoint with route tables: rtb-a"},
+	}
+	after := []types.Finding{
+		{Type: "misconfigured-endpoint", Severity: "high", Title: "S3 Gateway Endpoint Not Associated with NAT Route Tables", VPCID: "vpc-1", Service: "S3", Action: "Associate S3 endpoint with route tables: rtb-a, rtb-b"},
+	}
+
+	if added, resolved := diffFindings(before, before); len(added) != 0 || len(resolved) != 0 {
+		t.Fatalf("unchanged findings diffed as %v / %v", added, resolved)
+	}
+	added, resolved := diffFindings(before, after)
+	if len(added) != 1 || len(resolved) != 2 {
+		t.Fatalf("added %v, resolved %v", added, resolved)
+	}
+	c := findingChanges(added, resolved)
+	if len(c.New) != 1 || !strings.Contains(c.New[0], "rtb-a, rtb-b") {
+		t.Errorf("new = %v, want the association removed from rtb-b", c.New)
+	}
+	if len(c.Resolved) != 2 || !strings.HasPrefix(c.Resolved[0], "[high] Missing DynamoDB Gateway Endpoint in vpc-1") {
+		t.Errorf("resolved = %v", c.Resolved)
+	}
+}
+
+func TestWatchStateRoundTrip(t *testing.T) {
+	t.Setenv("TERMINAT_STATE_DIR", t.TempDir())
+	path, err := watchStatePath("123456789012", "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := loadWatchState(path)
+	if err != nil || !state.LastQuickScan.IsZero() {
+		t.Fatalf("missing state should load empty, got %+v, %v", state, err)
+	}
+	scanned := time.Date(2026, 9, 14, 6, 0, 0, 0, time.UTC)
+	state.LastQuickScan = scanned
+	state.Findings = []types.Finding{{Type: "missing-endpoint", VPCID: "vpc-1", Service: "S3"}}
+	if err := state.save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadWatchState(path)
+	if err != nil || !got.LastQuickScan.Equal(scanned) || len(got.Findings) != 1 {
+		t.Fatalf("reloaded %+v, %v", got, err)
+	}
+}
+
+func TestSampleSlotRotates(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	hours := map[int]bool{}
+	for i := 0; i < 24; i++ {
+		slot := sampleSlot(day.AddDate(0, 0, i).Add(23 * time.Hour))
+		if !slot.Truncate(24 * time.Hour).Equal(day.AddDate(0, 0, i)) {
+			t.Fatalf("slot %s is not on day %d", slot, i)
+		}
+		hours[slot.Hour()] = true
+	}
+	if len(hours) != 24 {
+		t.Errorf("24 days sampled %d distinct hours, want all 24", len(hours))
+	}
+	next := sampleSlot(day.AddDate(0, 0, 1))
+	if got := (next.Hour() - sampleSlot(day).Hour() + 24) % 24; got != sampleHourStep {
+		t.Errorf("consecutive slots are %dh apart, want %dh", got, sampleHourStep)
+	}
+}
+
+func TestWatchStateSamples(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	state := &watchState{Samples: []watchSample{
+		{Start: now.AddDate(0, 0, -40), Minutes: 15, Records: 10, TotalBytes: 1 << 30},
+		{Start: now.AddDate(0, 0, -1), Minutes: 15, Records: 10, TotalBytes: 1 << 30, S3Bytes: 1 << 29},
+	}}
+	if state.sampledOn(now) {
+		t.Fatal("no sample was taken today")
+	}
+	state.addSample(watchSample{Start: now, Minutes: 15, Records: 10, TotalBytes: 1 << 30}, now)
+	if !state.sampledOn(now.Add(6*time.Hour)) || len(state.Samples) != 2 {
+		t.Fatalf("samples = %+v, want yesterday's and today's", state.Samples)
+	}
+
+	est := analysis.CalculateSeasonalCosts("us-east-1", state.trafficSamples())
+	// 1 GiB per 15 minutes is 2,880 GB in a 720-hour month.
+	if est.TotalDataGB != 2880 || est.S3DataGB != 720 {
+		t.Errorf("estimate = %+v", est)
+	}
+}
+
+// expiryScanner finds the resources of expired and records what it is asked
+// to delete.
+type expiryScanner struct {
+	fakeScanner
+	expired *core.ExpiredResources
+	deleted *core.ExpiredResources
+}
+
+func (f *expiryScanner) FindExpiredResources(ctx context.Context, now time.Time) (*core.ExpiredResources, error) {
+	return f.expired, nil
+}
+
+func (f *expiryScanner) DeleteExpiredResources(ctx context.Context, expired *core.ExpiredResources) ([]string, error) {
+	f.deleted = expired
+	return nil, nil
+}
+
+func TestDeleteExpired(t *testing.T) {
+	scanner := &expiryScanner{expired: &core.ExpiredResources{}}
+	if err := deleteExpired(context.Background(), scanner, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if scanner.deleted != nil {
+		t.Fatalf("nothing expired, but deleted %+v", scanner.deleted)
+	}
+
+	scanner.expired = &core.ExpiredResources{FlowLogIDs: []string{"fl-1"}, LogGroups: []string{"/aws/vpc/flowlogs/terminat-1"}}
+	if err := deleteExpired(context.Background(), scanner, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if scanner.deleted != scanner.expired {
+		t.Fatalf("deleted %+v, want the expired resources", scanner.deleted)
+	}
+}